@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/agent"
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// runAgentScript handles the scripting form of `mclaw agent`:
+//
+//	--stdin            read the prompt from stdin (appended to -m if both)
+//	--json             print the result as JSON: content, tool calls, usage
+//	-m, --message      prompt text
+//	-s, --session      session key (default "cli:script")
+//	-v, --verbose      keep agent logs on stderr
+//
+// It reports false when neither --stdin nor --json is given, leaving the
+// interactive and plain -m modes to RunAgent. Exit status is 1 on failure,
+// with {"error": "..."} as the output in JSON mode.
+func runAgentScript(args []string) bool {
+	var message, sessionKey string
+	var fromStdin, asJSON, verbose bool
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--stdin":
+			fromStdin = true
+		case "--json":
+			asJSON = true
+		case "-v", "--verbose":
+			verbose = true
+		case "-m", "--message", "-s", "--session":
+			if i+1 >= len(args) {
+				scriptFail(asJSON, fmt.Errorf("%s needs a value", args[i]))
+			}
+			if args[i] == "-m" || args[i] == "--message" {
+				message = args[i+1]
+			} else {
+				sessionKey = args[i+1]
+			}
+			i++
+		}
+	}
+	if !fromStdin && !asJSON {
+		return false
+	}
+	if sessionKey == "" {
+		sessionKey = "cli:script"
+	}
+	if !verbose {
+		logger.SetLevel(logger.ERROR) // stdout is for the result, keep stderr quiet too
+	}
+
+	prompt := message
+	if fromStdin {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			scriptFail(asJSON, fmt.Errorf("reading stdin: %w", err))
+		}
+		if input := strings.TrimSpace(string(data)); input != "" {
+			if prompt != "" {
+				prompt += "\n\n"
+			}
+			prompt += input
+		}
+	}
+	if strings.TrimSpace(prompt) == "" {
+		scriptFail(asJSON, fmt.Errorf("empty prompt: pass -m or pipe text with --stdin"))
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		scriptFail(asJSON, fmt.Errorf("loading config: %w", err))
+	}
+	provider, err := providers.CreateProvider(cfg)
+	if err != nil {
+		scriptFail(asJSON, fmt.Errorf("creating provider: %w", err))
+	}
+	al := agent.NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	result, err := al.ProcessDirectResult(context.Background(), prompt, sessionKey)
+	if err != nil {
+		scriptFail(asJSON, err)
+	}
+	if !asJSON {
+		fmt.Println(result.Content)
+		return true
+	}
+	if result.ToolCalls == nil {
+		result.ToolCalls = []agent.ToolCallRecord{}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(result)
+	return true
+}
+
+func scriptFail(asJSON bool, err error) {
+	if asJSON {
+		json.NewEncoder(os.Stdout).Encode(map[string]string{"error": err.Error()})
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/audit"
+)
+
+// RunAudit handles `mclaw audit [options]`: list recorded tool calls.
+func RunAudit() {
+	args := os.Args[2:]
+	f := audit.Filter{Limit: 50}
+	asJSON := false
+
+	for i := 0; i < len(args); i++ {
+		opt := args[i]
+		switch opt {
+		case "-h", "--help", "help":
+			auditHelp()
+			return
+		case "--json":
+			asJSON = true
+			continue
+		}
+		if i+1 >= len(args) {
+			fmt.Printf("Unknown or incomplete option: %s\n", opt)
+			auditHelp()
+			os.Exit(1)
+		}
+		i++
+		value := args[i]
+		switch opt {
+		case "-c", "--channel":
+			f.Channel = value
+		case "-u", "--user":
+			f.SenderID = value
+		case "-t", "--tool":
+			f.Tool = value
+		case "--outcome":
+			f.Outcome = value
+		case "--since", "--until":
+			d, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				fmt.Printf("✗ Invalid date %s (use YYYY-MM-DD)\n", value)
+				os.Exit(1)
+			}
+			if opt == "--since" {
+				f.Since = d
+			} else {
+				f.Until = d
+			}
+		case "-n", "--limit":
+			f.Limit, _ = strconv.Atoi(value)
+		default:
+			fmt.Printf("Unknown option: %s\n", opt)
+			auditHelp()
+			os.Exit(1)
+		}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	entries, err := audit.Query(filepath.Join(filepath.Dir(cfg.WorkspacePath()), audit.FileName), f)
+	if err != nil {
+		fmt.Printf("✗ Reading audit log failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			enc.Encode(e)
+		}
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println("No matching tool calls.")
+		return
+	}
+	for _, e := range entries {
+		who := e.SenderID
+		if e.Channel != "" {
+			who = e.Channel + ":" + who
+		}
+		if who == "" {
+			who = "-"
+		}
+		line := fmt.Sprintf("%s  %-24s %-16s %-5s %6dms %7d chars  args %s",
+			e.Time.Local().Format("2006-01-02 15:04:05"), who, e.Tool, e.Outcome, e.DurationMs, e.ResultChars, e.ArgsHash)
+		if e.Error != "" {
+			line += "\n    " + e.Error
+		}
+		fmt.Println(line)
+	}
+}
+
+func auditHelp() {
+	fmt.Println("\nAudit: list tool calls recorded in audit.jsonl (newest last)")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -c, --channel <name>          Only this channel (e.g. telegram)")
+	fmt.Println("  -u, --user <id>               Only calls made for this sender")
+	fmt.Println("  -t, --tool <name>             Only this tool")
+	fmt.Println("  --outcome <ok|error>          Only successful or failed calls")
+	fmt.Println("  --since <YYYY-MM-DD>          From date (inclusive)")
+	fmt.Println("  --until <YYYY-MM-DD>          To date (exclusive)")
+	fmt.Println("  -n, --limit <n>               Newest n calls (default: 50, 0 = all)")
+	fmt.Println("  --json                        One JSON object per line")
+}
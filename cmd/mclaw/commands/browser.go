@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// RunBrowser handles `mclaw browser <login|clear>`.
+func RunBrowser() {
+	if len(os.Args) < 3 {
+		browserHelp()
+		return
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	profileDir := cfg.BrowserProfileDir()
+	if profileDir == "" {
+		fmt.Println("The browser profile is not persistent. Set tools.browser.persistent to true in your config first.")
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "login":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: mclaw browser login <url>")
+			os.Exit(1)
+		}
+		browserLogin(profileDir, os.Args[3])
+	case "clear":
+		if err := os.RemoveAll(profileDir); err != nil {
+			fmt.Printf("✗ Failed to clear browser profile: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Cleared browser profile %s\n", profileDir)
+	default:
+		fmt.Printf("Unknown browser command: %s\n", os.Args[2])
+		browserHelp()
+	}
+}
+
+func browserHelp() {
+	fmt.Println("\nBrowser commands:")
+	fmt.Println("  login <url>                   Open a browser window to sign in to a site once")
+	fmt.Println("  clear                         Delete saved cookies and logins")
+	fmt.Println()
+	fmt.Println("Requires tools.browser.persistent. The browser tool reuses the saved")
+	fmt.Println("profile, so pages behind the login are reachable afterwards.")
+}
+
+// browserLogin opens a visible Chrome window on the persistent profile and
+// waits until the user has signed in.
+func browserLogin(profileDir, url string) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "https://" + url
+	}
+	if err := os.MkdirAll(profileDir, 0700); err != nil {
+		fmt.Printf("✗ Failed to create browser profile: %v\n", err)
+		os.Exit(1)
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), tools.BrowserOptions(false, profileDir)...)
+	defer allocCancel()
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	defer cancel()
+
+	if err := chromedp.Run(ctx, chromedp.Navigate(url)); err != nil {
+		fmt.Printf("✗ Failed to open browser: %v\n", err)
+		fmt.Println("  Make sure Chrome/Chromium is installed and mclaw is not running with the same profile.")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Sign in to %s in the browser window, then press Enter here to save the session.\n", url)
+
+	entered := make(chan struct{})
+	go func() {
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		close(entered)
+	}()
+
+	select {
+	case <-entered:
+	case <-ctx.Done():
+		// Window closed by the user; the profile is already on disk
+	}
+
+	// Close Chrome cleanly so cookies are flushed to the profile
+	if err := chromedp.Cancel(ctx); err != nil && ctx.Err() == nil {
+		fmt.Printf("✗ Failed to close browser: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Browser session saved to %s\n", profileDir)
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// RunConfig handles `mclaw config <get|set|unset|validate>`.
+func RunConfig() {
+	if len(os.Args) < 3 {
+		configHelp()
+		return
+	}
+	path := getConfigPath()
+	args := os.Args[3:]
+
+	switch os.Args[2] {
+	case "get":
+		showSecrets := false
+		var keys []string
+		for _, a := range args {
+			if a == "--show-secrets" {
+				showSecrets = true
+			} else {
+				keys = append(keys, a)
+			}
+		}
+		if len(keys) != 1 {
+			fmt.Println("Usage: mclaw config get <key> [--show-secrets]")
+			os.Exit(1)
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		value, err := config.GetValue(cfg, keys[0])
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		if !showSecrets {
+			value = maskSecrets(keys[0], value)
+		}
+		printConfigValue(value)
+
+	case "set":
+		if len(args) < 2 {
+			fmt.Println("Usage: mclaw config set <key> <value>")
+			os.Exit(1)
+		}
+		key, value := args[0], strings.Join(args[1:], " ")
+		if err := config.SetValue(path, key, value); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Set %s\n", key)
+		warnInvalidConfig(path)
+
+	case "unset":
+		if len(args) != 1 {
+			fmt.Println("Usage: mclaw config unset <key>")
+			os.Exit(1)
+		}
+		if err := config.UnsetValue(path, args[0]); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Removed %s, the default applies\n", args[0])
+		warnInvalidConfig(path)
+
+	case "validate":
+		if err := config.ValidateFile(path); err != nil {
+			fmt.Printf("✗ %s has problems:\n", path)
+			for _, line := range strings.Split(err.Error(), "\n") {
+				fmt.Println("  - " + line)
+			}
+			os.Exit(1)
+		}
+		fmt.Printf("✓ %s is valid\n", path)
+
+	default:
+		configHelp()
+	}
+}
+
+func configHelp() {
+	fmt.Println("\nConfig commands:")
+	fmt.Println("  get <key> [--show-secrets]    Print the effective value of a key")
+	fmt.Println("  set <key> <value>             Write a value to the config file")
+	fmt.Println("  unset <key>                   Remove a key so its default applies")
+	fmt.Println("  validate                      Check the config file for mistakes")
+	fmt.Println()
+	fmt.Println("Keys are dotted paths, e.g. agents.defaults.model or channels.telegram.allow_from.")
+	fmt.Println("Lists accept comma-separated values; objects take JSON.")
+	fmt.Println("A running gateway picks up most changes without a restart.")
+}
+
+// warnInvalidConfig prints validation problems after an edit. The edit is
+// kept, since fixing a config often takes more than one set.
+func warnInvalidConfig(path string) {
+	if err := config.ValidateFile(path); err != nil {
+		fmt.Println("⚠️  The config still has problems:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Println("  - " + line)
+		}
+	}
+}
+
+func printConfigValue(value interface{}) {
+	if s, ok := value.(string); ok {
+		fmt.Println(s)
+		return
+	}
+	data, _ := json.MarshalIndent(value, "", "  ")
+	fmt.Println(string(data))
+}
+
+// maskSecrets hides API keys, tokens and passwords in get output.
+func maskSecrets(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			out[k] = maskSecrets(k, child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = maskSecrets(key, child)
+		}
+		return out
+	case string:
+		if v != "" && isSecretKey(key) {
+			return maskSecret(v)
+		}
+	}
+	return value
+}
+
+func isSecretKey(key string) bool {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key = key[i+1:]
+	}
+	key = strings.ToLower(key)
+	for _, s := range []string{"key", "token", "secret", "password"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
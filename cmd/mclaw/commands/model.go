@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// RunModel handles `mclaw model [list|set <name>]`.
+func RunModel() {
+	args := os.Args[2:]
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		fmt.Printf("Default model: %s\n", cfg.Agents.Defaults.Model)
+		chains := cfg.Agents.Defaults.FallbackChains()
+		for _, class := range []string{"rate_limit", "timeout", "content_filter"} {
+			if chain := chains[class]; len(chain.Models) > 0 {
+				fmt.Printf("On %-14s %s (cooldown %d min)\n", class+":", strings.Join(chain.Models, " → "), chain.CooldownMinutes)
+			}
+		}
+		aliases := cfg.Agents.Defaults.Models
+		if len(aliases) == 0 {
+			fmt.Println("\nNo model aliases. Add them under agents.defaults.models, e.g. {\"fast\": \"groq/llama-3.3-70b\"}.")
+			return
+		}
+		names := make([]string, 0, len(aliases))
+		for name := range aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Println("\nModels:")
+		for _, name := range names {
+			marker := " "
+			if aliases[name] == cfg.Agents.Defaults.Model {
+				marker = "*"
+			}
+			fmt.Printf("  %s %-10s %s\n", marker, name, aliases[name])
+		}
+
+	case "set":
+		if len(args) != 2 {
+			fmt.Println("Usage: mclaw model set <name>")
+			os.Exit(1)
+		}
+		model := cfg.ResolveModel(args[1])
+		if _, err := providers.CreateProviderForModel(cfg, model); err != nil {
+			fmt.Printf("✗ Can't use model %s: %v\n", args[1], err)
+			os.Exit(1)
+		}
+		if err := config.SetValue(getConfigPath(), "agents.defaults.model", model); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Default model is now %s\n", model)
+		fmt.Println("A running gateway switches over without a restart.")
+
+	default:
+		modelHelp()
+	}
+}
+
+func modelHelp() {
+	fmt.Println("\nModel commands:")
+	fmt.Println("  list          Show the default model and the aliases in agents.defaults.models")
+	fmt.Println("  set <name>    Make a model or alias the default")
+	fmt.Println()
+	fmt.Println("In chat, /model switches a single conversation.")
+}
@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// RunSecrets handles `mclaw secrets <set|rm|list|migrate>`.
+func RunSecrets() {
+	if len(os.Args) < 3 {
+		secretsHelp()
+		return
+	}
+
+	useKeyring := false
+	var args []string
+	for _, a := range os.Args[3:] {
+		if a == "--keyring" {
+			useKeyring = true
+		} else {
+			args = append(args, a)
+		}
+	}
+
+	path := getConfigPath()
+	// Unresolved, since a missing secret is what `secrets set` is there to fix
+	cfg, err := config.LoadConfigUnresolved(path)
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	store := &secretStore{file: cfg.SecretsFilePath(), keyring: useKeyring}
+	if useKeyring && !config.KeyringAvailable() {
+		fmt.Println("✗ No OS keyring client found. Install secret-tool (libsecret) or use the encrypted file.")
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "set":
+		if len(args) != 1 {
+			fmt.Println("Usage: mclaw secrets set <name> [--keyring]")
+			os.Exit(1)
+		}
+		value := readSecretInput(fmt.Sprintf("Value for %s: ", args[0]))
+		if value == "" {
+			fmt.Println("✗ Empty value, nothing stored.")
+			os.Exit(1)
+		}
+		if err := store.set(args[0], value); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Stored %s in %s\n", args[0], store)
+		fmt.Printf("Reference it in the config as \"secret:%s\"\n", args[0])
+
+	case "rm":
+		if len(args) != 1 {
+			fmt.Println("Usage: mclaw secrets rm <name> [--keyring]")
+			os.Exit(1)
+		}
+		if err := store.remove(args[0]); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Removed %s from %s\n", args[0], store)
+
+	case "list":
+		if useKeyring {
+			fmt.Println("The keyring cannot be listed; use your keyring manager (service \"mclaw\").")
+			return
+		}
+		secrets, err := store.load()
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		if len(secrets) == 0 {
+			fmt.Printf("No secrets in %s\n", store.file)
+			return
+		}
+		names := make([]string, 0, len(secrets))
+		for name := range secrets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %-32s %s\n", name, maskSecret(secrets[name]))
+		}
+
+	case "migrate":
+		moved, err := config.MigrateSecrets(path, store.set)
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		if len(moved) == 0 {
+			fmt.Println("No plaintext credentials found in the config.")
+			return
+		}
+		for _, name := range moved {
+			fmt.Printf("✓ %s → %s\n", name, store)
+		}
+		fmt.Printf("The config now references them as secret:NAME.\n")
+		if !useKeyring {
+			fmt.Printf("Set %s when starting mclaw so it can unlock %s.\n", config.SecretPassphraseEnv, store.file)
+		}
+
+	default:
+		secretsHelp()
+	}
+}
+
+func secretsHelp() {
+	fmt.Println("\nSecrets commands:")
+	fmt.Println("  set <name> [--keyring]        Store a secret (read from the terminal or stdin)")
+	fmt.Println("  rm <name> [--keyring]         Delete a secret")
+	fmt.Println("  list                          List secrets in the encrypted file")
+	fmt.Println("  migrate [--keyring]           Move plaintext keys and tokens out of the config")
+	fmt.Println()
+	fmt.Println("Secrets go to an encrypted file (secrets.enc next to the config) unlocked by")
+	fmt.Printf("%s, or to the OS keyring with --keyring. Reference them in\n", config.SecretPassphraseEnv)
+	fmt.Println("the config as \"secret:NAME\".")
+}
+
+// secretStore writes to the encrypted secrets file or the OS keyring.
+type secretStore struct {
+	file       string
+	keyring    bool
+	passphrase string
+	secrets    map[string]string
+}
+
+func (s *secretStore) String() string {
+	if s.keyring {
+		return "the OS keyring"
+	}
+	return s.file
+}
+
+func (s *secretStore) load() (map[string]string, error) {
+	if s.secrets != nil {
+		return s.secrets, nil
+	}
+	if s.passphrase == "" {
+		s.passphrase = os.Getenv(config.SecretPassphraseEnv)
+	}
+	if s.passphrase == "" {
+		_, err := os.Stat(s.file)
+		s.passphrase = readSecretInput("Passphrase for " + s.file + ": ")
+		if os.IsNotExist(err) && readSecretInput("Repeat passphrase: ") != s.passphrase {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	secrets, err := config.ReadSecretsFile(s.file, s.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	s.secrets = secrets
+	return secrets, nil
+}
+
+func (s *secretStore) set(name, value string) error {
+	if s.keyring {
+		return config.KeyringSet(name, value)
+	}
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return config.WriteSecretsFile(s.file, s.passphrase, secrets)
+}
+
+func (s *secretStore) remove(name string) error {
+	if s.keyring {
+		return config.KeyringDelete(name)
+	}
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return fmt.Errorf("%s is not in %s", name, s.file)
+	}
+	delete(secrets, name)
+	return config.WriteSecretsFile(s.file, s.passphrase, secrets)
+}
+
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// readSecretInput reads one line without echoing it when stdin is a
+// terminal. Piped input is read as is.
+func readSecretInput(prompt string) string {
+	info, err := os.Stdin.Stat()
+	terminal := err == nil && info.Mode()&os.ModeCharDevice != 0
+	if terminal {
+		fmt.Print(prompt)
+		if stty("-echo") == nil {
+			defer func() {
+				stty("echo")
+				fmt.Println()
+			}()
+		}
+	}
+	line, _ := stdinReader.ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,334 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// RunService handles `mclaw service <install|uninstall|start|stop|restart|status|logs>`,
+// which runs the gateway under systemd (Linux, as a user unit) or launchd
+// (macOS, as a LaunchAgent).
+func RunService() {
+	if len(os.Args) < 3 {
+		serviceHelp()
+		return
+	}
+	svc, err := newService()
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "install":
+		err = svc.install()
+	case "uninstall":
+		err = svc.uninstall()
+	case "start":
+		err = svc.start()
+	case "stop":
+		err = svc.stop()
+	case "restart":
+		if err = svc.stop(); err == nil {
+			err = svc.start()
+		}
+	case "status":
+		err = svc.status()
+	case "logs":
+		err = svc.logs(os.Args[3:])
+	default:
+		serviceHelp()
+		return
+	}
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func serviceHelp() {
+	fmt.Println("\nService commands:")
+	fmt.Println("  install                       Install and start mclaw as a background service")
+	fmt.Println("  uninstall                     Stop and remove the service")
+	fmt.Println("  start / stop / restart        Control the service")
+	fmt.Println("  status                        Show whether the service is running")
+	fmt.Println("  logs [-f] [-n lines]          Show the service log, -f to follow")
+	fmt.Println()
+	fmt.Println("Uses a systemd user unit on Linux and a LaunchAgent on macOS. The service")
+	fmt.Println("restarts mclaw if it crashes and runs the same --profile/--config as install.")
+}
+
+// service describes the installed gateway service for this instance.
+type service struct {
+	name    string // systemd unit name or launchd label
+	exe     string
+	args    []string // arguments for the gateway, global flags included
+	workDir string
+	logFile string
+	envFile string // systemd EnvironmentFile, e.g. for MCLAW_SECRETS_PASSPHRASE
+}
+
+func newService() (*service, error) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		return nil, fmt.Errorf("services are supported on Linux (systemd) and macOS (launchd), not %s", runtime.GOOS)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	cfgPath := getConfigPath()
+	cfg, err := config.LoadConfigUnresolved(cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	s := &service{
+		name:    "mclaw",
+		exe:     exe,
+		workDir: filepath.Dir(exe),
+		logFile: filepath.Join(filepath.Dir(cfg.WorkspacePath()), "logs", "mclaw.log"),
+		envFile: filepath.Join(filepath.Dir(cfgPath), "mclaw.env"),
+	}
+	if runtime.GOOS == "darwin" {
+		s.name = "com.mclaw.gateway"
+	}
+	if profile := config.Profile(); profile != "" {
+		s.name += "-" + profile
+		s.args = append(s.args, "--profile", profile)
+	}
+	if path := os.Getenv(config.ConfigEnv); path != "" {
+		abs, _ := filepath.Abs(path)
+		s.args = append(s.args, "--config", abs)
+	}
+	s.args = append(s.args, "start")
+	return s, nil
+}
+
+func (s *service) unitPath() string {
+	if runtime.GOOS == "darwin" {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, "Library", "LaunchAgents", s.name+".plist")
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "systemd", "user", s.name+".service")
+}
+
+func (s *service) install() error {
+	if err := os.MkdirAll(filepath.Dir(s.logFile), 0755); err != nil {
+		return err
+	}
+	path := s.unitPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	var unit string
+	if runtime.GOOS == "darwin" {
+		unit = s.launchdPlist()
+	} else {
+		unit = s.systemdUnit()
+	}
+	if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Wrote %s\n", path)
+
+	if runtime.GOOS == "darwin" {
+		serviceExecQuiet("launchctl", "bootout", s.launchdTarget()) // replace an older install
+		if err := serviceExec("launchctl", "bootstrap", s.launchdDomain(), path); err != nil {
+			return err
+		}
+	} else {
+		if err := serviceExec("systemctl", "--user", "daemon-reload"); err != nil {
+			return err
+		}
+		if err := serviceExec("systemctl", "--user", "enable", "--now", s.name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✓ %s is running and starts at login\n", s.name)
+	fmt.Printf("  Logs: %s (mclaw service logs -f)\n", s.logFile)
+	if runtime.GOOS == "linux" {
+		fmt.Printf("  Secrets passphrase or other env: %s (KEY=value lines, chmod 600)\n", s.envFile)
+		fmt.Println("  To keep it running after you log out: loginctl enable-linger $USER")
+	}
+	return nil
+}
+
+func (s *service) uninstall() error {
+	path := s.unitPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("%s is not installed", s.name)
+	}
+	if runtime.GOOS == "darwin" {
+		serviceExecQuiet("launchctl", "bootout", s.launchdTarget())
+	} else {
+		serviceExecQuiet("systemctl", "--user", "disable", "--now", s.name)
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	if runtime.GOOS == "linux" {
+		serviceExecQuiet("systemctl", "--user", "daemon-reload")
+	}
+	fmt.Printf("✓ Removed %s (logs kept in %s)\n", s.name, s.logFile)
+	return nil
+}
+
+func (s *service) start() error {
+	if runtime.GOOS == "darwin" {
+		serviceExecQuiet("launchctl", "bootstrap", s.launchdDomain(), s.unitPath())
+		return serviceExec("launchctl", "kickstart", s.launchdTarget())
+	}
+	return serviceExec("systemctl", "--user", "start", s.name)
+}
+
+func (s *service) stop() error {
+	if runtime.GOOS == "darwin" {
+		// bootout, since KeepAlive would restart a killed process
+		return serviceExec("launchctl", "bootout", s.launchdTarget())
+	}
+	return serviceExec("systemctl", "--user", "stop", s.name)
+}
+
+func (s *service) status() error {
+	if _, err := os.Stat(s.unitPath()); os.IsNotExist(err) {
+		fmt.Printf("%s is not installed (mclaw service install)\n", s.name)
+		return nil
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("launchctl", "print", s.launchdTarget())
+	} else {
+		cmd = exec.Command("systemctl", "--user", "status", "--no-pager", s.name)
+	}
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.Run() // non-zero just means it is not running
+	return nil
+}
+
+func (s *service) logs(args []string) error {
+	lines := "100"
+	follow := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-f", "--follow":
+			follow = true
+		case "-n":
+			if i+1 < len(args) {
+				i++
+				if _, err := strconv.Atoi(args[i]); err != nil {
+					return fmt.Errorf("-n needs a number of lines")
+				}
+				lines = args[i]
+			}
+		}
+	}
+	if _, err := os.Stat(s.logFile); err != nil {
+		return fmt.Errorf("no log at %s yet", s.logFile)
+	}
+	tailArgs := []string{"-n", lines}
+	if follow {
+		tailArgs = append(tailArgs, "-F")
+	}
+	cmd := exec.Command("tail", append(tailArgs, s.logFile)...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+func (s *service) systemdUnit() string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=mclaw personal AI assistant (%s)\n", s.name)
+	b.WriteString("After=network-online.target\nWants=network-online.target\n")
+	b.WriteString("StartLimitIntervalSec=300\nStartLimitBurst=5\n\n")
+	// mclaw sends READY=1 and watchdog pings; a stuck gateway is restarted
+	b.WriteString("[Service]\nType=notify\nWatchdogSec=300\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdQuote(append([]string{s.exe}, s.args...)))
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote([]string{s.workDir}))
+	fmt.Fprintf(&b, "EnvironmentFile=-%s\n", s.envFile)
+	b.WriteString("Restart=on-failure\nRestartSec=5\n")
+	fmt.Fprintf(&b, "StandardOutput=append:%s\nStandardError=append:%s\n\n", s.logFile, s.logFile)
+	b.WriteString("[Install]\nWantedBy=default.target\n")
+	return b.String()
+}
+
+func systemdQuote(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if strings.ContainsAny(a, " \t\"'\\") {
+			a = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(a) + `"`
+		}
+		quoted[i] = a
+	}
+	return strings.Join(quoted, " ")
+}
+
+func (s *service) launchdPlist() string {
+	esc := func(v string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(v))
+		return buf.String()
+	}
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	fmt.Fprintf(&b, "  <key>Label</key>\n  <string>%s</string>\n", esc(s.name))
+	b.WriteString("  <key>ProgramArguments</key>\n  <array>\n")
+	for _, a := range append([]string{s.exe}, s.args...) {
+		fmt.Fprintf(&b, "    <string>%s</string>\n", esc(a))
+	}
+	b.WriteString("  </array>\n")
+	fmt.Fprintf(&b, "  <key>WorkingDirectory</key>\n  <string>%s</string>\n", esc(s.workDir))
+	b.WriteString("  <key>RunAtLoad</key>\n  <true/>\n")
+	b.WriteString("  <key>KeepAlive</key>\n  <dict>\n    <key>SuccessfulExit</key>\n    <false/>\n  </dict>\n")
+	b.WriteString("  <key>ThrottleInterval</key>\n  <integer>5</integer>\n")
+	fmt.Fprintf(&b, "  <key>StandardOutPath</key>\n  <string>%s</string>\n", esc(s.logFile))
+	fmt.Fprintf(&b, "  <key>StandardErrorPath</key>\n  <string>%s</string>\n", esc(s.logFile))
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+func (s *service) launchdDomain() string {
+	return "gui/" + strconv.Itoa(os.Getuid())
+}
+
+func (s *service) launchdTarget() string {
+	return s.launchdDomain() + "/" + s.name
+}
+
+// serviceExec runs a service manager command, showing its output on failure.
+func serviceExec(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), msg)
+		}
+		return fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+func serviceExecQuiet(name string, args ...string) {
+	exec.Command(name, args...).Run()
+}
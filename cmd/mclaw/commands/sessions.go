@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/session"
+)
+
+// RunSessions handles `mclaw sessions <list|export|search|archive>`.
+func RunSessions() {
+	if len(os.Args) < 3 {
+		sessionsHelp()
+		return
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	dataDir := filepath.Dir(cfg.WorkspacePath())
+	sm, err := session.NewSQLiteSessionManager(filepath.Join(dataDir, "memory.db"), filepath.Join(dataDir, "sessions"))
+	if err != nil {
+		fmt.Printf("Error opening session database: %v\n", err)
+		os.Exit(1)
+	}
+	defer sm.Close()
+	sm.SetArchiveDir(filepath.Join(dataDir, "sessions_archive"))
+
+	switch os.Args[2] {
+	case "list":
+		sessionsList(sm)
+	case "export":
+		sessionsExport(sm, os.Args[3:])
+	case "search":
+		sessionsSearch(sm, os.Args[3:])
+	case "archive":
+		days := cfg.Sessions.ArchiveAfterDays
+		if len(os.Args) > 3 {
+			days, _ = strconv.Atoi(os.Args[3])
+		}
+		sessionsArchive(sm, days)
+	default:
+		fmt.Printf("Unknown sessions command: %s\n", os.Args[2])
+		sessionsHelp()
+	}
+}
+
+func sessionsHelp() {
+	fmt.Println("\nSessions commands:")
+	fmt.Println("  list                          List stored sessions")
+	fmt.Println("  export <key> [options]        Export a conversation transcript")
+	fmt.Println("  search [text] [options]       Search past conversations")
+	fmt.Println("  archive [days]                Archive sessions idle for more than <days>")
+	fmt.Println()
+	fmt.Println("Export options:")
+	fmt.Println("  -f, --format <md|json>        Output format (default: md)")
+	fmt.Println("  -o, --output <file>           Write to file instead of stdout")
+	fmt.Println()
+	fmt.Println("Search options:")
+	fmt.Println("  -c, --channel <name>          Only this channel (e.g. telegram)")
+	fmt.Println("  --since <YYYY-MM-DD>          From date (inclusive)")
+	fmt.Println("  --until <YYYY-MM-DD>          To date (exclusive)")
+	fmt.Println("  -n, --limit <n>               Maximum results (default: 20)")
+}
+
+func sessionsList(sm *session.SessionManager) {
+	keys := sm.ListKeys()
+	if len(keys) == 0 {
+		fmt.Println("No sessions.")
+		return
+	}
+	sort.Strings(keys)
+
+	fmt.Println("\nSessions:")
+	fmt.Println("---------")
+	for _, key := range keys {
+		s, ok := sm.Get(key)
+		if !ok {
+			continue
+		}
+		fmt.Printf("  %s  (%d messages, updated %s)\n", key, len(s.Messages), s.Updated.Format("2006-01-02 15:04"))
+	}
+}
+
+func sessionsExport(sm *session.SessionManager, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: mclaw sessions export <key> [--format md|json] [--output file]")
+		return
+	}
+
+	key := args[0]
+	format := session.FormatMarkdown
+	output := ""
+
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "-f", "--format":
+			if i+1 < len(args) {
+				format = args[i+1]
+				i++
+			}
+		case "-o", "--output":
+			if i+1 < len(args) {
+				output = args[i+1]
+				i++
+			}
+		}
+	}
+
+	s, ok := sm.Get(key)
+	if !ok {
+		fmt.Printf("✗ Session %s not found\n", key)
+		os.Exit(1)
+	}
+
+	data, err := session.Export(s, format)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		fmt.Printf("✗ Failed to write %s: %v\n", output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Exported %s to %s\n", key, output)
+}
+
+func sessionsSearch(sm *session.SessionManager, args []string) {
+	var q session.SearchQuery
+
+	for i := 0; i < len(args); i++ {
+		if i+1 < len(args) {
+			switch args[i] {
+			case "-c", "--channel":
+				q.Channel = args[i+1]
+				i++
+				continue
+			case "--since", "--until":
+				d, err := time.ParseInLocation("2006-01-02", args[i+1], time.Local)
+				if err != nil {
+					fmt.Printf("✗ Invalid date %s (use YYYY-MM-DD)\n", args[i+1])
+					os.Exit(1)
+				}
+				if args[i] == "--since" {
+					q.Since = d
+				} else {
+					q.Until = d
+				}
+				i++
+				continue
+			case "-n", "--limit":
+				q.Limit, _ = strconv.Atoi(args[i+1])
+				i++
+				continue
+			}
+		}
+		if q.Text == "" {
+			q.Text = args[i]
+		} else {
+			q.Text += " " + args[i]
+		}
+	}
+
+	hits, err := sm.Search(q)
+	if err != nil {
+		fmt.Printf("✗ Search failed: %v\n", err)
+		os.Exit(1)
+	}
+	if len(hits) == 0 {
+		fmt.Println("No matching messages.")
+		return
+	}
+
+	for _, hit := range hits {
+		content := hit.Content
+		if len(content) > 200 {
+			content = content[:200] + "..."
+		}
+		fmt.Printf("[%s] %s  %s\n  %s\n\n", hit.Time.Format("2006-01-02 15:04"), hit.SessionKey, hit.Role, content)
+	}
+}
+
+func sessionsArchive(sm *session.SessionManager, days int) {
+	if days <= 0 {
+		fmt.Println("✗ Specify a positive number of days (or set sessions.archive_after_days)")
+		os.Exit(1)
+	}
+
+	n, err := sm.ArchiveIdle(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		fmt.Printf("✗ Archive failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Archived %d sessions idle for more than %d days\n", n, days)
+}
@@ -0,0 +1,376 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/channels"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// wizardProvider describes one LLM provider the setup wizard can configure.
+type wizardProvider struct {
+	label   string
+	apiBase string // default endpoint
+	keyURL  string
+	config  func(cfg *config.Config) *config.ProviderConfig
+	route   func(model string) string // model name that routes to this provider
+}
+
+var wizardProviders = []wizardProvider{
+	{
+		label:   "OpenRouter (hundreds of models, one key)",
+		apiBase: "https://openrouter.ai/api/v1",
+		keyURL:  "https://openrouter.ai/keys",
+		config:  func(c *config.Config) *config.ProviderConfig { return &c.Providers.OpenRouter },
+		route:   func(m string) string { return m },
+	},
+	{
+		label:   "OpenAI",
+		apiBase: "https://api.openai.com/v1",
+		keyURL:  "https://platform.openai.com/api-keys",
+		config:  func(c *config.Config) *config.ProviderConfig { return &c.Providers.OpenAI },
+		route:   func(m string) string { return withPrefix("openai/", m) },
+	},
+	{
+		label:   "Google Gemini",
+		apiBase: "https://generativelanguage.googleapis.com/v1beta/openai",
+		keyURL:  "https://aistudio.google.com/apikey",
+		config:  func(c *config.Config) *config.ProviderConfig { return &c.Providers.Gemini },
+		route:   func(m string) string { return withPrefix("gemini/", strings.TrimPrefix(m, "models/")) },
+	},
+	{
+		label:   "Groq",
+		apiBase: "https://api.groq.com/openai/v1",
+		keyURL:  "https://console.groq.com/keys",
+		config:  func(c *config.Config) *config.ProviderConfig { return &c.Providers.Groq },
+		route:   func(m string) string { return withPrefix("groq/", m) },
+	},
+	{
+		label:   "Zhipu GLM",
+		apiBase: "https://open.bigmodel.cn/api/paas/v4",
+		keyURL:  "https://open.bigmodel.cn/usercenter/apikeys",
+		config:  func(c *config.Config) *config.ProviderConfig { return &c.Providers.Zhipu },
+		route:   func(m string) string { return m },
+	},
+	{
+		label:  "Local / self-hosted (vLLM, Ollama, LM Studio)",
+		config: func(c *config.Config) *config.ProviderConfig { return &c.Providers.VLLM },
+		route:  func(m string) string { return m },
+	},
+}
+
+func withPrefix(prefix, model string) string {
+	if strings.HasPrefix(model, prefix) {
+		return model
+	}
+	return prefix + model
+}
+
+// wizard holds the interactive state of `mclaw setup`.
+type wizard struct {
+	in  *bufio.Reader
+	cfg *config.Config
+	ctx context.Context
+}
+
+// runSetupWizard walks through provider, model and channel setup, checking
+// each credential with a live call before writing the config. Existing
+// values are offered as defaults, so it can be re-run to change one part.
+func runSetupWizard() {
+	path := getConfigPath()
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		fmt.Printf("Existing config at %s is invalid (%v); starting from defaults.\n", path, err)
+		cfg = config.DefaultConfig()
+	}
+	w := &wizard{in: bufio.NewReader(os.Stdin), cfg: cfg, ctx: context.Background()}
+
+	fmt.Printf("%s mclaw setup\n\nPress Enter to keep the value in [brackets].\n", Logo)
+
+	fmt.Println("\n── Step 1/3: Model provider ──")
+	w.setupProvider()
+
+	fmt.Println("\n── Step 2/3: Chat channels ──")
+	w.setupTelegram()
+	w.setupDiscord()
+
+	fmt.Println("\n── Step 3/3: Workspace ──")
+	cfg.Agents.Defaults.Workspace = w.ask("Workspace directory", cfg.Agents.Defaults.Workspace)
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Println("\n⚠️  The config still has problems:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Println("  - " + line)
+		}
+		if !w.confirm("Save anyway?", false) {
+			fmt.Println("Nothing was written.")
+			os.Exit(1)
+		}
+	}
+
+	if err := config.SaveConfig(path, cfg); err != nil {
+		fmt.Printf("✗ Failed to save config: %v\n", err)
+		os.Exit(1)
+	}
+	os.Chmod(path, 0600) // holds API keys
+	fmt.Printf("\n✓ Saved %s\n", path)
+	if loaded := cfg.Path(); loaded != path {
+		fmt.Printf("It takes precedence over %s, which you can now remove.\n", loaded)
+	}
+	fmt.Println("Start mclaw with: mclaw start")
+}
+
+func (w *wizard) setupProvider() {
+	for i, p := range wizardProviders {
+		mark := ""
+		if pc := p.config(w.cfg); pc.APIKey != "" || (p.apiBase == "" && pc.APIBase != "") {
+			mark = "  ✓ configured"
+		}
+		fmt.Printf("  %d) %s%s\n", i+1, p.label, mark)
+	}
+	p := wizardProviders[w.choose("Provider", len(wizardProviders), 1)-1]
+	pc := p.config(w.cfg)
+
+	apiBase := p.apiBase
+	if apiBase == "" {
+		pc.APIBase = w.ask("API base URL (e.g. http://localhost:11434/v1)", valueOr(pc.APIBase, "http://localhost:8000/v1"))
+		apiBase = pc.APIBase
+	} else if pc.APIBase != "" {
+		apiBase = pc.APIBase
+	}
+
+	var models []string
+	for {
+		if p.keyURL != "" {
+			fmt.Printf("Get a key at %s\n", p.keyURL)
+		}
+		pc.APIKey = w.askSecret("API key", pc.APIKey)
+		if pc.APIKey == "" && p.apiBase != "" {
+			fmt.Println("✗ An API key is required for this provider.")
+			continue
+		}
+
+		fmt.Print("Contacting provider… ")
+		list, err := providers.ListModels(w.ctx, pc.APIKey, apiBase)
+		if err == nil {
+			models = list
+			fmt.Printf("✓ %d models available\n", len(models))
+			break
+		}
+		fmt.Printf("✗ %s\n", shorten(err.Error(), 200))
+		if strings.Contains(err.Error(), "401") || strings.Contains(err.Error(), "403") {
+			if w.confirm("The key was rejected. Enter it again?", true) {
+				continue
+			}
+		} else {
+			fmt.Println("  Could not list models; the key will be checked with a test message instead.")
+		}
+		break
+	}
+
+	for {
+		model := w.pickModel(models, w.cfg.Agents.Defaults.Model)
+		model = p.route(model)
+		fmt.Printf("Sending a test message to %s… ", model)
+		if err := providers.CheckModel(w.ctx, w.cfg, model); err != nil {
+			fmt.Printf("✗ %s\n", shorten(err.Error(), 200))
+			if w.confirm("Choose a different model?", true) {
+				continue
+			}
+		} else {
+			fmt.Println("✓ it works")
+		}
+		w.cfg.Agents.Defaults.Model = model
+		return
+	}
+}
+
+// pickModel lets the user type a model ID, or part of one to search the
+// provider's list, or a number from the last search results.
+func (w *wizard) pickModel(models []string, current string) string {
+	var shown []string
+	for {
+		answer := w.ask("Model (type part of a name to search)", current)
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(shown) {
+			return shown[n-1]
+		}
+		if len(models) == 0 || containsString(models, answer) {
+			return answer
+		}
+
+		shown = shown[:0]
+		for _, m := range models {
+			if strings.Contains(strings.ToLower(m), strings.ToLower(answer)) {
+				shown = append(shown, m)
+			}
+		}
+		switch {
+		case len(shown) == 0:
+			if w.confirm(fmt.Sprintf("%q is not in the provider's list. Use it anyway?", answer), false) {
+				return answer
+			}
+		case len(shown) == 1:
+			return shown[0]
+		default:
+			if len(shown) > 20 {
+				fmt.Printf("%d matches, showing the first 20. Type more of the name to narrow it down.\n", len(shown))
+				shown = shown[:20]
+			}
+			for i, m := range shown {
+				fmt.Printf("  %2d) %s\n", i+1, m)
+			}
+		}
+	}
+}
+
+func (w *wizard) setupTelegram() {
+	tg := &w.cfg.Channels.Telegram
+	if !w.confirm("Connect a Telegram bot?", tg.Enabled) {
+		tg.Enabled = false
+		return
+	}
+	fmt.Println("Create a bot with @BotFather and paste its token.")
+	for {
+		tg.Token = w.askSecret("Bot token", tg.Token)
+		fmt.Print("Checking token… ")
+		name, err := channels.CheckTelegramToken(tg.Token)
+		if err == nil {
+			fmt.Printf("✓ connected as @%s\n", name)
+			break
+		}
+		fmt.Printf("✗ %v\n", err)
+		if !w.confirm("Try another token?", true) {
+			break
+		}
+	}
+	tg.Enabled = tg.Token != ""
+	fmt.Println("Limit who can talk to the bot by Telegram user ID or @username (ask @userinfobot for your ID).")
+	tg.AllowFrom = splitList(w.ask("Allowed users, comma separated (empty = anyone)", strings.Join(tg.AllowFrom, ", ")))
+}
+
+func (w *wizard) setupDiscord() {
+	dc := &w.cfg.Channels.Discord
+	if !w.confirm("Connect a Discord bot?", dc.Enabled) {
+		dc.Enabled = false
+		return
+	}
+	fmt.Println("Create an application at https://discord.com/developers/applications, add a bot and enable the Message Content intent.")
+	for {
+		dc.Token = w.askSecret("Bot token", dc.Token)
+		fmt.Print("Checking token… ")
+		name, err := channels.CheckDiscordToken(dc.Token)
+		if err == nil {
+			fmt.Printf("✓ connected as %s\n", name)
+			break
+		}
+		fmt.Printf("✗ %v\n", err)
+		if !w.confirm("Try another token?", true) {
+			break
+		}
+	}
+	dc.Enabled = dc.Token != ""
+	dc.AllowFrom = splitList(w.ask("Allowed Discord user IDs, comma separated (empty = anyone)", strings.Join(dc.AllowFrom, ", ")))
+}
+
+func (w *wizard) ask(label, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	line, err := w.in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Println()
+		os.Exit(1) // stdin closed
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return def
+	}
+	return line
+}
+
+// askSecret is ask with the current value masked in the prompt.
+func (w *wizard) askSecret(label, current string) string {
+	masked := ""
+	if current != "" {
+		masked = maskSecret(current)
+	}
+	answer := w.ask(label, masked)
+	if answer == masked {
+		return current
+	}
+	return answer
+}
+
+func (w *wizard) confirm(label string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		switch strings.ToLower(w.ask(label+" ("+hint+")", "")) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
+
+func (w *wizard) choose(label string, n, def int) int {
+	for {
+		answer := w.ask(label, strconv.Itoa(def))
+		if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= n {
+			return i
+		}
+		fmt.Printf("Enter a number from 1 to %d.\n", n)
+	}
+}
+
+func maskSecret(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("•", len(s))
+	}
+	return s[:4] + "…" + s[len(s)-4:]
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func shorten(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
+
+func valueOr(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
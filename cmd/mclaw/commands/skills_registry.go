@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/skills"
+)
+
+// runSkillsRegistry handles the registry-backed `mclaw skills` subcommands:
+//
+//	install <name>[@version]   install from the registry (a version pins it)
+//	upgrade [name...]          upgrade unpinned registry skills
+//	pin <name> / unpin <name>  hold or release the installed version
+//	search [keyword]           list skills in the registry
+//
+// It reports false for anything else, including `install owner/repo`,
+// which still installs straight from a GitHub repository.
+func runSkillsRegistry(cfg *config.Config, sub string, args []string) bool {
+	installer := skills.NewSkillInstaller(cfg.WorkspacePath())
+	registry := cfg.Skills.Registry
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	switch sub {
+	case "install":
+		if len(args) == 0 || strings.Contains(args[0], "/") {
+			return false
+		}
+		name, version, _ := strings.Cut(args[0], "@")
+		entry, err := installer.InstallFromRegistry(ctx, registry, name, version)
+		if err != nil {
+			fmt.Printf("✗ Failed to install %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		pinned := ""
+		if entry.Pinned {
+			pinned = " (pinned)"
+		}
+		fmt.Printf("✓ Installed %s %s%s, %d file(s) verified\n", name, entry.Version, pinned, len(entry.Files))
+
+	case "upgrade":
+		results, err := installer.Upgrade(ctx, registry, args)
+		if err != nil {
+			fmt.Printf("✗ Upgrade failed: %v\n", err)
+			os.Exit(1)
+		}
+		if len(results) == 0 {
+			fmt.Println("No skills installed from a registry.")
+		}
+		for _, r := range results {
+			switch {
+			case r.Err != nil:
+				fmt.Printf("✗ %s: %v\n", r.Name, r.Err)
+			case r.To != "":
+				fmt.Printf("✓ %s: %s → %s\n", r.Name, r.From, r.To)
+			default:
+				fmt.Printf("- %s %s: %s\n", r.Name, r.From, r.Skipped)
+			}
+		}
+
+	case "pin", "unpin":
+		if len(args) == 0 {
+			fmt.Printf("Usage: mclaw skills %s <name>\n", sub)
+			os.Exit(1)
+		}
+		if err := installer.SetPinned(args[0], sub == "pin"); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		if sub == "pin" {
+			fmt.Printf("✓ Pinned %s\n", args[0])
+		} else {
+			fmt.Printf("✓ Unpinned %s, upgrade will now update it\n", args[0])
+		}
+
+	case "search":
+		idx, err := installer.FetchIndex(ctx, registry)
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		found := idx.Skills
+		if len(args) > 0 {
+			found = idx.Search(strings.Join(args, " "))
+		}
+		if len(found) == 0 {
+			fmt.Println("No matching skills.")
+			return true
+		}
+		installed, _ := installer.Installed()
+		sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+		for _, s := range found {
+			latest := "?"
+			if v := s.Latest(); v != nil {
+				latest = v.Version
+			}
+			mark := ""
+			if e, ok := installed[s.Name]; ok {
+				mark = fmt.Sprintf(" [installed %s]", e.Version)
+			}
+			fmt.Printf("  %-20s %-8s %s%s\n", s.Name, latest, s.Description, mark)
+		}
+
+	default:
+		return false
+	}
+	return true
+}
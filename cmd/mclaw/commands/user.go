@@ -0,0 +1,508 @@
+package commands
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/audit"
+	"github.com/ntminh611/mclaw/pkg/auth"
+	"github.com/ntminh611/mclaw/pkg/feedback"
+	"github.com/ntminh611/mclaw/pkg/inbox"
+	"github.com/ntminh611/mclaw/pkg/memory"
+	"github.com/ntminh611/mclaw/pkg/session"
+)
+
+// userData is everything stored about one sender.
+type userData struct {
+	ID           string
+	Sessions     []*session.Session
+	Archived     map[string]bool // session keys that live in the archive
+	Memories     map[string][]memory.MemoryItem
+	Files        []string         // media and saved inbound files referenced in sessions, and inbox files
+	Inbox        []*inbox.Item    // files the sender sent to the inbox, with their metadata
+	Grants       []auth.Grant     // roles given to the sender with /authorize
+	Usage        []auth.Usage     // today's quota counters
+	Audit        []audit.Entry    // the sender's tool calls
+	Feedback     []feedback.Entry // reactions by the sender or in their direct chats
+	inbox        *inbox.Inbox
+	authz        *auth.Authorizer
+	auditPath    string
+	feedbackPath string
+	sm           *session.SessionManager
+	memStore     *memory.MemoryStore
+	memoryIDs    []string
+}
+
+// RunUser handles `mclaw user <export|purge> <id>`.
+func RunUser() {
+	if len(os.Args) < 4 {
+		userHelp()
+		return
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	dataDir := filepath.Dir(cfg.WorkspacePath())
+	dbPath := filepath.Join(dataDir, "memory.db")
+
+	sm, err := session.NewSQLiteSessionManager(dbPath, filepath.Join(dataDir, "sessions"))
+	if err != nil {
+		fmt.Printf("Error opening session database: %v\n", err)
+		os.Exit(1)
+	}
+	defer sm.Close()
+	sm.SetArchiveDir(filepath.Join(dataDir, "sessions_archive"))
+
+	memStore, err := memory.NewMemoryStore(dbPath)
+	if err != nil {
+		fmt.Printf("Error opening memory database: %v\n", err)
+		os.Exit(1)
+	}
+	defer memStore.Close()
+
+	in := inbox.New(filepath.Join(cfg.WorkspacePath(), "inbox"), 0)
+	data, err := collectUserData(os.Args[3], sm, memStore, in, []string{
+		filepath.Join(os.TempDir(), "mclaw_media"),
+		filepath.Join(cfg.WorkspacePath(), "inbound"),
+		in.Dir(),
+	})
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		os.Exit(1)
+	}
+	authz, err := auth.New(cfg, filepath.Join(dataDir, "auth.json"))
+	if err != nil {
+		fmt.Printf("Error reading access grants: %v\n", err)
+		os.Exit(1)
+	}
+	data.addAuth(authz)
+	if err := data.addAudit(filepath.Join(dataDir, audit.FileName)); err != nil {
+		fmt.Printf("Error reading the audit log: %v\n", err)
+		os.Exit(1)
+	}
+	if err := data.addFeedback(filepath.Join(dataDir, feedback.FileName)); err != nil {
+		fmt.Printf("Error reading the feedback log: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "export":
+		userExport(data, os.Args[4:])
+	case "purge":
+		userPurge(data, os.Args[4:])
+	default:
+		fmt.Printf("Unknown user command: %s\n", os.Args[2])
+		userHelp()
+	}
+}
+
+func userHelp() {
+	fmt.Println("\nUser commands:")
+	fmt.Println("  export <id> [-o file]         Export all data stored about a sender as a zip")
+	fmt.Println("  purge <id> [--yes]            Permanently delete all data stored about a sender")
+	fmt.Println()
+	fmt.Println("<id> is the sender ID, e.g. a Telegram user ID. Sessions are matched by")
+	fmt.Println("direct chats with that ID; memories, access grants, quota usage, audit")
+	fmt.Println("entries and reactions by the sender's user ID; inbox files by the sender")
+	fmt.Println("recorded with them.")
+}
+
+// collectUserData gathers the sessions, memories and files belonging to id.
+// fileDirs are the directories whose files may be referenced from
+// transcripts; inbox files are matched by the sender in their metadata.
+func collectUserData(id string, sm *session.SessionManager, memStore *memory.MemoryStore, in *inbox.Inbox, fileDirs []string) (*userData, error) {
+	// Accept "123|username" as well as "123"
+	bare, _, _ := strings.Cut(id, "|")
+	data := &userData{
+		ID:       bare,
+		Archived: make(map[string]bool),
+		Memories: make(map[string][]memory.MemoryItem),
+		inbox:    in,
+		sm:       sm,
+		memStore: memStore,
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range sm.ListKeys() {
+		if !seen[key] && sessionBelongsTo(key, bare) {
+			seen[key] = true
+			if s, ok := sm.Get(key); ok {
+				data.Sessions = append(data.Sessions, s)
+			}
+		}
+	}
+	for _, key := range sm.ArchivedKeys() {
+		if !seen[key] && sessionBelongsTo(key, bare) {
+			seen[key] = true
+			if s, ok := sm.LoadArchived(key); ok {
+				data.Sessions = append(data.Sessions, s)
+				data.Archived[key] = true
+			}
+		}
+	}
+	sort.Slice(data.Sessions, func(i, j int) bool { return data.Sessions[i].Key < data.Sessions[j].Key })
+
+	ids, err := memStore.MatchUserIDs(bare)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up memories: %w", err)
+	}
+	data.memoryIDs = ids
+	for _, userID := range ids {
+		items, err := memStore.GetByUser(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read memories: %w", err)
+		}
+		data.Memories[userID] = items
+	}
+
+	data.Files = referencedFiles(data.Sessions, fileDirs)
+	items, err := in.FromSender(bare)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the inbox: %w", err)
+	}
+	data.Inbox = items
+	for _, item := range items {
+		if !slices.Contains(data.Files, item.Path) {
+			data.Files = append(data.Files, item.Path)
+		}
+	}
+	sort.Strings(data.Files)
+	return data, nil
+}
+
+// addAuth adds the sender's access grants and quota usage.
+func (d *userData) addAuth(authz *auth.Authorizer) {
+	d.authz = authz
+	d.Grants = authz.UserGrants(d.ID)
+	d.Usage = authz.UserUsage(d.ID)
+}
+
+// addAudit adds the sender's entries in the audit log at path.
+func (d *userData) addAudit(path string) error {
+	entries, err := audit.Query(path, audit.Filter{SenderID: d.ID})
+	if err != nil {
+		return err
+	}
+	d.auditPath = path
+	d.Audit = entries
+	return nil
+}
+
+// addFeedback adds the reactions the sender gave, and those in their direct
+// chats, from the feedback log at path.
+func (d *userData) addFeedback(path string) error {
+	entries, err := feedback.FromSender(path, d.ID)
+	if err != nil {
+		return err
+	}
+	d.feedbackPath = path
+	d.Feedback = entries
+	return nil
+}
+
+// sessionBelongsTo reports whether a "channel:chatID" key, or a topic in
+// it, is a direct chat with the given sender.
+func sessionBelongsTo(key, id string) bool {
+	chatKey, _ := session.SplitTopic(key)
+	_, chatID, ok := strings.Cut(chatKey, ":")
+	return ok && chatID == id
+}
+
+// referencedFiles returns existing files under dirs that are mentioned in the
+// sessions' history or transcript, e.g. "[image: /tmp/mclaw_media/x.jpg]".
+func referencedFiles(sessions []*session.Session, dirs []string) []string {
+	var patterns []*regexp.Regexp
+	for _, dir := range dirs {
+		patterns = append(patterns, regexp.MustCompile(regexp.QuoteMeta(dir+string(filepath.Separator))+`[^\s\]\)"']+`))
+	}
+
+	found := make(map[string]bool)
+	scan := func(text string) {
+		for _, re := range patterns {
+			for _, path := range re.FindAllString(text, -1) {
+				path = strings.TrimRight(path, ".,;")
+				if info, err := os.Stat(path); err == nil && !info.IsDir() {
+					found[path] = true
+				}
+			}
+		}
+	}
+
+	for _, s := range sessions {
+		for _, m := range s.Messages {
+			scan(m.Content)
+		}
+		for _, e := range s.Transcript {
+			scan(e.Content)
+		}
+	}
+
+	files := make([]string, 0, len(found))
+	for path := range found {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func (d *userData) memoryCount() int {
+	n := 0
+	for _, items := range d.Memories {
+		n += len(items)
+	}
+	return n
+}
+
+func (d *userData) empty() bool {
+	return len(d.Sessions) == 0 && len(d.memoryIDs) == 0 && len(d.Files) == 0 && len(d.Grants) == 0 && len(d.Usage) == 0 && len(d.Audit) == 0 && len(d.Feedback) == 0
+}
+
+func userExport(data *userData, args []string) {
+	output := fmt.Sprintf("mclaw-user-%s-%s.zip", data.ID, time.Now().Format("20060102"))
+	for i := 0; i < len(args); i++ {
+		if (args[i] == "-o" || args[i] == "--output") && i+1 < len(args) {
+			output = args[i+1]
+			i++
+		}
+	}
+
+	if data.empty() {
+		fmt.Printf("No data stored for user %s.\n", data.ID)
+		return
+	}
+
+	if err := writeUserExport(data, output); err != nil {
+		os.Remove(output)
+		fmt.Printf("✗ Export failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Exported %d sessions, %d memories and %d files for user %s to %s\n",
+		len(data.Sessions), data.memoryCount(), len(data.Files), data.ID, output)
+}
+
+func writeUserExport(data *userData, output string) error {
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	writeJSON := func(name string, v interface{}) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	manifest := map[string]interface{}{
+		"user_id":     data.ID,
+		"exported_at": time.Now().Format(time.RFC3339),
+		"sessions":    len(data.Sessions),
+		"memories":    data.memoryCount(),
+		"files":       len(data.Files),
+		"grants":      len(data.Grants),
+		"usage":       len(data.Usage),
+		"audit":       len(data.Audit),
+		"feedback":    len(data.Feedback),
+	}
+	if err := writeJSON("manifest.json", manifest); err != nil {
+		return err
+	}
+
+	for _, s := range data.Sessions {
+		name := strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(s.Key)
+		js, err := session.ExportJSON(s)
+		if err != nil {
+			return err
+		}
+		for file, content := range map[string][]byte{
+			"sessions/" + name + ".json": js,
+			"sessions/" + name + ".md":   []byte(session.ExportMarkdown(s)),
+		} {
+			w, err := zw.Create(file)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(content); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(data.Memories) > 0 {
+		if err := writeJSON("memories.json", data.Memories); err != nil {
+			return err
+		}
+	}
+	if len(data.Inbox) > 0 {
+		if err := writeJSON("inbox.json", data.Inbox); err != nil {
+			return err
+		}
+	}
+	if len(data.Grants) > 0 {
+		if err := writeJSON("grants.json", data.Grants); err != nil {
+			return err
+		}
+	}
+	if len(data.Usage) > 0 {
+		if err := writeJSON("usage.json", data.Usage); err != nil {
+			return err
+		}
+	}
+	if len(data.Audit) > 0 {
+		if err := writeJSON("audit.json", data.Audit); err != nil {
+			return err
+		}
+	}
+	if len(data.Feedback) > 0 {
+		if err := writeJSON("feedback.json", data.Feedback); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range data.Files {
+		if err := addZipFile(zw, "files/"+filepath.Base(path), path); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func addZipFile(zw *zip.Writer, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+func userPurge(data *userData, args []string) {
+	confirmed := false
+	for _, arg := range args {
+		if arg == "-y" || arg == "--yes" {
+			confirmed = true
+		}
+	}
+
+	if data.empty() {
+		fmt.Printf("No data stored for user %s.\n", data.ID)
+		return
+	}
+
+	fmt.Printf("This will permanently delete for user %s:\n", data.ID)
+	fmt.Printf("  %d sessions (%d archived)\n", len(data.Sessions), len(data.Archived))
+	fmt.Printf("  %d memories\n", data.memoryCount())
+	fmt.Printf("  %d media and inbound files\n", len(data.Files))
+	fmt.Printf("  %d access grants\n", len(data.Grants))
+	fmt.Printf("  %d quota usage records\n", len(data.Usage))
+	fmt.Printf("  %d audit log entries\n", len(data.Audit))
+	fmt.Printf("  %d reactions\n", len(data.Feedback))
+
+	if !confirmed {
+		fmt.Printf("\nType the user ID to confirm: ")
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(line) != data.ID {
+			fmt.Println("Aborted.")
+			return
+		}
+	}
+
+	failed := false
+	for _, s := range data.Sessions {
+		if err := data.sm.Delete(s.Key); err != nil {
+			fmt.Printf("✗ Failed to delete session %s: %v\n", s.Key, err)
+			failed = true
+		}
+	}
+
+	memories := 0
+	for _, userID := range data.memoryIDs {
+		n, err := data.memStore.PurgeUser(userID)
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			failed = true
+		}
+		memories += n
+	}
+
+	for _, path := range data.Files {
+		var err error
+		if filepath.Dir(path) == data.inbox.Dir() {
+			err = data.inbox.Remove(filepath.Base(path)) // and its metadata
+		} else {
+			err = os.Remove(path)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Printf("✗ Failed to delete %s: %v\n", path, err)
+			failed = true
+		}
+	}
+
+	if data.authz != nil {
+		if _, err := data.authz.RevokeUser(data.ID); err != nil {
+			fmt.Printf("✗ Failed to revoke access grants: %v\n", err)
+			failed = true
+		}
+		if _, err := data.authz.ForgetUsage(data.ID); err != nil {
+			fmt.Printf("✗ Failed to delete quota usage: %v\n", err)
+			failed = true
+		}
+	}
+
+	if data.auditPath != "" {
+		if _, err := audit.PurgeSender(data.auditPath, data.ID); err != nil {
+			fmt.Printf("✗ Failed to delete audit log entries: %v\n", err)
+			failed = true
+		}
+	}
+	if data.feedbackPath != "" {
+		if _, err := feedback.PurgeSender(data.feedbackPath, data.ID); err != nil {
+			fmt.Printf("✗ Failed to delete reactions: %v\n", err)
+			failed = true
+		}
+	}
+
+	// Sessions and memories share one database; rewrite it so deleted rows
+	// cannot be recovered from free pages.
+	if err := data.sm.Vacuum(); err != nil {
+		fmt.Printf("✗ Failed to compact database: %v\n", err)
+		failed = true
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Purged %d sessions, %d memories, %d files, %d access grants, %d usage records, %d audit entries and %d reactions for user %s\n",
+		len(data.Sessions), memories, len(data.Files), len(data.Grants), len(data.Usage), len(data.Audit), len(data.Feedback), data.ID)
+}
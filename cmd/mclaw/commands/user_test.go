@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/audit"
+	"github.com/ntminh611/mclaw/pkg/auth"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/feedback"
+	"github.com/ntminh611/mclaw/pkg/inbox"
+	"github.com/ntminh611/mclaw/pkg/memory"
+	"github.com/ntminh611/mclaw/pkg/session"
+)
+
+// openTestStores opens the session and memory stores in dir.
+func openTestStores(t *testing.T, dir string) (*session.SessionManager, *memory.MemoryStore) {
+	t.Helper()
+	dbPath := filepath.Join(dir, "memory.db")
+	sm, err := session.NewSQLiteSessionManager(dbPath, filepath.Join(dir, "sessions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sm.Close() })
+	memStore, err := memory.NewMemoryStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { memStore.Close() })
+	return sm, memStore
+}
+
+func TestPurgeInboxFiles(t *testing.T) {
+	dir := t.TempDir()
+	sm, memStore := openTestStores(t, dir)
+
+	in := inbox.New(filepath.Join(dir, "workspace", "inbox"), 0)
+	receive := func(name, sender string) *inbox.Item {
+		src := filepath.Join(dir, "download-"+name)
+		if err := os.WriteFile(src, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		item, err := in.Ingest(src, name, "telegram", "42", sender)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return item
+	}
+	mine := receive("passport.jpg", "42|alice")
+	other := receive("menu.pdf", "7|bob")
+
+	data, err := collectUserData("42", sm, memStore, in, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Inbox) != 1 || len(data.Files) != 1 || data.Files[0] != mine.Path {
+		t.Fatalf("expected only the sender's inbox file, got %v", data.Files)
+	}
+
+	userPurge(data, []string{"--yes"})
+
+	if _, err := os.Stat(mine.Path); !os.IsNotExist(err) {
+		t.Error("expected the sender's file to be deleted")
+	}
+	if items, _ := in.FromSender("42"); len(items) != 0 {
+		t.Errorf("expected no metadata left for the sender, got %+v", items)
+	}
+	if _, err := os.Stat(filepath.Join(in.Dir(), ".meta", mine.Name+".json")); !os.IsNotExist(err) {
+		t.Error("expected the metadata sidecar to be deleted")
+	}
+	if _, err := os.Stat(other.Path); err != nil {
+		t.Errorf("expected other senders' files kept: %v", err)
+	}
+}
+
+func TestExportAndPurgeRecords(t *testing.T) {
+	dir := t.TempDir()
+	sm, memStore := openTestStores(t, dir)
+	in := inbox.New(filepath.Join(dir, "workspace", "inbox"), 0)
+	authPath := filepath.Join(dir, "auth.json")
+	authz, err := auth.New(config.DefaultConfig(), authPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authz.Grant("telegram", "42", auth.RoleGuest, "telegram:1")
+	authz.Grant("telegram", "7", auth.RoleGuest, "telegram:1")
+	authz.RecordTokens("telegram", "42|alice", 500, time.Now())
+	authz.RecordTokens("telegram", "7", 100, time.Now())
+	auditPath := filepath.Join(dir, audit.FileName)
+	auditLog, err := audit.Open(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditLog.Append(audit.Entry{Channel: "telegram", SenderID: "42|alice", Tool: "exec", Outcome: audit.OutcomeOK})
+	auditLog.Append(audit.Entry{Channel: "telegram", SenderID: "7|bob", Tool: "exec", Outcome: audit.OutcomeOK})
+	auditLog.Close()
+	feedbackPath := filepath.Join(dir, feedback.FileName)
+	feedbackLog, err := feedback.Open(feedbackPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feedbackLog.Append(feedback.Entry{Channel: "telegram", ChatID: "42", SenderID: "42|alice", Emoji: "👎", Reply: "mine"})
+	feedbackLog.Append(feedback.Entry{Channel: "telegram", ChatID: "7", SenderID: "7|bob", Emoji: "👍", Reply: "theirs"})
+	feedbackLog.Close()
+
+	data, err := collectUserData("42", sm, memStore, in, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.addAuth(authz)
+	if err := data.addAudit(auditPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := data.addFeedback(feedbackPath); err != nil {
+		t.Fatal(err)
+	}
+
+	export := filepath.Join(dir, "export.zip")
+	if err := writeUserExport(data, export); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.OpenReader(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	zr.Close()
+	for _, name := range []string{"grants.json", "usage.json", "audit.json", "feedback.json"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("expected %s in the export, got %v", name, names)
+		}
+	}
+
+	userPurge(data, []string{"--yes"})
+
+	reloaded, err := auth.New(config.DefaultConfig(), authPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grants := reloaded.Grants(); len(grants) != 1 || grants[0].UserID != "7" {
+		t.Errorf("expected only other senders' grants kept, got %+v", grants)
+	}
+	if usage := reloaded.UserUsage("42"); len(usage) != 0 {
+		t.Errorf("expected the sender's quota usage deleted, got %+v", usage)
+	}
+	if usage := reloaded.UserUsage("7"); len(usage) != 1 {
+		t.Errorf("expected other senders' quota usage kept, got %+v", usage)
+	}
+	if entries, _ := audit.Query(auditPath, audit.Filter{}); len(entries) != 1 || entries[0].SenderID != "7|bob" {
+		t.Errorf("expected only other senders' audit entries kept, got %+v", entries)
+	}
+	if entries, _ := feedback.FromSender(feedbackPath, "7"); len(entries) != 1 {
+		t.Errorf("expected other senders' reactions kept, got %+v", entries)
+	}
+	if entries, _ := feedback.FromSender(feedbackPath, "42"); len(entries) != 0 {
+		t.Errorf("expected the sender's reactions deleted, got %+v", entries)
+	}
+}
@@ -9,11 +9,17 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ntminh611/mclaw/cmd/mclaw/commands"
+	"github.com/ntminh611/mclaw/pkg/config"
 )
 
 func main() {
+	if err := parseGlobalFlags(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 	if len(os.Args) < 2 {
 		printHelp()
 		os.Exit(1)
@@ -34,6 +40,22 @@ func main() {
 		commands.RunCron()
 	case "skills":
 		commands.RunSkills()
+	case "sessions":
+		commands.RunSessions()
+	case "user":
+		commands.RunUser()
+	case "browser":
+		commands.RunBrowser()
+	case "model":
+		commands.RunModel()
+	case "config":
+		commands.RunConfig()
+	case "secrets":
+		commands.RunSecrets()
+	case "service":
+		commands.RunService()
+	case "audit":
+		commands.RunAudit()
 	case "version", "--version", "-v":
 		fmt.Printf("%s mclaw v%s\n", commands.Logo, commands.Version)
 	default:
@@ -53,5 +75,54 @@ func printHelp() {
 	fmt.Println("  status      Show mclaw status")
 	fmt.Println("  cron        Manage scheduled tasks")
 	fmt.Println("  skills      Manage skills (install, list, remove)")
+	fmt.Println("  sessions    List and export conversation transcripts")
+	fmt.Println("  user        Export or purge all data stored about a user")
+	fmt.Println("  browser     Sign in to sites for the browser tool")
+	fmt.Println("  model       List models or switch the default one")
+	fmt.Println("  config      Get, set or validate config values")
+	fmt.Println("  secrets     Keep API keys and tokens out of the config file")
+	fmt.Println("  service     Install and control mclaw as a systemd/launchd service")
+	fmt.Println("  audit       List tool calls the agent has made")
 	fmt.Println("  version     Show version information")
+	fmt.Println()
+	fmt.Println("Global flags:")
+	fmt.Println("  --config <file>    Use this config file (env MCLAW_CONFIG)")
+	fmt.Println("  --profile <name>   Run a separate instance from profiles/<name> (env MCLAW_PROFILE)")
+	fmt.Println("  --dry-run          Echo instead of calling the model and skip tool calls (env MCLAW_DRY_RUN)")
+}
+
+// parseGlobalFlags removes --config, --profile and --dry-run from os.Args,
+// wherever they appear, and exports them so every command and the config
+// loader agree on which instance they work on and how.
+func parseGlobalFlags() error {
+	args := []string{os.Args[0]}
+	for i := 1; i < len(os.Args); i++ {
+		name, value, hasValue := strings.Cut(os.Args[i], "=")
+		if name == "--dry-run" {
+			os.Setenv(config.DryRunEnv, "true")
+			continue
+		}
+		if name != "--config" && name != "--profile" {
+			args = append(args, os.Args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(os.Args) {
+				return fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = os.Args[i]
+		}
+		if name == "--config" {
+			os.Setenv(config.ConfigEnv, value)
+		} else {
+			os.Setenv(config.ProfileEnv, value)
+		}
+	}
+	os.Args = args
+
+	if name := config.Profile(); name != "" {
+		return config.ValidateProfile(name)
+	}
+	return nil
 }
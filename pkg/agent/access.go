@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/auth"
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// GetAuthorizer returns the roles and grants shared with the channels,
+// which use it for allow checks and /authorize.
+func (al *AgentLoop) GetAuthorizer() *auth.Authorizer {
+	return al.auth
+}
+
+// overQuota checks the sender's limits before the turn calls the LLM and
+// returns the reply to send instead when one is reached.
+func (al *AgentLoop) overQuota(msg bus.InboundMessage) string {
+	now := time.Now()
+	err := al.auth.CheckQuota(msg.Channel, msg.SenderID, tools.IsGroupChat(msg.Metadata), now)
+	var quota *auth.QuotaError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &quota) && quota.Limit == "per_minute":
+		wait := quota.ResetAt.Sub(now).Round(time.Second)
+		if wait < time.Second {
+			wait = time.Second
+		}
+		return fmt.Sprintf("🐢 That's a lot of messages at once — give me %s and try again.", wait)
+	case errors.As(err, &quota):
+		return fmt.Sprintf("⏳ You've used today's %s allowance. It resets at %s — see you then!",
+			quota.Limit, quota.ResetAt.Format("15:04"))
+	default:
+		return "🔒 You're not authorized to use this assistant."
+	}
+}
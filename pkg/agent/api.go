@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/openaiapi"
+)
+
+// newAPIServer sets up the OpenAI-compatible endpoint, or returns nil when
+// it is disabled. Requests run as interactive turns on the "api" channel;
+// a request without a session of its own leaves nothing behind.
+func newAPIServer(al *AgentLoop) *openaiapi.Server {
+	if !al.cfg.API.Enabled {
+		return nil
+	}
+	return openaiapi.NewServer(al.cfg.API, func(ctx context.Context, prompt, sessionKey string, keep bool) (*openaiapi.Result, error) {
+		if !keep {
+			defer func() {
+				if err := al.sessions.Delete(sessionKey); err != nil {
+					logger.WarnC("agent", fmt.Sprintf("Failed to delete one-off API session %s: %v", sessionKey, err))
+				}
+			}()
+		}
+		result, err := al.runTurn(ctx, bus.InboundMessage{
+			Channel:    "api",
+			SenderID:   "api",
+			ChatID:     strings.TrimPrefix(sessionKey, "api:"),
+			Content:    prompt,
+			SessionKey: sessionKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &openaiapi.Result{
+			Content:          result.Content,
+			PromptTokens:     result.Usage.PromptTokens,
+			CompletionTokens: result.Usage.CompletionTokens,
+		}, nil
+	})
+}
+
+// runAPIServer serves the API until ctx is cancelled.
+func (al *AgentLoop) runAPIServer(ctx context.Context) {
+	if err := al.api.Run(ctx); err != nil {
+		logger.ErrorC("agent", fmt.Sprintf("API server stopped: %v", err))
+	}
+}
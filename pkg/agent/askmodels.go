@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/budget"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/providers"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// newAskModelsTool creates ask_models for the models in
+// tools.ask_models.models. Their calls count towards the daily budget and
+// answers are merged by the agent's own model.
+func newAskModelsTool(cfg *config.Config, switcher *ModelSwitcher, spend *budget.Tracker) *tools.AskModelsTool {
+	var clients []tools.ModelClient
+	for _, name := range cfg.Tools.AskModels.Models {
+		model := cfg.ResolveModel(name)
+		provider, err := providers.CreateProviderForModel(cfg, model)
+		if err != nil {
+			logger.WarnC("agent", fmt.Sprintf("ask_models: skipping %s: %v", name, err))
+			continue
+		}
+		clients = append(clients, tools.ModelClient{Name: name, Model: model, Provider: spend.Meter(provider)})
+	}
+
+	merge := func(ctx context.Context, prompt string) (string, error) {
+		resp, err := switcher.Chat(ctx, []providers.Message{{Role: "user", Content: prompt}}, nil, map[string]interface{}{
+			"max_tokens":  2048,
+			"temperature": 0.3,
+		})
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+	}
+	return tools.NewAskModelsTool(clients, merge, time.Duration(cfg.Tools.AskModels.TimeoutSeconds)*time.Second)
+}
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ntminh611/mclaw/pkg/bookmarks"
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// newBookmarkService opens the read-later list, reading saved pages with
+// webFetch. Returns nil when bookmarks are disabled or the store is
+// unavailable.
+func newBookmarkService(cfg *config.Config, mb *bus.MessageBus, webFetch *tools.WebFetchTool, dbPath string) *bookmarks.Service {
+	if !cfg.Bookmarks.Enabled {
+		return nil
+	}
+	store, err := bookmarks.NewStore(dbPath)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Bookmark store unavailable, bookmarks disabled: %v", err))
+		return nil
+	}
+	svc := bookmarks.NewService(store, func(channel, chatID, content string) {
+		mb.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: content, Proactive: true})
+	})
+	svc.SetFetcher(func(ctx context.Context, url string) (string, error) {
+		return fetchPageText(ctx, webFetch, url)
+	})
+	day, err := bookmarks.ParseWeekday(cfg.Bookmarks.DigestDay)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("bookmarks.digest_day: %v, using sunday", err))
+	}
+	svc.SetDigest(cfg.Bookmarks.WeeklyDigest, day, cfg.Bookmarks.DigestHour)
+	return svc
+}
+
+// fetchPageText reads url through the web_fetch tool and returns the
+// extracted text.
+func fetchPageText(ctx context.Context, webFetch *tools.WebFetchTool, url string) (string, error) {
+	out, err := webFetch.Execute(ctx, map[string]interface{}{"url": url, "maxChars": 8000.0})
+	if err != nil {
+		return "", err
+	}
+	var page struct {
+		Status int    `json:"status"`
+		Text   string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(out), &page); err != nil {
+		return "", fmt.Errorf("%s", out)
+	}
+	if page.Status >= 400 {
+		return "", fmt.Errorf("HTTP %d", page.Status)
+	}
+	return page.Text, nil
+}
+
+// EnableBookmarks schedules the weekly bookmark digest on cs.
+func (al *AgentLoop) EnableBookmarks(cs *cron.CronService) {
+	if al.bookmarks == nil {
+		return
+	}
+	if err := al.bookmarks.Attach(cs); err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Failed to schedule the bookmark digest: %v", err))
+	}
+}
+
+// GetBookmarks returns the bookmark service, or nil if bookmarks are
+// disabled.
+func (al *AgentLoop) GetBookmarks() *bookmarks.Service {
+	return al.bookmarks
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Below these the model is told to stop calling tools and answer
+	wrapUpIterations = 2
+	wrapUpTime       = 90 * time.Second
+)
+
+// channelFormatting describes how replies are rendered on each channel.
+var channelFormatting = map[string]string{
+	"telegram": "Replies are shown in Telegram: Markdown is converted to Telegram HTML (bold, italic, code, links); tables are not supported. Messages over 4000 characters are split, so prefer concise answers.",
+	"discord":  "Replies are shown in Discord Markdown. Messages over 2000 characters are split, so prefer concise answers.",
+	"whatsapp": "Replies are shown in WhatsApp: use *bold* and _italic_ only; no headings, tables or links with labels.",
+	"feishu":   "Replies are shown in Feishu as plain text; avoid heavy Markdown.",
+	"cli":      "Replies are shown in a terminal as plain text; light Markdown is fine.",
+}
+
+// spokenReplies is added when the user is in a voice conversation.
+const spokenReplies = "The user is talking to you by voice and hears your reply read aloud. Answer in a few short, natural sentences; no lists, tables, code or links unless asked. Ask at most one question back."
+
+// turnBudget tracks the remaining resources of one agent turn, so the model
+// can wrap up gracefully instead of running into hard loop limits.
+type turnBudget struct {
+	maxIterations int
+	maxToolOnly   int
+	deadline      time.Time // zero = no time limit
+	channel       string
+	spoken        bool // voice conversation: replies are read aloud
+}
+
+// section renders the dynamic "## Turn Budget" system-prompt section for the
+// given (1-based) iteration.
+func (b turnBudget) section(iteration, toolOnly int, now time.Time) string {
+	left := b.maxIterations - iteration + 1
+	if b.maxToolOnly > 0 && b.maxToolOnly-toolOnly < left {
+		left = b.maxToolOnly - toolOnly
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Turn Budget\n")
+	sb.WriteString(fmt.Sprintf("- LLM calls left this turn (including this one): %d\n", left))
+
+	wrapUp := left <= wrapUpIterations
+	if !b.deadline.IsZero() {
+		remaining := b.deadline.Sub(now).Round(time.Second)
+		sb.WriteString(fmt.Sprintf("- Time left: %s\n", remaining))
+		wrapUp = wrapUp || remaining <= wrapUpTime
+	}
+
+	if wrapUp {
+		sb.WriteString("\nThe budget is nearly exhausted. Do not start new tool calls; give the user your best answer now, noting anything left unfinished.\n")
+	} else {
+		sb.WriteString("\nPlan tool use to finish within this budget.\n")
+	}
+
+	if f := channelFormatting[b.channel]; f != "" {
+		sb.WriteString("\n## Formatting\n" + f + "\n")
+	}
+	if b.spoken {
+		sb.WriteString("\n## Voice conversation\n" + spokenReplies + "\n")
+	}
+	return sb.String()
+}
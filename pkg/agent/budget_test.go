@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTurnBudgetSection(t *testing.T) {
+	now := time.Now()
+	b := turnBudget{maxIterations: 10, maxToolOnly: 5, deadline: now.Add(5 * time.Minute), channel: "telegram"}
+
+	s := b.section(1, 0, now)
+	if !strings.Contains(s, "LLM calls left this turn (including this one): 5") {
+		t.Errorf("expected tool-only limit to cap the budget, got:\n%s", s)
+	}
+	if !strings.Contains(s, "Time left: 5m0s") {
+		t.Errorf("expected time left, got:\n%s", s)
+	}
+	if strings.Contains(s, "nearly exhausted") {
+		t.Errorf("did not expect wrap-up hint early in the turn:\n%s", s)
+	}
+	if !strings.Contains(s, "## Formatting") {
+		t.Errorf("expected channel formatting hint, got:\n%s", s)
+	}
+	if strings.Contains(s, "## Voice conversation") {
+		t.Errorf("did not expect the voice hint outside voice mode:\n%s", s)
+	}
+	b.spoken = true
+	if s := b.section(1, 0, now); !strings.Contains(s, "## Voice conversation") {
+		t.Errorf("expected the voice hint in voice mode, got:\n%s", s)
+	}
+
+	if s := b.section(9, 0, now); !strings.Contains(s, "nearly exhausted") {
+		t.Errorf("expected wrap-up hint near the iteration limit, got:\n%s", s)
+	}
+	if s := b.section(1, 0, now.Add(4*time.Minute)); !strings.Contains(s, "nearly exhausted") {
+		t.Errorf("expected wrap-up hint near the deadline, got:\n%s", s)
+	}
+}
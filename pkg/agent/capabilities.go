@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// Images larger than this are left to the describe_image tool.
+const maxInlineImageSize = 5 << 20 // bytes
+
+// windowFor returns model's context window in tokens: the model registry's
+// value when it knows the model, else agents.defaults.max_tokens.
+func (al *AgentLoop) windowFor(model string) int {
+	if window := al.models.Lookup(model).ContextWindow; window > 0 {
+		return window
+	}
+	return al.contextWindow
+}
+
+// summaryWindow is the context window of the model summaries run on.
+func (al *AgentLoop) summaryWindow() int {
+	if al.summarizer != nil {
+		return al.windowFor(al.summaryModel)
+	}
+	return al.windowFor(al.switcher.CurrentModel())
+}
+
+// imageParts turns a message and the images sent with it into multimodal
+// content for a vision model. It returns nil when none of the media are
+// images small enough to send inline.
+func imageParts(text string, media []string) []providers.ContentPart {
+	var images []providers.ContentPart
+	for _, path := range media {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() > maxInlineImageSize {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.WarnC("agent", fmt.Sprintf("Failed to read image %s: %v", path, err))
+			continue
+		}
+		mimeType := http.DetectContentType(data)
+		if !strings.HasPrefix(mimeType, "image/") {
+			continue
+		}
+		images = append(images, providers.ContentPart{Type: "image_url", ImageURL: &providers.ImageURL{
+			URL: "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data),
+		}})
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	return append([]providers.ContentPart{{Type: "text", Text: text}}, images...)
+}
@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// maxCoalesceWindows caps how long a burst can keep extending the wait,
+// so a user typing steadily still gets an answer.
+const maxCoalesceWindows = 5
+
+// inboundQueue hands the agent loop one turn at a time. With a coalesce
+// window, messages a user sends in quick succession ("hey", "can you",
+// "check the logs") become a single turn. Each session's burst has its own
+// window, so a chat that is still typing never holds up turns for other
+// sessions.
+type inboundQueue struct {
+	bus    *bus.MessageBus
+	window func() time.Duration
+	ready  [][]bus.InboundMessage // turns ready to run, oldest first
+	open   map[string]*burst      // bursts still collecting, by session key
+}
+
+// burst is a session's messages waiting for the sender to pause.
+type burst struct {
+	parts    []bus.InboundMessage
+	deadline time.Time // when the burst closes unless another part arrives
+	limit    time.Time // how far new parts can push the deadline
+}
+
+func newInboundQueue(mb *bus.MessageBus, window func() time.Duration) *inboundQueue {
+	return &inboundQueue{bus: mb, window: window, open: make(map[string]*burst)}
+}
+
+// next returns the message for the next turn and the inbound messages it
+// was merged from, which must all be acknowledged once it is answered.
+func (q *inboundQueue) next(ctx context.Context) (bus.InboundMessage, []bus.InboundMessage, bool) {
+	for {
+		q.closeExpired(time.Now())
+		if len(q.ready) > 0 {
+			parts := q.ready[0]
+			q.ready = q.ready[1:]
+			return mergeMessages(parts), parts, true
+		}
+
+		// Wait for a message, or for the first open burst to close
+		waitCtx, cancel := ctx, func() {}
+		if b := q.earliest(); b != nil {
+			waitCtx, cancel = context.WithDeadline(ctx, b.deadline)
+		}
+		msg, ok := q.bus.ConsumeInbound(waitCtx)
+		cancel()
+		if !ok {
+			if ctx.Err() != nil {
+				return bus.InboundMessage{}, nil, false
+			}
+			continue
+		}
+		q.add(msg, time.Now())
+	}
+}
+
+// add files msg under its session: into the open burst it continues, as a
+// new burst, or straight into the ready turns. Anything else arriving in a
+// chat ends its open burst first, so turns in a chat keep their order.
+func (q *inboundQueue) add(msg bus.InboundMessage, now time.Time) {
+	key := msg.SessionKey
+	b, open := q.open[key]
+	if open && sameBurst(b.parts[0], msg) {
+		b.parts = append(b.parts, msg)
+		if b.deadline = now.Add(q.window()); b.deadline.After(b.limit) {
+			b.deadline = b.limit
+		}
+		return
+	}
+	if open {
+		q.ready = append(q.ready, b.parts)
+		delete(q.open, key)
+	}
+
+	window := q.window()
+	if window <= 0 || isCommand(msg) || msg.Priority == bus.PriorityBackground {
+		q.ready = append(q.ready, []bus.InboundMessage{msg})
+		return
+	}
+	q.open[key] = &burst{parts: []bus.InboundMessage{msg}, deadline: now.Add(window), limit: now.Add(window * maxCoalesceWindows)}
+}
+
+// closeExpired moves bursts whose window has passed to the ready turns, in
+// the order their windows ended.
+func (q *inboundQueue) closeExpired(now time.Time) {
+	for {
+		b := q.earliest()
+		if b == nil || b.deadline.After(now) {
+			return
+		}
+		q.ready = append(q.ready, b.parts)
+		delete(q.open, b.parts[0].SessionKey)
+	}
+}
+
+// earliest returns the open burst that closes first, or nil.
+func (q *inboundQueue) earliest() *burst {
+	var first *burst
+	for _, b := range q.open {
+		if first == nil || b.deadline.Before(first.deadline) {
+			first = b
+		}
+	}
+	return first
+}
+
+// sameBurst reports whether msg continues what first started: same
+// conversation, same sender and lane, and not a command.
+func sameBurst(first, msg bus.InboundMessage) bool {
+	return msg.SessionKey == first.SessionKey && msg.SenderID == first.SenderID &&
+		msg.Priority == first.Priority && !isCommand(msg)
+}
+
+func isCommand(msg bus.InboundMessage) bool {
+	return strings.HasPrefix(strings.TrimSpace(msg.Content), "/")
+}
+
+// mergeMessages joins a burst into one message. Text is kept in order,
+// media accumulates, and IDs and metadata come from the latest message.
+func mergeMessages(parts []bus.InboundMessage) bus.InboundMessage {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	merged := parts[len(parts)-1]
+	var texts []string
+	var media []string
+	for _, p := range parts {
+		if text := strings.TrimSpace(p.Content); text != "" {
+			texts = append(texts, text)
+		}
+		media = append(media, p.Media...)
+	}
+	merged.Content = strings.Join(texts, "\n\n")
+	merged.Media = media
+	return merged
+}
+
+// sessionLocks serializes turns per session key, so a cron job or API call
+// never runs a turn in a conversation that is already mid-turn.
+type sessionLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sessionLock
+}
+
+type sessionLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock blocks until the session is free and returns its unlock function.
+func (s *sessionLocks) lock(key string) func() {
+	s.mu.Lock()
+	if s.locks == nil {
+		s.locks = make(map[string]*sessionLock)
+	}
+	l, ok := s.locks[key]
+	if !ok {
+		l = &sessionLock{}
+		s.locks[key] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		s.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(s.locks, key)
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+func TestInboundQueueCoalescesBurst(t *testing.T) {
+	mb := bus.NewMessageBus()
+	q := newInboundQueue(mb, func() time.Duration { return 50 * time.Millisecond })
+
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "telegram:1", SenderID: "u1", Content: "hey", Media: []string{"a.jpg"}})
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "telegram:2", SenderID: "u2", Content: "other chat"})
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "telegram:1", SenderID: "u1", Content: "can you check the logs", Metadata: map[string]string{"message_id": "3"}})
+
+	// Each session's burst is its own turn
+	turns := make(map[string]bus.InboundMessage)
+	parts := make(map[string]int)
+	for i := 0; i < 2; i++ {
+		msg, p, ok := q.next(context.Background())
+		if !ok {
+			t.Fatal("expected a message")
+		}
+		turns[msg.SessionKey] = msg
+		parts[msg.SessionKey] = len(p)
+	}
+	msg := turns["telegram:1"]
+	if parts["telegram:1"] != 2 {
+		t.Fatalf("expected 2 merged parts, got %d", parts["telegram:1"])
+	}
+	if want := "hey\n\ncan you check the logs"; msg.Content != want {
+		t.Errorf("expected content %q, got %q", want, msg.Content)
+	}
+	if len(msg.Media) != 1 || msg.Metadata["message_id"] != "3" {
+		t.Errorf("expected media kept and latest metadata, got %v %v", msg.Media, msg.Metadata)
+	}
+	if other := turns["telegram:2"]; parts["telegram:2"] != 1 || other.Content != "other chat" {
+		t.Errorf("expected the other session alone, got %q (%d parts)", other.Content, parts["telegram:2"])
+	}
+}
+
+func TestInboundQueueDoesNotWaitOnOtherBursts(t *testing.T) {
+	mb := bus.NewMessageBus()
+	window := 100 * time.Millisecond
+	q := newInboundQueue(mb, func() time.Duration { return window })
+
+	// One user keeps typing for most of the burst limit
+	start := time.Now()
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "telegram:1", SenderID: "u1", Content: "part 0"})
+	go func() {
+		for i := 1; i <= 8; i++ {
+			time.Sleep(40 * time.Millisecond)
+			mb.PublishInbound(bus.InboundMessage{SessionKey: "telegram:1", SenderID: "u1", Content: fmt.Sprintf("part %d", i)})
+		}
+	}()
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "telegram:2", SenderID: "u2", Content: "/status"})
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "telegram:3", SenderID: "u3", Content: "hello"})
+
+	msg, _, _ := q.next(context.Background())
+	if msg.Content != "/status" || time.Since(start) > window/2 {
+		t.Errorf("expected the command right away, got %q after %v", msg.Content, time.Since(start))
+	}
+	msg, _, _ = q.next(context.Background())
+	if msg.Content != "hello" || time.Since(start) > 3*window {
+		t.Errorf("expected the other chat after its own window, got %q after %v", msg.Content, time.Since(start))
+	}
+	msg, parts, _ := q.next(context.Background())
+	if msg.SessionKey != "telegram:1" || len(parts) < 2 {
+		t.Errorf("expected the typing user's burst last, got %q (%d parts)", msg.Content, len(parts))
+	}
+}
+
+func TestInboundQueueKeepsCommandsSeparate(t *testing.T) {
+	mb := bus.NewMessageBus()
+	q := newInboundQueue(mb, func() time.Duration { return 20 * time.Millisecond })
+
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "s", SenderID: "u", Content: "summarize this"})
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "s", SenderID: "u", Content: "/new"})
+
+	msg, _, _ := q.next(context.Background())
+	if msg.Content != "summarize this" {
+		t.Errorf("expected plain message alone, got %q", msg.Content)
+	}
+	msg, _, _ = q.next(context.Background())
+	if msg.Content != "/new" {
+		t.Errorf("expected command as its own turn, got %q", msg.Content)
+	}
+}
+
+func TestInboundQueueWithoutWindow(t *testing.T) {
+	mb := bus.NewMessageBus()
+	q := newInboundQueue(mb, func() time.Duration { return 0 })
+
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "s", SenderID: "u", Content: "one"})
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "s", SenderID: "u", Content: "two"})
+
+	for _, want := range []string{"one", "two"} {
+		msg, parts, _ := q.next(context.Background())
+		if msg.Content != want || len(parts) != 1 {
+			t.Errorf("expected %q alone, got %q (%d parts)", want, msg.Content, len(parts))
+		}
+	}
+}
+
+func TestSessionLocksSerializePerKey(t *testing.T) {
+	var locks sessionLocks
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock("telegram:1")
+			defer unlock()
+			mu.Lock()
+			active++
+			if active > maxActive {
+				maxActive = active
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if maxActive != 1 {
+		t.Errorf("expected one turn at a time, saw %d concurrently", maxActive)
+	}
+	if len(locks.locks) != 0 {
+		t.Errorf("expected idle locks to be released, %d left", len(locks.locks))
+	}
+
+	// Different sessions do not block each other
+	unlock := locks.lock("a")
+	done := make(chan struct{})
+	go func() {
+		locks.lock("b")()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("expected another session to proceed while one is locked")
+	}
+	unlock()
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/contacts"
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// newContactService opens the contact book. Returns nil when contacts are
+// disabled or the store is unavailable.
+func newContactService(cfg *config.Config, mb *bus.MessageBus, dbPath string) *contacts.Service {
+	if !cfg.Contacts.Enabled {
+		return nil
+	}
+	store, err := contacts.NewStore(dbPath)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Contact store unavailable, contacts disabled: %v", err))
+		return nil
+	}
+	svc := contacts.NewService(store, func(channel, chatID, content string) {
+		mb.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: content, Proactive: true})
+	})
+	svc.SetBirthdayHour(cfg.Contacts.BirthdayHour)
+	return svc
+}
+
+// EnableContacts schedules the contacts' birthday greetings on cs.
+func (al *AgentLoop) EnableContacts(cs *cron.CronService) {
+	if al.contacts == nil {
+		return
+	}
+	if err := al.contacts.Attach(cs); err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Failed to load contact birthdays: %v", err))
+	}
+}
+
+// GetContacts returns the contact service, or nil if contacts are disabled.
+func (al *AgentLoop) GetContacts() *contacts.Service {
+	return al.contacts
+}
@@ -4,16 +4,22 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/ntminh611/mclaw/pkg/contacts"
 	"github.com/ntminh611/mclaw/pkg/memory"
 	"github.com/ntminh611/mclaw/pkg/providers"
+	"github.com/ntminh611/mclaw/pkg/session"
 	"github.com/ntminh611/mclaw/pkg/skills"
+	"github.com/ntminh611/mclaw/pkg/tools"
 )
 
 type ContextBuilder struct {
 	workspace    string
 	skillsLoader *skills.SkillsLoader
+	tools        *tools.ToolRegistry
+	contacts     *contacts.Service // nil when contacts are disabled
 }
 
 func NewContextBuilder(workspace string) *ContextBuilder {
@@ -24,6 +30,35 @@ func NewContextBuilder(workspace string) *ContextBuilder {
 	}
 }
 
+// SetToolRegistry lets the builder tell the model which tools are unavailable.
+func (cb *ContextBuilder) SetToolRegistry(registry *tools.ToolRegistry) {
+	cb.tools = registry
+}
+
+// SetContacts lets the builder recall the people a message mentions.
+func (cb *ContextBuilder) SetContacts(svc *contacts.Service) {
+	cb.contacts = svc
+}
+
+// PeopleSection lists the contacts of the chat mentioned in message, so
+// the model knows who "mom" or "Lan" is without a tool call.
+func (cb *ContextBuilder) PeopleSection(sessionKey, message string) string {
+	if cb.contacts == nil {
+		return ""
+	}
+	owner, _ := session.SplitTopic(sessionKey)
+	people := cb.contacts.Recall(owner, message)
+	if len(people) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## People Mentioned\nFrom the user's contact book (keep it current with the contacts tool):\n")
+	for _, c := range people {
+		sb.WriteString("- " + c.Describe() + "\n")
+	}
+	return sb.String()
+}
+
 func (cb *ContextBuilder) BuildSystemPrompt() string {
 	now := time.Now().Format("2006-01-02 15:04 (Monday)")
 	workspacePath, _ := filepath.Abs(filepath.Join(cb.workspace))
@@ -46,14 +81,8 @@ Your workspace is at: %s
 - Daily notes: %s/memory/2006-01-02.md
 - Custom skills: %s/skills/{skill-name}/SKILL.md
 
-## Weather Information
-When users ask about weather, use the web_fetch tool with wttr.in URLs:
-- Current weather: https://wttr.in/{city}?format=j1
-- Beijing: https://wttr.in/Beijing?format=j1
-- Shanghai: https://wttr.in/Shanghai?format=j1
-- New York: https://wttr.in/New_York?format=j1
-- London: https://wttr.in/London?format=j1
-- Tokyo: https://wttr.in/Tokyo?format=j1
+## Weather and Places
+For weather, use the geo tool's "weather" action with a place name, or with the coordinates of a location the user shared ("[location: lat, lon]" messages), so "what's the weather here" uses their pin.
 
 IMPORTANT: When responding to direct questions or conversations, reply directly with your text response.
 Only use the 'message' tool when you need to send a message to a specific chat channel (like WhatsApp).
@@ -85,7 +114,7 @@ func (cb *ContextBuilder) LoadBootstrapFiles() string {
 	return result
 }
 
-func (cb *ContextBuilder) BuildMessages(history []providers.Message, summary string, currentMessage string, media []string, memories []memory.SearchResult) []providers.Message {
+func (cb *ContextBuilder) BuildMessages(history []providers.Message, summary string, scratchpad string, pinned []string, currentMessage string, media []string, memories []memory.SearchResult) []providers.Message {
 	messages := []providers.Message{}
 
 	systemPrompt := cb.BuildSystemPrompt()
@@ -104,10 +133,27 @@ func (cb *ContextBuilder) BuildMessages(history []providers.Message, summary str
 		systemPrompt += "\n\n" + skillsContent
 	}
 
+	if cb.tools != nil {
+		if unavailable := cb.tools.UnavailableSummary(); unavailable != "" {
+			systemPrompt += "\n\n## Unavailable Tools\nThese tools are currently unavailable. Do not call them; use the suggested alternatives instead:\n" + unavailable
+		}
+	}
+
 	if summary != "" {
 		systemPrompt += "\n\n## Summary of Previous Conversation\n\n" + summary
 	}
 
+	if len(pinned) > 0 {
+		systemPrompt += "\n\n## Pinned Instructions\nThe user pinned these instructions. Always follow them:\n"
+		for _, p := range pinned {
+			systemPrompt += "- " + p + "\n"
+		}
+	}
+
+	if scratchpad != "" {
+		systemPrompt += "\n\n## Scratchpad\nYour working notes for this conversation (update them with the scratchpad tool):\n\n" + scratchpad
+	}
+
 	// Inject long-term memories from Mem0-lite
 	if len(memories) > 0 {
 		systemPrompt += "\n\n## Long-term Memories\nThe following are facts you remember about this user from previous conversations:\n"
@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/digest"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// EnableDigest registers the digest job handler with the cron service and
+// schedules the daily or weekly digest the config asks for.
+func (al *AgentLoop) EnableDigest(cs *cron.CronService) {
+	src := digest.Sources{
+		Stats: al.stats,
+		Jobs:  func() []cron.CronJob { return cs.ListJobs(true) },
+		Facts: al.learnedFacts,
+	}
+	cs.SetKindHandler(digest.JobKind, digest.Handler(src, al.bus))
+	al.digestCron = cs
+	al.scheduleDigest(al.cfg)
+}
+
+// scheduleDigest adds, updates or removes the digest job after the digest
+// settings changed.
+func (al *AgentLoop) scheduleDigest(cfg *config.Config) {
+	if al.digestCron == nil {
+		return
+	}
+	if err := digest.Schedule(al.digestCron, cfg, time.Now()); err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Digest not scheduled: %v", err))
+	}
+}
+
+// learnedFacts returns the memories extracted between since and until.
+func (al *AgentLoop) learnedFacts(since, until time.Time) []string {
+	if al.memory == nil {
+		return nil
+	}
+	items, err := al.memory.LearnedSince(since)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Digest could not read memories: %v", err))
+		return nil
+	}
+	var facts []string
+	for _, item := range items {
+		if item.CreatedAt.Before(until) {
+			facts = append(facts, item.Content)
+		}
+	}
+	return facts
+}
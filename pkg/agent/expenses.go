@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/expenses"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// newExpenseService opens the expense ledger. Returns nil when expenses
+// are disabled or the store is unavailable.
+func newExpenseService(cfg *config.Config, mb *bus.MessageBus, dbPath string) *expenses.Service {
+	if !cfg.Expenses.Enabled {
+		return nil
+	}
+	store, err := expenses.NewStore(dbPath)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Expense store unavailable, expenses disabled: %v", err))
+		return nil
+	}
+	svc := expenses.NewService(store, func(channel, chatID, content string) {
+		mb.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: content, Proactive: true})
+	})
+	svc.SetDefaults(cfg.Expenses.Currency, cfg.Expenses.AlertPercent, cfg.Expenses.MonthlyReport, cfg.Expenses.ReportHour)
+	return svc
+}
+
+// EnableExpenses schedules the monthly expense report on cs.
+func (al *AgentLoop) EnableExpenses(cs *cron.CronService) {
+	if al.expenses == nil {
+		return
+	}
+	if err := al.expenses.Attach(cs); err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Failed to schedule the expense report: %v", err))
+	}
+}
+
+// GetExpenses returns the expense service, or nil if expenses are disabled.
+func (al *AgentLoop) GetExpenses() *expenses.Service {
+	return al.expenses
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/feedback"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/memory"
+)
+
+// feedbackExcerptChars is how much of a prompt and reply the feedback log
+// keeps.
+const feedbackExcerptChars = 500
+
+func openFeedbackLog(dataDir string) *feedback.Log {
+	l, err := feedback.Open(filepath.Join(dataDir, feedback.FileName))
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Feedback log disabled: %v", err))
+		return nil
+	}
+	return l
+}
+
+// runFeedback records reactions to the assistant's messages until ctx ends.
+func (al *AgentLoop) runFeedback(ctx context.Context) {
+	for {
+		r, ok := al.bus.ConsumeReaction(ctx)
+		if !ok {
+			return
+		}
+		al.recordReaction(r)
+	}
+}
+
+// recordReaction logs a reaction with the turn it is about and counts it
+// in the daily stats. A strong negative reaction (👎, 💩, ...) is also kept
+// as a memory, so later answers to that user avoid the same style.
+func (al *AgentLoop) recordReaction(r bus.Reaction) {
+	sentiment, strong := feedback.Classify(r.Emoji)
+	sessionKey, prompt := al.findTurn(r)
+
+	if sentiment != feedback.Neutral {
+		al.stats.RecordReaction(sentiment == feedback.Positive, time.Now())
+	}
+	if al.feedback != nil {
+		err := al.feedback.Append(feedback.Entry{
+			Channel:    r.Channel,
+			ChatID:     r.ChatID,
+			SenderID:   r.SenderID,
+			SessionKey: sessionKey,
+			Emoji:      r.Emoji,
+			Sentiment:  sentiment,
+			Strong:     strong,
+			Prompt:     excerpt(prompt, feedbackExcerptChars, true),
+			Reply:      excerpt(r.Reply, feedbackExcerptChars, true),
+		})
+		if err != nil {
+			logger.WarnC("agent", fmt.Sprintf("Failed to log feedback: %v", err))
+		}
+	}
+	logger.InfoC("agent", fmt.Sprintf("Reaction %s (%s) on a reply in %s", r.Emoji, sentiment, sessionKey))
+
+	if strong && sentiment == feedback.Negative && al.memory != nil {
+		fact := fmt.Sprintf("User disliked an answer (reacted %s), so avoid this kind of reply: %q", r.Emoji, excerpt(r.Reply, 200, true))
+		if prompt != "" {
+			fact += fmt.Sprintf(" (it answered %q)", excerpt(prompt, 100, true))
+		}
+		go al.memory.Remember(r.SenderID, fact, memory.CategoryPreference, 0.7)
+	}
+}
+
+// findTurn finds the session holding the reply reacted to, looking in the
+// chat's active topic and then its main conversation, and returns it with
+// the user message the reply answered. Replies no longer in the history
+// are attributed to the active session with no prompt.
+func (al *AgentLoop) findTurn(r bus.Reaction) (string, string) {
+	chatKey := r.Channel + ":" + r.ChatID
+	active := al.sessions.ActiveKey(chatKey)
+	want := replyKey(r.Reply)
+	for _, key := range []string{active, chatKey} {
+		history := al.sessions.GetHistory(key)
+		for i := len(history) - 1; i >= 0; i-- {
+			if history[i].Role != "assistant" || replyKey(history[i].Content) != want {
+				continue
+			}
+			for j := i - 1; j >= 0; j-- {
+				if history[j].Role == "user" {
+					return key, history[j].Content
+				}
+			}
+			return key, ""
+		}
+	}
+	return active, ""
+}
+
+// replyKey is the start of a reply with whitespace collapsed, enough to
+// match a sent message to its history entry despite a usage footer.
+func replyKey(s string) string {
+	return excerpt(strings.Join(strings.Fields(s), " "), 120, true)
+}
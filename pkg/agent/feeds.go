@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/feeds"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/netguard"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// newFeedService opens the feed store and adds the subscriptions listed in
+// config. Returns nil when feeds are disabled or the store is unavailable.
+func newFeedService(cfg *config.Config, mb *bus.MessageBus, guard *netguard.Guard, dbPath string) *feeds.Service {
+	if !cfg.Feeds.Enabled {
+		return nil
+	}
+	store, err := feeds.NewStore(dbPath)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Feed store unavailable, feeds disabled: %v", err))
+		return nil
+	}
+
+	svc := feeds.NewService(store, guard.Client(time.Minute), func(channel, chatID, content string) {
+		mb.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: content, Proactive: true})
+	})
+	defaultInterval := time.Duration(cfg.Feeds.IntervalMinutes) * time.Minute
+	svc.SetDefaults(defaultInterval, cfg.Feeds.MaxItems)
+
+	for _, sub := range cfg.Feeds.Subscriptions {
+		if sub.URL == "" || sub.Channel == "" || sub.ChatID == "" {
+			logger.WarnC("agent", fmt.Sprintf("Skipping feed subscription without url, channel or chat_id: %+v", sub))
+			continue
+		}
+		interval := defaultInterval
+		if sub.IntervalMinutes > 0 {
+			interval = time.Duration(sub.IntervalMinutes) * time.Minute
+		}
+		if _, _, err := store.Subscribe(sub.URL, feeds.ChatKey(sub.Channel, sub.ChatID), interval); err != nil {
+			logger.WarnC("agent", fmt.Sprintf("Failed to add feed %s: %v", sub.URL, err))
+		}
+	}
+	return svc
+}
+
+// feedDigest summarizes new feed items, and saved bookmarks, with the
+// summary model.
+func (al *AgentLoop) feedDigest(ctx context.Context, prompt string) (string, error) {
+	if al.spend.Exceeded(time.Now()) {
+		return "", errOverBudget
+	}
+	resp, err := al.summaryChat(ctx, []providers.Message{{Role: "user", Content: prompt}}, map[string]interface{}{
+		"max_tokens":  1024,
+		"temperature": 0.3,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
@@ -0,0 +1,493 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/channels"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/openaiapi"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// harness runs an agent loop against a scripted provider, with a test
+// channel registered on a channel manager as in a real deployment.
+type harness struct {
+	cfg     *config.Config
+	bus     *bus.MessageBus
+	mock    *providers.MockProvider
+	agent   *AgentLoop
+	manager *channels.Manager
+	channel *channels.TestChannel
+}
+
+func newHarness(t *testing.T, mock *providers.MockProvider) *harness {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = filepath.Join(t.TempDir(), "workspace")
+	cfg.Storage.Enabled = false
+
+	h := &harness{cfg: cfg, bus: bus.NewMessageBus(), mock: mock}
+	h.agent = NewAgentLoop(cfg, h.bus, mock)
+	manager, err := channels.NewManager(cfg, h.bus)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	h.manager = manager
+	h.channel = channels.NewTestChannel("test", h.bus)
+	manager.RegisterChannel("test", h.channel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := manager.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll: %v", err)
+	}
+	go h.agent.Run(ctx)
+	t.Cleanup(func() {
+		h.agent.Stop()
+		cancel()
+		manager.StopAll(context.Background())
+	})
+	return h
+}
+
+func TestHarnessToolCallTurn(t *testing.T) {
+	mock := providers.NewMockProvider().
+		CallTool("write_file", map[string]interface{}{"path": "notes/hello.txt", "content": "hi there"}).
+		Reply("Saved **hello.txt**.").
+		SetDefault("ok")
+	h := newHarness(t, mock)
+
+	if !h.channel.Receive("user1", "chat1", "save a greeting") {
+		t.Fatal("message was dropped")
+	}
+	reply, err := h.channel.WaitReply("chat1", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Parts) != 1 || reply.Parts[0] != "Saved hello.txt." {
+		t.Errorf("expected the reply rendered as plain text, got %+v", reply)
+	}
+
+	data, err := os.ReadFile(filepath.Join(h.cfg.WorkspacePath(), "notes", "hello.txt"))
+	if err != nil || string(data) != "hi there" {
+		t.Errorf("expected the tool to write the file, got %q, %v", data, err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) < 2 {
+		t.Fatalf("expected two LLM calls, got %d", len(calls))
+	}
+	if got := calls[0].LastUserMessage(); !strings.Contains(got, "save a greeting") {
+		t.Errorf("first call user message = %q", got)
+	}
+	last := calls[1].Messages[len(calls[1].Messages)-1]
+	if last.Role != "tool" || last.ToolCallID != "call_1" {
+		t.Errorf("expected the tool result fed back, got %+v", last)
+	}
+}
+
+func TestHarnessProviderError(t *testing.T) {
+	mock := providers.NewMockProvider().Fail(&providers.RateLimitError{StatusCode: 429, Body: "slow down"}).SetDefault("ok")
+	h := newHarness(t, mock)
+
+	h.channel.Receive("user1", "chat2", "hello")
+	reply, err := h.channel.WaitReply("chat2", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Kind != bus.KindError {
+		t.Errorf("expected an error reply, got %+v", reply)
+	}
+}
+
+func TestHarnessCronDelivery(t *testing.T) {
+	mock := providers.NewMockProvider().Reply("Your report is ready.").SetDefault("ok")
+	h := newHarness(t, mock)
+
+	ran := make(chan struct{})
+	service := cron.NewCronService(filepath.Join(t.TempDir(), "jobs.json"), func(job *cron.CronJob) (string, error) {
+		defer close(ran)
+		result, err := h.agent.ProcessBackground(context.Background(), job.Payload.Message, "cron:"+job.ID)
+		if err != nil {
+			return "", err
+		}
+		if job.Payload.Deliver {
+			h.manager.SendToChannel(context.Background(), job.Payload.Channel, job.Payload.To, result)
+		}
+		return result, nil
+	})
+	if err := service.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer service.Stop()
+	if _, err := service.AddOneShot("report", time.Now().Add(100*time.Millisecond), cron.CronPayload{Message: "write the report", Deliver: true, Channel: "test", To: "chat3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ran:
+	case <-time.After(10 * time.Second):
+		t.Fatal("cron job did not run")
+	}
+	reply, err := h.channel.WaitReply("chat3", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Content != "Your report is ready." {
+		t.Errorf("unexpected delivery %+v", reply)
+	}
+}
+
+func TestHarnessSendLater(t *testing.T) {
+	mock := providers.NewMockProvider().
+		CallTool("send_later", map[string]interface{}{"action": "schedule", "content": "Standup in **5** minutes!", "when": "tomorrow 9am", "channel": "test", "chat_id": "team"}).
+		Reply("Scheduled.").
+		SetDefault("ok")
+	h := newHarness(t, mock)
+
+	service := cron.NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+	// A message that came due while mclaw was stopped goes out on startup
+	missed, err := service.AddOneShot("send later", time.Now().Add(-time.Minute), cron.CronPayload{Kind: "send", Message: "Missed *message*", Channel: "test", To: "team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.agent.EnableReminders(service)
+	reply, err := h.channel.WaitReply("team", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Content != "Missed *message*" {
+		t.Errorf("expected the missed message sent verbatim, got %+v", reply)
+	}
+
+	h.channel.Receive("user1", "chat4", "remind the team about standup tomorrow at 9")
+	if _, err := h.channel.WaitReply("chat4", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	var scheduled []cron.CronJob
+	for _, job := range service.ListJobs(true) {
+		if job.ID == missed.ID {
+			t.Error("expected the missed message removed after sending")
+		}
+		if job.Payload.Kind == "send" {
+			scheduled = append(scheduled, job)
+		}
+	}
+	if len(scheduled) != 1 || scheduled[0].Payload.Message != "Standup in **5** minutes!" || scheduled[0].Payload.To != "team" {
+		t.Errorf("unexpected scheduled messages %+v", scheduled)
+	}
+}
+
+func TestHarnessBroadcast(t *testing.T) {
+	mock := providers.NewMockProvider().
+		CallTool("broadcast", map[string]interface{}{"group": "family", "content": "Dinner at 7!"}).
+		Reply("Sent.").
+		SetDefault("ok")
+	h := newHarness(t, mock)
+	h.cfg.Channels.Recipients = map[string][]string{"family": {"test:mom", "test:dad"}}
+
+	h.channel.Receive("user1", "chat5", "tell the family dinner is at 7")
+	if _, err := h.channel.WaitReply("chat5", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for _, chat := range []string{"mom", "dad"} {
+		reply, err := h.channel.WaitReply(chat, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reply.Content != "Dinner at 7!" {
+			t.Errorf("unexpected message to %s: %+v", chat, reply)
+		}
+	}
+	if err := h.manager.SendToChannel(context.Background(), bus.GroupChannel, "nobody", "hi"); err == nil {
+		t.Error("expected an unknown group to be an error")
+	}
+}
+
+func TestHarnessReactionFeedback(t *testing.T) {
+	mock := providers.NewMockProvider().
+		Reply("TCP is a reliable, ordered byte stream between two hosts.").
+		SetDefault("ok")
+	h := newHarness(t, mock)
+
+	h.channel.Receive("user1", "chat6", "explain tcp")
+	if _, err := h.channel.WaitReply("chat6", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	index := -1
+	for i, msg := range h.channel.Sent() {
+		if msg.ChatID == "chat6" && !msg.Interim() {
+			index = i
+		}
+	}
+	if !h.channel.React("user1", index, "👎") {
+		t.Fatal("reaction to the reply was ignored")
+	}
+
+	path := filepath.Join(filepath.Dir(h.cfg.WorkspacePath()), "feedback.jsonl")
+	deadline := time.Now().Add(5 * time.Second)
+	var data []byte
+	for time.Now().Before(deadline) {
+		data, _ = os.ReadFile(path)
+		if len(data) > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	for _, want := range []string{`"sentiment":"negative"`, `"strong":true`, `"prompt":"explain tcp"`, `"session_key":"test:chat6"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in the feedback log, got %s", want, data)
+		}
+	}
+	now := time.Now()
+	if stats := h.agent.stats.Total(now.Add(-time.Hour), now.Add(time.Hour)); stats.Disliked != 1 {
+		t.Errorf("expected 1 disliked reply in the stats, got %+v", stats)
+	}
+}
+
+func TestHarnessRetry(t *testing.T) {
+	mock := providers.NewMockProvider().
+		Reply("First answer.").
+		Reply("Second answer.").
+		Reply("Third answer.").
+		SetDefault("ok")
+	h := newHarness(t, mock)
+
+	h.channel.Receive("user1", "chat7", "write a haiku")
+	if _, err := h.channel.WaitReply("chat7", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	h.channel.HandleMessage("user1", "chat7", "/retry", nil, map[string]string{"retry": "true"})
+	sent, err := h.channel.WaitSent(2, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent[1].Content != "Second answer." {
+		t.Errorf("expected the retried answer, got %+v", sent[1])
+	}
+	if calls := mock.Calls(); len(calls) != 2 || calls[1].LastUserMessage() != "write a haiku" {
+		t.Errorf("expected the last message to be asked again, got %+v", calls)
+	}
+	history := h.agent.sessions.GetHistory("test:chat7")
+	if len(history) != 2 || history[1].Content != "Second answer." {
+		t.Errorf("expected only the retried exchange in the history, got %+v", history)
+	}
+
+	// 🔄 on the latest answer is a shortcut; on older ones it is only feedback
+	h.channel.React("user1", 0, "🔄")
+	if !h.channel.React("user1", 1, "🔄") {
+		t.Fatal("retry reaction was ignored")
+	}
+	if sent, err = h.channel.WaitSent(3, 10*time.Second); err != nil || sent[2].Content != "Third answer." {
+		t.Fatalf("expected a third answer, got %+v (%v)", sent, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := len(mock.Calls()); n != 3 {
+		t.Errorf("expected 3 model calls, got %d", n)
+	}
+}
+
+func TestHarnessSessionModel(t *testing.T) {
+	mock := providers.NewMockProvider().SetDefault("ok")
+	h := newHarness(t, mock)
+
+	// A session set to a model without a configured provider says so
+	// instead of falling back to the default
+	h.agent.sessions.SetModel("test:chat9", "unknown/model")
+	h.channel.Receive("user1", "chat9", "hello")
+	reply, err := h.channel.WaitReply("chat9", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reply.Content, "can't use model unknown/model") || len(mock.Calls()) != 0 {
+		t.Errorf("expected the session model to be used, got %q and %d calls", reply.Content, len(mock.Calls()))
+	}
+
+	h.agent.sessions.SetModel("test:chat9", "")
+	h.channel.Receive("user1", "chat9", "hello again")
+	if _, err := h.channel.WaitSent(2, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if calls := mock.Calls(); len(calls) != 1 || calls[0].Model != h.agent.switcher.CurrentModel() {
+		t.Errorf("expected the default model after a reset, got %+v", calls)
+	}
+}
+
+func TestHarnessSessionOptions(t *testing.T) {
+	mock := providers.NewMockProvider().SetDefault("ok")
+	h := newHarness(t, mock)
+
+	h.agent.sessions.SetOption("test:chat10", "temperature", 0.2)
+	h.agent.sessions.SetOption("test:chat10", "max_tokens", 2048)
+	h.channel.Receive("user1", "chat10", "hello")
+	if _, err := h.channel.WaitReply("chat10", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	calls := mock.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(calls))
+	}
+	if calls[0].Options["temperature"] != 0.2 || calls[0].Options["max_tokens"] != 2048 {
+		t.Errorf("expected the session's options, got %v", calls[0].Options)
+	}
+}
+
+func TestHarnessDailyBudget(t *testing.T) {
+	mock := providers.NewMockProvider().SetDefault("ok")
+	h := newHarness(t, mock)
+	h.cfg.Budget.Channel, h.cfg.Budget.ChatID = "test", "owner"
+	h.agent.spend.SetLimit(0.000001, map[string]config.ModelPrice{
+		h.agent.switcher.CurrentModel(): {Input: 1, Output: 1},
+	})
+
+	h.channel.Receive("user1", "chat11", "hello")
+	if _, err := h.channel.WaitReply("chat11", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.channel.WaitReply("owner", 10*time.Second); err != nil {
+		t.Fatalf("expected the owner to be told: %v", err)
+	}
+
+	// Background work stops; the owner is still answered
+	if _, err := h.agent.ProcessBackground(context.Background(), "check the news", "cron:news"); err != errOverBudget {
+		t.Errorf("expected the background turn to be refused, got %v", err)
+	}
+	h.channel.Receive("user1", "chat11", "are you there?")
+	if _, err := h.channel.WaitSent(3, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if calls := len(mock.Calls()); calls != 2 {
+		t.Errorf("expected 2 LLM calls, got %d", calls)
+	}
+}
+
+func TestHarnessModelCapabilities(t *testing.T) {
+	mock := providers.NewMockProvider().SetDefault("a red square")
+	h := newHarness(t, mock)
+	no, yes := false, true
+	h.cfg.ModelInfo = config.ModelCatalog{h.agent.switcher.CurrentModel(): {Tools: &no, Vision: &yes}}
+	h.agent.models.Apply(h.cfg)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "photo.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h.channel.HandleMessage("user1", "chat12", "what is this?", []string{path}, map[string]string{"message_id": "1"})
+	if _, err := h.channel.WaitReply("chat12", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	calls := mock.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(calls))
+	}
+	if len(calls[0].Tools) != 0 {
+		t.Errorf("expected no tools for a model without tool calling, got %d", len(calls[0].Tools))
+	}
+	last := calls[0].Messages[len(calls[0].Messages)-1]
+	if len(last.Parts) != 2 || last.Parts[1].ImageURL == nil || !strings.HasPrefix(last.Parts[1].ImageURL.URL, "data:image/png;base64,") {
+		t.Errorf("expected the photo attached for a vision model, got %+v", last.Parts)
+	}
+}
+
+func TestHarnessContextOverflow(t *testing.T) {
+	overflow := &providers.APIError{StatusCode: 400, Body: `{"error":{"code":"context_length_exceeded"}}`}
+	mock := providers.NewMockProvider().Fail(overflow).Reply("Short answer.").Fail(overflow).Fail(overflow).SetDefault("ok")
+	h := newHarness(t, mock)
+	h.agent.sessions.AddMessage("test:chat13", "user", "an old question")
+	h.agent.sessions.AddMessage("test:chat13", "assistant", "an old answer")
+
+	h.channel.Receive("user1", "chat13", "hello")
+	reply, err := h.channel.WaitReply("chat13", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Kind == bus.KindError {
+		t.Fatalf("expected the retry to answer, got %+v", reply)
+	}
+	calls := mock.Calls()
+	if len(calls) != 2 || len(calls[0].Messages) != 4 || len(calls[1].Messages) != 2 {
+		t.Fatalf("expected one retry without the history, got %d calls", len(calls))
+	}
+	if !strings.Contains(calls[1].Messages[0].Content, "2 earlier messages") {
+		t.Error("expected the model to be told the history was dropped")
+	}
+
+	// A second overflow is reported plainly
+	h.channel.Receive("user1", "chat14", "hello")
+	reply, err = h.channel.WaitReply("chat14", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Kind != bus.KindError || !strings.Contains(strings.Join(reply.Parts, ""), "context window") {
+		t.Errorf("expected a readable error, got %+v", reply)
+	}
+}
+
+func TestDryRunSkipsTools(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = filepath.Join(t.TempDir(), "workspace")
+	cfg.Storage.Enabled = false
+	cfg.DryRun = true
+	mock := providers.NewMockProvider().
+		CallTool("write_file", map[string]interface{}{"path": "notes.txt", "content": "hi"}).
+		Reply("Done.")
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), mock)
+
+	if _, err := al.ProcessDirect(context.Background(), "save a note", "cli:dry"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.WorkspacePath(), "notes.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected the dry run to leave the workspace alone, got %v", err)
+	}
+	calls := mock.Calls()
+	if len(calls) != 2 || !strings.Contains(calls[1].Messages[len(calls[1].Messages)-1].Content, "Dry run: write_file was not run") {
+		t.Errorf("expected the tool result to report the skipped call, got %+v", calls)
+	}
+}
+
+func TestHarnessAPISessions(t *testing.T) {
+	mock := providers.NewMockProvider().SetDefault("ok")
+	h := newHarness(t, mock)
+	h.cfg.API.Enabled = true
+	h.cfg.API.Keys = []string{"k1"}
+	srv := newAPIServer(h.agent)
+
+	post := func(header string) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`))
+		req.Header.Set("Authorization", "Bearer k1")
+		if header != "" {
+			req.Header.Set(openaiapi.SessionHeader, header)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+	}
+	post("")
+	post("")
+	post("desk")
+
+	// Requests without a session leave nothing in the store
+	keys := h.agent.sessions.ListKeys()
+	if len(keys) != 1 || keys[0] != "api:desk" {
+		t.Errorf("expected only the named session kept, got %v", keys)
+	}
+}
@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// Excerpt kept from each end of a condensed message, so instructions the
+// user typed before or after the pasted text survive.
+const inboundExcerptChars = 300
+
+// condenseInbound replaces a very long inbound message (forwarded article,
+// large paste) with a summary plus a workspace reference to the full text.
+func (al *AgentLoop) condenseInbound(ctx context.Context, msg bus.InboundMessage) string {
+	content := msg.Content
+	if al.inboundLimit <= 0 || len(content) <= al.inboundLimit {
+		return content
+	}
+
+	kind := "Long message"
+	if msg.Metadata["forwarded"] == "true" {
+		kind = "Forwarded message"
+	}
+
+	path, err := al.saveInbound(content)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Failed to save long inbound message: %v", err))
+		return content
+	}
+
+	summary, err := al.summarizeInbound(ctx, content)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Inbound summarization failed: %v", err))
+		summary = "(summary unavailable)"
+	}
+
+	logger.InfoC("agent", fmt.Sprintf("Condensed %d-char inbound message, full text at %s", len(content), path))
+
+	return fmt.Sprintf("[%s, %d characters. Full text saved to %s — use read_file if you need details.]\n\n"+
+		"Beginning:\n%s\n\nEnd:\n%s\n\nSummary:\n%s",
+		kind, len(content), path, excerpt(content, inboundExcerptChars, true), excerpt(content, inboundExcerptChars, false), summary)
+}
+
+func (al *AgentLoop) saveInbound(content string) (string, error) {
+	dir := filepath.Join(al.workspace, "inbound")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, time.Now().Format("20060102-150405.000")+".md")
+	return path, os.WriteFile(path, []byte(content), 0644)
+}
+
+func (al *AgentLoop) summarizeInbound(ctx context.Context, content string) (string, error) {
+	// Keep the summarizer input within half the context window
+	if limit := al.summaryWindow() * 2; limit > 0 && len(content) > limit {
+		content = content[:limit]
+	}
+
+	prompt := "Summarize the following text for an assistant that will answer questions about it. " +
+		"Keep key facts, names, numbers, dates and conclusions. Use at most 10 bullet points.\n\nTEXT:\n" + content
+
+	ctx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	defer cancel()
+
+	resp, err := al.summaryChat(ctx, []providers.Message{{Role: "user", Content: prompt}}, map[string]interface{}{
+		"max_tokens":  1024,
+		"temperature": 0.3,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
+
+// excerpt returns up to n bytes from the start (or end) of s, trimmed to a rune boundary.
+func excerpt(s string, n int, fromStart bool) string {
+	if len(s) <= n {
+		return s
+	}
+	if fromStart {
+		s = s[:n]
+		return strings.ToValidUTF8(s, "") + "…"
+	}
+	return "…" + strings.ToValidUTF8(s[len(s)-n:], "")
+}
@@ -0,0 +1,13 @@
+package agent
+
+import (
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// EnableJournal lets the journal tool schedule its weekly reflection on cs.
+func (al *AgentLoop) EnableJournal(cs *cron.CronService) {
+	if t, ok := al.tools.Get("journal"); ok {
+		t.(*tools.JournalTool).SetCronService(cs)
+	}
+}
@@ -9,23 +9,47 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ntminh611/mclaw/pkg/audit"
+	"github.com/ntminh611/mclaw/pkg/auth"
+	"github.com/ntminh611/mclaw/pkg/bookmarks"
+	"github.com/ntminh611/mclaw/pkg/budget"
 	"github.com/ntminh611/mclaw/pkg/bus"
 	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/contacts"
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/digest"
+	"github.com/ntminh611/mclaw/pkg/expenses"
+	"github.com/ntminh611/mclaw/pkg/feedback"
+	"github.com/ntminh611/mclaw/pkg/feeds"
+	"github.com/ntminh611/mclaw/pkg/health"
 	"github.com/ntminh611/mclaw/pkg/logger"
 	"github.com/ntminh611/mclaw/pkg/memory"
+	"github.com/ntminh611/mclaw/pkg/models"
+	"github.com/ntminh611/mclaw/pkg/monitors"
+	"github.com/ntminh611/mclaw/pkg/netguard"
+	"github.com/ntminh611/mclaw/pkg/ocr"
+	"github.com/ntminh611/mclaw/pkg/openaiapi"
 	"github.com/ntminh611/mclaw/pkg/providers"
+	"github.com/ntminh611/mclaw/pkg/reminders"
 	"github.com/ntminh611/mclaw/pkg/session"
+	"github.com/ntminh611/mclaw/pkg/tasks"
 	"github.com/ntminh611/mclaw/pkg/tools"
+	"github.com/ntminh611/mclaw/pkg/watch"
+	"github.com/ntminh611/mclaw/pkg/webhooks"
+	"github.com/ntminh611/mclaw/pkg/workflow"
 )
 
 type AgentLoop struct {
+	cfg            *config.Config
 	bus            *bus.MessageBus
 	provider       providers.LLMProvider
 	switcher       *ModelSwitcher
@@ -37,10 +61,48 @@ type AgentLoop struct {
 	contextBuilder *ContextBuilder
 	tools          *tools.ToolRegistry
 	memory         *memory.MemoryEngine
+	usageCfg       config.UsageConfig
+	sessionTTL     time.Duration // archive sessions idle longer than this (0 = never)
+	inboundLimit   int           // inbound messages longer than this are summarized (0 = off)
+	coalesceWindow time.Duration // rapid messages within this window become one turn (0 = off)
+	stuckAfter     time.Duration // turns running longer are cancelled (0 = never)
+	sessionLocks   sessionLocks
+	workers        int      // turns processed at once, each in its own session
+	bgWaiters      sync.Map // ProcessBackground calls awaiting their reply, by message ID
+	bgSeq          atomic.Uint64
+	auth           *auth.Authorizer
+	secrets        *secretFilter
+	monitor        *health.Monitor
+	summarizer     providers.LLMProvider // dedicated summary_model provider (nil = use switcher)
+	summaryModel   string
 	running        bool
 	summarizing    sync.Map
+	toolFailures   map[string]int // consecutive failures per tool, across turns
+	failuresMu     sync.Mutex
+	feeds          *feeds.Service // nil when feeds are disabled
+	stats          *digest.Recorder
+	feedback       *feedback.Log     // nil when the log cannot be opened
+	digestCron     *cron.CronService // set by EnableDigest
+	toolMetrics    *tools.ToolMetrics
+	reminders      *reminders.Service // nil when the store is unavailable
+	spend          *budget.Tracker    // daily LLM spend, see budget.daily_usd
+	webhooks       *webhooks.Server   // nil when webhooks are disabled
+	watches        *watch.Service     // nil when watching is disabled
+	monitors       *monitors.Service  // nil when monitors are disabled
+	contacts       *contacts.Service  // nil when contacts are disabled
+	expenses       *expenses.Service  // nil when expenses are disabled
+	bookmarks      *bookmarks.Service // nil when bookmarks are disabled
+	ocr            ocr.Engine         // nil when OCR is off
+	api            *openaiapi.Server  // nil when the API is disabled
+	models         *models.Registry   // context window, tools and vision per model
 }
 
+const (
+	// After this many consecutive failures a tool is hidden from the model
+	maxToolFailures     = 3
+	toolFailureCooldown = 10 * time.Minute
+)
+
 func NewAgentLoop(cfg *config.Config, bus *bus.MessageBus, provider providers.LLMProvider) *AgentLoop {
 	workspace := cfg.WorkspacePath()
 	os.MkdirAll(workspace, 0755)
@@ -48,26 +110,181 @@ func NewAgentLoop(cfg *config.Config, bus *bus.MessageBus, provider providers.LL
 	toolsRegistry := tools.NewToolRegistry()
 	toolsRegistry.Register(&tools.ReadFileTool{})
 	toolsRegistry.Register(&tools.WriteFileTool{})
+	toolsRegistry.Register(tools.NewEditFileTool(filepath.Join(filepath.Dir(workspace), "backups")))
 	toolsRegistry.Register(&tools.ListDirTool{})
+	toolsRegistry.Register(tools.NewReadDocumentTool())
+	visionProvider, visionModel := newVisionProvider(cfg)
+	toolsRegistry.Register(tools.NewDescribeImageTool(visionProvider, visionModel))
 	toolsRegistry.Register(tools.NewExecTool(workspace))
+	toolsRegistry.Register(tools.NewCodeRunTool(cfg.Tools.CodeRun, workspace))
+	toolsRegistry.Register(tools.NewSystemTool(cfg.Tools.System))
+
+	// Keep model-driven requests off the host's internal network
+	guard := netguard.New(cfg.Tools.Network.AllowPrivate)
+
+	toolsRegistry.Register(tools.NewWebSearchTool(cfg.Tools.Web.Search))
+	webFetch := tools.NewWebFetchTool(50000)
+	webFetch.SetProfiles(cfg.Tools.Web.Profiles)
+	webFetch.SetNetworkGuard(guard)
+	toolsRegistry.Register(webFetch)
+	browser := tools.NewBrowserTool(30 * time.Second)
+	browser.SetProfiles(cfg.Tools.Web.Profiles)
+	browser.SetNetworkGuard(guard)
+	browser.SetProfileDir(cfg.BrowserProfileDir())
+	toolsRegistry.Register(browser)
+	httpTool := tools.NewHTTPRequestTool(cfg.Tools.HTTP.Secrets)
+	httpTool.SetNetworkGuard(guard)
+	toolsRegistry.Register(httpTool)
+	netcheck := tools.NewNetCheckTool()
+	netcheck.SetNetworkGuard(guard)
+	toolsRegistry.Register(netcheck)
+	toolsRegistry.Register(tools.NewEmailTool(cfg.Tools.Email))
+	toolsRegistry.Register(tools.NewGitHubTool(cfg.Tools.GitHub))
+	toolsRegistry.Register(tools.NewKubernetesTool(cfg.Tools.Kubernetes))
+	toolsRegistry.Register(tools.NewGeoTool())
+	cronTool := tools.NewCronTool()
+	cronTool.SetToolPolicy(func() map[string]config.ToolPolicyConfig { return cfg.Tools.Policy })
+	toolsRegistry.Register(cronTool)
+	heartbeatTool := tools.NewHeartbeatTool()
+	toolsRegistry.Register(heartbeatTool)
+
+	// Keep credentials out of logs, tool results and saved sessions
+	secrets := newSecretFilter(cfg)
+	logger.SetRedactor(secrets.Redact)
+
+	dataDir := filepath.Dir(cfg.WorkspacePath())
+	sessionsManager, err := session.NewSQLiteSessionManager(filepath.Join(dataDir, "memory.db"), filepath.Join(dataDir, "sessions"))
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Session database unavailable, falling back to JSON files: %v", err))
+		sessionsManager = session.NewSessionManager(filepath.Join(dataDir, "sessions"))
+	}
+	sessionsManager.SetArchiveDir(filepath.Join(dataDir, "sessions_archive"))
+	sessionsManager.SetRedactor(secrets.Redact)
+	toolsRegistry.Register(tools.NewSessionSearchTool(sessionsManager))
+	toolsRegistry.Register(tools.NewScratchpadTool(sessionsManager))
+	toolsRegistry.Register(tools.NewNotesTool(workspace))
+	toolsRegistry.Register(tools.NewJournalTool(workspace))
+	toolsRegistry.Register(tools.NewPinTool(sessionsManager))
+	toolsRegistry.Register(tools.NewTopicTool(sessionsManager))
+	toolsRegistry.Register(tools.NewPollTool(bus))
+	toolsRegistry.Register(tools.NewSendLaterTool(bus))
+	toolsRegistry.Register(tools.NewTimerTool(bus))
+	toolsRegistry.Register(tools.NewBroadcastTool(bus, func() map[string][]string { return cfg.Channels.Recipients }))
+
+	if taskStore, err := tasks.NewStore(filepath.Join(dataDir, "memory.db")); err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Task store unavailable, tasks tool disabled: %v", err))
+	} else {
+		toolsRegistry.Register(tools.NewTasksTool(taskStore))
+		heartbeatTool.AddPromptSection(func() string { return taskStore.HeartbeatSection(time.Now()) })
+	}
 
-	braveAPIKey := cfg.Tools.Web.Search.APIKey
-	toolsRegistry.Register(tools.NewWebSearchTool(braveAPIKey, cfg.Tools.Web.Search.MaxResults))
-	toolsRegistry.Register(tools.NewWebFetchTool(50000))
-	toolsRegistry.Register(tools.NewBrowserTool(30 * time.Second))
-	toolsRegistry.Register(tools.NewCronTool())
-	toolsRegistry.Register(tools.NewHeartbeatTool())
+	var reminderService *reminders.Service
+	if reminderStore, err := reminders.NewStore(filepath.Join(dataDir, "memory.db")); err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Reminder store unavailable, remind tool disabled: %v", err))
+	} else {
+		reminderService = reminders.NewService(reminderStore, bus)
+		toolsRegistry.Register(tools.NewRemindTool(reminderService))
+	}
 
-	sessionsManager := session.NewSessionManager(filepath.Join(filepath.Dir(cfg.WorkspacePath()), "sessions"))
+	feedService := newFeedService(cfg, bus, guard, filepath.Join(dataDir, "memory.db"))
+	if feedService != nil {
+		toolsRegistry.Register(tools.NewFeedsTool(feedService))
+	}
+	monitorService := newMonitorService(cfg, bus, guard, filepath.Join(dataDir, "memory.db"))
+	if monitorService != nil {
+		toolsRegistry.Register(tools.NewMonitorsTool(monitorService))
+	}
+	contactService := newContactService(cfg, bus, filepath.Join(dataDir, "memory.db"))
+	if contactService != nil {
+		toolsRegistry.Register(tools.NewContactsTool(contactService))
+	}
+	expenseService := newExpenseService(cfg, bus, filepath.Join(dataDir, "memory.db"))
+	if expenseService != nil {
+		toolsRegistry.Register(tools.NewExpensesTool(expenseService))
+	}
+	bookmarkService := newBookmarkService(cfg, bus, webFetch, filepath.Join(dataDir, "memory.db"))
+	if bookmarkService != nil {
+		toolsRegistry.Register(tools.NewBookmarksTool(bookmarkService))
+	}
+	toolsRegistry.Register(tools.NewMarketTool(cfg.Tools.Market, filepath.Join(dataDir, "market_watchlist.json")))
+	toolsRegistry.Register(tools.NewConvertTool(filepath.Join(dataDir, "fx_rates.json")))
+	toolsRegistry.Register(tools.NewListsTool(filepath.Join(dataDir, "lists.json")))
 
+	authz, err := auth.New(cfg, filepath.Join(dataDir, "auth.json"))
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Ignoring runtime access grants: %v", err))
+	}
+
+	// Every LLM call counts towards the daily budget
+	registry := models.New(cfg)
+	spend := budget.New(filepath.Join(dataDir, "budget.json"), cfg.Budget.DailyUSD, registry.Pricing())
+	spend.OnExceeded(budgetNotifier(cfg, bus))
 	switcher := NewModelSwitcher(cfg, provider)
+	switcher.spend = spend
+	toolsRegistry.Register(newAskModelsTool(cfg, switcher, spend))
+
+	// Workflows run tools directly and only use the LLM for explicit prompt steps
+	workflows := workflow.NewEngine(workspace, toolsRegistry, func(ctx context.Context, prompt string) (string, error) {
+		if spend.Exceeded(time.Now()) {
+			return "", errOverBudget
+		}
+		resp, err := switcher.Chat(ctx, []providers.Message{{Role: "user", Content: prompt}}, nil, map[string]interface{}{
+			"max_tokens":  2048,
+			"temperature": 0.3,
+		})
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+	})
+	workflows.SetNotifier(workflowNotifier(bus))
+	toolsRegistry.Register(tools.NewWorkflowTool(workflows))
+	cronTool.SetWorkflowEngine(workflows)
+
+	// User-defined tools from workspace/tools/*.yaml
+	customTools, errs := tools.LoadCustomTools(filepath.Join(workspace, "tools"), workspace, httpTool)
+	for _, err := range errs {
+		logger.WarnC("agent", fmt.Sprintf("Skipping custom tool: %v", err))
+	}
+	loaded := 0
+	for _, t := range customTools {
+		if _, exists := toolsRegistry.Get(t.Name()); exists {
+			logger.WarnC("agent", fmt.Sprintf("Skipping custom tool %s: a built-in tool has that name", t.Name()))
+			continue
+		}
+		toolsRegistry.Register(t)
+		loaded++
+	}
+	if loaded > 0 {
+		logger.InfoC("agent", fmt.Sprintf("Loaded %d custom tool(s) from workspace/tools", loaded))
+	}
+
+	// Cross-cutting behavior for every tool call, including workflow steps
+	toolMetrics := tools.NewToolMetrics()
+	if cfg.Tools.Audit.Enabled {
+		if auditLog, err := audit.Open(filepath.Join(dataDir, audit.FileName)); err != nil {
+			logger.WarnC("agent", fmt.Sprintf("Tool audit log disabled: %v", err))
+		} else {
+			toolsRegistry.Use(tools.AuditMiddleware(auditLog))
+		}
+	}
+	toolsRegistry.Use(
+		tools.LoggingMiddleware(),
+		toolMetrics.Middleware(),
+		tools.ValidateArgs(),
+		tools.RedactSecrets(secrets.Redact),
+	)
+	if cfg.DryRun {
+		toolsRegistry.Use(tools.DryRun())
+		logger.WarnC("agent", "Dry run: LLM calls are echoed, tools do nothing and memory is off")
+	}
 
-	// Initialize Mem0-lite memory engine
+	// Initialize Mem0-lite memory engine (not in dry runs, it would learn the echoes)
 	var memEngine *memory.MemoryEngine
-	if cfg.Memory.Enabled {
-		var err error
+	if cfg.Memory.Enabled && !cfg.DryRun {
 		// Use ModelSwitcher's getters so memory always uses the current active model
-		memEngine, err = memory.NewMemoryEngine(cfg, switcher.CurrentProvider, switcher.CurrentModel)
+		currentProvider := func() providers.LLMProvider { return spend.Meter(switcher.CurrentProvider()) }
+		memEngine, err = memory.NewMemoryEngine(cfg, currentProvider, switcher.CurrentModel)
 		if err != nil {
 			logger.WarnC("agent", fmt.Sprintf("Failed to initialize memory engine: %v", err))
 		} else if memEngine != nil {
@@ -75,7 +292,15 @@ func NewAgentLoop(cfg *config.Config, bus *bus.MessageBus, provider providers.LL
 		}
 	}
 
-	return &AgentLoop{
+	contextBuilder := NewContextBuilder(workspace)
+	contextBuilder.SetToolRegistry(toolsRegistry)
+	contextBuilder.SetContacts(contactService)
+
+	summarizer, summaryModel := newSummarizer(cfg)
+	summarizer = spend.Meter(summarizer)
+
+	al := &AgentLoop{
+		cfg:            cfg,
 		bus:            bus,
 		provider:       provider,
 		switcher:       switcher,
@@ -84,11 +309,56 @@ func NewAgentLoop(cfg *config.Config, bus *bus.MessageBus, provider providers.LL
 		contextWindow:  cfg.Agents.Defaults.MaxTokens,
 		maxIterations:  cfg.Agents.Defaults.MaxToolIterations,
 		sessions:       sessionsManager,
-		contextBuilder: NewContextBuilder(workspace),
+		contextBuilder: contextBuilder,
 		tools:          toolsRegistry,
 		memory:         memEngine,
+		usageCfg:       cfg.Usage,
+		sessionTTL:     time.Duration(cfg.Sessions.ArchiveAfterDays) * 24 * time.Hour,
+		inboundLimit:   cfg.Agents.Defaults.LongMessageChars,
+		coalesceWindow: time.Duration(cfg.Agents.Defaults.CoalesceMs) * time.Millisecond,
+		workers:        cfg.Agents.Defaults.Workers,
+		auth:           authz,
+		secrets:        secrets,
+		monitor:        health.NewMonitor(),
+		stuckAfter:     time.Duration(cfg.Health.StuckMinutes) * time.Minute,
+		summarizer:     summarizer,
+		summaryModel:   summaryModel,
 		running:        false,
 		summarizing:    sync.Map{},
+		toolFailures:   make(map[string]int),
+		feeds:          feedService,
+		toolMetrics:    toolMetrics,
+		stats:          digest.OpenRecorder(filepath.Join(dataDir, "stats.json")),
+		feedback:       openFeedbackLog(dataDir),
+		reminders:      reminderService,
+		monitors:       monitorService,
+		contacts:       contactService,
+		expenses:       expenseService,
+		bookmarks:      bookmarkService,
+		ocr:            newOCREngine(cfg, visionProvider, visionModel),
+		spend:          spend,
+		models:         registry,
+	}
+	if feedService != nil && cfg.Feeds.Summarize {
+		feedService.SetSummarizer(al.feedDigest)
+	}
+	if bookmarkService != nil && cfg.Bookmarks.Summarize {
+		bookmarkService.SetSummarizer(al.feedDigest)
+	}
+	al.webhooks = newWebhookServer(al)
+	al.watches = newWatchService(al, dataDir)
+	if al.watches != nil {
+		toolsRegistry.Register(tools.NewWatchPathTool(al.watches))
+	}
+	al.api = newAPIServer(al)
+	cfg.OnReload(al.applyConfig)
+	return al
+}
+
+// workflowNotifier delivers workflow notify steps straight to the outbound bus.
+func workflowNotifier(mb *bus.MessageBus) workflow.NotifyFunc {
+	return func(channel, chatID, content string) {
+		mb.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: content, Proactive: true})
 	}
 }
 
@@ -100,37 +370,119 @@ func (al *AgentLoop) GetToolRegistry() *tools.ToolRegistry {
 	return al.tools
 }
 
+// GetToolMetrics returns per-tool call counts, errors and durations.
+func (al *AgentLoop) GetToolMetrics() *tools.ToolMetrics {
+	return al.toolMetrics
+}
+
 func (al *AgentLoop) Run(ctx context.Context) error {
 	al.running = true
 
+	if al.sessionTTL > 0 {
+		go al.runSessionArchiver(ctx)
+	}
+	if al.feeds != nil {
+		go al.feeds.Run(ctx)
+	}
+	if al.webhooks != nil {
+		go al.runWebhooks(ctx)
+	}
+	if al.watches != nil {
+		go al.watches.Run(ctx)
+	}
+	if al.api != nil {
+		go al.runAPIServer(ctx)
+	}
+	if al.cfg.Storage.Enabled {
+		go al.runJanitor(ctx)
+	}
+	go al.cfg.Watch(ctx, configWatchInterval)
+	go al.monitor.Run(ctx)
+	go al.runFeedback(ctx)
+
+	// Messages accepted but not answered before a crash are queued again
+	walPath := filepath.Join(filepath.Dir(al.cfg.WorkspacePath()), "inbound.wal")
+	if replayed, err := al.bus.EnableWAL(walPath); err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Inbound WAL disabled: %v", err))
+	} else if replayed > 0 {
+		logger.InfoC("agent", fmt.Sprintf("Replaying %d unanswered message(s) from before the restart", replayed))
+	}
+
+	queue := newInboundQueue(al.bus, func() time.Duration { return al.coalesceWindow })
+	pool := newWorkerPool(al.workers, func(job turnJob) { al.handleInbound(ctx, job) })
+	defer pool.wait()
 	for al.running {
-		select {
-		case <-ctx.Done():
+		if !pool.acquire(ctx) {
 			return nil
-		default:
-			msg, ok := al.bus.ConsumeInbound(ctx)
-			if !ok {
-				continue
-			}
-
-			response, err := al.processMessage(ctx, msg)
-			if err != nil {
-				response = formatErrorForUser(err)
-			}
-
-			if response != "" {
-				al.bus.PublishOutbound(bus.OutboundMessage{
-					Channel: msg.Channel,
-					ChatID:  msg.ChatID,
-					Content: response,
-				})
-			}
 		}
+		msg, parts, ok := queue.next(ctx)
+		if !ok {
+			pool.release()
+			continue
+		}
+		pool.submit(turnJob{msg: msg, parts: parts})
 	}
 
 	return nil
 }
 
+// handleInbound answers one turn from the bus and acknowledges the
+// messages it covers.
+func (al *AgentLoop) handleInbound(ctx context.Context, job turnJob) {
+	msg := job.msg
+	response, err := al.processMessage(ctx, msg)
+	if err != nil && ctx.Err() != nil {
+		return // shutting down: leave it in the WAL to answer after restart
+	}
+	if msg.Priority == bus.PriorityBackground {
+		al.replyBackground(msg.ID, response, err)
+		return
+	}
+	kind := bus.KindFinal
+	if err != nil {
+		response = formatErrorForUser(err)
+		kind = bus.KindError
+	}
+
+	if response != "" {
+		al.bus.PublishOutbound(bus.OutboundMessage{
+			Channel: msg.Channel,
+			ChatID:  msg.ChatID,
+			Content: response,
+			Kind:    kind,
+		})
+	}
+	for _, part := range job.parts {
+		al.bus.Ack(part)
+	}
+}
+
+func pinnedInstructions(pins []session.Pin) []string {
+	instructions := make([]string, 0, len(pins))
+	for _, p := range pins {
+		instructions = append(instructions, p.Content)
+	}
+	return instructions
+}
+
+// runSessionArchiver periodically archives sessions idle longer than sessionTTL.
+func (al *AgentLoop) runSessionArchiver(ctx context.Context) {
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if _, err := al.sessions.ArchiveIdle(al.sessionTTL); err != nil {
+			logger.WarnC("agent", fmt.Sprintf("Session archival failed: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func (al *AgentLoop) Stop() {
 	al.running = false
 }
@@ -148,16 +500,74 @@ func (al *AgentLoop) ProcessDirect(ctx context.Context, content, sessionKey stri
 }
 
 func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage) (string, error) {
+	result, err := al.runTurn(ctx, msg)
+	if err != nil {
+		return "", err
+	}
+	content := result.Content
+	// Usage footer is appended after saving so it never enters session history
+	if al.usageCfg.ShowFooter {
+		if footer := result.usage.Footer(); footer != "" {
+			content += "\n\n_" + footer + "_"
+		}
+	}
+	return content, nil
+}
+
+// runTurn answers one inbound message, running tools until the model
+// replies with text, and records the exchange in the session.
+func (al *AgentLoop) runTurn(ctx context.Context, msg bus.InboundMessage) (*TurnResult, error) {
+	// The chat's active topic, if one was chosen, has its own session
+	msg.SessionKey = al.sessions.ActiveKey(msg.SessionKey)
+
+	// Cron jobs and API calls run turns outside Run; one turn per session at a time
+	unlock := al.sessionLocks.lock(msg.SessionKey)
+	defer unlock()
+
+	if reply := al.overQuota(msg); reply != "" {
+		return &TurnResult{Content: reply, SessionKey: msg.SessionKey, usage: newUsageTracker(nil)}, nil
+	}
+
+	if reply, err := al.overBudget(msg); err != nil {
+		return nil, err
+	} else if reply != "" {
+		return &TurnResult{Content: reply, SessionKey: msg.SessionKey, usage: newUsageTracker(nil)}, nil
+	}
+
+	llm, err := al.turnLLM(msg)
+	if err != nil {
+		return &TurnResult{Content: "⚠️ " + err.Error(), SessionKey: msg.SessionKey, usage: newUsageTracker(nil)}, nil
+	}
+	if msg.Metadata["retry"] == "true" {
+		prompt, ok := al.sessions.Rewind(msg.SessionKey)
+		if !ok {
+			return &TurnResult{Content: "Nothing to retry yet.", SessionKey: msg.SessionKey, usage: newUsageTracker(nil)}, nil
+		}
+		logger.InfoC("agent", fmt.Sprintf("Retrying the last turn of %s with %s", msg.SessionKey, llm.Model()))
+		msg.Content = prompt
+	}
+
 	// Per-message timeout to prevent hanging
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
+	defer al.monitor.Begin("agent turn", msg.SessionKey, al.stuckAfter, cancel)()
 
-	// Inject current chat context into CronTool for auto-delivery
-	if cronTool, ok := al.tools.Get("cron"); ok {
-		if ct, ok := cronTool.(*tools.CronTool); ok {
-			ct.SetContext(msg.Channel, msg.ChatID)
-		}
-	}
+	// Web results are deduplicated across the turn's iterations
+	ctx = tools.WithResearchCache(ctx)
+	ctx = tools.WithCaller(ctx, tools.Caller{Channel: msg.Channel, SenderID: msg.SenderID, SessionKey: msg.SessionKey})
+
+	// Text in photos and screenshots is extracted for text-only models
+	msg.Content = al.ocrInbound(ctx, msg, llm.Model())
+
+	// Long pastes and forwarded articles are summarized to keep context lean
+	msg.Content = al.condenseInbound(ctx, msg)
+
+	// Checkpoint before the turn so /undo can rewind it and failed turns can be reverted
+	checkpoint := al.sessions.SaveCheckpoint(msg.SessionKey, excerpt(msg.Content, 80, true))
+
+	// Scope per-conversation tools (scratchpad, pins, project dir) to this session
+	scope, projectPrompt := al.newToolScope(msg)
+	ctx = tools.WithScope(ctx, scope)
 
 	history := al.sessions.GetHistory(msg.SessionKey)
 	summary := al.sessions.GetSummary(msg.SessionKey)
@@ -176,26 +586,57 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 	messages := al.contextBuilder.BuildMessages(
 		history,
 		summary,
+		al.sessions.GetScratchpad(msg.SessionKey),
+		pinnedInstructions(al.sessions.GetPins(msg.SessionKey)),
 		msg.Content,
 		nil,
 		memories,
 	)
+	turnStart := len(messages) - 1 // index of the current user message
+	images := imageParts(msg.Content, msg.Media)
+	if projectPrompt != "" {
+		messages[0].Content += "\n\n" + projectPrompt
+	}
+	if people := al.contextBuilder.PeopleSection(msg.SessionKey, msg.Content); people != "" {
+		messages[0].Content += "\n\n" + people
+	}
+
+	usage := newUsageTracker(al.models.Pricing())
+	var toolCalls []ToolCallRecord
 
 	iteration := 0
 	var finalContent string
 	consecutiveToolErrors := 0
 	consecutiveToolOnly := 0
+	overflowed := false // the context was shrunk after a context-length error
 	const maxConsecutiveErrors = 3
 	const maxConsecutiveToolOnly = 10
 
+	// Budget hints are refreshed in the system prompt on every iteration
+	basePrompt := messages[0].Content
+	budget := turnBudget{maxIterations: al.maxIterations, maxToolOnly: maxConsecutiveToolOnly, channel: msg.Channel,
+		spoken: msg.Metadata["voice_mode"] == "true"}
+	budget.deadline, _ = ctx.Deadline()
+
 	for iteration < al.maxIterations {
 		iteration++
+		messages[0].Content = basePrompt + "\n\n" + budget.section(iteration, consecutiveToolOnly, time.Now())
+
+		// A fallback may have switched models, so check what this one can do
+		activeModel := llm.Model()
+		caps := al.models.Lookup(activeModel)
+		messages[turnStart].Parts = nil
+		if caps.Vision {
+			messages[turnStart].Parts = images
+		}
 
-		toolDefs := al.tools.GetDefinitions()
+		toolDefs := al.tools.GetDefinitions(ctx)
 		providerToolDefs := make([]providers.ToolDefinition, 0, len(toolDefs))
 
-		// If too many consecutive tool errors, stop providing tools to force a text response
-		if consecutiveToolErrors >= maxConsecutiveErrors {
+		if !caps.Tools {
+			providerToolDefs = nil
+		} else if consecutiveToolErrors >= maxConsecutiveErrors {
+			// If too many consecutive tool errors, stop providing tools to force a text response
 			logger.WarnC("agent", fmt.Sprintf("Too many consecutive tool errors (%d), forcing text-only response", consecutiveToolErrors))
 			providerToolDefs = nil
 		} else {
@@ -211,23 +652,41 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 			}
 		}
 
-		activeModel := al.switcher.CurrentModel()
 		logger.InfoC("agent", fmt.Sprintf("Iteration %d: calling LLM (model=%s)...", iteration, activeModel))
 		llmStart := time.Now()
 
-		response, err := al.switcher.Chat(ctx, messages, providerToolDefs, map[string]interface{}{
-			"max_tokens":  8192,
-			"temperature": 0.7,
+		options := al.chatOptions(msg.SessionKey)
+		options["on_usage"] = providers.UsageCallback(func(u providers.UsageInfo) {
+			logger.DebugC("agent", fmt.Sprintf("Streaming usage: %d tokens (estimated=%t)", u.TotalTokens, u.Estimated))
 		})
+		response, err := llm.Chat(ctx, messages, providerToolDefs, options)
+		if err != nil && !overflowed && providers.IsContextOverflow(err) {
+			// Retry once without the history and with long results cut short
+			overflowed = true
+			logger.WarnC("agent", fmt.Sprintf("Context window exceeded for %s, retrying with a shrunk context: %v", msg.SessionKey, err))
+			if turnStart > 1 {
+				basePrompt += droppedHistoryNote(turnStart - 1)
+				messages[0].Content = basePrompt + "\n\n" + budget.section(iteration, consecutiveToolOnly, time.Now())
+			}
+			messages = shrinkContext(messages, turnStart)
+			turnStart = 1
+			response, err = llm.Chat(ctx, messages, providerToolDefs, options)
+		}
 
 		llmDuration := time.Since(llmStart)
 		if err != nil {
 			logger.ErrorC("agent", fmt.Sprintf("LLM call failed after %s: %v", llmDuration, err))
-			return "", fmt.Errorf("LLM call failed: %w", err)
+			// Undo any session state the turn's tools changed (e.g. scratchpad)
+			if rerr := al.sessions.RestoreCheckpoint(msg.SessionKey, checkpoint); rerr != nil {
+				logger.WarnC("agent", fmt.Sprintf("Failed to revert session %s: %v", msg.SessionKey, rerr))
+			}
+			al.stats.RecordTurn(msg.Channel, usage.Total(), usage.Cost(), true, time.Now())
+			return nil, fmt.Errorf("LLM call failed: %w", err)
 		}
 
 		logger.InfoC("agent", fmt.Sprintf("LLM responded in %s (content=%d chars, thinking=%d chars, tools=%d)",
 			llmDuration, len(response.Content), len(response.Thinking), len(response.ToolCalls)))
+		usage.Add(llm.Model(), response.Usage)
 
 		// Send thinking content to user if available
 		if response.Thinking != "" && msg.Channel != "cli" {
@@ -238,12 +697,16 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 			al.bus.PublishOutbound(bus.OutboundMessage{
 				Channel: msg.Channel,
 				ChatID:  msg.ChatID,
-				Content: "💭 *Thinking:*\n\n" + thinkingPreview,
+				Content: thinkingPreview,
+				Kind:    bus.KindThinking,
 			})
 		}
 
 		if len(response.ToolCalls) == 0 {
 			finalContent = response.Content
+			if response.Truncated {
+				finalContent += "\n\n⚠️ _The model stopped responding partway, so this answer may be incomplete._"
+			}
 			break
 		}
 
@@ -288,12 +751,21 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 			logger.InfoC("agent", fmt.Sprintf("Executing tool: %s", tc.Name))
 			toolStart := time.Now()
 			result, err := al.tools.Execute(ctx, tc.Name, tc.Arguments)
+			record := ToolCallRecord{Name: tc.Name, Arguments: tc.Arguments, Result: result, DurationMs: time.Since(toolStart).Milliseconds()}
+			if err != nil {
+				record.Error = err.Error()
+			}
+			toolCalls = append(toolCalls, record)
 			if err != nil {
 				logger.ErrorC("agent", fmt.Sprintf("Tool %s failed after %s: %v", tc.Name, time.Since(toolStart), err))
 				result = fmt.Sprintf("Error: %v\n\nHint: If this is a path error, make sure to use absolute paths. Your workspace is at an absolute path, not a relative one.", err)
+				if !errors.Is(err, tools.ErrInvalidArgs) {
+					al.recordToolFailure(tc.Name, err)
+				}
 			} else {
 				logger.InfoC("agent", fmt.Sprintf("Tool %s completed in %s (result=%d chars)", tc.Name, time.Since(toolStart), len(result)))
 				allFailed = false
+				al.recordToolSuccess(tc.Name)
 			}
 
 			toolResultMsg := providers.Message{
@@ -319,8 +791,13 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 	al.sessions.AddMessage(msg.SessionKey, "user", msg.Content)
 	al.sessions.AddMessage(msg.SessionKey, "assistant", finalContent)
 
+	// Full transcript keeps tool calls and results for export
+	turn := append([]providers.Message{}, messages[turnStart:]...)
+	turn = append(turn, providers.Message{Role: "assistant", Content: finalContent})
+	al.sessions.AppendTranscript(msg.SessionKey, turn...)
+
 	// Async: Process conversation for memory extraction (Mem0-lite)
-	if al.memory != nil {
+	if al.memory != nil && !al.spend.Exceeded(time.Now()) {
 		convMessages := []providers.Message{
 			{Role: "user", Content: msg.Content},
 			{Role: "assistant", Content: finalContent},
@@ -334,9 +811,9 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 	// Token Awareness (Dynamic)
 	// Trigger if history > 20 messages OR estimated tokens > 75% of context window
 	tokenEstimate := al.estimateTokens(newHistory)
-	threshold := al.contextWindow * 75 / 100
+	threshold := al.windowFor(llm.Model()) * 75 / 100
 
-	if len(newHistory) > 20 || tokenEstimate > threshold {
+	if len(newHistory) > 20 || tokenEstimate > threshold || overflowed {
 		if _, loading := al.summarizing.LoadOrStore(msg.SessionKey, true); !loading {
 			go func() {
 				defer al.summarizing.Delete(msg.SessionKey)
@@ -347,10 +824,58 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 
 	al.sessions.Save(al.sessions.GetOrCreate(msg.SessionKey))
 
-	return finalContent, nil
+	logger.InfoC("agent", fmt.Sprintf("Turn usage: %d tokens, $%.4f", usage.Total(), usage.Cost()))
+	al.auth.RecordTokens(msg.Channel, msg.SenderID, usage.Total(), time.Now())
+	al.stats.RecordTurn(msg.Channel, usage.Total(), usage.Cost(), false, time.Now())
+
+	return &TurnResult{
+		Content:    finalContent,
+		ToolCalls:  toolCalls,
+		Usage:      usage.Summary(),
+		Iterations: iteration,
+		Model:      llm.Model(),
+		SessionKey: msg.SessionKey,
+		usage:      usage,
+	}, nil
+}
+
+// recordToolFailure counts consecutive failures and temporarily hides a tool
+// that keeps failing, so the model stops burning iterations on it.
+func (al *AgentLoop) recordToolFailure(name string, err error) {
+	al.failuresMu.Lock()
+	al.toolFailures[name]++
+	count := al.toolFailures[name]
+	if count >= maxToolFailures {
+		delete(al.toolFailures, name)
+	}
+	al.failuresMu.Unlock()
+
+	if count >= maxToolFailures {
+		reason := fmt.Sprintf("failed %d times in a row (last error: %s); try another approach", count, truncateError(err, 120))
+		al.tools.MarkUnavailable(name, reason, toolFailureCooldown)
+		logger.WarnC("agent", fmt.Sprintf("Tool %s marked unavailable for %s", name, toolFailureCooldown))
+	}
+}
+
+func (al *AgentLoop) recordToolSuccess(name string) {
+	al.failuresMu.Lock()
+	delete(al.toolFailures, name)
+	al.failuresMu.Unlock()
+}
+
+func truncateError(err error, maxLen int) string {
+	s := err.Error()
+	if len(s) > maxLen {
+		return s[:maxLen] + "..."
+	}
+	return s
 }
 
 func (al *AgentLoop) summarizeSession(sessionKey string) {
+	if al.spend.Exceeded(time.Now()) {
+		logger.InfoC("agent", fmt.Sprintf("Not summarizing %s: daily budget reached", sessionKey))
+		return
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
@@ -366,7 +891,7 @@ func (al *AgentLoop) summarizeSession(sessionKey string) {
 
 	// Oversized Message Guard (Dynamic)
 	// Skip messages larger than 50% of context window to prevent summarizer overflow.
-	maxMessageTokens := al.contextWindow / 2
+	maxMessageTokens := al.summaryWindow() / 2
 	validMessages := make([]providers.Message, 0)
 	omitted := false
 
@@ -400,7 +925,7 @@ func (al *AgentLoop) summarizeSession(sessionKey string) {
 
 		// Merge them
 		mergePrompt := fmt.Sprintf("Merge these two conversation summaries into one cohesive summary:\n\n1: %s\n\n2: %s", s1, s2)
-		resp, err := al.provider.Chat(ctx, []providers.Message{{Role: "user", Content: mergePrompt}}, nil, al.model, map[string]interface{}{
+		resp, err := al.summaryChat(ctx, []providers.Message{{Role: "user", Content: mergePrompt}}, map[string]interface{}{
 			"max_tokens":  1024,
 			"temperature": 0.3,
 		})
@@ -434,7 +959,7 @@ func (al *AgentLoop) summarizeBatch(ctx context.Context, batch []providers.Messa
 		prompt += fmt.Sprintf("%s: %s\n", m.Role, m.Content)
 	}
 
-	response, err := al.provider.Chat(ctx, []providers.Message{{Role: "user", Content: prompt}}, nil, al.model, map[string]interface{}{
+	response, err := al.summaryChat(ctx, []providers.Message{{Role: "user", Content: prompt}}, map[string]interface{}{
 		"max_tokens":  1024,
 		"temperature": 0.3,
 	})
@@ -456,6 +981,8 @@ func formatErrorForUser(err error) string {
 	errStr := err.Error()
 
 	switch {
+	case providers.IsContextOverflow(err):
+		return "📚 This conversation no longer fits in the model's context window, even shortened. Send a shorter message, or /reset to start over."
 	case strings.Contains(errStr, "429") || strings.Contains(errStr, "rate") || strings.Contains(errStr, "exhausted"):
 		return "⚠️ API rate limit reached. Please wait a moment and try again."
 	case strings.Contains(errStr, "context deadline exceeded") || strings.Contains(errStr, "timeout"):
@@ -2,24 +2,31 @@ package agent
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/ntminh611/mclaw/pkg/budget"
+	"github.com/ntminh611/mclaw/pkg/bus"
 	"github.com/ntminh611/mclaw/pkg/config"
 	"github.com/ntminh611/mclaw/pkg/providers"
 )
 
-// ModelSwitcher manages automatic model fallback on 429 rate limit errors.
-// When the primary model is rate-limited, it switches to fallback models.
-// At the start of a new day (local time), it resets back to the primary model.
+// ModelSwitcher sends chat requests to the primary model and, when a call
+// fails, retries it on the fallback chain for that kind of error (rate
+// limit, timeout or content filter). A model that failed is skipped until
+// its cooldown ends; while the primary cools down, calls stay on the
+// fallback that answered, and switch back once the cooldown is over.
 type ModelSwitcher struct {
 	cfg             *config.Config
 	primaryModel    string
-	fallbackModels  []string
+	chains          map[string]config.FallbackChain // by error class
 	currentModel    string
 	currentProvider providers.LLMProvider
-	rateLimitDay    int // day of year when rate limit was hit (-1 = not rate limited)
+	cooldowns       map[string]time.Time // model -> end of its cooldown
+	spend           *budget.Tracker
+	newProvider     func(model string) (providers.LLMProvider, error)
 	mu              sync.RWMutex
 }
 
@@ -28,10 +35,13 @@ func NewModelSwitcher(cfg *config.Config, initialProvider providers.LLMProvider)
 	return &ModelSwitcher{
 		cfg:             cfg,
 		primaryModel:    cfg.Agents.Defaults.Model,
-		fallbackModels:  cfg.Agents.Defaults.FallbackModels,
+		chains:          cfg.Agents.Defaults.FallbackChains(),
 		currentModel:    cfg.Agents.Defaults.Model,
 		currentProvider: initialProvider,
-		rateLimitDay:    -1,
+		cooldowns:       make(map[string]time.Time),
+		newProvider: func(model string) (providers.LLMProvider, error) {
+			return providers.CreateProviderForModel(cfg, model)
+		},
 	}
 }
 
@@ -49,120 +59,203 @@ func (ms *ModelSwitcher) CurrentProvider() providers.LLMProvider {
 	return ms.currentProvider
 }
 
-// Chat sends a chat request with automatic fallback on 429 errors.
-// If the current model returns 429, it switches to the next fallback model
-// and retries the request once.
+// SetModels replaces the primary model and the fallback chains, e.g. after
+// a config reload, and switches to the new primary right away.
+func (ms *ModelSwitcher) SetModels(primary string, chains map[string]config.FallbackChain) error {
+	provider, err := ms.newProvider(primary)
+	if err != nil {
+		return err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.primaryModel = primary
+	ms.chains = chains
+	ms.currentModel = primary
+	ms.currentProvider = provider
+	ms.cooldowns = make(map[string]time.Time)
+	return nil
+}
+
+// Chat sends a chat request, falling back along the chain for the error
+// class when the call fails. The first failure is returned when no model in
+// the chain answers.
 func (ms *ModelSwitcher) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, options map[string]interface{}) (*providers.LLMResponse, error) {
-	ms.maybeResetDaily()
+	ms.maybeRestorePrimary(time.Now())
 
 	ms.mu.RLock()
 	model := ms.currentModel
 	provider := ms.currentProvider
 	ms.mu.RUnlock()
 
-	response, err := provider.Chat(ctx, messages, tools, model, options)
-	if err == nil {
-		return response, nil
+	response, err := ms.try(ctx, provider, model, messages, tools, options)
+	class := failureClass(ctx, response, err)
+	if class == "" {
+		return response, err
+	}
+
+	tried := map[string]bool{model: true}
+	failed := model
+	for {
+		ms.coolDown(failed, class, time.Now())
+		next, nextProvider, ok := ms.nextFallback(class, tried, time.Now())
+		if !ok {
+			log.Printf("[model-switcher] No %s fallback left for %s", class, model)
+			return response, err
+		}
+		tried[next] = true
+		log.Printf("[model-switcher] %s on %s, retrying with %s", class, failed, next)
+
+		nextResponse, nextErr := ms.try(ctx, nextProvider, next, messages, tools, options)
+		nextClass := failureClass(ctx, nextResponse, nextErr)
+		if nextClass == "" {
+			if nextErr == nil {
+				ms.adopt(class, model, next, nextProvider)
+			}
+			return nextResponse, nextErr
+		}
+		failed = next
 	}
+}
 
-	// Check if it's a rate limit error
-	if !providers.IsRateLimitError(err) {
-		return nil, err
+// try makes one call and records its cost.
+func (ms *ModelSwitcher) try(ctx context.Context, provider providers.LLMProvider, model string, messages []providers.Message, tools []providers.ToolDefinition, options map[string]interface{}) (*providers.LLMResponse, error) {
+	response, err := provider.Chat(ctx, messages, tools, model, options)
+	if err == nil && response != nil {
+		ms.spend.Record(model, response.Usage, time.Now())
 	}
+	return response, err
+}
 
-	log.Printf("[model-switcher] Rate limit hit on model %s, attempting fallback...", model)
+// failureClass returns the error class of a call worth retrying on another
+// model, or "" when it succeeded or another model would not help. Calls cut
+// short by the caller's own context are never retried.
+func failureClass(ctx context.Context, response *providers.LLMResponse, err error) string {
+	if ctx.Err() != nil {
+		return ""
+	}
+	if err != nil {
+		return providers.ClassifyError(err)
+	}
+	if response != nil && response.FinishReason == "content_filter" {
+		return providers.ErrorContentFilter
+	}
+	return ""
+}
 
-	// Try to switch to next model
-	if !ms.switchToNext() {
-		log.Printf("[model-switcher] No fallback models available, returning rate limit error")
-		return nil, err
+// coolDown puts a failed model aside for its chain's cooldown.
+func (ms *ModelSwitcher) coolDown(model, class string, now time.Time) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if minutes := ms.chains[class].CooldownMinutes; minutes > 0 {
+		ms.cooldowns[model] = now.Add(time.Duration(minutes) * time.Minute)
 	}
+}
 
-	// Retry with new model
+// nextFallback returns the first model in the class's chain that was not
+// tried yet and is not cooling down.
+func (ms *ModelSwitcher) nextFallback(class string, tried map[string]bool, now time.Time) (string, providers.LLMProvider, bool) {
 	ms.mu.RLock()
-	newModel := ms.currentModel
-	newProvider := ms.currentProvider
+	chain := ms.chains[class].Models
 	ms.mu.RUnlock()
 
-	log.Printf("[model-switcher] Retrying with fallback model: %s", newModel)
-	return newProvider.Chat(ctx, messages, tools, newModel, options)
+	for _, model := range chain {
+		if tried[model] || ms.coolingDown(model, now) {
+			continue
+		}
+		provider, err := ms.newProvider(model)
+		if err != nil {
+			log.Printf("[model-switcher] Failed to create provider for %s: %v", model, err)
+			tried[model] = true
+			continue
+		}
+		return model, provider, true
+	}
+	return "", nil, false
 }
 
-// switchToNext attempts to switch to the next available fallback model.
-// Returns true if a switch was made, false if no fallback is available.
-func (ms *ModelSwitcher) switchToNext() bool {
+// adopt keeps later calls on the fallback that answered while the model it
+// replaced cools down. Without a cooldown only the one call was moved.
+func (ms *ModelSwitcher) adopt(class, failed, model string, provider providers.LLMProvider) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
-
-	if len(ms.fallbackModels) == 0 {
-		return false
-	}
-
-	// Find current model in the fallback list to determine next
-	nextModel := ""
-	if ms.currentModel == ms.primaryModel {
-		// Switch from primary to first fallback
-		nextModel = ms.fallbackModels[0]
-	} else {
-		// Find current position in fallback list and try next
-		for i, m := range ms.fallbackModels {
-			if m == ms.currentModel {
-				if i+1 < len(ms.fallbackModels) {
-					nextModel = ms.fallbackModels[i+1]
-				}
-				break
-			}
-		}
+	if ms.chains[class].CooldownMinutes <= 0 || ms.currentModel != failed {
+		return
 	}
+	ms.currentModel = model
+	ms.currentProvider = provider
+	log.Printf("[model-switcher] ✅ Switched from %s to %s", failed, model)
+}
 
-	if nextModel == "" {
-		return false
+func (ms *ModelSwitcher) coolingDown(model string, now time.Time) bool {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return now.Before(ms.cooldowns[model])
+}
+
+// maybeRestorePrimary switches back to the primary model once its cooldown
+// is over.
+func (ms *ModelSwitcher) maybeRestorePrimary(now time.Time) {
+	ms.mu.RLock()
+	primary, current := ms.primaryModel, ms.currentModel
+	ms.mu.RUnlock()
+	if current == primary || ms.coolingDown(primary, now) {
+		return
 	}
 
-	// Create provider for the new model
-	provider, err := providers.CreateProviderForModel(ms.cfg, nextModel)
+	provider, err := ms.newProvider(primary)
 	if err != nil {
-		log.Printf("[model-switcher] Failed to create provider for %s: %v", nextModel, err)
-		return false
+		log.Printf("[model-switcher] Failed to restore primary model %s: %v", primary, err)
+		return
 	}
 
-	ms.currentModel = nextModel
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.primaryModel != primary || ms.currentModel != current {
+		return // changed meanwhile
+	}
+	log.Printf("[model-switcher] 🔄 Cooldown over — switching from %s back to primary model %s", current, primary)
+	ms.currentModel = primary
 	ms.currentProvider = provider
-	ms.rateLimitDay = time.Now().YearDay()
-
-	log.Printf("[model-switcher] ✅ Switched from rate-limited model to: %s", nextModel)
-	return true
+	delete(ms.cooldowns, primary)
 }
 
-// maybeResetDaily checks if a new day has started since the last rate limit,
-// and resets to the primary model if so.
-func (ms *ModelSwitcher) maybeResetDaily() {
-	ms.mu.Lock()
-	defer ms.mu.Unlock()
+// turnModel is what one turn talks to: the switcher, or a model the message
+// asked for by name or alias (metadata "model", e.g. from /retry pro) or
+// chosen for the session with /model.
+type turnModel struct {
+	switcher *ModelSwitcher
+	model    string
+	provider providers.LLMProvider // nil = use the switcher
+}
 
-	if ms.rateLimitDay < 0 {
-		return // not rate limited
+// turnLLM picks the model for msg's turn.
+func (al *AgentLoop) turnLLM(msg bus.InboundMessage) (turnModel, error) {
+	name := msg.Metadata["model"]
+	if name == "" {
+		name = al.sessions.GetModel(msg.SessionKey)
 	}
-
-	today := time.Now().YearDay()
-	if today == ms.rateLimitDay {
-		return // same day, keep fallback
+	if name == "" {
+		return turnModel{switcher: al.switcher}, nil
 	}
-
-	// New day — reset to primary
-	if ms.currentModel == ms.primaryModel {
-		ms.rateLimitDay = -1
-		return
+	model := al.cfg.ResolveModel(name)
+	provider, err := providers.CreateProviderForModel(al.cfg, model)
+	if err != nil {
+		return turnModel{}, fmt.Errorf("can't use model %s: %v", name, err)
 	}
+	return turnModel{model: model, provider: al.spend.Meter(provider)}, nil
+}
 
-	provider, err := providers.CreateProviderForModel(ms.cfg, ms.primaryModel)
-	if err != nil {
-		log.Printf("[model-switcher] Failed to reset to primary model %s: %v", ms.primaryModel, err)
-		return
+func (t turnModel) Model() string {
+	if t.provider != nil {
+		return t.model
 	}
+	return t.switcher.CurrentModel()
+}
 
-	log.Printf("[model-switcher] 🔄 New day — resetting from %s back to primary model: %s", ms.currentModel, ms.primaryModel)
-	ms.currentModel = ms.primaryModel
-	ms.currentProvider = provider
-	ms.rateLimitDay = -1
+func (t turnModel) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, options map[string]interface{}) (*providers.LLMResponse, error) {
+	if t.provider != nil {
+		return t.provider.Chat(ctx, messages, tools, t.model, options)
+	}
+	return t.switcher.Chat(ctx, messages, tools, options)
 }
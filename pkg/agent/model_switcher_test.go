@@ -1,8 +1,10 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/ntminh611/mclaw/pkg/config"
 	"github.com/ntminh611/mclaw/pkg/providers"
@@ -22,79 +24,112 @@ func TestModelSwitcherInit(t *testing.T) {
 	}
 }
 
-func TestModelSwitcherNoFallback(t *testing.T) {
+// newTestSwitcher returns a switcher whose models are mock providers.
+func newTestSwitcher(chains map[string]config.FallbackChain, mocks map[string]*providers.MockProvider) *ModelSwitcher {
 	cfg := &config.Config{}
-	cfg.Agents.Defaults.Model = "gemini/gemini-3-pro"
-	cfg.Agents.Defaults.FallbackModels = nil
-	cfg.Providers.Gemini.APIKey = "test-key"
-
-	provider, _ := providers.CreateProviderForModel(cfg, cfg.Agents.Defaults.Model)
-	ms := NewModelSwitcher(cfg, provider)
-
-	// switchToNext should return false when no fallbacks
-	if ms.switchToNext() {
-		t.Error("expected switchToNext to return false with no fallback models")
+	cfg.Agents.Defaults.Model = "primary"
+	ms := NewModelSwitcher(cfg, mocks["primary"])
+	ms.chains = chains
+	ms.newProvider = func(model string) (providers.LLMProvider, error) {
+		if m, ok := mocks[model]; ok {
+			return m, nil
+		}
+		return nil, fmt.Errorf("no provider for %s", model)
 	}
+	return ms
 }
 
-func TestModelSwitcherSwitchToFallback(t *testing.T) {
-	cfg := &config.Config{}
-	cfg.Agents.Defaults.Model = "gemini/gemini-3-pro"
-	cfg.Agents.Defaults.FallbackModels = []string{"gemini/gemini-2.0-flash", "gemini/gemini-2.0-flash-lite"}
-	cfg.Providers.Gemini.APIKey = "test-key"
-
-	provider, _ := providers.CreateProviderForModel(cfg, cfg.Agents.Defaults.Model)
-	ms := NewModelSwitcher(cfg, provider)
+func TestModelSwitcherNoFallback(t *testing.T) {
+	primary := providers.NewMockProvider().Fail(&providers.RateLimitError{StatusCode: 429}).SetDefault("ok")
+	ms := newTestSwitcher(nil, map[string]*providers.MockProvider{"primary": primary})
 
-	// Switch to first fallback
-	if !ms.switchToNext() {
-		t.Fatal("expected switchToNext to succeed")
+	if _, err := ms.Chat(context.Background(), nil, nil, nil); !providers.IsRateLimitError(err) {
+		t.Errorf("expected the rate limit error without fallbacks, got %v", err)
 	}
-	if ms.CurrentModel() != "gemini/gemini-2.0-flash" {
-		t.Errorf("expected gemini-2.0-flash, got %s", ms.CurrentModel())
+	if ms.CurrentModel() != "primary" {
+		t.Errorf("expected to stay on the primary model, got %s", ms.CurrentModel())
 	}
+}
 
-	// Switch to second fallback
-	if !ms.switchToNext() {
-		t.Fatal("expected switchToNext to succeed for second fallback")
+func TestModelSwitcherChainPerErrorClass(t *testing.T) {
+	chains := map[string]config.FallbackChain{
+		providers.ErrorRateLimit: {Models: []string{"busy", "cheap"}, CooldownMinutes: 60},
+		providers.ErrorTimeout:   {Models: []string{"fast"}, CooldownMinutes: 10},
 	}
-	if ms.CurrentModel() != "gemini/gemini-2.0-flash-lite" {
-		t.Errorf("expected gemini-2.0-flash-lite, got %s", ms.CurrentModel())
+	mocks := map[string]*providers.MockProvider{
+		"primary": providers.NewMockProvider().
+			Fail(&providers.RateLimitError{StatusCode: 429}).
+			SetDefault("primary"),
+		"busy":  providers.NewMockProvider().Fail(&providers.APIError{StatusCode: 529, Body: "overloaded"}).SetDefault("busy"),
+		"cheap": providers.NewMockProvider().SetDefault("cheap"),
+		"fast":  providers.NewMockProvider().SetDefault("fast"),
 	}
+	ms := newTestSwitcher(chains, mocks)
 
-	// No more fallbacks
-	if ms.switchToNext() {
-		t.Error("expected switchToNext to return false when all fallbacks exhausted")
+	// A rate limit walks the rate-limit chain, skipping the overloaded model
+	resp, err := ms.Chat(context.Background(), nil, nil, nil)
+	if err != nil || resp.Content != "cheap" {
+		t.Fatalf("expected the answer from cheap, got %+v, %v", resp, err)
+	}
+	if ms.CurrentModel() != "cheap" {
+		t.Errorf("expected to stay on cheap while primary cools down, got %s", ms.CurrentModel())
 	}
-}
 
-func TestModelSwitcherDailyReset(t *testing.T) {
-	cfg := &config.Config{}
-	cfg.Agents.Defaults.Model = "gemini/gemini-3-pro"
-	cfg.Agents.Defaults.FallbackModels = []string{"gemini/gemini-2.0-flash"}
-	cfg.Providers.Gemini.APIKey = "test-key"
+	// Once primary's cooldown is over, calls go back to it
+	ms.mu.Lock()
+	ms.cooldowns["primary"] = time.Now().Add(-time.Second)
+	ms.mu.Unlock()
+	if resp, _ := ms.Chat(context.Background(), nil, nil, nil); resp.Content != "primary" || ms.CurrentModel() != "primary" {
+		t.Errorf("expected primary after its cooldown, got %q on %s", resp.Content, ms.CurrentModel())
+	}
 
-	provider, _ := providers.CreateProviderForModel(cfg, cfg.Agents.Defaults.Model)
-	ms := NewModelSwitcher(cfg, provider)
+	// A timeout uses its own chain
+	mocks["primary"].Fail(providers.ErrStreamStalled)
+	if resp, _ := ms.Chat(context.Background(), nil, nil, nil); resp.Content != "fast" {
+		t.Errorf("expected the timeout chain, got %q", resp.Content)
+	}
+}
 
-	// Switch to fallback
-	ms.switchToNext()
-	if ms.CurrentModel() != "gemini/gemini-2.0-flash" {
-		t.Fatalf("expected fallback model, got %s", ms.CurrentModel())
+func TestModelSwitcherContentFilterRetriesOnce(t *testing.T) {
+	chains := map[string]config.FallbackChain{
+		providers.ErrorContentFilter: {Models: []string{"lenient"}},
+	}
+	mocks := map[string]*providers.MockProvider{
+		"primary": providers.NewMockProvider().
+			Respond(func(providers.MockCall) (*providers.LLMResponse, error) {
+				return &providers.LLMResponse{FinishReason: "content_filter"}, nil
+			}).
+			SetDefault("primary"),
+		"lenient": providers.NewMockProvider().SetDefault("lenient"),
 	}
+	ms := newTestSwitcher(chains, mocks)
 
-	// Simulate day change by setting rateLimitDay to yesterday
-	ms.mu.Lock()
-	ms.rateLimitDay = ms.rateLimitDay - 1
-	if ms.rateLimitDay < 0 {
-		ms.rateLimitDay = 364 // wrap around
+	if resp, _ := ms.Chat(context.Background(), nil, nil, nil); resp.Content != "lenient" {
+		t.Errorf("expected the filtered call to be answered by lenient, got %q", resp.Content)
 	}
-	ms.mu.Unlock()
+	// Without a cooldown the next call goes to the primary model again
+	if resp, _ := ms.Chat(context.Background(), nil, nil, nil); resp.Content != "primary" || ms.CurrentModel() != "primary" {
+		t.Errorf("expected primary for the next call, got %q", resp.Content)
+	}
+}
 
-	// maybeResetDaily should reset to primary
-	ms.maybeResetDaily()
-	if ms.CurrentModel() != "gemini/gemini-3-pro" {
-		t.Errorf("expected reset to primary model, got %s", ms.CurrentModel())
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err   error
+		class string
+	}{
+		{&providers.RateLimitError{StatusCode: 429}, providers.ErrorRateLimit},
+		{&providers.APIError{StatusCode: 503, Body: "overloaded"}, providers.ErrorRateLimit},
+		{fmt.Errorf("read: %w", providers.ErrStreamStalled), providers.ErrorTimeout},
+		{&providers.APIError{StatusCode: 504}, providers.ErrorTimeout},
+		{&providers.APIError{StatusCode: 400, Body: `{"error":{"code":"content_filter"}}`}, providers.ErrorContentFilter},
+		{&providers.APIError{StatusCode: 401, Body: "invalid key"}, ""},
+		{fmt.Errorf("some other error"), ""},
+	}
+	for _, tt := range tests {
+		if got := providers.ClassifyError(tt.err); got != tt.class {
+			t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.class)
+		}
 	}
 }
 
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/monitors"
+	"github.com/ntminh611/mclaw/pkg/netguard"
+)
+
+// newMonitorService opens the monitor store. Returns nil when monitors are
+// disabled or the store is unavailable.
+func newMonitorService(cfg *config.Config, mb *bus.MessageBus, guard *netguard.Guard, dbPath string) *monitors.Service {
+	if !cfg.Monitors.Enabled {
+		return nil
+	}
+	store, err := monitors.NewStore(dbPath)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Monitor store unavailable, monitors disabled: %v", err))
+		return nil
+	}
+	timeout := time.Duration(cfg.Monitors.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	svc := monitors.NewService(store, guard.Client(timeout), func(channel, chatID, content string) {
+		// Alerts go out even during quiet hours
+		mb.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: content, Proactive: true, Urgent: true})
+	})
+	svc.SetDefaults(time.Duration(cfg.Monitors.IntervalMinutes)*time.Minute, cfg.Monitors.FailuresBeforeAlert,
+		time.Duration(cfg.Monitors.RepeatAlertMinutes)*time.Minute)
+	return svc
+}
+
+// EnableMonitors schedules the uptime monitors' checks on cs.
+func (al *AgentLoop) EnableMonitors(cs *cron.CronService) {
+	if al.monitors == nil {
+		return
+	}
+	if err := al.monitors.Attach(cs); err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Failed to load monitors: %v", err))
+	}
+}
+
+// GetMonitors returns the monitor service, or nil if monitors are disabled.
+func (al *AgentLoop) GetMonitors() *monitors.Service {
+	return al.monitors
+}
@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/ocr"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// newOCREngine picks the engine for ocr.engine: tesseract when "auto" finds
+// it installed, else the vision model. Returns nil when OCR is off or no
+// engine is available.
+func newOCREngine(cfg *config.Config, visionProvider providers.LLMProvider, visionModel string) ocr.Engine {
+	if !cfg.OCR.Enabled {
+		return nil
+	}
+
+	engine := cfg.OCR.Engine
+	if engine == "" || engine == "auto" || engine == "tesseract" {
+		tesseract, err := ocr.NewTesseract(cfg.OCR.TesseractPath, cfg.OCR.Languages)
+		if err == nil {
+			return tesseract
+		}
+		if engine == "tesseract" {
+			logger.WarnC("agent", fmt.Sprintf("OCR disabled: %v", err))
+			return nil
+		}
+	}
+	if visionProvider == nil {
+		logger.WarnC("agent", "OCR disabled: tesseract is not installed and no vision_model is configured")
+		return nil
+	}
+	return ocr.NewVision(visionProvider, visionModel)
+}
+
+// ocrInbound appends the text found in the images of msg to its content.
+// When only_text_models is set, images sent to a model that can see them
+// are left alone.
+func (al *AgentLoop) ocrInbound(ctx context.Context, msg bus.InboundMessage, model string) string {
+	content := msg.Content
+	if al.ocr == nil || len(msg.Media) == 0 {
+		return content
+	}
+	if al.cfg.OCR.OnlyTextModels && al.models.Lookup(model).Vision {
+		return content
+	}
+
+	for _, path := range msg.Media {
+		if !isImageFile(path) {
+			continue
+		}
+		imgCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		start := time.Now()
+		text, err := al.ocr.Extract(imgCtx, path)
+		cancel()
+		if err != nil {
+			logger.WarnC("agent", fmt.Sprintf("OCR of %s failed: %v", path, err))
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		text, truncated := ocr.Truncate(text, al.cfg.OCR.MaxChars)
+		if truncated {
+			text += "\n…(truncated)"
+		}
+		logger.InfoC("agent", fmt.Sprintf("OCR found %d chars in %s (%s, %s)", len(text), filepath.Base(path),
+			al.ocr.Name(), time.Since(start).Round(time.Millisecond)))
+		content = strings.TrimSpace(content) + fmt.Sprintf("\n\n[Text in image %s (OCR, %s)]:\n%s",
+			filepath.Base(path), al.ocr.Name(), text)
+	}
+	return content
+}
+
+// isImageFile reports whether path is an image small enough for OCR.
+func isImageFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > ocr.MaxImageSize {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	return strings.HasPrefix(http.DetectContentType(head[:n]), "image/")
+}
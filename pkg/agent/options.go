@@ -0,0 +1,20 @@
+package agent
+
+import "github.com/ntminh611/mclaw/pkg/session"
+
+// chatOptions returns the generation options for a turn: the defaults with
+// the session's /set overrides on top.
+func (al *AgentLoop) chatOptions(sessionKey string) map[string]interface{} {
+	options := map[string]interface{}{
+		"max_tokens":  8192,
+		"temperature": 0.7,
+	}
+	for name, v := range al.sessions.GetOptions(sessionKey) {
+		if o, ok := session.LookupOption(name); ok && o.Integer {
+			options[name] = int(v)
+		} else {
+			options[name] = v
+		}
+	}
+	return options
+}
@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// Tool results and messages are cut to this many characters when a request
+// overflows the context window.
+const overflowKeepChars = 2000
+
+// shrinkContext makes a request that overflowed the context window fit: the
+// conversation history before the current turn is dropped (its summary is
+// already in the system prompt) and long tool results and messages from the
+// turn are cut short. The current user message follows the system prompt in
+// the result.
+func shrinkContext(messages []providers.Message, turnStart int) []providers.Message {
+	shrunk := make([]providers.Message, 0, len(messages)-turnStart+1)
+	shrunk = append(shrunk, messages[0])
+	for _, m := range messages[turnStart:] {
+		if len(m.Content) > overflowKeepChars {
+			m.Content = m.Content[:overflowKeepChars] + fmt.Sprintf("\n... [%d characters cut to fit the context window]", len(m.Content)-overflowKeepChars)
+		}
+		shrunk = append(shrunk, m)
+	}
+	return shrunk
+}
+
+// droppedHistoryNote tells the model why earlier messages are missing.
+func droppedHistoryNote(n int) string {
+	return fmt.Sprintf("\n\n(The %d earlier messages of this conversation were dropped to fit the context window. Ask the user if you need something from them.)", n)
+}
@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// scopeToProject points the file tools and exec at the session's active
+// project (or the workspace) and limits tools to the project's defaults.
+// Returns the system-prompt section describing the project, if any.
+func (al *AgentLoop) scopeToProject(scope *tools.Scope, sessionKey string) string {
+	scope.WorkDir, scope.Allowed = al.workspace, nil
+	name := al.sessions.GetProject(sessionKey)
+	if name == "" {
+		return ""
+	}
+
+	project, ok := al.cfg.Project(name)
+	if !ok {
+		logger.WarnC("agent", fmt.Sprintf("Session %s uses unknown project %q, falling back to workspace", sessionKey, name))
+		return ""
+	}
+
+	scope.WorkDir, scope.Allowed = project.Path, project.Tools
+	return projectSection(project)
+}
+
+func projectSection(p config.ProjectConfig) string {
+	var sb strings.Builder
+	sb.WriteString("## Active Project\n")
+	sb.WriteString(fmt.Sprintf("Name: %s\nPath: %s\n", p.Name, p.Path))
+	if p.Description != "" {
+		sb.WriteString("Description: " + p.Description + "\n")
+	}
+	sb.WriteString("\nWork inside this project's directory: relative paths in file tools and exec resolve against it. " +
+		"Do not modify files of other projects unless the user asks.")
+	return sb.String()
+}
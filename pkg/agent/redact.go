@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/redact"
+)
+
+// secretFilter scrubs credentials from tool results, logs and saved
+// sessions. It holds the current redactor so secrets.redact can be changed
+// with a config reload.
+type secretFilter struct {
+	current atomic.Pointer[redact.Redactor]
+}
+
+func newSecretFilter(cfg *config.Config) *secretFilter {
+	f := &secretFilter{}
+	f.update(cfg)
+	return f
+}
+
+// update rebuilds the redactor from the config's credentials and patterns.
+func (f *secretFilter) update(cfg *config.Config) {
+	r, err := redact.New(cfg.SecretValues(), cfg.Secrets.Redact)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Skipping secrets.redact entries: %v", err))
+	}
+	f.current.Store(r)
+}
+
+// Redact returns s with secrets masked.
+func (f *secretFilter) Redact(s string) string {
+	return f.current.Load().Redact(s)
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// configWatchInterval is how often the config file is checked for edits.
+const configWatchInterval = 5 * time.Second
+
+// applyConfig refreshes the values the loop copied out of the config at
+// startup. Tool policy and projects are read from the config per message
+// and need nothing here.
+func (al *AgentLoop) applyConfig(cfg *config.Config, changed []string) {
+	for _, name := range changed {
+		switch name {
+		case "agents.defaults.model", "agents.defaults.fallback_models", "agents.defaults.fallbacks":
+			model := cfg.Agents.Defaults.Model
+			if err := al.switcher.SetModels(model, cfg.Agents.Defaults.FallbackChains()); err != nil {
+				logger.WarnC("agent", fmt.Sprintf("Keeping model %s: %v", al.switcher.CurrentModel(), err))
+				continue
+			}
+			al.model = model
+			logger.InfoC("agent", fmt.Sprintf("Model switched to %s", model))
+		case "agents.defaults.max_tokens":
+			al.contextWindow = cfg.Agents.Defaults.MaxTokens
+		case "agents.defaults.max_tool_iterations":
+			al.maxIterations = cfg.Agents.Defaults.MaxToolIterations
+		case "agents.defaults.long_message_chars":
+			al.inboundLimit = cfg.Agents.Defaults.LongMessageChars
+		case "agents.defaults.coalesce_ms":
+			al.coalesceWindow = time.Duration(cfg.Agents.Defaults.CoalesceMs) * time.Millisecond
+		case "secrets.redact":
+			al.secrets.update(cfg)
+		case "digest":
+			al.scheduleDigest(cfg)
+		case "usage", "budget", "model_info":
+			al.usageCfg = cfg.Usage
+			al.models.Apply(cfg)
+			al.spend.SetLimit(cfg.Budget.DailyUSD, al.models.Pricing())
+		case "memory.top_k", "memory.min_score", "memory.max_memories":
+			if al.memory != nil {
+				al.memory.SetLimits(cfg.Memory.TopK, cfg.Memory.MinScore, cfg.Memory.MaxMemories)
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/reminders"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// EnableReminders schedules reminders and send_later messages on cs.
+// Reminders set before this is called, or while mclaw was stopped, are
+// scheduled or delivered now.
+func (al *AgentLoop) EnableReminders(cs *cron.CronService) {
+	if t, ok := al.tools.Get("send_later"); ok {
+		t.(*tools.SendLaterTool).SetCronService(cs)
+	}
+	if al.reminders == nil {
+		return
+	}
+	if err := al.reminders.Attach(cs); err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Failed to load reminders: %v", err))
+	}
+}
+
+// GetReminders returns the reminder service, or nil if it is unavailable.
+func (al *AgentLoop) GetReminders() *reminders.Service {
+	return al.reminders
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/auth"
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// errOverBudget stops background LLM work once the daily budget is spent.
+var errOverBudget = errors.New("daily LLM budget reached, background work resumes tomorrow")
+
+// overBudget decides whether a turn may still call the LLM once the daily
+// budget is spent: the owner's own messages are answered, other users get
+// the returned reply and background turns (cron, heartbeat, webhooks) fail.
+func (al *AgentLoop) overBudget(msg bus.InboundMessage) (string, error) {
+	if !al.spend.Exceeded(time.Now()) {
+		return "", nil
+	}
+	if msg.Priority == bus.PriorityBackground {
+		logger.WarnC("agent", fmt.Sprintf("Skipping background turn for %s: daily budget reached", msg.SessionKey))
+		return "", errOverBudget
+	}
+	if role, _ := al.auth.Role(msg.Channel, msg.SenderID); role == auth.RoleOwner {
+		return "", nil
+	}
+	return "💸 I've reached today's spending limit. I'll be back tomorrow!", nil
+}
+
+// budgetNotifier tells the owner when the daily budget runs out.
+func budgetNotifier(cfg *config.Config, mb *bus.MessageBus) func(spent, limit float64) {
+	return func(spent, limit float64) {
+		channel, chatID := cfg.Budget.Channel, cfg.Budget.ChatID
+		if chatID == "" {
+			chatID = cfg.OwnerChat(channel)
+		}
+		if channel == "" || chatID == "" {
+			logger.WarnC("agent", "Daily budget reached but there is no owner chat to tell")
+			return
+		}
+		mb.PublishOutbound(bus.OutboundMessage{
+			Channel: channel,
+			ChatID:  chatID,
+			Content: fmt.Sprintf("💸 Today's LLM budget of $%.2f is used up ($%.2f spent). "+
+				"Until midnight I only answer you; other users, cron jobs, heartbeats and memory extraction are paused. "+
+				"Raise budget.daily_usd to resume sooner.", limit, spent),
+		})
+	}
+}
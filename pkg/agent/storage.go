@@ -0,0 +1,18 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/storage"
+)
+
+// runJanitor keeps downloaded media, the inbox and logs within
+// storage.limits until ctx is cancelled.
+func (al *AgentLoop) runJanitor(ctx context.Context) {
+	interval := time.Duration(al.cfg.Storage.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	storage.NewJanitor(storage.Targets(al.cfg)).Run(ctx, interval)
+}
@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// newSummarizer creates a dedicated provider for agents.defaults.summary_model,
+// independent of the ModelSwitcher, so summarization can run on a cheap fast
+// model. Returns nil when unset or when the provider cannot be created.
+func newSummarizer(cfg *config.Config) (providers.LLMProvider, string) {
+	model := cfg.Agents.Defaults.SummaryModel
+	if model == "" || model == cfg.Agents.Defaults.Model {
+		return nil, ""
+	}
+
+	provider, err := providers.CreateProviderForModel(cfg, model)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Failed to create provider for summary_model %s, using agent model: %v", model, err))
+		return nil, ""
+	}
+
+	logger.InfoC("agent", fmt.Sprintf("Using dedicated summary_model: %s", model))
+	return provider, model
+}
+
+// summaryChat runs a summarization request on the summary model, falling
+// back to the agent's active model if it is unset or fails.
+func (al *AgentLoop) summaryChat(ctx context.Context, messages []providers.Message, options map[string]interface{}) (*providers.LLMResponse, error) {
+	if al.summarizer != nil {
+		resp, err := al.summarizer.Chat(ctx, messages, nil, al.summaryModel, options)
+		if err == nil {
+			return resp, nil
+		}
+		logger.WarnC("agent", fmt.Sprintf("Summary model %s failed, using agent model: %v", al.summaryModel, err))
+	}
+	return al.switcher.Chat(ctx, messages, nil, options)
+}
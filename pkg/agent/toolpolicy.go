@@ -0,0 +1,24 @@
+package agent
+
+import (
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// deniedTools combines the channel's tool policy, the sender's role and
+// the tools the user switched off for this session via /tools.
+func (al *AgentLoop) deniedTools(msg bus.InboundMessage) map[string]string {
+	denied := tools.PolicyDenied(al.cfg.Tools.Policy, msg.Channel, tools.IsGroupChat(msg.Metadata))
+	role, _ := al.auth.Role(msg.Channel, msg.SenderID)
+	for name, reason := range al.auth.DeniedTools(role, al.tools.Names()) {
+		if _, ok := denied[name]; !ok {
+			denied[name] = reason
+		}
+	}
+	for _, name := range al.sessions.GetDisabledTools(msg.SessionKey) {
+		if _, ok := denied[name]; !ok {
+			denied[name] = "turned off in this chat (/tools on " + name + " to enable)"
+		}
+	}
+	return denied
+}
@@ -0,0 +1,17 @@
+package agent
+
+import (
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// newToolScope resolves what the tools work on during msg's turn: the chat
+// for cron delivery, the session for scratchpad and pins, the project's
+// directory and tool set, and the tools denied in this chat. Returns the
+// system-prompt section for the session's project, if any.
+func (al *AgentLoop) newToolScope(msg bus.InboundMessage) (tools.Scope, string) {
+	scope := tools.Scope{Channel: msg.Channel, ChatID: msg.ChatID, Group: tools.IsGroupChat(msg.Metadata), SessionKey: msg.SessionKey}
+	projectPrompt := al.scopeToProject(&scope, msg.SessionKey)
+	scope.Denied = al.deniedTools(msg)
+	return scope, projectPrompt
+}
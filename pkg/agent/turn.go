@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// TurnResult is the machine-readable outcome of one agent turn, as emitted
+// by `mclaw agent --json`.
+type TurnResult struct {
+	Content    string           `json:"content"`
+	ToolCalls  []ToolCallRecord `json:"tool_calls"`
+	Usage      TurnUsage        `json:"usage"`
+	Iterations int              `json:"iterations"`
+	Model      string           `json:"model"`
+	SessionKey string           `json:"session_key"`
+	usage      *usageTracker
+}
+
+// ToolCallRecord is one tool call made during a turn.
+type ToolCallRecord struct {
+	Name       string                 `json:"name"`
+	Arguments  map[string]interface{} `json:"arguments"`
+	Result     string                 `json:"result"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// TurnUsage totals the tokens and cost of a turn's LLM calls.
+type TurnUsage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	Estimated        bool    `json:"estimated"` // token counts were estimated, not reported
+}
+
+// ProcessDirectResult is ProcessDirect returning the full turn result
+// instead of the reply text alone.
+func (al *AgentLoop) ProcessDirectResult(ctx context.Context, content, sessionKey string) (*TurnResult, error) {
+	return al.runTurn(ctx, bus.InboundMessage{
+		Channel:    "cli",
+		SenderID:   "user",
+		ChatID:     "direct",
+		Content:    content,
+		SessionKey: sessionKey,
+	})
+}
+
+// Summary returns the accumulated usage.
+func (u *usageTracker) Summary() TurnUsage {
+	return TurnUsage{
+		PromptTokens:     u.prompt,
+		CompletionTokens: u.output,
+		TotalTokens:      u.Total(),
+		CostUSD:          u.cost,
+		Estimated:        u.estimated,
+	}
+}
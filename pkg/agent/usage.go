@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/ntminh611/mclaw/pkg/budget"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// usageTracker accumulates token usage and cost across the LLM calls of one turn.
+type usageTracker struct {
+	pricing   map[string]config.ModelPrice
+	prompt    int
+	output    int
+	cost      float64
+	estimated bool
+}
+
+func newUsageTracker(pricing map[string]config.ModelPrice) *usageTracker {
+	return &usageTracker{pricing: pricing}
+}
+
+// Add records the usage of a single LLM call made with the given model.
+func (u *usageTracker) Add(model string, usage *providers.UsageInfo) {
+	if usage == nil {
+		return
+	}
+	u.prompt += usage.PromptTokens
+	u.output += usage.CompletionTokens
+	if usage.Estimated {
+		u.estimated = true
+	}
+	u.cost += budget.Cost(u.pricing, model, usage)
+}
+
+func (u *usageTracker) Total() int {
+	return u.prompt + u.output
+}
+
+func (u *usageTracker) Cost() float64 {
+	return u.cost
+}
+
+// Footer renders a compact usage line, e.g. "≈ 3.2k tokens, $0.004".
+func (u *usageTracker) Footer() string {
+	if u.Total() == 0 {
+		return ""
+	}
+	footer := "≈ " + formatTokenCount(u.Total()) + " tokens"
+	if u.cost > 0 {
+		footer += fmt.Sprintf(", $%.3f", u.cost)
+	}
+	return footer
+}
+
+func formatTokenCount(n int) string {
+	if n >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+func TestUsageTrackerFooter(t *testing.T) {
+	pricing := map[string]config.ModelPrice{
+		"gemini-2.5-flash": {Input: 1.0, Output: 2.0},
+	}
+	u := newUsageTracker(pricing)
+
+	if u.Footer() != "" {
+		t.Errorf("expected empty footer with no usage, got %q", u.Footer())
+	}
+
+	u.Add("gemini/gemini-2.5-flash", &providers.UsageInfo{PromptTokens: 2000, CompletionTokens: 1200, TotalTokens: 3200})
+	u.Add("gemini/gemini-2.5-flash", nil)
+
+	if u.Total() != 3200 {
+		t.Errorf("expected 3200 tokens, got %d", u.Total())
+	}
+
+	want := "≈ 3.2k tokens, $0.004"
+	if got := u.Footer(); got != want {
+		t.Errorf("expected footer %q, got %q", want, got)
+	}
+}
+
+func TestUsageTrackerUnknownModelHasNoCost(t *testing.T) {
+	u := newUsageTracker(nil)
+	u.Add("glm-4.7", &providers.UsageInfo{PromptTokens: 500, CompletionTokens: 100, Estimated: true})
+
+	if u.Cost() != 0 {
+		t.Errorf("expected zero cost for unpriced model, got %f", u.Cost())
+	}
+	if got := u.Footer(); got != "≈ 600 tokens" {
+		t.Errorf("unexpected footer %q", got)
+	}
+}
@@ -0,0 +1,25 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// newVisionProvider creates the provider behind describe_image for
+// agents.defaults.vision_model. Returns nil when unset or unavailable.
+func newVisionProvider(cfg *config.Config) (providers.LLMProvider, string) {
+	model := cfg.Agents.Defaults.VisionModel
+	if model == "" {
+		return nil, ""
+	}
+
+	provider, err := providers.CreateProviderForModel(cfg, model)
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Failed to create provider for vision_model %s, describe_image disabled: %v", model, err))
+		return nil, ""
+	}
+	return provider, model
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/watch"
+)
+
+// newWatchService sets up the watched paths, or returns nil when watching
+// is disabled. Changes run as background turns in a "watch:<name>"
+// session.
+func newWatchService(al *AgentLoop, dataDir string) *watch.Service {
+	cfg := al.cfg.Watches
+	if !cfg.Enabled {
+		return nil
+	}
+	configured := make([]watch.Watch, 0, len(cfg.Paths))
+	for _, p := range cfg.Paths {
+		configured = append(configured, watch.Watch{
+			Name: p.Name, Path: p.Path, Pattern: p.Pattern, Recursive: p.Recursive, Events: p.Events,
+			Prompt: p.Prompt, Channel: p.Channel, ChatID: p.ChatID,
+		})
+	}
+	svc, err := watch.NewService(filepath.Join(dataDir, "watches.json"), time.Duration(cfg.IntervalSeconds)*time.Second, configured,
+		al.ProcessBackground, func(channel, chatID, content string) {
+			al.bus.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: content, Proactive: true})
+		})
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Skipping watches: %v", err))
+	}
+	return svc
+}
@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/webhooks"
+)
+
+// newWebhookServer sets up the webhook endpoints, or returns nil when they
+// are disabled. Calls run as background turns in a "webhook:<name>"
+// session.
+func newWebhookServer(al *AgentLoop) *webhooks.Server {
+	cfg := al.cfg.Webhooks
+	if !cfg.Enabled {
+		return nil
+	}
+	srv, err := webhooks.NewServer(cfg, al.ProcessBackground, func(channel, chatID, content string) {
+		al.bus.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: content, Proactive: true})
+	})
+	if err != nil {
+		logger.WarnC("agent", fmt.Sprintf("Skipping webhooks: %v", err))
+	}
+	return srv
+}
+
+// runWebhooks serves the webhook endpoints until ctx is cancelled.
+func (al *AgentLoop) runWebhooks(ctx context.Context) {
+	if err := al.webhooks.Run(ctx); err != nil {
+		logger.ErrorC("agent", fmt.Sprintf("Webhook server stopped: %v", err))
+	}
+}
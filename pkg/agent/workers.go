@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// turnJob is one turn for the worker pool: the message to answer and the
+// inbound messages it was coalesced from.
+type turnJob struct {
+	msg   bus.InboundMessage
+	parts []bus.InboundMessage
+}
+
+// workerPool runs turns on up to size goroutines. A turn for a session
+// that is already running waits behind it instead of taking a worker, so
+// replies in a chat keep their order.
+type workerPool struct {
+	slots chan struct{}
+	run   func(turnJob)
+	mu    sync.Mutex
+	queue map[string][]turnJob // running sessions and the turns waiting on them
+	wg    sync.WaitGroup
+}
+
+func newWorkerPool(size int, run func(turnJob)) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+	return &workerPool{
+		slots: make(chan struct{}, size),
+		run:   run,
+		queue: make(map[string][]turnJob),
+	}
+}
+
+// acquire blocks until a worker is free. The next message is taken off
+// the bus only then, so an interactive message that arrives while all
+// workers are busy still goes ahead of queued background work.
+func (p *workerPool) acquire(ctx context.Context) bool {
+	select {
+	case p.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *workerPool) release() {
+	<-p.slots
+}
+
+// submit runs job on the worker taken by acquire, or queues it behind the
+// running turn of its session and hands the worker back.
+func (p *workerPool) submit(job turnJob) {
+	key := job.msg.SessionKey
+	p.mu.Lock()
+	if waiting, busy := p.queue[key]; busy {
+		p.queue[key] = append(waiting, job)
+		p.mu.Unlock()
+		p.release()
+		return
+	}
+	p.queue[key] = nil
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.release()
+		for {
+			p.run(job)
+			p.mu.Lock()
+			waiting := p.queue[key]
+			if len(waiting) == 0 {
+				delete(p.queue, key)
+				p.mu.Unlock()
+				return
+			}
+			job, p.queue[key] = waiting[0], waiting[1:]
+			p.mu.Unlock()
+		}
+	}()
+}
+
+// wait blocks until all running turns are done.
+func (p *workerPool) wait() {
+	p.wg.Wait()
+}
+
+// backgroundReply carries the result of a ProcessBackground turn back to
+// its caller.
+type backgroundReply struct {
+	content string
+	err     error
+}
+
+// ProcessBackground runs a turn for work the assistant starts itself, such
+// as cron jobs and heartbeats. The turn goes through the bus's background
+// lane, so it waits while users are waiting, and its reply is returned
+// rather than sent. When Run is not consuming the bus the turn runs right
+// away.
+func (al *AgentLoop) ProcessBackground(ctx context.Context, content, sessionKey string) (string, error) {
+	msg := bus.InboundMessage{
+		Channel:    "cli",
+		SenderID:   "user",
+		ChatID:     "direct",
+		Content:    content,
+		SessionKey: sessionKey,
+		Priority:   bus.PriorityBackground,
+	}
+	if !al.running {
+		return al.processMessage(ctx, msg)
+	}
+
+	msg.ID = fmt.Sprintf("background:%d", al.bgSeq.Add(1))
+	reply := make(chan backgroundReply, 1)
+	al.bgWaiters.Store(msg.ID, reply)
+	defer al.bgWaiters.Delete(msg.ID)
+
+	al.bus.PublishInbound(msg)
+
+	select {
+	case r := <-reply:
+		return r.content, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// replyBackground hands a background turn's result to the waiting
+// ProcessBackground call, if it has not given up.
+func (al *AgentLoop) replyBackground(id, content string, err error) {
+	if waiter, ok := al.bgWaiters.Load(id); ok {
+		waiter.(chan backgroundReply) <- backgroundReply{content: content, err: err}
+	}
+}
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+func TestWorkerPoolKeepsSessionOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	pool := newWorkerPool(3, func(job turnJob) {
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		order = append(order, job.msg.Content)
+		mu.Unlock()
+	})
+
+	for _, content := range []string{"1", "2", "3", "4"} {
+		if !pool.acquire(context.Background()) {
+			t.Fatal("expected a free worker")
+		}
+		pool.submit(turnJob{msg: bus.InboundMessage{SessionKey: "s", Content: content}})
+	}
+	pool.wait()
+
+	want := []string{"1", "2", "3", "4"}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("expected turns in order %v, got %v", want, order)
+		}
+	}
+	if len(pool.queue) != 0 {
+		t.Errorf("expected no sessions left running, got %d", len(pool.queue))
+	}
+}
+
+func TestWorkerPoolRunsSessionsConcurrently(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan string, 2)
+	pool := newWorkerPool(2, func(job turnJob) {
+		started <- job.msg.SessionKey
+		<-release
+	})
+
+	for _, key := range []string{"a", "b"} {
+		pool.acquire(context.Background())
+		pool.submit(turnJob{msg: bus.InboundMessage{SessionKey: key}})
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("expected both sessions to run at once")
+		}
+	}
+
+	// Both workers are busy, so a third turn has to wait
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if pool.acquire(ctx) {
+		t.Error("expected no free worker while both are busy")
+	}
+	close(release)
+	pool.wait()
+}
+
+func TestInboundQueuePrefersInteractive(t *testing.T) {
+	mb := bus.NewMessageBus()
+	q := newInboundQueue(mb, func() time.Duration { return 0 })
+
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "cron", Content: "daily report", Priority: bus.PriorityBackground})
+	mb.PublishInbound(bus.InboundMessage{SessionKey: "telegram:1", Content: "hello"})
+
+	msg, _, _ := q.next(context.Background())
+	if msg.Content != "hello" {
+		t.Errorf("expected the user's message first, got %q", msg.Content)
+	}
+	msg, _, _ = q.next(context.Background())
+	if msg.Content != "daily report" {
+		t.Errorf("expected background work after, got %q", msg.Content)
+	}
+}
@@ -0,0 +1,148 @@
+// Package approvals holds messages the assistant sends on its own, such as
+// cron and heartbeat results, as drafts until the owner approves them. A
+// draft is forwarded to its chat only when approved; rejected and expired
+// drafts are dropped. Drafts are kept in a JSON file so they survive a
+// restart.
+package approvals
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// Draft is a message waiting for approval.
+type Draft struct {
+	ID      string              `json:"id"`
+	Message bus.OutboundMessage `json:"message"`
+	Created time.Time           `json:"created"`
+}
+
+// Target returns the chat the draft would go to, as "channel:chat_id".
+func (d *Draft) Target() string {
+	return d.Message.Channel + ":" + d.Message.ChatID
+}
+
+type queueFile struct {
+	Next   int      `json:"next"`
+	Drafts []*Draft `json:"drafts"`
+}
+
+// Queue is the list of pending drafts.
+type Queue struct {
+	path   string
+	expire time.Duration
+	mu     sync.Mutex
+	next   int
+	drafts map[string]*Draft
+}
+
+// NewQueue opens the queue stored at path. Drafts older than expire are
+// dropped; zero keeps them until decided.
+func NewQueue(path string, expire time.Duration) *Queue {
+	q := &Queue{path: path, expire: expire, next: 1, drafts: make(map[string]*Draft)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[approvals] Failed to read %s: %v", path, err)
+		}
+		return q
+	}
+	var f queueFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		log.Printf("[approvals] Ignoring corrupt %s: %v", path, err)
+		return q
+	}
+	if f.Next > q.next {
+		q.next = f.Next
+	}
+	for _, d := range f.Drafts {
+		q.drafts[d.ID] = d
+	}
+	return q
+}
+
+// Add holds msg as a new draft.
+func (q *Queue) Add(msg bus.OutboundMessage, now time.Time) (*Draft, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.prune(now)
+	d := &Draft{ID: strconv.Itoa(q.next), Message: msg, Created: now}
+	q.next++
+	q.drafts[d.ID] = d
+	return d, q.save()
+}
+
+// Take removes the draft with the given ID and returns it, so a draft is
+// decided at most once.
+func (q *Queue) Take(id string, now time.Time) (*Draft, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.prune(now)
+	d, ok := q.drafts[id]
+	if !ok {
+		return nil, fmt.Errorf("no pending draft #%s (already decided or expired)", id)
+	}
+	delete(q.drafts, id)
+	return d, q.save()
+}
+
+// Pending returns the drafts waiting for approval, oldest first.
+func (q *Queue) Pending(now time.Time) []*Draft {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.prune(now) {
+		if err := q.save(); err != nil {
+			log.Printf("[approvals] Failed to save: %v", err)
+		}
+	}
+	list := make([]*Draft, 0, len(q.drafts))
+	for _, d := range q.drafts {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Created.Before(list[j].Created) })
+	return list
+}
+
+// prune drops expired drafts and reports whether any were dropped.
+func (q *Queue) prune(now time.Time) bool {
+	if q.expire <= 0 {
+		return false
+	}
+	pruned := false
+	for id, d := range q.drafts {
+		if now.Sub(d.Created) > q.expire {
+			log.Printf("[approvals] Draft #%s to %s expired unapproved", id, d.Target())
+			delete(q.drafts, id)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+func (q *Queue) save() error {
+	f := queueFile{Next: q.next, Drafts: make([]*Draft, 0, len(q.drafts))}
+	for _, d := range q.drafts {
+		f.Drafts = append(f.Drafts, d)
+	}
+	sort.Slice(f.Drafts, func(i, j int) bool { return f.Drafts[i].Created.Before(f.Drafts[j].Created) })
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(q.path, data, 0600)
+}
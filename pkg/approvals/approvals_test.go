@@ -0,0 +1,48 @@
+package approvals
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+func TestQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approvals.json")
+	now := time.Now()
+	q := NewQueue(path, 24*time.Hour)
+
+	first, err := q.Add(bus.OutboundMessage{Channel: "telegram", ChatID: "-100", Content: "Team update"}, now.Add(-25*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := q.Add(bus.OutboundMessage{Channel: "discord", ChatID: "42", Content: "Build is green"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == second.ID || second.Target() != "discord:42" {
+		t.Errorf("unexpected drafts %+v, %+v", first, second)
+	}
+
+	// Drafts survive a restart; the expired one is dropped
+	q = NewQueue(path, 24*time.Hour)
+	pending := q.Pending(now)
+	if len(pending) != 1 || pending[0].ID != second.ID || pending[0].Message.Content != "Build is green" {
+		t.Fatalf("unexpected pending drafts %+v", pending)
+	}
+
+	d, err := q.Take(second.ID, now)
+	if err != nil || d.Message.ChatID != "42" {
+		t.Fatalf("Take = %+v, %v", d, err)
+	}
+	if _, err := q.Take(second.ID, now); err == nil {
+		t.Error("expected a draft to be decided only once")
+	}
+
+	// IDs are not reused after a restart
+	third, _ := NewQueue(path, 0).Add(bus.OutboundMessage{Channel: "telegram", ChatID: "1"}, now)
+	if third.ID == first.ID || third.ID == second.ID {
+		t.Errorf("expected a fresh ID, got %s", third.ID)
+	}
+}
@@ -0,0 +1,204 @@
+// Package audit keeps an append-only record of every tool call: who asked
+// for it, on which channel, which tool ran, a hash of its arguments, how
+// long it took and how it ended. Arguments and results themselves are not
+// stored, so the log can be kept indefinitely without holding file contents
+// or credentials.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the audit log's name in the data directory.
+const FileName = "audit.jsonl"
+
+// Outcomes of a tool call.
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Entry is one tool call.
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Channel     string    `json:"channel,omitempty"`
+	SenderID    string    `json:"sender_id,omitempty"`
+	SessionKey  string    `json:"session_key,omitempty"`
+	Tool        string    `json:"tool"`
+	ArgsHash    string    `json:"args_hash"`
+	ResultChars int       `json:"result_chars"`
+	DurationMs  int64     `json:"duration_ms"`
+	Outcome     string    `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Log appends entries to a JSON Lines file. The file is only ever opened
+// for appending; the one rewrite is PurgeSender, for deleting a user's data.
+type Log struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// Open opens or creates the audit log at path.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &Log{path: path, file: f}, nil
+}
+
+// Path returns the file the log is written to.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes one entry. Errors are truncated to 200 characters.
+func (l *Log) Append(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if len(e.Error) > 200 {
+		e.Error = e.Error[:200] + "..."
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// HashArgs returns a short SHA-256 of the arguments' JSON, so identical
+// calls can be matched up without keeping what was passed.
+func HashArgs(args map[string]interface{}) string {
+	data, _ := json.Marshal(args) // map keys are sorted, so equal args hash the same
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Filter selects entries in Query. Empty fields match everything.
+type Filter struct {
+	Since    time.Time
+	Until    time.Time
+	Channel  string
+	SenderID string // matches with or without the "|name" suffix channels add
+	Tool     string
+	Outcome  string
+	Limit    int // keep only the newest Limit matches (0 = all)
+}
+
+func (f Filter) match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	if f.Channel != "" && !strings.EqualFold(e.Channel, f.Channel) {
+		return false
+	}
+	if f.SenderID != "" && e.SenderID != f.SenderID && !strings.HasPrefix(e.SenderID, f.SenderID+"|") {
+		return false
+	}
+	if f.Tool != "" && e.Tool != f.Tool {
+		return false
+	}
+	return f.Outcome == "" || e.Outcome == f.Outcome
+}
+
+// Query reads the log at path and returns the matching entries, oldest
+// first. A missing file is an empty log; lines that do not parse are
+// skipped.
+func Query(path string, f Filter) ([]Entry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil || !f.match(e) {
+			continue
+		}
+		entries = append(entries, e)
+		if f.Limit > 0 && len(entries) > 2*f.Limit {
+			entries = append(entries[:0], entries[len(entries)-f.Limit:]...)
+		}
+	}
+	if f.Limit > 0 && len(entries) > f.Limit {
+		entries = entries[len(entries)-f.Limit:]
+	}
+	return entries, scanner.Err()
+}
+
+// PurgeSender removes a sender's entries from the log at path and returns
+// how many there were. The file is rewritten in place rather than
+// replaced, so a running mclaw keeps appending to it.
+func PurgeSender(path, senderID string) (int, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	f := Filter{SenderID: senderID}
+	var kept []byte
+	removed := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && f.match(e) {
+			removed++
+			continue
+		}
+		kept = append(append(kept, scanner.Bytes()...), '\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := file.Truncate(0); err != nil {
+		return 0, err
+	}
+	if _, err := file.WriteAt(kept, 0); err != nil {
+		return 0, err
+	}
+	return removed, file.Sync()
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	calls := []Entry{
+		{Time: start, Channel: "telegram", SenderID: "100|alice", Tool: "exec", Outcome: OutcomeOK},
+		{Time: start.Add(time.Minute), Channel: "telegram", SenderID: "200|bob", Tool: "read_file", Outcome: OutcomeError, Error: "permission denied"},
+		{Time: start.Add(2 * time.Minute), Channel: "cli", SenderID: "user", Tool: "exec", Outcome: OutcomeOK},
+	}
+	for _, e := range calls {
+		if err := l.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	// Reopening appends instead of truncating
+	l, _ = Open(path)
+	l.Append(Entry{Time: start.Add(3 * time.Minute), Channel: "telegram", SenderID: "100|alice", Tool: "write_file", Outcome: OutcomeOK})
+	l.Close()
+
+	all, err := Query(path, Filter{})
+	if err != nil || len(all) != 4 {
+		t.Fatalf("expected 4 entries, got %d (%v)", len(all), err)
+	}
+	if got, _ := Query(path, Filter{SenderID: "100"}); len(got) != 2 {
+		t.Errorf("expected 2 calls by sender 100, got %d", len(got))
+	}
+	if got, _ := Query(path, Filter{Tool: "exec", Channel: "Telegram"}); len(got) != 1 {
+		t.Errorf("expected 1 exec call on telegram, got %d", len(got))
+	}
+	if got, _ := Query(path, Filter{Outcome: OutcomeError}); len(got) != 1 || got[0].Error != "permission denied" {
+		t.Errorf("expected the failed read_file call, got %+v", got)
+	}
+	if got, _ := Query(path, Filter{Since: start.Add(time.Minute), Until: start.Add(3 * time.Minute)}); len(got) != 2 {
+		t.Errorf("expected 2 calls in the time range, got %d", len(got))
+	}
+	if got, _ := Query(path, Filter{Limit: 1}); len(got) != 1 || got[0].Tool != "write_file" {
+		t.Errorf("expected only the newest call, got %+v", got)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected audit log to be private, got %v", info.Mode().Perm())
+	}
+}
+
+func TestHashArgs(t *testing.T) {
+	a := HashArgs(map[string]interface{}{"path": "/etc/hosts", "limit": 10})
+	b := HashArgs(map[string]interface{}{"limit": 10, "path": "/etc/hosts"})
+	if a != b {
+		t.Errorf("expected equal args to hash the same, got %s and %s", a, b)
+	}
+	if a == HashArgs(map[string]interface{}{"path": "/etc/passwd", "limit": 10}) {
+		t.Error("expected different args to hash differently")
+	}
+}
+
+func TestPurgeSender(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.Append(Entry{Channel: "telegram", SenderID: "100|alice", Tool: "exec", Outcome: OutcomeOK})
+	l.Append(Entry{Channel: "telegram", SenderID: "200|bob", Tool: "read_file", Outcome: OutcomeOK})
+	l.Append(Entry{Channel: "discord", SenderID: "100", Tool: "web_search", Outcome: OutcomeOK})
+
+	if n, err := PurgeSender(path, "100"); err != nil || n != 2 {
+		t.Fatalf("PurgeSender: %d %v", n, err)
+	}
+
+	// The open log keeps appending to the rewritten file
+	l.Append(Entry{Channel: "telegram", SenderID: "300", Tool: "exec", Outcome: OutcomeOK})
+	entries, err := Query(path, Filter{})
+	if err != nil || len(entries) != 2 || entries[0].SenderID != "200|bob" || entries[1].SenderID != "300" {
+		t.Errorf("expected only other senders' entries, got %+v (%v)", entries, err)
+	}
+}
@@ -0,0 +1,293 @@
+// Package auth decides who may talk to the assistant and what they may do.
+// Users in a channel's allow_from are owners. Owners give other users a
+// role at runtime with /authorize, usually guest: a few harmless tools and
+// a daily quota, so a shared bot cannot run commands on the host or spend
+// the owner's API budget.
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// Role is what a user may do.
+type Role string
+
+const (
+	RoleOwner Role = "owner"
+	RoleGuest Role = "guest"
+)
+
+// ParseRole validates a role name given to /authorize.
+func ParseRole(s string) (Role, error) {
+	switch role := Role(strings.ToLower(strings.TrimSpace(s))); role {
+	case RoleOwner, RoleGuest:
+		return role, nil
+	default:
+		return "", fmt.Errorf("unknown role %q (use owner or guest)", s)
+	}
+}
+
+// Grant is access given to a user at runtime.
+type Grant struct {
+	Channel   string    `json:"channel"`
+	UserID    string    `json:"user_id"`
+	Role      Role      `json:"role"`
+	GrantedBy string    `json:"granted_by,omitempty"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+// Authorizer resolves users to roles and enforces role limits. Grants are
+// kept in a JSON file so they survive restarts.
+type Authorizer struct {
+	mu        sync.RWMutex
+	path      string
+	quotaPath string              // today's usage counters, saved next to the grants
+	owners    map[string][]string // channel -> allow_from
+	roles     map[Role]config.RoleConfig
+	users     map[string]config.LimitsConfig // by grantKey
+	group     config.LimitsConfig
+	grants    map[string]Grant // by grantKey
+	usage     map[string]*dailyUsage
+}
+
+// New creates an Authorizer for cfg with grants stored at path, and keeps
+// it in step with config reloads. If the grants file cannot be read the
+// error is returned along with an Authorizer that knows only the owners
+// from the config.
+func New(cfg *config.Config, path string) (*Authorizer, error) {
+	a := &Authorizer{
+		path:      path,
+		quotaPath: filepath.Join(filepath.Dir(path), "quota.json"),
+		grants:    make(map[string]Grant),
+		usage:     make(map[string]*dailyUsage),
+	}
+	a.apply(cfg)
+	cfg.OnReload(func(cfg *config.Config, changed []string) { a.apply(cfg) })
+	a.loadUsage(time.Now())
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+	if err != nil {
+		return a, err
+	}
+	var grants []Grant
+	if err := json.Unmarshal(data, &grants); err != nil {
+		return a, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, g := range grants {
+		a.grants[grantKey(g.Channel, g.UserID)] = g
+	}
+	return a, nil
+}
+
+func (a *Authorizer) apply(cfg *config.Config) {
+	owners := map[string][]string{
+		"telegram": cfg.Channels.Telegram.AllowFrom,
+		"discord":  cfg.Channels.Discord.AllowFrom,
+		"feishu":   cfg.Channels.Feishu.AllowFrom,
+		"whatsapp": cfg.Channels.WhatsApp.AllowFrom,
+	}
+
+	// A role without a tools list keeps its default one, so setting just a
+	// guest quota does not hand guests every tool
+	defaults := config.DefaultConfig().Auth.Roles
+	roles := make(map[Role]config.RoleConfig)
+	for name, rc := range defaults {
+		roles[Role(name)] = rc
+	}
+	for name, rc := range cfg.Auth.Roles {
+		if rc.Tools == nil {
+			rc.Tools = defaults[name].Tools
+		}
+		roles[Role(name)] = rc
+	}
+
+	users := make(map[string]config.LimitsConfig, len(cfg.Auth.Users))
+	for key, limits := range cfg.Auth.Users {
+		if channel, id, ok := strings.Cut(key, ":"); ok {
+			users[grantKey(strings.ToLower(channel), id)] = limits
+		}
+	}
+
+	a.mu.Lock()
+	a.owners = owners
+	a.roles = roles
+	a.users = users
+	a.group = cfg.Auth.Group
+	a.mu.Unlock()
+}
+
+// Role returns the role of a sender on a channel, and false if the sender
+// may not use the assistant at all. Messages from the local CLI and from
+// channels without an allow_from list come from owners.
+func (a *Authorizer) Role(channel, senderID string) (Role, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	owners, known := a.owners[channel]
+	if !known {
+		return RoleOwner, true // cli, cron and other local sources
+	}
+	if a.listedOwner(channel, senderID) {
+		return RoleOwner, true
+	}
+	if g, ok := a.grants[grantKey(channel, UserID(senderID))]; ok {
+		return g.Role, true
+	}
+	if len(owners) == 0 {
+		return RoleOwner, true
+	}
+	return "", false
+}
+
+// listedOwner reports whether the sender is in the channel's allow_from.
+// Callers hold a.mu.
+func (a *Authorizer) listedOwner(channel, senderID string) bool {
+	userID := UserID(senderID)
+	for _, owner := range a.owners[channel] {
+		if senderID == owner || userID == owner {
+			return true
+		}
+	}
+	return false
+}
+
+// Grant gives a user a role on a channel, replacing any earlier grant.
+func (a *Authorizer) Grant(channel, userID string, role Role, grantedBy string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.grants[grantKey(channel, userID)] = Grant{
+		Channel:   channel,
+		UserID:    userID,
+		Role:      role,
+		GrantedBy: grantedBy,
+		GrantedAt: time.Now(),
+	}
+	return a.save()
+}
+
+// Revoke removes a user's grant. It reports false if there was none;
+// owners from allow_from can only be removed in the config.
+func (a *Authorizer) Revoke(channel, userID string) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := grantKey(channel, userID)
+	if _, ok := a.grants[key]; !ok {
+		return false, nil
+	}
+	delete(a.grants, key)
+	return true, a.save()
+}
+
+// Grants returns the runtime grants, by channel and user ID.
+func (a *Authorizer) Grants() []Grant {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	grants := make([]Grant, 0, len(a.grants))
+	for _, g := range a.grants {
+		grants = append(grants, g)
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		return grantKey(grants[i].Channel, grants[i].UserID) < grantKey(grants[j].Channel, grants[j].UserID)
+	})
+	return grants
+}
+
+// UserGrants returns the runtime grants of a user on any channel.
+func (a *Authorizer) UserGrants(userID string) []Grant {
+	var grants []Grant
+	for _, g := range a.Grants() {
+		if g.UserID == UserID(userID) {
+			grants = append(grants, g)
+		}
+	}
+	return grants
+}
+
+// RevokeUser removes a user's grants on every channel and returns how many
+// there were.
+func (a *Authorizer) RevokeUser(userID string) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := 0
+	for key, g := range a.grants {
+		if g.UserID == UserID(userID) {
+			delete(a.grants, key)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, a.save()
+}
+
+// DeniedTools returns the tools among names that role may not use, mapped
+// to the reason, in the form tools.Scope.Denied takes.
+func (a *Authorizer) DeniedTools(role Role, names []string) map[string]string {
+	a.mu.RLock()
+	rc := a.roles[role]
+	a.mu.RUnlock()
+
+	denied := make(map[string]string)
+	if len(rc.Tools) > 0 {
+		allowed := make(map[string]bool, len(rc.Tools))
+		for _, name := range rc.Tools {
+			allowed[name] = true
+		}
+		for _, name := range names {
+			if !allowed[name] {
+				denied[name] = "not available to " + string(role) + "s"
+			}
+		}
+	}
+	for _, name := range rc.DenyTools {
+		denied[name] = "not available to " + string(role) + "s"
+	}
+	return denied
+}
+
+// save writes the grants file. Callers hold a.mu.
+func (a *Authorizer) save() error {
+	grants := make([]Grant, 0, len(a.grants))
+	for _, g := range a.grants {
+		grants = append(grants, g)
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].GrantedAt.Before(grants[j].GrantedAt) })
+	data, err := json.MarshalIndent(grants, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0700); err != nil {
+		return err
+	}
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, a.path)
+}
+
+// UserID strips the display name channels append to sender IDs, as in
+// "414383435|alice".
+func UserID(senderID string) string {
+	if idx := strings.Index(senderID, "|"); idx > 0 {
+		return senderID[:idx]
+	}
+	return senderID
+}
+
+func grantKey(channel, userID string) string {
+	return channel + ":" + userID
+}
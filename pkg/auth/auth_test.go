@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+func newTestAuthorizer(t *testing.T, path string) *Authorizer {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Channels.Telegram.AllowFrom = []string{"100"}
+	a, err := New(cfg, path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return a
+}
+
+func TestRoles(t *testing.T) {
+	a := newTestAuthorizer(t, filepath.Join(t.TempDir(), "auth.json"))
+
+	if role, ok := a.Role("telegram", "100|alice"); !ok || role != RoleOwner {
+		t.Errorf("expected allow_from user to be owner, got %q %v", role, ok)
+	}
+	if _, ok := a.Role("telegram", "200|bob"); ok {
+		t.Error("expected unknown user to be refused")
+	}
+	if role, ok := a.Role("discord", "300"); !ok || role != RoleOwner {
+		t.Errorf("expected open channel to let anyone in as owner, got %q %v", role, ok)
+	}
+	if role, ok := a.Role("cli", "user"); !ok || role != RoleOwner {
+		t.Errorf("expected local CLI to be owner, got %q %v", role, ok)
+	}
+}
+
+func TestGrantsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	a := newTestAuthorizer(t, path)
+	if err := a.Grant("telegram", "200", RoleGuest, "telegram:100"); err != nil {
+		t.Fatalf("Grant: %v", err)
+	}
+
+	reloaded := newTestAuthorizer(t, path)
+	if role, ok := reloaded.Role("telegram", "200|bob"); !ok || role != RoleGuest {
+		t.Errorf("expected grant to survive a restart as guest, got %q %v", role, ok)
+	}
+
+	removed, err := reloaded.Revoke("telegram", "200")
+	if err != nil || !removed {
+		t.Fatalf("Revoke: %v %v", removed, err)
+	}
+	if _, ok := reloaded.Role("telegram", "200"); ok {
+		t.Error("expected revoked user to be refused")
+	}
+}
+
+func TestRevokeUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	a := newTestAuthorizer(t, path)
+	a.Grant("telegram", "200", RoleGuest, "")
+	a.Grant("discord", "200", RoleOwner, "")
+	a.Grant("telegram", "300", RoleGuest, "")
+
+	if grants := a.UserGrants("200|bob"); len(grants) != 2 {
+		t.Fatalf("expected the user's grants on both channels, got %+v", grants)
+	}
+	if n, err := a.RevokeUser("200"); err != nil || n != 2 {
+		t.Fatalf("RevokeUser: %d %v", n, err)
+	}
+	reloaded := newTestAuthorizer(t, path)
+	if grants := reloaded.Grants(); len(grants) != 1 || grants[0].UserID != "300" {
+		t.Errorf("expected only the other user's grant saved, got %+v", grants)
+	}
+}
+
+func TestGuestTools(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Auth.Roles = map[string]config.RoleConfig{"guest": {DailyMessages: 5}} // no tools list: keep the default
+	a, err := New(cfg, filepath.Join(t.TempDir(), "auth.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	denied := a.DeniedTools(RoleGuest, []string{"exec", "web_search", "write_file"})
+	if _, ok := denied["exec"]; !ok {
+		t.Error("expected exec to be denied to guests")
+	}
+	if _, ok := denied["web_search"]; ok {
+		t.Error("expected web_search to stay available to guests")
+	}
+	if len(a.DeniedTools(RoleOwner, []string{"exec"})) != 0 {
+		t.Error("expected owners to keep every tool")
+	}
+}
+
+func TestQuota(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Channels.Telegram.AllowFrom = []string{"100"}
+	cfg.Auth.Roles = map[string]config.RoleConfig{"guest": {DailyMessages: 2, DailyTokens: 1000}}
+	a, _ := New(cfg, filepath.Join(t.TempDir(), "auth.json"))
+	a.Grant("telegram", "200", RoleGuest, "")
+
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	for i := 0; i < 2; i++ {
+		if err := a.CheckQuota("telegram", "200", false, now); err != nil {
+			t.Fatalf("message %d: unexpected %v", i+1, err)
+		}
+	}
+	var quota *QuotaError
+	if err := a.CheckQuota("telegram", "200", false, now); !errors.As(err, &quota) || quota.Limit != "messages" {
+		t.Errorf("expected message quota error, got %v", err)
+	}
+	if err := a.CheckQuota("telegram", "100", false, now); err != nil {
+		t.Errorf("expected owner to be unlimited, got %v", err)
+	}
+
+	tomorrow := now.Add(24 * time.Hour)
+	if err := a.CheckQuota("telegram", "200", false, tomorrow); err != nil {
+		t.Errorf("expected quota to reset the next day, got %v", err)
+	}
+	a.RecordTokens("telegram", "200", 1500, tomorrow)
+	if err := a.CheckQuota("telegram", "200", false, tomorrow); !errors.As(err, &quota) || quota.Limit != "tokens" {
+		t.Errorf("expected token quota error, got %v", err)
+	}
+}
+
+func TestLimitsPerUserAndGroup(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Auth.Users = map[string]config.LimitsConfig{"Telegram:300": {DailyMessages: 1}}
+	cfg.Auth.Group = config.LimitsConfig{DailyMessages: 10, PerMinute: 2}
+	a, _ := New(cfg, filepath.Join(t.TempDir(), "auth.json"))
+
+	// Open channel: everyone is an owner, but group chats still get capped
+	if got := a.Limits("telegram", "200", false); got != (config.LimitsConfig{}) {
+		t.Errorf("expected no limits in a private chat, got %+v", got)
+	}
+	if got := a.Limits("telegram", "200", true); got.DailyMessages != 10 || got.PerMinute != 2 {
+		t.Errorf("expected group limits, got %+v", got)
+	}
+	if got := a.Limits("telegram", "300|carol", true); got.DailyMessages != 1 || got.PerMinute != 0 {
+		t.Errorf("expected the per-user entry to replace other limits, got %+v", got)
+	}
+}
+
+func TestThrottleAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	cfg := config.DefaultConfig()
+	cfg.Auth.Group = config.LimitsConfig{PerMinute: 2, DailyTokens: 5000}
+	a, _ := New(cfg, path)
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := a.CheckQuota("discord", "42", true, now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("message %d: unexpected %v", i+1, err)
+		}
+	}
+	var quota *QuotaError
+	err := a.CheckQuota("discord", "42", true, now.Add(2*time.Second))
+	if !errors.As(err, &quota) || quota.Limit != "per_minute" || !quota.ResetAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected throttling until a minute after the first message, got %v", err)
+	}
+	if err := a.CheckQuota("discord", "42", true, now.Add(61*time.Second)); err != nil {
+		t.Errorf("expected throttle to lift after a minute, got %v", err)
+	}
+
+	a.RecordTokens("discord", "42", 6000, now)
+	restarted, _ := New(cfg, path)
+	if err := restarted.CheckQuota("discord", "42", true, now); !errors.As(err, &quota) || quota.Limit != "tokens" {
+		t.Errorf("expected token usage to survive a restart, got %v", err)
+	}
+}
+
+func TestForgetUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	a := newTestAuthorizer(t, path)
+	now := time.Now()
+	a.RecordTokens("telegram", "200|bob", 300, now)
+	a.RecordTokens("discord", "200", 100, now)
+	a.RecordTokens("telegram", "300", 50, now)
+
+	if usage := a.UserUsage("200"); len(usage) != 2 || usage[0].Channel != "discord" || usage[1].Tokens != 300 {
+		t.Fatalf("expected the user's usage on both channels, got %+v", usage)
+	}
+	if n, err := a.ForgetUsage("200"); err != nil || n != 2 {
+		t.Fatalf("ForgetUsage: %d %v", n, err)
+	}
+	restarted := newTestAuthorizer(t, path)
+	if usage := restarted.UserUsage("200"); len(usage) != 0 {
+		t.Errorf("expected no saved usage left for the user, got %+v", usage)
+	}
+	if usage := restarted.UserUsage("300"); len(usage) != 1 {
+		t.Errorf("expected other users' usage kept, got %+v", usage)
+	}
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// QuotaError is returned when a sender has hit one of their limits.
+type QuotaError struct {
+	Role    Role
+	Limit   string // "messages", "tokens" or "per_minute"
+	ResetAt time.Time
+}
+
+func (e *QuotaError) Error() string {
+	if e.Limit == "per_minute" {
+		return fmt.Sprintf("too many messages, try again at %s", e.ResetAt.Format("15:04:05"))
+	}
+	return fmt.Sprintf("daily %s limit reached, resets at %s", e.Limit, e.ResetAt.Format("15:04"))
+}
+
+// dailyUsage counts one sender's turns and tokens for one local day.
+// Recent holds the times of the last minute's messages for throttling.
+type dailyUsage struct {
+	Day      string      `json:"day"`
+	Messages int         `json:"messages"`
+	Tokens   int         `json:"tokens"`
+	Recent   []time.Time `json:"-"`
+}
+
+// Limits returns the limits that apply to a sender: their entry in
+// auth.users if there is one, otherwise their role's limits, tightened by
+// auth.group in group chats for anyone not in allow_from.
+func (a *Authorizer) Limits(channel, senderID string, group bool) config.LimitsConfig {
+	role, _ := a.Role(channel, senderID)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if limits, ok := a.users[grantKey(channel, UserID(senderID))]; ok {
+		return limits
+	}
+	rc := a.roles[role]
+	limits := config.LimitsConfig{DailyMessages: rc.DailyMessages, DailyTokens: rc.DailyTokens, PerMinute: rc.PerMinute}
+	if group && !a.listedOwner(channel, senderID) {
+		limits.DailyMessages = tighter(limits.DailyMessages, a.group.DailyMessages)
+		limits.DailyTokens = tighter(limits.DailyTokens, a.group.DailyTokens)
+		limits.PerMinute = tighter(limits.PerMinute, a.group.PerMinute)
+	}
+	return limits
+}
+
+// CheckQuota reports a *QuotaError if the sender has reached a limit, and
+// otherwise counts the message against it. It runs before the LLM call,
+// so a refused message costs nothing.
+func (a *Authorizer) CheckQuota(channel, senderID string, group bool, now time.Time) error {
+	role, ok := a.Role(channel, senderID)
+	if !ok {
+		return fmt.Errorf("%s is not authorized", senderID)
+	}
+	limits := a.Limits(channel, senderID, group)
+	if limits == (config.LimitsConfig{}) {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	u := a.usageFor(channel, senderID, now)
+	if limits.DailyMessages > 0 && u.Messages >= limits.DailyMessages {
+		return &QuotaError{Role: role, Limit: "messages", ResetAt: nextMidnight(now)}
+	}
+	if limits.DailyTokens > 0 && u.Tokens >= limits.DailyTokens {
+		return &QuotaError{Role: role, Limit: "tokens", ResetAt: nextMidnight(now)}
+	}
+	if limits.PerMinute > 0 {
+		recent := u.Recent[:0]
+		for _, t := range u.Recent {
+			if now.Sub(t) < time.Minute {
+				recent = append(recent, t)
+			}
+		}
+		u.Recent = recent
+		if len(recent) >= limits.PerMinute {
+			return &QuotaError{Role: role, Limit: "per_minute", ResetAt: recent[0].Add(time.Minute)}
+		}
+		u.Recent = append(u.Recent, now)
+	}
+	u.Messages++
+	return nil
+}
+
+// RecordTokens adds a finished turn's tokens to the sender's daily usage
+// and saves the counters, so a restart does not reset anyone's quota.
+func (a *Authorizer) RecordTokens(channel, senderID string, tokens int, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.usageFor(channel, senderID, now).Tokens += tokens
+	a.saveUsage()
+}
+
+// Usage is a sender's counters for one day, as saved in quota.json.
+type Usage struct {
+	Channel  string `json:"channel"`
+	UserID   string `json:"user_id"`
+	Day      string `json:"day"`
+	Messages int    `json:"messages"`
+	Tokens   int    `json:"tokens"`
+}
+
+// UserUsage returns a user's counters on every channel.
+func (a *Authorizer) UserUsage(userID string) []Usage {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var usage []Usage
+	for key, u := range a.usage {
+		channel, id, _ := strings.Cut(key, ":")
+		if id == UserID(userID) {
+			usage = append(usage, Usage{Channel: channel, UserID: id, Day: u.Day, Messages: u.Messages, Tokens: u.Tokens})
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Channel < usage[j].Channel })
+	return usage
+}
+
+// ForgetUsage deletes a user's counters on every channel and returns how
+// many there were. quota.json is rewritten either way, which also drops
+// counters of earlier days still in it.
+func (a *Authorizer) ForgetUsage(userID string) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := 0
+	for key := range a.usage {
+		if _, id, _ := strings.Cut(key, ":"); id == UserID(userID) {
+			delete(a.usage, key)
+			n++
+		}
+	}
+	return n, a.saveUsage()
+}
+
+// saveUsage writes the counters to quota.json. Callers hold a.mu.
+func (a *Authorizer) saveUsage() error {
+	if a.quotaPath == "" {
+		return nil
+	}
+	data, err := json.Marshal(a.usage)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.quotaPath, data, 0600)
+}
+
+// loadUsage restores today's counters saved by RecordTokens.
+func (a *Authorizer) loadUsage(now time.Time) {
+	data, err := os.ReadFile(a.quotaPath)
+	if err != nil {
+		return
+	}
+	var saved map[string]*dailyUsage
+	if json.Unmarshal(data, &saved) != nil {
+		return
+	}
+	today := now.Format("2006-01-02")
+	for key, u := range saved {
+		if u != nil && u.Day == today {
+			a.usage[key] = u
+		}
+	}
+}
+
+// usageFor returns today's counters for a sender. Callers hold a.mu.
+func (a *Authorizer) usageFor(channel, senderID string, now time.Time) *dailyUsage {
+	day := now.Format("2006-01-02")
+	key := grantKey(channel, UserID(senderID))
+	u, ok := a.usage[key]
+	if !ok || u.Day != day {
+		u = &dailyUsage{Day: day}
+		a.usage[key] = u
+	}
+	return u
+}
+
+// tighter combines two limits where 0 means unlimited.
+func tighter(a, b int) int {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+func nextMidnight(now time.Time) time.Time {
+	y, m, d := now.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+}
@@ -0,0 +1,140 @@
+package bookmarks
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+func TestPreview(t *testing.T) {
+	text := "\n# Go 1.23 Release Notes\n\n• Home\nThe latest Go release, version 1.23, arrives six months after Go 1.22 with range-over-func iterators.\n"
+	title, opening := Preview(text)
+	if title != "Go 1.23 Release Notes" {
+		t.Errorf("title = %q", title)
+	}
+	if !strings.HasPrefix(opening, "The latest Go release") {
+		t.Errorf("opening = %q", opening)
+	}
+	if title, _ := Preview("Just a short line"); title != "Just a short line" {
+		t.Errorf("expected the first line as title, got %q", title)
+	}
+
+	if u, err := NormalizeURL(" Example.com/post#comments "); err != nil || u != "https://example.com/post" {
+		t.Errorf("NormalizeURL = %q, %v", u, err)
+	}
+	if _, err := NormalizeURL("ftp://example.com"); err == nil {
+		t.Error("expected ftp to be rejected")
+	}
+	if tags := NormalizeTags([]string{"#Go", "go", " machine learning ", ""}); strings.Join(tags, "|") != "go|machine-learning" {
+		t.Errorf("NormalizeTags = %q", tags)
+	}
+}
+
+func TestBookmarksAndDigest(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "memory.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	svc := NewService(store, nil)
+	svc.SetFetcher(func(ctx context.Context, url string) (string, error) {
+		if strings.Contains(url, "down") {
+			return "", fmt.Errorf("status 503")
+		}
+		return "# Page at " + url + "\nThis paragraph is long enough to be taken as the opening of the page.", nil
+	})
+	summaries := 0
+	svc.SetSummarizer(func(ctx context.Context, prompt string) (string, error) {
+		summaries++
+		return "A summary.", nil
+	})
+
+	ctx := context.Background()
+	owner := "telegram:42"
+	b, note, err := svc.Add(ctx, owner, "example.com/a", "", "", []string{"Go"})
+	if err != nil || note != "" {
+		t.Fatal(err, note)
+	}
+	if b.Title != "Page at https://example.com/a" || b.Summary != "A summary." || !b.Unread() {
+		t.Errorf("unexpected bookmark %+v", b)
+	}
+	if _, _, err := svc.Add(ctx, owner, "https://example.com/a#top", "", "", nil); err == nil {
+		t.Error("expected a duplicate to be rejected")
+	}
+	if _, _, err := svc.Add(ctx, owner, "example.com/b", "Given title", "Given summary", nil); err != nil {
+		t.Fatal(err)
+	}
+	if summaries != 1 {
+		t.Errorf("expected no fetch when title and summary are given, got %d summaries", summaries)
+	}
+	down, note, err := svc.Add(ctx, owner, "down.example.com", "", "", []string{"later"})
+	if err != nil || !strings.Contains(note, "503") || down.Title != "down.example.com" {
+		t.Fatalf("expected an unreadable page to be saved with a note, got %+v, %q, %v", down, note, err)
+	}
+
+	if list, _ := svc.List(owner, Filter{Tag: "#go"}); len(list) != 1 || list[0].ID != b.ID {
+		t.Errorf("expected the tagged link, got %+v", list)
+	}
+	if list, _ := svc.List(owner, Filter{Query: "given"}); len(list) != 1 {
+		t.Errorf("expected one search hit, got %+v", list)
+	}
+	if _, err := svc.MarkRead(owner, b.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := svc.List(owner, Filter{Unread: true}); len(list) != 2 {
+		t.Errorf("expected two unread links, got %+v", list)
+	}
+	if tagged, err := svc.Tag(owner, down.ID, []string{"ops"}, []string{"later"}); err != nil || strings.Join(tagged.Tags, ",") != "ops" {
+		t.Errorf("Tag = %+v, %v", tagged, err)
+	}
+	if _, err := svc.MarkRead("telegram:7", b.ID, true); err == nil {
+		t.Error("expected other chats not to see the bookmark")
+	}
+
+	old := &Bookmark{Owner: owner, URL: "https://old.example.com", Title: "Old", CreatedAt: time.Now().AddDate(0, 0, -30)}
+	if err := store.Add(old); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := svc.Digest(owner, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"these 2 links", "[Given title](https://example.com/b)", "1 older unread link"} {
+		if !strings.Contains(digest, want) {
+			t.Errorf("expected %q in the digest:\n%s", want, digest)
+		}
+	}
+	if strings.Contains(digest, "Page at") {
+		t.Errorf("expected read links to be left out:\n%s", digest)
+	}
+
+	cs := cron.NewCronService(filepath.Join(dir, "jobs.json"), nil)
+	svc.SetDigest(true, time.Friday, 20)
+	if err := svc.Attach(cs); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Attach(cs); err != nil {
+		t.Fatal(err)
+	}
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 || jobs[0].Payload.Kind != JobKind {
+		t.Fatalf("expected one digest job, got %+v", jobs)
+	}
+	next := time.UnixMilli(*jobs[0].State.NextRunAtMS)
+	if next.Weekday() != time.Friday || next.Hour() != 20 || next.Sub(time.Now()) > 7*24*time.Hour {
+		t.Errorf("expected the digest on the coming Friday at 20:00, got %s", next)
+	}
+	svc.SetDigest(false, time.Friday, 20)
+	if err := svc.Attach(cs); err != nil {
+		t.Fatal(err)
+	}
+	if jobs := cs.ListJobs(true); len(jobs) != 0 {
+		t.Errorf("expected the digest job to be dropped, got %+v", jobs)
+	}
+}
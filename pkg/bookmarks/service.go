@@ -0,0 +1,421 @@
+package bookmarks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+// JobKind is the cron payload kind of the weekly digest.
+const JobKind = "bookmark_digest"
+
+// digestMax caps the links listed in one digest.
+const digestMax = 5
+
+// NotifyFunc delivers a message to a chat.
+type NotifyFunc func(channel, chatID, content string)
+
+// FetchFunc returns the readable text of a page.
+type FetchFunc func(ctx context.Context, url string) (string, error)
+
+// SummarizeFunc answers a summarization prompt with a model.
+type SummarizeFunc func(ctx context.Context, prompt string) (string, error)
+
+// Service saves links with a title and summary taken from the page and
+// sends the weekly digest of unread links. Until a cron service is
+// attached, no digest is sent.
+type Service struct {
+	store     *Store
+	notify    NotifyFunc
+	fetch     FetchFunc
+	summarize SummarizeFunc
+
+	mu         sync.Mutex
+	cron       *cron.CronService
+	digest     bool
+	digestDay  time.Weekday
+	digestHour int
+}
+
+// NewService creates a bookmark service that sends its digest on Sundays
+// at 18:00.
+func NewService(store *Store, notify NotifyFunc) *Service {
+	return &Service{store: store, notify: notify, digest: true, digestDay: time.Sunday, digestHour: 18}
+}
+
+// SetFetcher sets how pages are read for their title and summary. Without
+// one, links are saved with only what the caller passes.
+func (s *Service) SetFetcher(f FetchFunc) {
+	s.fetch = f
+}
+
+// SetSummarizer sets the model that writes the summary of a page. Without
+// one, the summary is the opening of the page.
+func (s *Service) SetSummarizer(f SummarizeFunc) {
+	s.summarize = f
+}
+
+// SetDigest sets whether, on which day and at what local hour the weekly
+// digest is sent.
+func (s *Service) SetDigest(enabled bool, day time.Weekday, hour int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.digest = enabled
+	s.digestDay = day
+	if hour >= 0 && hour < 24 {
+		s.digestHour = hour
+	}
+}
+
+// Attach registers the digest handler with cs and schedules the next
+// digest, dropping stale digest jobs.
+func (s *Service) Attach(cs *cron.CronService) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cron = cs
+	cs.SetKindHandler(JobKind, s.fire)
+
+	scheduled := false
+	for _, job := range cs.ListJobs(true) {
+		if job.Payload.Kind != JobKind {
+			continue
+		}
+		if !s.digest || scheduled || job.State.NextRunAtMS == nil {
+			cs.RemoveJob(job.ID)
+			continue
+		}
+		scheduled = true
+	}
+	if s.digest && !scheduled {
+		return s.scheduleDigest(time.Now())
+	}
+	return nil
+}
+
+// Add saves rawURL for owner's chat. Title and summary are filled from the
+// page when not given; a page that cannot be read is still saved, with the
+// reason returned as a note.
+func (s *Service) Add(ctx context.Context, owner, rawURL, title, summary string, tags []string) (*Bookmark, string, error) {
+	if _, _, ok := strings.Cut(owner, ":"); !ok {
+		return nil, "", fmt.Errorf("bookmarks need a chat")
+	}
+	u, err := NormalizeURL(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing, err := s.store.FindURL(owner, u); err != nil {
+		return nil, "", err
+	} else if existing != nil {
+		return nil, "", fmt.Errorf("already saved as #%d", existing.ID)
+	}
+
+	b := &Bookmark{Owner: owner, URL: u, Title: oneLine(title), Summary: strings.TrimSpace(summary), Tags: NormalizeTags(tags)}
+	var note string
+	if (b.Title == "" || b.Summary == "") && s.fetch != nil {
+		if err := s.preview(ctx, b); err != nil {
+			note = fmt.Sprintf("couldn't read the page: %v", err)
+		}
+	}
+	if b.Title == "" {
+		b.Title = fallbackTitle(u)
+	}
+	if err := s.store.Add(b); err != nil {
+		return nil, "", err
+	}
+	return b, note, nil
+}
+
+// preview fills the missing title and summary of b from its page.
+func (s *Service) preview(ctx context.Context, b *Bookmark) error {
+	text, err := s.fetch(ctx, b.URL)
+	if err != nil {
+		return err
+	}
+	title, opening := Preview(text)
+	if b.Title == "" {
+		b.Title = title
+	}
+	if b.Summary != "" {
+		return nil
+	}
+	b.Summary = opening
+	if s.summarize != nil && strings.TrimSpace(text) != "" {
+		prompt := "Summarize this web page in one or two plain sentences saying what it is about and why it may be worth reading. " +
+			"Reply with the summary only.\n\nTitle: " + b.Title + "\n\n" + truncate(text, 6000)
+		if out, err := s.summarize(ctx, prompt); err != nil {
+			log.Printf("[bookmarks] Summary of %s failed: %v", b.URL, err)
+		} else if out = strings.TrimSpace(out); out != "" {
+			b.Summary = out
+		}
+	}
+	return nil
+}
+
+// List returns owner's bookmarks matching f, newest first.
+func (s *Service) List(owner string, f Filter) ([]*Bookmark, error) {
+	if f.Tag != "" {
+		if tags := NormalizeTags([]string{f.Tag}); len(tags) > 0 {
+			f.Tag = tags[0]
+		}
+	}
+	f.Query = strings.TrimSpace(f.Query)
+	return s.store.List(owner, f)
+}
+
+// MarkRead marks one of owner's bookmarks read, or unread again.
+func (s *Service) MarkRead(owner string, id int64, read bool) (*Bookmark, error) {
+	return s.update(owner, id, func(b *Bookmark) {
+		switch {
+		case !read:
+			b.ReadAt = time.Time{}
+		case b.ReadAt.IsZero():
+			b.ReadAt = time.Now()
+		}
+	})
+}
+
+// Tag adds and removes tags of one of owner's bookmarks.
+func (s *Service) Tag(owner string, id int64, add, remove []string) (*Bookmark, error) {
+	drop := make(map[string]bool)
+	for _, t := range NormalizeTags(remove) {
+		drop[t] = true
+	}
+	return s.update(owner, id, func(b *Bookmark) {
+		var tags []string
+		for _, t := range NormalizeTags(append(b.Tags, add...)) {
+			if !drop[t] {
+				tags = append(tags, t)
+			}
+		}
+		b.Tags = tags
+	})
+}
+
+func (s *Service) update(owner string, id int64, apply func(*Bookmark)) (*Bookmark, error) {
+	b, err := s.store.Get(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	apply(b)
+	if err := s.store.Save(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Remove deletes one of owner's bookmarks.
+func (s *Service) Remove(owner string, id int64) (*Bookmark, error) {
+	return s.store.Delete(owner, id)
+}
+
+// Digest renders the unread links owner saved in the week before now, or
+// "" when there are none.
+func (s *Service) Digest(owner string, now time.Time) (string, error) {
+	week, err := s.store.List(owner, Filter{Unread: true, Since: now.AddDate(0, 0, -7)})
+	if err != nil || len(week) == 0 {
+		return "", err
+	}
+	unread, err := s.store.CountUnread(owner)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if len(week) == 1 {
+		sb.WriteString("🔖 **You saved this link this week and haven't read it yet:**\n")
+	} else {
+		fmt.Fprintf(&sb, "🔖 **You saved these %d links this week and haven't read them yet:**\n", len(week))
+	}
+	for _, b := range week[:min(digestMax, len(week))] {
+		fmt.Fprintf(&sb, "- #%d [%s](%s)", b.ID, b.Title, b.URL)
+		if b.Summary != "" {
+			sb.WriteString(" — " + truncate(oneLine(b.Summary), 160))
+		}
+		sb.WriteString("\n")
+	}
+	if more := len(week) - digestMax; more > 0 {
+		fmt.Fprintf(&sb, "…and %d more from this week.\n", more)
+	}
+	switch older := unread - len(week); {
+	case older == 1:
+		sb.WriteString("1 older unread link is waiting too.\n")
+	case older > 1:
+		fmt.Fprintf(&sb, "%d older unread links are waiting too.\n", older)
+	}
+	return sb.String(), nil
+}
+
+// fire is the cron handler of the weekly digest: it sends the digest to
+// every chat that saved unread links this week and schedules the next one.
+func (s *Service) fire(job *cron.CronJob) (string, error) {
+	now := time.Now()
+	owners, err := s.store.Owners(now.AddDate(0, 0, -7))
+
+	s.mu.Lock()
+	if scheduleErr := s.scheduleDigest(now); scheduleErr != nil {
+		log.Printf("[bookmarks] Failed to schedule the next digest: %v", scheduleErr)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	sent := 0
+	for _, owner := range owners {
+		digest, err := s.Digest(owner, now)
+		if err != nil {
+			log.Printf("[bookmarks] Digest for %s failed: %v", owner, err)
+			continue
+		}
+		if digest != "" && s.notify != nil {
+			channel, chatID, _ := strings.Cut(owner, ":")
+			s.notify(channel, chatID, digest)
+			sent++
+		}
+	}
+	return fmt.Sprintf("sent %d bookmark digests", sent), nil
+}
+
+// scheduleDigest adds the job of the next digest, on the first digest day
+// after now. Caller must hold s.mu.
+func (s *Service) scheduleDigest(now time.Time) error {
+	next := time.Date(now.Year(), now.Month(), now.Day(), s.digestHour, 0, 0, 0, now.Location())
+	next = next.AddDate(0, 0, (int(s.digestDay)-int(next.Weekday())+7)%7)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	_, err := s.cron.AddOneShot("weekly bookmark digest", next, cron.CronPayload{
+		Kind:    JobKind,
+		Message: strconv.FormatInt(next.Unix(), 10),
+	})
+	return err
+}
+
+// Describe renders a bookmark on one line, e.g.
+// "#3 Go 1.23 Release Notes · https://go.dev/doc/go1.23 #go · unread".
+func (b *Bookmark) Describe() string {
+	s := fmt.Sprintf("#%d %s · %s", b.ID, b.Title, b.URL)
+	for _, t := range b.Tags {
+		s += " #" + t
+	}
+	if b.Unread() {
+		s += " · unread"
+	} else {
+		s += " · read " + b.ReadAt.Format("Jan 2")
+	}
+	return s
+}
+
+// NormalizeURL trims rawURL, adds https:// when the scheme is missing and
+// drops the fragment, so the same page is not saved twice.
+func NormalizeURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", fmt.Errorf("url is required")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid URL %q", rawURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("only http/https URLs can be saved")
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), nil
+}
+
+// NormalizeTags lowercases tags, strips a leading "#", turns inner spaces
+// into dashes and drops blanks and duplicates.
+func NormalizeTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(t)), "#")
+		t = strings.ReplaceAll(strings.Join(strings.Fields(t), "-"), ",", "")
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Preview takes a title and the opening paragraph from the text of a page
+// as web_fetch extracts it: the first heading, or else the first line, is
+// the title.
+func Preview(text string) (title, opening string) {
+	var first string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if heading := strings.TrimSpace(strings.TrimLeft(line, "#")); heading != "" && title == "" {
+				title = heading
+			}
+			continue
+		}
+		if first == "" {
+			first = line
+		}
+		if opening == "" && utf8.RuneCountInString(line) >= 60 && !strings.HasPrefix(line, "•") {
+			opening = line
+		}
+		if title != "" && opening != "" {
+			break
+		}
+	}
+	if title == "" {
+		title = first
+	}
+	if opening == "" && first != title {
+		opening = first
+	}
+	return truncate(oneLine(title), 120), truncate(oneLine(opening), 300)
+}
+
+// ParseWeekday reads a day of the week such as "sunday" or "Sun".
+func ParseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || (len(s) == 3 && strings.HasPrefix(name, s)) {
+			return d, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("%q is not a day of the week", s)
+}
+
+func fallbackTitle(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	return strings.TrimPrefix(parsed.Host, "www.") + strings.TrimSuffix(parsed.Path, "/")
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:limit])) + "…"
+}
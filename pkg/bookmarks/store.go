@@ -0,0 +1,295 @@
+// Package bookmarks keeps a read-later list per conversation in SQLite:
+// links with their title, a short summary and tags, marked read once
+// done, and a weekly digest of the links saved but not yet read.
+package bookmarks
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Bookmark is a saved link. Owner is the session key of the conversation
+// it was saved in ("channel:chat_id").
+type Bookmark struct {
+	ID        int64
+	Owner     string
+	URL       string
+	Title     string
+	Summary   string
+	Tags      []string
+	ReadAt    time.Time // zero while unread
+	CreatedAt time.Time
+}
+
+// Unread reports whether the bookmark has not been marked read.
+func (b *Bookmark) Unread() bool {
+	return b.ReadAt.IsZero()
+}
+
+// Filter narrows a listing. Zero values match everything.
+type Filter struct {
+	Tag    string
+	Query  string    // matched against URL, title and summary
+	Unread bool      // only links not marked read
+	Since  time.Time // only links saved at or after this time
+	Limit  int
+}
+
+// Store persists bookmarks in SQLite.
+type Store struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// NewStore creates or opens the bookmarks table in the database at dbPath.
+func NewStore(dbPath string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create bookmarks directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bookmarks database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate bookmarks database: %w", err)
+	}
+
+	log.Printf("[bookmarks] Store initialized at %s", dbPath)
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS bookmarks (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner      TEXT NOT NULL,
+		url        TEXT NOT NULL,
+		title      TEXT NOT NULL DEFAULT '',
+		summary    TEXT NOT NULL DEFAULT '',
+		tags       TEXT NOT NULL DEFAULT '',
+		read_at    INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		UNIQUE(owner, url)
+	);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_owner_created ON bookmarks(owner, created_at);
+	`)
+	return err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add inserts b and sets its ID. Saving a URL twice in the same chat is
+// an error.
+func (s *Store) Add(b *Bookmark) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = time.Now()
+	}
+	res, err := s.db.Exec(`INSERT INTO bookmarks (owner, url, title, summary, tags, read_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		b.Owner, b.URL, b.Title, b.Summary, joinTags(b.Tags), unixOrZero(b.ReadAt), b.CreatedAt.Unix())
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return fmt.Errorf("%s is already saved", b.URL)
+		}
+		return fmt.Errorf("failed to add bookmark: %w", err)
+	}
+	b.ID, err = res.LastInsertId()
+	return err
+}
+
+// Save updates the title, summary, tags and read time of b.
+func (s *Store) Save(b *Bookmark) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`UPDATE bookmarks SET title = ?, summary = ?, tags = ?, read_at = ? WHERE id = ? AND owner = ?`,
+		b.Title, b.Summary, joinTags(b.Tags), unixOrZero(b.ReadAt), b.ID, b.Owner)
+	if err != nil {
+		return fmt.Errorf("failed to update bookmark: %w", err)
+	}
+	return nil
+}
+
+// Get returns one of owner's bookmarks.
+func (s *Store) Get(owner string, id int64) (*Bookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.query(`WHERE id = ? AND owner = ?`, id, owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("bookmark #%d not found", id)
+	}
+	return list[0], nil
+}
+
+// FindURL returns owner's bookmark of url, or nil if it is not saved.
+func (s *Store) FindURL(owner, url string) (*Bookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.query(`WHERE owner = ? AND url = ?`, owner, url)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// List returns owner's bookmarks matching f, newest first.
+func (s *Store) List(owner string, f Filter) ([]*Bookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clause := `WHERE owner = ?`
+	args := []interface{}{owner}
+	if f.Tag != "" {
+		clause += ` AND tags LIKE ? ESCAPE '\'`
+		args = append(args, "%,"+likeEscape(f.Tag)+",%")
+	}
+	if f.Query != "" {
+		clause += ` AND (url LIKE ? ESCAPE '\' OR title LIKE ? ESCAPE '\' OR summary LIKE ? ESCAPE '\')`
+		q := "%" + likeEscape(f.Query) + "%"
+		args = append(args, q, q, q)
+	}
+	if f.Unread {
+		clause += ` AND read_at = 0`
+	}
+	if !f.Since.IsZero() {
+		clause += ` AND created_at >= ?`
+		args = append(args, f.Since.Unix())
+	}
+	clause += ` ORDER BY created_at DESC, id DESC`
+	if f.Limit > 0 {
+		clause += fmt.Sprintf(` LIMIT %d`, f.Limit)
+	}
+	return s.query(clause, args...)
+}
+
+// CountUnread returns how many of owner's bookmarks are unread.
+func (s *Store) CountUnread(owner string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM bookmarks WHERE owner = ? AND read_at = 0`, owner).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+	return n, nil
+}
+
+// Owners returns the chats with unread bookmarks saved at or after since.
+func (s *Store) Owners(since time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query(`SELECT DISTINCT owner FROM bookmarks WHERE read_at = 0 AND created_at >= ? ORDER BY owner`,
+		since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bookmarks: %w", err)
+	}
+	defer rows.Close()
+	var owners []string
+	for rows.Next() {
+		var owner string
+		if err := rows.Scan(&owner); err != nil {
+			return nil, err
+		}
+		owners = append(owners, owner)
+	}
+	return owners, rows.Err()
+}
+
+// Delete removes one of owner's bookmarks and returns it.
+func (s *Store) Delete(owner string, id int64) (*Bookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.query(`WHERE id = ? AND owner = ?`, id, owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("bookmark #%d not found", id)
+	}
+	if _, err := s.db.Exec(`DELETE FROM bookmarks WHERE id = ?`, id); err != nil {
+		return nil, fmt.Errorf("failed to remove bookmark: %w", err)
+	}
+	return list[0], nil
+}
+
+// query runs a SELECT over bookmarks. Caller must hold s.mu.
+func (s *Store) query(clause string, args ...interface{}) ([]*Bookmark, error) {
+	rows, err := s.db.Query(`SELECT id, owner, url, title, summary, tags, read_at, created_at FROM bookmarks `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Bookmark
+	for rows.Next() {
+		var b Bookmark
+		var tags string
+		var readAt, created int64
+		if err := rows.Scan(&b.ID, &b.Owner, &b.URL, &b.Title, &b.Summary, &tags, &readAt, &created); err != nil {
+			return nil, err
+		}
+		b.Tags = splitTags(tags)
+		if readAt > 0 {
+			b.ReadAt = time.Unix(readAt, 0)
+		}
+		b.CreatedAt = time.Unix(created, 0)
+		list = append(list, &b)
+	}
+	return list, rows.Err()
+}
+
+// joinTags stores tags as ",a,b," so a single tag can be matched with LIKE.
+func joinTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "," + strings.Join(tags, ",") + ","
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,196 @@
+// Package budget caps what the assistant spends on LLM calls per day. The
+// cost of each call is estimated from its token usage and the configured
+// model prices and added to the day's total, which is kept in a JSON file so
+// a restart doesn't reset it and starts over at local midnight.
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// Cost estimates the USD cost of one call from its usage. Prices are looked
+// up by the exact model name first, then without the provider prefix (e.g.
+// "gemini/gemini-2.5-pro" → "gemini-2.5-pro"), then by the longest model
+// family that name starts with ("gpt-4o" prices "gpt-4o-2024-11-20").
+// Unpriced models cost nothing.
+func Cost(pricing map[string]config.ModelPrice, model string, usage *providers.UsageInfo) float64 {
+	if usage == nil {
+		return 0
+	}
+	price, ok := pricing[model]
+	if !ok {
+		name := model[strings.LastIndex(model, "/")+1:]
+		family := ""
+		for key := range pricing {
+			if len(key) > len(family) && strings.HasPrefix(name, key) {
+				family = key
+			}
+		}
+		price, ok = pricing[family]
+	}
+	if !ok {
+		return 0
+	}
+	return float64(usage.PromptTokens)*price.Input/1e6 + float64(usage.CompletionTokens)*price.Output/1e6
+}
+
+type state struct {
+	Day      string  `json:"day"`
+	Spent    float64 `json:"spent"`
+	Notified bool    `json:"notified,omitempty"`
+}
+
+// Tracker adds up the day's spend. A nil Tracker records nothing and is
+// never exceeded.
+type Tracker struct {
+	path       string
+	mu         sync.Mutex
+	limit      float64
+	pricing    map[string]config.ModelPrice
+	state      state
+	onExceeded func(spent, limit float64)
+}
+
+// New opens the tracker stored at path. limit is in USD per day; 0 means
+// spend is only counted.
+func New(path string, limit float64, pricing map[string]config.ModelPrice) *Tracker {
+	t := &Tracker{path: path, limit: limit, pricing: pricing}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[budget] Failed to read %s: %v", path, err)
+		}
+		return t
+	}
+	if err := json.Unmarshal(data, &t.state); err != nil {
+		log.Printf("[budget] Ignoring corrupt %s: %v", path, err)
+	}
+	return t
+}
+
+// SetLimit changes the daily limit and prices, e.g. after a config reload.
+func (t *Tracker) SetLimit(limit float64, pricing map[string]config.ModelPrice) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if limit != t.limit {
+		t.state.Notified = false
+	}
+	t.limit = limit
+	t.pricing = pricing
+}
+
+// OnExceeded sets a function called once a day, when the spend first
+// reaches the limit.
+func (t *Tracker) OnExceeded(fn func(spent, limit float64)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onExceeded = fn
+}
+
+// Record adds the cost of one call made with model.
+func (t *Tracker) Record(model string, usage *providers.UsageInfo, now time.Time) {
+	if t == nil || usage == nil {
+		return
+	}
+	t.mu.Lock()
+	cost := Cost(t.pricing, model, usage)
+	if cost == 0 {
+		t.mu.Unlock()
+		return
+	}
+	t.rollover(now)
+	t.state.Spent += cost
+	var notify func(spent, limit float64)
+	if t.limit > 0 && t.state.Spent >= t.limit && !t.state.Notified {
+		t.state.Notified = true
+		notify = t.onExceeded
+	}
+	spent, limit := t.state.Spent, t.limit
+	if err := t.save(); err != nil {
+		log.Printf("[budget] Failed to save: %v", err)
+	}
+	t.mu.Unlock()
+
+	if notify != nil {
+		log.Printf("[budget] Daily budget of $%.2f reached ($%.2f spent)", limit, spent)
+		notify(spent, limit)
+	}
+}
+
+// Exceeded reports whether today's spend has reached the limit.
+func (t *Tracker) Exceeded(now time.Time) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover(now)
+	return t.limit > 0 && t.state.Spent >= t.limit
+}
+
+// Spent returns today's spend and the limit.
+func (t *Tracker) Spent(now time.Time) (spent, limit float64) {
+	if t == nil {
+		return 0, 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover(now)
+	return t.state.Spent, t.limit
+}
+
+// Meter wraps p so the cost of every call it makes is recorded.
+func (t *Tracker) Meter(p providers.LLMProvider) providers.LLMProvider {
+	if t == nil || p == nil {
+		return p
+	}
+	return &metered{LLMProvider: p, tracker: t}
+}
+
+type metered struct {
+	providers.LLMProvider
+	tracker *Tracker
+}
+
+func (m *metered) Chat(ctx context.Context, messages []providers.Message, tools []providers.ToolDefinition, model string, options map[string]interface{}) (*providers.LLMResponse, error) {
+	resp, err := m.LLMProvider.Chat(ctx, messages, tools, model, options)
+	if err == nil && resp != nil {
+		m.tracker.Record(model, resp.Usage, time.Now())
+	}
+	return resp, err
+}
+
+// rollover starts a new day's total. Callers hold t.mu.
+func (t *Tracker) rollover(now time.Time) {
+	day := now.Format("2006-01-02")
+	if t.state.Day != day {
+		t.state = state{Day: day}
+	}
+}
+
+func (t *Tracker) save() error {
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, data, 0600)
+}
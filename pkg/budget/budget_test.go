@@ -0,0 +1,76 @@
+package budget
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+var pricing = map[string]config.ModelPrice{
+	"gpt-4o": {Input: 2.5, Output: 10},
+}
+
+func TestCost(t *testing.T) {
+	usage := &providers.UsageInfo{PromptTokens: 1_000_000, CompletionTokens: 100_000}
+	if got := Cost(pricing, "openai/gpt-4o", usage); math.Abs(got-3.5) > 1e-9 {
+		t.Errorf("expected $3.50 via the unprefixed name, got %f", got)
+	}
+	if got := Cost(pricing, "openai/gpt-4o-2024-11-20", usage); math.Abs(got-3.5) > 1e-9 {
+		t.Errorf("expected $3.50 via the model family, got %f", got)
+	}
+	if got := Cost(pricing, "unknown", usage); got != 0 {
+		t.Errorf("expected unpriced models to cost nothing, got %f", got)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budget.json")
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	tr := New(path, 1, pricing)
+	notified := 0
+	tr.OnExceeded(func(spent, limit float64) { notified++ })
+
+	// $0.60 per call
+	usage := &providers.UsageInfo{PromptTokens: 200_000, CompletionTokens: 10_000}
+	tr.Record("gpt-4o", usage, now)
+	if tr.Exceeded(now) {
+		t.Fatal("expected the budget not to be exceeded after $0.60")
+	}
+	tr.Record("gpt-4o", usage, now)
+	tr.Record("gpt-4o", usage, now)
+	if !tr.Exceeded(now) || notified != 1 {
+		t.Fatalf("expected the budget to be exceeded once, notified %d times", notified)
+	}
+
+	// The total survives a restart and starts over the next day
+	tr = New(path, 1, pricing)
+	if spent, _ := tr.Spent(now); math.Abs(spent-1.8) > 1e-9 || !tr.Exceeded(now) {
+		t.Errorf("expected $1.80 after a restart, got %f", spent)
+	}
+	if tr.Exceeded(now.Add(24 * time.Hour)) {
+		t.Error("expected a new day to start with a fresh budget")
+	}
+
+	var nilTracker *Tracker
+	nilTracker.Record("gpt-4o", usage, now)
+	if nilTracker.Exceeded(now) {
+		t.Error("expected a nil tracker never to be exceeded")
+	}
+}
+
+func TestMeter(t *testing.T) {
+	tr := New(filepath.Join(t.TempDir(), "budget.json"), 0, pricing)
+	mock := providers.NewMockProvider().SetDefault("ok")
+	messages := []providers.Message{{Role: "user", Content: "What is the weather like today?"}}
+	if _, err := tr.Meter(mock).Chat(context.Background(), messages, nil, "gpt-4o", nil); err != nil {
+		t.Fatal(err)
+	}
+	if spent, _ := tr.Spent(time.Now()); spent <= 0 {
+		t.Errorf("expected the call to be counted, got %f", spent)
+	}
+}
@@ -3,31 +3,83 @@ package bus
 import (
 	"context"
 	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/logger"
 )
 
+// DefaultDedupWindow is how long an inbound message ID is remembered.
+const DefaultDedupWindow = 10 * time.Minute
+
 type MessageBus struct {
-	inbound  chan InboundMessage
-	outbound chan OutboundMessage
-	handlers map[string]MessageHandler
-	mu       sync.RWMutex
+	inbound    chan InboundMessage
+	background chan InboundMessage
+	outbound   chan OutboundMessage
+	reactions  chan Reaction
+	handlers   map[string]MessageHandler
+	mu         sync.RWMutex
+	dedup      *dedupCache
+	wal        *inboundWAL
 }
 
 func NewMessageBus() *MessageBus {
 	return &MessageBus{
-		inbound:  make(chan InboundMessage, 100),
-		outbound: make(chan OutboundMessage, 100),
-		handlers: make(map[string]MessageHandler),
+		inbound:    make(chan InboundMessage, 100),
+		background: make(chan InboundMessage, 100),
+		outbound:   make(chan OutboundMessage, 100),
+		reactions:  make(chan Reaction, 100),
+		handlers:   make(map[string]MessageHandler),
+		dedup:      newDedupCache(DefaultDedupWindow),
+	}
+}
+
+// PublishInbound queues a message for the agent. A message whose ID was
+// already published within the dedup window (a Telegram update redelivered
+// after a reconnect, a bridge replaying its backlog) is dropped, so it is
+// not answered and billed twice; PublishInbound then returns false.
+//
+// Background messages skip the WAL: whoever produced them (a cron job, the
+// heartbeat) runs them again on its own schedule.
+func (mb *MessageBus) PublishInbound(msg InboundMessage) bool {
+	if msg.ID != "" && mb.dedup.Seen(msg.ID, time.Now()) {
+		return false
+	}
+
+	mb.mu.RLock()
+	w := mb.wal
+	mb.mu.RUnlock()
+	if w != nil && msg.Priority == PriorityInteractive {
+		seq, err := w.append(msg)
+		if err != nil {
+			logger.WarnCF("bus", "WAL append failed, message will not survive a restart", map[string]interface{}{"error": err.Error()})
+		}
+		msg.seq = seq
 	}
+
+	mb.lane(msg) <- msg
+	return true
 }
 
-func (mb *MessageBus) PublishInbound(msg InboundMessage) {
-	mb.inbound <- msg
+func (mb *MessageBus) lane(msg InboundMessage) chan InboundMessage {
+	if msg.Priority == PriorityBackground {
+		return mb.background
+	}
+	return mb.inbound
 }
 
+// ConsumeInbound returns the next inbound message, taking interactive
+// messages before background ones.
 func (mb *MessageBus) ConsumeInbound(ctx context.Context) (InboundMessage, bool) {
 	select {
 	case msg := <-mb.inbound:
 		return msg, true
+	default:
+	}
+	select {
+	case msg := <-mb.inbound:
+		return msg, true
+	case msg := <-mb.background:
+		return msg, true
 	case <-ctx.Done():
 		return InboundMessage{}, false
 	}
@@ -46,6 +98,27 @@ func (mb *MessageBus) SubscribeOutbound(ctx context.Context) (OutboundMessage, b
 	}
 }
 
+// PublishReaction queues a reaction for the agent. Reactions are only
+// feedback, so one is dropped rather than blocking a channel when the
+// agent is not keeping up.
+func (mb *MessageBus) PublishReaction(r Reaction) {
+	select {
+	case mb.reactions <- r:
+	default:
+		logger.WarnCF("bus", "Reaction queue full, dropping reaction", map[string]interface{}{"channel": r.Channel})
+	}
+}
+
+// ConsumeReaction returns the next reaction.
+func (mb *MessageBus) ConsumeReaction(ctx context.Context) (Reaction, bool) {
+	select {
+	case r := <-mb.reactions:
+		return r, true
+	case <-ctx.Done():
+		return Reaction{}, false
+	}
+}
+
 func (mb *MessageBus) RegisterHandler(channel string, handler MessageHandler) {
 	mb.mu.Lock()
 	defer mb.mu.Unlock()
@@ -61,5 +134,7 @@ func (mb *MessageBus) GetHandler(channel string) (MessageHandler, bool) {
 
 func (mb *MessageBus) Close() {
 	close(mb.inbound)
+	close(mb.background)
 	close(mb.outbound)
+	close(mb.reactions)
 }
@@ -0,0 +1,40 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// dedupCache remembers message IDs for a sliding window.
+type dedupCache struct {
+	mu        sync.Mutex
+	window    time.Duration
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+func newDedupCache(window time.Duration) *dedupCache {
+	return &dedupCache{window: window, seen: make(map[string]time.Time)}
+}
+
+// Seen reports whether id was recorded within the window, and records it.
+func (d *dedupCache) Seen(id string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if at, ok := d.seen[id]; ok && now.Sub(at) < d.window {
+		return true
+	}
+	d.seen[id] = now
+
+	// Expired IDs are swept at most once per window
+	if now.Sub(d.lastSweep) >= d.window {
+		for k, at := range d.seen {
+			if now.Sub(at) >= d.window {
+				delete(d.seen, k)
+			}
+		}
+		d.lastSweep = now
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package bus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedupCacheWindow(t *testing.T) {
+	d := newDedupCache(time.Minute)
+	start := time.Now()
+
+	if d.Seen("tg-1", start) {
+		t.Fatal("expected a new ID not to be seen")
+	}
+	if !d.Seen("tg-1", start.Add(30*time.Second)) {
+		t.Error("expected a duplicate within the window to be seen")
+	}
+	if d.Seen("tg-2", start.Add(30*time.Second)) {
+		t.Error("expected a different ID not to be seen")
+	}
+	if d.Seen("tg-1", start.Add(2*time.Minute)) {
+		t.Error("expected the ID forgotten once the window passed")
+	}
+}
+
+func TestPublishInboundDropsDuplicates(t *testing.T) {
+	mb := NewMessageBus()
+	msg := InboundMessage{ID: "tg-7", Channel: "telegram", ChatID: "1", Content: "hi"}
+
+	if !mb.PublishInbound(msg) {
+		t.Fatal("expected the first delivery to be published")
+	}
+	if mb.PublishInbound(msg) {
+		t.Error("expected the redelivery to be dropped")
+	}
+	if !mb.PublishInbound(InboundMessage{Channel: "telegram", ChatID: "1", Content: "no id"}) ||
+		!mb.PublishInbound(InboundMessage{Channel: "telegram", ChatID: "1", Content: "no id"}) {
+		t.Error("expected messages without an ID never to be deduplicated")
+	}
+	if got := len(mb.inbound); got != 3 {
+		t.Errorf("expected 3 queued messages, got %d", got)
+	}
+}
@@ -0,0 +1,36 @@
+package bus
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	templateVar   = regexp.MustCompile(`\{([a-z_]+)\}`)
+	templateBlock = regexp.MustCompile(`(?s)\{#([a-z]+)\}(.*?)\{/([a-z]+)\}`)
+)
+
+// RenderTemplate fills an outbound message template. Variables are written
+// as {name}; unknown variables are left as-is. Channel-specific blocks
+// {#telegram}...{/telegram} are kept only when delivering to that channel, so
+// one template can carry e.g. Telegram HTML and plain-text variants.
+func RenderTemplate(tmpl string, vars map[string]string, channel string) string {
+	out := templateBlock.ReplaceAllStringFunc(tmpl, func(block string) string {
+		m := templateBlock.FindStringSubmatch(block)
+		if m[1] != m[3] {
+			return block
+		}
+		if m[1] == channel {
+			return m[2]
+		}
+		return ""
+	})
+
+	out = templateVar.ReplaceAllStringFunc(out, func(v string) string {
+		if val, ok := vars[v[1:len(v)-1]]; ok {
+			return val
+		}
+		return v
+	})
+	return strings.TrimSpace(out)
+}
@@ -1,6 +1,18 @@
 package bus
 
+// Priority decides which inbound work the agent takes first when it is
+// busy. Messages from users are interactive; cron jobs, heartbeats and
+// other work the assistant starts itself go in the background lane and
+// wait while a user is waiting.
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota
+	PriorityBackground
+)
+
 type InboundMessage struct {
+	ID         string            `json:"id,omitempty"` // unique per channel message, used to drop redeliveries
 	Channel    string            `json:"channel"`
 	SenderID   string            `json:"sender_id"`
 	ChatID     string            `json:"chat_id"`
@@ -8,12 +20,77 @@ type InboundMessage struct {
 	Media      []string          `json:"media,omitempty"`
 	SessionKey string            `json:"session_key"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
+	Priority   Priority          `json:"priority,omitempty"`
+	seq        uint64            // WAL sequence number, see MessageBus.Ack
 }
 
+// Kind says what an outbound message is, so channels can render it
+// appropriately: fold thinking away, show progress in italics, or skip
+// what they cannot display well.
+type Kind string
+
+const (
+	KindFinal    Kind = ""         // the answer to a turn, or a notification
+	KindThinking Kind = "thinking" // the model's reasoning before it answers
+	KindStatus   Kind = "status"   // progress while a turn runs, e.g. a tool starting
+	KindError    Kind = "error"    // the turn failed; Content is the user-facing explanation
+)
+
+// GroupChannel is the pseudo-channel of recipient groups: a message to
+// channel "group" with a group's name as ChatID goes to every chat in the
+// group (config channels.recipients).
+const GroupChannel = "group"
+
 type OutboundMessage struct {
 	Channel string `json:"channel"`
 	ChatID  string `json:"chat_id"`
 	Content string `json:"content"`
+	Kind    Kind   `json:"kind,omitempty"`
+	Poll    *Poll  `json:"poll,omitempty"` // send as a native poll where the channel has them
+
+	// Proactive marks messages the assistant sends on its own (cron,
+	// heartbeat, feed, workflow and webhook results) rather than as a
+	// reply; they may be held for the owner's approval, see config approvals
+	Proactive bool `json:"proactive,omitempty"`
+	// Urgent lets a proactive message through the proactivity policy's
+	// daily limit and quiet hours, e.g. a monitor going down
+	Urgent bool `json:"urgent,omitempty"`
+	// Buttons are shown under the message where the channel supports them
+	Buttons []Button `json:"buttons,omitempty"`
+
+	// Set by the channel dispatcher: Content rendered in the channel's
+	// markup (Format) and split into parts that fit its size limit
+	Parts  []string `json:"parts,omitempty"`
+	Format string   `json:"format,omitempty"`
+}
+
+// Poll is a question with fixed answers. Channels without native polls
+// send the message's Content instead, which lists the options.
+type Poll struct {
+	Question string   `json:"question"`
+	Options  []string `json:"options"`
+	Multiple bool     `json:"multiple,omitempty"` // allow choosing several options
+}
+
+// Button is an inline button. Pressing it reports Data back to the channel.
+type Button struct {
+	Label string `json:"label"`
+	Data  string `json:"data"`
+}
+
+// Interim reports whether more messages for the same turn will follow.
+func (m OutboundMessage) Interim() bool {
+	return m.Kind == KindThinking || m.Kind == KindStatus
+}
+
+// Reaction is an emoji a user put on one of the assistant's messages. It is
+// passed to the agent as feedback on that reply, not as a message to answer.
+type Reaction struct {
+	Channel  string `json:"channel"`
+	ChatID   string `json:"chat_id"`
+	SenderID string `json:"sender_id"`
+	Emoji    string `json:"emoji"`
+	Reply    string `json:"reply"` // the message reacted to, as the agent wrote it
 }
 
 type MessageHandler func(InboundMessage) error
@@ -0,0 +1,233 @@
+package bus
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// maxReplays is how many restarts a message may survive unanswered before
+// it is given up on, so a message that crashes the process cannot keep it
+// in a crash loop.
+const maxReplays = 3
+
+// walCompactAfter is how many records may accumulate before the log is
+// rewritten with only the pending messages.
+const walCompactAfter = 1000
+
+// walRecord is one line of the inbound write-ahead log.
+type walRecord struct {
+	Op      string          `json:"op"` // "add" or "ack"
+	Seq     uint64          `json:"seq"`
+	Replays int             `json:"replays,omitempty"`
+	Time    time.Time       `json:"time,omitempty"`
+	Msg     *InboundMessage `json:"msg,omitempty"`
+}
+
+// inboundWAL is an append-only log of accepted inbound messages. A message
+// is added when published and acknowledged once the agent has answered
+// it; anything left unacknowledged at startup is published again.
+type inboundWAL struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	seq     uint64
+	pending map[uint64]walRecord
+	records int
+}
+
+// EnableWAL backs the inbound queue with a write-ahead log at path.
+// Messages logged but never acknowledged by a previous run are queued
+// again, and their IDs seed the dedup window so a platform redelivering
+// them is not processed twice. It returns how many were replayed.
+func (mb *MessageBus) EnableWAL(path string) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, err
+	}
+	pending, seq, err := readWAL(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// Replay counts are bumped on disk before anything is re-queued, so a
+	// crash during the replay still counts against maxReplays
+	w := &inboundWAL{path: path, seq: seq, pending: make(map[uint64]walRecord)}
+	var replay []InboundMessage
+	for _, rec := range pending {
+		if rec.Replays >= maxReplays {
+			logger.WarnCF("bus", "Giving up on inbound message replayed without an answer", map[string]interface{}{
+				"channel": rec.Msg.Channel,
+				"chat_id": rec.Msg.ChatID,
+				"replays": rec.Replays,
+			})
+			continue
+		}
+		rec.Replays++
+		w.pending[rec.Seq] = rec
+		msg := *rec.Msg
+		msg.seq = rec.Seq
+		replay = append(replay, msg)
+	}
+	if err := w.rewrite(w.pending); err != nil {
+		return 0, err
+	}
+
+	mb.mu.Lock()
+	mb.wal = w
+	mb.mu.Unlock()
+
+	now := time.Now()
+	for _, msg := range replay {
+		if msg.ID != "" {
+			mb.dedup.Seen(msg.ID, now)
+		}
+	}
+	go func() {
+		for _, msg := range replay {
+			mb.inbound <- msg
+		}
+	}()
+	return len(replay), nil
+}
+
+// Ack marks an inbound message as handled so it is not replayed after a
+// restart. It is a no-op without a WAL or for messages that were not
+// published through the bus.
+func (mb *MessageBus) Ack(msg InboundMessage) {
+	mb.mu.RLock()
+	w := mb.wal
+	mb.mu.RUnlock()
+	if w == nil || msg.seq == 0 {
+		return
+	}
+	if err := w.ack(msg.seq); err != nil {
+		logger.WarnCF("bus", "WAL ack failed", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// append logs msg and returns its sequence number.
+func (w *inboundWAL) append(msg InboundMessage) (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.seq++
+	rec := walRecord{Op: "add", Seq: w.seq, Time: time.Now(), Msg: &msg}
+	if err := w.write(rec); err != nil {
+		return 0, err
+	}
+	w.pending[rec.Seq] = rec
+	return rec.Seq, nil
+}
+
+func (w *inboundWAL) ack(seq uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.pending[seq]; !ok {
+		return nil
+	}
+	delete(w.pending, seq)
+	if w.records >= walCompactAfter {
+		return w.rewrite(w.pending)
+	}
+	return w.write(walRecord{Op: "ack", Seq: seq})
+}
+
+// write appends one record and syncs it to disk. Callers hold w.mu.
+func (w *inboundWAL) write(rec walRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	w.records++
+	return w.file.Sync()
+}
+
+// rewrite replaces the log with just the given pending records.
+func (w *inboundWAL) rewrite(pending map[uint64]walRecord) error {
+	tmp := w.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	seqs := make([]uint64, 0, len(pending))
+	for seq := range pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	enc := json.NewEncoder(f)
+	for _, seq := range seqs {
+		if err := enc.Encode(pending[seq]); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+	if err := os.Rename(tmp, w.path); err != nil {
+		return err
+	}
+
+	if w.file != nil {
+		w.file.Close()
+	}
+	w.file, err = os.OpenFile(w.path, os.O_APPEND|os.O_WRONLY, 0600)
+	w.records = len(seqs)
+	return err
+}
+
+// readWAL returns the unacknowledged records in path, oldest first, and
+// the highest sequence number used. A torn last line from a crash
+// mid-write is ignored.
+func readWAL(path string) ([]walRecord, uint64, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	pending := make(map[uint64]walRecord)
+	var maxSeq uint64
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec walRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if rec.Seq > maxSeq {
+			maxSeq = rec.Seq
+		}
+		switch rec.Op {
+		case "add":
+			if rec.Msg != nil {
+				pending[rec.Seq] = rec
+			}
+		case "ack":
+			delete(pending, rec.Seq)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]walRecord, 0, len(pending))
+	for _, rec := range pending {
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Seq < out[j].Seq })
+	return out, maxSeq, nil
+}
@@ -0,0 +1,177 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/approvals"
+	"github.com/ntminh611/mclaw/pkg/auth"
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// Approver decides drafts held for the owner's approval. Channels get it
+// through SetApprover to act on the owner's buttons and commands.
+type Approver interface {
+	PendingDrafts() []*approvals.Draft
+	Decide(ctx context.Context, id string, approve bool) (string, error)
+}
+
+// approving is implemented by channels that let the owner decide drafts.
+type approving interface {
+	SetApprover(a Approver)
+}
+
+// attachApprovals holds proactive messages for approval when enabled.
+func (m *Manager) attachApprovals() {
+	if !m.config.Approvals.Enabled {
+		return
+	}
+	expire := time.Duration(m.config.Approvals.ExpireHours) * time.Hour
+	path := filepath.Join(filepath.Dir(m.config.WorkspacePath()), "approvals.json")
+	m.approvals = approvals.NewQueue(path, expire)
+	for _, channel := range m.channels {
+		m.attachApprover(channel)
+	}
+}
+
+func (m *Manager) attachApprover(channel Channel) {
+	if m.approvals == nil {
+		return
+	}
+	if c, ok := channel.(approving); ok {
+		c.SetApprover(m)
+	}
+}
+
+// ownerTarget returns the chat drafts are sent to.
+func (m *Manager) ownerTarget() (string, string) {
+	channel, chatID := m.config.Approvals.Channel, m.config.Approvals.ChatID
+	if chatID == "" {
+		chatID = m.config.OwnerChat(channel)
+	}
+	return channel, chatID
+}
+
+// holdForApproval queues a proactive message as a draft and shows it to the
+// owner, reporting whether it was held. Messages to the owner's own chat
+// are never held.
+func (m *Manager) holdForApproval(ctx context.Context, msg bus.OutboundMessage) bool {
+	if m.approvals == nil || !msg.Proactive || msg.Interim() {
+		return false
+	}
+	channel, chatID := m.ownerTarget()
+	if msg.Channel == channel && msg.ChatID == chatID {
+		return false
+	}
+
+	d, err := m.approvals.Add(msg, time.Now())
+	if err != nil {
+		logger.ErrorCF("channels", "Failed to save draft", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	logger.InfoCF("channels", "Holding proactive message for approval", map[string]interface{}{
+		"draft": d.ID,
+		"to":    d.Target(),
+	})
+
+	draft := bus.OutboundMessage{
+		Channel: channel,
+		ChatID:  chatID,
+		Content: fmt.Sprintf("📝 Draft #%s for %s — send it?\n\n%s\n\n(/approve %s or /reject %s)",
+			d.ID, d.Target(), msg.Content, d.ID, d.ID),
+		Buttons: []bus.Button{
+			{Label: "✅ Send", Data: "approve:" + d.ID},
+			{Label: "🗑 Discard", Data: "reject:" + d.ID},
+		},
+	}
+	if err := m.send(ctx, draft); err != nil {
+		logger.ErrorCF("channels", "Failed to show draft to the owner", map[string]interface{}{
+			"draft": d.ID,
+			"error": err.Error(),
+		})
+	}
+	return true
+}
+
+// PendingDrafts returns the drafts waiting for approval.
+func (m *Manager) PendingDrafts() []*approvals.Draft {
+	if m.approvals == nil {
+		return nil
+	}
+	return m.approvals.Pending(time.Now())
+}
+
+// Decide sends (approve) or discards a draft and describes the outcome.
+func (m *Manager) Decide(ctx context.Context, id string, approve bool) (string, error) {
+	if m.approvals == nil {
+		return "", fmt.Errorf("approvals are not enabled")
+	}
+	d, err := m.approvals.Take(id, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if !approve {
+		return fmt.Sprintf("🗑 Draft #%s for %s discarded", d.ID, d.Target()), nil
+	}
+
+	var errs []error
+	for _, msg := range m.expandRecipients(d.Message) {
+		if err := m.send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s:%s: %w", msg.Channel, msg.ChatID, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return "", fmt.Errorf("draft #%s: %w", d.ID, err)
+	}
+	return fmt.Sprintf("✅ Draft #%s sent to %s", d.ID, d.Target()), nil
+}
+
+// isOwner reports whether senderID may act for the owner, e.g. decide
+// drafts. Without an authorizer every allowed user is an owner.
+func (c *BaseChannel) isOwner(senderID string) bool {
+	if a := c.authorizer(); a != nil {
+		role, _ := a.Role(c.name, senderID)
+		return role == auth.RoleOwner
+	}
+	return c.IsAllowed(senderID)
+}
+
+// approvalCommand runs /approve and /reject and returns the plain-text
+// reply. Without an ID it lists the pending drafts.
+func approvalCommand(ctx context.Context, a Approver, approve bool, arg string) string {
+	if a == nil {
+		return "Approvals are not enabled (approvals.enabled in config.json)."
+	}
+	id := strings.TrimPrefix(strings.TrimSpace(arg), "#")
+	if id == "" {
+		drafts := a.PendingDrafts()
+		if len(drafts) == 0 {
+			return "No drafts waiting for approval."
+		}
+		lines := []string{fmt.Sprintf("Drafts waiting for approval (%d):", len(drafts))}
+		for _, d := range drafts {
+			lines = append(lines, fmt.Sprintf("#%s to %s: %s", d.ID, d.Target(), draftPreview(d.Message.Content, 80)))
+		}
+		return strings.Join(lines, "\n") + "\n\nUsage: /approve <id> or /reject <id>"
+	}
+	result, err := a.Decide(ctx, id, approve)
+	if err != nil {
+		return "⚠️ " + err.Error()
+	}
+	return result
+}
+
+// draftPreview shortens a draft to one line of at most n characters.
+func draftPreview(s string, n int) string {
+	r := []rune(strings.Join(strings.Fields(s), " "))
+	if len(r) <= n {
+		return string(r)
+	}
+	return string(r[:n]) + "…"
+}
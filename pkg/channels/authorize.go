@@ -0,0 +1,99 @@
+package channels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/auth"
+)
+
+// authorized is implemented by channels embedding BaseChannel.
+type authorized interface {
+	SetAuthorizer(a *auth.Authorizer)
+}
+
+// SetAuthorizer switches every channel's allow checks to the shared
+// authorizer, so users granted access with /authorize get through.
+func (m *Manager) SetAuthorizer(a *auth.Authorizer) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, channel := range m.channels {
+		if c, ok := channel.(authorized); ok {
+			c.SetAuthorizer(a)
+		}
+	}
+}
+
+// authorizeCommand runs the owner-only /authorize command and returns the
+// plain-text reply:
+//
+//	/authorize                      list runtime grants
+//	/authorize <id> [guest|owner]   grant access (default guest)
+//	/authorize revoke <id>          take it back
+//
+// An ID may name another channel as "discord:1234"; otherwise it is a user
+// of the channel the command came from.
+func authorizeCommand(a *auth.Authorizer, channel, senderID, arg string) string {
+	if a == nil {
+		return "Access control is not available."
+	}
+	if role, _ := a.Role(channel, senderID); role != auth.RoleOwner {
+		return "Only the owner can authorize users."
+	}
+
+	fields := strings.Fields(arg)
+	if len(fields) == 0 {
+		grants := a.Grants()
+		if len(grants) == 0 {
+			return "No users authorized at runtime. Owners come from allow_from in the config.\n\n" + authorizeUsage
+		}
+		lines := []string{"Authorized users:"}
+		for _, g := range grants {
+			lines = append(lines, fmt.Sprintf("- %s:%s — %s (since %s)", g.Channel, g.UserID, g.Role, g.GrantedAt.Format("2006-01-02")))
+		}
+		return strings.Join(lines, "\n") + "\n\n" + authorizeUsage
+	}
+
+	if fields[0] == "revoke" {
+		if len(fields) != 2 {
+			return authorizeUsage
+		}
+		target, user := splitTarget(channel, fields[1])
+		removed, err := a.Revoke(target, user)
+		switch {
+		case err != nil:
+			return fmt.Sprintf("Revoked %s:%s but could not save: %v", target, user, err)
+		case !removed:
+			return fmt.Sprintf("%s:%s has no runtime access to revoke.", target, user)
+		}
+		return fmt.Sprintf("Revoked access for %s:%s.", target, user)
+	}
+
+	if len(fields) > 2 {
+		return authorizeUsage
+	}
+	role := auth.RoleGuest
+	if len(fields) == 2 {
+		parsed, err := auth.ParseRole(fields[1])
+		if err != nil {
+			return err.Error()
+		}
+		role = parsed
+	}
+	target, user := splitTarget(channel, fields[0])
+	if err := a.Grant(target, user, role, channel+":"+auth.UserID(senderID)); err != nil {
+		return fmt.Sprintf("Granted %s:%s but could not save: %v", target, user, err)
+	}
+	return fmt.Sprintf("Authorized %s:%s as %s.", target, user, role)
+}
+
+const authorizeUsage = "Usage: /authorize <user_id> [guest|owner], /authorize revoke <user_id>\n" +
+	"Prefix the ID with a channel for other platforms, e.g. discord:1234."
+
+// splitTarget reads "channel:id" or a bare ID on the current channel.
+func splitTarget(channel, target string) (string, string) {
+	if name, id, ok := strings.Cut(target, ":"); ok && name != "" && id != "" {
+		return strings.ToLower(name), id
+	}
+	return channel, auth.UserID(target)
+}
@@ -3,9 +3,13 @@ package channels
 import (
 	"context"
 	"strings"
+	"sync"
 	"sync/atomic"
 
+	"github.com/ntminh611/mclaw/pkg/auth"
 	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/inbox"
+	"github.com/ntminh611/mclaw/pkg/logger"
 )
 
 type Channel interface {
@@ -23,6 +27,11 @@ type BaseChannel struct {
 	running   atomic.Bool
 	name      string
 	allowList []string
+	allowMu   sync.RWMutex
+	guard     *BotGuard
+	auth      *auth.Authorizer // replaces allowList when set
+	inbox     *inbox.Inbox     // where received files are kept (nil = temp dir)
+	replies   replyTracker     // recent replies, to match reactions to
 }
 
 func NewBaseChannel(name string, config interface{}, bus *bus.MessageBus, allowList []string) *BaseChannel {
@@ -42,7 +51,34 @@ func (c *BaseChannel) IsRunning() bool {
 	return c.running.Load()
 }
 
+// SetAllowList replaces the allowed senders, e.g. after a config reload.
+func (c *BaseChannel) SetAllowList(allowList []string) {
+	c.allowMu.Lock()
+	defer c.allowMu.Unlock()
+	c.allowList = allowList
+}
+
+// SetAuthorizer hands allow checks to the shared authorizer, which also
+// knows the users granted access with /authorize.
+func (c *BaseChannel) SetAuthorizer(a *auth.Authorizer) {
+	c.allowMu.Lock()
+	defer c.allowMu.Unlock()
+	c.auth = a
+}
+
+func (c *BaseChannel) authorizer() *auth.Authorizer {
+	c.allowMu.RLock()
+	defer c.allowMu.RUnlock()
+	return c.auth
+}
+
 func (c *BaseChannel) IsAllowed(senderID string) bool {
+	c.allowMu.RLock()
+	defer c.allowMu.RUnlock()
+	if c.auth != nil {
+		_, ok := c.auth.Role(c.name, senderID)
+		return ok
+	}
 	if len(c.allowList) == 0 {
 		return true
 	}
@@ -63,12 +99,46 @@ func (c *BaseChannel) IsAllowed(senderID string) bool {
 	return false
 }
 
-func (c *BaseChannel) HandleMessage(senderID, chatID, content string, media []string, metadata map[string]string) {
+// SetInbox makes received files land in the workspace inbox.
+func (c *BaseChannel) SetInbox(in *inbox.Inbox) {
+	c.inbox = in
+}
+
+// ingest moves a downloaded file into the inbox and returns its new path.
+// Without an inbox, or if the move fails, the download path is returned.
+func (c *BaseChannel) ingest(path, original, chatID, senderID string) string {
+	if c.inbox == nil {
+		return path
+	}
+	item, err := c.inbox.Ingest(path, original, c.name, chatID, senderID)
+	if item == nil {
+		logger.WarnCF("channels", "Failed to move file to inbox", map[string]interface{}{
+			"channel": c.name,
+			"error":   err.Error(),
+		})
+		return path
+	}
+	return item.Path
+}
+
+func (c *BaseChannel) setBotGuard(guard *BotGuard) {
+	c.guard = guard
+}
+
+// HandleMessage publishes an inbound message to the bus. It returns false if
+// the message was dropped (sender not allowed, bot loop protection, or a
+// redelivery of a message already handled).
+func (c *BaseChannel) HandleMessage(senderID, chatID, content string, media []string, metadata map[string]string) bool {
 	if !c.IsAllowed(senderID) {
-		return
+		return false
+	}
+
+	if c.guard != nil && !c.guard.Allow(c.name, chatID, metadata) {
+		return false
 	}
 
 	msg := bus.InboundMessage{
+		ID:         messageID(c.name, chatID, metadata),
 		Channel:    c.name,
 		SenderID:   senderID,
 		ChatID:     chatID,
@@ -78,7 +148,24 @@ func (c *BaseChannel) HandleMessage(senderID, chatID, content string, media []st
 		Metadata:   metadata,
 	}
 
-	c.bus.PublishInbound(msg)
+	if !c.bus.PublishInbound(msg) {
+		logger.InfoCF("channels", "Dropped redelivered message", map[string]interface{}{
+			"channel": c.name,
+			"id":      msg.ID,
+		})
+		return false
+	}
+	return true
+}
+
+// messageID identifies an inbound message across redeliveries. Platform
+// message IDs are only unique within a chat on some platforms (Telegram),
+// so the chat is part of the key.
+func messageID(channel, chatID string, metadata map[string]string) string {
+	if id := metadata["message_id"]; id != "" {
+		return channel + ":" + chatID + ":" + id
+	}
+	return ""
 }
 
 func (c *BaseChannel) setRunning(running bool) {
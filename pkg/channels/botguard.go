@@ -0,0 +1,110 @@
+package channels
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+const (
+	// A message arriving this soon after our reply looks machine-generated
+	botReplyThreshold = 3 * time.Second
+	maxBotBackoff     = time.Hour
+)
+
+// BotGuard prevents runaway conversations with other bots by limiting
+// bot-to-bot exchanges per chat and backing off when the limit is hit.
+type BotGuard struct {
+	maxExchanges int
+	window       time.Duration
+	backoff      time.Duration
+	chats        map[string]*botChatState
+	mu           sync.Mutex
+}
+
+type botChatState struct {
+	lastReply    time.Time
+	exchanges    []time.Time // bot-like messages within the window
+	backoff      time.Duration
+	backoffUntil time.Time
+}
+
+func NewBotGuard(cfg config.BotGuardConfig) *BotGuard {
+	return &BotGuard{
+		maxExchanges: cfg.MaxExchanges,
+		window:       time.Duration(cfg.WindowMinutes) * time.Minute,
+		backoff:      time.Duration(cfg.BackoffMinutes) * time.Minute,
+		chats:        make(map[string]*botChatState),
+	}
+}
+
+func (g *BotGuard) state(channel, chatID string) *botChatState {
+	key := channel + ":" + chatID
+	st, ok := g.chats[key]
+	if !ok {
+		st = &botChatState{}
+		g.chats[key] = st
+	}
+	return st
+}
+
+// Allow reports whether an inbound message should be processed. Messages
+// from humans always pass and reset the chat's counters.
+func (g *BotGuard) Allow(channel, chatID string, metadata map[string]string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	st := g.state(channel, chatID)
+
+	botLike := metadata["is_bot"] == "true" ||
+		(!st.lastReply.IsZero() && now.Sub(st.lastReply) < botReplyThreshold)
+	if !botLike {
+		st.exchanges = nil
+		st.backoff = 0
+		st.backoffUntil = time.Time{}
+		return true
+	}
+
+	if now.Before(st.backoffUntil) {
+		return false
+	}
+
+	kept := st.exchanges[:0]
+	for _, t := range st.exchanges {
+		if now.Sub(t) < g.window {
+			kept = append(kept, t)
+		}
+	}
+	st.exchanges = append(kept, now)
+
+	if g.maxExchanges > 0 && len(st.exchanges) > g.maxExchanges {
+		if st.backoff == 0 {
+			st.backoff = g.backoff
+		} else {
+			st.backoff *= 2
+		}
+		if st.backoff > maxBotBackoff {
+			st.backoff = maxBotBackoff
+		}
+		st.backoffUntil = now.Add(st.backoff)
+		st.exchanges = nil
+		logger.WarnCF("channels", "Bot-to-bot loop detected, muting chat", map[string]interface{}{
+			"channel": channel,
+			"chat_id": chatID,
+			"backoff": st.backoff.String(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// RecordReply notes that we just replied in a chat.
+func (g *BotGuard) RecordReply(channel, chatID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.state(channel, chatID).lastReply = time.Now()
+}
@@ -0,0 +1,30 @@
+package channels
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CheckTelegramToken calls getMe and returns the bot's username.
+func CheckTelegramToken(token string) (string, error) {
+	bot, err := tgbotapi.NewBotAPI(token)
+	if err != nil {
+		return "", fmt.Errorf("telegram rejected the token: %w", err)
+	}
+	return bot.Self.UserName, nil
+}
+
+// CheckDiscordToken fetches the bot user and returns its name.
+func CheckDiscordToken(token string) (string, error) {
+	session, err := discordgo.New("Bot " + token)
+	if err != nil {
+		return "", err
+	}
+	user, err := session.User("@me")
+	if err != nil {
+		return "", fmt.Errorf("discord rejected the token: %w", err)
+	}
+	return user.Username, nil
+}
@@ -0,0 +1,120 @@
+package channels
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+const (
+	// Room reserved in each part for the part header and continuation marker
+	partMarkerReserve = 120
+	// Replies split into at least this many parts get a table of contents
+	tocMinParts = 3
+	maxTOCItems = 12
+)
+
+var headingLine = regexp.MustCompile(`(?m)^#{1,3}\s+(.+?)\s*#*\s*$`)
+
+// renderText turns an outbound message into Markdown for channels without
+// a richer way to show its kind.
+func renderText(msg bus.OutboundMessage) string {
+	switch msg.Kind {
+	case bus.KindThinking:
+		return "💭 *Thinking:*\n\n" + msg.Content
+	case bus.KindStatus:
+		return "_" + strings.TrimSpace(msg.Content) + "_"
+	default:
+		return msg.Content
+	}
+}
+
+// composeParts splits a long reply into numbered parts that fit maxLen.
+// Each part is labeled "Part i/n", all but the last end with a continuation
+// marker, code fences are closed and reopened across part boundaries, and
+// long multi-section replies start with a table of contents.
+func composeParts(content string, maxLen int) []string {
+	if len(content) <= maxLen {
+		return []string{content}
+	}
+
+	split := func(text string) []string {
+		return balanceFences(splitMessage(text, maxLen-partMarkerReserve))
+	}
+
+	// The TOC shifts content into later parts, so rebuild it until the part
+	// numbers it lists are stable
+	chunks := split(content)
+	for i, toc := 0, buildTOC(chunks); i < 3 && toc != ""; i++ {
+		chunks = split(toc + "\n" + content)
+		next := buildTOC(chunks)
+		if next == toc {
+			break
+		}
+		toc = next
+	}
+	n := len(chunks)
+
+	parts := make([]string, n)
+	for i, chunk := range chunks {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("📄 *Part %d/%d*\n\n", i+1, n))
+		sb.WriteString(chunk)
+		if i < n-1 {
+			sb.WriteString(fmt.Sprintf("\n\n_… continued in part %d/%d_", i+2, n))
+		}
+		parts[i] = sb.String()
+	}
+	return parts
+}
+
+// buildTOC lists the reply's Markdown headings with the part each starts in.
+func buildTOC(chunks []string) string {
+	if len(chunks) < tocMinParts {
+		return ""
+	}
+
+	var items []string
+	for i, chunk := range chunks {
+		for _, m := range headingLine.FindAllStringSubmatch(chunk, -1) {
+			items = append(items, fmt.Sprintf("• %s — part %d", m[1], i+1))
+		}
+	}
+	if len(items) < 2 {
+		return ""
+	}
+	if len(items) > maxTOCItems {
+		items = append(items[:maxTOCItems], "• …")
+	}
+	return "*Contents*\n" + strings.Join(items, "\n") + "\n"
+}
+
+// balanceFences closes a code block left open at the end of a chunk and
+// reopens it at the start of the next, so each part renders on its own.
+func balanceFences(chunks []string) []string {
+	open := ""
+	for i, chunk := range chunks {
+		if open != "" {
+			chunk = open + "\n" + chunk
+			open = ""
+		}
+		lines := strings.Split(chunk, "\n")
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "```") {
+				if open == "" {
+					open = trimmed // keep the language tag when reopening
+				} else {
+					open = ""
+				}
+			}
+		}
+		if open != "" {
+			chunk += "\n```"
+		}
+		chunks[i] = chunk
+	}
+	return chunks
+}
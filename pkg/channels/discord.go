@@ -15,6 +15,7 @@ import (
 	"github.com/ntminh611/mclaw/pkg/bus"
 	"github.com/ntminh611/mclaw/pkg/config"
 	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/storage"
 	"github.com/ntminh611/mclaw/pkg/voice"
 )
 
@@ -22,7 +23,7 @@ type DiscordChannel struct {
 	*BaseChannel
 	session     *discordgo.Session
 	config      config.DiscordConfig
-	transcriber *voice.GroqTranscriber
+	transcriber voice.Transcriber
 }
 
 func NewDiscordChannel(cfg config.DiscordConfig, bus *bus.MessageBus) (*DiscordChannel, error) {
@@ -41,7 +42,7 @@ func NewDiscordChannel(cfg config.DiscordConfig, bus *bus.MessageBus) (*DiscordC
 	}, nil
 }
 
-func (c *DiscordChannel) SetTranscriber(transcriber *voice.GroqTranscriber) {
+func (c *DiscordChannel) SetTranscriber(transcriber voice.Transcriber) {
 	c.transcriber = transcriber
 }
 
@@ -49,6 +50,7 @@ func (c *DiscordChannel) Start(ctx context.Context) error {
 	logger.InfoC("discord", "Starting Discord bot")
 
 	c.session.AddHandler(c.handleMessage)
+	c.session.AddHandler(c.handleReaction)
 
 	if err := c.session.Open(); err != nil {
 		return fmt.Errorf("failed to open discord session: %w", err)
@@ -89,15 +91,26 @@ func (c *DiscordChannel) Send(ctx context.Context, msg bus.OutboundMessage) erro
 		return fmt.Errorf("channel ID is empty")
 	}
 
-	message := msg.Content
-
-	if _, err := c.session.ChannelMessageSend(channelID, message); err != nil {
-		return fmt.Errorf("failed to send discord message: %w", err)
+	msg = formatMessage(msg, profileFor(c.Name(), nil))
+	for _, part := range msg.Parts {
+		sent, err := c.session.ChannelMessageSend(channelID, part)
+		if err != nil {
+			return fmt.Errorf("failed to send discord message: %w", err)
+		}
+		c.rememberReply(channelID, sent.ID, msg)
 	}
 
 	return nil
 }
 
+// handleReaction passes reactions on the bot's replies on as feedback.
+func (c *DiscordChannel) handleReaction(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	if r == nil || r.MessageReaction == nil || r.UserID == s.State.User.ID {
+		return
+	}
+	c.HandleReaction(r.UserID, r.ChannelID, r.MessageID, r.Emoji.Name)
+}
+
 func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m == nil || m.Author == nil {
 		return
@@ -113,6 +126,23 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 		senderName += "#" + m.Author.Discriminator
 	}
 
+	if arg, ok := strings.CutPrefix(m.Content, "/authorize"); ok && (arg == "" || arg[0] == ' ') {
+		reply := authorizeCommand(c.authorizer(), "discord", senderID, arg)
+		if _, err := s.ChannelMessageSend(m.ChannelID, reply); err != nil {
+			logger.WarnCF("discord", "Failed to send /authorize reply", map[string]interface{}{"error": err.Error()})
+		}
+		return
+	}
+
+	if arg, ok := strings.CutPrefix(m.Content, "/retry"); ok && (arg == "" || arg[0] == ' ') {
+		metadata := map[string]string{"retry": "true", "message_id": m.ID}
+		if model := strings.TrimSpace(arg); model != "" {
+			metadata["model"] = model
+		}
+		c.HandleMessage(senderID, m.ChannelID, m.Content, nil, metadata)
+		return
+	}
+
 	content := m.Content
 	mediaPaths := []string{}
 
@@ -152,6 +182,17 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 				}
 				content += fmt.Sprintf("[attachment: %s]", attachment.URL)
 			}
+		} else if c.inbox != nil {
+			// Keep the file where file tools can read it
+			ref := attachment.URL
+			if localPath := c.downloadAttachment(attachment.URL, attachment.Filename); localPath != "" {
+				ref = c.ingest(localPath, attachment.Filename, m.ChannelID, senderID)
+			}
+			mediaPaths = append(mediaPaths, ref)
+			if content != "" {
+				content += "\n"
+			}
+			content += fmt.Sprintf("[file: %s]", ref)
 		} else {
 			mediaPaths = append(mediaPaths, attachment.URL)
 			if content != "" {
@@ -184,6 +225,9 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 		"channel_id":   m.ChannelID,
 		"is_dm":        fmt.Sprintf("%t", m.GuildID == ""),
 	}
+	if m.Author.Bot {
+		metadata["is_bot"] = "true"
+	}
 
 	c.HandleMessage(senderID, m.ChannelID, content, mediaPaths, metadata)
 }
@@ -208,7 +252,7 @@ func isAudioFile(filename, contentType string) bool {
 }
 
 func (c *DiscordChannel) downloadAttachment(url, filename string) string {
-	mediaDir := filepath.Join(os.TempDir(), "mclaw_media")
+	mediaDir := storage.MediaDir()
 	if err := os.MkdirAll(mediaDir, 0755); err != nil {
 		log.Printf("Failed to create media directory: %v", err)
 		return ""
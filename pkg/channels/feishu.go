@@ -94,7 +94,22 @@ func (c *FeishuChannel) Send(ctx context.Context, msg bus.OutboundMessage) error
 		return fmt.Errorf("chat ID is empty")
 	}
 
-	payload, err := json.Marshal(map[string]string{"text": msg.Content})
+	msg = formatMessage(msg, profileFor(c.Name(), nil))
+	for _, part := range msg.Parts {
+		if err := c.sendText(ctx, msg.ChatID, part); err != nil {
+			return err
+		}
+	}
+
+	logger.DebugCF("feishu", "Feishu message sent", map[string]interface{}{
+		"chat_id": msg.ChatID,
+	})
+
+	return nil
+}
+
+func (c *FeishuChannel) sendText(ctx context.Context, chatID, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
 	if err != nil {
 		return fmt.Errorf("failed to marshal feishu content: %w", err)
 	}
@@ -102,7 +117,7 @@ func (c *FeishuChannel) Send(ctx context.Context, msg bus.OutboundMessage) error
 	req := larkim.NewCreateMessageReqBuilder().
 		ReceiveIdType(larkim.ReceiveIdTypeChatId).
 		Body(larkim.NewCreateMessageReqBodyBuilder().
-			ReceiveId(msg.ChatID).
+			ReceiveId(chatID).
 			MsgType(larkim.MsgTypeText).
 			Content(string(payload)).
 			Uuid(fmt.Sprintf("mclaw-%d", time.Now().UnixNano())).
@@ -117,11 +132,6 @@ func (c *FeishuChannel) Send(ctx context.Context, msg bus.OutboundMessage) error
 	if !resp.Success() {
 		return fmt.Errorf("feishu api error: code=%d msg=%s", resp.Code, resp.Msg)
 	}
-
-	logger.DebugCF("feishu", "Feishu message sent", map[string]interface{}{
-		"chat_id": msg.ChatID,
-	})
-
 	return nil
 }
 
@@ -0,0 +1,56 @@
+package channels
+
+import (
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/markdown"
+)
+
+// Profile says how the agent's Markdown is adapted for a channel.
+type Profile struct {
+	Format markdown.Format
+	MaxLen int // longer replies are split into labeled parts; 0 = never split
+}
+
+// profiles are the defaults per channel name. Channels not listed get
+// their replies as plain text in one piece.
+var profiles = map[string]Profile{
+	"telegram": {Format: markdown.FormatTelegramHTML, MaxLen: 4000},
+	"discord":  {Format: markdown.FormatDiscord, MaxLen: 1900},
+	"slack":    {Format: markdown.FormatSlack, MaxLen: 3900},
+	"whatsapp": {Format: markdown.FormatWhatsApp, MaxLen: 4000},
+	"feishu":   {Format: markdown.FormatPlain, MaxLen: 4000},
+	"sms":      {Format: markdown.FormatPlain, MaxLen: 1500},
+	"email":    {Format: markdown.FormatPlain},
+}
+
+// profileFor returns the profile of a channel, with the format replaced
+// by channels.format[channel] when configured.
+func profileFor(channel string, overrides map[string]string) Profile {
+	p, ok := profiles[channel]
+	if !ok {
+		p = Profile{Format: markdown.FormatPlain}
+	}
+	if f := markdown.Format(overrides[channel]); markdown.Known(f) {
+		p.Format = f
+	}
+	return p
+}
+
+// formatMessage fills in msg.Parts and msg.Format for the profile. Parts
+// already set are kept.
+func formatMessage(msg bus.OutboundMessage, p Profile) bus.OutboundMessage {
+	if len(msg.Parts) > 0 {
+		return msg
+	}
+	text := renderText(msg)
+	chunks := []string{text}
+	if p.MaxLen > 0 {
+		chunks = composeParts(text, p.MaxLen)
+	}
+	for i, chunk := range chunks {
+		chunks[i] = markdown.Render(chunk, p.Format)
+	}
+	msg.Parts = chunks
+	msg.Format = string(p.Format)
+	return msg
+}
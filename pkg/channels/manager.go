@@ -8,12 +8,21 @@ package channels
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/ntminh611/mclaw/pkg/approvals"
 	"github.com/ntminh611/mclaw/pkg/bus"
 	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/inbox"
 	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/netguard"
+	"github.com/ntminh611/mclaw/pkg/proactivity"
+	"github.com/ntminh611/mclaw/pkg/voice"
 )
 
 type Manager struct {
@@ -21,9 +30,18 @@ type Manager struct {
 	bus          *bus.MessageBus
 	config       *config.Config
 	dispatchTask *asyncTask
+	botGuard     *BotGuard
+	inbox        *inbox.Inbox
+	approvals    *approvals.Queue
+	proactivity  *proactivity.Policy
 	mu           sync.RWMutex
 }
 
+// botGuarded is implemented by channels embedding BaseChannel.
+type botGuarded interface {
+	setBotGuard(guard *BotGuard)
+}
+
 type asyncTask struct {
 	cancel context.CancelFunc
 }
@@ -35,13 +53,61 @@ func NewManager(cfg *config.Config, messageBus *bus.MessageBus) (*Manager, error
 		config:   cfg,
 	}
 
+	if cfg.BotGuard.Enabled {
+		m.botGuard = NewBotGuard(cfg.BotGuard)
+	}
+
 	if err := m.initChannels(); err != nil {
 		return nil, err
 	}
 
+	for _, channel := range m.channels {
+		m.attachBotGuard(channel)
+		m.attachModelSelector(channel)
+	}
+	m.attachInbox()
+	m.attachApprovals()
+	m.attachProactivity()
+
+	cfg.OnReload(m.applyConfig)
+
 	return m, nil
 }
 
+// allowListed is implemented by channels embedding BaseChannel.
+type allowListed interface {
+	SetAllowList(allowList []string)
+}
+
+// applyConfig pushes reloaded allow lists, tool policy and projects into
+// the running channels without reconnecting them.
+func (m *Manager) applyConfig(cfg *config.Config, changed []string) {
+	allowLists := map[string][]string{
+		"telegram": cfg.Channels.Telegram.AllowFrom,
+		"discord":  cfg.Channels.Discord.AllowFrom,
+		"feishu":   cfg.Channels.Feishu.AllowFrom,
+		"whatsapp": cfg.Channels.WhatsApp.AllowFrom,
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for name, channel := range m.channels {
+		if a, ok := channel.(allowListed); ok {
+			if list, known := allowLists[name]; known {
+				a.SetAllowList(list)
+			}
+		}
+	}
+	if telegram, ok := m.channels["telegram"].(*TelegramChannel); ok {
+		telegram.SetToolPolicy(cfg.Tools.Policy)
+		telegram.SetProjects(cfg.Projects)
+		telegram.SetModelName(cfg.Agents.Defaults.Model)
+	}
+	logger.InfoCF("channels", "Applied reloaded config", map[string]interface{}{
+		"changed": strings.Join(changed, ", "),
+	})
+}
+
 func (m *Manager) initChannels() error {
 	logger.InfoC("channels", "Initializing channel manager")
 
@@ -53,6 +119,21 @@ func (m *Manager) initChannels() error {
 				"error": err.Error(),
 			})
 		} else {
+			telegram.SetProjects(m.config.Projects)
+			telegram.SetToolPolicy(m.config.Tools.Policy)
+			telegram.SetNetworkGuard(netguard.New(m.config.Tools.Network.AllowPrivate))
+			if m.config.Channels.Telegram.VoiceReplies {
+				synth, err := voice.NewSynthesizer(m.config.TTS, m.config.Providers.OpenAI.APIKey)
+				if err != nil {
+					logger.WarnCF("channels", "Voice replies disabled", map[string]interface{}{
+						"error": err.Error(),
+					})
+				} else if synth == nil {
+					logger.WarnC("channels", "Voice replies need a tts provider; disabled")
+				} else {
+					telegram.SetSynthesizer(synth, m.config.TTS.MaxChars)
+				}
+			}
 			m.channels["telegram"] = telegram
 			logger.InfoC("channels", "Telegram channel enabled successfully")
 		}
@@ -97,6 +178,8 @@ func (m *Manager) initChannels() error {
 		}
 	}
 
+	m.attachTranscriber()
+
 	logger.InfoCF("channels", "Channel initialization completed", map[string]interface{}{
 		"enabled_channels": len(m.channels),
 	})
@@ -104,13 +187,73 @@ func (m *Manager) initChannels() error {
 	return nil
 }
 
+// transcribing is implemented by channels that accept voice messages.
+type transcribing interface {
+	SetTranscriber(transcriber voice.Transcriber)
+}
+
+// attachTranscriber wires a self-hosted speech-to-text backend into the
+// voice-capable channels. The default Groq transcriber is set up by the
+// caller, so only explicitly configured local backends are handled here.
+func (m *Manager) attachTranscriber() {
+	provider := strings.ToLower(m.config.STT.Provider)
+	if provider == "" || provider == "groq" {
+		return
+	}
+
+	transcriber, err := voice.NewTranscriber(m.config.STT, m.config.Providers.Groq.APIKey)
+	if err != nil {
+		logger.WarnCF("channels", "Voice transcription disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, channel := range m.channels {
+		if t, ok := channel.(transcribing); ok {
+			t.SetTranscriber(transcriber)
+		}
+	}
+	logger.InfoCF("channels", "Voice transcription backend configured", map[string]interface{}{
+		"backend": transcriber.Name(),
+	})
+}
+
+func (m *Manager) attachBotGuard(channel Channel) {
+	if m.botGuard == nil {
+		return
+	}
+	if g, ok := channel.(botGuarded); ok {
+		g.setBotGuard(m.botGuard)
+	}
+}
+
+// inboxed is implemented by channels embedding BaseChannel.
+type inboxed interface {
+	SetInbox(in *inbox.Inbox)
+}
+
+// attachInbox makes the channels keep received files in workspace/inbox.
+func (m *Manager) attachInbox() {
+	if !m.config.Inbox.Enabled {
+		return
+	}
+	retention := time.Duration(m.config.Inbox.RetentionDays) * 24 * time.Hour
+	m.inbox = inbox.New(filepath.Join(m.config.WorkspacePath(), "inbox"), retention)
+	for _, channel := range m.channels {
+		if c, ok := channel.(inboxed); ok {
+			c.SetInbox(m.inbox)
+		}
+	}
+}
+
 func (m *Manager) StartAll(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// The dispatcher still runs: channels may be registered later
 	if len(m.channels) == 0 {
 		logger.WarnC("channels", "No channels enabled")
-		return nil
 	}
 
 	logger.InfoC("channels", "Starting all channels")
@@ -119,6 +262,12 @@ func (m *Manager) StartAll(ctx context.Context) error {
 	m.dispatchTask = &asyncTask{cancel: cancel}
 
 	go m.dispatchOutbound(dispatchCtx)
+	if m.inbox != nil {
+		go m.inbox.Run(dispatchCtx)
+	}
+	if m.proactivity != nil {
+		go m.releaseHeld(dispatchCtx)
+	}
 
 	for name, channel := range m.channels {
 		logger.InfoCF("channels", "Starting channel", map[string]interface{}{
@@ -177,25 +326,68 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				continue
 			}
 
-			m.mu.RLock()
-			channel, exists := m.channels[msg.Channel]
-			m.mu.RUnlock()
-
-			if !exists {
-				logger.WarnCF("channels", "Unknown channel for outbound message", map[string]interface{}{
-					"channel": msg.Channel,
-				})
+			if m.throttle(msg) || m.holdForApproval(ctx, msg) {
 				continue
 			}
+			m.deliver(ctx, msg)
+		}
+	}
+}
 
-			if err := channel.Send(ctx, msg); err != nil {
-				logger.ErrorCF("channels", "Error sending message to channel", map[string]interface{}{
-					"channel": msg.Channel,
-					"error":   err.Error(),
-				})
-			}
+// deliver sends msg, or each message of a recipient group, logging failures.
+func (m *Manager) deliver(ctx context.Context, msg bus.OutboundMessage) {
+	for _, msg := range m.expandRecipients(msg) {
+		if err := m.send(ctx, msg); err != nil {
+			logger.ErrorCF("channels", "Error sending message to channel", map[string]interface{}{
+				"channel": msg.Channel,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// send formats msg for its channel and sends it.
+func (m *Manager) send(ctx context.Context, msg bus.OutboundMessage) error {
+	m.mu.RLock()
+	channel, exists := m.channels[msg.Channel]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("channel %s not found", msg.Channel)
+	}
+
+	msg = formatMessage(msg, profileFor(msg.Channel, m.config.Channels.Format))
+	if err := channel.Send(ctx, msg); err != nil {
+		return err
+	}
+	if m.botGuard != nil && !msg.Interim() {
+		m.botGuard.RecordReply(msg.Channel, msg.ChatID)
+	}
+	return nil
+}
+
+// expandRecipients turns a message to a recipient group into one message
+// per chat in the group. Other messages are returned as they are.
+func (m *Manager) expandRecipients(msg bus.OutboundMessage) []bus.OutboundMessage {
+	if msg.Channel != bus.GroupChannel {
+		return []bus.OutboundMessage{msg}
+	}
+	members := m.config.Channels.Recipients[msg.ChatID]
+	if len(members) == 0 {
+		logger.WarnCF("channels", "Unknown recipient group", map[string]interface{}{"group": msg.ChatID})
+		return nil
+	}
+	out := make([]bus.OutboundMessage, 0, len(members))
+	for _, member := range members {
+		channel, chatID, ok := strings.Cut(member, ":")
+		if !ok || channel == bus.GroupChannel {
+			continue
 		}
+		target := msg
+		target.Channel, target.ChatID = channel, chatID
+		out = append(out, target)
 	}
+	return out
 }
 
 func (m *Manager) GetChannel(name string) (Channel, bool) {
@@ -233,6 +425,9 @@ func (m *Manager) GetEnabledChannels() []string {
 func (m *Manager) RegisterChannel(name string, channel Channel) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.attachBotGuard(channel)
+	m.attachApprover(channel)
+	m.attachModelSelector(channel)
 	m.channels[name] = channel
 }
 
@@ -242,20 +437,29 @@ func (m *Manager) UnregisterChannel(name string) {
 	delete(m.channels, name)
 }
 
+// SendToChannel delivers a message the assistant sends on its own, such as
+// a cron or heartbeat result. The proactivity policy may hold it for after
+// the quiet hours or drop it, and with approvals enabled it is held as a
+// draft for the owner unless it goes to the owner's chat.
 func (m *Manager) SendToChannel(ctx context.Context, channelName, chatID, content string) error {
-	m.mu.RLock()
-	channel, exists := m.channels[channelName]
-	m.mu.RUnlock()
-
-	if !exists {
-		return fmt.Errorf("channel %s not found", channelName)
+	msg := bus.OutboundMessage{Channel: channelName, ChatID: chatID, Content: content, Proactive: true}
+	if channelName == bus.GroupChannel {
+		if _, ok := m.config.Channels.Recipients[chatID]; !ok {
+			return fmt.Errorf("recipient group %s not found", chatID)
+		}
 	}
-
-	msg := bus.OutboundMessage{
-		Channel: channelName,
-		ChatID:  chatID,
-		Content: content,
+	if m.throttle(msg) || m.holdForApproval(ctx, msg) {
+		return nil
 	}
 
-	return channel.Send(ctx, msg)
+	var errs []error
+	for _, msg := range m.expandRecipients(msg) {
+		if err := m.send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s:%s: %w", msg.Channel, msg.ChatID, err))
+		}
+	}
+	if len(errs) == 1 && channelName != bus.GroupChannel {
+		return errors.Unwrap(errs[0])
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,138 @@
+package channels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/providers"
+	"github.com/ntminh611/mclaw/pkg/session"
+)
+
+// ModelSelector lists the configured models and switches the default one.
+// Channels get it through SetModelSelector to run /model.
+type ModelSelector interface {
+	DefaultModel() string
+	ModelAliases() map[string]string
+	CheckModel(name string) (string, error)
+	SetDefaultModel(name string) (string, error)
+}
+
+// modelSelecting is implemented by channels with a /model command.
+type modelSelecting interface {
+	SetModelSelector(s ModelSelector)
+}
+
+func (m *Manager) attachModelSelector(channel Channel) {
+	if c, ok := channel.(modelSelecting); ok {
+		c.SetModelSelector(m)
+	}
+}
+
+// DefaultModel returns the model every session uses unless it chose another.
+func (m *Manager) DefaultModel() string {
+	return m.config.Agents.Defaults.Model
+}
+
+// ModelAliases returns the short names from agents.defaults.models.
+func (m *Manager) ModelAliases() map[string]string {
+	aliases := make(map[string]string, len(m.config.Agents.Defaults.Models))
+	for name, model := range m.config.Agents.Defaults.Models {
+		aliases[name] = model
+	}
+	return aliases
+}
+
+// CheckModel resolves an alias and checks that a provider is configured
+// for the model, returning the full model name.
+func (m *Manager) CheckModel(name string) (string, error) {
+	model := m.config.ResolveModel(name)
+	if _, err := providers.CreateProviderForModel(m.config, model); err != nil {
+		return "", fmt.Errorf("can't use model %s: %v", name, err)
+	}
+	return model, nil
+}
+
+// SetDefaultModel writes a new default model to the config file and reloads
+// it, which switches the agent over without a restart.
+func (m *Manager) SetDefaultModel(name string) (string, error) {
+	model, err := m.CheckModel(name)
+	if err != nil {
+		return "", err
+	}
+	path := m.config.Path()
+	if path == "" {
+		return "", fmt.Errorf("no config file to save the default model to")
+	}
+	if err := config.SetValue(path, "agents.defaults.model", model); err != nil {
+		return "", err
+	}
+	if err := m.config.Reload(); err != nil {
+		return "", err
+	}
+	return model, nil
+}
+
+// modelCommand runs /model and returns the plain-text reply. Without an
+// argument it lists the models; a name switches this session, "reset"
+// goes back to the default and "default <name>" changes the default for
+// everyone, which only the owner may do.
+func modelCommand(s ModelSelector, sm *session.SessionManager, sessionKey string, owner bool, arg string) string {
+	if s == nil || sm == nil {
+		return "⚠️ Model switching not available."
+	}
+	fields := strings.Fields(arg)
+	current := sm.GetModel(sessionKey)
+
+	switch {
+	case len(fields) == 0:
+		lines := []string{"🤖 Default model: " + s.DefaultModel()}
+		if current != "" {
+			lines = append(lines, "This chat uses: "+current)
+		}
+		aliases := s.ModelAliases()
+		if len(aliases) > 0 {
+			names := make([]string, 0, len(aliases))
+			for name := range aliases {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			lines = append(lines, "", "Models:")
+			for _, name := range names {
+				lines = append(lines, fmt.Sprintf("• %s — %s", name, aliases[name]))
+			}
+		}
+		return strings.Join(lines, "\n") + "\n\nUsage: /model <name> for this chat, /model reset to go back to the default, /model default <name> to change the default"
+
+	case len(fields) == 1 && (fields[0] == "reset" || fields[0] == "off"):
+		if current == "" {
+			return "This chat already uses the default model, " + s.DefaultModel() + "."
+		}
+		sm.SetModel(sessionKey, "")
+		return "🤖 Back to the default model, " + s.DefaultModel() + "."
+
+	case fields[0] == "default" || fields[0] == "global":
+		if len(fields) != 2 {
+			return "Usage: /model default <name>"
+		}
+		if !owner {
+			return "Only the owner can change the default model."
+		}
+		model, err := s.SetDefaultModel(fields[1])
+		if err != nil {
+			return "⚠️ " + err.Error()
+		}
+		return "🤖 Default model is now " + model + "."
+
+	case len(fields) > 1:
+		return "Usage: /model <name>"
+	}
+
+	model, err := s.CheckModel(fields[0])
+	if err != nil {
+		return "⚠️ " + err.Error()
+	}
+	sm.SetModel(sessionKey, fields[0])
+	return "🤖 This chat now uses " + model + ". Send /model reset to go back to the default."
+}
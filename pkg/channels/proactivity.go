@@ -0,0 +1,73 @@
+package channels
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/logger"
+	"github.com/ntminh611/mclaw/pkg/proactivity"
+)
+
+// releaseInterval is how often held proactive messages are checked for
+// the end of the quiet hours.
+const releaseInterval = time.Minute
+
+// attachProactivity throttles proactive messages when enabled.
+func (m *Manager) attachProactivity() {
+	pc := m.config.Proactivity
+	if !pc.Enabled {
+		return
+	}
+	path := filepath.Join(filepath.Dir(m.config.WorkspacePath()), "proactivity.json")
+	policy, err := proactivity.New(path, proactivity.Options{
+		MaxPerDay:      pc.MaxPerDay,
+		QuietHours:     pc.QuietHours,
+		DropWhenQuiet:  pc.DropWhenQuiet,
+		UrgentPrefixes: pc.UrgentPrefixes,
+	})
+	if err != nil {
+		logger.ErrorCF("channels", "Proactivity policy disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	m.proactivity = policy
+}
+
+// throttle applies the proactivity policy to msg, reporting whether it was
+// held for after the quiet hours or dropped instead of being sent now.
+func (m *Manager) throttle(msg bus.OutboundMessage) bool {
+	if m.proactivity == nil {
+		return false
+	}
+	decision := m.proactivity.Check(msg, time.Now())
+	if decision == proactivity.Send {
+		return false
+	}
+	logger.InfoCF("channels", "Proactive message throttled", map[string]interface{}{
+		"decision": decision.String(),
+		"to":       msg.Channel + ":" + msg.ChatID,
+	})
+	return true
+}
+
+// releaseHeld delivers the proactive messages held during the quiet hours
+// once they are over.
+func (m *Manager) releaseHeld(ctx context.Context) {
+	ticker := time.NewTicker(releaseInterval)
+	defer ticker.Stop()
+	for {
+		for _, msg := range m.proactivity.Release(time.Now()) {
+			if !m.holdForApproval(ctx, msg) {
+				m.deliver(ctx, msg)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package channels
+
+import (
+	"sync"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// maxTrackedReplies is how many of the assistant's recent messages per
+// channel can be matched to a reaction.
+const maxTrackedReplies = 500
+
+// retryReactions rerun the answer they are put on, like /retry. Telegram's
+// reaction list has no arrows, so 🤔 works there too.
+var retryReactions = map[string]bool{"🔄": true, "🔁": true, "🤔": true}
+
+// replyTracker remembers what the assistant's recent messages said, so a
+// reaction to one can be passed on with the reply it is about.
+type replyTracker struct {
+	mu    sync.Mutex
+	texts map[string]string // "chatID:messageID" -> content
+	order []string
+	last  map[string]string // chatID -> content of the latest reply
+}
+
+// remember records a sent message, dropping the oldest past the limit.
+func (t *replyTracker) remember(chatID, messageID, content string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.texts == nil {
+		t.texts = make(map[string]string)
+		t.last = make(map[string]string)
+	}
+	t.last[chatID] = content
+	key := chatID + ":" + messageID
+	if _, ok := t.texts[key]; !ok {
+		t.order = append(t.order, key)
+	}
+	t.texts[key] = content
+	if len(t.order) > maxTrackedReplies {
+		delete(t.texts, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+// lookup returns the content of a tracked message and whether it is the
+// chat's latest reply.
+func (t *replyTracker) lookup(chatID, messageID string) (string, bool, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	content, ok := t.texts[chatID+":"+messageID]
+	return content, ok, ok && t.last[chatID] == content
+}
+
+// rememberReply records that messageID in chatID carries a reply, so
+// reactions to it count as feedback. Parts of one reply share its content.
+func (c *BaseChannel) rememberReply(chatID, messageID string, msg bus.OutboundMessage) {
+	if msg.Interim() {
+		return
+	}
+	c.replies.remember(chatID, messageID, msg.Content)
+}
+
+// HandleReaction passes an emoji reaction on one of the assistant's replies
+// to the agent as feedback; 🔄 on the latest reply retries it instead.
+// Reactions to other messages or from users who are not allowed are
+// ignored; it reports whether the reaction was passed on.
+func (c *BaseChannel) HandleReaction(senderID, chatID, messageID, emoji string) bool {
+	reply, ok, latest := c.replies.lookup(chatID, messageID)
+	if !ok || emoji == "" || !c.IsAllowed(senderID) {
+		return false
+	}
+	if latest && retryReactions[emoji] {
+		return c.HandleMessage(senderID, chatID, "/retry", nil, map[string]string{"retry": "true"})
+	}
+	c.bus.PublishReaction(bus.Reaction{
+		Channel:  c.name,
+		ChatID:   chatID,
+		SenderID: senderID,
+		Emoji:    emoji,
+		Reply:    reply,
+	})
+	return true
+}
@@ -2,13 +2,16 @@ package channels
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,7 +22,13 @@ import (
 	"github.com/ntminh611/mclaw/pkg/config"
 	"github.com/ntminh611/mclaw/pkg/cron"
 	"github.com/ntminh611/mclaw/pkg/heartbeat"
+	"github.com/ntminh611/mclaw/pkg/markdown"
+	"github.com/ntminh611/mclaw/pkg/monitors"
+	"github.com/ntminh611/mclaw/pkg/netguard"
+	"github.com/ntminh611/mclaw/pkg/reminders"
 	"github.com/ntminh611/mclaw/pkg/session"
+	"github.com/ntminh611/mclaw/pkg/storage"
+	"github.com/ntminh611/mclaw/pkg/tools"
 	"github.com/ntminh611/mclaw/pkg/voice"
 )
 
@@ -28,14 +37,35 @@ type TelegramChannel struct {
 	bot              *tgbotapi.BotAPI
 	config           config.TelegramConfig
 	chatIDs          map[string]int64
-	updates          tgbotapi.UpdatesChannel
-	transcriber      *voice.GroqTranscriber
+	stopUpdates      context.CancelFunc
+	transcriber      voice.Transcriber
+	synthesizer      voice.Synthesizer
+	ttsMaxChars      int
 	cronService      *cron.CronService
 	heartbeatService *heartbeat.HeartbeatService
 	sessionManager   *session.SessionManager
+	reminders        *reminders.Service
+	monitors         *monitors.Service
+	approver         Approver
+	models           ModelSelector
+	projects         []config.ProjectConfig
+	toolRegistry     *tools.ToolRegistry
+	toolPolicy       map[string]config.ToolPolicyConfig
+	guard            *netguard.Guard
 	modelName        string
 	placeholders     sync.Map // chatID -> messageID
 	stopThinking     sync.Map // chatID -> chan struct{}
+	sendLocks        sync.Map // chatID -> *sync.Mutex, keeps multi-part replies in order
+	voiceChats       sync.Map // chatID -> struct{}, next reply also goes out as a voice note
+	voiceMode        sync.Map // chatID -> time.Time, voice conversation mode ends then
+	polls            sync.Map // poll ID -> sentPoll, polls the agent created
+}
+
+// sentPoll remembers where a poll was sent, so votes can be passed on.
+type sentPoll struct {
+	chatID   string
+	question string
+	options  []string
 }
 
 func NewTelegramChannel(cfg config.TelegramConfig, bus *bus.MessageBus) (*TelegramChannel, error) {
@@ -52,15 +82,23 @@ func NewTelegramChannel(cfg config.TelegramConfig, bus *bus.MessageBus) (*Telegr
 		config:       cfg,
 		chatIDs:      make(map[string]int64),
 		transcriber:  nil,
+		guard:        netguard.New(nil),
 		placeholders: sync.Map{},
 		stopThinking: sync.Map{},
 	}, nil
 }
 
-func (c *TelegramChannel) SetTranscriber(transcriber *voice.GroqTranscriber) {
+func (c *TelegramChannel) SetTranscriber(transcriber voice.Transcriber) {
 	c.transcriber = transcriber
 }
 
+// SetSynthesizer enables voice-note replies to voice messages. Replies
+// longer than maxChars are sent as text only.
+func (c *TelegramChannel) SetSynthesizer(s voice.Synthesizer, maxChars int) {
+	c.synthesizer = s
+	c.ttsMaxChars = maxChars
+}
+
 func (c *TelegramChannel) SetCronService(cs *cron.CronService) {
 	c.cronService = cs
 }
@@ -73,6 +111,45 @@ func (c *TelegramChannel) SetSessionManager(sm *session.SessionManager) {
 	c.sessionManager = sm
 }
 
+// SetReminders enables /reminders.
+func (c *TelegramChannel) SetReminders(r *reminders.Service) {
+	c.reminders = r
+}
+
+// SetMonitors enables /monitors.
+func (c *TelegramChannel) SetMonitors(m *monitors.Service) {
+	c.monitors = m
+}
+
+// SetApprover enables /approve, /reject and the draft buttons.
+func (c *TelegramChannel) SetApprover(a Approver) {
+	c.approver = a
+}
+
+// SetModelSelector enables /model.
+func (c *TelegramChannel) SetModelSelector(s ModelSelector) {
+	c.models = s
+}
+
+// SetNetworkGuard sets the guard applied to file downloads.
+func (c *TelegramChannel) SetNetworkGuard(g *netguard.Guard) {
+	c.guard = g
+}
+
+func (c *TelegramChannel) SetProjects(projects []config.ProjectConfig) {
+	c.projects = projects
+}
+
+// SetToolRegistry lets /tools list the agent's tools.
+func (c *TelegramChannel) SetToolRegistry(r *tools.ToolRegistry) {
+	c.toolRegistry = r
+}
+
+// SetToolPolicy sets the per-channel tool restrictions shown by /tools.
+func (c *TelegramChannel) SetToolPolicy(policy map[string]config.ToolPolicyConfig) {
+	c.toolPolicy = policy
+}
+
 func (c *TelegramChannel) SetModelName(model string) {
 	c.modelName = model
 }
@@ -82,9 +159,12 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 30
+	// Reactions are only delivered when asked for by name
+	u.AllowedUpdates = []string{"message", "callback_query", "poll", "poll_answer", "message_reaction"}
 
-	updates := c.bot.GetUpdatesChan(u)
-	c.updates = updates
+	pollCtx, stop := context.WithCancel(ctx)
+	updates := c.pollUpdates(pollCtx, u)
+	c.stopUpdates = stop
 
 	c.setRunning(true)
 
@@ -99,9 +179,24 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 		tgbotapi.BotCommand{Command: "start", Description: "Start the bot"},
 		tgbotapi.BotCommand{Command: "help", Description: "Show available commands"},
 		tgbotapi.BotCommand{Command: "reset", Description: "Clear conversation history"},
+		tgbotapi.BotCommand{Command: "undo", Description: "Undo the last exchange"},
+		tgbotapi.BotCommand{Command: "retry", Description: "Answer the last message again"},
+		tgbotapi.BotCommand{Command: "model", Description: "List or switch models"},
+		tgbotapi.BotCommand{Command: "set", Description: "Change temperature or reply length for this chat"},
 		tgbotapi.BotCommand{Command: "status", Description: "Show bot status"},
+		tgbotapi.BotCommand{Command: "export", Description: "Export conversation transcript"},
+		tgbotapi.BotCommand{Command: "pin", Description: "Pin an instruction or list pins"},
+		tgbotapi.BotCommand{Command: "unpin", Description: "Remove a pinned instruction"},
+		tgbotapi.BotCommand{Command: "project", Description: "Switch project workspace"},
+		tgbotapi.BotCommand{Command: "topic", Description: "List or switch conversation topics"},
+		tgbotapi.BotCommand{Command: "tools", Description: "List or toggle tools for this chat"},
+		tgbotapi.BotCommand{Command: "voice", Description: "Start or stop a voice conversation"},
 		tgbotapi.BotCommand{Command: "cron", Description: "List cron jobs"},
+		tgbotapi.BotCommand{Command: "reminders", Description: "List, snooze or cancel reminders"},
+		tgbotapi.BotCommand{Command: "monitors", Description: "List or manage uptime monitors"},
 		tgbotapi.BotCommand{Command: "heartbeat", Description: "Show heartbeat status"},
+		tgbotapi.BotCommand{Command: "approve", Description: "List or send drafts held for approval"},
+		tgbotapi.BotCommand{Command: "reject", Description: "Discard a held draft"},
 	)
 	if _, err := c.bot.Request(commands); err != nil {
 		log.Printf("Failed to set bot commands: %v", err)
@@ -117,8 +212,17 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 					log.Printf("Updates channel closed, reconnecting...")
 					return
 				}
-				if update.Message != nil {
-					c.handleMessage(update)
+				switch {
+				case update.Message != nil:
+					c.handleMessage(update.Update)
+				case update.MessageReaction != nil:
+					c.handleReaction(update.MessageReaction)
+				case update.CallbackQuery != nil:
+					c.handleCallback(update.CallbackQuery)
+				case update.PollAnswer != nil:
+					c.handlePollAnswer(update.PollAnswer)
+				case update.Poll != nil && update.Poll.IsClosed:
+					c.polls.Delete(update.Poll.ID)
 				}
 			}
 		}
@@ -127,13 +231,85 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 	return nil
 }
 
+// telegramUpdate is an update with the fields the bot library predates.
+type telegramUpdate struct {
+	tgbotapi.Update
+	MessageReaction *messageReactionUpdated `json:"message_reaction"`
+}
+
+// messageReactionUpdated is a user changing their reactions on a message.
+type messageReactionUpdated struct {
+	Chat        tgbotapi.Chat  `json:"chat"`
+	MessageID   int            `json:"message_id"`
+	User        *tgbotapi.User `json:"user"` // nil for anonymous group admins
+	OldReaction []reactionType `json:"old_reaction"`
+	NewReaction []reactionType `json:"new_reaction"`
+}
+
+type reactionType struct {
+	Type  string `json:"type"` // "emoji", "custom_emoji" or "paid"
+	Emoji string `json:"emoji"`
+}
+
+// pollUpdates long-polls getUpdates like the bot library's GetUpdatesChan,
+// but also decodes reactions. The channel is closed when ctx ends.
+func (c *TelegramChannel) pollUpdates(ctx context.Context, config tgbotapi.UpdateConfig) <-chan telegramUpdate {
+	ch := make(chan telegramUpdate, 100)
+	go func() {
+		defer close(ch)
+		for ctx.Err() == nil {
+			resp, err := c.bot.Request(config)
+			var updates []telegramUpdate
+			if err == nil {
+				err = json.Unmarshal(resp.Result, &updates)
+			}
+			if err != nil {
+				log.Printf("[telegram] Failed to get updates, retrying in 3 seconds: %v", err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(3 * time.Second):
+				}
+				continue
+			}
+			for _, update := range updates {
+				if update.UpdateID < config.Offset {
+					continue
+				}
+				config.Offset = update.UpdateID + 1
+				select {
+				case ch <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch
+}
+
+// handleReaction passes emoji newly put on the bot's replies on as feedback.
+func (c *TelegramChannel) handleReaction(r *messageReactionUpdated) {
+	if r.User == nil {
+		return
+	}
+	old := make(map[string]bool, len(r.OldReaction))
+	for _, o := range r.OldReaction {
+		old[o.Emoji] = true
+	}
+	for _, n := range r.NewReaction {
+		if n.Type == "emoji" && !old[n.Emoji] {
+			c.HandleReaction(telegramSenderID(r.User), fmt.Sprintf("%d", r.Chat.ID), strconv.Itoa(r.MessageID), n.Emoji)
+		}
+	}
+}
+
 func (c *TelegramChannel) Stop(ctx context.Context) error {
 	log.Println("Stopping Telegram bot...")
 	c.setRunning(false)
 
-	if c.updates != nil {
-		c.bot.StopReceivingUpdates()
-		c.updates = nil
+	if c.stopUpdates != nil {
+		c.stopUpdates()
+		c.stopUpdates = nil
 	}
 
 	return nil
@@ -149,47 +325,222 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 		return fmt.Errorf("invalid chat ID: %w", err)
 	}
 
-	// Stop thinking animation
-	if stop, ok := c.stopThinking.Load(msg.ChatID); ok {
-		close(stop.(chan struct{}))
-		c.stopThinking.Delete(msg.ChatID)
+	// Thinking and progress updates arrive mid-turn: keep the typing
+	// indicator going and show reasoning folded away
+	if msg.Kind == bus.KindThinking {
+		return c.sendThinking(chatID, msg.Content)
+	}
+	if msg.Poll != nil {
+		return c.sendPoll(chatID, msg)
 	}
+	if !msg.Interim() {
+		if stop, ok := c.stopThinking.Load(msg.ChatID); ok {
+			close(stop.(chan struct{}))
+			c.stopThinking.Delete(msg.ChatID)
+		}
+	}
+
+	// Long replies arrive split into labeled parts (Telegram limit ~4096
+	// chars). Parts of one reply are sent back-to-back, never interleaved
+	// with other messages to the same chat.
+	msg = formatMessage(msg, profileFor(c.Name(), nil))
 
-	// Split long messages into chunks (Telegram limit ~4096 chars)
-	const maxLen = 4000
-	content := msg.Content
-	chunks := splitMessage(content, maxLen)
+	lock, _ := c.sendLocks.LoadOrStore(msg.ChatID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
 
-	for i, chunk := range chunks {
-		// Small delay between chunks to avoid rate limiting
+	for i, part := range msg.Parts {
+		// Small delay between parts to avoid rate limiting
 		if i > 0 {
 			time.Sleep(500 * time.Millisecond)
 		}
 
-		htmlContent := markdownToTelegramHTML(chunk)
-		tgMsg := tgbotapi.NewMessage(chatID, htmlContent)
-		tgMsg.ParseMode = tgbotapi.ModeHTML
+		// Buttons go under the last part
+		var markup interface{}
+		if i == len(msg.Parts)-1 && len(msg.Buttons) > 0 {
+			markup = inlineKeyboard(msg.Buttons)
+		}
+
+		tgMsg := tgbotapi.NewMessage(chatID, part)
+		tgMsg.ReplyMarkup = markup
+		if msg.Format == string(markdown.FormatTelegramHTML) {
+			tgMsg.ParseMode = tgbotapi.ModeHTML
+		}
 
-		if err := c.sendWithRetry(tgMsg); err != nil {
+		sent, err := c.sendMessage(tgMsg)
+		if err != nil && tgMsg.ParseMode != "" {
 			// Fallback to plain text
-			tgMsg = tgbotapi.NewMessage(chatID, chunk)
-			tgMsg.ParseMode = ""
-			if err := c.sendWithRetry(tgMsg); err != nil {
-				log.Printf("Failed to send chunk: %v", err)
-			}
+			tgMsg = tgbotapi.NewMessage(chatID, htmlToText(part))
+			tgMsg.ReplyMarkup = markup
+			sent, err = c.sendMessage(tgMsg)
+		}
+		if err != nil {
+			log.Printf("Failed to send part: %v", err)
+			continue
 		}
+		c.rememberReply(msg.ChatID, strconv.Itoa(sent.MessageID), msg)
+	}
+
+	if msg.Kind == bus.KindFinal {
+		if _, ok := c.voiceChats.LoadAndDelete(msg.ChatID); ok {
+			c.sendVoiceReply(ctx, chatID, msg.Content)
+		}
+	}
+
+	return nil
+}
+
+// sendThinking shows the model's reasoning in a collapsed quote the user
+// can expand.
+func (c *TelegramChannel) sendThinking(chatID int64, thinking string) error {
+	tgMsg := tgbotapi.NewMessage(chatID, "💭 <b>Thinking</b>\n<blockquote expandable>"+html.EscapeString(thinking)+"</blockquote>")
+	tgMsg.ParseMode = tgbotapi.ModeHTML
+	if err := c.sendWithRetry(tgMsg); err != nil {
+		tgMsg = tgbotapi.NewMessage(chatID, renderText(bus.OutboundMessage{Content: thinking, Kind: bus.KindThinking}))
+		return c.sendWithRetry(tgMsg)
 	}
+	return nil
+}
 
+// sendPoll sends a native poll. It is not anonymous, so Telegram reports
+// each vote, which handlePollAnswer passes on to the agent.
+func (c *TelegramChannel) sendPoll(chatID int64, msg bus.OutboundMessage) error {
+	poll := tgbotapi.NewPoll(chatID, msg.Poll.Question, msg.Poll.Options...)
+	poll.IsAnonymous = false
+	poll.AllowsMultipleAnswers = msg.Poll.Multiple
+	sent, err := c.bot.Send(poll)
+	if err != nil {
+		log.Printf("[telegram] Failed to send poll, sending it as text: %v", err)
+		return c.sendWithRetry(tgbotapi.NewMessage(chatID, msg.Content))
+	}
+	if sent.Poll != nil {
+		c.polls.Store(sent.Poll.ID, sentPoll{chatID: msg.ChatID, question: msg.Poll.Question, options: msg.Poll.Options})
+	}
 	return nil
 }
 
+// handlePollAnswer passes a vote on a poll the agent created to the agent
+// as a message from the voter.
+func (c *TelegramChannel) handlePollAnswer(answer *tgbotapi.PollAnswer) {
+	v, ok := c.polls.Load(answer.PollID)
+	if !ok {
+		return
+	}
+	poll := v.(sentPoll)
+
+	var chosen []string
+	for _, id := range answer.OptionIDs {
+		if id >= 0 && id < len(poll.options) {
+			chosen = append(chosen, fmt.Sprintf("%q", poll.options[id]))
+		}
+	}
+	content := fmt.Sprintf("[poll answer: retracted their vote in %q]", poll.question)
+	if len(chosen) > 0 {
+		content = fmt.Sprintf("[poll answer: voted %s in %q]", strings.Join(chosen, ", "), poll.question)
+	}
+
+	metadata := map[string]string{
+		"user_id":    fmt.Sprintf("%d", answer.User.ID),
+		"username":   answer.User.UserName,
+		"first_name": answer.User.FirstName,
+		"poll_id":    answer.PollID,
+	}
+	c.HandleMessage(telegramSenderID(&answer.User), poll.chatID, content, nil, metadata)
+}
+
+// inlineKeyboard lays out buttons in one row.
+func inlineKeyboard(buttons []bus.Button) tgbotapi.InlineKeyboardMarkup {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(buttons))
+	for _, b := range buttons {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(b.Label, b.Data))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// handleCallback acts on a pressed button. The only buttons are the
+// owner's approve/discard buttons under drafts.
+func (c *TelegramChannel) handleCallback(q *tgbotapi.CallbackQuery) {
+	action, id, _ := strings.Cut(q.Data, ":")
+	var text string
+	decided := false
+	switch {
+	case action != "approve" && action != "reject":
+		text = "Unknown button."
+	case c.approver == nil:
+		text = "Approvals are not enabled."
+	case !c.isOwner(telegramSenderID(q.From)):
+		text = "Only the owner can decide drafts."
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		result, err := c.approver.Decide(ctx, id, action == "approve")
+		cancel()
+		decided = true
+		if err != nil {
+			text = "⚠️ " + err.Error()
+		} else {
+			text = result
+		}
+	}
+
+	if _, err := c.bot.Request(tgbotapi.NewCallback(q.ID, text)); err != nil {
+		log.Printf("[telegram] Failed to answer button: %v", err)
+	}
+	if q.Message == nil || !decided {
+		return
+	}
+	// The draft is decided (or gone): replace its buttons with the outcome
+	edit := tgbotapi.NewEditMessageReplyMarkup(q.Message.Chat.ID, q.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+	if _, err := c.bot.Request(edit); err != nil {
+		log.Printf("[telegram] Failed to remove draft buttons: %v", err)
+	}
+	c.sendWithRetry(tgbotapi.NewMessage(q.Message.Chat.ID, text))
+}
+
+// sendVoiceReply speaks a reply to a voice message back as a voice note.
+// The text reply has already been sent, so failures are only logged.
+func (c *TelegramChannel) sendVoiceReply(ctx context.Context, chatID int64, content string) {
+	text := voice.SpeakableText(content)
+	if text == "" || (c.ttsMaxChars > 0 && len(text) > c.ttsMaxChars) {
+		return
+	}
+
+	c.bot.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatRecordVoice))
+
+	ctx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	defer cancel()
+	path, err := c.synthesizer.Synthesize(ctx, text)
+	if err != nil {
+		log.Printf("[telegram] Voice reply via %s failed: %v", c.synthesizer.Name(), err)
+		return
+	}
+	defer os.Remove(path)
+
+	// Telegram only plays OGG/Opus as a voice note; other formats go as audio
+	var audio tgbotapi.Chattable
+	if strings.HasSuffix(path, ".ogg") {
+		audio = tgbotapi.NewVoice(chatID, tgbotapi.FilePath(path))
+	} else {
+		audio = tgbotapi.NewAudio(chatID, tgbotapi.FilePath(path))
+	}
+	if err := c.sendWithRetry(audio); err != nil {
+		log.Printf("[telegram] Failed to send voice reply: %v", err)
+	}
+}
+
 // sendWithRetry sends a Telegram message with retry on rate limit (429)
 func (c *TelegramChannel) sendWithRetry(msg tgbotapi.Chattable) error {
+	_, err := c.sendMessage(msg)
+	return err
+}
+
+// sendMessage is sendWithRetry returning the sent message.
+func (c *TelegramChannel) sendMessage(msg tgbotapi.Chattable) (tgbotapi.Message, error) {
 	maxRetries := 2
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		_, err := c.bot.Send(msg)
+		sent, err := c.bot.Send(msg)
 		if err == nil {
-			return nil
+			return sent, nil
 		}
 
 		errStr := err.Error()
@@ -207,9 +558,9 @@ func (c *TelegramChannel) sendWithRetry(msg tgbotapi.Chattable) error {
 			continue
 		}
 
-		return err
+		return sent, err
 	}
-	return fmt.Errorf("failed after %d retries due to rate limiting", maxRetries)
+	return tgbotapi.Message{}, fmt.Errorf("failed after %d retries due to rate limiting", maxRetries)
 }
 
 // splitMessage splits text into chunks of maxLen, preferring to split at newlines
@@ -249,10 +600,7 @@ func (c *TelegramChannel) handleMessage(update tgbotapi.Update) {
 		return
 	}
 
-	senderID := fmt.Sprintf("%d", user.ID)
-	if user.UserName != "" {
-		senderID = fmt.Sprintf("%d|%s", user.ID, user.UserName)
-	}
+	senderID := telegramSenderID(user)
 
 	chatID := message.Chat.ID
 	c.chatIDs[senderID] = chatID
@@ -281,6 +629,7 @@ func (c *TelegramChannel) handleMessage(update tgbotapi.Update) {
 		photo := message.Photo[len(message.Photo)-1]
 		photoPath := c.downloadPhoto(photo.FileID)
 		if photoPath != "" {
+			photoPath = c.ingest(photoPath, time.Now().Format("photo_20060102_150405.jpg"), fmt.Sprintf("%d", chatID), senderID)
 			mediaPaths = append(mediaPaths, photoPath)
 			if content != "" {
 				content += "\n"
@@ -289,26 +638,27 @@ func (c *TelegramChannel) handleMessage(update tgbotapi.Update) {
 		}
 	}
 
-	if message.Voice != nil {
-		voicePath := c.downloadFile(message.Voice.FileID, ".ogg")
+	spoken := false
+	if message.Voice != nil || message.VideoNote != nil {
+		kind, fileID, ext, duration := "voice", "", ".ogg", 0
+		if message.Voice != nil {
+			fileID, duration = message.Voice.FileID, message.Voice.Duration
+		} else {
+			kind, fileID, ext, duration = "video note", message.VideoNote.FileID, ".mp4", message.VideoNote.Duration
+		}
+		voicePath := c.downloadFile(fileID, ext)
 		if voicePath != "" {
 			mediaPaths = append(mediaPaths, voicePath)
+			spoken = c.extendVoiceMode(fmt.Sprintf("%d", chatID))
+			if c.synthesizer != nil && (c.config.VoiceReplies || spoken) {
+				c.voiceChats.Store(fmt.Sprintf("%d", chatID), struct{}{})
+			}
 
 			transcribedText := ""
-			if c.transcriber != nil && c.transcriber.IsAvailable() {
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				defer cancel()
-
-				result, err := c.transcriber.Transcribe(ctx, voicePath)
-				if err != nil {
-					log.Printf("Voice transcription failed: %v", err)
-					transcribedText = fmt.Sprintf("[voice: %s (transcription failed)]", voicePath)
-				} else {
-					transcribedText = fmt.Sprintf("[voice transcription: %s]", result.Text)
-					log.Printf("Voice transcribed successfully: %s", result.Text)
-				}
+			if c.canTranscribe() {
+				transcribedText = c.transcribe(message, voicePath, kind, duration, spoken)
 			} else {
-				transcribedText = fmt.Sprintf("[voice: %s]", voicePath)
+				transcribedText = fmt.Sprintf("[%s: %s]", kind, voicePath)
 			}
 
 			if content != "" {
@@ -321,17 +671,52 @@ func (c *TelegramChannel) handleMessage(update tgbotapi.Update) {
 	if message.Audio != nil {
 		audioPath := c.downloadFile(message.Audio.FileID, ".mp3")
 		if audioPath != "" {
+			audioPath = c.ingest(audioPath, message.Audio.FileName, fmt.Sprintf("%d", chatID), senderID)
 			mediaPaths = append(mediaPaths, audioPath)
 			if content != "" {
 				content += "\n"
 			}
 			content += fmt.Sprintf("[audio: %s]", audioPath)
+			if c.canTranscribe() {
+				content += "\n" + c.transcribe(message, audioPath, "audio", message.Audio.Duration, false)
+			}
+		}
+	}
+
+	var location *tgbotapi.Location
+	switch {
+	case message.Venue != nil:
+		location = &message.Venue.Location
+		if content != "" {
+			content += "\n"
+		}
+		content += fmt.Sprintf("[venue: %s, %s (%.6f, %.6f)]", message.Venue.Title, message.Venue.Address, location.Latitude, location.Longitude)
+	case message.Location != nil:
+		location = message.Location
+		if content != "" {
+			content += "\n"
+		}
+		content += fmt.Sprintf("[location: %.6f, %.6f]", location.Latitude, location.Longitude)
+	}
+
+	if message.Contact != nil {
+		if content != "" {
+			content += "\n"
 		}
+		content += contactText(message.Contact)
+	}
+
+	if message.Poll != nil {
+		if content != "" {
+			content += "\n"
+		}
+		content += pollText(message.Poll)
 	}
 
 	if message.Document != nil {
 		docPath := c.downloadFile(message.Document.FileID, "")
 		if docPath != "" {
+			docPath = c.ingest(docPath, message.Document.FileName, fmt.Sprintf("%d", chatID), senderID)
 			mediaPaths = append(mediaPaths, docPath)
 			if content != "" {
 				content += "\n"
@@ -373,8 +758,177 @@ func (c *TelegramChannel) handleMessage(update tgbotapi.Update) {
 		"first_name": user.FirstName,
 		"is_group":   fmt.Sprintf("%t", message.Chat.Type != "private"),
 	}
+	if message.ForwardDate != 0 {
+		metadata["forwarded"] = "true"
+	}
+	if spoken {
+		metadata["voice_mode"] = "true"
+	}
+	if location != nil {
+		metadata["latitude"] = fmt.Sprintf("%.6f", location.Latitude)
+		metadata["longitude"] = fmt.Sprintf("%.6f", location.Longitude)
+		if location.HorizontalAccuracy > 0 {
+			metadata["accuracy_m"] = fmt.Sprintf("%.0f", location.HorizontalAccuracy)
+		}
+		if message.Venue != nil {
+			metadata["venue"] = message.Venue.Title
+			metadata["address"] = message.Venue.Address
+		}
+	}
+	if user.IsBot {
+		metadata["is_bot"] = "true"
+	}
+
+	if !c.HandleMessage(senderID, fmt.Sprintf("%d", chatID), content, mediaPaths, metadata) {
+		// Dropped (e.g. bot loop guard): no reply will come to stop the typing indicator
+		if stop, ok := c.stopThinking.LoadAndDelete(fmt.Sprintf("%d", chatID)); ok {
+			close(stop.(chan struct{}))
+		}
+	}
+}
+
+// Recordings at least this long get a "transcribing…" placeholder that is
+// edited with the transcription, so the user knows they were heard.
+const voiceFeedbackSeconds = 30
+
+func (c *TelegramChannel) canTranscribe() bool {
+	return c.transcriber != nil && c.transcriber.IsAvailable()
+}
+
+// transcribe transcribes a voice note, video note or audio file of the
+// given length in seconds and returns its message content. Long
+// recordings are transcribed in pieces. quiet skips the placeholder, as
+// in voice conversations where the spoken reply is the feedback.
+func (c *TelegramChannel) transcribe(message *tgbotapi.Message, path, kind string, seconds int, quiet bool) string {
+	chatID := message.Chat.ID
+
+	var placeholderID int
+	if seconds >= voiceFeedbackSeconds && !quiet {
+		reply := tgbotapi.NewMessage(chatID, "🎙 Transcribing…")
+		reply.ReplyToMessageID = message.MessageID
+		if sent, err := c.bot.Send(reply); err == nil {
+			placeholderID = sent.MessageID
+		}
+	}
+
+	// Each piece of a long recording gets its own share of time
+	timeout := 30 * time.Second
+	if pieces := seconds / int(voice.ChunkLength.Seconds()); pieces > 0 {
+		timeout = time.Duration(pieces+1) * 2 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := voice.TranscribeLong(ctx, c.transcriber, path, time.Duration(seconds)*time.Second)
+
+	if placeholderID != 0 {
+		feedback := "🎙 Transcription failed, I'll work with the audio file."
+		if err == nil {
+			feedback = "🎙 <i>" + html.EscapeString(strings.ToValidUTF8(truncateString(result.Text, 3500), "")) + "</i>"
+		}
+		edit := tgbotapi.NewEditMessageText(chatID, placeholderID, feedback)
+		edit.ParseMode = tgbotapi.ModeHTML
+		if _, err := c.bot.Send(edit); err != nil {
+			log.Printf("Failed to update transcription placeholder: %v", err)
+		}
+	}
+
+	if err != nil {
+		log.Printf("Voice transcription failed: %v", err)
+		return fmt.Sprintf("[%s: %s (transcription failed)]", kind, path)
+	}
+	log.Printf("Voice transcribed successfully: %s", truncateString(result.Text, 200))
+	return fmt.Sprintf("[%s transcription: %s]", kind, result.Text)
+}
+
+// voiceModeWindow is how long a voice conversation lasts after the last
+// voice note.
+func (c *TelegramChannel) voiceModeWindow() time.Duration {
+	if c.config.VoiceMode > 0 {
+		return time.Duration(c.config.VoiceMode) * time.Minute
+	}
+	return 10 * time.Minute
+}
+
+// extendVoiceMode reports whether the chat is in a voice conversation and,
+// if so, keeps it going for another window.
+func (c *TelegramChannel) extendVoiceMode(chatKey string) bool {
+	until, ok := c.voiceMode.Load(chatKey)
+	if !ok {
+		return false
+	}
+	if time.Now().After(until.(time.Time)) {
+		c.voiceMode.Delete(chatKey)
+		return false
+	}
+	c.voiceMode.Store(chatKey, time.Now().Add(c.voiceModeWindow()))
+	return true
+}
+
+// voiceCommand starts, stops or shows the chat's voice conversation mode.
+// In voice mode voice notes are transcribed without feedback messages,
+// the model keeps its answers short and speakable, and every answer is
+// also sent as a voice note.
+func (c *TelegramChannel) voiceCommand(chatKey, arg string) string {
+	window := c.voiceModeWindow()
+	switch strings.ToLower(arg) {
+	case "", "status":
+		if until, ok := c.voiceMode.Load(chatKey); ok && time.Now().Before(until.(time.Time)) {
+			return fmt.Sprintf("🎧 Voice conversation on until %s. Send /voice off to stop.", until.(time.Time).Format("15:04"))
+		}
+		return "🎧 Voice conversation off. Send /voice on, then talk to me with voice notes."
+	case "on", "start":
+		if !c.canTranscribe() {
+			return "⚠️ Voice transcription is not configured."
+		}
+		c.voiceMode.Store(chatKey, time.Now().Add(window))
+		text := fmt.Sprintf("🎧 Voice conversation on. Send voice notes; it ends after %s of silence or with /voice off.", window.Round(time.Minute))
+		if c.synthesizer == nil {
+			text += "\nText-to-speech is not configured, so I'll answer in text."
+		}
+		return text
+	case "off", "stop":
+		c.voiceMode.Delete(chatKey)
+		return "🎧 Voice conversation off."
+	}
+	return "Usage: /voice [on|off]"
+}
+
+// contactText describes a shared contact card.
+func contactText(contact *tgbotapi.Contact) string {
+	name := strings.TrimSpace(contact.FirstName + " " + contact.LastName)
+	text := fmt.Sprintf("[contact: %s, phone %s", name, contact.PhoneNumber)
+	if contact.UserID != 0 {
+		text += fmt.Sprintf(", Telegram user %d", contact.UserID)
+	}
+	return text + "]"
+}
+
+// pollText describes a poll shared in the chat with its current results.
+func pollText(poll *tgbotapi.Poll) string {
+	var sb strings.Builder
+	kind := "poll"
+	if poll.Type == "quiz" {
+		kind = "quiz"
+	}
+	if poll.IsClosed {
+		kind = "closed " + kind
+	}
+	fmt.Fprintf(&sb, "[%s: %s", kind, poll.Question)
+	for _, o := range poll.Options {
+		fmt.Fprintf(&sb, "\n- %s: %d votes", o.Text, o.VoterCount)
+	}
+	fmt.Fprintf(&sb, "\n%d voters]", poll.TotalVoterCount)
+	return sb.String()
+}
 
-	c.HandleMessage(senderID, fmt.Sprintf("%d", chatID), content, mediaPaths, metadata)
+// telegramSenderID is the sender ID used for allow checks: the numeric ID,
+// with the username appended when the user has one.
+func telegramSenderID(user *tgbotapi.User) string {
+	if user.UserName != "" {
+		return fmt.Sprintf("%d|%s", user.ID, user.UserName)
+	}
+	return fmt.Sprintf("%d", user.ID)
 }
 
 func (c *TelegramChannel) handleCommand(message *tgbotapi.Message) {
@@ -385,6 +939,11 @@ func (c *TelegramChannel) handleCommand(message *tgbotapi.Message) {
 
 	switch cmd {
 	case "start":
+		if !c.IsAllowed(telegramSenderID(message.From)) {
+			text = "🔒 <b>MClaw AI Assistant</b>\n\n" +
+				fmt.Sprintf("You don't have access yet. Your user ID is <code>%d</code>; ask the owner to send <code>/authorize %d</code>.", message.From.ID, message.From.ID)
+			break
+		}
 		model := c.modelName
 		if model == "" {
 			model = "unknown"
@@ -399,21 +958,73 @@ func (c *TelegramChannel) handleCommand(message *tgbotapi.Message) {
 			"/start — Start the bot\n" +
 			"/help — Show this help\n" +
 			"/reset — Clear conversation history\n" +
+			"/undo — Undo the last exchange\n" +
+			"/retry [model] — Answer the last message again, e.g. /retry pro\n" +
+			"/model [name|reset] — List models or switch this chat's model\n" +
+			"/model default &lt;name&gt; — Change the default model (owner only)\n" +
+			"/set [option value|reset] — Change generation options for this chat, e.g. /set temperature 0.2\n" +
 			"/status — Show bot status\n" +
+			"/export [md|json] — Export conversation transcript\n" +
+			"/pin [text] — Pin an instruction (no text: list pins)\n" +
+			"/unpin &lt;id&gt; — Remove a pinned instruction\n" +
+			"/project [name|off] — Switch project workspace\n" +
+			"/topic [name|main] — List or switch conversation topics\n" +
+			"/tools [on|off &lt;name&gt;] — List or toggle tools for this chat\n" +
+			"/voice [on|off] — Start or stop a voice conversation\n" +
 			"/cron — List scheduled jobs\n" +
-			"/heartbeat — Heartbeat status\n\n" +
+			"/reminders [snooze|cancel &lt;id&gt;] — List, snooze or cancel reminders\n" +
+			"/monitors [add &lt;url&gt; [interval]|check|pause|resume|remove &lt;id&gt;] — Uptime monitors\n" +
+			"/heartbeat — Heartbeat status\n" +
+			"/approve [id] — List drafts held for approval, or send one (owner only)\n" +
+			"/reject &lt;id&gt; — Discard a held draft (owner only)\n" +
+			"/authorize [id [role]] — Give a user access (owner only)\n\n" +
 			"Or just send me any message to chat!"
 
 	case "reset":
 		senderID := fmt.Sprintf("%d", message.From.ID)
 		sessionKey := fmt.Sprintf("telegram:%s", senderID)
 		if c.sessionManager != nil {
-			c.sessionManager.ClearHistory(sessionKey)
+			c.sessionManager.ClearHistory(c.sessionManager.ActiveKey(sessionKey))
 			text = "🗑 <b>Session cleared!</b>\n\nConversation history has been reset. Let's start fresh!"
 		} else {
 			text = "⚠️ Session manager not available."
 		}
 
+	case "undo":
+		if c.sessionManager == nil {
+			text = "⚠️ Session manager not available."
+			break
+		}
+		label, ok := c.sessionManager.Undo(c.activeSession(chatID))
+		if !ok {
+			text = "Nothing to undo."
+			break
+		}
+		text = "↩️ <b>Undone.</b> Rewound to before: <i>" + html.EscapeString(label) + "</i>"
+
+	case "retry":
+		// The agent rolls the session back and runs the last message again
+		metadata := map[string]string{"retry": "true", "message_id": fmt.Sprintf("%d", message.MessageID)}
+		if model := strings.TrimSpace(message.CommandArguments()); model != "" {
+			metadata["model"] = model
+		}
+		if c.HandleMessage(telegramSenderID(message.From), fmt.Sprintf("%d", chatID), message.Text, nil, metadata) {
+			c.bot.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+			return
+		}
+		text = "⚠️ Could not retry."
+
+	case "model":
+		text = html.EscapeString(modelCommand(c.models, c.sessionManager, c.activeSession(chatID),
+			c.isOwner(telegramSenderID(message.From)), message.CommandArguments()))
+
+	case "set":
+		if c.sessionManager == nil {
+			text = "⚠️ Session manager not available."
+			break
+		}
+		text = c.setCommand(c.activeSession(chatID), strings.TrimSpace(message.CommandArguments()))
+
 	case "status":
 		model := c.modelName
 		if model == "" {
@@ -424,6 +1035,11 @@ func (c *TelegramChannel) handleCommand(message *tgbotapi.Message) {
 			fmt.Sprintf("🤖 Model: <code>%s</code>", model),
 			fmt.Sprintf("📡 Channel: Telegram (running: %t)", c.IsRunning()),
 		}
+		if c.sessionManager != nil {
+			if m := c.sessionManager.GetModel(c.activeSession(chatID)); m != "" {
+				lines[1] += fmt.Sprintf(" (this chat: <code>%s</code>)", html.EscapeString(m))
+			}
+		}
 
 		if c.cronService != nil {
 			status := c.cronService.Status()
@@ -435,7 +1051,7 @@ func (c *TelegramChannel) handleCommand(message *tgbotapi.Message) {
 		}
 
 		if c.transcriber != nil && c.transcriber.IsAvailable() {
-			lines = append(lines, "🎤 Voice: enabled (Groq)")
+			lines = append(lines, fmt.Sprintf("🎤 Voice: enabled (%s)", c.transcriber.Name()))
 		} else {
 			lines = append(lines, "🎤 Voice: disabled")
 		}
@@ -468,6 +1084,125 @@ func (c *TelegramChannel) handleCommand(message *tgbotapi.Message) {
 		}
 		text = strings.Join(lines, "\n")
 
+	case "reminders":
+		if c.reminders == nil {
+			text = "⚠️ Reminders not available."
+			break
+		}
+		text = c.remindersCommand(fmt.Sprintf("telegram:%d", chatID), strings.TrimSpace(message.CommandArguments()))
+
+	case "monitors":
+		if c.monitors == nil {
+			text = "⚠️ Monitors not available."
+			break
+		}
+		text = c.monitorsCommand(fmt.Sprintf("telegram:%d", chatID), strings.TrimSpace(message.CommandArguments()))
+
+	case "export":
+		if c.sessionManager == nil {
+			text = "⚠️ Session manager not available."
+			break
+		}
+		format := strings.TrimSpace(message.CommandArguments())
+		if format == "" {
+			format = session.FormatMarkdown
+		}
+		sess, ok := c.sessionManager.Get(c.activeSession(chatID))
+		if !ok {
+			text = "📭 No conversation to export yet."
+			break
+		}
+		data, err := session.Export(sess, format)
+		if err != nil {
+			text = fmt.Sprintf("⚠️ %s", html.EscapeString(err.Error()))
+			break
+		}
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+			Name:  fmt.Sprintf("transcript-%s.%s", time.Now().Format("20060102-1504"), format),
+			Bytes: data,
+		})
+		if _, err := c.bot.Send(doc); err != nil {
+			log.Printf("Failed to send transcript: %v", err)
+			text = "⚠️ Failed to send transcript."
+			break
+		}
+		return
+
+	case "pin":
+		if c.sessionManager == nil {
+			text = "⚠️ Session manager not available."
+			break
+		}
+		sessionKey := c.activeSession(chatID)
+		content := strings.TrimSpace(message.CommandArguments())
+		if content != "" {
+			pin := c.sessionManager.AddPin(sessionKey, content)
+			text = fmt.Sprintf("📌 Pinned #%d: %s", pin.ID, html.EscapeString(pin.Content))
+			break
+		}
+		pins := c.sessionManager.GetPins(sessionKey)
+		if len(pins) == 0 {
+			text = "📌 No pinned instructions.\n\nUsage: /pin always answer in Vietnamese"
+			break
+		}
+		lines := []string{fmt.Sprintf("📌 <b>Pinned instructions</b> (%d)\n", len(pins))}
+		for _, p := range pins {
+			lines = append(lines, fmt.Sprintf("#%d %s", p.ID, html.EscapeString(p.Content)))
+		}
+		text = strings.Join(lines, "\n")
+
+	case "unpin":
+		if c.sessionManager == nil {
+			text = "⚠️ Session manager not available."
+			break
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(message.CommandArguments()), "#"))
+		if err != nil {
+			text = "Usage: /unpin &lt;id&gt; (see /pin for IDs)"
+			break
+		}
+		if c.sessionManager.RemovePin(c.activeSession(chatID), id) {
+			text = fmt.Sprintf("✓ Unpinned #%d", id)
+		} else {
+			text = fmt.Sprintf("Pin #%d not found.", id)
+		}
+
+	case "project":
+		if c.sessionManager == nil {
+			text = "⚠️ Session manager not available."
+			break
+		}
+		text = c.projectCommand(c.activeSession(chatID), strings.TrimSpace(message.CommandArguments()))
+
+	case "topic":
+		if c.sessionManager == nil {
+			text = "⚠️ Session manager not available."
+			break
+		}
+		text = c.topicCommand(fmt.Sprintf("telegram:%d", chatID), strings.TrimSpace(message.CommandArguments()))
+
+	case "tools":
+		if c.sessionManager == nil || c.toolRegistry == nil {
+			text = "⚠️ Tool settings not available."
+			break
+		}
+		text = c.toolsCommand(c.activeSession(chatID), message.Chat.Type != "private", strings.TrimSpace(message.CommandArguments()))
+
+	case "voice":
+		text = c.voiceCommand(fmt.Sprintf("%d", chatID), strings.TrimSpace(message.CommandArguments()))
+
+	case "authorize":
+		text = html.EscapeString(authorizeCommand(c.authorizer(), "telegram", telegramSenderID(message.From), message.CommandArguments()))
+
+	case "approve", "reject":
+		if !c.isOwner(telegramSenderID(message.From)) {
+			text = "Only the owner can decide drafts."
+			break
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		text = html.EscapeString(approvalCommand(ctx, c.approver, cmd == "approve", message.CommandArguments()))
+		cancel()
+
 	case "heartbeat":
 		if c.heartbeatService == nil {
 			text = "⚠️ Heartbeat service not available."
@@ -492,6 +1227,303 @@ func (c *TelegramChannel) handleCommand(message *tgbotapi.Message) {
 	}
 }
 
+// projectCommand lists projects or switches the session's active project.
+func (c *TelegramChannel) projectCommand(sessionKey, arg string) string {
+	current := c.sessionManager.GetProject(sessionKey)
+
+	switch arg {
+	case "":
+		if len(c.projects) == 0 {
+			return "📂 No projects configured. Add them under <code>projects</code> in config.json."
+		}
+		lines := []string{"📂 <b>Projects</b>\n"}
+		for _, p := range c.projects {
+			marker := "▫️"
+			if p.Name == current {
+				marker = "▶️"
+			}
+			line := fmt.Sprintf("%s <b>%s</b> — <code>%s</code>", marker, html.EscapeString(p.Name), html.EscapeString(p.Path))
+			if p.Description != "" {
+				line += "\n   " + html.EscapeString(p.Description)
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "\nUsage: /project &lt;name&gt; or /project off")
+		return strings.Join(lines, "\n")
+
+	case "off", "none":
+		c.sessionManager.SetProject(sessionKey, "")
+		return "📂 Project cleared. Back to the default workspace."
+	}
+
+	for _, p := range c.projects {
+		if p.Name == arg {
+			c.sessionManager.SetProject(sessionKey, p.Name)
+			return fmt.Sprintf("📂 Switched to <b>%s</b> (<code>%s</code>)", html.EscapeString(p.Name), html.EscapeString(p.Path))
+		}
+	}
+	return fmt.Sprintf("Unknown project: %s. Send /project to list projects.", html.EscapeString(arg))
+}
+
+// topicCommand lists the chat's topics or switches to one, creating it if
+// needed. Session commands such as /pin and /undo act on the active topic.
+func (c *TelegramChannel) topicCommand(chatKey, arg string) string {
+	if arg == "" {
+		active := c.sessionManager.ActiveTopic(chatKey)
+		marker := func(on bool) string {
+			if on {
+				return "▶️"
+			}
+			return "▫️"
+		}
+		lines := []string{"🧵 <b>Topics</b>\n", marker(active == "") + " main"}
+		for _, t := range c.sessionManager.Topics(chatKey) {
+			lines = append(lines, fmt.Sprintf("%s %s — %d messages", marker(t.Active), html.EscapeString(t.Name), t.Messages))
+		}
+		lines = append(lines, "\nUsage: /topic &lt;name&gt; to switch (new names start a topic), /topic main to go back")
+		return strings.Join(lines, "\n")
+	}
+
+	name, err := session.NormalizeTopic(arg)
+	if err != nil {
+		return "⚠️ " + html.EscapeString(err.Error())
+	}
+	created := c.sessionManager.SwitchTopic(chatKey, name)
+	switch {
+	case name == "":
+		return "🧵 Back to the main conversation."
+	case created:
+		return fmt.Sprintf("🧵 Started topic <b>%s</b>.", html.EscapeString(name))
+	}
+	return fmt.Sprintf("🧵 Switched to topic <b>%s</b>.", html.EscapeString(name))
+}
+
+// setCommand lists the session's generation options or changes one.
+// "/set <option> default" drops one override, "/set reset" all of them.
+func (c *TelegramChannel) setCommand(sessionKey, arg string) string {
+	fields := strings.Fields(arg)
+	switch {
+	case len(fields) == 0:
+		overrides := c.sessionManager.GetOptions(sessionKey)
+		lines := []string{"⚙️ <b>Generation options</b>\n"}
+		for _, o := range session.Options {
+			value := "default"
+			if v, ok := overrides[o.Name]; ok {
+				value = "<b>" + session.FormatOption(v) + "</b>"
+			}
+			lines = append(lines, fmt.Sprintf("• <code>%s</code> = %s — %s (%g–%g)", o.Name, value, o.Help, o.Min, o.Max))
+		}
+		lines = append(lines, "\nUsage: /set &lt;option&gt; &lt;value&gt;, /set &lt;option&gt; default, or /set reset")
+		return strings.Join(lines, "\n")
+
+	case len(fields) == 1 && fields[0] == "reset":
+		if len(c.sessionManager.ResetOptions(sessionKey)) == 0 {
+			return "This chat already uses the default options."
+		}
+		return "⚙️ All options are back to their defaults."
+
+	case len(fields) != 2:
+		return "Usage: /set &lt;option&gt; &lt;value&gt;, e.g. /set temperature 0.2"
+	}
+
+	name, raw := strings.ToLower(fields[0]), fields[1]
+	if _, ok := session.LookupOption(name); !ok {
+		return fmt.Sprintf("Unknown option: %s. Send /set to list options.", html.EscapeString(name))
+	}
+	if raw == "default" || raw == "reset" {
+		c.sessionManager.ResetOptions(sessionKey, name)
+		return fmt.Sprintf("⚙️ <code>%s</code> is back to its default.", name)
+	}
+	v, err := session.ParseOption(name, raw)
+	if err != nil {
+		return "⚠️ " + html.EscapeString(err.Error())
+	}
+	c.sessionManager.SetOption(sessionKey, name, v)
+	return fmt.Sprintf("⚙️ <code>%s</code> set to %s for this chat.", name, session.FormatOption(v))
+}
+
+// activeSession returns the session key of the chat's active topic.
+func (c *TelegramChannel) activeSession(chatID int64) string {
+	key := fmt.Sprintf("telegram:%d", chatID)
+	if c.sessionManager == nil {
+		return key
+	}
+	return c.sessionManager.ActiveKey(key)
+}
+
+// toolsCommand lists the chat's tools or turns one on or off for the session.
+// Tools blocked by config policy cannot be turned on here.
+func (c *TelegramChannel) toolsCommand(sessionKey string, isGroup bool, arg string) string {
+	blocked := tools.PolicyDenied(c.toolPolicy, "telegram", isGroup)
+	off := make(map[string]bool)
+	for _, name := range c.sessionManager.GetDisabledTools(sessionKey) {
+		off[name] = true
+	}
+
+	fields := strings.Fields(arg)
+	if len(fields) == 0 {
+		lines := []string{"🧰 <b>Tools</b>\n"}
+		for _, name := range c.toolRegistry.Names() {
+			marker := "✅"
+			switch {
+			case blocked[name] != "":
+				marker = "🔒"
+			case off[name]:
+				marker = "🚫"
+			case !c.toolRegistry.IsAvailable(name):
+				marker = "⚠️"
+			}
+			lines = append(lines, fmt.Sprintf("%s <code>%s</code>", marker, html.EscapeString(name)))
+		}
+		lines = append(lines, "\n✅ on · 🚫 off in this chat · 🔒 blocked by config · ⚠️ unavailable",
+			"Usage: /tools off &lt;name&gt; or /tools on &lt;name&gt;")
+		return strings.Join(lines, "\n")
+	}
+
+	if len(fields) != 2 || (fields[0] != "on" && fields[0] != "off") {
+		return "Usage: /tools, /tools off &lt;name&gt; or /tools on &lt;name&gt;"
+	}
+	name := fields[1]
+	if _, ok := c.toolRegistry.Get(name); !ok {
+		return fmt.Sprintf("Unknown tool: %s. Send /tools to list tools.", html.EscapeString(name))
+	}
+
+	if fields[0] == "off" {
+		c.sessionManager.SetToolDisabled(sessionKey, name, true)
+		return fmt.Sprintf("🚫 <code>%s</code> turned off for this chat.", html.EscapeString(name))
+	}
+	if reason := blocked[name]; reason != "" {
+		return fmt.Sprintf("🔒 <code>%s</code> is %s.", html.EscapeString(name), html.EscapeString(reason))
+	}
+	c.sessionManager.SetToolDisabled(sessionKey, name, false)
+	return fmt.Sprintf("✅ <code>%s</code> turned on for this chat.", html.EscapeString(name))
+}
+
+// remindersCommand lists the chat's reminders, or snoozes or cancels one.
+func (c *TelegramChannel) remindersCommand(sessionKey, arg string) string {
+	now := time.Now()
+	fields := strings.Fields(arg)
+	if len(fields) == 0 || fields[0] == "all" {
+		list, err := c.reminders.List(sessionKey, len(fields) > 0)
+		if err != nil {
+			return "⚠️ " + html.EscapeString(err.Error())
+		}
+		if len(list) == 0 {
+			return "⏰ No pending reminders.\n\nJust ask, e.g. <i>remind me to call mom tomorrow at 6pm</i>."
+		}
+		lines := []string{fmt.Sprintf("⏰ <b>Reminders</b> (%d)\n", len(list))}
+		for _, r := range list {
+			lines = append(lines, html.EscapeString(r.Describe(now)))
+		}
+		lines = append(lines, "\nUsage: /reminders snooze &lt;id&gt; [30m|tomorrow 9am], /reminders cancel &lt;id&gt;, /reminders all")
+		return strings.Join(lines, "\n")
+	}
+
+	usage := "Usage: /reminders, /reminders snooze &lt;id&gt; [when] or /reminders cancel &lt;id&gt;"
+	if len(fields) < 2 {
+		return usage
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(fields[1], "#"), 10, 64)
+	if err != nil {
+		return usage
+	}
+
+	switch fields[0] {
+	case "snooze":
+		until := now.Add(reminders.DefaultSnooze)
+		if when := strings.Join(fields[2:], " "); when != "" {
+			if until, err = reminders.ParseWhen(when, now); err != nil {
+				return "⚠️ " + html.EscapeString(err.Error())
+			}
+		}
+		r, err := c.reminders.Snooze(sessionKey, id, until)
+		if err != nil {
+			return "⚠️ " + html.EscapeString(err.Error())
+		}
+		return "💤 Snoozed " + html.EscapeString(r.Describe(now))
+	case "cancel":
+		r, err := c.reminders.Cancel(sessionKey, id)
+		if err != nil {
+			return "⚠️ " + html.EscapeString(err.Error())
+		}
+		return fmt.Sprintf("✓ Cancelled reminder #%d: %s", r.ID, html.EscapeString(r.Text))
+	}
+	return usage
+}
+
+// monitorsCommand lists the chat's uptime monitors, or adds, checks,
+// pauses, resumes or removes one.
+func (c *TelegramChannel) monitorsCommand(sessionKey, arg string) string {
+	now := time.Now()
+	fields := strings.Fields(arg)
+	if len(fields) == 0 {
+		list, err := c.monitors.List(sessionKey)
+		if err != nil {
+			return "⚠️ " + html.EscapeString(err.Error())
+		}
+		if len(list) == 0 {
+			return "📡 No monitors yet.\n\nAdd one with /monitors add https://example.com 5m, or just ask, e.g. <i>let me know if my blog goes down</i>."
+		}
+		lines := []string{fmt.Sprintf("📡 <b>Monitors</b> (%d)\n", len(list))}
+		for _, m := range list {
+			lines = append(lines, html.EscapeString(m.Describe(now)))
+		}
+		lines = append(lines, "\nUsage: /monitors add &lt;url&gt; [5m], /monitors check|pause|resume|remove &lt;id&gt;")
+		return strings.Join(lines, "\n")
+	}
+
+	usage := "Usage: /monitors, /monitors add &lt;url&gt; [interval] or /monitors check|pause|resume|remove &lt;id&gt;"
+	if len(fields) < 2 {
+		return usage
+	}
+	if fields[0] == "add" {
+		m := monitors.Monitor{URL: fields[1]}
+		if len(fields) > 2 {
+			interval, err := time.ParseDuration(fields[2])
+			if err != nil {
+				return usage
+			}
+			m.Interval = interval
+		}
+		added, err := c.monitors.Add(sessionKey, m)
+		if err != nil {
+			return "⚠️ " + html.EscapeString(err.Error())
+		}
+		return "📡 Monitoring " + html.EscapeString(added.Describe(now))
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(fields[1], "#"), 10, 64)
+	if err != nil {
+		return usage
+	}
+	switch fields[0] {
+	case "check":
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		m, res, err := c.monitors.CheckNow(ctx, sessionKey, id)
+		if err != nil {
+			return "⚠️ " + html.EscapeString(err.Error())
+		}
+		if !res.OK {
+			return "🔴 Check failed: " + html.EscapeString(res.Error) + "\n" + html.EscapeString(m.Describe(now))
+		}
+		return fmt.Sprintf("🟢 HTTP %d in %s\n%s", res.Status, res.Latency.Round(time.Millisecond), html.EscapeString(m.Describe(now)))
+	case "pause", "resume":
+		m, err := c.monitors.SetPaused(sessionKey, id, fields[0] == "pause")
+		if err != nil {
+			return "⚠️ " + html.EscapeString(err.Error())
+		}
+		return html.EscapeString(m.Describe(now))
+	case "remove":
+		m, err := c.monitors.Remove(sessionKey, id)
+		if err != nil {
+			return "⚠️ " + html.EscapeString(err.Error())
+		}
+		return fmt.Sprintf("✓ Removed monitor #%d: %s", m.ID, html.EscapeString(m.Name))
+	}
+	return usage
+}
+
 func (c *TelegramChannel) downloadPhoto(fileID string) string {
 	file, err := c.bot.GetFile(tgbotapi.FileConfig{FileID: fileID})
 	if err != nil {
@@ -510,7 +1542,7 @@ func (c *TelegramChannel) downloadFileWithInfo(file *tgbotapi.File, ext string)
 	url := file.Link(c.bot.Token)
 	log.Printf("File URL: %s", url)
 
-	mediaDir := filepath.Join(os.TempDir(), "mclaw_media")
+	mediaDir := storage.MediaDir()
 	if err := os.MkdirAll(mediaDir, 0755); err != nil {
 		log.Printf("Failed to create media directory: %v", err)
 		return ""
@@ -527,7 +1559,7 @@ func (c *TelegramChannel) downloadFileWithInfo(file *tgbotapi.File, ext string)
 }
 
 func (c *TelegramChannel) downloadFromURL(url, localPath string) error {
-	resp, err := http.Get(url)
+	resp, err := c.guard.Client(2 * time.Minute).Get(url)
 	if err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
@@ -566,7 +1598,7 @@ func (c *TelegramChannel) downloadFile(fileID, ext string) string {
 	url := file.Link(c.bot.Token)
 	log.Printf("File URL: %s", url)
 
-	mediaDir := filepath.Join(os.TempDir(), "mclaw_media")
+	mediaDir := storage.MediaDir()
 	if err := os.MkdirAll(mediaDir, 0755); err != nil {
 		log.Printf("Failed to create media directory: %v", err)
 		return ""
@@ -582,6 +1614,14 @@ func (c *TelegramChannel) downloadFile(fileID, ext string) string {
 	return localPath
 }
 
+var htmlTag = regexp.MustCompile(`<[^>]+>`)
+
+// htmlToText strips the markup of a part rendered as Telegram HTML, for
+// when Telegram rejects it.
+func htmlToText(s string) string {
+	return html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+}
+
 func parseChatID(chatIDStr string) (int64, error) {
 	var id int64
 	_, err := fmt.Sscanf(chatIDStr, "%d", &id)
@@ -594,107 +1634,3 @@ func truncateString(s string, maxLen int) string {
 	}
 	return s[:maxLen]
 }
-
-func markdownToTelegramHTML(text string) string {
-	if text == "" {
-		return ""
-	}
-
-	codeBlocks := extractCodeBlocks(text)
-	text = codeBlocks.text
-
-	inlineCodes := extractInlineCodes(text)
-	text = inlineCodes.text
-
-	text = regexp.MustCompile(`^#{1,6}\s+(.+)$`).ReplaceAllString(text, "$1")
-
-	text = regexp.MustCompile(`^>\s*(.*)$`).ReplaceAllString(text, "$1")
-
-	text = escapeHTML(text)
-
-	text = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`).ReplaceAllString(text, `<a href="$2">$1</a>`)
-
-	text = regexp.MustCompile(`\*\*(.+?)\*\*`).ReplaceAllString(text, "<b>$1</b>")
-
-	text = regexp.MustCompile(`__(.+?)__`).ReplaceAllString(text, "<b>$1</b>")
-
-	reItalic := regexp.MustCompile(`_([^_]+)_`)
-	text = reItalic.ReplaceAllStringFunc(text, func(s string) string {
-		match := reItalic.FindStringSubmatch(s)
-		if len(match) < 2 {
-			return s
-		}
-		return "<i>" + match[1] + "</i>"
-	})
-
-	text = regexp.MustCompile(`~~(.+?)~~`).ReplaceAllString(text, "<s>$1</s>")
-
-	text = regexp.MustCompile(`^[-*]\s+`).ReplaceAllString(text, "• ")
-
-	for i, code := range inlineCodes.codes {
-		escaped := escapeHTML(code)
-		text = strings.ReplaceAll(text, fmt.Sprintf("\x00IC%d\x00", i), fmt.Sprintf("<code>%s</code>", escaped))
-	}
-
-	for i, code := range codeBlocks.codes {
-		escaped := escapeHTML(code)
-		text = strings.ReplaceAll(text, fmt.Sprintf("\x00CB%d\x00", i), fmt.Sprintf("<pre><code>%s</code></pre>", escaped))
-	}
-
-	return text
-}
-
-type codeBlockMatch struct {
-	text  string
-	codes []string
-}
-
-func extractCodeBlocks(text string) codeBlockMatch {
-	re := regexp.MustCompile("```[\\w]*\\n?([\\s\\S]*?)```")
-	matches := re.FindAllStringSubmatch(text, -1)
-
-	codes := make([]string, 0, len(matches))
-	for _, match := range matches {
-		codes = append(codes, match[1])
-	}
-
-	i := 0
-	text = re.ReplaceAllStringFunc(text, func(m string) string {
-		placeholder := fmt.Sprintf("\x00CB%d\x00", i)
-		i++
-		return placeholder
-	})
-
-	return codeBlockMatch{text: text, codes: codes}
-}
-
-type inlineCodeMatch struct {
-	text  string
-	codes []string
-}
-
-func extractInlineCodes(text string) inlineCodeMatch {
-	re := regexp.MustCompile("`([^`]+)`")
-	matches := re.FindAllStringSubmatch(text, -1)
-
-	codes := make([]string, 0, len(matches))
-	for _, match := range matches {
-		codes = append(codes, match[1])
-	}
-
-	i := 0
-	text = re.ReplaceAllStringFunc(text, func(m string) string {
-		placeholder := fmt.Sprintf("\x00IC%d\x00", i)
-		i++
-		return placeholder
-	})
-
-	return inlineCodeMatch{text: text, codes: codes}
-}
-
-func escapeHTML(text string) string {
-	text = strings.ReplaceAll(text, "&", "&amp;")
-	text = strings.ReplaceAll(text, "<", "&lt;")
-	text = strings.ReplaceAll(text, ">", "&gt;")
-	return text
-}
@@ -0,0 +1,131 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// TestChannel is an in-memory channel for tests and for programs embedding
+// mclaw: Receive publishes a message as if a user had sent it, and
+// everything the agent sends is recorded. Register it on a Manager (or
+// consume the bus directly) like any other channel.
+type TestChannel struct {
+	*BaseChannel
+
+	mu     sync.Mutex
+	sent   []bus.OutboundMessage
+	notify chan struct{}
+	seq    int
+}
+
+// NewTestChannel returns a channel named name; allowList works as for the
+// real channels (empty allows everyone).
+func NewTestChannel(name string, messageBus *bus.MessageBus, allowList ...string) *TestChannel {
+	return &TestChannel{
+		BaseChannel: NewBaseChannel(name, nil, messageBus, allowList),
+		notify:      make(chan struct{}),
+	}
+}
+
+func (c *TestChannel) Start(ctx context.Context) error {
+	c.setRunning(true)
+	return nil
+}
+
+func (c *TestChannel) Stop(ctx context.Context) error {
+	c.setRunning(false)
+	return nil
+}
+
+// Send records msg. Messages arrive already formatted by the manager's
+// dispatcher when the channel is registered on one.
+func (c *TestChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, msg)
+	c.rememberReply(msg.ChatID, fmt.Sprintf("%d", len(c.sent)-1), msg)
+	close(c.notify)
+	c.notify = make(chan struct{})
+	return nil
+}
+
+// Receive publishes an inbound message from senderID in chatID. It returns
+// false if the message was dropped, e.g. because the sender is not allowed.
+func (c *TestChannel) Receive(senderID, chatID, content string) bool {
+	c.mu.Lock()
+	c.seq++
+	id := fmt.Sprintf("%d", c.seq)
+	c.mu.Unlock()
+	return c.HandleMessage(senderID, chatID, content, nil, map[string]string{"message_id": id})
+}
+
+// React puts emoji on the sent message with the given index (see Sent) as
+// senderID. It returns false if the reaction was ignored.
+func (c *TestChannel) React(senderID string, index int, emoji string) bool {
+	c.mu.Lock()
+	if index < 0 || index >= len(c.sent) {
+		c.mu.Unlock()
+		return false
+	}
+	chatID := c.sent[index].ChatID
+	c.mu.Unlock()
+	return c.HandleReaction(senderID, chatID, fmt.Sprintf("%d", index), emoji)
+}
+
+// Sent returns the messages sent so far.
+func (c *TestChannel) Sent() []bus.OutboundMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]bus.OutboundMessage(nil), c.sent...)
+}
+
+// WaitSent blocks until at least n messages were sent, or timeout passes.
+func (c *TestChannel) WaitSent(n int, timeout time.Duration) ([]bus.OutboundMessage, error) {
+	return c.wait(timeout, func(sent []bus.OutboundMessage) bool { return len(sent) >= n },
+		fmt.Sprintf("%d messages", n))
+}
+
+// WaitReply blocks until a message that is not interim (thinking, status)
+// was sent to chatID and returns the first one.
+func (c *TestChannel) WaitReply(chatID string, timeout time.Duration) (bus.OutboundMessage, error) {
+	var reply bus.OutboundMessage
+	_, err := c.wait(timeout, func(sent []bus.OutboundMessage) bool {
+		for _, msg := range sent {
+			if msg.ChatID == chatID && !msg.Interim() {
+				reply = msg
+				return true
+			}
+		}
+		return false
+	}, "a reply to "+chatID)
+	return reply, err
+}
+
+func (c *TestChannel) wait(timeout time.Duration, done func([]bus.OutboundMessage) bool, what string) ([]bus.OutboundMessage, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		c.mu.Lock()
+		sent, notify := append([]bus.OutboundMessage(nil), c.sent...), c.notify
+		c.mu.Unlock()
+		if done(sent) {
+			return sent, nil
+		}
+		select {
+		case <-notify:
+		case <-deadline.C:
+			return sent, fmt.Errorf("timed out after %s waiting for %s, got %d messages", timeout, what, len(sent))
+		}
+	}
+}
+
+// Reset forgets the messages sent so far.
+func (c *TestChannel) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = nil
+}
@@ -85,19 +85,22 @@ func (c *WhatsAppChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 		return fmt.Errorf("whatsapp connection not established")
 	}
 
-	payload := map[string]interface{}{
-		"type":    "message",
-		"to":      msg.ChatID,
-		"content": msg.Content,
-	}
+	msg = formatMessage(msg, profileFor(c.Name(), nil))
+	for _, part := range msg.Parts {
+		payload := map[string]interface{}{
+			"type":    "message",
+			"to":      msg.ChatID,
+			"content": part,
+		}
 
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
-	}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message: %w", err)
+		}
 
-	if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
 	}
 
 	return nil
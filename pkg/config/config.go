@@ -2,26 +2,377 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/caarlos0/env/v11"
+
+	"github.com/ntminh611/mclaw/pkg/markdown"
+	"github.com/ntminh611/mclaw/pkg/proactivity"
 )
 
 type Config struct {
-	Agents    AgentsConfig    `json:"agents"`
-	Channels  ChannelsConfig  `json:"channels"`
-	Providers ProvidersConfig `json:"providers"`
-	Tools     ToolsConfig     `json:"tools"`
-	Memory    MemoryConfig    `json:"memory"`
-	Heartbeat HeartbeatConfig `json:"heartbeat"`
-	mu        sync.RWMutex
+	Agents      AgentsConfig      `json:"agents"`
+	Channels    ChannelsConfig    `json:"channels"`
+	Providers   ProvidersConfig   `json:"providers"`
+	Tools       ToolsConfig       `json:"tools"`
+	Memory      MemoryConfig      `json:"memory"`
+	Heartbeat   HeartbeatConfig   `json:"heartbeat"`
+	Usage       UsageConfig       `json:"usage"`
+	Budget      BudgetConfig      `json:"budget"`
+	ModelInfo   ModelCatalog      `json:"model_info"`
+	Sessions    SessionsConfig    `json:"sessions"`
+	BotGuard    BotGuardConfig    `json:"bot_guard"`
+	Projects    []ProjectConfig   `json:"projects"`
+	TTS         TTSConfig         `json:"tts"`
+	STT         STTConfig         `json:"stt"`
+	OCR         OCRConfig         `json:"ocr"`
+	Feeds       FeedsConfig       `json:"feeds"`
+	Skills      SkillsConfig      `json:"skills"`
+	Secrets     SecretsConfig     `json:"secrets"`
+	Auth        AuthConfig        `json:"auth"`
+	Health      HealthConfig      `json:"health"`
+	Digest      DigestConfig      `json:"digest"`
+	Approvals   ApprovalsConfig   `json:"approvals"`
+	Proactivity ProactivityConfig `json:"proactivity"`
+	Webhooks    WebhooksConfig    `json:"webhooks"`
+	API         APIConfig         `json:"api"`
+	Inbox       InboxConfig       `json:"inbox"`
+	Storage     StorageConfig     `json:"storage"`
+	Watches     WatchesConfig     `json:"watches"`
+	Monitors    MonitorsConfig    `json:"monitors"`
+	Contacts    ContactsConfig    `json:"contacts"`
+	Expenses    ExpensesConfig    `json:"expenses"`
+	Bookmarks   BookmarksConfig   `json:"bookmarks"`
+	DryRun      bool              `json:"dry_run" env:"MCLAW_DRY_RUN"` // echo LLM calls and make tools no-ops, see --dry-run
+	mu          sync.RWMutex
+	path        string       // file loaded by LoadConfig, watched for changes
+	files       []string     // path and its includes
+	hooks       []ReloadHook // run after a reload
+	refs        []secretRef  // resolved secret:NAME references
+}
+
+// TTSConfig selects the text-to-speech engine used for voice replies.
+type TTSConfig struct {
+	Provider string `json:"provider" env:"MCLAW_TTS_PROVIDER"` // openai, elevenlabs or piper (empty = off)
+	APIKey   string `json:"api_key" env:"MCLAW_TTS_API_KEY"`   // openai falls back to providers.openai.api_key
+	APIBase  string `json:"api_base" env:"MCLAW_TTS_API_BASE"` // OpenAI-compatible endpoint
+	Model    string `json:"model" env:"MCLAW_TTS_MODEL"`       // tts-1, an ElevenLabs model ID, or a piper .onnx voice path
+	Voice    string `json:"voice" env:"MCLAW_TTS_VOICE"`       // OpenAI voice name or ElevenLabs voice ID
+	MaxChars int    `json:"max_chars" env:"MCLAW_TTS_MAX_CHARS"`
+}
+
+// STTConfig selects the speech-to-text backend for voice messages. The
+// default uses Groq when providers.groq.api_key is set; "server" and
+// "whisper_cpp" keep audio on your own machines.
+type STTConfig struct {
+	Provider string `json:"provider" env:"MCLAW_STT_PROVIDER"` // groq, server or whisper_cpp
+	APIBase  string `json:"api_base" env:"MCLAW_STT_API_BASE"` // server: OpenAI-compatible endpoint, e.g. http://localhost:8000/v1
+	APIKey   string `json:"api_key" env:"MCLAW_STT_API_KEY"`
+	Model    string `json:"model" env:"MCLAW_STT_MODEL"`   // server model name, or whisper.cpp ggml model path
+	Binary   string `json:"binary" env:"MCLAW_STT_BINARY"` // whisper.cpp CLI (default: whisper-cli)
+	Language string `json:"language" env:"MCLAW_STT_LANGUAGE"`
+}
+
+// OCRConfig extracts the text of images users send and adds it to their
+// message, so receipts and screenshots work with a text-only model.
+type OCRConfig struct {
+	Enabled        bool   `json:"enabled" env:"MCLAW_OCR_ENABLED"`
+	Engine         string `json:"engine" env:"MCLAW_OCR_ENGINE"`                     // auto (tesseract if installed, else the vision model), tesseract or vision
+	Languages      string `json:"languages" env:"MCLAW_OCR_LANGUAGES"`               // tesseract languages, e.g. eng+vie
+	TesseractPath  string `json:"tesseract_path" env:"MCLAW_OCR_TESSERACT_PATH"`     // default: tesseract on PATH
+	MaxChars       int    `json:"max_chars" env:"MCLAW_OCR_MAX_CHARS"`               // extracted text kept per image
+	OnlyTextModels bool   `json:"only_text_models" env:"MCLAW_OCR_ONLY_TEXT_MODELS"` // skip when the model can see images itself
+}
+
+// FeedsConfig controls RSS/Atom polling. Subscriptions listed here are
+// added at startup; users can add more with the feeds tool.
+type FeedsConfig struct {
+	Enabled         bool               `json:"enabled" env:"MCLAW_FEEDS_ENABLED"`
+	IntervalMinutes int                `json:"interval_minutes" env:"MCLAW_FEEDS_INTERVAL_MINUTES"` // default poll interval
+	MaxItems        int                `json:"max_items" env:"MCLAW_FEEDS_MAX_ITEMS"`               // new items per push; the rest are counted
+	Summarize       bool               `json:"summarize" env:"MCLAW_FEEDS_SUMMARIZE"`               // digest new items with the summary model
+	Subscriptions   []FeedSubscription `json:"subscriptions"`
+}
+
+// MonitorsConfig sets the defaults of uptime monitors, which check a URL
+// on an interval and alert their chat when it goes down and comes back.
+type MonitorsConfig struct {
+	Enabled             bool `json:"enabled" env:"MCLAW_MONITORS_ENABLED"`
+	IntervalMinutes     int  `json:"interval_minutes" env:"MCLAW_MONITORS_INTERVAL_MINUTES"`           // default check interval
+	FailuresBeforeAlert int  `json:"failures_before_alert" env:"MCLAW_MONITORS_FAILURES_BEFORE_ALERT"` // consecutive failed checks before a monitor is down
+	RepeatAlertMinutes  int  `json:"repeat_alert_minutes" env:"MCLAW_MONITORS_REPEAT_ALERT_MINUTES"`   // re-alert while still down; 0 = only on changes
+	TimeoutSeconds      int  `json:"timeout_seconds" env:"MCLAW_MONITORS_TIMEOUT_SECONDS"`             // per check
+}
+
+// ContactsConfig controls the contact book, whose people are recalled
+// into the prompt when mentioned and whose birthdays are announced.
+type ContactsConfig struct {
+	Enabled      bool `json:"enabled" env:"MCLAW_CONTACTS_ENABLED"`
+	BirthdayHour int  `json:"birthday_hour" env:"MCLAW_CONTACTS_BIRTHDAY_HOUR"` // local hour birthdays are announced at
+}
+
+// ExpensesConfig controls the expense ledger and its monthly report, sent
+// on the 1st to every chat that recorded expenses the month before.
+type ExpensesConfig struct {
+	Enabled       bool   `json:"enabled" env:"MCLAW_EXPENSES_ENABLED"`
+	Currency      string `json:"currency" env:"MCLAW_EXPENSES_CURRENCY"`             // default currency, budgets are in it
+	AlertPercent  int    `json:"alert_percent" env:"MCLAW_EXPENSES_ALERT_PERCENT"`   // warn when a budget is this full; 0 = only when exceeded
+	MonthlyReport bool   `json:"monthly_report" env:"MCLAW_EXPENSES_MONTHLY_REPORT"` // send last month's report on the 1st
+	ReportHour    int    `json:"report_hour" env:"MCLAW_EXPENSES_REPORT_HOUR"`       // local hour of the report
+}
+
+// BookmarksConfig controls the read-later list and its weekly digest of
+// the links saved that week and not yet read.
+type BookmarksConfig struct {
+	Enabled      bool   `json:"enabled" env:"MCLAW_BOOKMARKS_ENABLED"`
+	Summarize    bool   `json:"summarize" env:"MCLAW_BOOKMARKS_SUMMARIZE"`         // summarize saved pages with the summary model
+	WeeklyDigest bool   `json:"weekly_digest" env:"MCLAW_BOOKMARKS_WEEKLY_DIGEST"` // send the week's unread links
+	DigestDay    string `json:"digest_day" env:"MCLAW_BOOKMARKS_DIGEST_DAY"`       // e.g. "sunday"
+	DigestHour   int    `json:"digest_hour" env:"MCLAW_BOOKMARKS_DIGEST_HOUR"`     // local hour of the digest
+}
+
+// DigestConfig schedules a summary of the assistant's activity (messages
+// handled, cron results, memories learned, spend) sent to the owner.
+type DigestConfig struct {
+	Enabled bool   `json:"enabled" env:"MCLAW_DIGEST_ENABLED"`
+	Period  string `json:"period" env:"MCLAW_DIGEST_PERIOD"`   // daily or weekly
+	Time    string `json:"time" env:"MCLAW_DIGEST_TIME"`       // local delivery time, "HH:MM"
+	Weekday string `json:"weekday" env:"MCLAW_DIGEST_WEEKDAY"` // weekly delivery day, e.g. "monday"
+	Channel string `json:"channel" env:"MCLAW_DIGEST_CHANNEL"`
+	ChatID  string `json:"chat_id" env:"MCLAW_DIGEST_CHAT_ID"` // default: the channel's first allow_from user
+}
+
+// ApprovalsConfig holds messages the assistant sends on its own (cron,
+// heartbeat, workflow and webhook results) as drafts for the owner to
+// approve before they go to other chats. Messages to the owner's own chat
+// are sent right away.
+type ApprovalsConfig struct {
+	Enabled     bool   `json:"enabled" env:"MCLAW_APPROVALS_ENABLED"`
+	Channel     string `json:"channel" env:"MCLAW_APPROVALS_CHANNEL"` // where drafts are sent; approve buttons need telegram
+	ChatID      string `json:"chat_id" env:"MCLAW_APPROVALS_CHAT_ID"` // default: the channel's first allow_from user
+	ExpireHours int    `json:"expire_hours" env:"MCLAW_APPROVALS_EXPIRE_HOURS"`
+}
+
+// ProactivityConfig limits how often the assistant messages a chat on its
+// own. Heartbeat, cron, feed, watcher and other proactive results count
+// against max_per_day, and during quiet_hours they wait until the quiet
+// hours end. Monitor alerts and messages starting with an urgent prefix
+// always go through.
+type ProactivityConfig struct {
+	Enabled        bool     `json:"enabled" env:"MCLAW_PROACTIVITY_ENABLED"`
+	MaxPerDay      int      `json:"max_per_day" env:"MCLAW_PROACTIVITY_MAX_PER_DAY"`         // per chat; 0 = unlimited
+	QuietHours     string   `json:"quiet_hours" env:"MCLAW_PROACTIVITY_QUIET_HOURS"`         // local time, e.g. "22:00-07:00"; empty = none
+	DropWhenQuiet  bool     `json:"drop_when_quiet" env:"MCLAW_PROACTIVITY_DROP_WHEN_QUIET"` // discard instead of delivering after the quiet hours
+	UrgentPrefixes []string `json:"urgent_prefixes" env:"MCLAW_PROACTIVITY_URGENT_PREFIXES"`
+}
+
+// WebhooksConfig serves /hooks/<name> endpoints that turn an incoming
+// payload into an agent turn, e.g. for GitHub, Grafana or IFTTT.
+type WebhooksConfig struct {
+	Enabled bool         `json:"enabled" env:"MCLAW_WEBHOOKS_ENABLED"`
+	Listen  string       `json:"listen" env:"MCLAW_WEBHOOKS_LISTEN"` // host:port of the HTTP server
+	Hooks   []HookConfig `json:"hooks"`
+}
+
+// HookConfig is one webhook endpoint.
+type HookConfig struct {
+	Name    string `json:"name"`    // served at /hooks/<name>
+	Secret  string `json:"secret"`  // HMAC key (X-Hub-Signature-256) or token (Bearer, X-Webhook-Token, ?token=)
+	Prompt  string `json:"prompt"`  // Go template over .payload, .headers and .query
+	Channel string `json:"channel"` // where the agent's reply is sent (empty = not sent)
+	ChatID  string `json:"chat_id"`
+}
+
+// WatchesConfig makes the gateway watch files and directories. Each batch
+// of changes runs the watch's prompt as a background turn and the reply is
+// sent to its chat. The watch_path tool adds more watches at runtime.
+type WatchesConfig struct {
+	Enabled         bool              `json:"enabled" env:"MCLAW_WATCHES_ENABLED"`
+	IntervalSeconds int               `json:"interval_seconds" env:"MCLAW_WATCHES_INTERVAL_SECONDS"` // how often paths are scanned
+	Paths           []WatchPathConfig `json:"paths"`
+}
+
+// WatchPathConfig is one watched file or directory.
+type WatchPathConfig struct {
+	Name      string   `json:"name"`      // also the "watch:<name>" session
+	Path      string   `json:"path"`      // ~ is expanded
+	Pattern   string   `json:"pattern"`   // file name glob, e.g. "*.pdf" (empty = all files)
+	Recursive bool     `json:"recursive"` // include subdirectories
+	Events    []string `json:"events"`    // created, modified, removed (default created)
+	Prompt    string   `json:"prompt"`    // Go template over .name, .path and .changes
+	Channel   string   `json:"channel"`   // where the agent's reply is sent (empty = not sent)
+	ChatID    string   `json:"chat_id"`
+}
+
+// APIConfig serves an OpenAI-compatible /v1/chat/completions endpoint, so
+// chat apps and SDKs can use the assistant, with its tools, memory and
+// sessions, as if it were a model.
+type APIConfig struct {
+	Enabled bool     `json:"enabled" env:"MCLAW_API_ENABLED"`
+	Listen  string   `json:"listen" env:"MCLAW_API_LISTEN"` // host:port of the HTTP server
+	Keys    []string `json:"keys" env:"MCLAW_API_KEYS"`     // accepted bearer tokens
+	Model   string   `json:"model" env:"MCLAW_API_MODEL"`   // model name shown to clients
+}
+
+// InboxConfig controls where files sent to the bot are kept. They are
+// moved to workspace/inbox with their sender recorded, and deleted after
+// RetentionDays unless moved elsewhere.
+type InboxConfig struct {
+	Enabled       bool `json:"enabled" env:"MCLAW_INBOX_ENABLED"`               // false = leave downloads in the temp dir
+	RetentionDays int  `json:"retention_days" env:"MCLAW_INBOX_RETENTION_DAYS"` // 0 = keep until deleted
+}
+
+// StorageConfig limits the disk space used by downloaded media, the
+// workspace inbox and logs. The oldest files go first; 0 = no limit.
+type StorageConfig struct {
+	Enabled         bool          `json:"enabled" env:"MCLAW_STORAGE_ENABLED"`
+	IntervalMinutes int           `json:"interval_minutes" env:"MCLAW_STORAGE_INTERVAL_MINUTES"`
+	Limits          StorageLimits `json:"limits"`
+}
+
+// StorageLimits sets a limit per directory.
+type StorageLimits struct {
+	Media StorageLimit `json:"media"` // attachments downloaded to the temp dir
+	Inbox StorageLimit `json:"inbox"` // workspace/inbox
+	Logs  StorageLimit `json:"logs"`  // oversized logs are cut to their latest lines
+}
+
+// StorageLimit caps a directory's total size and the age of its files.
+type StorageLimit struct {
+	MaxMB      int `json:"max_mb"`
+	MaxAgeDays int `json:"max_age_days"`
+}
+
+// FeedSubscription delivers one feed to one chat.
+type FeedSubscription struct {
+	URL             string `json:"url"`
+	Channel         string `json:"channel"`
+	ChatID          string `json:"chat_id"`
+	IntervalMinutes int    `json:"interval_minutes"` // 0 = feeds.interval_minutes
+}
+
+// SkillsConfig points `mclaw skills` at a registry: a GitHub "owner/repo"
+// with an index.json of skill manifests, or a full URL to the index.
+type SkillsConfig struct {
+	Registry string `json:"registry" env:"MCLAW_SKILLS_REGISTRY"`
+}
+
+// SecretsConfig says where "secret:NAME" references in the config are
+// looked up, after the MCLAW_SECRET_<NAME> environment variables, and what
+// else to scrub from logs, tool results and sessions besides the config's
+// own credentials and well-known key formats.
+type SecretsConfig struct {
+	File    string   `json:"file" env:"MCLAW_SECRETS_FILE"`       // encrypted secrets file (default secrets.enc next to the config)
+	Keyring bool     `json:"keyring" env:"MCLAW_SECRETS_KEYRING"` // fall back to the OS keyring
+	Redact  []string `json:"redact"`                              // extra regular expressions to mask
+}
+
+// HealthConfig sets when a turn counts as stuck. A stuck turn is
+// cancelled; if it is still running after twice the time, systemd watchdog
+// pings stop so the service is restarted.
+type HealthConfig struct {
+	StuckMinutes int `json:"stuck_minutes" env:"MCLAW_HEALTH_STUCK_MINUTES"` // 0 = never
+}
+
+// AuthConfig sets what each role may do. Users in a channel's allow_from
+// are owners; owners give other users a role at runtime with /authorize.
+type AuthConfig struct {
+	Roles map[string]RoleConfig   `json:"roles"` // keyed by role: "owner", "guest"
+	Users map[string]LimitsConfig `json:"users"` // keyed by "channel:user_id", replaces the role's limits
+	Group LimitsConfig            `json:"group"` // extra cap per sender in group chats, except allow_from owners
+}
+
+// RoleConfig limits one role. Tools lists the tools the role may use and
+// DenyTools takes some of those away; leaving tools out keeps the role's
+// default list, [] allows every tool. Daily limits reset at local midnight
+// and PerMinute throttles bursts; 0 means unlimited.
+type RoleConfig struct {
+	Tools         []string `json:"tools"`
+	DenyTools     []string `json:"deny_tools"`
+	DailyMessages int      `json:"daily_messages"`
+	DailyTokens   int      `json:"daily_tokens"`
+	PerMinute     int      `json:"per_minute"`
+}
+
+// LimitsConfig caps one sender's usage; 0 means no limit.
+type LimitsConfig struct {
+	DailyMessages int `json:"daily_messages"`
+	DailyTokens   int `json:"daily_tokens"`
+	PerMinute     int `json:"per_minute"`
+}
+
+// UsageConfig controls token/cost reporting on replies.
+// Pricing is keyed by model name (as configured) in USD per 1M tokens.
+type UsageConfig struct {
+	ShowFooter bool                  `json:"show_footer" env:"MCLAW_USAGE_SHOW_FOOTER"` // append "≈ 3.2k tokens, $0.004" to replies
+	Pricing    map[string]ModelPrice `json:"pricing"`
+}
+
+// BudgetConfig caps the estimated LLM spend per day (local time), priced
+// with usage.pricing. Once DailyUSD is reached, only the owner's own
+// messages are answered: other users, cron jobs, heartbeats, webhooks,
+// memory extraction and summaries wait for the next day, and the owner is
+// told once.
+type BudgetConfig struct {
+	DailyUSD float64 `json:"daily_usd" env:"MCLAW_BUDGET_DAILY_USD"` // 0 = no limit
+	Channel  string  `json:"channel" env:"MCLAW_BUDGET_CHANNEL"`     // where the owner is told
+	ChatID   string  `json:"chat_id" env:"MCLAW_BUDGET_CHAT_ID"`     // default: the channel's first allow_from user
+}
+
+// SessionsConfig controls conversation retention. Sessions idle longer than
+// ArchiveAfterDays are compressed into archive files and restored when the
+// chat resumes. 0 disables archival.
+// ProjectConfig is a named workspace that a conversation can switch to with
+// /project, so work on different repositories doesn't share one directory.
+type ProjectConfig struct {
+	Name        string   `json:"name"`
+	Path        string   `json:"path"`
+	Description string   `json:"description"`
+	Tools       []string `json:"tools"` // tools offered while active (empty = all)
+}
+
+type SessionsConfig struct {
+	ArchiveAfterDays int `json:"archive_after_days" env:"MCLAW_SESSIONS_ARCHIVE_AFTER_DAYS"` // default 30
+}
+
+// BotGuardConfig limits exchanges with other bots in a chat. A sender counts
+// as a bot when the channel reports it or when it keeps answering our replies
+// within seconds. After MaxExchanges bot exchanges within WindowMinutes the
+// chat is muted for BackoffMinutes, doubling on each repeat (max 1 hour).
+type BotGuardConfig struct {
+	Enabled        bool `json:"enabled" env:"MCLAW_BOT_GUARD_ENABLED"`                 // default true
+	MaxExchanges   int  `json:"max_exchanges" env:"MCLAW_BOT_GUARD_MAX_EXCHANGES"`     // default 4
+	WindowMinutes  int  `json:"window_minutes" env:"MCLAW_BOT_GUARD_WINDOW_MINUTES"`   // default 10
+	BackoffMinutes int  `json:"backoff_minutes" env:"MCLAW_BOT_GUARD_BACKOFF_MINUTES"` // default 5
+}
+
+// ModelCatalog holds model_info entries by model name or family.
+type ModelCatalog map[string]ModelInfo
+
+// ModelInfo overrides what the built-in model registry knows about a model
+// or model family ("gpt-4o" also covers "openai/gpt-4o-2024-11-20"). Unset
+// fields keep the built-in value.
+type ModelInfo struct {
+	ContextWindow int         `json:"context_window,omitempty"` // tokens
+	Tools         *bool       `json:"tools,omitempty"`          // supports tool calling
+	Vision        *bool       `json:"vision,omitempty"`         // accepts images
+	Price         *ModelPrice `json:"price,omitempty"`
+}
+
+type ModelPrice struct {
+	Input  float64 `json:"input"`  // USD per 1M prompt tokens
+	Output float64 `json:"output"` // USD per 1M completion tokens
 }
 
 type HeartbeatConfig struct {
-	Enabled         bool `json:"enabled" env:"MCLAW_HEARTBEAT_ENABLED"`                   // default true
-	IntervalMinutes int  `json:"interval_minutes" env:"MCLAW_HEARTBEAT_INTERVAL_MINUTES"` // default 10
+	Enabled         bool   `json:"enabled" env:"MCLAW_HEARTBEAT_ENABLED"`                   // default true
+	IntervalMinutes int    `json:"interval_minutes" env:"MCLAW_HEARTBEAT_INTERVAL_MINUTES"` // default 10
+	Template        string `json:"template" env:"MCLAW_HEARTBEAT_TEMPLATE"`                 // delivery template with {date}, {time}, {result}
 }
 
 // MemoryConfig controls the Mem0-lite intelligent memory layer.
@@ -48,6 +399,52 @@ type AgentDefaults struct {
 	MaxTokens         int      `json:"max_tokens" env:"MCLAW_AGENTS_DEFAULTS_MAX_TOKENS"`
 	Temperature       float64  `json:"temperature" env:"MCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
 	MaxToolIterations int      `json:"max_tool_iterations" env:"MCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
+	LongMessageChars  int      `json:"long_message_chars" env:"MCLAW_AGENTS_DEFAULTS_LONG_MESSAGE_CHARS"` // inbound messages longer than this are summarized (0 = off)
+	CoalesceMs        int      `json:"coalesce_ms" env:"MCLAW_AGENTS_DEFAULTS_COALESCE_MS"`               // merge a sender's messages arriving within this many ms into one turn (0 = off)
+	Workers           int      `json:"workers" env:"MCLAW_AGENTS_DEFAULTS_WORKERS"`                       // sessions processed concurrently
+	StreamIdleSecs    int      `json:"stream_idle_secs" env:"MCLAW_AGENTS_DEFAULTS_STREAM_IDLE_SECS"`     // stop waiting on a silent LLM stream and keep the partial answer (0 = off)
+	SummaryModel      string   `json:"summary_model" env:"MCLAW_AGENTS_DEFAULTS_SUMMARY_MODEL"`           // LLM for summarization (default: agent model)
+	VisionModel       string   `json:"vision_model" env:"MCLAW_AGENTS_DEFAULTS_VISION_MODEL"`             // vision-capable LLM for describe_image (empty = tool disabled)
+
+	// Models names models for chat commands, e.g. {"fast": "groq/llama-3.3-70b",
+	// "pro": "anthropic/claude-opus-4"}, so "/retry pro" picks the smart one
+	Models map[string]string `json:"models"`
+
+	// Fallbacks picks other models when a call fails, with one chain per
+	// kind of error
+	Fallbacks FallbackPolicy `json:"fallbacks"`
+}
+
+// FallbackPolicy lists the models tried when a call fails, by error class.
+// Rate limits (429, overloaded), timeouts (stalled streams, 408/504) and
+// content-filter refusals each have their own chain. fallback_models is
+// the rate-limit chain when rate_limit.models is empty.
+type FallbackPolicy struct {
+	RateLimit     FallbackChain `json:"rate_limit"`
+	Timeout       FallbackChain `json:"timeout"`
+	ContentFilter FallbackChain `json:"content_filter"`
+}
+
+// FallbackChain is tried in order. A model that failed is skipped for
+// CooldownMinutes; while the primary model cools down, calls stay on the
+// fallback that worked. With no cooldown only the failed call is retried.
+type FallbackChain struct {
+	Models          []string `json:"models"`
+	CooldownMinutes int      `json:"cooldown_minutes"`
+}
+
+// FallbackChains returns the chains by error class ("rate_limit",
+// "timeout", "content_filter").
+func (d AgentDefaults) FallbackChains() map[string]FallbackChain {
+	rateLimit := d.Fallbacks.RateLimit
+	if len(rateLimit.Models) == 0 {
+		rateLimit.Models = d.FallbackModels
+	}
+	return map[string]FallbackChain{
+		"rate_limit":     rateLimit,
+		"timeout":        d.Fallbacks.Timeout,
+		"content_filter": d.Fallbacks.ContentFilter,
+	}
 }
 
 type ChannelsConfig struct {
@@ -55,6 +452,15 @@ type ChannelsConfig struct {
 	Telegram TelegramConfig `json:"telegram"`
 	Feishu   FeishuConfig   `json:"feishu"`
 	Discord  DiscordConfig  `json:"discord"`
+
+	// Format overrides how replies are formatted per channel name:
+	// telegram_html, discord, slack, whatsapp, plain or markdown (as written)
+	Format map[string]string `json:"format"`
+
+	// Recipients names groups of chats, as "channel:chat_id", that a
+	// message can be broadcast to: send it to channel "group" with the
+	// group's name as the chat ID, e.g. {"family": ["telegram:123", ...]}
+	Recipients map[string][]string `json:"recipients"`
 }
 
 type WhatsAppConfig struct {
@@ -64,9 +470,11 @@ type WhatsAppConfig struct {
 }
 
 type TelegramConfig struct {
-	Enabled   bool     `json:"enabled" env:"MCLAW_CHANNELS_TELEGRAM_ENABLED"`
-	Token     string   `json:"token" env:"MCLAW_CHANNELS_TELEGRAM_TOKEN"`
-	AllowFrom []string `json:"allow_from" env:"MCLAW_CHANNELS_TELEGRAM_ALLOW_FROM"`
+	Enabled      bool     `json:"enabled" env:"MCLAW_CHANNELS_TELEGRAM_ENABLED"`
+	Token        string   `json:"token" env:"MCLAW_CHANNELS_TELEGRAM_TOKEN"`
+	AllowFrom    []string `json:"allow_from" env:"MCLAW_CHANNELS_TELEGRAM_ALLOW_FROM"`
+	VoiceReplies bool     `json:"voice_replies" env:"MCLAW_CHANNELS_TELEGRAM_VOICE_REPLIES"` // answer voice messages with a voice note too (needs tts)
+	VoiceMode    int      `json:"voice_mode" env:"MCLAW_CHANNELS_TELEGRAM_VOICE_MODE"`       // minutes /voice conversation mode lasts after the last voice note
 }
 
 type FeishuConfig struct {
@@ -100,27 +508,166 @@ type ProviderConfig struct {
 }
 
 type WebSearchConfig struct {
-	APIKey     string `json:"api_key" env:"MCLAW_TOOLS_WEB_SEARCH_API_KEY"`
+	Provider   string `json:"provider" env:"MCLAW_TOOLS_WEB_SEARCH_PROVIDER"` // brave, tavily, searxng or duckduckgo
+	APIKey     string `json:"api_key" env:"MCLAW_TOOLS_WEB_SEARCH_API_KEY"`   // brave or tavily
+	BaseURL    string `json:"base_url" env:"MCLAW_TOOLS_WEB_SEARCH_BASE_URL"` // searxng instance
 	MaxResults int    `json:"max_results" env:"MCLAW_TOOLS_WEB_SEARCH_MAX_RESULTS"`
 }
 
+// ExtractionProfile tunes HTML-to-text extraction for one site.
+type ExtractionProfile struct {
+	Selector    string   `json:"selector"`     // main content CSS selector (overrides auto-detection)
+	Remove      []string `json:"remove"`       // extra CSS selectors to drop before extraction
+	KeepTables  bool     `json:"keep_tables"`  // render tables as Markdown rows
+	StripLinks  bool     `json:"strip_links"`  // omit link URLs from the text
+	WaitSeconds int      `json:"wait_seconds"` // browser only: JS render wait (0 = default)
+}
+
 type WebToolsConfig struct {
-	Search WebSearchConfig `json:"search"`
+	Search   WebSearchConfig              `json:"search"`
+	Profiles map[string]ExtractionProfile `json:"profiles"` // keyed by domain, also matches subdomains
+}
+
+type HTTPToolConfig struct {
+	Secrets map[string]string `json:"secrets"` // the only names {{secret:NAME}} resolves; a value may itself be "secret:NAME"
+}
+
+// NetworkConfig controls which internal addresses tools and media downloads
+// may reach. Loopback, private, link-local and metadata ranges are blocked
+// unless listed here.
+type NetworkConfig struct {
+	AllowPrivate []string `json:"allow_private"` // hostnames, IPs or CIDRs, e.g. "192.168.1.0/24"
+}
+
+// BrowserToolConfig controls the headless browser. With Persistent set,
+// cookies and logins are kept in ProfileDir between invocations; run
+// `mclaw browser login <url>` to sign in once interactively.
+type BrowserToolConfig struct {
+	Persistent bool   `json:"persistent" env:"MCLAW_TOOLS_BROWSER_PERSISTENT"`
+	ProfileDir string `json:"profile_dir" env:"MCLAW_TOOLS_BROWSER_PROFILE_DIR"` // default: <data dir>/browser_profile
+}
+
+// EmailToolConfig gives the email tool access to one IMAP mailbox. Only
+// the listed folders are visible. With ReadOnly (the default) folders are
+// opened with EXAMINE and nothing on the server is changed.
+type EmailToolConfig struct {
+	Host     string   `json:"host" env:"MCLAW_TOOLS_EMAIL_HOST"` // e.g. imap.gmail.com (empty = tool disabled)
+	Port     int      `json:"port" env:"MCLAW_TOOLS_EMAIL_PORT"` // default 993
+	Username string   `json:"username" env:"MCLAW_TOOLS_EMAIL_USERNAME"`
+	Password string   `json:"password" env:"MCLAW_TOOLS_EMAIL_PASSWORD"` // app password
+	NoTLS    bool     `json:"no_tls" env:"MCLAW_TOOLS_EMAIL_NO_TLS"`     // plain IMAP, for local bridges only
+	Folders  []string `json:"folders"`                                   // allowed folders (default INBOX); "Work/*" = subtree, "*" = all
+	ReadOnly bool     `json:"read_only" env:"MCLAW_TOOLS_EMAIL_READ_ONLY"`
+}
+
+// GitHubToolConfig gives the github tool access to the user's account. A
+// fine-grained token with read access to notifications and issues is
+// enough unless ReadOnly is off.
+type GitHubToolConfig struct {
+	Token    string `json:"token" env:"MCLAW_TOOLS_GITHUB_TOKEN"`       // empty = tool disabled
+	APIBase  string `json:"api_base" env:"MCLAW_TOOLS_GITHUB_API_BASE"` // default https://api.github.com; GitHub Enterprise: https://host/api/v3
+	ReadOnly bool   `json:"read_only" env:"MCLAW_TOOLS_GITHUB_READ_ONLY"`
+}
+
+// KubernetesToolConfig points the kubernetes tool at a cluster. It runs
+// kubectl with the user's kubeconfig and only ever reads, and only in the
+// listed namespaces.
+type KubernetesToolConfig struct {
+	Kubeconfig string   `json:"kubeconfig" env:"MCLAW_TOOLS_KUBERNETES_KUBECONFIG"` // default: kubectl's own ($KUBECONFIG or ~/.kube/config)
+	Context    string   `json:"context" env:"MCLAW_TOOLS_KUBERNETES_CONTEXT"`       // default: the current context
+	Namespaces []string `json:"namespaces"`                                         // empty = tool disabled; the first is the default
+}
+
+// MarketToolConfig picks the data sources of the market tool: CoinGecko
+// for crypto and a configurable provider for stocks.
+type MarketToolConfig struct {
+	StocksProvider  string `json:"stocks_provider" env:"MCLAW_TOOLS_MARKET_STOCKS_PROVIDER"`     // yahoo (default, worldwide) or tcbs (Vietnam: HOSE, HNX, UPCOM)
+	CoinGeckoAPIKey string `json:"coingecko_api_key" env:"MCLAW_TOOLS_MARKET_COINGECKO_API_KEY"` // optional demo key, raises the rate limit
+	Currency        string `json:"currency" env:"MCLAW_TOOLS_MARKET_CURRENCY"`                   // crypto quote currency, default usd
+}
+
+// SystemToolConfig controls the system tool, which reports CPU, memory,
+// disk, processes and service status. Unless ReadOnly, it may also start,
+// stop and restart the services listed in Services.
+type SystemToolConfig struct {
+	ReadOnly bool     `json:"read_only" env:"MCLAW_TOOLS_SYSTEM_READ_ONLY"`
+	Services []string `json:"services"` // systemd units it may control, also shown in the overview
+}
+
+// CodeRunConfig controls the code_run sandbox. "docker" runs each snippet
+// in a throwaway container without network; "local" runs the host's
+// python3/node in a temporary directory (and venv when packages are
+// requested) and is only as isolated as the mclaw user. "auto" uses docker
+// and refuses to run code when it is not installed.
+type CodeRunConfig struct {
+	Sandbox        string `json:"sandbox" env:"MCLAW_TOOLS_CODE_RUN_SANDBOX"` // auto, docker or local
+	PythonImage    string `json:"python_image" env:"MCLAW_TOOLS_CODE_RUN_PYTHON_IMAGE"`
+	NodeImage      string `json:"node_image" env:"MCLAW_TOOLS_CODE_RUN_NODE_IMAGE"`
+	TimeoutSeconds int    `json:"timeout_seconds" env:"MCLAW_TOOLS_CODE_RUN_TIMEOUT_SECONDS"`
+	MemoryMB       int    `json:"memory_mb" env:"MCLAW_TOOLS_CODE_RUN_MEMORY_MB"`           // docker only
+	AllowPackages  bool   `json:"allow_packages" env:"MCLAW_TOOLS_CODE_RUN_ALLOW_PACKAGES"` // let snippets pip/npm install (needs network)
+}
+
+// ToolPolicyConfig restricts tools in one channel. Deny applies to every
+// chat of the channel, GroupDeny only to group chats. Users cannot turn
+// denied tools back on with /tools.
+type ToolPolicyConfig struct {
+	Deny      []string `json:"deny"`
+	GroupDeny []string `json:"group_deny"`
 }
 
 type ToolsConfig struct {
-	Web WebToolsConfig `json:"web"`
+	Web     WebToolsConfig              `json:"web"`
+	HTTP    HTTPToolConfig              `json:"http"`
+	Network NetworkConfig               `json:"network"`
+	Browser BrowserToolConfig           `json:"browser"`
+	Email   EmailToolConfig             `json:"email"`
+	GitHub  GitHubToolConfig            `json:"github"`
+	CodeRun CodeRunConfig               `json:"code_run"`
+	System  SystemToolConfig            `json:"system"`
+	Audit   AuditConfig                 `json:"audit"`
+	Policy  map[string]ToolPolicyConfig `json:"policy"` // keyed by channel name; "*" applies to all channels
+
+	// Models the ask_models tool consults side by side
+	AskModels AskModelsConfig `json:"ask_models"`
+
+	// Read-only cluster access for the kubernetes tool
+	Kubernetes KubernetesToolConfig `json:"kubernetes"`
+
+	// Price sources of the market tool
+	Market MarketToolConfig `json:"market"`
+}
+
+// AskModelsConfig lists the models (names or aliases from
+// agents.defaults.models) ask_models sends a prompt to. The tool needs at
+// least two.
+type AskModelsConfig struct {
+	Models         []string `json:"models"`
+	TimeoutSeconds int      `json:"timeout_seconds" env:"MCLAW_TOOLS_ASK_MODELS_TIMEOUT_SECONDS"` // per model
+}
+
+// AuditConfig controls the append-only log of tool calls (audit.jsonl in
+// the data directory), read with `mclaw audit`.
+type AuditConfig struct {
+	Enabled bool `json:"enabled" env:"MCLAW_TOOLS_AUDIT_ENABLED"`
 }
 
 func DefaultConfig() *Config {
 	return &Config{
 		Agents: AgentsConfig{
 			Defaults: AgentDefaults{
-				Workspace:         "./mclaw/workspace",
+				Workspace:         defaultWorkspace(),
 				Model:             "glm-4.7",
 				MaxTokens:         8192,
 				Temperature:       0.7,
 				MaxToolIterations: 20,
+				LongMessageChars:  6000,
+				Workers:           1,
+				StreamIdleSecs:    90,
+				Fallbacks: FallbackPolicy{
+					RateLimit: FallbackChain{CooldownMinutes: 60},
+					Timeout:   FallbackChain{CooldownMinutes: 10},
+				},
 			},
 		},
 		Channels: ChannelsConfig{
@@ -133,6 +680,7 @@ func DefaultConfig() *Config {
 				Enabled:   false,
 				Token:     "",
 				AllowFrom: []string{},
+				VoiceMode: 10,
 			},
 			Feishu: FeishuConfig{
 				Enabled:           false,
@@ -164,6 +712,24 @@ func DefaultConfig() *Config {
 					MaxResults: 5,
 				},
 			},
+			Email: EmailToolConfig{
+				Port:     993,
+				Folders:  []string{"INBOX"},
+				ReadOnly: true,
+			},
+			CodeRun: CodeRunConfig{
+				Sandbox:        "auto",
+				PythonImage:    "python:3.12-slim",
+				NodeImage:      "node:22-slim",
+				TimeoutSeconds: 60,
+				MemoryMB:       512,
+			},
+			System:    SystemToolConfig{ReadOnly: true},
+			AskModels: AskModelsConfig{TimeoutSeconds: 90},
+			Market:    MarketToolConfig{StocksProvider: "yahoo", Currency: "usd"},
+			Audit: AuditConfig{
+				Enabled: true,
+			},
 		},
 		Memory: MemoryConfig{
 			Enabled:      false,
@@ -174,22 +740,158 @@ func DefaultConfig() *Config {
 			MaxMemories:  1000,
 			ExtractModel: "", // use agent model
 		},
+		Usage: UsageConfig{
+			ShowFooter: false,
+			Pricing:    map[string]ModelPrice{},
+		},
+		Budget: BudgetConfig{
+			Channel: "telegram",
+		},
+		Sessions: SessionsConfig{
+			ArchiveAfterDays: 30,
+		},
+		BotGuard: BotGuardConfig{
+			Enabled:        true,
+			MaxExchanges:   4,
+			WindowMinutes:  10,
+			BackoffMinutes: 5,
+		},
+		TTS: TTSConfig{
+			MaxChars: 1500,
+		},
+		OCR: OCRConfig{
+			Engine:         "auto",
+			Languages:      "eng",
+			MaxChars:       4000,
+			OnlyTextModels: true,
+		},
+		Feeds: FeedsConfig{
+			Enabled:         true,
+			IntervalMinutes: 60,
+			MaxItems:        5,
+		},
+		Monitors: MonitorsConfig{
+			Enabled:             true,
+			IntervalMinutes:     5,
+			FailuresBeforeAlert: 2,
+			TimeoutSeconds:      15,
+		},
+		Contacts: ContactsConfig{
+			Enabled:      true,
+			BirthdayHour: 9,
+		},
+		Expenses: ExpensesConfig{
+			Enabled:       true,
+			Currency:      "USD",
+			AlertPercent:  80,
+			MonthlyReport: true,
+			ReportHour:    9,
+		},
+		Bookmarks: BookmarksConfig{
+			Enabled:      true,
+			Summarize:    true,
+			WeeklyDigest: true,
+			DigestDay:    "sunday",
+			DigestHour:   18,
+		},
+		Digest: DigestConfig{
+			Period:  "daily",
+			Time:    "08:00",
+			Weekday: "monday",
+			Channel: "telegram",
+		},
+		Approvals: ApprovalsConfig{
+			Channel:     "telegram",
+			ExpireHours: 24,
+		},
+		Proactivity: ProactivityConfig{
+			MaxPerDay:      10,
+			QuietHours:     "22:00-07:00",
+			UrgentPrefixes: []string{"🚨", "[urgent]"},
+		},
+		Webhooks: WebhooksConfig{
+			Listen: "127.0.0.1:18790",
+		},
+		API: APIConfig{
+			Listen: "127.0.0.1:18791",
+			Model:  "mclaw",
+		},
+		Inbox: InboxConfig{
+			Enabled:       true,
+			RetentionDays: 7,
+		},
+		Watches: WatchesConfig{IntervalSeconds: 10},
+		Storage: StorageConfig{
+			Enabled:         true,
+			IntervalMinutes: 60,
+			Limits: StorageLimits{
+				Media: StorageLimit{MaxMB: 500, MaxAgeDays: 3},
+				Inbox: StorageLimit{MaxMB: 2048},
+				Logs:  StorageLimit{MaxMB: 100, MaxAgeDays: 30},
+			},
+		},
+		Skills: SkillsConfig{
+			Registry: "sipeed/mclaw-skills",
+		},
+		Secrets: SecretsConfig{
+			Keyring: true,
+		},
+		Auth: AuthConfig{
+			Roles: map[string]RoleConfig{
+				"owner": {},
+				"guest": {
+					Tools:         []string{"web_search", "web_fetch", "describe_image", "scratchpad", "pin", "tasks"},
+					DailyMessages: 50,
+					DailyTokens:   200000,
+					PerMinute:     5,
+				},
+			},
+		},
+		Health: HealthConfig{
+			StuckMinutes: 15,
+		},
 	}
 }
 
+// LoadConfig reads a JSON, YAML or TOML config (chosen by extension) and
+// the files it includes over the defaults, then applies env overrides. If
+// path does not exist, a sibling in another format is used; if none exists
+// the defaults are returned.
 func LoadConfig(path string) (*Config, error) {
+	cfg, err := LoadConfigUnresolved(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadConfigUnresolved is LoadConfig without resolving "secret:NAME"
+// references, for commands that manage the secrets themselves.
+func LoadConfigUnresolved(path string) (*Config, error) {
+	path = ResolvePath(path)
 	cfg := DefaultConfig()
+	cfg.path = path
 
-	data, err := os.ReadFile(path)
+	tree, files, err := readConfigFiles(path)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if os.IsNotExist(err) && len(files) == 0 {
 			return cfg, nil
 		}
 		return nil, err
 	}
+	for _, f := range files {
+		cfg.files = append(cfg.files, f.path)
+	}
 
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
 	if err := json.Unmarshal(data, cfg); err != nil {
-		return nil, err
+		return nil, describeJSONError(nil, err)
 	}
 
 	if err := env.Parse(cfg); err != nil {
@@ -203,10 +905,19 @@ func SaveConfig(path string, cfg *Config) error {
 	cfg.mu.RLock()
 	defer cfg.mu.RUnlock()
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := json.Marshal(cfg)
 	if err != nil {
 		return err
 	}
+	// Write secret:NAME references back rather than the values they resolved to
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	restoreSecretRefs(tree, cfg.refs)
+	if data, err = json.MarshalIndent(tree, "", "  "); err != nil {
+		return err
+	}
 
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -216,12 +927,215 @@ func SaveConfig(path string, cfg *Config) error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// Validate reports settings that would keep mclaw from starting or a
+// channel from connecting. Every problem is included in the error.
+func (c *Config) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var errs []error
+	if c.Agents.Defaults.Model == "" {
+		errs = append(errs, fmt.Errorf("agents.defaults.model is not set"))
+	}
+	p := c.Providers
+	hasKey := false
+	for _, pc := range []ProviderConfig{p.Anthropic, p.OpenAI, p.OpenRouter, p.Groq, p.Zhipu, p.VLLM, p.Gemini} {
+		hasKey = hasKey || pc.APIKey != ""
+	}
+	if !hasKey && p.VLLM.APIBase == "" && !c.DryRun {
+		errs = append(errs, fmt.Errorf("no provider API key is configured"))
+	}
+	if c.Agents.Defaults.MaxToolIterations <= 0 {
+		errs = append(errs, fmt.Errorf("agents.defaults.max_tool_iterations must be positive"))
+	}
+
+	ch := c.Channels
+	if ch.Telegram.Enabled && ch.Telegram.Token == "" {
+		errs = append(errs, fmt.Errorf("channels.telegram is enabled but has no token"))
+	}
+	if ch.Discord.Enabled && ch.Discord.Token == "" {
+		errs = append(errs, fmt.Errorf("channels.discord is enabled but has no token"))
+	}
+	if ch.Feishu.Enabled && (ch.Feishu.AppID == "" || ch.Feishu.AppSecret == "") {
+		errs = append(errs, fmt.Errorf("channels.feishu is enabled but app_id or app_secret is missing"))
+	}
+	if ch.WhatsApp.Enabled && ch.WhatsApp.BridgeURL == "" {
+		errs = append(errs, fmt.Errorf("channels.whatsapp is enabled but has no bridge_url"))
+	}
+	for name, format := range ch.Format {
+		if !markdown.Known(markdown.Format(format)) {
+			errs = append(errs, fmt.Errorf("channels.format.%s: unknown format %q", name, format))
+		}
+	}
+	for name, members := range ch.Recipients {
+		if len(members) == 0 {
+			errs = append(errs, fmt.Errorf("channels.recipients.%s is empty", name))
+		}
+		for _, m := range members {
+			channel, chatID, _ := strings.Cut(m, ":")
+			if channel == "" || chatID == "" || channel == "group" {
+				errs = append(errs, fmt.Errorf("channels.recipients.%s: %q is not a \"channel:chat_id\"", name, m))
+			}
+		}
+	}
+	if c.Tools.Email.Host != "" && c.Tools.Email.Username == "" {
+		errs = append(errs, fmt.Errorf("tools.email.host is set but username is missing"))
+	}
+	if n := len(c.Tools.AskModels.Models); n > 3 {
+		errs = append(errs, fmt.Errorf("tools.ask_models.models lists %d models, the limit is 3", n))
+	}
+	if c.Watches.Enabled {
+		seen := make(map[string]bool)
+		for _, w := range c.Watches.Paths {
+			switch {
+			case w.Name == "" || w.Path == "":
+				errs = append(errs, fmt.Errorf("watches.paths: every watch needs a name and a path"))
+			case seen[w.Name]:
+				errs = append(errs, fmt.Errorf("watches.paths: duplicate name %q", w.Name))
+			}
+			seen[w.Name] = true
+		}
+		if c.Watches.IntervalSeconds < 1 {
+			errs = append(errs, fmt.Errorf("watches.interval_seconds must be at least 1"))
+		}
+	}
+	switch c.Tools.Market.StocksProvider {
+	case "", "yahoo", "tcbs":
+	default:
+		errs = append(errs, fmt.Errorf("tools.market.stocks_provider must be yahoo or tcbs, got %q", c.Tools.Market.StocksProvider))
+	}
+	if c.Monitors.Enabled && c.Monitors.RepeatAlertMinutes < 0 {
+		errs = append(errs, fmt.Errorf("monitors.repeat_alert_minutes must not be negative"))
+	}
+	if c.Contacts.BirthdayHour < 0 || c.Contacts.BirthdayHour > 23 {
+		errs = append(errs, fmt.Errorf("contacts.birthday_hour must be between 0 and 23"))
+	}
+	if c.Expenses.AlertPercent < 0 || c.Expenses.AlertPercent > 99 {
+		errs = append(errs, fmt.Errorf("expenses.alert_percent must be between 0 and 99"))
+	}
+	if c.Expenses.ReportHour < 0 || c.Expenses.ReportHour > 23 {
+		errs = append(errs, fmt.Errorf("expenses.report_hour must be between 0 and 23"))
+	}
+	if c.Bookmarks.DigestHour < 0 || c.Bookmarks.DigestHour > 23 {
+		errs = append(errs, fmt.Errorf("bookmarks.digest_hour must be between 0 and 23"))
+	}
+	if c.OCR.Enabled {
+		switch c.OCR.Engine {
+		case "", "auto", "tesseract", "vision":
+		default:
+			errs = append(errs, fmt.Errorf("ocr.engine must be auto, tesseract or vision, got %q", c.OCR.Engine))
+		}
+		if c.OCR.Engine == "vision" && c.Agents.Defaults.VisionModel == "" {
+			errs = append(errs, fmt.Errorf("ocr.engine vision needs agents.defaults.vision_model"))
+		}
+	}
+	if c.Proactivity.Enabled {
+		if c.Proactivity.MaxPerDay < 0 {
+			errs = append(errs, fmt.Errorf("proactivity.max_per_day must not be negative"))
+		}
+		if c.Proactivity.QuietHours != "" {
+			if _, _, err := proactivity.ParseQuietHours(c.Proactivity.QuietHours); err != nil {
+				errs = append(errs, fmt.Errorf("proactivity.quiet_hours: %w", err))
+			}
+		}
+	}
+	if c.Webhooks.Enabled {
+		seen := make(map[string]bool)
+		for _, h := range c.Webhooks.Hooks {
+			switch {
+			case h.Name == "" || strings.ContainsAny(h.Name, "/?#"):
+				errs = append(errs, fmt.Errorf("webhooks.hooks: invalid name %q", h.Name))
+			case seen[h.Name]:
+				errs = append(errs, fmt.Errorf("webhooks.hooks: duplicate name %q", h.Name))
+			case h.Secret == "":
+				errs = append(errs, fmt.Errorf("webhooks.hooks %q has no secret", h.Name))
+			}
+			seen[h.Name] = true
+		}
+	}
+	chains := c.Agents.Defaults.FallbackChains()
+	for _, class := range []string{"rate_limit", "timeout", "content_filter"} {
+		if chains[class].CooldownMinutes < 0 {
+			errs = append(errs, fmt.Errorf("agents.defaults.fallbacks.%s.cooldown_minutes must not be negative", class))
+		}
+	}
+	if c.Budget.DailyUSD < 0 {
+		errs = append(errs, fmt.Errorf("budget.daily_usd must not be negative"))
+	}
+	if c.Approvals.Enabled && c.Approvals.ChatID == "" && c.OwnerChat(c.Approvals.Channel) == "" {
+		errs = append(errs, fmt.Errorf("approvals is enabled but has no chat_id and channels.%s has no allow_from user", c.Approvals.Channel))
+	}
+	if c.API.Enabled && len(c.API.Keys) == 0 {
+		errs = append(errs, fmt.Errorf("api is enabled but has no keys"))
+	}
+	return errors.Join(errs...)
+}
+
+// OwnerChat returns the first allow_from user of a channel, whose private
+// chat with the bot has the same ID on Telegram and WhatsApp.
+func (c *Config) OwnerChat(channel string) string {
+	var allow []string
+	switch channel {
+	case "telegram":
+		allow = c.Channels.Telegram.AllowFrom
+	case "discord":
+		allow = c.Channels.Discord.AllowFrom
+	case "feishu":
+		allow = c.Channels.Feishu.AllowFrom
+	case "whatsapp":
+		allow = c.Channels.WhatsApp.AllowFrom
+	}
+	if len(allow) == 0 {
+		return ""
+	}
+	id, _, _ := strings.Cut(allow[0], "|")
+	return id
+}
+
+// ResolveModel returns the model an alias in agents.defaults.models stands
+// for, or name itself when it is not an alias.
+func (c *Config) ResolveModel(name string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if model, ok := c.Agents.Defaults.Models[name]; ok {
+		return model
+	}
+	return name
+}
+
 func (c *Config) WorkspacePath() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return expandPath(c.Agents.Defaults.Workspace)
 }
 
+// BrowserProfileDir returns the persistent browser profile directory, or ""
+// when the browser should start from a fresh profile each time.
+func (c *Config) BrowserProfileDir() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.Tools.Browser.Persistent {
+		return ""
+	}
+	if c.Tools.Browser.ProfileDir != "" {
+		return expandPath(c.Tools.Browser.ProfileDir)
+	}
+	return filepath.Join(filepath.Dir(expandPath(c.Agents.Defaults.Workspace)), "browser_profile")
+}
+
+// Project returns the named project with its path expanded.
+func (c *Config) Project(name string) (ProjectConfig, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, p := range c.Projects {
+		if p.Name == name {
+			p.Path = expandPath(p.Path)
+			return p, true
+		}
+	}
+	return ProjectConfig{}, false
+}
+
 func (c *Config) GetAPIKey() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -249,6 +1163,43 @@ func (c *Config) GetAPIKey() string {
 	return ""
 }
 
+// SecretValues returns every credential in the config (API keys, tokens,
+// passwords, http secrets and MCLAW_SECRET_* variables) so they can be
+// redacted from tool output, logs and sessions.
+func (c *Config) SecretValues() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	values := []string{
+		c.Providers.Anthropic.APIKey, c.Providers.OpenAI.APIKey, c.Providers.OpenRouter.APIKey,
+		c.Providers.Groq.APIKey, c.Providers.Zhipu.APIKey, c.Providers.VLLM.APIKey, c.Providers.Gemini.APIKey,
+		c.Channels.Telegram.Token, c.Channels.Discord.Token,
+		c.Channels.Feishu.AppSecret, c.Channels.Feishu.EncryptKey, c.Channels.Feishu.VerificationToken,
+		c.Tools.Web.Search.APIKey, c.Tools.Email.Password, c.Tools.GitHub.Token, c.Tools.Market.CoinGeckoAPIKey,
+		c.Memory.APIKey, c.TTS.APIKey, c.STT.APIKey,
+	}
+	for _, v := range c.Tools.HTTP.Secrets {
+		values = append(values, v)
+	}
+	for _, h := range c.Webhooks.Hooks {
+		values = append(values, h.Secret)
+	}
+	values = append(values, c.API.Keys...)
+	for _, kv := range os.Environ() {
+		if name, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(name, "MCLAW_SECRET_") {
+			values = append(values, v)
+		}
+	}
+
+	out := values[:0]
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func (c *Config) GetAPIBase() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
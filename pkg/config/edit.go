@@ -0,0 +1,478 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keys are dotted JSON paths into the config, e.g. "agents.defaults.model",
+// "channels.telegram.allow_from" or "projects.0.path". They are checked
+// against the Config struct, so typos are caught before anything is written.
+
+// GetValue returns the effective value of key (file, defaults and env
+// overrides combined).
+func GetValue(cfg *Config, key string) (interface{}, error) {
+	if _, err := keyType(key); err != nil {
+		return nil, err
+	}
+	cfg.mu.RLock()
+	data, err := json.Marshal(cfg)
+	cfg.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	node := tree
+	for _, seg := range strings.Split(key, ".") {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			node = n[seg]
+		case []interface{}:
+			i, _ := strconv.Atoi(seg)
+			if i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("%s: index %d out of range (%d items)", key, i, len(n))
+			}
+			node = n[i]
+		default:
+			return nil, nil // inside a map entry that is not set
+		}
+	}
+	return node, nil
+}
+
+// SetValue parses raw according to the type of key and writes it to the
+// config file at path. The result must still decode into a Config.
+func SetValue(path, key, raw string) error {
+	t, err := keyType(key)
+	if err != nil {
+		return err
+	}
+	value, err := parseValue(t, raw)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	path = ResolvePath(path)
+	tree, err := readTree(path)
+	if err != nil {
+		return err
+	}
+	segs := strings.Split(key, ".")
+	updated, err := setPath(tree, segs, value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return writeTree(path, updated.(map[string]interface{}))
+}
+
+// UnsetValue removes key from the config file so its default applies again.
+func UnsetValue(path, key string) error {
+	if _, err := keyType(key); err != nil {
+		return err
+	}
+	path = ResolvePath(path)
+	tree, err := readTree(path)
+	if err != nil {
+		return err
+	}
+
+	segs := strings.Split(key, ".")
+	var node interface{} = tree
+	for _, seg := range segs[:len(segs)-1] {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			node = n[seg]
+		case []interface{}:
+			i, _ := strconv.Atoi(seg)
+			if i >= len(n) {
+				return fmt.Errorf("%s is not set", key)
+			}
+			node = n[i]
+		default:
+			return fmt.Errorf("%s is not set", key)
+		}
+	}
+	last := segs[len(segs)-1]
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if _, set := n[last]; !set {
+			return fmt.Errorf("%s is not set", key)
+		}
+		delete(n, last)
+	case []interface{}:
+		return fmt.Errorf("%s cannot be unset; set the list instead", key)
+	default:
+		return fmt.Errorf("%s is not set", key)
+	}
+	return writeTree(path, tree)
+}
+
+// ValidateFile checks that the config at path and its includes parse, have
+// no unknown keys or mistyped values, that every secret reference resolves,
+// and that together they pass Validate.
+func ValidateFile(path string) error {
+	path = ResolvePath(path)
+	tree, files, err := readConfigFiles(path)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := strictDecode(f.tree, f.data, DefaultConfig()); err != nil {
+			return fmt.Errorf("%s: %w", f.path, err)
+		}
+	}
+	cfg := DefaultConfig()
+	cfg.path = path
+	if err := strictDecode(tree, nil, cfg); err != nil {
+		return err
+	}
+	if err := cfg.resolveSecrets(); err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// strictDecode decodes tree into cfg, rejecting unknown keys. When data is
+// the JSON source of tree it is decoded directly, so errors carry line
+// numbers.
+func strictDecode(tree map[string]interface{}, data []byte, cfg *Config) error {
+	source := data
+	if _, hasInclude := tree["include"]; hasInclude || !json.Valid(data) {
+		stripped := make(map[string]interface{}, len(tree))
+		for k, v := range tree {
+			if k != "include" {
+				stripped[k] = v
+			}
+		}
+		var err error
+		if data, err = json.Marshal(stripped); err != nil {
+			return err
+		}
+		source = nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return describeJSONError(source, err)
+	}
+	return nil
+}
+
+// describeJSONError turns decoder errors into messages with a line number
+// and, for unknown keys, a suggestion. data is the decoded document; pass
+// nil when it was generated and line numbers would mislead.
+func describeJSONError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := lineCol(data, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %v", line, col, syntaxErr)
+	case errors.As(err, &typeErr):
+		msg := fmt.Sprintf("%s must be %s, not %s", typeErr.Field, describeType(typeErr.Type), typeErr.Value)
+		if data == nil {
+			return errors.New(msg)
+		}
+		line, _ := lineCol(data, typeErr.Offset)
+		return fmt.Errorf("line %d: %s", line, msg)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		msg := fmt.Sprintf("unknown key %q", field)
+		if s := suggest(field, allKeyNames(reflect.TypeOf(Config{}))); s != "" {
+			msg += fmt.Sprintf(" (did you mean %q?)", s)
+		}
+		return errors.New(msg)
+	}
+	return err
+}
+
+func lineCol(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// keyType resolves a dotted key against the Config struct.
+func keyType(key string) (reflect.Type, error) {
+	if key == "" {
+		return nil, fmt.Errorf("empty key")
+	}
+	t := reflect.TypeOf(Config{})
+	var walked []string
+	for _, seg := range strings.Split(key, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := jsonField(t, seg)
+			if !ok {
+				msg := fmt.Sprintf("unknown key %q", strings.Join(append(walked, seg), "."))
+				if s := suggest(seg, fieldNames(t)); s != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", strings.Join(append(walked, s), "."))
+				} else {
+					msg += "; valid keys here: " + strings.Join(fieldNames(t), ", ")
+				}
+				return nil, errors.New(msg)
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Slice:
+			if i, err := strconv.Atoi(seg); err != nil || i < 0 {
+				return nil, fmt.Errorf("%s is a list; use a numeric index, e.g. %s.0", strings.Join(walked, "."), strings.Join(walked, "."))
+			}
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("%s is %s and has no key %q", strings.Join(walked, "."), describeType(t), seg)
+		}
+		walked = append(walked, seg)
+	}
+	return t, nil
+}
+
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.IsExported() && jsonName(f) == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+func fieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() && jsonName(f) != "-" {
+			names = append(names, jsonName(f))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// allKeyNames collects field names at every depth, for suggestions.
+func allKeyNames(t reflect.Type) []string {
+	seen := make(map[reflect.Type]bool)
+	var names []string
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || seen[t] {
+			return
+		}
+		seen[t] = true
+		for _, name := range fieldNames(t) {
+			names = append(names, name)
+			f, _ := jsonField(t, name)
+			walk(f.Type)
+		}
+	}
+	walk(t)
+	return names
+}
+
+// suggest returns the candidate closest to s, if it is a plausible typo.
+func suggest(s string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(s), c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// parseValue converts a command-line string to a JSON value of type t.
+// Lists of strings also accept comma-separated values.
+func parseValue(t reflect.Type, raw string) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", raw)
+		}
+		return b, nil
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected a whole number, got %q", raw)
+		}
+		return n, nil
+	case reflect.Float64, reflect.Float32:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", raw)
+		}
+		return f, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			list := []interface{}{}
+			for _, part := range strings.Split(raw, ",") {
+				if part = strings.TrimSpace(part); part != "" {
+					list = append(list, part)
+				}
+			}
+			return list, nil
+		}
+	}
+
+	// Structs, maps and other lists take JSON, checked against the type
+	var v interface{}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return nil, fmt.Errorf("expected JSON for %s: %v", describeType(t), err)
+	}
+	if err := json.Unmarshal([]byte(raw), reflect.New(t).Interface()); err != nil {
+		return nil, fmt.Errorf("value does not fit %s: %v", describeType(t), err)
+	}
+	return v, nil
+}
+
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
+
+// setPath stores value at segs inside node, creating objects and
+// appending to lists as needed.
+func setPath(node interface{}, segs []string, value interface{}) (interface{}, error) {
+	if len(segs) == 0 {
+		return value, nil
+	}
+	seg := segs[0]
+	if i, err := strconv.Atoi(seg); err == nil {
+		list, _ := node.([]interface{})
+		if i > len(list) {
+			return nil, fmt.Errorf("index %d is past the end of the list (%d items)", i, len(list))
+		}
+		var child interface{}
+		if i < len(list) {
+			child = list[i]
+		}
+		v, err := setPath(child, segs[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		if i == len(list) {
+			return append(list, v), nil
+		}
+		list[i] = v
+		return list, nil
+	}
+
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		m = make(map[string]interface{})
+	}
+	v, err := setPath(m[seg], segs[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	m[seg] = v
+	return m, nil
+}
+
+func readTree(path string) (map[string]interface{}, error) {
+	if ext := filepath.Ext(path); ext != ".json" && ext != "" {
+		return nil, fmt.Errorf("only JSON configs can be edited this way; edit %s directly", path)
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make(map[string]interface{}), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tree := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) == 0 {
+		return tree, nil
+	}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("%s is not valid JSON (%v); fix it or run mclaw config validate", path, describeJSONError(data, err))
+	}
+	return tree, nil
+}
+
+// writeTree checks that the edited file still decodes into a Config, then
+// replaces the file atomically, keeping its permissions.
+func writeTree(path string, tree map[string]interface{}) error {
+	data, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := strictDecode(tree, data, DefaultConfig()); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
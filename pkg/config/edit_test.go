@@ -0,0 +1,239 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const editBase = `{
+  "agents": {"defaults": {"model": "gpt-4o-mini", "max_tokens": 1024}},
+  "channels": {"telegram": {"enabled": false, "allow_from": ["1"]}}
+}`
+
+// lookup follows a dotted key through a decoded JSON tree.
+func lookup(node interface{}, key string) interface{} {
+	for _, seg := range strings.Split(key, ".") {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			node = n[seg]
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i >= len(n) {
+				return nil
+			}
+			node = n[i]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		key  string
+		raw  string
+		want interface{}
+		err  string
+	}{
+		{key: "agents.defaults.model", raw: "gpt-4o", want: "gpt-4o"},
+		{key: "agents.defaults.max_tokens", raw: "4096", want: float64(4096)},
+		{key: "agents.defaults.temperature", raw: "0.2", want: 0.2},
+		{key: "channels.telegram.enabled", raw: "true", want: true},
+		{key: "channels.telegram.allow_from", raw: "1, 2,,3", want: []interface{}{"1", "2", "3"}},
+		{key: "channels.telegram.allow_from", raw: `["a,b"]`, want: []interface{}{"a,b"}},
+		{key: "channels.telegram.allow_from.1", raw: "2", want: "2"},
+		{key: "projects.0.path", raw: "/src/app", want: "/src/app"},
+		{key: "channels.discord", raw: `{"enabled": true, "token": "secret:discord"}`, want: map[string]interface{}{"enabled": true, "token": "secret:discord"}},
+		{key: "agents.defaults.max_tokens", raw: "lots", err: `expected a whole number, got "lots"`},
+		{key: "agents.defaults.temperature", raw: "warm", err: "expected a number"},
+		{key: "channels.telegram.enabled", raw: "yes please", err: "expected true or false"},
+		{key: "channels.discord", raw: `{"enabled": "yes"}`, err: "value does not fit an object"},
+		{key: "projects.2.path", raw: "/src", err: "index 2 is past the end of the list (0 items)"},
+		{key: "agents.defaults.modle", raw: "x", err: `did you mean "agents.defaults.model"`},
+		{key: "channels.telegram.allow_from.first", raw: "x", err: "channels.telegram.allow_from is a list; use a numeric index"},
+		{key: "agents.defaults.model.name", raw: "x", err: "agents.defaults.model is a string and has no key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.raw, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(editBase), 0600); err != nil {
+				t.Fatal(err)
+			}
+			err := SetValue(path, tt.key, tt.raw)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+				}
+				if data, _ := os.ReadFile(path); string(data) != editBase {
+					t.Errorf("expected the file untouched after an error, got:\n%s", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tree, err := readTree(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lookup(tree, tt.key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+			// Everything else in the file is kept
+			if tt.key != "agents.defaults.model" && lookup(tree, "agents.defaults.model") != "gpt-4o-mini" {
+				t.Errorf("expected sibling keys kept, got %v", tree)
+			}
+			if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+				t.Errorf("expected permissions kept, got %v", info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestUnsetValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(editBase), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UnsetValue(path, "agents.defaults.max_tokens"); err != nil {
+		t.Fatal(err)
+	}
+	tree, _ := readTree(path)
+	defaults := lookup(tree, "agents.defaults").(map[string]interface{})
+	if _, ok := defaults["max_tokens"]; ok || defaults["model"] != "gpt-4o-mini" {
+		t.Errorf("expected only max_tokens removed, got %v", defaults)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Agents.Defaults.MaxTokens; got != DefaultConfig().Agents.Defaults.MaxTokens {
+		t.Errorf("expected the default to apply again, got %d", got)
+	}
+
+	if err := UnsetValue(path, "channels.telegram"); err != nil {
+		t.Fatal(err)
+	}
+	tree, _ = readTree(path)
+	if channels := lookup(tree, "channels").(map[string]interface{}); len(channels) != 0 {
+		t.Errorf("expected the whole section removed, got %v", channels)
+	}
+
+	for key, want := range map[string]string{
+		"agents.defaults.max_tokens":     "agents.defaults.max_tokens is not set",
+		"channels.discord.token":         "channels.discord.token is not set",
+		"projects.0.path":                "projects.0.path is not set",
+		"agents.defaults.model.x":        "has no key",
+		"agents.defaults.nope":           `unknown key "agents.defaults.nope"`,
+		"channels.telegram.allow_from.0": "channels.telegram.allow_from.0 is not set",
+		"projects.3.path":                "projects.3.path is not set",
+	} {
+		if err := UnsetValue(path, key); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("unset %s: expected an error containing %q, got %v", key, want, err)
+		}
+	}
+}
+
+func TestUnsetInsideLists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"projects": [{"name": "app", "path": "/src/app", "tools": ["exec"]}]}`), 0600)
+
+	if err := UnsetValue(path, "projects.0.tools"); err != nil {
+		t.Fatal(err)
+	}
+	tree, _ := readTree(path)
+	if project := lookup(tree, "projects.0").(map[string]interface{}); len(project) != 2 || project["name"] != "app" {
+		t.Errorf("expected only tools removed from the project, got %v", project)
+	}
+	if err := UnsetValue(path, "projects.0"); err == nil || !strings.Contains(err.Error(), "cannot be unset; set the list instead") {
+		t.Errorf("expected list items to be refused, got %v", err)
+	}
+}
+
+func TestEditKeepsSecretsAndIncludes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	extra := filepath.Join(dir, "channels.json")
+	extraSrc := `{"channels": {"telegram": {"enabled": true, "token": "123:abc"}}}`
+	if err := os.WriteFile(extra, []byte(extraSrc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	main := `{
+  "include": "channels.json",
+  "providers": {"openai": {"api_key": "secret:openai"}},
+  "agents": {"defaults": {"model": "gpt-4o-mini"}}
+}`
+	if err := os.WriteFile(path, []byte(main), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MCLAW_SECRET_OPENAI", "sk-live")
+
+	if err := SetValue(path, "agents.defaults.model", "gpt-4o"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetValue(path, "providers.groq.api_key", "secret:groq"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnsetValue(path, "agents.defaults.model"); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := readTree(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree["include"] != "channels.json" {
+		t.Errorf("expected the include kept, got %v", tree["include"])
+	}
+	if _, inlined := tree["channels"]; inlined {
+		t.Errorf("expected the included file not to be copied in, got %v", tree["channels"])
+	}
+	if got := lookup(tree, "providers.openai.api_key"); got != "secret:openai" {
+		t.Errorf("expected the reference kept, got %v", got)
+	}
+	if got := lookup(tree, "providers.groq.api_key"); got != "secret:groq" {
+		t.Errorf("expected a new reference stored as written, got %v", got)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "sk-live") {
+		t.Errorf("expected no resolved secret in the file, got:\n%s", data)
+	}
+	if data, _ := os.ReadFile(extra); string(data) != extraSrc {
+		t.Errorf("expected the included file untouched, got:\n%s", data)
+	}
+
+	// get shows the effective value, includes and secrets resolved
+	t.Setenv("MCLAW_SECRET_GROQ", "gsk-live")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{
+		"channels.telegram.token":  "123:abc",
+		"providers.openai.api_key": "sk-live",
+		"agents.defaults.model":    DefaultConfig().Agents.Defaults.Model,
+	} {
+		if got, err := GetValue(cfg, key); err != nil || got != want {
+			t.Errorf("get %s: expected %v, got %v, %v", key, want, got, err)
+		}
+	}
+	if err := ValidateFile(path); err != nil {
+		t.Errorf("expected the edited config to validate, got %v", err)
+	}
+}
+
+func TestEditRefusesTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte("[agents.defaults]\nmodel = \"gpt-4o\"\n"), 0600)
+	if err := SetValue(path, "agents.defaults.model", "x"); err == nil || !strings.Contains(err.Error(), "only JSON configs can be edited") {
+		t.Errorf("expected TOML edits to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configExts are the config formats LoadConfig reads, in the order
+// ResolvePath tries them.
+var configExts = []string{".json", ".yaml", ".yml", ".toml"}
+
+// ResolvePath returns path if it exists, otherwise the first sibling with
+// the same base name and another supported extension, so a default of
+// config.json finds config.yaml or config.toml.
+func ResolvePath(path string) string {
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, ext := range configExts {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return path
+}
+
+// configFile is one file read while loading, the main config or an include.
+type configFile struct {
+	path string
+	data []byte
+	tree map[string]interface{}
+}
+
+// readConfigFiles reads path and, recursively, the files named by its
+// top-level "include" key (a path or list of paths, relative to the
+// including file, globs allowed). Keys in a file override the same keys
+// from its includes, and later includes override earlier ones. The merged
+// tree has no include keys.
+func readConfigFiles(path string) (map[string]interface{}, []configFile, error) {
+	var files []configFile
+	tree, err := readConfigInto(path, map[string]bool{}, &files)
+	return tree, files, err
+}
+
+func readConfigInto(path string, visiting map[string]bool, files *[]configFile) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if visiting[abs] {
+		return nil, fmt.Errorf("%s includes itself", path)
+	}
+	visiting[abs] = true
+	defer delete(visiting, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := decodeConfig(path, data)
+	if err != nil {
+		return nil, err
+	}
+	*files = append(*files, configFile{path: path, data: data, tree: tree})
+
+	includes, err := includePaths(path, tree["include"])
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]interface{})
+	for _, inc := range includes {
+		warnIfShared(inc)
+		sub, err := readConfigInto(inc, visiting, files)
+		if err != nil {
+			return nil, fmt.Errorf("include %s: %w", inc, err)
+		}
+		mergeTree(merged, sub)
+	}
+	own := make(map[string]interface{}, len(tree))
+	for k, v := range tree {
+		if k != "include" {
+			own[k] = v
+		}
+	}
+	mergeTree(merged, own)
+	return merged, nil
+}
+
+// decodeConfig parses a config file by its extension; anything that is
+// not YAML or TOML is read as JSON.
+func decodeConfig(path string, data []byte) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) == 0 {
+		return tree, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if tree == nil {
+			tree = make(map[string]interface{})
+		}
+	case ".toml":
+		t, err := parseTOML(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		tree = t
+	default:
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, describeJSONError(data, err))
+		}
+	}
+	return tree, nil
+}
+
+func includePaths(from string, value interface{}) ([]string, error) {
+	var names []string
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		names = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: include must be a path or a list of paths", from)
+			}
+			names = append(names, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s: include must be a path or a list of paths", from)
+	}
+
+	var paths []string
+	for _, name := range names {
+		if strings.HasPrefix(name, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				name = filepath.Join(home, name[2:])
+			}
+		}
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(from), name)
+		}
+		if !strings.ContainsAny(name, "*?[") {
+			paths = append(paths, name)
+			continue
+		}
+		matches, err := filepath.Glob(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: include %s: %w", from, name, err)
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// mergeTree copies src into dst, merging nested objects key by key. Other
+// values, lists included, are replaced.
+func mergeTree(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		dstMap, ok2 := dst[k].(map[string]interface{})
+		if ok && ok2 {
+			mergeTree(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// warnIfShared flags included files that other users can read. Includes
+// usually hold the API keys and tokens split out of the main config.
+func warnIfShared(path string) {
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0077 == 0 {
+		return
+	}
+	log.Printf("[config] Warning: %s is readable by other users (mode %04o); run chmod 600 %s", path, info.Mode().Perm(), path)
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// The OS keyring is reached through the platform's command-line client:
+// secret-tool (libsecret) on Linux and security on macOS. Secrets are
+// stored under the service "mclaw" with the secret name as the account.
+const keyringService = "mclaw"
+
+const keyringTimeout = 10 * time.Second
+
+func keyringTool() string {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		return "secret-tool"
+	case "darwin":
+		return "security"
+	}
+	return ""
+}
+
+// KeyringAvailable reports whether the OS keyring client is installed.
+func KeyringAvailable() bool {
+	tool := keyringTool()
+	if tool == "" {
+		return false
+	}
+	_, err := exec.LookPath(tool)
+	return err == nil
+}
+
+// KeyringGet reads a secret from the OS keyring.
+func KeyringGet(name string) (string, error) {
+	var args []string
+	if keyringTool() == "security" {
+		args = []string{"find-generic-password", "-s", keyringService, "-a", name, "-w"}
+	} else {
+		args = []string{"lookup", "service", keyringService, "account", name}
+	}
+	out, err := runKeyring(nil, args...)
+	if err != nil {
+		// Both tools exit non-zero without output when nothing matches
+		if out == "" {
+			return "", ErrSecretNotFound
+		}
+		return "", err
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+// KeyringSet stores a secret in the OS keyring, replacing any old value.
+func KeyringSet(name, value string) error {
+	if keyringTool() == "security" {
+		// security only takes the password as an argument
+		_, err := runKeyring(nil, "add-generic-password", "-U", "-s", keyringService, "-a", name, "-w", value)
+		return err
+	}
+	_, err := runKeyring(strings.NewReader(value), "store", "--label", "mclaw "+name, "service", keyringService, "account", name)
+	return err
+}
+
+// KeyringDelete removes a secret from the OS keyring.
+func KeyringDelete(name string) error {
+	if keyringTool() == "security" {
+		_, err := runKeyring(nil, "delete-generic-password", "-s", keyringService, "-a", name)
+		return err
+	}
+	_, err := runKeyring(nil, "clear", "service", keyringService, "account", name)
+	return err
+}
+
+func runKeyring(stdin *strings.Reader, args ...string) (string, error) {
+	if !KeyringAvailable() {
+		return "", fmt.Errorf("no OS keyring client found (install secret-tool from libsecret)")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), keyringTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, keyringTool(), args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("keyring: %s", msg)
+		}
+		return stdout.String(), fmt.Errorf("keyring: %w", err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// A profile is a named, self-contained instance: its own config file and
+// workspace under profiles/<name> next to the executable, so several bots
+// can run on one machine without sharing sessions, memory or skills.
+const (
+	ProfileEnv = "MCLAW_PROFILE" // selects a profile, like --profile
+	ConfigEnv  = "MCLAW_CONFIG"  // explicit config file, like --config
+	DryRunEnv  = "MCLAW_DRY_RUN" // sets dry_run, like --dry-run
+)
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// Profile returns the active profile name, or "" for the default instance.
+func Profile() string {
+	return os.Getenv(ProfileEnv)
+}
+
+// ValidateProfile checks that name is usable as a directory name.
+func ValidateProfile(name string) error {
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+// ProfileDir returns the directory holding a profile's config and data.
+func ProfileDir(name string) string {
+	return expandPath("./profiles/" + name)
+}
+
+// DefaultPath returns the config file to use: MCLAW_CONFIG if set, else
+// the active profile's config.json, else fallback.
+func DefaultPath(fallback string) string {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return expandPath(path)
+	}
+	if name := Profile(); name != "" && ValidateProfile(name) == nil {
+		return filepath.Join(ProfileDir(name), "config.json")
+	}
+	return fallback
+}
+
+// defaultWorkspace keeps each profile's data apart when its config does
+// not name a workspace.
+func defaultWorkspace() string {
+	if name := Profile(); name != "" && ValidateProfile(name) == nil {
+		return "./profiles/" + name + "/workspace"
+	}
+	return "./mclaw/workspace"
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ReloadHook is called after a reload with the settings that changed, so
+// components that cached a value can pick up the new one.
+type ReloadHook func(cfg *Config, changed []string)
+
+// reloadable lists settings that are safe to change while running. Anything
+// else (tokens, providers, enabled channels, paths) needs a restart.
+var reloadable = []struct {
+	name string
+	get  func(c *Config) interface{}
+	set  func(dst, src *Config)
+}{
+	{"agents.defaults.model", func(c *Config) interface{} { return c.Agents.Defaults.Model },
+		func(d, s *Config) { d.Agents.Defaults.Model = s.Agents.Defaults.Model }},
+	{"agents.defaults.fallback_models", func(c *Config) interface{} { return c.Agents.Defaults.FallbackModels },
+		func(d, s *Config) { d.Agents.Defaults.FallbackModels = s.Agents.Defaults.FallbackModels }},
+	{"agents.defaults.fallbacks", func(c *Config) interface{} { return c.Agents.Defaults.Fallbacks },
+		func(d, s *Config) { d.Agents.Defaults.Fallbacks = s.Agents.Defaults.Fallbacks }},
+	{"agents.defaults.models", func(c *Config) interface{} { return c.Agents.Defaults.Models },
+		func(d, s *Config) { d.Agents.Defaults.Models = s.Agents.Defaults.Models }},
+	{"agents.defaults.temperature", func(c *Config) interface{} { return c.Agents.Defaults.Temperature },
+		func(d, s *Config) { d.Agents.Defaults.Temperature = s.Agents.Defaults.Temperature }},
+	{"agents.defaults.max_tokens", func(c *Config) interface{} { return c.Agents.Defaults.MaxTokens },
+		func(d, s *Config) { d.Agents.Defaults.MaxTokens = s.Agents.Defaults.MaxTokens }},
+	{"agents.defaults.max_tool_iterations", func(c *Config) interface{} { return c.Agents.Defaults.MaxToolIterations },
+		func(d, s *Config) { d.Agents.Defaults.MaxToolIterations = s.Agents.Defaults.MaxToolIterations }},
+	{"agents.defaults.long_message_chars", func(c *Config) interface{} { return c.Agents.Defaults.LongMessageChars },
+		func(d, s *Config) { d.Agents.Defaults.LongMessageChars = s.Agents.Defaults.LongMessageChars }},
+	{"agents.defaults.coalesce_ms", func(c *Config) interface{} { return c.Agents.Defaults.CoalesceMs },
+		func(d, s *Config) { d.Agents.Defaults.CoalesceMs = s.Agents.Defaults.CoalesceMs }},
+	{"channels.telegram.allow_from", func(c *Config) interface{} { return c.Channels.Telegram.AllowFrom },
+		func(d, s *Config) { d.Channels.Telegram.AllowFrom = s.Channels.Telegram.AllowFrom }},
+	{"channels.discord.allow_from", func(c *Config) interface{} { return c.Channels.Discord.AllowFrom },
+		func(d, s *Config) { d.Channels.Discord.AllowFrom = s.Channels.Discord.AllowFrom }},
+	{"channels.feishu.allow_from", func(c *Config) interface{} { return c.Channels.Feishu.AllowFrom },
+		func(d, s *Config) { d.Channels.Feishu.AllowFrom = s.Channels.Feishu.AllowFrom }},
+	{"channels.whatsapp.allow_from", func(c *Config) interface{} { return c.Channels.WhatsApp.AllowFrom },
+		func(d, s *Config) { d.Channels.WhatsApp.AllowFrom = s.Channels.WhatsApp.AllowFrom }},
+	{"channels.recipients", func(c *Config) interface{} { return c.Channels.Recipients },
+		func(d, s *Config) { d.Channels.Recipients = s.Channels.Recipients }},
+	{"tools.policy", func(c *Config) interface{} { return c.Tools.Policy },
+		func(d, s *Config) { d.Tools.Policy = s.Tools.Policy }},
+	{"memory.top_k", func(c *Config) interface{} { return c.Memory.TopK },
+		func(d, s *Config) { d.Memory.TopK = s.Memory.TopK }},
+	{"memory.min_score", func(c *Config) interface{} { return c.Memory.MinScore },
+		func(d, s *Config) { d.Memory.MinScore = s.Memory.MinScore }},
+	{"memory.max_memories", func(c *Config) interface{} { return c.Memory.MaxMemories },
+		func(d, s *Config) { d.Memory.MaxMemories = s.Memory.MaxMemories }},
+	{"usage", func(c *Config) interface{} { return c.Usage },
+		func(d, s *Config) { d.Usage = s.Usage }},
+	{"budget", func(c *Config) interface{} { return c.Budget },
+		func(d, s *Config) { d.Budget = s.Budget }},
+	{"model_info", func(c *Config) interface{} { return c.ModelInfo },
+		func(d, s *Config) { d.ModelInfo = s.ModelInfo }},
+	{"projects", func(c *Config) interface{} { return c.Projects },
+		func(d, s *Config) { d.Projects = s.Projects }},
+	{"secrets.redact", func(c *Config) interface{} { return c.Secrets.Redact },
+		func(d, s *Config) { d.Secrets.Redact = s.Secrets.Redact }},
+	{"digest", func(c *Config) interface{} { return c.Digest },
+		func(d, s *Config) { d.Digest = s.Digest }},
+	{"auth", func(c *Config) interface{} { return c.Auth },
+		func(d, s *Config) { d.Auth = s.Auth }},
+}
+
+// Path returns the file the config was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// OnReload registers a hook that runs after reloadable settings change.
+func (c *Config) OnReload(hook ReloadHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook)
+}
+
+// Apply copies the reloadable settings of next into c. It returns the
+// settings that changed and the sections that differ but only take effect
+// after a restart.
+func (c *Config) Apply(next *Config) (changed, restart []string) {
+	c.mu.Lock()
+	for _, r := range reloadable {
+		if !reflect.DeepEqual(r.get(c), r.get(next)) {
+			r.set(c, next)
+			changed = append(changed, r.name)
+		}
+	}
+	// Whatever still differs is not reloadable
+	cur, _ := sections(c)
+	nxt, _ := sections(next)
+	for name, data := range nxt {
+		if !bytes.Equal(cur[name], data) {
+			restart = append(restart, name)
+		}
+	}
+	hooks := append([]ReloadHook(nil), c.hooks...)
+	c.mu.Unlock()
+
+	sort.Strings(restart)
+	if len(changed) > 0 {
+		for _, hook := range hooks {
+			hook(c, changed)
+		}
+	}
+	return changed, restart
+}
+
+// sections flattens the config two levels deep ("channels.telegram") to
+// JSON, for naming what changed.
+func sections(c *Config) (map[string][]byte, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, err
+	}
+	out := make(map[string][]byte)
+	for name, raw := range top {
+		var sub map[string]json.RawMessage
+		if json.Unmarshal(raw, &sub) != nil {
+			out[name] = raw
+			continue
+		}
+		for key, v := range sub {
+			out[name+"."+key] = v
+		}
+	}
+	return out, nil
+}
+
+// Reload re-reads the config file and environment and applies the safe
+// changes. An invalid file is rejected and the running config kept.
+func (c *Config) Reload() error {
+	next, err := LoadConfig(c.path)
+	if err != nil {
+		return err
+	}
+	if err := next.Validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.files = next.files
+	c.mu.Unlock()
+	changed, restart := c.Apply(next)
+	if len(changed) > 0 {
+		log.Printf("[config] Reloaded: %v", changed)
+	}
+	if len(restart) > 0 {
+		log.Printf("[config] Changes to %v take effect after a restart", restart)
+	}
+	return nil
+}
+
+// Watch reloads the config when its file changes (checked every interval)
+// or the process receives SIGHUP, until ctx is cancelled.
+func (c *Config) Watch(ctx context.Context, interval time.Duration) {
+	if c.path == "" {
+		return
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := c.stamp()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Printf("[config] SIGHUP received, reloading %s", c.path)
+		case <-ticker.C:
+			stamp := c.stamp()
+			if stamp == last {
+				continue
+			}
+			last = stamp
+		}
+		if err := c.Reload(); err != nil {
+			log.Printf("[config] Reload failed, keeping the running config: %v", err)
+		}
+	}
+}
+
+// stamp changes whenever the config file or one of its includes does.
+func (c *Config) stamp() string {
+	c.mu.RLock()
+	files := c.files
+	c.mu.RUnlock()
+	if len(files) == 0 {
+		files = []string{c.path}
+	}
+	stamps := make([]string, len(files))
+	for i, f := range files {
+		stamps[i] = fileStamp(f)
+	}
+	return strings.Join(stamps, ",")
+}
+
+func fileStamp(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", info.ModTime().UnixNano(), info.Size())
+}
@@ -0,0 +1,382 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Any string in the config can be a reference of the form "secret:NAME"
+// instead of the value itself. References are resolved when the config is
+// loaded, from, in order:
+//
+//  1. the environment variable MCLAW_SECRET_<NAME>
+//  2. the encrypted secrets file (secrets.file), unlocked with the
+//     passphrase in MCLAW_SECRETS_PASSPHRASE
+//  3. the OS keyring (secrets.keyring), under the service "mclaw"
+//
+// so API keys and bot tokens never have to sit in the config as plaintext.
+const secretPrefix = "secret:"
+
+// SecretPassphraseEnv holds the passphrase for the encrypted secrets file.
+const SecretPassphraseEnv = "MCLAW_SECRETS_PASSPHRASE"
+
+var secretName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ErrSecretNotFound is returned when a secret is in none of the stores.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// secretRef records a reference that was resolved, so SaveConfig can write
+// the reference back instead of the value.
+type secretRef struct {
+	path  []string // JSON path of the field
+	ref   string
+	value string
+}
+
+// SecretsFilePath returns the encrypted secrets file for this config.
+func (c *Config) SecretsFilePath() string {
+	if c.Secrets.File != "" {
+		return expandPath(c.Secrets.File)
+	}
+	dir := "."
+	if c.path != "" {
+		dir = filepath.Dir(c.path)
+	}
+	return filepath.Join(dir, "secrets.enc")
+}
+
+// LookupSecret finds a secret by name in the configured stores.
+func (c *Config) LookupSecret(name string) (string, error) {
+	return (&secretSource{cfg: c}).lookup(name)
+}
+
+// secretSource looks up secrets, decrypting the secrets file at most once.
+type secretSource struct {
+	cfg     *Config
+	file    map[string]string
+	fileErr error
+	loaded  bool
+}
+
+func (s *secretSource) lookup(name string) (string, error) {
+	envName := "MCLAW_SECRET_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	if v := os.Getenv(envName); v != "" {
+		return v, nil
+	}
+
+	if !s.loaded {
+		s.file, s.fileErr = ReadSecretsFile(s.cfg.SecretsFilePath(), os.Getenv(SecretPassphraseEnv))
+		s.loaded = true
+	}
+	if v, ok := s.file[name]; ok {
+		return v, nil
+	}
+
+	if s.cfg.Secrets.Keyring && KeyringAvailable() {
+		v, err := KeyringGet(name)
+		if err == nil {
+			return v, nil
+		}
+		if !errors.Is(err, ErrSecretNotFound) {
+			return "", err
+		}
+	}
+	if s.fileErr != nil {
+		return "", s.fileErr // it may be in the file we could not open
+	}
+	return "", ErrSecretNotFound
+}
+
+// resolveSecrets replaces every "secret:NAME" string in the config with the
+// secret's value. The secrets file is decrypted at most once.
+func (c *Config) resolveSecrets() error {
+	var refs []secretRef
+	var problems []error
+	cache := make(map[string]string)
+	source := &secretSource{cfg: c}
+
+	walkStrings(reflect.ValueOf(c).Elem(), nil, func(path []string, get func() string, set func(string)) {
+		ref := get()
+		if !strings.HasPrefix(ref, secretPrefix) {
+			return
+		}
+		name := strings.TrimPrefix(ref, secretPrefix)
+		if !secretName.MatchString(name) {
+			problems = append(problems, fmt.Errorf("%s: invalid secret name %q", strings.Join(path, "."), name))
+			return
+		}
+		value, ok := cache[name]
+		if !ok {
+			var err error
+			if value, err = source.lookup(name); err != nil {
+				if errors.Is(err, ErrSecretNotFound) {
+					err = fmt.Errorf("secret %q is not set (add it with: mclaw secrets set %s)", name, name)
+				}
+				problems = append(problems, fmt.Errorf("%s: %w", strings.Join(path, "."), err))
+				return
+			}
+			cache[name] = value
+		}
+		set(value)
+		refs = append(refs, secretRef{path: path, ref: ref, value: value})
+	})
+
+	c.refs = refs
+	return errors.Join(problems...)
+}
+
+// walkStrings calls fn for every string field, map value and list item
+// under v, with its JSON path.
+func walkStrings(v reflect.Value, path []string, fn func(path []string, get func() string, set func(string))) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			fn(path, v.String, v.SetString)
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			walkStrings(v.Elem(), path, fn)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || jsonName(f) == "-" {
+				continue
+			}
+			walkStrings(v.Field(i), appendPath(path, jsonName(f)), fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkStrings(v.Index(i), appendPath(path, fmt.Sprint(i)), fn)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			elem := v.MapIndex(key)
+			if elem.Kind() != reflect.String {
+				continue
+			}
+			m, k := v, key
+			fn(appendPath(path, key.String()), elem.String, func(s string) {
+				m.SetMapIndex(k, reflect.ValueOf(s).Convert(elem.Type()))
+			})
+		}
+	}
+}
+
+func appendPath(path []string, seg string) []string {
+	out := make([]string, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, seg)
+}
+
+// restoreSecretRefs puts references back into a marshaled config wherever
+// the resolved value is unchanged.
+func restoreSecretRefs(tree map[string]interface{}, refs []secretRef) {
+	for _, r := range refs {
+		var node interface{} = tree
+		for _, seg := range r.path[:len(r.path)-1] {
+			switch n := node.(type) {
+			case map[string]interface{}:
+				node = n[seg]
+			case []interface{}:
+				var i int
+				fmt.Sscan(seg, &i)
+				if i >= len(n) {
+					node = nil
+					break
+				}
+				node = n[i]
+			default:
+				node = nil
+			}
+		}
+		last := r.path[len(r.path)-1]
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if n[last] == r.value {
+				n[last] = r.ref
+			}
+		case []interface{}:
+			var i int
+			fmt.Sscan(last, &i)
+			if i < len(n) && n[i] == r.value {
+				n[i] = r.ref
+			}
+		}
+	}
+}
+
+// secretsFile is the on-disk form of the encrypted secrets file: a JSON
+// object of name → value, sealed with AES-256-GCM under a key derived from
+// the passphrase with PBKDF2-SHA256.
+type secretsFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+const secretsKDFIterations = 600000
+
+// ReadSecretsFile decrypts the secrets file at path. A missing file reads
+// as empty.
+func ReadSecretsFile(path, passphrase string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("%s is encrypted; set %s to unlock it", path, SecretPassphraseEnv)
+	}
+	var f secretsFile
+	if err := json.Unmarshal(raw, &f); err != nil || f.Version != 1 {
+		return nil, fmt.Errorf("%s is not an mclaw secrets file", path)
+	}
+	gcm, err := secretsCipher(passphrase, f.Salt, f.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt %s: wrong passphrase or corrupted file", path)
+	}
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// WriteSecretsFile encrypts secrets to path with a fresh salt and nonce.
+func WriteSecretsFile(path, passphrase string, secrets map[string]string) error {
+	if passphrase == "" {
+		return fmt.Errorf("a passphrase is required; set %s", SecretPassphraseEnv)
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	f := secretsFile{Version: 1, Iterations: secretsKDFIterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+	gcm, err := secretsCipher(passphrase, f.Salt, f.Iterations)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func secretsCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("invalid secrets file")
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretFieldNames are the config fields that hold credentials.
+var secretFieldNames = []string{"api_key", "token", "app_secret", "encrypt_key", "verification_token", "password"}
+
+// MigrateSecrets moves plaintext credentials out of the JSON config at
+// path: each is handed to store under a name derived from its key (e.g.
+// providers_openai_api_key) and replaced with a reference. It returns the
+// names moved.
+func MigrateSecrets(path string, store func(name, value string) error) ([]string, error) {
+	path = ResolvePath(path)
+	tree, err := readTree(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var moved []string
+	var walk func(node map[string]interface{}, path []string, all bool) error
+	walk = func(node map[string]interface{}, path []string, all bool) error {
+		keys := make([]string, 0, len(node))
+		for k := range node {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := appendPath(path, k)
+			switch v := node[k].(type) {
+			case map[string]interface{}:
+				// Every value under tools.http.secrets is a credential
+				if err := walk(v, p, strings.Join(p, ".") == "tools.http.secrets"); err != nil {
+					return err
+				}
+			case string:
+				if v == "" || strings.HasPrefix(v, secretPrefix) || (!all && !containsName(secretFieldNames, k)) {
+					continue
+				}
+				name := strings.NewReplacer("-", "_", ".", "_").Replace(strings.Join(p, "_"))
+				if err := store(name, v); err != nil {
+					return fmt.Errorf("%s: %w", strings.Join(p, "."), err)
+				}
+				node[k] = secretPrefix + name
+				moved = append(moved, name)
+			}
+		}
+		return nil
+	}
+	if err := walk(tree, nil, false); err != nil {
+		return nil, err
+	}
+	if len(moved) == 0 {
+		return nil, nil
+	}
+	return moved, writeTree(path, tree)
+}
+
+func containsName(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
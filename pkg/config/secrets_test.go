@@ -0,0 +1,218 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSecretsFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	secrets := map[string]string{"providers_openai_api_key": "sk-test", "HA_TOKEN": "ha-123"}
+	if err := WriteSecretsFile(path, "correct horse", secrets); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "sk-test") {
+		t.Fatal("expected the file not to hold values in plaintext")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected the secrets file to be private, got %v", info.Mode().Perm())
+	}
+
+	got, err := ReadSecretsFile(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["providers_openai_api_key"] != "sk-test" || got["HA_TOKEN"] != "ha-123" {
+		t.Errorf("unexpected secrets %v", got)
+	}
+
+	if _, err := ReadSecretsFile(path, "wrong horse"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("expected a wrong passphrase error, got %v", err)
+	}
+	if _, err := ReadSecretsFile(path, ""); err == nil || !strings.Contains(err.Error(), SecretPassphraseEnv) {
+		t.Errorf("expected to be told to set the passphrase, got %v", err)
+	}
+	if got, err := ReadSecretsFile(filepath.Join(t.TempDir(), "missing.enc"), ""); err != nil || len(got) != 0 {
+		t.Errorf("expected a missing file to read as empty, got %v, %v", got, err)
+	}
+}
+
+func TestSecretsFileTampered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	if err := WriteSecretsFile(path, "pass", map[string]string{"token": "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(path)
+	var f secretsFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		t.Fatal(err)
+	}
+	f.Data[len(f.Data)/2] ^= 0x01
+	raw, _ = json.Marshal(f)
+	os.WriteFile(path, raw, 0600)
+
+	if _, err := ReadSecretsFile(path, "pass"); err == nil || !strings.Contains(err.Error(), "corrupted") {
+		t.Errorf("expected tampering to be detected, got %v", err)
+	}
+	os.WriteFile(path, []byte(`{"hello": "world"}`), 0600)
+	if _, err := ReadSecretsFile(path, "pass"); err == nil || !strings.Contains(err.Error(), "not an mclaw secrets file") {
+		t.Errorf("expected a foreign file to be refused, got %v", err)
+	}
+}
+
+// fakeKeyring puts a secret-tool on PATH that keeps secrets as files in a
+// temporary directory.
+func fakeKeyring(t *testing.T, secrets map[string]string) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("the fake keyring stands in for secret-tool")
+	}
+	bin := t.TempDir()
+	store := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+lookup) [ -f "` + store + `/$5" ] && cat "` + store + `/$5" || exit 1 ;;
+store) cat > "` + store + `/$7" ;;
+clear) rm -f "` + store + `/$5" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "secret-tool"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	for name, value := range secrets {
+		if err := KeyringSet(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSecretResolutionOrder(t *testing.T) {
+	dir := t.TempDir()
+	fakeKeyring(t, map[string]string{"a": "keyring-a", "b": "keyring-b", "c": "keyring-c"})
+	t.Setenv(SecretPassphraseEnv, "pass")
+	if err := WriteSecretsFile(filepath.Join(dir, "secrets.enc"), "pass", map[string]string{"a": "file-a", "b": "file-b"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MCLAW_SECRET_A", "env-a")
+
+	path := filepath.Join(dir, "config.json")
+	config := `{
+		"secrets": {"keyring": true},
+		"providers": {"openai": {"api_key": "secret:a"}, "groq": {"api_key": "secret:b"}},
+		"channels": {"telegram": {"token": "secret:c"}}
+	}`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The environment wins, then the encrypted file, then the keyring
+	if got := cfg.Providers.OpenAI.APIKey; got != "env-a" {
+		t.Errorf("expected the env var first, got %q", got)
+	}
+	if got := cfg.Providers.Groq.APIKey; got != "file-b" {
+		t.Errorf("expected the secrets file before the keyring, got %q", got)
+	}
+	if got := cfg.Channels.Telegram.Token; got != "keyring-c" {
+		t.Errorf("expected the keyring last, got %q", got)
+	}
+	if _, err := cfg.LookupSecret("d"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound, got %v", err)
+	}
+
+	// Saving writes the references back, not the values
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := os.ReadFile(path)
+	for _, value := range []string{"env-a", "file-b", "keyring-c"} {
+		if strings.Contains(string(saved), value) {
+			t.Errorf("expected %s not to be saved, got:\n%s", value, saved)
+		}
+	}
+	if !strings.Contains(string(saved), `"secret:c"`) {
+		t.Errorf("expected the references kept, got:\n%s", saved)
+	}
+}
+
+func TestUnresolvedSecret(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	os.WriteFile(path, []byte(`{"providers": {"openai": {"api_key": "secret:nowhere"}}, "tools": {"github": {"token": "secret:bad name"}}}`), 0600)
+
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), `secret "nowhere" is not set`) || !strings.Contains(err.Error(), `invalid secret name "bad name"`) {
+		t.Errorf("expected both references reported, got %v", err)
+	}
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{
+		"providers": {"openai": {"api_key": "sk-plain", "api_base": "https://api.openai.com/v1"}, "groq": {"api_key": "secret:groq"}},
+		"channels": {"telegram": {"token": "123:abc", "allow_from": ["1"]}},
+		"tools": {"http": {"secrets": {"HA_TOKEN": "ha-123", "weather.key": "w-1"}}}
+	}`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := make(map[string]string)
+	moved, err := MigrateSecrets(path, func(name, value string) error {
+		stored[name] = value
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"channels_telegram_token":        "123:abc",
+		"providers_openai_api_key":       "sk-plain",
+		"tools_http_secrets_HA_TOKEN":    "ha-123",
+		"tools_http_secrets_weather_key": "w-1",
+	}
+	if len(moved) != len(want) || len(stored) != len(want) {
+		t.Fatalf("expected %d secrets moved, got %v", len(want), moved)
+	}
+	for name, value := range want {
+		if stored[name] != value {
+			t.Errorf("expected %s = %q stored, got %q", name, value, stored[name])
+		}
+	}
+
+	tree, err := readTree(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	providers := tree["providers"].(map[string]interface{})
+	openai := providers["openai"].(map[string]interface{})
+	if openai["api_key"] != "secret:providers_openai_api_key" || openai["api_base"] != "https://api.openai.com/v1" {
+		t.Errorf("expected only the key replaced by a reference, got %v", openai)
+	}
+	if groq := providers["groq"].(map[string]interface{}); groq["api_key"] != "secret:groq" {
+		t.Errorf("expected an existing reference left alone, got %v", groq)
+	}
+	secrets := tree["tools"].(map[string]interface{})["http"].(map[string]interface{})["secrets"].(map[string]interface{})
+	if secrets["HA_TOKEN"] != "secret:tools_http_secrets_HA_TOKEN" {
+		t.Errorf("expected every http secret moved, got %v", secrets)
+	}
+
+	// A second run has nothing left to move
+	if moved, err := MigrateSecrets(path, func(name, value string) error { return nil }); err != nil || len(moved) != 0 {
+		t.Errorf("expected nothing moved twice, got %v, %v", moved, err)
+	}
+}
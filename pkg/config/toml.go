@@ -0,0 +1,450 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseTOML decodes the subset of TOML a config file needs: tables, arrays
+// of tables, dotted and quoted keys, basic and literal strings, integers,
+// floats, booleans, arrays and inline tables. Dates are kept as strings and
+// multi-line strings are rejected. As TOML requires, a table is defined
+// only once: a second [table] header for it, or a header for a table
+// already made by dotted keys, is an error, and inline tables cannot be
+// extended.
+func parseTOML(src string) (map[string]interface{}, error) {
+	p := &tomlParser{src: src, line: 1, defined: make(map[uintptr]bool), inline: make(map[uintptr]bool)}
+	root := make(map[string]interface{})
+	current := root
+
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return root, nil
+		}
+		var err error
+		if p.peek() == '[' {
+			current, err = p.parseTableHeader(root)
+		} else {
+			err = p.parseKeyValue(current)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+	}
+}
+
+type tomlParser struct {
+	src     string
+	pos     int
+	line    int
+	defined map[uintptr]bool // tables a [header] may not define again
+	inline  map[uintptr]bool // inline tables, which nothing may add to
+}
+
+// tableID identifies a table for tomlParser.defined and inline.
+func tableID(t map[string]interface{}) uintptr {
+	return reflect.ValueOf(t).Pointer()
+}
+
+func (p *tomlParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) advance() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipBlank skips spaces and comments, and newlines too when multiline.
+func (p *tomlParser) skipBlank(multiline bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.advance()
+		case c == '\n' && multiline:
+			p.advance()
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.advance()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) endOfLine() error {
+	p.skipBlank(false)
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return fmt.Errorf("unexpected %q after value", p.peek())
+	}
+	p.advance()
+	return nil
+}
+
+func (p *tomlParser) expect(c byte) error {
+	if p.peek() != c {
+		if p.eof() {
+			return fmt.Errorf("expected %q, got end of file", c)
+		}
+		return fmt.Errorf("expected %q, got %q", c, p.peek())
+	}
+	p.advance()
+	return nil
+}
+
+// parseTableHeader handles [a.b] and [[a.b]] and returns the table that
+// following keys belong to.
+func (p *tomlParser) parseTableHeader(root map[string]interface{}) (map[string]interface{}, error) {
+	p.advance()
+	array := p.peek() == '['
+	if array {
+		p.advance()
+	}
+	p.skipBlank(false)
+	keys, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank(false)
+	if err := p.expect(']'); err != nil {
+		return nil, err
+	}
+	if array {
+		if err := p.expect(']'); err != nil {
+			return nil, err
+		}
+	}
+
+	parent, err := p.descend(root, keys[:len(keys)-1], false)
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	if array {
+		list, _ := parent[last].([]interface{})
+		if _, exists := parent[last]; exists && list == nil {
+			return nil, fmt.Errorf("%s is already defined and is not an array of tables", strings.Join(keys, "."))
+		}
+		if len(list) > 0 {
+			if last, ok := list[len(list)-1].(map[string]interface{}); !ok || p.inline[tableID(last)] {
+				return nil, fmt.Errorf("%s is already defined and is not an array of tables", strings.Join(keys, "."))
+			}
+		}
+		table := make(map[string]interface{})
+		p.defined[tableID(table)] = true
+		parent[last] = append(list, table)
+		return table, nil
+	}
+	switch v := parent[last].(type) {
+	case nil:
+		table := make(map[string]interface{})
+		p.defined[tableID(table)] = true
+		parent[last] = table
+		return table, nil
+	case map[string]interface{}:
+		if p.defined[tableID(v)] || p.inline[tableID(v)] {
+			return nil, fmt.Errorf("table %s is defined twice", strings.Join(keys, "."))
+		}
+		p.defined[tableID(v)] = true
+		return v, nil
+	}
+	return nil, fmt.Errorf("%s is already defined and is not a table", strings.Join(keys, "."))
+}
+
+// descend walks keys from table, creating tables as needed. An array of
+// tables resolves to its last element, as TOML specifies. Tables made by
+// the dotted keys of a key/value (byKey) count as defined, so no header
+// may define them later.
+func (p *tomlParser) descend(table map[string]interface{}, keys []string, byKey bool) (map[string]interface{}, error) {
+	for i, k := range keys {
+		switch v := table[k].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			if byKey {
+				p.defined[tableID(next)] = true
+			}
+			table[k] = next
+			table = next
+		case map[string]interface{}:
+			if p.inline[tableID(v)] {
+				return nil, fmt.Errorf("%s is an inline table and cannot be extended", strings.Join(keys[:i+1], "."))
+			}
+			table = v
+		case []interface{}:
+			if len(v) == 0 {
+				return nil, fmt.Errorf("%s is not a table", k)
+			}
+			last, ok := v[len(v)-1].(map[string]interface{})
+			if !ok || p.inline[tableID(last)] {
+				return nil, fmt.Errorf("%s is not a table", k)
+			}
+			table = last
+		default:
+			return nil, fmt.Errorf("%s is not a table", k)
+		}
+	}
+	return table, nil
+}
+
+func (p *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipBlank(false)
+	if err := p.expect('='); err != nil {
+		return err
+	}
+	p.skipBlank(false)
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	parent, err := p.descend(table, keys[:len(keys)-1], true)
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, exists := parent[last]; exists {
+		return fmt.Errorf("duplicate key %s", strings.Join(keys, "."))
+	}
+	parent[last] = value
+	return nil
+}
+
+// parseKey reads a possibly dotted key such as a."b.c".d.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipBlank(false)
+		var key string
+		var err error
+		switch c := p.peek(); {
+		case c == '"':
+			key, err = p.parseBasicString()
+		case c == '\'':
+			key, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.advance()
+			}
+			key = p.src[start:p.pos]
+			if key == "" {
+				return nil, fmt.Errorf("expected a key, got %q", c)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		p.skipBlank(false)
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.advance()
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '"':
+		return p.parseBasicString()
+	case c == '\'':
+		return p.parseLiteralString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case p.eof() || c == '\n':
+		return nil, fmt.Errorf("missing value")
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+		p.advance()
+	}
+	word := p.src[start:p.pos]
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	num := strings.ReplaceAll(word, "_", "")
+	if n, err := strconv.ParseInt(num, 0, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(num, 64); err == nil {
+		return f, nil
+	}
+	// Offset date-times and local dates are passed through as text
+	if len(word) >= 10 && word[4] == '-' && word[7] == '-' {
+		return word, nil
+	}
+	return nil, fmt.Errorf("invalid value %q (strings need quotes)", word)
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		return "", fmt.Errorf("multi-line strings are not supported")
+	}
+	p.advance()
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := p.advance()
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", fmt.Errorf("unterminated string")
+			}
+			switch e := p.advance(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case '"', '\\':
+				b.WriteByte(e)
+			case 'u', 'U':
+				size := 4
+				if e == 'U' {
+					size = 8
+				}
+				if p.pos+size > len(p.src) {
+					return "", fmt.Errorf("short unicode escape")
+				}
+				code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(code)) {
+					return "", fmt.Errorf("invalid unicode escape \\%c%s", e, p.src[p.pos:p.pos+size])
+				}
+				p.pos += size
+				b.WriteRune(rune(code))
+			default:
+				return "", fmt.Errorf("invalid escape \\%c", e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	if strings.HasPrefix(p.src[p.pos:], `'''`) {
+		return "", fmt.Errorf("multi-line strings are not supported")
+	}
+	p.advance()
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		if p.peek() == '\n' {
+			break
+		}
+		p.advance()
+	}
+	if p.eof() || p.peek() != '\'' {
+		return "", fmt.Errorf("unterminated string")
+	}
+	s := p.src[start:p.pos]
+	p.advance()
+	return s, nil
+}
+
+// parseArray reads [a, b, ...], which may span lines and hold comments.
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	p.advance()
+	list := []interface{}{}
+	for {
+		p.skipBlank(true)
+		if p.peek() == ']' {
+			p.advance()
+			return list, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		p.skipBlank(true)
+		if p.peek() == ',' {
+			p.advance()
+			continue
+		}
+		if err := p.expect(']'); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+}
+
+// parseInlineTable reads {a = 1, b.c = "x"} on a single line.
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	p.advance()
+	table := make(map[string]interface{})
+	p.skipBlank(false)
+	if p.peek() == '}' {
+		p.advance()
+		p.seal(table)
+		return table, nil
+	}
+	for {
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipBlank(false)
+		if p.peek() == ',' {
+			p.advance()
+			p.skipBlank(false)
+			continue
+		}
+		if err := p.expect('}'); err != nil {
+			return nil, err
+		}
+		p.seal(table)
+		return table, nil
+	}
+}
+
+// seal marks an inline table, and the tables its dotted keys made, as
+// closed to additions.
+func (p *tomlParser) seal(table map[string]interface{}) {
+	p.inline[tableID(table)] = true
+	for _, v := range table {
+		if t, ok := v.(map[string]interface{}); ok {
+			p.seal(t)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type tree = map[string]interface{}
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want tree
+		err  string
+	}{
+		{
+			name: "scalars and comments",
+			src:  "# settings\nname = \"mclaw\" # trailing\nport = 18_790\nratio = 0.75\nhex = 0x1F\nenabled = true\noff = false\nsince = 2026-01-02T03:04:05Z\n",
+			want: tree{"name": "mclaw", "port": int64(18790), "ratio": 0.75, "hex": int64(31), "enabled": true, "off": false, "since": "2026-01-02T03:04:05Z"},
+		},
+		{
+			name: "basic string escapes",
+			src:  `s = "tab\there \"quoted\" back\\slash\nline \u00e9 \U0001F600"`,
+			want: tree{"s": "tab\there \"quoted\" back\\slash\nline é 😀"},
+		},
+		{
+			name: "literal strings keep backslashes",
+			src:  `path = 'C:\Users\mclaw'`,
+			want: tree{"path": `C:\Users\mclaw`},
+		},
+		{
+			name: "arrays across lines with comments",
+			src:  "allow = [\n  \"1\", # owner\n  \"2\",\n]\nnested = [[1, 2], []]\n",
+			want: tree{"allow": []interface{}{"1", "2"}, "nested": []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}{}}},
+		},
+		{
+			name: "inline tables",
+			src:  "telegram = {enabled = true, allow = [\"1\"], limits.daily = 5}\nempty = {}\n",
+			want: tree{
+				"telegram": tree{"enabled": true, "allow": []interface{}{"1"}, "limits": tree{"daily": int64(5)}},
+				"empty":    tree{},
+			},
+		},
+		{
+			name: "dotted and quoted keys",
+			src:  "agents.defaults.model = \"gpt-4o\"\n\"a.b\".'c' = 1\nsite . \"x\" = 2\n",
+			want: tree{
+				"agents": tree{"defaults": tree{"model": "gpt-4o"}},
+				"a.b":    tree{"c": int64(1)},
+				"site":   tree{"x": int64(2)},
+			},
+		},
+		{
+			name: "tables and sub-tables in any order",
+			src:  "[channels.telegram]\nenabled = true\n[channels]\nmode = \"all\"\n[channels.discord]\nenabled = false\n",
+			want: tree{"channels": tree{
+				"telegram": tree{"enabled": true},
+				"mode":     "all",
+				"discord":  tree{"enabled": false},
+			}},
+		},
+		{
+			name: "arrays of tables",
+			src:  "[[webhooks.hooks]]\nname = \"a\"\n[webhooks.hooks.auth]\nsecret = \"x\"\n[[webhooks.hooks]]\nname = \"b\"\n[webhooks.hooks.auth]\nsecret = \"y\"\n",
+			want: tree{"webhooks": tree{"hooks": []interface{}{
+				tree{"name": "a", "auth": tree{"secret": "x"}},
+				tree{"name": "b", "auth": tree{"secret": "y"}},
+			}}},
+		},
+		{
+			name: "duplicate key",
+			src:  "a = 1\nb = 2\na = 3\n",
+			err:  "line 3: duplicate key a",
+		},
+		{
+			name: "duplicate dotted key",
+			src:  "[x]\ny.z = 1\ny.z = 2\n",
+			err:  "line 3: duplicate key y.z",
+		},
+		{
+			name: "table defined twice",
+			src:  "[tools]\na = 1\n\n[tools]\nb = 2\n",
+			err:  "line 4: table tools is defined twice",
+		},
+		{
+			name: "sub-table defined twice",
+			src:  "[a.b]\nx = 1\n[a]\n[a.b]\ny = 2\n",
+			err:  "line 4: table a.b is defined twice",
+		},
+		{
+			name: "table already made by dotted keys",
+			src:  "a.b.c = 1\n[a.b]\nd = 2\n",
+			err:  "line 2: table a.b is defined twice",
+		},
+		{
+			name: "header for an inline table",
+			src:  "a = {x = 1}\n[a]\ny = 2\n",
+			err:  "line 2: table a is defined twice",
+		},
+		{
+			name: "dotted key into an inline table",
+			src:  "a = {x = 1}\na.y = 2\n",
+			err:  "line 2: a is an inline table and cannot be extended",
+		},
+		{
+			name: "array of tables over a value",
+			src:  "a = 1\n[[a]]\n",
+			err:  "line 2: a is already defined and is not an array of tables",
+		},
+		{
+			name: "table over a value",
+			src:  "a = 1\n[a]\n",
+			err:  "line 2: a is already defined and is not a table",
+		},
+		{
+			name: "invalid escape",
+			src:  "x = 1\ns = \"bad \\q\"\n",
+			err:  `line 2: invalid escape \q`,
+		},
+		{
+			name: "unterminated string",
+			src:  "s = \"open\ny = 1\n",
+			err:  "line 1: unterminated string",
+		},
+		{
+			name: "bare string value",
+			src:  "\n\nmodel = gpt-4o\n",
+			err:  "line 3: invalid value \"gpt-4o\" (strings need quotes)",
+		},
+		{
+			name: "unclosed array reports where it ended",
+			src:  "a = [\n  1,\n  2\nb = 3\n",
+			err:  "line 4: expected ']'",
+		},
+		{
+			name: "garbage after a value",
+			src:  "a = 1 2\n",
+			err:  "line 1: unexpected '2' after value",
+		},
+		{
+			name: "multi-line strings are rejected",
+			src:  "s = \"\"\"\ntext\n\"\"\"\n",
+			err:  "line 1: multi-line strings are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML(tt.src)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package contacts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// birthdayLayouts are the accepted birthday spellings, with and without
+// a year. Numeric dates other than ISO are read day first.
+var birthdayLayouts = []struct {
+	layout  string
+	hasYear bool
+}{
+	{"2006-01-02", true},
+	{"01-02", false},
+	{"2/1/2006", true},
+	{"2/1", false},
+	{"2.1.2006", true},
+	{"January 2 2006", true},
+	{"January 2", false},
+	{"Jan 2 2006", true},
+	{"Jan 2", false},
+	{"2 January 2006", true},
+	{"2 January", false},
+	{"2 Jan 2006", true},
+	{"2 Jan", false},
+}
+
+// ParseBirthday normalizes a birthday to "2006-01-02", or "01-02" when
+// the year is not given. An empty string stays empty.
+func ParseBirthday(s string) (string, error) {
+	s = strings.Join(strings.Fields(strings.ReplaceAll(s, ",", " ")), " ")
+	if s == "" {
+		return "", nil
+	}
+	for _, l := range birthdayLayouts {
+		if l.hasYear {
+			if t, err := time.Parse(l.layout, s); err == nil {
+				if t.After(time.Now()) {
+					return "", fmt.Errorf("birthday %q is in the future", s)
+				}
+				return t.Format("2006-01-02"), nil
+			}
+			continue
+		}
+		// Parse without a year in a leap year, so Feb 29 is accepted
+		if t, err := time.Parse("2006 "+l.layout, "2000 "+s); err == nil {
+			return t.Format("01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid birthday %q: use YYYY-MM-DD or MM-DD", s)
+}
+
+// splitBirthday returns the month, day and year (0 if unknown) of a
+// normalized birthday.
+func splitBirthday(b string) (time.Month, int, int, bool) {
+	if t, err := time.Parse("2006-01-02", b); err == nil {
+		return t.Month(), t.Day(), t.Year(), true
+	}
+	if t, err := time.Parse("2006-01-02", "2000-"+b); err == nil {
+		return t.Month(), t.Day(), 0, true
+	}
+	return 0, 0, 0, false
+}
+
+// NextBirthday returns the first birthday greeting time after after, at
+// hour in after's location. Feb 29 birthdays are greeted on Feb 28 in
+// other years.
+func NextBirthday(birthday string, after time.Time, hour int) (time.Time, bool) {
+	month, day, _, ok := splitBirthday(birthday)
+	if !ok {
+		return time.Time{}, false
+	}
+	for year := after.Year(); ; year++ {
+		d := day
+		if month == time.February && day == 29 && !isLeap(year) {
+			d = 28
+		}
+		t := time.Date(year, month, d, hour, 0, 0, 0, after.Location())
+		if t.After(after) {
+			return t, true
+		}
+	}
+}
+
+// IsBirthday reports whether day is the contact's birthday.
+func IsBirthday(birthday string, day time.Time) bool {
+	next, ok := NextBirthday(birthday, day.AddDate(0, 0, -1), 0)
+	return ok && next.Year() == day.Year() && next.YearDay() == day.YearDay()
+}
+
+// Age returns how old the contact turns on their birthday in year, or 0
+// when the birth year is unknown.
+func Age(birthday string, year int) int {
+	if _, _, born, ok := splitBirthday(birthday); ok && born > 0 && year > born {
+		return year - born
+	}
+	return 0
+}
+
+// FormatBirthday renders a normalized birthday, e.g. "May 17, 1990" or
+// "May 17".
+func FormatBirthday(birthday string) string {
+	month, day, year, ok := splitBirthday(birthday)
+	switch {
+	case !ok:
+		return birthday
+	case year > 0:
+		return fmt.Sprintf("%s %d, %d", month, day, year)
+	default:
+		return fmt.Sprintf("%s %d", month, day)
+	}
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
@@ -0,0 +1,132 @@
+package contacts
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+func TestParseBirthday(t *testing.T) {
+	cases := map[string]string{
+		"1990-05-17":   "1990-05-17",
+		"05-17":        "05-17",
+		"17/5/1990":    "1990-05-17",
+		"17/05":        "05-17",
+		"May 17":       "05-17",
+		"May 17, 1990": "1990-05-17",
+		"17 May 1990":  "1990-05-17",
+		"02-29":        "02-29",
+		"":             "",
+	}
+	for in, want := range cases {
+		got, err := ParseBirthday(in)
+		if err != nil || got != want {
+			t.Errorf("ParseBirthday(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"13-45", "someday", "2999-01-01"} {
+		if _, err := ParseBirthday(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestNextBirthday(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2027, 5, 17, 10, 0, 0, 0, loc)
+	if next, _ := NextBirthday("1990-05-17", now, 9); !next.Equal(time.Date(2028, 5, 17, 9, 0, 0, 0, loc)) {
+		t.Errorf("expected next year once today's hour passed, got %s", next)
+	}
+	if next, _ := NextBirthday("05-17", now, 11); !next.Equal(time.Date(2027, 5, 17, 11, 0, 0, 0, loc)) {
+		t.Errorf("expected later today, got %s", next)
+	}
+	if next, _ := NextBirthday("2000-02-29", now, 9); !next.Equal(time.Date(2028, 2, 29, 9, 0, 0, 0, loc)) {
+		t.Errorf("expected Feb 29 in a leap year, got %s", next)
+	}
+	if next, _ := NextBirthday("02-29", time.Date(2026, 1, 1, 0, 0, 0, 0, loc), 9); !next.Equal(time.Date(2026, 2, 28, 9, 0, 0, 0, loc)) {
+		t.Errorf("expected Feb 28 in other years, got %s", next)
+	}
+	if !IsBirthday("1990-05-17", now) || IsBirthday("1990-05-18", now) {
+		t.Error("IsBirthday mismatch")
+	}
+	if Age("1990-05-17", 2027) != 37 || Age("05-17", 2027) != 0 {
+		t.Error("Age mismatch")
+	}
+}
+
+func TestRecallAndBirthdays(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "memory.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	var sent []string
+	svc := NewService(store, func(channel, chatID, content string) {
+		sent = append(sent, channel+":"+chatID+" "+content)
+	})
+	cs := cron.NewCronService(filepath.Join(dir, "jobs.json"), nil)
+	if err := svc.Attach(cs); err != nil {
+		t.Fatal(err)
+	}
+
+	lan, err := svc.Add("telegram:42", Contact{Name: "Lan", Aliases: []string{"em Lan", "sis"}, Relation: "sister",
+		Birthday: "1995-12-01", Notes: "loves orchids", Channel: "zalo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Add("telegram:42", Contact{Name: "lan"}); err == nil {
+		t.Error("expected a duplicate name to be refused")
+	}
+	if _, err := svc.Add("telegram:42", Contact{Name: "Minh Anh", Relation: "colleague"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Add("telegram:7", Contact{Name: "Lan"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := svc.Recall("telegram:42", "What should I get my sis for her birthday?"); len(got) != 1 || got[0].ID != lan.ID {
+		t.Errorf("expected the alias to recall Lan, got %+v", got)
+	}
+	if got := svc.Recall("telegram:42", "Planning dinner with Lan and minh anh."); len(got) != 2 {
+		t.Errorf("expected two contacts, got %d", len(got))
+	}
+	if got := svc.Recall("telegram:42", "The Landmark tower"); len(got) != 0 {
+		t.Errorf("expected whole-word matches only, got %+v", got)
+	}
+
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 || jobs[0].Payload.Kind != JobKind || lan.JobID != jobs[0].ID {
+		t.Fatalf("expected one birthday job for Lan, got %+v", jobs)
+	}
+
+	// Move the birthday to today so the greeting is due
+	today := time.Now().Format("01-02")
+	if lan, err = svc.Update("telegram:42", lan.ID, func(c *Contact) { c.Birthday = today }); err != nil {
+		t.Fatal(err)
+	}
+	job := cs.ListJobs(true)[0]
+	if _, err := svc.fire(&job); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || !strings.HasPrefix(sent[0], "telegram:42 ") || !strings.Contains(sent[0], "Lan's birthday") ||
+		!strings.Contains(sent[0], "orchids") {
+		t.Fatalf("expected a greeting in the owner's chat, got %q", sent)
+	}
+	if lan, _ = svc.Get("telegram:42", lan.ID); lan.LastGreeted != time.Now().Year() || lan.JobID == "" || lan.JobID == job.ID {
+		t.Errorf("expected the greeting to be recorded and next year's job scheduled, got %+v", lan)
+	}
+	if err := svc.Attach(cs); err != nil || len(sent) != 1 {
+		t.Errorf("expected no second greeting on restart, got %q (%v)", sent, err)
+	}
+
+	if _, err := svc.Remove("telegram:7", lan.ID); err == nil {
+		t.Error("expected other chats' contacts to be out of reach")
+	}
+	if _, err := svc.Remove("telegram:42", lan.ID); err != nil || len(cs.ListJobs(true)) != 0 {
+		t.Errorf("expected the contact and its job to be gone: %v", err)
+	}
+}
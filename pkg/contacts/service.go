@@ -0,0 +1,396 @@
+package contacts
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+// JobKind is the cron payload kind of birthday greetings.
+const JobKind = "birthday"
+
+// maxRecall caps how many contacts one message recalls into the prompt.
+const maxRecall = 5
+
+// NotifyFunc delivers a message to a chat.
+type NotifyFunc func(channel, chatID, content string)
+
+// Service adds and updates contacts, recalls them when they are mentioned
+// and greets their birthdays. Until a cron service is attached, birthdays
+// are stored but not scheduled.
+type Service struct {
+	store  *Store
+	notify NotifyFunc
+	mu     sync.Mutex // serializes changes to a contact and its job
+	cron   *cron.CronService
+	hour   int
+}
+
+// NewService creates a contact service that greets birthdays at 09:00.
+func NewService(store *Store, notify NotifyFunc) *Service {
+	return &Service{store: store, notify: notify, hour: 9}
+}
+
+// SetBirthdayHour sets the local hour birthdays are announced at.
+func (s *Service) SetBirthdayHour(hour int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hour >= 0 && hour < 24 {
+		s.hour = hour
+	}
+}
+
+// Attach registers the birthday handler with cs, greets birthdays that
+// are today but were missed while mclaw was not running, and makes sure
+// every birthday has a job.
+func (s *Service) Attach(cs *cron.CronService) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cron = cs
+	cs.SetKindHandler(JobKind, s.fire)
+
+	jobs := make(map[string]bool)
+	for _, job := range cs.ListJobs(true) {
+		if job.Payload.Kind == JobKind && job.State.NextRunAtMS != nil {
+			jobs[job.ID] = true
+		}
+	}
+
+	list, err := s.store.List("")
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	keep := make(map[string]bool)
+	for _, c := range list {
+		if c.Birthday == "" {
+			continue
+		}
+		if IsBirthday(c.Birthday, now) && now.Hour() >= s.hour && c.LastGreeted < now.Year() {
+			s.greet(c, now)
+		}
+		if !jobs[c.JobID] {
+			s.schedule(c, now)
+			if err := s.store.Save(c); err != nil {
+				log.Printf("[contacts] Failed to save contact #%d: %v", c.ID, err)
+			}
+		}
+		keep[c.JobID] = true
+	}
+	for _, job := range cs.ListJobs(true) {
+		if job.Payload.Kind == JobKind && !keep[job.ID] {
+			cs.RemoveJob(job.ID)
+		}
+	}
+	return nil
+}
+
+// Add validates and stores a contact for owner's chat and schedules its
+// birthday greeting.
+func (s *Service) Add(owner string, c Contact) (*Contact, error) {
+	if _, _, ok := strings.Cut(owner, ":"); !ok {
+		return nil, fmt.Errorf("contacts need a chat")
+	}
+	if err := normalize(&c); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.store.List(owner)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range existing {
+		if strings.EqualFold(e.Name, c.Name) {
+			return nil, fmt.Errorf("%s is already contact #%d; update it instead", e.Name, e.ID)
+		}
+	}
+
+	c.Owner = owner
+	if err := s.store.Add(&c); err != nil {
+		return nil, err
+	}
+	s.schedule(&c, time.Now())
+	return &c, s.store.Save(&c)
+}
+
+// Update applies change to one of owner's contacts, validates the result
+// and reschedules its birthday.
+func (s *Service) Update(owner string, id int64, change func(c *Contact)) (*Contact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.store.Get(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	birthday := c.Birthday
+	change(c)
+	c.ID, c.Owner = id, owner
+	if err := normalize(c); err != nil {
+		return nil, err
+	}
+	if c.Birthday != birthday {
+		c.LastGreeted = 0
+		s.schedule(c, time.Now())
+	}
+	return c, s.store.Save(c)
+}
+
+// Get returns one of owner's contacts.
+func (s *Service) Get(owner string, id int64) (*Contact, error) {
+	return s.store.Get(owner, id)
+}
+
+// List returns owner's contacts by name.
+func (s *Service) List(owner string) ([]*Contact, error) {
+	return s.store.List(owner)
+}
+
+// Search returns owner's contacts matching query in any text field.
+func (s *Service) Search(owner, query string) ([]*Contact, error) {
+	return s.store.Search(owner, query)
+}
+
+// Remove deletes one of owner's contacts and its birthday job.
+func (s *Service) Remove(owner string, id int64) (*Contact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.store.Get(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	s.unschedule(c)
+	return c, s.store.Delete(owner, id)
+}
+
+// Upcoming returns owner's contacts with a birthday within days, soonest
+// first.
+func (s *Service) Upcoming(owner string, now time.Time, days int) ([]*Contact, error) {
+	list, err := s.store.List(owner)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := make(map[int64]time.Time)
+	var upcoming []*Contact
+	for _, c := range list {
+		t, ok := NextBirthday(c.Birthday, today.Add(-time.Second), 0)
+		if ok && t.Sub(today) <= time.Duration(days)*24*time.Hour {
+			next[c.ID] = t
+			upcoming = append(upcoming, c)
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool { return next[upcoming[i].ID].Before(next[upcoming[j].ID]) })
+	return upcoming, nil
+}
+
+// Recall returns owner's contacts whose name or an alias appears in text
+// as whole words, for the prompt.
+func (s *Service) Recall(owner, text string) []*Contact {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	list, err := s.store.List(owner)
+	if err != nil {
+		log.Printf("[contacts] Recall failed: %v", err)
+		return nil
+	}
+	lower := strings.ToLower(text)
+	var found []*Contact
+	for _, c := range list {
+		for _, name := range c.Names() {
+			if mentions(lower, strings.ToLower(name)) {
+				found = append(found, c)
+				break
+			}
+		}
+		if len(found) == maxRecall {
+			break
+		}
+	}
+	return found
+}
+
+// mentions reports whether name occurs in text with no letter or digit
+// directly before or after it.
+func mentions(text, name string) bool {
+	if name == "" {
+		return false
+	}
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], name)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(name)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// fire is the cron handler for birthday jobs.
+func (s *Service) fire(job *cron.CronJob) (string, error) {
+	id, err := strconv.ParseInt(job.Payload.Message, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("bad birthday job: %q", job.Payload.Message)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.store.Get("", id)
+	if err != nil {
+		return "", err
+	}
+	if c.JobID != job.ID {
+		return fmt.Sprintf("birthday of contact #%d skipped", id), nil
+	}
+	now := time.Now()
+	if c.LastGreeted < now.Year() {
+		s.greet(c, now)
+	}
+	s.schedule(c, now)
+	if err := s.store.Save(c); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("greeted birthday of contact #%d", id), nil
+}
+
+// greet announces c's birthday to its owner. Caller must hold s.mu and
+// save c.
+func (s *Service) greet(c *Contact, now time.Time) {
+	c.LastGreeted = now.Year()
+	if s.notify == nil {
+		return
+	}
+	channel, chatID, _ := strings.Cut(c.Owner, ":")
+	s.notify(channel, chatID, GreetingText(c, now))
+	log.Printf("[contacts] Announced the birthday of contact #%d to %s", c.ID, c.Owner)
+}
+
+// GreetingText is the birthday announcement for c.
+func GreetingText(c *Contact, now time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🎂 **Today is %s's birthday**", c.Name)
+	if age := Age(c.Birthday, now.Year()); age > 0 {
+		fmt.Fprintf(&sb, " (turns %d)", age)
+	}
+	if c.Relation != "" {
+		sb.WriteString(" · " + c.Relation)
+	}
+	if c.Channel != "" {
+		sb.WriteString("\nReach them on: " + c.Channel)
+	}
+	if c.Notes != "" {
+		sb.WriteString("\nNotes: " + c.Notes)
+	}
+	return sb.String()
+}
+
+// schedule (re)creates the job of c's next birthday greeting. Caller must
+// hold s.mu.
+func (s *Service) schedule(c *Contact, now time.Time) {
+	if s.cron == nil {
+		return // scheduled by Attach
+	}
+	s.unschedule(c)
+	if c.Birthday == "" {
+		return
+	}
+	after := now
+	if c.LastGreeted >= now.Year() {
+		after = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
+	}
+	next, ok := NextBirthday(c.Birthday, after, s.hour)
+	if !ok {
+		return
+	}
+	channel, chatID, _ := strings.Cut(c.Owner, ":")
+	job, err := s.cron.AddOneShot(fmt.Sprintf("birthday of %s", c.Name), next, cron.CronPayload{
+		Kind:    JobKind,
+		Message: strconv.FormatInt(c.ID, 10),
+		Channel: channel,
+		To:      chatID,
+	})
+	if err != nil {
+		log.Printf("[contacts] Failed to schedule the birthday of contact #%d: %v", c.ID, err)
+		return
+	}
+	c.JobID = job.ID
+}
+
+func (s *Service) unschedule(c *Contact) {
+	if s.cron != nil && c.JobID != "" {
+		s.cron.RemoveJob(c.JobID)
+	}
+	c.JobID = ""
+}
+
+// normalize trims c's fields and validates its name and birthday.
+func normalize(c *Contact) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("contact name is empty")
+	}
+	var aliases []string
+	for _, a := range c.Aliases {
+		if a = strings.TrimSpace(strings.ReplaceAll(a, ",", " ")); a != "" && !strings.EqualFold(a, c.Name) {
+			aliases = append(aliases, a)
+		}
+	}
+	c.Aliases = aliases
+	c.Relation = strings.TrimSpace(c.Relation)
+	c.Notes = strings.TrimSpace(c.Notes)
+	c.Channel = strings.TrimSpace(c.Channel)
+	birthday, err := ParseBirthday(c.Birthday)
+	if err != nil {
+		return err
+	}
+	c.Birthday = birthday
+	return nil
+}
+
+// Describe renders a contact for listings and the prompt, e.g.
+// "#3 Lan (sister) · birthday May 17, 1990 · reach on zalo · likes orchids".
+func (c *Contact) Describe() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "#%d %s", c.ID, c.Name)
+	if len(c.Aliases) > 0 {
+		fmt.Fprintf(&sb, " aka %s", strings.Join(c.Aliases, ", "))
+	}
+	if c.Relation != "" {
+		fmt.Fprintf(&sb, " (%s)", c.Relation)
+	}
+	if c.Birthday != "" {
+		sb.WriteString(" · birthday " + FormatBirthday(c.Birthday))
+	}
+	if c.Channel != "" {
+		sb.WriteString(" · reach on " + c.Channel)
+	}
+	if c.Notes != "" {
+		sb.WriteString(" · " + c.Notes)
+	}
+	return sb.String()
+}
@@ -0,0 +1,231 @@
+// Package contacts keeps a contact book per conversation: the people the
+// user talks about, with relation, birthday, notes and how they prefer to
+// be reached. Contacts mentioned in a message are recalled into the
+// prompt, and birthdays are announced through one-shot cron jobs that
+// reschedule themselves every year.
+package contacts
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Contact is one person in the contact book. Owner is the session key of
+// the conversation it was added in ("channel:chat_id"), which is also
+// where birthday greetings go.
+type Contact struct {
+	ID          int64
+	Owner       string
+	Name        string
+	Aliases     []string // nicknames that also recall the contact, e.g. "mom"
+	Relation    string   // e.g. "sister", "colleague"
+	Birthday    string   // "2006-01-02", or "01-02" when the year is unknown
+	Notes       string
+	Channel     string // preferred way to reach them, e.g. "zalo" or "telegram:12345"
+	JobID       string // cron job of the next birthday greeting
+	LastGreeted int    // year of the last birthday greeting
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Names returns the name and aliases, the spellings a message can use to
+// mention the contact.
+func (c *Contact) Names() []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+// Store persists contacts in SQLite.
+type Store struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// NewStore creates or opens the contacts table in the database at dbPath.
+func NewStore(dbPath string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create contacts directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open contacts database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate contacts database: %w", err)
+	}
+
+	log.Printf("[contacts] Store initialized at %s", dbPath)
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS contacts (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner        TEXT NOT NULL,
+		name         TEXT NOT NULL,
+		aliases      TEXT NOT NULL DEFAULT '',
+		relation     TEXT NOT NULL DEFAULT '',
+		birthday     TEXT NOT NULL DEFAULT '',
+		notes        TEXT NOT NULL DEFAULT '',
+		channel      TEXT NOT NULL DEFAULT '',
+		job_id       TEXT NOT NULL DEFAULT '',
+		last_greeted INTEGER NOT NULL DEFAULT 0,
+		created_at   INTEGER NOT NULL,
+		updated_at   INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_contacts_owner ON contacts(owner);
+	`)
+	return err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add inserts c and sets its ID.
+func (s *Store) Add(c *Contact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	c.UpdatedAt = now
+	res, err := s.db.Exec(`INSERT INTO contacts (owner, name, aliases, relation, birthday, notes, channel, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.Owner, c.Name, strings.Join(c.Aliases, ","), c.Relation, c.Birthday, c.Notes, c.Channel,
+		c.CreatedAt.Unix(), c.UpdatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to add contact: %w", err)
+	}
+	c.ID, err = res.LastInsertId()
+	return err
+}
+
+// Save writes back a contact.
+func (s *Store) Save(c *Contact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c.UpdatedAt = time.Now()
+	_, err := s.db.Exec(`UPDATE contacts SET name = ?, aliases = ?, relation = ?, birthday = ?, notes = ?, channel = ?,
+		job_id = ?, last_greeted = ?, updated_at = ? WHERE id = ?`,
+		c.Name, strings.Join(c.Aliases, ","), c.Relation, c.Birthday, c.Notes, c.Channel,
+		c.JobID, c.LastGreeted, c.UpdatedAt.Unix(), c.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save contact #%d: %w", c.ID, err)
+	}
+	return nil
+}
+
+// Get returns one of owner's contacts, or any contact if owner is "".
+func (s *Store) Get(owner string, id int64) (*Contact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var list []*Contact
+	var err error
+	if owner == "" {
+		list, err = s.query(`WHERE id = ?`, id)
+	} else {
+		list, err = s.query(`WHERE id = ? AND owner = ?`, id, owner)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("contact #%d not found", id)
+	}
+	return list[0], nil
+}
+
+// List returns the contacts of a chat by name, or of all chats if owner
+// is "".
+func (s *Store) List(owner string) ([]*Contact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if owner == "" {
+		return s.query(`ORDER BY id`)
+	}
+	return s.query(`WHERE owner = ? ORDER BY name COLLATE NOCASE`, owner)
+}
+
+// Search returns owner's contacts whose name, aliases, relation or notes
+// contain query.
+func (s *Store) Search(owner, query string) ([]*Contact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	like := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(query) + "%"
+	return s.query(`WHERE owner = ? AND (name LIKE ? ESCAPE '\' OR aliases LIKE ? ESCAPE '\' OR relation LIKE ? ESCAPE '\'
+		OR notes LIKE ? ESCAPE '\') ORDER BY name COLLATE NOCASE`, owner, like, like, like, like)
+}
+
+// Delete removes one of owner's contacts.
+func (s *Store) Delete(owner string, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM contacts WHERE id = ? AND owner = ?`, id, owner)
+	if err != nil {
+		return fmt.Errorf("failed to remove contact: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("contact #%d not found", id)
+	}
+	return nil
+}
+
+// query runs a SELECT over contacts. Caller must hold s.mu.
+func (s *Store) query(clause string, args ...interface{}) ([]*Contact, error) {
+	rows, err := s.db.Query(`SELECT id, owner, name, aliases, relation, birthday, notes, channel, job_id, last_greeted,
+		created_at, updated_at FROM contacts `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contacts: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Contact
+	for rows.Next() {
+		var c Contact
+		var aliases string
+		var created, updated int64
+		if err := rows.Scan(&c.ID, &c.Owner, &c.Name, &aliases, &c.Relation, &c.Birthday, &c.Notes, &c.Channel,
+			&c.JobID, &c.LastGreeted, &created, &updated); err != nil {
+			return nil, err
+		}
+		c.Aliases = SplitAliases(aliases)
+		c.CreatedAt = time.Unix(created, 0)
+		c.UpdatedAt = time.Unix(updated, 0)
+		list = append(list, &c)
+	}
+	return list, rows.Err()
+}
+
+// SplitAliases splits a comma-separated alias list, dropping blanks.
+func SplitAliases(s string) []string {
+	var aliases []string
+	for _, a := range strings.Split(s, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			aliases = append(aliases, a)
+		}
+	}
+	return aliases
+}
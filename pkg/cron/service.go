@@ -19,11 +19,13 @@ type CronSchedule struct {
 }
 
 type CronPayload struct {
-	Kind    string `json:"kind"`
-	Message string `json:"message"`
-	Deliver bool   `json:"deliver"`
-	Channel string `json:"channel,omitempty"`
-	To      string `json:"to,omitempty"`
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+	Deliver  bool   `json:"deliver"`
+	Channel  string `json:"channel,omitempty"`
+	To       string `json:"to,omitempty"`
+	Template string `json:"template,omitempty"` // delivery template, see RenderDelivery
+	Group    bool   `json:"group,omitempty"`    // To is, or may be, a group chat
 }
 
 type CronJobState struct {
@@ -56,6 +58,7 @@ type CronService struct {
 	storePath   string
 	store       *CronStore
 	onJob       JobHandler
+	handlers    map[string]JobHandler // per payload kind, overrides onJob
 	mu          sync.RWMutex
 	running     bool
 	stopChan    chan struct{}
@@ -66,12 +69,21 @@ func NewCronService(storePath string, onJob JobHandler) *CronService {
 	cs := &CronService{
 		storePath: storePath,
 		onJob:     onJob,
+		handlers:  make(map[string]JobHandler),
 		stopChan:  make(chan struct{}),
 	}
 	cs.loadStore()
 	return cs
 }
 
+// SetKindHandler routes jobs whose payload has the given kind (e.g. "workflow")
+// to a dedicated handler instead of the default onJob callback.
+func (cs *CronService) SetKindHandler(kind string, handler JobHandler) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.handlers[kind] = handler
+}
+
 func (cs *CronService) Start() error {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
@@ -157,9 +169,16 @@ func (cs *CronService) executeJob(job *CronJob) {
 	startTime := time.Now().UnixMilli()
 	log.Printf("[cron] Executing job '%s' (ID: %s)", job.Name, job.ID)
 
+	cs.mu.RLock()
+	handler, ok := cs.handlers[job.Payload.Kind]
+	cs.mu.RUnlock()
+	if !ok {
+		handler = cs.onJob
+	}
+
 	var err error
-	if cs.onJob != nil {
-		_, err = cs.onJob(job)
+	if handler != nil {
+		_, err = handler(job)
 	}
 
 	cs.mu.Lock()
@@ -221,6 +240,9 @@ func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int6
 		if schedule.EveryMS == nil || *schedule.EveryMS <= 0 {
 			return nil
 		}
+		if schedule.AtMS != nil {
+			return nextAnchored(*schedule.AtMS, *schedule.EveryMS, nowMS)
+		}
 		next := nowMS + *schedule.EveryMS
 		return &next
 	}
@@ -228,6 +250,33 @@ func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int6
 	return nil
 }
 
+// nextAnchored returns the first run after nowMS of an "every" schedule
+// whose AtMS fixes the phase, e.g. daily at 08:00. Whole-day intervals keep
+// the local time of day across DST changes.
+func nextAnchored(anchorMS, everyMS, nowMS int64) *int64 {
+	if anchorMS > nowMS {
+		return &anchorMS
+	}
+	const dayMS = 24 * 60 * 60 * 1000
+	n := (nowMS-anchorMS)/everyMS + 1
+	if everyMS%dayMS != 0 {
+		next := anchorMS + n*everyMS
+		return &next
+	}
+
+	// n-1 days-steps is at most an hour (DST) away from now; step from there
+	anchor := time.UnixMilli(anchorMS)
+	days := int(everyMS / dayMS)
+	k := int(n) - 1
+	next := anchor.AddDate(0, 0, k*days)
+	for next.UnixMilli() <= nowMS {
+		k++
+		next = anchor.AddDate(0, 0, k*days)
+	}
+	ms := next.UnixMilli()
+	return &ms
+}
+
 func (cs *CronService) recomputeNextRuns() {
 	now := time.Now().UnixMilli()
 	for i := range cs.store.Jobs {
@@ -286,6 +335,17 @@ func (cs *CronService) saveStore() error {
 }
 
 func (cs *CronService) AddJob(name string, schedule CronSchedule, message string, deliver bool, channel, to string) (*CronJob, error) {
+	return cs.AddJobPayload(name, schedule, CronPayload{
+		Kind:    "agent_turn",
+		Message: message,
+		Deliver: deliver,
+		Channel: channel,
+		To:      to,
+	})
+}
+
+// AddJobPayload adds a job with an explicit payload, e.g. Kind "workflow".
+func (cs *CronService) AddJobPayload(name string, schedule CronSchedule, payload CronPayload) (*CronJob, error) {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
@@ -296,13 +356,7 @@ func (cs *CronService) AddJob(name string, schedule CronSchedule, message string
 		Name:     name,
 		Enabled:  true,
 		Schedule: schedule,
-		Payload: CronPayload{
-			Kind:    "agent_turn",
-			Message: message,
-			Deliver: deliver,
-			Channel: channel,
-			To:      to,
-		},
+		Payload:  payload,
 		State: CronJobState{
 			NextRunAtMS: cs.computeNextRun(&schedule, now),
 		},
@@ -319,6 +373,26 @@ func (cs *CronService) AddJob(name string, schedule CronSchedule, message string
 	return &job, nil
 }
 
+// AddOneShot adds a job that runs once at the given time and is then
+// removed, rather than kept disabled like other "at" jobs.
+func (cs *CronService) AddOneShot(name string, at time.Time, payload CronPayload) (*CronJob, error) {
+	atMS := at.UnixMilli()
+	job, err := cs.AddJobPayload(name, CronSchedule{Kind: "at", AtMS: &atMS}, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for i := range cs.store.Jobs {
+		if cs.store.Jobs[i].ID == job.ID {
+			cs.store.Jobs[i].DeleteAfterRun = true
+			job.DeleteAfterRun = true
+		}
+	}
+	return job, cs.saveStore()
+}
+
 func (cs *CronService) RemoveJob(jobID string) bool {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
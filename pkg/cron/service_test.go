@@ -308,3 +308,71 @@ func TestCronStoreFileCreated(t *testing.T) {
 		t.Fatalf("store file should exist: %v", err)
 	}
 }
+
+func TestRenderDelivery(t *testing.T) {
+	job := &CronJob{Name: "daily-report"}
+	if got := job.RenderDelivery("raw result", "telegram"); got != "raw result" {
+		t.Errorf("expected result unchanged without template, got %q", got)
+	}
+
+	job.Payload.Template = "{#telegram}<b>{job_name}</b>{/telegram}{#discord}**{job_name}**{/discord}\n{result}\n{unknown}"
+
+	if got := job.RenderDelivery("all good", "telegram"); got != "<b>daily-report</b>\nall good\n{unknown}" {
+		t.Errorf("unexpected telegram rendering: %q", got)
+	}
+	if got := job.RenderDelivery("all good", "cli"); got != "all good\n{unknown}" {
+		t.Errorf("unexpected rendering for channel without block: %q", got)
+	}
+}
+
+func TestEveryScheduleAnchored(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+	anchor := time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local)
+	anchorMS := anchor.UnixMilli()
+	dayMS := int64(24 * time.Hour / time.Millisecond)
+	schedule := CronSchedule{Kind: "every", EveryMS: &dayMS, AtMS: &anchorMS}
+
+	before := anchor.Add(-time.Hour).UnixMilli()
+	if next := cs.computeNextRun(&schedule, before); next == nil || *next != anchorMS {
+		t.Errorf("expected the first run at the anchor, got %v", next)
+	}
+
+	now := time.Date(2026, 4, 10, 9, 30, 0, 0, time.Local).UnixMilli()
+	next := cs.computeNextRun(&schedule, now)
+	want := time.Date(2026, 4, 11, 8, 0, 0, 0, time.Local)
+	if next == nil || !time.UnixMilli(*next).Equal(want) {
+		t.Errorf("expected next run %v, got %v", want, next)
+	}
+
+	hourMS := int64(time.Hour / time.Millisecond)
+	hourly := CronSchedule{Kind: "every", EveryMS: &hourMS, AtMS: &anchorMS}
+	if next := cs.computeNextRun(&hourly, anchorMS+90*60*1000); next == nil || *next != anchorMS+2*hourMS {
+		t.Errorf("expected the next whole hour after the anchor, got %v", next)
+	}
+}
+
+func TestOneShotRemovedAfterRun(t *testing.T) {
+	done := make(chan struct{})
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), func(job *CronJob) (string, error) {
+		close(done)
+		return "done", nil
+	})
+
+	job, err := cs.AddOneShot("once", time.Now().Add(time.Hour), CronPayload{Kind: "agent_turn", Message: "hi"})
+	if err != nil || !job.DeleteAfterRun {
+		t.Fatalf("AddOneShot = %+v, %v", job, err)
+	}
+
+	pastMS := time.Now().Add(-time.Second).UnixMilli()
+	cs.mu.Lock()
+	cs.store.Jobs[0].State.NextRunAtMS = &pastMS
+	cs.mu.Unlock()
+	cs.running = true
+	cs.checkJobs()
+
+	<-done
+	time.Sleep(100 * time.Millisecond)
+	if jobs := cs.ListJobs(true); len(jobs) != 0 {
+		t.Errorf("expected the one-shot job to be removed, got %+v", jobs)
+	}
+}
@@ -0,0 +1,25 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// RenderDelivery formats a job's result for delivery to a channel using the
+// payload's template. Without a template the result is delivered as-is.
+// Available variables: {date}, {time}, {job_name}, {job_id}, {result}.
+func (job *CronJob) RenderDelivery(result, channel string) string {
+	if job.Payload.Template == "" {
+		return result
+	}
+
+	now := time.Now()
+	return bus.RenderTemplate(job.Payload.Template, map[string]string{
+		"date":     now.Format("2006-01-02"),
+		"time":     now.Format("15:04"),
+		"job_name": job.Name,
+		"job_id":   job.ID,
+		"result":   result,
+	}, channel)
+}
@@ -0,0 +1,256 @@
+// Package digest compiles a periodic report of what the assistant did:
+// messages handled, cron job results, memories learned and money spent.
+// Reports are delivered through a cron job of kind "digest", so they show
+// up in `mclaw cron list` and survive restarts like any other job.
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+// JobKind is the cron payload kind of digest jobs.
+const JobKind = "digest"
+
+// CronRun is the last result of a cron job that ran during the period.
+type CronRun struct {
+	Name   string
+	Status string
+	Error  string
+	At     time.Time
+}
+
+// Report is the activity of one period.
+type Report struct {
+	Period   string // "daily" or "weekly"
+	Since    time.Time
+	Until    time.Time
+	Stats    DayStats
+	CronRuns []CronRun
+	Facts    []string // memories learned
+}
+
+// Sources are where a report's data comes from. Any of them may be nil.
+type Sources struct {
+	Stats *Recorder
+	Jobs  func() []cron.CronJob
+	Facts func(since, until time.Time) []string
+}
+
+// Build compiles the report for the full days before now: yesterday for a
+// daily digest, the last seven days for a weekly one.
+func Build(src Sources, period string, now time.Time) Report {
+	days := 1
+	if period == "weekly" {
+		days = 7
+	}
+	until := dayStart(now)
+	r := Report{Period: period, Since: until.AddDate(0, 0, -days), Until: until}
+	r.Stats = src.Stats.Total(r.Since, r.Until)
+
+	if src.Jobs != nil {
+		for _, job := range src.Jobs() {
+			if job.Payload.Kind == JobKind || job.State.LastRunAtMS == nil {
+				continue
+			}
+			at := time.UnixMilli(*job.State.LastRunAtMS)
+			if at.Before(r.Since) || !at.Before(r.Until) {
+				continue
+			}
+			r.CronRuns = append(r.CronRuns, CronRun{Name: job.Name, Status: job.State.LastStatus, Error: job.State.LastError, At: at})
+		}
+		sort.Slice(r.CronRuns, func(i, j int) bool { return r.CronRuns[i].At.Before(r.CronRuns[j].At) })
+	}
+	if src.Facts != nil {
+		r.Facts = src.Facts(r.Since, r.Until)
+	}
+	return r
+}
+
+// maxFacts is how many learned memories are listed before summarizing.
+const maxFacts = 10
+
+// Render formats the report as Markdown.
+func (r Report) Render() string {
+	var sb strings.Builder
+	if r.Period == "weekly" {
+		fmt.Fprintf(&sb, "📊 **Weekly digest** — %s to %s\n\n", r.Since.Format("Jan 2"), r.Until.AddDate(0, 0, -1).Format("Jan 2"))
+	} else {
+		fmt.Fprintf(&sb, "📊 **Daily digest** — %s\n\n", r.Since.Format("Mon, Jan 2"))
+	}
+
+	s := r.Stats
+	fmt.Fprintf(&sb, "💬 **Messages:** %d", s.Messages)
+	if s.Failed > 0 {
+		fmt.Fprintf(&sb, " (%d failed)", s.Failed)
+	}
+	if len(s.Channels) > 1 {
+		channels := make([]string, 0, len(s.Channels))
+		for ch, n := range s.Channels {
+			channels = append(channels, fmt.Sprintf("%s %d", ch, n))
+		}
+		sort.Strings(channels)
+		sb.WriteString(" — " + strings.Join(channels, ", "))
+	}
+	sb.WriteString("\n")
+	fmt.Fprintf(&sb, "💰 **Spend:** $%.2f (%s tokens)\n", s.Cost, formatCount(s.Tokens))
+	if s.Liked+s.Disliked > 0 {
+		fmt.Fprintf(&sb, "👍 **Reactions:** %d liked, %d disliked\n", s.Liked, s.Disliked)
+	}
+
+	if len(r.CronRuns) > 0 {
+		failed := 0
+		for _, run := range r.CronRuns {
+			if run.Status == "error" {
+				failed++
+			}
+		}
+		fmt.Fprintf(&sb, "\n⏰ **Scheduled jobs:** %d ran, %d failed\n", len(r.CronRuns), failed)
+		for _, run := range r.CronRuns {
+			if run.Status == "error" {
+				fmt.Fprintf(&sb, "- ❌ %s: %s\n", run.Name, run.Error)
+			}
+		}
+	}
+
+	if len(r.Facts) > 0 {
+		fmt.Fprintf(&sb, "\n🧠 **Learned %d new memor%s:**\n", len(r.Facts), plural(len(r.Facts), "y", "ies"))
+		for i, fact := range r.Facts {
+			if i == maxFacts {
+				fmt.Fprintf(&sb, "- …and %d more\n", len(r.Facts)-maxFacts)
+				break
+			}
+			sb.WriteString("- " + fact + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Handler returns the cron handler for digest jobs. It builds the report
+// named in the job's message ("daily" or "weekly") and sends it to the
+// job's channel and chat.
+func Handler(src Sources, mb *bus.MessageBus) cron.JobHandler {
+	return func(job *cron.CronJob) (string, error) {
+		if job.Payload.Channel == "" || job.Payload.To == "" {
+			return "", fmt.Errorf("digest job has no channel or chat")
+		}
+		report := Build(src, job.Payload.Message, time.Now()).Render()
+		mb.PublishOutbound(bus.OutboundMessage{
+			Channel: job.Payload.Channel,
+			ChatID:  job.Payload.To,
+			Content: job.RenderDelivery(report, job.Payload.Channel),
+		})
+		return report, nil
+	}
+}
+
+// Schedule makes the cron service hold exactly the digest job cfg asks
+// for: it adds the job, replaces it when the settings changed, and removes
+// it when the digest is disabled.
+func Schedule(cs *cron.CronService, cfg *config.Config, now time.Time) error {
+	want, err := jobFor(cfg, now)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range cs.ListJobs(true) {
+		if job.Payload.Kind != JobKind {
+			continue
+		}
+		if want != nil && sameJob(job, want) {
+			want = nil // already scheduled
+			continue
+		}
+		cs.RemoveJob(job.ID)
+	}
+	if want == nil {
+		return nil
+	}
+	_, err = cs.AddJobPayload(want.Name, want.Schedule, want.Payload)
+	return err
+}
+
+// jobFor returns the digest job for cfg, or nil if the digest is off.
+func jobFor(cfg *config.Config, now time.Time) (*cron.CronJob, error) {
+	dc := cfg.Digest
+	if !dc.Enabled {
+		return nil, nil
+	}
+
+	clock, err := time.ParseInLocation("15:04", dc.Time, now.Location())
+	if err != nil {
+		return nil, fmt.Errorf("digest.time %q: use HH:MM", dc.Time)
+	}
+	first := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+
+	every := 24 * time.Hour
+	switch dc.Period {
+	case "", "daily":
+		dc.Period = "daily"
+	case "weekly":
+		every = 7 * every
+		weekday, ok := weekdays[strings.ToLower(dc.Weekday)]
+		if !ok {
+			return nil, fmt.Errorf("digest.weekday %q is not a day of the week", dc.Weekday)
+		}
+		first = first.AddDate(0, 0, (int(weekday)-int(first.Weekday())+7)%7)
+	default:
+		return nil, fmt.Errorf("digest.period %q: use daily or weekly", dc.Period)
+	}
+
+	chatID := dc.ChatID
+	if chatID == "" {
+		chatID = cfg.OwnerChat(dc.Channel)
+	}
+	if dc.Channel == "" || chatID == "" {
+		return nil, fmt.Errorf("digest needs a channel and chat_id (or an allow_from user on %q)", dc.Channel)
+	}
+
+	anchorMS := first.UnixMilli()
+	everyMS := every.Milliseconds()
+	return &cron.CronJob{
+		Name:     dc.Period + " digest",
+		Schedule: cron.CronSchedule{Kind: "every", EveryMS: &everyMS, AtMS: &anchorMS},
+		Payload:  cron.CronPayload{Kind: JobKind, Message: dc.Period, Deliver: true, Channel: dc.Channel, To: chatID},
+	}, nil
+}
+
+// sameJob reports whether a scheduled job matches the wanted one. Anchors
+// are compared by time of day (and weekday for weekly jobs) rather than
+// exactly, since every restart computes a new first run.
+func sameJob(job cron.CronJob, want *cron.CronJob) bool {
+	s, w := job.Schedule, want.Schedule
+	if job.Payload != want.Payload || s.Kind != w.Kind || s.EveryMS == nil || s.AtMS == nil || *s.EveryMS != *w.EveryMS {
+		return false
+	}
+	a, b := time.UnixMilli(*s.AtMS), time.UnixMilli(*w.AtMS)
+	if a.Hour() != b.Hour() || a.Minute() != b.Minute() {
+		return false
+	}
+	return *w.EveryMS == (24*time.Hour).Milliseconds() || a.Weekday() == b.Weekday()
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+func formatCount(n int) string {
+	if n >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
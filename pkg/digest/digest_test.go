@@ -0,0 +1,120 @@
+package digest
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+func TestRecorderTotals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	r := OpenRecorder(path)
+	day := time.Date(2026, 5, 4, 9, 0, 0, 0, time.Local)
+	r.RecordTurn("telegram", 1000, 0.02, false, day)
+	r.RecordTurn("discord", 500, 0.01, true, day.Add(2*time.Hour))
+	r.RecordTurn("telegram", 300, 0.005, false, day.AddDate(0, 0, 1))
+
+	// Counters survive a reopen
+	r = OpenRecorder(path)
+	got := r.Total(dayStart(day), dayStart(day).AddDate(0, 0, 1))
+	if got.Messages != 2 || got.Failed != 1 || got.Tokens != 1500 || got.Channels["discord"] != 1 {
+		t.Errorf("unexpected totals for the first day: %+v", got)
+	}
+	if week := r.Total(dayStart(day).AddDate(0, 0, -6), dayStart(day).AddDate(0, 0, 2)); week.Messages != 3 {
+		t.Errorf("expected 3 messages over the week, got %d", week.Messages)
+	}
+
+	var none *Recorder
+	none.RecordTurn("cli", 1, 0, false, day)
+}
+
+func TestBuildAndRender(t *testing.T) {
+	r := OpenRecorder(filepath.Join(t.TempDir(), "stats.json"))
+	now := time.Date(2026, 5, 5, 8, 0, 0, 0, time.Local)
+	yesterday := now.AddDate(0, 0, -1)
+	r.RecordTurn("telegram", 2500, 0.04, false, yesterday)
+	r.RecordTurn("telegram", 100, 0.001, false, now) // today, not in the report
+	r.RecordReaction(true, yesterday)
+	r.RecordReaction(false, yesterday)
+	r.RecordReaction(true, yesterday)
+
+	ranAt := yesterday.UnixMilli()
+	oldRun := yesterday.AddDate(0, 0, -3).UnixMilli()
+	jobs := []cron.CronJob{
+		{Name: "backup", State: cron.CronJobState{LastRunAtMS: &ranAt, LastStatus: "error", LastError: "disk full"}},
+		{Name: "news", State: cron.CronJobState{LastRunAtMS: &ranAt, LastStatus: "ok"}},
+		{Name: "stale", State: cron.CronJobState{LastRunAtMS: &oldRun, LastStatus: "ok"}},
+		{Name: "daily digest", Payload: cron.CronPayload{Kind: JobKind}, State: cron.CronJobState{LastRunAtMS: &ranAt}},
+	}
+	src := Sources{
+		Stats: r,
+		Jobs:  func() []cron.CronJob { return jobs },
+		Facts: func(since, until time.Time) []string { return []string{"Prefers tea over coffee"} },
+	}
+
+	report := Build(src, "daily", now)
+	if report.Stats.Messages != 1 || len(report.CronRuns) != 2 {
+		t.Fatalf("expected 1 message and 2 cron runs, got %+v", report)
+	}
+	text := report.Render()
+	for _, want := range []string{"Daily digest", "**Messages:** 1", "$0.04", "2.5k tokens", "2 ran, 1 failed", "backup: disk full", "2 liked, 1 disliked", "Prefers tea over coffee"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in report:\n%s", want, text)
+		}
+	}
+
+	if weekly := Build(src, "weekly", now); len(weekly.CronRuns) != 3 {
+		t.Errorf("expected the weekly report to include the older run, got %d runs", len(weekly.CronRuns))
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	cs := cron.NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+	cfg := config.DefaultConfig()
+	cfg.Digest.Enabled = true
+	cfg.Channels.Telegram.AllowFrom = []string{"12345|owner"}
+	now := time.Now()
+
+	if err := Schedule(cs, cfg, now); err != nil {
+		t.Fatal(err)
+	}
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 || jobs[0].Payload.To != "12345" || jobs[0].Payload.Message != "daily" {
+		t.Fatalf("expected one daily digest to the owner, got %+v", jobs)
+	}
+	next := time.UnixMilli(*jobs[0].State.NextRunAtMS)
+	if next.Hour() != 8 || next.Minute() != 0 || !next.After(now) || next.Sub(now) > 25*time.Hour {
+		t.Errorf("expected the next run at the coming 08:00, got %v", next)
+	}
+
+	// Unchanged settings keep the job, changed ones replace it
+	Schedule(cs, cfg, now.Add(time.Hour))
+	if again := cs.ListJobs(true); len(again) != 1 || again[0].ID != jobs[0].ID {
+		t.Errorf("expected the job to be kept, got %+v", again)
+	}
+	cfg.Digest.Period = "weekly"
+	cfg.Digest.Weekday = "friday"
+	Schedule(cs, cfg, now)
+	jobs = cs.ListJobs(true)
+	if len(jobs) != 1 || jobs[0].Payload.Message != "weekly" {
+		t.Fatalf("expected one weekly digest, got %+v", jobs)
+	}
+	if next := time.UnixMilli(*jobs[0].State.NextRunAtMS); next.Weekday() != time.Friday || next.Hour() != 8 {
+		t.Errorf("expected the next run on a Friday at 08:00, got %v", next)
+	}
+
+	cfg.Digest.Time = "8am"
+	if err := Schedule(cs, cfg, now); err == nil {
+		t.Error("expected an error for a malformed time")
+	}
+	cfg.Digest.Time = "08:00"
+	cfg.Digest.Enabled = false
+	Schedule(cs, cfg, now)
+	if len(cs.ListJobs(true)) != 0 {
+		t.Error("expected disabling the digest to remove its job")
+	}
+}
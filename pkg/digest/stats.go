@@ -0,0 +1,146 @@
+package digest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// keepDays is how long daily counters are kept.
+const keepDays = 90
+
+// DayStats counts the turns the agent handled on one local day, or over a
+// period when summed by Recorder.Total.
+type DayStats struct {
+	Messages int            `json:"messages"`
+	Failed   int            `json:"failed,omitempty"`
+	Tokens   int            `json:"tokens"`
+	Cost     float64        `json:"cost"`
+	Channels map[string]int `json:"channels,omitempty"` // messages by channel
+	Liked    int            `json:"liked,omitempty"`    // positive reactions to replies
+	Disliked int            `json:"disliked,omitempty"` // negative reactions to replies
+}
+
+func (d *DayStats) add(o DayStats) {
+	d.Messages += o.Messages
+	d.Failed += o.Failed
+	d.Tokens += o.Tokens
+	d.Cost += o.Cost
+	d.Liked += o.Liked
+	d.Disliked += o.Disliked
+	for ch, n := range o.Channels {
+		if d.Channels == nil {
+			d.Channels = make(map[string]int)
+		}
+		d.Channels[ch] += n
+	}
+}
+
+// Recorder keeps per-day turn counters in a JSON file. A nil *Recorder
+// records nothing.
+type Recorder struct {
+	mu   sync.Mutex
+	path string
+	days map[string]*DayStats // by "2006-01-02"
+}
+
+// OpenRecorder loads the counters at path, starting empty if the file is
+// missing or unreadable.
+func OpenRecorder(path string) *Recorder {
+	r := &Recorder{path: path, days: make(map[string]*DayStats)}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &r.days)
+	}
+	return r
+}
+
+// RecordTurn counts one turn with its token usage and cost.
+func (r *Recorder) RecordTurn(channel string, tokens int, cost float64, failed bool, now time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	one := DayStats{Messages: 1, Tokens: tokens, Cost: cost, Channels: map[string]int{channel: 1}}
+	if failed {
+		one.Failed = 1
+	}
+	r.day(now).add(one)
+	r.save()
+}
+
+// RecordReaction counts a positive (liked) or negative reaction to a reply.
+func (r *Recorder) RecordReaction(liked bool, now time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d := r.day(now)
+	if liked {
+		d.Liked++
+	} else {
+		d.Disliked++
+	}
+	r.save()
+}
+
+// Total sums the days from since up to, not including, until.
+func (r *Recorder) Total(since, until time.Time) DayStats {
+	var total DayStats
+	if r == nil {
+		return total
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for day := dayStart(since); day.Before(until); day = day.AddDate(0, 0, 1) {
+		if d, ok := r.days[day.Format("2006-01-02")]; ok {
+			total.add(*d)
+		}
+	}
+	return total
+}
+
+// day returns the counters of now's day. Callers hold r.mu.
+func (r *Recorder) day(now time.Time) *DayStats {
+	key := now.Format("2006-01-02")
+	d, ok := r.days[key]
+	if !ok {
+		d = &DayStats{}
+		r.days[key] = d
+		r.prune(now)
+	}
+	return d
+}
+
+// prune drops days older than keepDays. Callers hold r.mu.
+func (r *Recorder) prune(now time.Time) {
+	cutoff := now.AddDate(0, 0, -keepDays).Format("2006-01-02")
+	for day := range r.days {
+		if day < cutoff {
+			delete(r.days, day)
+		}
+	}
+}
+
+// save writes the counters. Callers hold r.mu.
+func (r *Recorder) save() {
+	data, err := json.Marshal(r.days)
+	if err != nil {
+		return
+	}
+	os.MkdirAll(filepath.Dir(r.path), 0755)
+	tmp := r.path + ".tmp"
+	if os.WriteFile(tmp, data, 0644) == nil {
+		os.Rename(tmp, r.path)
+	}
+}
+
+func dayStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,167 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer answers IMAP commands from a script keyed by command verb.
+func fakeServer(t *testing.T, replies map[string]string) (addr string, received chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	received = make(chan string, 20)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "* OK fake IMAP ready\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			tag, cmd, _ := strings.Cut(strings.TrimSpace(line), " ")
+			received <- cmd
+			verb := strings.Fields(cmd)[0]
+			if verb == "UID" {
+				verb += " " + strings.Fields(cmd)[1]
+			}
+			if reply, ok := strings.CutPrefix(replies[verb], "NO "); ok {
+				fmt.Fprintf(conn, "%s NO %s\r\n", tag, reply)
+				continue
+			}
+			fmt.Fprintf(conn, "%s%s OK done\r\n", replies[verb], tag)
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestClientSearchAndFetch(t *testing.T) {
+	header := "From: =?UTF-8?Q?Ng=C3=A2n?= <ngan@example.com>\r\nSubject: Invoice\r\nDate: Tue, 14 May 2024 09:30:00 +0700\r\n\r\n"
+	addr, received := fakeServer(t, map[string]string{
+		"LIST":       "* LIST (\\HasNoChildren) \"/\" \"INBOX\"\r\n* LIST (\\Noselect) \"/\" \"[Gmail]\"\r\n* LIST () \"/\" {7}\r\nArchive\r\n",
+		"EXAMINE":    "* 3 EXISTS\r\n",
+		"UID SEARCH": "* SEARCH 4 9\r\n",
+		"UID FETCH":  fmt.Sprintf("* 2 FETCH (UID 9 FLAGS (\\Seen) BODY[HEADER.FIELDS (DATE FROM TO CC SUBJECT)] {%d}\r\n%s)\r\n", len(header), header),
+	})
+
+	c, err := Dial(context.Background(), addr, true)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.Login("me@example.com", `pa"ss`); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if got := <-received; got != `LOGIN "me@example.com" "pa\"ss"` {
+		t.Errorf("login command = %s", got)
+	}
+
+	folders, err := c.List()
+	if err != nil || strings.Join(folders, ",") != "INBOX,Archive" {
+		t.Errorf("List = %v, %v", folders, err)
+	}
+
+	if err := c.Select("INBOX", true); err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	uids, err := c.Search(SearchCriteria{Unseen: true, From: "ngan", Since: since}.String())
+	if err != nil || len(uids) != 2 || uids[1] != 9 {
+		t.Fatalf("Search = %v, %v", uids, err)
+	}
+
+	fetched, err := c.FetchHeaders([]uint32{9})
+	if err != nil || len(fetched) != 1 {
+		t.Fatalf("FetchHeaders = %v, %v", fetched, err)
+	}
+	m := ParseHeaders(fetched[0])
+	if m.UID != 9 || !m.Seen || m.Subject != "Invoice" || !strings.Contains(m.From, "Ngân") || m.Date.IsZero() {
+		t.Errorf("header = %+v", m)
+	}
+
+	var commands []string
+	for len(received) > 0 {
+		commands = append(commands, <-received)
+	}
+	want := []string{`LIST "" "*"`, `EXAMINE "INBOX"`, `UID SEARCH UNSEEN FROM "ngan" SINCE 1-May-2024`}
+	for _, w := range want {
+		if !strings.Contains(strings.Join(commands, "\n"), w) {
+			t.Errorf("missing command %q in %q", w, commands)
+		}
+	}
+}
+
+func TestClientLoginRejected(t *testing.T) {
+	addr, _ := fakeServer(t, map[string]string{"LOGIN": "NO [AUTHENTICATIONFAILED] Invalid credentials"})
+	c, err := Dial(context.Background(), addr, true)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer c.Close()
+
+	err = c.Login("me", "wrong")
+	if err == nil || !strings.Contains(err.Error(), "Invalid credentials") {
+		t.Errorf("Login error = %v", err)
+	}
+}
+
+func TestParseMessageMultipart(t *testing.T) {
+	raw := "From: a@example.com\r\n" +
+		"Subject: =?ISO-8859-1?Q?Caf=E9?=\r\n" +
+		"Content-Type: multipart/mixed; boundary=outer\r\n\r\n" +
+		"--outer\r\n" +
+		"Content-Type: multipart/alternative; boundary=inner\r\n\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n\r\n" +
+		"<p>HTML version</p>\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n" +
+		"Plain =C3=A9t=C3=A9 version\r\n" +
+		"--inner--\r\n" +
+		"--outer\r\n" +
+		"Content-Type: application/pdf; name=\"invoice.pdf\"\r\n" +
+		"Content-Disposition: attachment; filename=\"invoice.pdf\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n\r\n" +
+		"JVBERi0xLjQK\r\n" +
+		"--outer--\r\n"
+
+	m, err := ParseMessage(Fetched{UID: 1, Data: []byte(raw)})
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	if m.Subject != "Café" {
+		t.Errorf("subject = %q", m.Subject)
+	}
+	if m.Body != "Plain été version" {
+		t.Errorf("body = %q", m.Body)
+	}
+	if len(m.Attachments) != 1 || m.Attachments[0] != "invoice.pdf" {
+		t.Errorf("attachments = %v", m.Attachments)
+	}
+}
+
+func TestParseMessageHTMLOnly(t *testing.T) {
+	raw := "Subject: hi\r\nContent-Type: text/html\r\n\r\n<html><head><style>p{}</style></head><body><p>Hello&nbsp;there</p><p>Bye</p></body></html>"
+	m, err := ParseMessage(Fetched{Data: []byte(raw)})
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	if m.Body != "Hello there\nBye" {
+		t.Errorf("body = %q", m.Body)
+	}
+}
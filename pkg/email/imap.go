@@ -0,0 +1,318 @@
+// Package email is a small read-oriented IMAP client: enough to list
+// folders, search, fetch headers and bodies, and set the \Seen flag.
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const commandTimeout = 30 * time.Second
+
+// Client is a connection to one IMAP server. It is not safe for concurrent use.
+type Client struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// response is one untagged server response with its literals split out.
+type response struct {
+	text     string
+	literals [][]byte
+}
+
+// Dial connects to addr ("host:port"), over TLS unless plain is set, and
+// reads the server greeting.
+func Dial(ctx context.Context, addr string, plain bool) (*Client, error) {
+	dialer := &net.Dialer{Timeout: commandTimeout}
+	var conn net.Conn
+	var err error
+	if plain {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		host, _, _ := net.SplitHostPort(addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	c := &Client{conn: conn, r: bufio.NewReader(conn)}
+	conn.SetDeadline(time.Now().Add(commandTimeout))
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected greeting: %s", greeting)
+	}
+	return c, nil
+}
+
+// Close logs out and closes the connection.
+func (c *Client) Close() error {
+	c.command("LOGOUT")
+	return c.conn.Close()
+}
+
+// Login authenticates with a plain LOGIN command.
+func (c *Client) Login(username, password string) error {
+	_, err := c.command("LOGIN " + quote(username) + " " + quote(password))
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	return nil
+}
+
+var listName = regexp.MustCompile(`^\* LIST \(([^)]*)\) (?:"[^"]*"|NIL) (.+)$`)
+
+// List returns the names of all selectable folders.
+func (c *Client) List() ([]string, error) {
+	resps, err := c.command(`LIST "" "*"`)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, r := range resps {
+		m := listName.FindStringSubmatch(r.text)
+		if m == nil || strings.Contains(strings.ToLower(m[1]), `\noselect`) {
+			continue
+		}
+		name := m[2]
+		if len(r.literals) > 0 {
+			name = string(r.literals[0])
+		}
+		names = append(names, unquote(name))
+	}
+	return names, nil
+}
+
+// Select opens a folder. With readOnly the folder is opened with EXAMINE,
+// so the server itself refuses any flag changes.
+func (c *Client) Select(folder string, readOnly bool) error {
+	verb := "SELECT"
+	if readOnly {
+		verb = "EXAMINE"
+	}
+	if _, err := c.command(verb + " " + quote(folder)); err != nil {
+		return fmt.Errorf("cannot open folder %q: %w", folder, err)
+	}
+	return nil
+}
+
+// Search runs UID SEARCH with the given criteria and returns matching UIDs
+// in ascending order.
+func (c *Client) Search(criteria string) ([]uint32, error) {
+	resps, err := c.command("UID SEARCH " + criteria)
+	if err != nil {
+		return nil, err
+	}
+	var uids []uint32
+	for _, r := range resps {
+		if !strings.HasPrefix(r.text, "* SEARCH") {
+			continue
+		}
+		for _, f := range strings.Fields(strings.TrimPrefix(r.text, "* SEARCH")) {
+			if n, err := strconv.ParseUint(f, 10, 32); err == nil {
+				uids = append(uids, uint32(n))
+			}
+		}
+	}
+	return uids, nil
+}
+
+// Fetched is the part of a message returned by Fetch.
+type Fetched struct {
+	UID   uint32
+	Flags []string
+	Data  []byte
+}
+
+var (
+	fetchUID   = regexp.MustCompile(`\bUID (\d+)`)
+	fetchFlags = regexp.MustCompile(`\bFLAGS \(([^)]*)\)`)
+)
+
+// FetchHeaders returns the Date, From, To and Subject headers of messages.
+func (c *Client) FetchHeaders(uids []uint32) ([]Fetched, error) {
+	return c.fetch(uids, "BODY.PEEK[HEADER.FIELDS (DATE FROM TO CC SUBJECT)]")
+}
+
+// FetchMessage returns up to maxBytes of a full message without marking
+// it as read.
+func (c *Client) FetchMessage(uid uint32, maxBytes int) (*Fetched, error) {
+	msgs, err := c.fetch([]uint32{uid}, fmt.Sprintf("BODY.PEEK[]<0.%d>", maxBytes))
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("message %d not found", uid)
+	}
+	return &msgs[0], nil
+}
+
+func (c *Client) fetch(uids []uint32, item string) ([]Fetched, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	resps, err := c.command(fmt.Sprintf("UID FETCH %s (UID FLAGS %s)", uidSet(uids), item))
+	if err != nil {
+		return nil, err
+	}
+	var out []Fetched
+	for _, r := range resps {
+		if !strings.Contains(r.text, " FETCH ") || len(r.literals) == 0 {
+			continue
+		}
+		m := fetchUID.FindStringSubmatch(r.text)
+		if m == nil {
+			continue
+		}
+		uid, _ := strconv.ParseUint(m[1], 10, 32)
+		f := Fetched{UID: uint32(uid), Data: r.literals[len(r.literals)-1]}
+		if fm := fetchFlags.FindStringSubmatch(r.text); fm != nil {
+			f.Flags = strings.Fields(fm[1])
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// MarkSeen sets the \Seen flag. The folder must be opened read-write.
+func (c *Client) MarkSeen(uids []uint32) error {
+	_, err := c.command(fmt.Sprintf(`UID STORE %s +FLAGS.SILENT (\Seen)`, uidSet(uids)))
+	return err
+}
+
+// command sends one tagged command and collects untagged responses until
+// its completion. A NO or BAD completion is returned as an error.
+func (c *Client) command(cmd string) ([]response, error) {
+	c.tag++
+	tag := fmt.Sprintf("a%d", c.tag)
+	c.conn.SetDeadline(time.Now().Add(commandTimeout))
+	if _, err := io.WriteString(c.conn, tag+" "+cmd+"\r\n"); err != nil {
+		return nil, err
+	}
+
+	var resps []response
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(resp.text, tag+" ") {
+			status := strings.TrimPrefix(resp.text, tag+" ")
+			if !strings.HasPrefix(status, "OK") {
+				return nil, fmt.Errorf("%s", status)
+			}
+			return resps, nil
+		}
+		resps = append(resps, resp)
+	}
+}
+
+var literalSuffix = regexp.MustCompile(`\{(\d+)\+?\}$`)
+
+// readResponse reads one response line, following any literals it contains.
+func (c *Client) readResponse() (response, error) {
+	var resp response
+	var sb strings.Builder
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return resp, err
+		}
+		sb.WriteString(line)
+
+		m := literalSuffix.FindStringSubmatch(line)
+		if m == nil {
+			resp.text = sb.String()
+			return resp, nil
+		}
+		n, _ := strconv.Atoi(m[1])
+		lit := make([]byte, n)
+		if _, err := io.ReadFull(c.r, lit); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, lit)
+	}
+}
+
+func (c *Client) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func uidSet(uids []uint32) string {
+	parts := make([]string, len(uids))
+	for i, u := range uids {
+		parts[i] = strconv.FormatUint(uint64(u), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+// quote renders s as an IMAP quoted string.
+func quote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", "").Replace(s)
+	return `"` + s + `"`
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(s[1 : len(s)-1])
+	}
+	return s
+}
+
+// SearchCriteria builds UID SEARCH criteria. Zero values are ignored; with
+// nothing set it matches all messages.
+type SearchCriteria struct {
+	Unseen  bool
+	From    string
+	Subject string
+	Text    string
+	Since   time.Time
+}
+
+func (s SearchCriteria) String() string {
+	var parts []string
+	if s.Unseen {
+		parts = append(parts, "UNSEEN")
+	}
+	if s.From != "" {
+		parts = append(parts, "FROM "+quote(s.From))
+	}
+	if s.Subject != "" {
+		parts = append(parts, "SUBJECT "+quote(s.Subject))
+	}
+	if s.Text != "" {
+		parts = append(parts, "TEXT "+quote(s.Text))
+	}
+	if !s.Since.IsZero() {
+		parts = append(parts, "SINCE "+s.Since.Format("2-Jan-2006"))
+	}
+	if len(parts) == 0 {
+		return "ALL"
+	}
+	criteria := strings.Join(parts, " ")
+	for _, r := range criteria {
+		if r > 127 {
+			return "CHARSET UTF-8 " + criteria
+		}
+	}
+	return criteria
+}
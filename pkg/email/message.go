@@ -0,0 +1,210 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Message is a decoded email.
+type Message struct {
+	UID         uint32
+	Seen        bool
+	From        string
+	To          string
+	Cc          string
+	Subject     string
+	Date        time.Time
+	Body        string   // text/plain part, or text/html converted to text
+	Attachments []string // file names
+}
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// ParseHeaders decodes a fetched header block.
+func ParseHeaders(f Fetched) *Message {
+	msg := &Message{UID: f.UID, Seen: hasFlag(f.Flags, `\Seen`)}
+	m, err := mail.ReadMessage(bytes.NewReader(append(bytes.TrimRight(f.Data, "\r\n"), "\r\n\r\n"...)))
+	if err != nil {
+		return msg
+	}
+	fillHeaders(msg, m.Header)
+	return msg
+}
+
+// ParseMessage decodes a full fetched message, extracting a readable body.
+// Messages truncated by the fetch limit are decoded as far as possible.
+func ParseMessage(f Fetched) (*Message, error) {
+	msg := &Message{UID: f.UID, Seen: hasFlag(f.Flags, `\Seen`)}
+	m, err := mail.ReadMessage(bytes.NewReader(f.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+	fillHeaders(msg, m.Header)
+
+	var plain, htmlText string
+	walkPart(m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), "", m.Body, &plain, &htmlText, &msg.Attachments)
+	switch {
+	case strings.TrimSpace(plain) != "":
+		msg.Body = plain
+	case htmlText != "":
+		msg.Body = htmlToText(htmlText)
+	}
+	msg.Body = strings.TrimSpace(msg.Body)
+	return msg, nil
+}
+
+func fillHeaders(msg *Message, h mail.Header) {
+	msg.From = decodeHeader(h.Get("From"))
+	msg.To = decodeHeader(h.Get("To"))
+	msg.Cc = decodeHeader(h.Get("Cc"))
+	msg.Subject = decodeHeader(h.Get("Subject"))
+	if d, err := h.Date(); err == nil {
+		msg.Date = d
+	}
+}
+
+func decodeHeader(s string) string {
+	if dec, err := wordDecoder.DecodeHeader(s); err == nil {
+		return dec
+	}
+	return s
+}
+
+// walkPart collects the first text/plain and text/html bodies and the names
+// of attachments, descending into multipart containers.
+func walkPart(contentType, encoding, disposition string, body io.Reader, plain, htmlText *string, attachments *[]string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err != nil {
+				return
+			}
+			walkPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"),
+				part.Header.Get("Content-Disposition"), part, plain, htmlText, attachments)
+		}
+	}
+
+	if disp, dparams, err := mime.ParseMediaType(disposition); err == nil && disp == "attachment" {
+		*attachments = append(*attachments, decodeHeader(valueOr(dparams["filename"], params["name"])))
+		return
+	}
+
+	switch mediaType {
+	case "text/plain", "text/html":
+	default:
+		if name := params["name"]; name != "" {
+			*attachments = append(*attachments, decodeHeader(name))
+		}
+		return
+	}
+
+	data, _ := io.ReadAll(decodeTransfer(encoding, body))
+	text := toUTF8(params["charset"], data)
+	if mediaType == "text/plain" && *plain == "" {
+		*plain = text
+	} else if mediaType == "text/html" && *htmlText == "" {
+		*htmlText = text
+	}
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: r})
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+// newlineStripper drops CR and LF so base64 bodies wrapped at 76 columns decode.
+type newlineStripper struct{ r io.Reader }
+
+func (n *newlineStripper) Read(p []byte) (int, error) {
+	for {
+		c, err := n.r.Read(p)
+		j := 0
+		for _, b := range p[:c] {
+			if b != '\r' && b != '\n' {
+				p[j] = b
+				j++
+			}
+		}
+		if j > 0 || err != nil {
+			return j, err
+		}
+	}
+}
+
+// charsetReader supports the charsets mail commonly uses that the standard
+// library can convert without extra packages.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(toUTF8(charset, data)), nil
+}
+
+// toUTF8 converts Latin-1 text; other charsets are passed through.
+func toUTF8(charset string, data []byte) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return string(data)
+	}
+}
+
+var (
+	htmlDrop   = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlBreak  = regexp.MustCompile(`(?i)<(br|/p|/div|/tr|/li|/h[1-6])[^>]*>`)
+	htmlTag    = regexp.MustCompile(`<[^>]*>`)
+	blankLines = regexp.MustCompile(`\n[ \t]*(\n[ \t]*)+`)
+)
+
+func htmlToText(s string) string {
+	s = htmlDrop.ReplaceAllString(s, "")
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	return blankLines.ReplaceAllString(s, "\n\n")
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if strings.EqualFold(f, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueOr(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,96 @@
+package expenses
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// multipliers are the shorthand suffixes of amounts, e.g. "50k" or "1.5tr".
+var multipliers = map[string]float64{
+	"k": 1e3, "nghìn": 1e3, "ngàn": 1e3,
+	"m": 1e6, "tr": 1e6, "triệu": 1e6,
+	"b": 1e9, "tỷ": 1e9, "ty": 1e9,
+}
+
+// ParseAmount reads an amount as people type it: "50k", "1.5m", "2tr",
+// "50,000", "50.000đ" or "$12.50". A separator followed by exactly three
+// digits is read as a thousands separator.
+func ParseAmount(s string) (float64, error) {
+	orig := s
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimLeft(s, "$€£¥₫")
+	for _, suffix := range []string{"vnd", "usd", "eur"} {
+		s = strings.TrimSpace(strings.TrimSuffix(s, suffix))
+	}
+	s = strings.TrimRightFunc(s, func(r rune) bool { return r == 'đ' || r == 'd' || r == '₫' || unicode.IsSpace(r) })
+
+	mult := 1.0
+	for suffix, m := range multipliers {
+		if trimmed := strings.TrimSuffix(s, suffix); trimmed != s && trimmed != "" && isNumberEnd(trimmed) {
+			s, mult = strings.TrimSpace(trimmed), m
+			break
+		}
+	}
+
+	number := normalizeSeparators(s)
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid amount %q", orig)
+	}
+	return v * mult, nil
+}
+
+func isNumberEnd(s string) bool {
+	s = strings.TrimSpace(s)
+	return s != "" && s[len(s)-1] >= '0' && s[len(s)-1] <= '9'
+}
+
+// normalizeSeparators turns "1,234.5", "1.234,5", "50.000" and "12,5" into
+// Go float syntax.
+func normalizeSeparators(s string) string {
+	dots, commas := strings.Count(s, "."), strings.Count(s, ",")
+	switch {
+	case dots > 0 && commas > 0:
+		// The last separator is the decimal one
+		if strings.LastIndex(s, ".") > strings.LastIndex(s, ",") {
+			return strings.ReplaceAll(s, ",", "")
+		}
+		return strings.ReplaceAll(strings.ReplaceAll(s, ".", ""), ",", ".")
+	case dots+commas == 0:
+		return s
+	}
+	sep := "."
+	if commas > 0 {
+		sep = ","
+	}
+	parts := strings.Split(s, sep)
+	thousands := len(parts) > 2
+	if !thousands {
+		thousands = len(parts[len(parts)-1]) == 3 && len(parts[0]) > 0 && parts[0] != "0"
+	}
+	if thousands {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts, ".")
+}
+
+// FormatAmount renders a positive amount with thousands separators and at
+// most two decimals, e.g. "1,250,000" or "12.5".
+func FormatAmount(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	whole, frac, _ := strings.Cut(s, ".")
+	var sb strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(r)
+	}
+	if frac != "" {
+		sb.WriteString("." + frac)
+	}
+	return sb.String()
+}
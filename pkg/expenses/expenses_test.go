@@ -0,0 +1,118 @@
+package expenses
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]float64{
+		"50k":      50000,
+		"1.5m":     1500000,
+		"2tr":      2000000,
+		"50,000":   50000,
+		"50.000đ":  50000,
+		"$12.50":   12.5,
+		"12,5":     12.5,
+		"1.234,56": 1234.56,
+		"1,234.56": 1234.56,
+		"300 VND":  300,
+		"0.5":      0.5,
+	}
+	for in, want := range cases {
+		if got, err := ParseAmount(in); err != nil || got != want {
+			t.Errorf("ParseAmount(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "abc", "-5", "k"} {
+		if _, err := ParseAmount(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+	if got := FormatAmount(1250000); got != "1,250,000" {
+		t.Errorf("FormatAmount = %q", got)
+	}
+}
+
+func TestBudgetsAndReport(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "memory.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	var sent []string
+	svc := NewService(store, func(channel, chatID, content string) {
+		sent = append(sent, channel+":"+chatID+" "+content)
+	})
+	svc.SetDefaults("vnd", 80, true, 9)
+
+	owner := "telegram:42"
+	if err := svc.SetBudget(owner, "Food", 500000); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SetBudget(owner, TotalCategory, 2000000); err != nil {
+		t.Fatal(err)
+	}
+
+	add := func(amount float64, category string) []string {
+		t.Helper()
+		e, alerts, err := svc.Add(owner, Expense{Amount: amount, Category: category})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Currency != "VND" || e.Category != strings.ToLower(category) {
+			t.Fatalf("expected defaults to apply, got %+v", e)
+		}
+		return alerts
+	}
+	if alerts := add(300000, "food"); len(alerts) != 0 {
+		t.Errorf("expected no alert at 60%%, got %q", alerts)
+	}
+	if alerts := add(120000, "Food"); len(alerts) != 1 || !strings.Contains(alerts[0], "84%") {
+		t.Errorf("expected one early warning, got %q", alerts)
+	}
+	if alerts := add(30000, "food"); len(alerts) != 0 {
+		t.Errorf("expected the warning to fire once, got %q", alerts)
+	}
+	if alerts := add(100000, "food"); len(alerts) != 1 || !strings.Contains(alerts[0], "Over the food budget") {
+		t.Errorf("expected an over-budget alert, got %q", alerts)
+	}
+	add(1500000, "rent")
+	if _, _, err := svc.Add(owner, Expense{Amount: 20, Currency: "usd", Category: "apps"}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := svc.Report(owner, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Total: 2,050,000 VND", "- rent: 1,500,000", "food: 550,000", "⚠️", "Also spent: 20 USD"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected %q in the report:\n%s", want, report)
+		}
+	}
+	if other, _ := svc.Report("telegram:7", time.Now()); other != "" {
+		t.Errorf("expected other chats to have no report, got %q", other)
+	}
+
+	cs := cron.NewCronService(filepath.Join(dir, "jobs.json"), nil)
+	if err := svc.Attach(cs); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Attach(cs); err != nil {
+		t.Fatal(err)
+	}
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 || jobs[0].Payload.Kind != JobKind {
+		t.Fatalf("expected one report job, got %+v", jobs)
+	}
+	next := time.UnixMilli(*jobs[0].State.NextRunAtMS)
+	if next.Day() != 1 || next.Hour() != 9 || !next.After(time.Now()) {
+		t.Errorf("expected the report on the 1st at 09:00, got %s", next)
+	}
+}
@@ -0,0 +1,354 @@
+package expenses
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+// JobKind is the cron payload kind of the monthly expense report.
+const JobKind = "expense_report"
+
+// NotifyFunc delivers a message to a chat.
+type NotifyFunc func(channel, chatID, content string)
+
+// Service records expenses, checks them against budgets and sends the
+// monthly report. Until a cron service is attached, no report is sent.
+type Service struct {
+	store        *Store
+	notify       NotifyFunc
+	mu           sync.Mutex // serializes adds, so budget alerts fire once
+	cron         *cron.CronService
+	currency     string
+	alertPercent int
+	reportHour   int
+	report       bool
+}
+
+// NewService creates an expense service in USD that warns at 80% of a
+// budget and reports on the 1st of the month at 09:00.
+func NewService(store *Store, notify NotifyFunc) *Service {
+	return &Service{store: store, notify: notify, currency: "USD", alertPercent: 80, reportHour: 9, report: true}
+}
+
+// SetDefaults sets the currency of amounts given without one, the share
+// of a budget that triggers an early warning (0 = only when exceeded),
+// and whether and at what hour the monthly report is sent.
+func (s *Service) SetDefaults(currency string, alertPercent int, report bool, reportHour int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if currency = strings.ToUpper(strings.TrimSpace(currency)); currency != "" {
+		s.currency = currency
+	}
+	if alertPercent >= 0 && alertPercent < 100 {
+		s.alertPercent = alertPercent
+	}
+	if reportHour >= 0 && reportHour < 24 {
+		s.reportHour = reportHour
+	}
+	s.report = report
+}
+
+// Currency returns the default currency.
+func (s *Service) Currency() string {
+	return s.currency
+}
+
+// Attach registers the report handler with cs and schedules the next
+// monthly report, dropping stale report jobs.
+func (s *Service) Attach(cs *cron.CronService) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cron = cs
+	cs.SetKindHandler(JobKind, s.fire)
+
+	scheduled := false
+	for _, job := range cs.ListJobs(true) {
+		if job.Payload.Kind != JobKind {
+			continue
+		}
+		if !s.report || scheduled || job.State.NextRunAtMS == nil {
+			cs.RemoveJob(job.ID)
+			continue
+		}
+		scheduled = true
+	}
+	if s.report && !scheduled {
+		return s.scheduleReport(time.Now())
+	}
+	return nil
+}
+
+// Add records an expense for owner's chat and returns it with any budget
+// alerts it triggered. Currency defaults to the configured one, Date to
+// today and Category to "other".
+func (s *Service) Add(owner string, e Expense) (*Expense, []string, error) {
+	if _, _, ok := strings.Cut(owner, ":"); !ok {
+		return nil, nil, fmt.Errorf("expenses need a chat")
+	}
+	if e.Amount <= 0 || math.IsInf(e.Amount, 0) || math.IsNaN(e.Amount) {
+		return nil, nil, fmt.Errorf("amount must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.Owner = owner
+	e.Category = NormalizeCategory(e.Category)
+	e.Note = strings.TrimSpace(e.Note)
+	if e.Currency = strings.ToUpper(strings.TrimSpace(e.Currency)); e.Currency == "" {
+		e.Currency = s.currency
+	}
+	if e.Date.IsZero() {
+		e.Date = time.Now()
+	}
+	e.Date = day(e.Date)
+
+	var before map[string]float64
+	if e.Currency == s.currency {
+		var err error
+		if before, err = s.monthTotals(owner, e.Date); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := s.store.Add(&e); err != nil {
+		return nil, nil, err
+	}
+	if before == nil {
+		return &e, nil, nil
+	}
+
+	budgets, err := s.store.Budgets(owner)
+	if err != nil {
+		return &e, nil, err
+	}
+	var alerts []string
+	for _, category := range []string{e.Category, TotalCategory} {
+		limit, ok := budgets[category]
+		if !ok {
+			continue
+		}
+		if alert := budgetAlert(category, before[category], before[category]+e.Amount, limit, s.alertPercent, s.currency); alert != "" {
+			alerts = append(alerts, alert)
+		}
+	}
+	return &e, alerts, nil
+}
+
+// budgetAlert returns the warning for spending going from spent to now in
+// a month with the given budget, or "" if no threshold was crossed.
+func budgetAlert(category string, spent, now, budget float64, alertPercent int, currency string) string {
+	name := "the " + category + " budget"
+	if category == TotalCategory {
+		name = "the monthly budget"
+	}
+	switch {
+	case now > budget && spent <= budget:
+		return fmt.Sprintf("🚨 Over %s: %s of %s %s (%.0f%%)", name, FormatAmount(now), FormatAmount(budget), currency, now/budget*100)
+	case alertPercent > 0 && now <= budget && now >= budget*float64(alertPercent)/100 && spent < budget*float64(alertPercent)/100:
+		return fmt.Sprintf("⚠️ %.0f%% of %s used: %s of %s %s, %s left this month", now/budget*100, name, FormatAmount(now),
+			FormatAmount(budget), currency, FormatAmount(budget-now))
+	}
+	return ""
+}
+
+// monthTotals sums owner's spending in the default currency in t's month,
+// per category and under TotalCategory. Caller must hold s.mu.
+func (s *Service) monthTotals(owner string, t time.Time) (map[string]float64, error) {
+	from, to := monthRange(t)
+	list, err := s.store.List(owner, from, to, "")
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]float64)
+	for _, e := range list {
+		if e.Currency == s.currency {
+			totals[e.Category] += e.Amount
+			totals[TotalCategory] += e.Amount
+		}
+	}
+	return totals, nil
+}
+
+// List returns owner's expenses between two days, optionally of one
+// category.
+func (s *Service) List(owner string, from, to time.Time, category string) ([]*Expense, error) {
+	if category != "" {
+		category = NormalizeCategory(category)
+	}
+	return s.store.List(owner, day(from), day(to), category)
+}
+
+// Remove deletes one of owner's expenses.
+func (s *Service) Remove(owner string, id int64) (*Expense, error) {
+	return s.store.Delete(owner, id)
+}
+
+// SetBudget sets owner's monthly budget for a category (TotalCategory for
+// all spending) in the default currency; 0 removes it.
+func (s *Service) SetBudget(owner, category string, amount float64) error {
+	if amount < 0 {
+		return fmt.Errorf("budget must not be negative")
+	}
+	return s.store.SetBudget(owner, NormalizeCategory(category), amount)
+}
+
+// Budgets returns owner's monthly budgets by category.
+func (s *Service) Budgets(owner string) (map[string]float64, error) {
+	return s.store.Budgets(owner)
+}
+
+// Report renders owner's spending in month: totals per currency, each
+// category against its budget, the change from the month before and the
+// largest expenses. It returns "" when there was no spending.
+func (s *Service) Report(owner string, month time.Time) (string, error) {
+	from, to := monthRange(month)
+	list, err := s.store.List(owner, from, to, "")
+	if err != nil {
+		return "", err
+	}
+	if len(list) == 0 {
+		return "", nil
+	}
+	budgets, err := s.store.Budgets(owner)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	previous, err := s.monthTotals(owner, from.AddDate(0, -1, 0))
+	s.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	byCategory := make(map[string]float64)
+	others := make(map[string]float64) // other currencies
+	var total float64
+	for _, e := range list {
+		if e.Currency != s.currency {
+			others[e.Currency] += e.Amount
+			continue
+		}
+		byCategory[e.Category] += e.Amount
+		total += e.Amount
+	}
+
+	var sb strings.Builder
+	title := from.Format("January 2006")
+	if now := time.Now(); !to.Before(day(now)) {
+		title += " so far"
+	}
+	fmt.Fprintf(&sb, "📊 **Expenses, %s**\nTotal: %s %s", title, FormatAmount(total), s.currency)
+	if budget, ok := budgets[TotalCategory]; ok {
+		fmt.Fprintf(&sb, " of %s budget (%.0f%%)", FormatAmount(budget), total/budget*100)
+	}
+	if prev := previous[TotalCategory]; prev > 0 {
+		fmt.Fprintf(&sb, " · %+.0f%% vs %s", (total-prev)/prev*100, from.AddDate(0, -1, 0).Format("January"))
+	}
+	sb.WriteString("\n")
+
+	categories := make([]string, 0, len(byCategory))
+	for c := range byCategory {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool { return byCategory[categories[i]] > byCategory[categories[j]] })
+	for _, c := range categories {
+		fmt.Fprintf(&sb, "- %s: %s", c, FormatAmount(byCategory[c]))
+		if total > 0 {
+			fmt.Fprintf(&sb, " (%.0f%%)", byCategory[c]/total*100)
+		}
+		if budget, ok := budgets[c]; ok {
+			mark := ""
+			if byCategory[c] > budget {
+				mark = " ⚠️"
+			}
+			fmt.Fprintf(&sb, " · budget %s%s", FormatAmount(budget), mark)
+		}
+		sb.WriteString("\n")
+	}
+	if len(others) > 0 {
+		var parts []string
+		for currency, amount := range others {
+			parts = append(parts, FormatAmount(amount)+" "+currency)
+		}
+		sort.Strings(parts)
+		sb.WriteString("Also spent: " + strings.Join(parts, ", ") + "\n")
+	}
+
+	largest := append([]*Expense(nil), list...)
+	sort.SliceStable(largest, func(i, j int) bool { return largest[i].Amount > largest[j].Amount })
+	sb.WriteString("Largest:\n")
+	for _, e := range largest[:min(3, len(largest))] {
+		sb.WriteString("- " + e.Describe() + "\n")
+	}
+	return sb.String(), nil
+}
+
+// fire is the cron handler of the monthly report: it reports last month
+// to every chat that spent something and schedules the next report.
+func (s *Service) fire(job *cron.CronJob) (string, error) {
+	now := time.Now()
+	lastMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	from, to := monthRange(lastMonth)
+	owners, err := s.store.Owners(from, to)
+
+	s.mu.Lock()
+	if scheduleErr := s.scheduleReport(now); scheduleErr != nil {
+		log.Printf("[expenses] Failed to schedule the next report: %v", scheduleErr)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	for _, owner := range owners {
+		report, err := s.Report(owner, lastMonth)
+		if err != nil {
+			log.Printf("[expenses] Report for %s failed: %v", owner, err)
+			continue
+		}
+		if report != "" && s.notify != nil {
+			channel, chatID, _ := strings.Cut(owner, ":")
+			s.notify(channel, chatID, report)
+		}
+	}
+	return fmt.Sprintf("sent %d expense reports", len(owners)), nil
+}
+
+// scheduleReport adds the job of the next report, on the 1st of the month
+// after now. Caller must hold s.mu.
+func (s *Service) scheduleReport(now time.Time) error {
+	next := time.Date(now.Year(), now.Month(), 1, s.reportHour, 0, 0, 0, now.Location()).AddDate(0, 1, 0)
+	_, err := s.cron.AddOneShot("monthly expense report", next, cron.CronPayload{
+		Kind:    JobKind,
+		Message: strconv.FormatInt(next.Unix(), 10),
+	})
+	return err
+}
+
+// Describe renders an expense, e.g. "#12 Oct 15 · 50,000 VND · food · lunch".
+func (e *Expense) Describe() string {
+	s := fmt.Sprintf("#%d %s · %s %s · %s", e.ID, e.Date.Format("Jan 2"), FormatAmount(e.Amount), e.Currency, e.Category)
+	if e.Note != "" {
+		s += " · " + e.Note
+	}
+	return s
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// monthRange returns the first and last day of t's month.
+func monthRange(t time.Time) (time.Time, time.Time) {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return first, first.AddDate(0, 1, -1)
+}
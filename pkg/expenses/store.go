@@ -0,0 +1,235 @@
+// Package expenses keeps a spending ledger per conversation in SQLite,
+// with monthly budgets per category and a monthly report delivered by a
+// cron job.
+package expenses
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// TotalCategory is the budget category that caps all spending in a month.
+const TotalCategory = "total"
+
+// Expense is one spending entry. Owner is the session key of the
+// conversation it was recorded in ("channel:chat_id").
+type Expense struct {
+	ID        int64
+	Owner     string
+	Amount    float64
+	Currency  string
+	Category  string
+	Note      string
+	Date      time.Time // day of the expense, midnight local time
+	CreatedAt time.Time
+}
+
+// Store persists expenses and budgets in SQLite.
+type Store struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// NewStore creates or opens the expense tables in the database at dbPath.
+func NewStore(dbPath string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create expenses directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open expenses database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate expenses database: %w", err)
+	}
+
+	log.Printf("[expenses] Store initialized at %s", dbPath)
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS expenses (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner      TEXT NOT NULL,
+		amount     REAL NOT NULL,
+		currency   TEXT NOT NULL,
+		category   TEXT NOT NULL,
+		note       TEXT NOT NULL DEFAULT '',
+		day        TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_expenses_owner_day ON expenses(owner, day);
+	CREATE TABLE IF NOT EXISTS expense_budgets (
+		owner    TEXT NOT NULL,
+		category TEXT NOT NULL,
+		amount   REAL NOT NULL,
+		PRIMARY KEY (owner, category)
+	);
+	`)
+	return err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add inserts e and sets its ID.
+func (s *Store) Add(e *Expense) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	res, err := s.db.Exec(`INSERT INTO expenses (owner, amount, currency, category, note, day, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.Owner, e.Amount, e.Currency, e.Category, e.Note, e.Date.Format("2006-01-02"), e.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to add expense: %w", err)
+	}
+	e.ID, err = res.LastInsertId()
+	return err
+}
+
+// Delete removes one of owner's expenses and returns it.
+func (s *Store) Delete(owner string, id int64) (*Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.query(`WHERE id = ? AND owner = ?`, id, owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("expense #%d not found", id)
+	}
+	if _, err := s.db.Exec(`DELETE FROM expenses WHERE id = ?`, id); err != nil {
+		return nil, fmt.Errorf("failed to remove expense: %w", err)
+	}
+	return list[0], nil
+}
+
+// List returns owner's expenses from from to to (inclusive days), newest
+// first, optionally of one category.
+func (s *Store) List(owner string, from, to time.Time, category string) ([]*Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if category != "" {
+		return s.query(`WHERE owner = ? AND day >= ? AND day <= ? AND category = ? ORDER BY day DESC, id DESC`,
+			owner, from.Format("2006-01-02"), to.Format("2006-01-02"), category)
+	}
+	return s.query(`WHERE owner = ? AND day >= ? AND day <= ? ORDER BY day DESC, id DESC`,
+		owner, from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+// Owners returns the chats with expenses from from to to.
+func (s *Store) Owners(from, to time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query(`SELECT DISTINCT owner FROM expenses WHERE day >= ? AND day <= ? ORDER BY owner`,
+		from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expenses: %w", err)
+	}
+	defer rows.Close()
+	var owners []string
+	for rows.Next() {
+		var owner string
+		if err := rows.Scan(&owner); err != nil {
+			return nil, err
+		}
+		owners = append(owners, owner)
+	}
+	return owners, rows.Err()
+}
+
+// SetBudget sets owner's monthly budget for a category, or removes it when
+// amount is 0.
+func (s *Store) SetBudget(owner, category string, amount float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if amount <= 0 {
+		_, err = s.db.Exec(`DELETE FROM expense_budgets WHERE owner = ? AND category = ?`, owner, category)
+	} else {
+		_, err = s.db.Exec(`INSERT INTO expense_budgets (owner, category, amount) VALUES (?, ?, ?)
+			ON CONFLICT(owner, category) DO UPDATE SET amount = excluded.amount`, owner, category, amount)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set budget: %w", err)
+	}
+	return nil
+}
+
+// Budgets returns owner's monthly budgets by category.
+func (s *Store) Budgets(owner string) (map[string]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query(`SELECT category, amount FROM expense_budgets WHERE owner = ?`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+	defer rows.Close()
+	budgets := make(map[string]float64)
+	for rows.Next() {
+		var category string
+		var amount float64
+		if err := rows.Scan(&category, &amount); err != nil {
+			return nil, err
+		}
+		budgets[category] = amount
+	}
+	return budgets, rows.Err()
+}
+
+// query runs a SELECT over expenses. Caller must hold s.mu.
+func (s *Store) query(clause string, args ...interface{}) ([]*Expense, error) {
+	rows, err := s.db.Query(`SELECT id, owner, amount, currency, category, note, day, created_at FROM expenses `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Expense
+	for rows.Next() {
+		var e Expense
+		var day string
+		var created int64
+		if err := rows.Scan(&e.ID, &e.Owner, &e.Amount, &e.Currency, &e.Category, &e.Note, &day, &created); err != nil {
+			return nil, err
+		}
+		e.Date, _ = time.ParseInLocation("2006-01-02", day, time.Local)
+		e.CreatedAt = time.Unix(created, 0)
+		list = append(list, &e)
+	}
+	return list, rows.Err()
+}
+
+// NormalizeCategory lowercases and trims a category, defaulting to "other".
+func NormalizeCategory(category string) string {
+	category = strings.ToLower(strings.Join(strings.Fields(category), " "))
+	if category == "" {
+		return "other"
+	}
+	return category
+}
@@ -0,0 +1,191 @@
+// Package feedback records how users react to the assistant's answers. An
+// emoji reaction on a reply is classified as positive, negative or neutral
+// and appended to a JSON Lines log together with the turn it is about, so
+// answers people disliked can be found and learned from later.
+package feedback
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the feedback log's name in the data directory.
+const FileName = "feedback.jsonl"
+
+// Sentiments of a reaction.
+const (
+	Positive = "positive"
+	Negative = "negative"
+	Neutral  = "neutral"
+)
+
+var (
+	positive = setOf("👍", "❤", "🔥", "🥰", "👏", "😁", "🎉", "🤩", "🙏", "👌", "😍", "❤‍🔥", "💯", "🤣", "⚡", "🏆", "🤝", "🤗", "🫡", "😎", "😘", "💘", "✅")
+	negative = setOf("👎", "💩", "🤮", "🤬", "😡", "🤡", "🖕", "😢", "😭", "💔", "🥱", "🤨", "😐", "🥴", "🙈", "❌")
+	// strong reactions say the answer itself was bad, not just sad news
+	strong = setOf("👎", "💩", "🤮", "🤬", "😡", "🤡", "🖕")
+)
+
+func setOf(emojis ...string) map[string]bool {
+	m := make(map[string]bool, len(emojis))
+	for _, e := range emojis {
+		m[normalize(e)] = true
+	}
+	return m
+}
+
+// normalize drops variation selectors and skin tones, so "❤️" matches "❤"
+// and "👍🏽" matches "👍".
+func normalize(emoji string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\uFE0F' || (r >= 0x1F3FB && r <= 0x1F3FF) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(emoji))
+}
+
+// Classify returns the sentiment of an emoji reaction and whether it is a
+// strong one, such as 👎 or 💩.
+func Classify(emoji string) (sentiment string, isStrong bool) {
+	e := normalize(emoji)
+	switch {
+	case positive[e]:
+		return Positive, false
+	case negative[e]:
+		return Negative, strong[e]
+	}
+	return Neutral, false
+}
+
+// Entry is one reaction.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Channel    string    `json:"channel"`
+	ChatID     string    `json:"chat_id"`
+	SenderID   string    `json:"sender_id,omitempty"`
+	SessionKey string    `json:"session_key,omitempty"`
+	Emoji      string    `json:"emoji"`
+	Sentiment  string    `json:"sentiment"`
+	Strong     bool      `json:"strong,omitempty"`
+	Prompt     string    `json:"prompt,omitempty"` // the user message the reply answered, when found
+	Reply      string    `json:"reply"`
+}
+
+// Log appends entries to a JSON Lines file.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens or creates the feedback log at path.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create feedback directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open feedback log: %w", err)
+	}
+	return &Log{file: f}, nil
+}
+
+// Append writes one entry.
+func (l *Log) Append(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// belongsTo reports whether e is about senderID: a reaction they gave, with
+// or without the "|name" suffix channels add, or one in their direct chat,
+// whose prompt is their message.
+func (e Entry) belongsTo(senderID string) bool {
+	return e.SenderID == senderID || strings.HasPrefix(e.SenderID, senderID+"|") || e.ChatID == senderID
+}
+
+// FromSender returns the entries in the log at path that belong to
+// senderID, oldest first. A missing file has none.
+func FromSender(path, senderID string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.belongsTo(senderID) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// PurgeSender removes the entries belonging to senderID from the log at
+// path and returns how many there were. The file is rewritten in place
+// rather than replaced, so a running mclaw keeps appending to it.
+func PurgeSender(path, senderID string) (int, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var kept []byte
+	removed := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.belongsTo(senderID) {
+			removed++
+			continue
+		}
+		kept = append(append(kept, scanner.Bytes()...), '\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := file.Truncate(0); err != nil {
+		return 0, err
+	}
+	if _, err := file.WriteAt(kept, 0); err != nil {
+		return 0, err
+	}
+	return removed, file.Sync()
+}
@@ -0,0 +1,89 @@
+package feedback
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		emoji     string
+		sentiment string
+		strong    bool
+	}{
+		{"👍", Positive, false},
+		{"👍🏽", Positive, false},
+		{"❤️", Positive, false},
+		{"👎", Negative, true},
+		{"💩", Negative, true},
+		{"😢", Negative, false},
+		{"🤔", Neutral, false},
+		{"", Neutral, false},
+	}
+	for _, tt := range tests {
+		sentiment, strong := Classify(tt.emoji)
+		if sentiment != tt.sentiment || strong != tt.strong {
+			t.Errorf("Classify(%q) = %s, %v; want %s, %v", tt.emoji, sentiment, strong, tt.sentiment, tt.strong)
+		}
+	}
+}
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", FileName)
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Append(Entry{Channel: "telegram", ChatID: "1", Emoji: "👎", Sentiment: Negative, Strong: true, Reply: "Long answer"})
+	l.Append(Entry{Channel: "discord", ChatID: "2", Emoji: "👍", Sentiment: Positive, Reply: "Short answer"})
+	l.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(lines))
+	}
+	var e Entry
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Emoji != "👎" || !e.Strong || e.Time.IsZero() {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestPurgeSender(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.Append(Entry{Channel: "telegram", ChatID: "42", SenderID: "42|alice", Emoji: "👎", Reply: "Direct answer"})
+	l.Append(Entry{Channel: "telegram", ChatID: "-100", SenderID: "42", Emoji: "👍", Reply: "Group answer"})
+	l.Append(Entry{Channel: "telegram", ChatID: "-100", SenderID: "7|bob", Emoji: "👍", Reply: "Other answer"})
+
+	if entries, err := FromSender(path, "42"); err != nil || len(entries) != 2 {
+		t.Fatalf("expected the sender's 2 entries, got %+v (%v)", entries, err)
+	}
+	if n, err := PurgeSender(path, "42"); err != nil || n != 2 {
+		t.Fatalf("PurgeSender: %d %v", n, err)
+	}
+
+	// The open log keeps appending to the rewritten file
+	l.Append(Entry{Channel: "discord", ChatID: "9", SenderID: "9", Emoji: "🔥", Reply: "Later answer"})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "Other answer") || !strings.Contains(lines[1], "Later answer") {
+		t.Errorf("expected only other senders' entries, got:\n%s", data)
+	}
+}
@@ -0,0 +1,132 @@
+package feeds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const rssSample = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<rss version="2.0"><channel>
+  <title>Example News</title>
+  <item>
+    <title>First &amp; foremost</title>
+    <link>https://example.com/1</link>
+    <guid>id-1</guid>
+    <pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate>
+    <description><![CDATA[<p>Hello <b>world</b>&nbsp;!</p>]]></description>
+  </item>
+  <item>
+    <title>Second</title>
+    <link>https://example.com/2</link>
+  </item>
+</channel></rss>`
+
+const atomSample = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Blog</title>
+  <entry>
+    <id>tag:example.com,2024:1</id>
+    <title>Atom entry</title>
+    <link rel="self" href="https://example.com/self"/>
+    <link rel="alternate" href="https://example.com/post"/>
+    <updated>2024-05-01T10:00:00Z</updated>
+    <content type="html">Body text</content>
+  </entry>
+</feed>`
+
+func TestParseRSS(t *testing.T) {
+	feed, err := Parse([]byte(rssSample))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if feed.Title != "Example News" || len(feed.Items) != 2 {
+		t.Fatalf("got title %q with %d items", feed.Title, len(feed.Items))
+	}
+	first := feed.Items[0]
+	if first.Title != "First & foremost" || first.GUID != "id-1" || first.Summary != "Hello world !" {
+		t.Errorf("first item = %+v", first)
+	}
+	if first.Published.IsZero() {
+		t.Error("pubDate not parsed")
+	}
+	if feed.Items[1].key() != "https://example.com/2" {
+		t.Errorf("item without guid keyed by %q, want its link", feed.Items[1].key())
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	feed, err := Parse([]byte(atomSample))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if feed.Title != "Example Blog" || len(feed.Items) != 1 {
+		t.Fatalf("got title %q with %d items", feed.Title, len(feed.Items))
+	}
+	entry := feed.Items[0]
+	if entry.Link != "https://example.com/post" || entry.Summary != "Body text" || entry.Published.IsZero() {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestParseRejectsHTML(t *testing.T) {
+	if _, err := Parse([]byte("<html><body>not a feed</body></html>")); err == nil {
+		t.Error("expected an error for an HTML page")
+	}
+}
+
+func TestServiceDeliversOnlyNewItems(t *testing.T) {
+	body := rssSample
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	store, err := NewStore(filepath.Join(t.TempDir(), "memory.db"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer store.Close()
+
+	var sent []string
+	svc := NewService(store, srv.Client(), func(channel, chatID, content string) {
+		if channel != "telegram" || chatID != "42" {
+			t.Errorf("delivered to %s:%s", channel, chatID)
+		}
+		sent = append(sent, content)
+	})
+
+	ctx := context.Background()
+	sub, _, err := svc.Subscribe(ctx, srv.URL, ChatKey("telegram", "42"), 10*time.Minute)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	// Existing items are marked seen on subscribe
+	svc.pollDue(ctx, time.Now().Add(time.Hour))
+	if len(sent) != 0 {
+		t.Fatalf("pushed existing items: %q", sent)
+	}
+
+	body = strings.Replace(rssSample, "<item>", `<item><title>Breaking</title><link>https://example.com/3</link></item><item>`, 1)
+	svc.pollDue(ctx, time.Now().Add(2*time.Hour))
+	if len(sent) != 1 || !strings.Contains(sent[0], "Breaking") || strings.Contains(sent[0], "Second") {
+		t.Fatalf("sent = %q, want only the new item", sent)
+	}
+
+	svc.pollDue(ctx, time.Now().Add(3*time.Hour))
+	if len(sent) != 1 {
+		t.Errorf("item delivered twice: %q", sent)
+	}
+
+	if err := store.Unsubscribe("telegram:7", sub.ID); err == nil {
+		t.Error("another chat removed the subscription")
+	}
+	if err := store.Unsubscribe("telegram:42", sub.ID); err != nil {
+		t.Errorf("Unsubscribe: %v", err)
+	}
+}
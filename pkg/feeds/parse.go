@@ -0,0 +1,197 @@
+// Package feeds polls RSS and Atom feeds and delivers new items to chats.
+package feeds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Feed is a parsed RSS or Atom document.
+type Feed struct {
+	Title string
+	Items []Item
+}
+
+// Item is one entry of a feed.
+type Item struct {
+	GUID      string
+	Title     string
+	Link      string
+	Summary   string
+	Published time.Time
+}
+
+// key identifies an item for deduplication.
+func (i Item) key() string {
+	switch {
+	case i.GUID != "":
+		return i.GUID
+	case i.Link != "":
+		return i.Link
+	default:
+		return i.Title
+	}
+}
+
+type rssDoc struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"` // RSS 1.0 (RDF) puts items at the top level
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string `xml:"description"`
+}
+
+type atomDoc struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID    string `xml:"id"`
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+}
+
+// Parse detects RSS 2.0, RSS 1.0 or Atom by the root element and parses it.
+func Parse(data []byte) (*Feed, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss", "RDF":
+		var doc rssDoc
+		if err := newDecoder(data).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("invalid RSS: %w", err)
+		}
+		feed := &Feed{Title: strings.TrimSpace(doc.Channel.Title)}
+		for _, it := range append(doc.Channel.Items, doc.Items...) {
+			date := it.PubDate
+			if date == "" {
+				date = it.Date
+			}
+			feed.Items = append(feed.Items, Item{
+				GUID:      strings.TrimSpace(it.GUID),
+				Title:     cleanText(it.Title),
+				Link:      strings.TrimSpace(it.Link),
+				Summary:   cleanText(it.Description),
+				Published: parseDate(date),
+			})
+		}
+		return feed, nil
+
+	case "feed":
+		var doc atomDoc
+		if err := newDecoder(data).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("invalid Atom: %w", err)
+		}
+		feed := &Feed{Title: cleanText(doc.Title)}
+		for _, e := range doc.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			if link == "" && len(e.Links) > 0 {
+				link = e.Links[0].Href
+			}
+			summary := e.Summary
+			if summary == "" {
+				summary = e.Content
+			}
+			date := e.Published
+			if date == "" {
+				date = e.Updated
+			}
+			feed.Items = append(feed.Items, Item{
+				GUID:      strings.TrimSpace(e.ID),
+				Title:     cleanText(e.Title),
+				Link:      strings.TrimSpace(link),
+				Summary:   cleanText(summary),
+				Published: parseDate(date),
+			})
+		}
+		return feed, nil
+
+	default:
+		return nil, fmt.Errorf("not an RSS or Atom feed (root element <%s>)", root)
+	}
+}
+
+func newDecoder(data []byte) *xml.Decoder {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	// Feeds declare all sorts of encodings; pass bytes through and let
+	// invalid characters show rather than failing the whole feed.
+	dec.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+	return dec
+}
+
+func rootElement(data []byte) (string, error) {
+	dec := newDecoder(data)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("not an RSS or Atom feed: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// cleanText strips HTML and collapses whitespace.
+func cleanText(s string) string {
+	s = htmlTag.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02",
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,249 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	pollTick     = time.Minute
+	maxFeedBytes = 5 << 20
+	seenItemTTL  = 90 * 24 * time.Hour
+	userAgent    = "mclaw-feeds/1.0 (+https://github.com/ntminh611/mclaw)"
+)
+
+// NotifyFunc delivers a message to a chat.
+type NotifyFunc func(channel, chatID, content string)
+
+// SummarizeFunc sends a one-shot prompt to the LLM and returns its reply.
+type SummarizeFunc func(ctx context.Context, prompt string) (string, error)
+
+// Service polls subscribed feeds and pushes new items to their chats.
+type Service struct {
+	store     *Store
+	client    *http.Client
+	notify    NotifyFunc
+	summarize SummarizeFunc
+	interval  time.Duration
+	maxItems  int
+}
+
+// NewService creates a feed poller. client should be guarded against
+// private addresses, since feed URLs come from chat users.
+func NewService(store *Store, client *http.Client, notify NotifyFunc) *Service {
+	return &Service{
+		store:    store,
+		client:   client,
+		notify:   notify,
+		interval: time.Hour,
+		maxItems: 5,
+	}
+}
+
+// SetDefaults sets the poll interval for subscriptions that don't choose
+// one and the number of items listed per push.
+func (s *Service) SetDefaults(interval time.Duration, maxItems int) {
+	if interval > 0 {
+		s.interval = interval
+	}
+	if maxItems > 0 {
+		s.maxItems = maxItems
+	}
+}
+
+// SetSummarizer makes pushes an LLM-written digest instead of a plain list.
+func (s *Service) SetSummarizer(fn SummarizeFunc) {
+	s.summarize = fn
+}
+
+// Store returns the subscription store.
+func (s *Service) Store() *Store {
+	return s.store
+}
+
+// Subscribe validates the feed, stores the subscription and marks the
+// current items as seen so only later ones are pushed.
+func (s *Service) Subscribe(ctx context.Context, url, chatKey string, interval time.Duration) (*Subscription, *Feed, error) {
+	if interval <= 0 {
+		interval = s.interval
+	}
+	feed, err := s.Fetch(ctx, url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sub, isNew, err := s.store.Subscribe(url, chatKey, interval)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isNew {
+		now := time.Now()
+		if _, err := s.store.FilterNew(sub.ID, feed.Items, now); err != nil {
+			return nil, nil, err
+		}
+		s.store.MarkChecked(sub.ID, feed.Title, nil, now)
+		sub.Title = feed.Title
+	}
+	return sub, feed, nil
+}
+
+// Fetch downloads and parses a feed.
+func (s *Service) Fetch(ctx context.Context, url string) (*Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed URL: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.5")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch feed: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	return Parse(data)
+}
+
+// Run polls due subscriptions until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollTick)
+	defer ticker.Stop()
+
+	lastPrune := time.Time{}
+	for {
+		now := time.Now()
+		s.pollDue(ctx, now)
+		if now.Sub(lastPrune) > 24*time.Hour {
+			if err := s.store.PruneItems(seenItemTTL, now); err != nil {
+				log.Printf("[feeds] Failed to prune seen items: %v", err)
+			}
+			lastPrune = now
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) pollDue(ctx context.Context, now time.Time) {
+	subs, err := s.store.DueSubscriptions(now)
+	if err != nil {
+		log.Printf("[feeds] Failed to list due subscriptions: %v", err)
+		return
+	}
+	for _, sub := range subs {
+		if ctx.Err() != nil {
+			return
+		}
+		s.poll(ctx, sub)
+	}
+}
+
+func (s *Service) poll(ctx context.Context, sub *Subscription) {
+	fetchCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	feed, err := s.Fetch(fetchCtx, sub.URL)
+	cancel()
+
+	now := time.Now()
+	if err != nil {
+		log.Printf("[feeds] %s: %v", sub.URL, err)
+		s.store.MarkChecked(sub.ID, "", err, now)
+		return
+	}
+
+	fresh, err := s.store.FilterNew(sub.ID, feed.Items, now)
+	if err != nil {
+		log.Printf("[feeds] Failed to record items of %s: %v", sub.URL, err)
+		return
+	}
+	s.store.MarkChecked(sub.ID, feed.Title, nil, now)
+
+	// Subscriptions from config have never been checked; don't flood the
+	// chat with the whole backlog on the first poll.
+	if sub.LastChecked.IsZero() || len(fresh) == 0 {
+		return
+	}
+
+	title := feed.Title
+	if title == "" {
+		title = sub.URL
+	}
+	channel, chatID, ok := strings.Cut(sub.ChatKey, ":")
+	if !ok {
+		log.Printf("[feeds] Subscription #%d has invalid chat key %q", sub.ID, sub.ChatKey)
+		return
+	}
+	s.notify(channel, chatID, s.render(ctx, title, fresh))
+}
+
+// render formats new items as a list, or as a digest when a summarizer is set.
+func (s *Service) render(ctx context.Context, title string, items []Item) string {
+	shown := items
+	if len(shown) > s.maxItems {
+		shown = shown[:s.maxItems]
+	}
+
+	if s.summarize != nil {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Write a short digest of these new items from the feed %q. One line per item with its link; group related items. No preamble.\n\n", title)
+		for _, it := range shown {
+			fmt.Fprintf(&sb, "- %s (%s)\n  %s\n", it.Title, it.Link, truncate(it.Summary, 500))
+		}
+		summaryCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		digest, err := s.summarize(summaryCtx, sb.String())
+		cancel()
+		if err == nil && strings.TrimSpace(digest) != "" {
+			return fmt.Sprintf("📰 %s\n\n%s%s", title, strings.TrimSpace(digest), moreLine(len(items)-len(shown)))
+		}
+		if err != nil {
+			log.Printf("[feeds] Summary failed, sending plain list: %v", err)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📰 %s\n", title)
+	for _, it := range shown {
+		fmt.Fprintf(&sb, "\n• %s", it.Title)
+		if it.Link != "" {
+			fmt.Fprintf(&sb, "\n  %s", it.Link)
+		}
+	}
+	sb.WriteString(moreLine(len(items) - len(shown)))
+	return sb.String()
+}
+
+func moreLine(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\n…and %d more", n)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
+
+// ChatKey builds the "channel:chatID" key subscriptions are stored under.
+func ChatKey(channel, chatID string) string {
+	return channel + ":" + chatID
+}
@@ -0,0 +1,220 @@
+package feeds
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Subscription delivers new items of one feed to one chat.
+type Subscription struct {
+	ID          int64
+	URL         string
+	Title       string
+	ChatKey     string // "channel:chatID"
+	Interval    time.Duration
+	LastChecked time.Time
+	LastError   string
+}
+
+// Store persists subscriptions and the items already delivered.
+type Store struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// NewStore creates or opens the feed tables in the database at dbPath.
+func NewStore(dbPath string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create feeds directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open feeds database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate feeds database: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS feed_subscriptions (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		url          TEXT NOT NULL,
+		title        TEXT NOT NULL DEFAULT '',
+		chat_key     TEXT NOT NULL,
+		interval_s   INTEGER NOT NULL,
+		last_checked INTEGER NOT NULL DEFAULT 0,
+		last_error   TEXT NOT NULL DEFAULT '',
+		UNIQUE(url, chat_key)
+	);
+	CREATE TABLE IF NOT EXISTS feed_items (
+		subscription_id INTEGER NOT NULL,
+		item_key        TEXT NOT NULL,
+		seen_at         INTEGER NOT NULL,
+		PRIMARY KEY (subscription_id, item_key)
+	);
+	`)
+	return err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Subscribe adds a subscription, or updates the interval of an existing one
+// for the same URL and chat. The boolean reports whether it is new.
+func (s *Store) Subscribe(url, chatKey string, interval time.Duration) (*Subscription, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.query(`WHERE url = ? AND chat_key = ?`, url, chatKey)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if _, err := s.db.Exec(`INSERT INTO feed_subscriptions (url, chat_key, interval_s) VALUES (?, ?, ?)
+		ON CONFLICT(url, chat_key) DO UPDATE SET interval_s = excluded.interval_s`,
+		url, chatKey, int64(interval.Seconds())); err != nil {
+		return nil, false, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	subs, err := s.query(`WHERE url = ? AND chat_key = ?`, url, chatKey)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(subs) == 0 {
+		return nil, false, fmt.Errorf("subscription to %s not found after insert", url)
+	}
+	return subs[0], len(existing) == 0, nil
+}
+
+// Unsubscribe removes one of chatKey's subscriptions and its seen items.
+func (s *Store) Unsubscribe(chatKey string, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM feed_subscriptions WHERE id = ? AND chat_key = ?`, id, chatKey)
+	if err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("subscription #%d not found", id)
+	}
+	_, err = s.db.Exec(`DELETE FROM feed_items WHERE subscription_id = ?`, id)
+	return err
+}
+
+// List returns the subscriptions of a chat, or of all chats if chatKey is "".
+func (s *Store) List(chatKey string) ([]*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if chatKey == "" {
+		return s.query(`ORDER BY id`)
+	}
+	return s.query(`WHERE chat_key = ? ORDER BY id`, chatKey)
+}
+
+// DueSubscriptions returns subscriptions whose interval has elapsed.
+func (s *Store) DueSubscriptions(now time.Time) ([]*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.query(`WHERE last_checked + interval_s <= ? ORDER BY last_checked`, now.Unix())
+}
+
+// MarkChecked records a poll, its error (if any) and the feed title.
+func (s *Store) MarkChecked(id int64, title string, checkErr error, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errText := ""
+	if checkErr != nil {
+		errText = checkErr.Error()
+	}
+	_, err := s.db.Exec(`UPDATE feed_subscriptions SET last_checked = ?, last_error = ?,
+		title = CASE WHEN ? != '' THEN ? ELSE title END WHERE id = ?`,
+		now.Unix(), errText, title, title, id)
+	return err
+}
+
+// FilterNew returns the items not delivered before and marks them seen.
+func (s *Store) FilterNew(subscriptionID int64, items []Item, now time.Time) ([]Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var fresh []Item
+	seen := make(map[string]bool)
+	for _, item := range items {
+		key := item.key()
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		res, err := tx.Exec(`INSERT OR IGNORE INTO feed_items (subscription_id, item_key, seen_at) VALUES (?, ?, ?)`,
+			subscriptionID, key, now.Unix())
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			fresh = append(fresh, item)
+		}
+	}
+	return fresh, tx.Commit()
+}
+
+// PruneItems forgets seen items older than maxAge. Feeds only list recent
+// entries, so old keys will not reappear.
+func (s *Store) PruneItems(maxAge time.Duration, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`DELETE FROM feed_items WHERE seen_at < ?`, now.Add(-maxAge).Unix())
+	return err
+}
+
+// query runs a SELECT over subscriptions. Caller must hold s.mu.
+func (s *Store) query(clause string, args ...interface{}) ([]*Subscription, error) {
+	rows, err := s.db.Query(`SELECT id, url, title, chat_key, interval_s, last_checked, last_error FROM feed_subscriptions `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*Subscription
+	for rows.Next() {
+		var sub Subscription
+		var interval, checked int64
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.Title, &sub.ChatKey, &interval, &checked, &sub.LastError); err != nil {
+			return nil, err
+		}
+		sub.Interval = time.Duration(interval) * time.Second
+		if checked > 0 {
+			sub.LastChecked = time.Unix(checked, 0)
+		}
+		subs = append(subs, &sub)
+	}
+	return subs, rows.Err()
+}
@@ -0,0 +1,150 @@
+// Package health watches long-running work for stalls and keeps the
+// systemd watchdog fed while everything is making progress.
+//
+// Work is tracked with Begin. Work running longer than its limit is
+// cancelled; if it is still running after twice the limit the process is
+// reported unhealthy and watchdog pings stop, so systemd restarts mclaw
+// (unanswered messages are replayed from the inbound WAL).
+package health
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// checkInterval is how often work is checked when systemd does not ask
+// for more frequent watchdog pings.
+const checkInterval = 30 * time.Second
+
+// Stall describes work running past its limit.
+type Stall struct {
+	Name    string
+	Detail  string
+	Started time.Time
+	Fatal   bool // past twice the limit and did not stop when cancelled
+}
+
+func (s Stall) String() string {
+	return fmt.Sprintf("%s (%s) running since %s", s.Name, s.Detail, s.Started.Format("15:04:05"))
+}
+
+type task struct {
+	name, detail string
+	started      time.Time
+	limit        time.Duration
+	cancel       context.CancelFunc
+	cancelled    bool
+}
+
+// Monitor tracks running work. The zero value is not usable; a nil
+// *Monitor ignores everything, so callers need not check.
+type Monitor struct {
+	mu    sync.Mutex
+	tasks map[uint64]*task
+	seq   uint64
+}
+
+// NewMonitor creates an empty Monitor.
+func NewMonitor() *Monitor {
+	return &Monitor{tasks: make(map[uint64]*task)}
+}
+
+// Begin records that work has started and returns a function to call when
+// it ends. cancel, if not nil, is called once the work exceeds limit.
+func (m *Monitor) Begin(name, detail string, limit time.Duration, cancel context.CancelFunc) (done func()) {
+	if m == nil || limit <= 0 {
+		return func() {}
+	}
+	m.mu.Lock()
+	m.seq++
+	id := m.seq
+	m.tasks[id] = &task{name: name, detail: detail, started: time.Now(), limit: limit, cancel: cancel}
+	m.mu.Unlock()
+
+	return func() {
+		m.mu.Lock()
+		delete(m.tasks, id)
+		m.mu.Unlock()
+	}
+}
+
+// Check cancels work that has run past its limit and returns every stalled
+// task, oldest first.
+func (m *Monitor) Check(now time.Time) []Stall {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var stalls []Stall
+	for _, t := range m.tasks {
+		age := now.Sub(t.started)
+		if age < t.limit {
+			continue
+		}
+		if !t.cancelled && t.cancel != nil {
+			t.cancel()
+		}
+		t.cancelled = true
+		stalls = append(stalls, Stall{Name: t.name, Detail: t.detail, Started: t.started, Fatal: age >= 2*t.limit})
+	}
+	sort.Slice(stalls, func(i, j int) bool { return stalls[i].Started.Before(stalls[j].Started) })
+	return stalls
+}
+
+// Run tells systemd the service is ready, then checks work periodically
+// and sends watchdog pings while it is healthy, until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	if ok, err := Notify("READY=1"); err != nil {
+		log.Printf("[health] sd_notify failed: %v", err)
+	} else if ok {
+		log.Printf("[health] Notified systemd that mclaw is ready")
+	}
+
+	interval := WatchdogInterval()
+	watchdog := interval > 0
+	if !watchdog || interval > checkInterval {
+		interval = checkInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	reported := make(map[string]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			Notify("STOPPING=1")
+			return
+		case now := <-ticker.C:
+			healthy := true
+			current := make(map[string]bool)
+			for _, s := range m.Check(now) {
+				key := s.String()
+				if s.Fatal {
+					healthy = false
+					key += " fatal"
+				}
+				current[key] = true
+				if reported[key] {
+					continue
+				}
+				if s.Fatal && watchdog {
+					log.Printf("[health] Stuck: %s; stopping watchdog pings so systemd restarts mclaw", s)
+				} else if s.Fatal {
+					log.Printf("[health] Stuck: %s; restart mclaw to recover", s)
+				} else {
+					log.Printf("[health] Cancelled %s", s)
+				}
+			}
+			reported = current // log each stall once
+			if watchdog && healthy {
+				Notify("WATCHDOG=1")
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMonitorCancelsThenFails(t *testing.T) {
+	m := NewMonitor()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := m.Begin("agent", "telegram:1", time.Minute, cancel)
+	m.Begin("agent", "telegram:2", time.Hour, nil)
+	start := time.Now()
+
+	if stalls := m.Check(start.Add(30 * time.Second)); len(stalls) != 0 {
+		t.Fatalf("expected no stalls yet, got %v", stalls)
+	}
+
+	stalls := m.Check(start.Add(90 * time.Second))
+	if len(stalls) != 1 || stalls[0].Detail != "telegram:1" || stalls[0].Fatal {
+		t.Fatalf("expected telegram:1 to be stalled but not fatal, got %+v", stalls)
+	}
+	if ctx.Err() == nil {
+		t.Error("expected stalled work to be cancelled")
+	}
+
+	if stalls := m.Check(start.Add(3 * time.Minute)); len(stalls) != 1 || !stalls[0].Fatal {
+		t.Errorf("expected work ignoring cancellation to become fatal, got %+v", stalls)
+	}
+
+	done()
+	if stalls := m.Check(start.Add(3 * time.Minute)); len(stalls) != 0 {
+		t.Errorf("expected finished work to be forgotten, got %v", stalls)
+	}
+
+	var none *Monitor
+	none.Begin("agent", "x", time.Second, nil)()
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if ok, err := Notify("READY=1"); ok || err != nil {
+		t.Errorf("expected no-op without NOTIFY_SOCKET, got %v %v", ok, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unix datagram sockets unavailable: %v", err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	if ok, err := Notify("WATCHDOG=1"); !ok || err != nil {
+		t.Fatalf("Notify: %v %v", ok, err)
+	}
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFromUnix(buf)
+	if err != nil || string(buf[:n]) != "WATCHDOG=1" {
+		t.Errorf("expected WATCHDOG=1, got %q (%v)", buf[:n], err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	if got := WatchdogInterval(); got != 0 {
+		t.Errorf("expected watchdog off, got %v", got)
+	}
+	t.Setenv("WATCHDOG_USEC", "120000000")
+	t.Setenv("WATCHDOG_PID", "")
+	if got := WatchdogInterval(); got != time.Minute {
+		t.Errorf("expected half of WatchdogSec, got %v", got)
+	}
+	t.Setenv("WATCHDOG_PID", "1")
+	if os.Getpid() != 1 && WatchdogInterval() != 0 {
+		t.Error("expected watchdog meant for another process to be ignored")
+	}
+}
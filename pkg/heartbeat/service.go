@@ -9,6 +9,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
 )
 
 // HeartbeatNote represents an individual heartbeat item
@@ -31,6 +33,8 @@ type HeartbeatService struct {
 	storePath   string
 	store       *HeartbeatStore
 	onHeartbeat func(string) (string, error)
+	template    string
+	sections    []func() string
 	interval    time.Duration
 	enabled     bool
 	mu          sync.RWMutex
@@ -52,6 +56,41 @@ func NewHeartbeatService(workspace string, onHeartbeat func(string) (string, err
 	return hs
 }
 
+// SetTemplate sets the template used by RenderDelivery.
+func (hs *HeartbeatService) SetTemplate(tmpl string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.template = tmpl
+}
+
+// RenderDelivery formats a heartbeat result for delivery to a channel.
+// Without a template the result is delivered as-is. Available variables:
+// {date}, {time}, {result}; see bus.RenderTemplate for channel blocks.
+func (hs *HeartbeatService) RenderDelivery(result, channel string) string {
+	hs.mu.RLock()
+	tmpl := hs.template
+	hs.mu.RUnlock()
+
+	if tmpl == "" {
+		return result
+	}
+
+	now := time.Now()
+	return bus.RenderTemplate(tmpl, map[string]string{
+		"date":   now.Format("2006-01-02"),
+		"time":   now.Format("15:04"),
+		"result": result,
+	}, channel)
+}
+
+// AddPromptSection registers a function whose output is appended to every
+// heartbeat prompt, e.g. due tasks. Empty output is skipped.
+func (hs *HeartbeatService) AddPromptSection(section func() string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.sections = append(hs.sections, section)
+}
+
 func (hs *HeartbeatService) Start() error {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
@@ -172,6 +211,12 @@ Check if there are any tasks you should act on based on the notes above.
 Be proactive in identifying potential issues or improvements.
 `, now, enabledCount, notesList)
 
+	for _, section := range hs.sections {
+		if extra := section(); extra != "" {
+			prompt += "\n" + extra
+		}
+	}
+
 	return prompt
 }
 
@@ -0,0 +1,299 @@
+// Package inbox keeps the files users send to the bot. Channels download
+// attachments to a temporary path; the inbox moves them into
+// workspace/inbox under their original name, records who sent them, and
+// deletes them once they expire, so file tools can work with them in the
+// meantime. Moving a file out of the inbox keeps it.
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metaDir holds one JSON sidecar per file, out of the way of listings.
+const metaDir = ".meta"
+
+// sweepInterval is how often expired files are looked for.
+const sweepInterval = time.Hour
+
+// Item describes a received file.
+type Item struct {
+	Path     string    `json:"-"`
+	Name     string    `json:"name"`     // file name in the inbox
+	Original string    `json:"original"` // name the sender gave it
+	Size     int64     `json:"size"`
+	Channel  string    `json:"channel"`
+	ChatID   string    `json:"chat_id"`
+	Sender   string    `json:"sender"`
+	Received time.Time `json:"received"`
+	Expires  time.Time `json:"expires,omitempty"` // zero = kept until moved or deleted
+}
+
+// Inbox is a directory of received files.
+type Inbox struct {
+	dir       string
+	retention time.Duration
+	mu        sync.Mutex // serializes name allocation
+}
+
+// New returns an inbox in dir. Files expire after retention; zero keeps
+// them until they are deleted by hand.
+func New(dir string, retention time.Duration) *Inbox {
+	return &Inbox{dir: dir, retention: retention}
+}
+
+// Dir returns the inbox directory.
+func (in *Inbox) Dir() string {
+	return in.dir
+}
+
+// Ingest moves the downloaded file at src into the inbox under original
+// (or src's name when empty) and records its metadata.
+func (in *Inbox) Ingest(src, original, channel, chatID, sender string) (*Item, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if original == "" {
+		original = filepath.Base(src)
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Join(in.dir, metaDir), 0755); err != nil {
+		return nil, err
+	}
+	name := in.freeName(sanitize(original))
+	dst := filepath.Join(in.dir, name)
+	if err := move(src, dst); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	item := &Item{
+		Path:     dst,
+		Name:     name,
+		Original: original,
+		Size:     info.Size(),
+		Channel:  channel,
+		ChatID:   chatID,
+		Sender:   sender,
+		Received: now,
+	}
+	if in.retention > 0 {
+		item.Expires = now.Add(in.retention)
+	}
+	data, _ := json.MarshalIndent(item, "", "  ")
+	if err := os.WriteFile(in.metaPath(name), data, 0644); err != nil {
+		return item, fmt.Errorf("save metadata: %w", err)
+	}
+	return item, nil
+}
+
+// List returns the files in the inbox with their metadata. Files put there
+// by hand have no sender and never expire.
+func (in *Inbox) List() ([]*Item, error) {
+	entries, err := os.ReadDir(in.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []*Item
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		item, err := in.load(e.Name())
+		if err != nil {
+			info, statErr := e.Info()
+			if statErr != nil {
+				continue
+			}
+			item = &Item{Name: e.Name(), Original: e.Name(), Size: info.Size(), Received: info.ModTime()}
+		}
+		item.Path = filepath.Join(in.dir, e.Name())
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// FromSender returns the files sent by id. A sender recorded as
+// "123|username" matches id "123".
+func (in *Inbox) FromSender(id string) ([]*Item, error) {
+	items, err := in.List()
+	if err != nil {
+		return nil, err
+	}
+	var out []*Item
+	for _, item := range items {
+		if sender, _, _ := strings.Cut(item.Sender, "|"); item.Sender != "" && sender == id {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// Remove deletes a file and its metadata.
+func (in *Inbox) Remove(name string) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if err := os.Remove(filepath.Join(in.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(in.metaPath(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Sweep deletes files that expired by now and metadata of files that were
+// moved away. It returns how many files were deleted.
+func (in *Inbox) Sweep(now time.Time) (int, error) {
+	metas, err := os.ReadDir(filepath.Join(in.dir, metaDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	removed := 0
+	for _, e := range metas {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok {
+			continue
+		}
+		path := filepath.Join(in.dir, name)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			os.Remove(in.metaPath(name))
+			continue
+		}
+		item, err := in.load(name)
+		if err != nil || item.Expires.IsZero() || item.Expires.After(now) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("[inbox] Failed to delete expired %s: %v", name, err)
+			continue
+		}
+		os.Remove(in.metaPath(name))
+		removed++
+	}
+	return removed, nil
+}
+
+// Run sweeps expired files until ctx is cancelled.
+func (in *Inbox) Run(ctx context.Context) {
+	if in.retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		if n, err := in.Sweep(time.Now()); err != nil {
+			log.Printf("[inbox] Sweep failed: %v", err)
+		} else if n > 0 {
+			log.Printf("[inbox] Deleted %d expired file(s)", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (in *Inbox) load(name string) (*Item, error) {
+	data, err := os.ReadFile(in.metaPath(name))
+	if err != nil {
+		return nil, err
+	}
+	var item Item
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (in *Inbox) metaPath(name string) string {
+	return filepath.Join(in.dir, metaDir, name+".json")
+}
+
+// freeName returns name, or "name-2.ext", "name-3.ext"... if it is taken.
+// Caller must hold in.mu.
+func (in *Inbox) freeName(name string) string {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(filepath.Join(in.dir, candidate)); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+}
+
+// sanitize turns a sender-supplied name into a safe file name.
+func sanitize(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, r == 0x7f, r == '/', r == ':', r == '*', r == '?', r == '"', r == '<', r == '>', r == '|':
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+	if r := []rune(name); len(r) > 100 {
+		ext := filepath.Ext(name)
+		if len([]rune(ext)) > 10 {
+			ext = ""
+		}
+		name = string(r[:100-len([]rune(ext))]) + ext
+	}
+	if name == "" {
+		name = "file"
+	}
+	return name
+}
+
+// move renames src to dst, copying when they are on different filesystems.
+func move(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,108 @@
+package inbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func download(t *testing.T, content string) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "dl-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(content)
+	f.Close()
+	return f.Name()
+}
+
+func TestIngest(t *testing.T) {
+	in := New(filepath.Join(t.TempDir(), "inbox"), 24*time.Hour)
+
+	src := download(t, "quarterly numbers")
+	item, err := in.Ingest(src, "Q3 report.pdf", "telegram", "42", "7|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Name != "Q3 report.pdf" || item.Size != 17 || item.Expires.IsZero() {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("expected the download to be moved")
+	}
+	if data, _ := os.ReadFile(item.Path); string(data) != "quarterly numbers" {
+		t.Errorf("unexpected content %q", data)
+	}
+
+	// Same name again gets a suffix; unsafe names are cleaned up
+	second, _ := in.Ingest(download(t, "v2"), "Q3 report.pdf", "telegram", "42", "7|alice")
+	if second.Name != "Q3 report-2.pdf" {
+		t.Errorf("expected a deduplicated name, got %q", second.Name)
+	}
+	third, _ := in.Ingest(download(t, "x"), "../../etc/passwd", "discord", "9", "1")
+	if third.Name != "passwd" || filepath.Dir(third.Path) != in.Dir() {
+		t.Errorf("expected the name confined to the inbox, got %q", third.Path)
+	}
+
+	items, err := in.List()
+	if err != nil || len(items) != 3 {
+		t.Fatalf("List = %d items, %v", len(items), err)
+	}
+	for _, it := range items {
+		if it.Name == "Q3 report.pdf" && (it.Sender != "7|alice" || it.ChatID != "42") {
+			t.Errorf("expected metadata kept, got %+v", it)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	in := New(filepath.Join(t.TempDir(), "inbox"), time.Hour)
+	old, _ := in.Ingest(download(t, "a"), "old.txt", "telegram", "1", "1")
+	kept, _ := in.Ingest(download(t, "b"), "kept.txt", "telegram", "1", "1")
+
+	// The user moved one file out to keep it
+	moved := filepath.Join(t.TempDir(), "kept.txt")
+	if err := os.Rename(kept.Path, moved); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := in.Sweep(time.Now()); err != nil || n != 0 {
+		t.Fatalf("Sweep before expiry = %d, %v", n, err)
+	}
+	if _, err := os.Stat(in.metaPath("kept.txt")); !os.IsNotExist(err) {
+		t.Error("expected metadata of a moved file to be dropped")
+	}
+
+	if n, _ := in.Sweep(time.Now().Add(2 * time.Hour)); n != 1 {
+		t.Errorf("expected 1 expired file deleted, got %d", n)
+	}
+	if _, err := os.Stat(old.Path); !os.IsNotExist(err) {
+		t.Error("expected the expired file to be deleted")
+	}
+	if _, err := os.Stat(moved); err != nil {
+		t.Error("expected the moved file to survive")
+	}
+}
+
+func TestFromSenderAndRemove(t *testing.T) {
+	in := New(filepath.Join(t.TempDir(), "inbox"), 0)
+	a, _ := in.Ingest(download(t, "a"), "a.txt", "telegram", "42", "7|alice")
+	in.Ingest(download(t, "b"), "b.txt", "telegram", "42", "8")
+	os.WriteFile(filepath.Join(in.Dir(), "by-hand.txt"), []byte("c"), 0644)
+
+	items, err := in.FromSender("7")
+	if err != nil || len(items) != 1 || items[0].Name != "a.txt" {
+		t.Fatalf("FromSender = %+v, %v", items, err)
+	}
+	if err := in.Remove(a.Name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(in.metaPath(a.Name)); !os.IsNotExist(err) {
+		t.Error("expected the metadata removed with the file")
+	}
+	if items, _ := in.List(); len(items) != 2 {
+		t.Errorf("expected 2 files left, got %d", len(items))
+	}
+}
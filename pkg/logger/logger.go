@@ -34,6 +34,7 @@ var (
 	logger       *Logger
 	once         sync.Once
 	mu           sync.RWMutex
+	redactor     func(string) string
 )
 
 type Logger struct {
@@ -67,6 +68,14 @@ func GetLevel() LogLevel {
 	return currentLevel
 }
 
+// SetRedactor installs a filter applied to every message and field value
+// before it is written, so credentials never reach the console or log file.
+func SetRedactor(fn func(string) string) {
+	mu.Lock()
+	defer mu.Unlock()
+	redactor = fn
+}
+
 func EnableFileLogging(filePath string) error {
 	mu.Lock()
 	defer mu.Unlock()
@@ -101,6 +110,27 @@ func logMessage(level LogLevel, component string, message string, fields map[str
 		return
 	}
 
+	mu.RLock()
+	scrub := redactor
+	mu.RUnlock()
+	if scrub != nil {
+		message = scrub(message)
+		if len(fields) > 0 {
+			scrubbed := make(map[string]interface{}, len(fields))
+			for k, v := range fields {
+				switch v := v.(type) {
+				case string:
+					scrubbed[k] = scrub(v)
+				case error:
+					scrubbed[k] = scrub(v.Error())
+				default:
+					scrubbed[k] = v
+				}
+			}
+			fields = scrubbed
+		}
+	}
+
 	entry := LogEntry{
 		Level:     logLevelNames[level],
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
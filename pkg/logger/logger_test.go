@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -137,3 +140,23 @@ func TestLoggerHelperFunctions(t *testing.T) {
 	DebugC("test", "Debug with component")
 	WarnF("Warning with fields", map[string]interface{}{"key": "value"})
 }
+
+func TestRedactor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mclaw.log")
+	if err := EnableFileLogging(path); err != nil {
+		t.Fatal(err)
+	}
+	defer DisableFileLogging()
+	SetRedactor(func(s string) string { return strings.ReplaceAll(s, "sk-secret", "[REDACTED]") })
+	defer SetRedactor(nil)
+
+	InfoCF("test", "calling with sk-secret", map[string]interface{}{"key": "sk-secret", "n": 1})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-secret") {
+		t.Errorf("expected secret to be masked in the log file, got %s", data)
+	}
+}
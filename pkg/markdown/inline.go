@@ -0,0 +1,304 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var autolinkRe = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*|[^\s<>@]+@[^\s<>@]+\.[a-zA-Z]+)>`)
+
+// parseInline parses the text of a paragraph, heading or cell. Line breaks
+// become Break nodes: chat replies are shown with the lines as written.
+func parseInline(s string) []*Node {
+	var out []*Node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			out = append(out, &Node{Kind: Text, Text: text.String()})
+			text.Reset()
+		}
+	}
+	add := func(n *Node) {
+		flush()
+		out = append(out, n)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			add(&Node{Kind: Break})
+			i += 2
+			continue
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '\n':
+			// Trailing spaces before a break are not content
+			t := strings.TrimRight(text.String(), " ")
+			text.Reset()
+			text.WriteString(t)
+			add(&Node{Kind: Break})
+			i++
+			continue
+		case c == '`':
+			n := runLen(s, i, '`')
+			if end := codeClose(s, i+n, n); end >= 0 {
+				add(&Node{Kind: Code, Text: codeText(s[i+n : end])})
+				i = end + n
+			} else {
+				text.WriteString(s[i : i+n])
+				i += n
+			}
+			continue
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if node, end, ok := parseLink(s, i+1); ok {
+				node.Kind = Image
+				add(node)
+				i = end
+				continue
+			}
+		case c == '[':
+			if node, end, ok := parseLink(s, i); ok {
+				add(node)
+				i = end
+				continue
+			}
+		case c == '<':
+			if m := autolinkRe.FindStringSubmatch(s[i:]); m != nil {
+				url := m[1]
+				if !strings.Contains(url, ":") {
+					url = "mailto:" + url
+				}
+				add(&Node{Kind: Link, URL: url, Children: []*Node{{Kind: Text, Text: m[1]}}})
+				i += len(m[0])
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			if node, end, ok := parseEmphasis(s, i); ok {
+				add(node)
+				i = end
+				continue
+			}
+			n := runLen(s, i, c)
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return out
+}
+
+// parseLink parses "[text](url)" starting at the bracket.
+func parseLink(s string, i int) (*Node, int, bool) {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			n := runLen(s, j, '`')
+			if end := codeClose(s, j+n, n); end >= 0 {
+				j = end + n - 1
+			} else {
+				j += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if j+1 >= len(s) || s[j+1] != '(' {
+				return nil, 0, false
+			}
+			url, end, ok := parseDestination(s, j+2)
+			if !ok {
+				return nil, 0, false
+			}
+			return &Node{Kind: Link, URL: url, Children: parseInline(s[i+1 : j])}, end, true
+		}
+	}
+	return nil, 0, false
+}
+
+// parseDestination parses `url "title")` and returns the url and the
+// index after the closing parenthesis.
+func parseDestination(s string, i int) (string, int, bool) {
+	i = skipSpaces(s, i)
+	var url string
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexAny(s[i:], ">\n")
+		if end < 0 || s[i+end] != '>' {
+			return "", 0, false
+		}
+		url, i = s[i+1:i+end], i+end+1
+	} else {
+		start, depth := i, 0
+	loop:
+		for ; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s):
+				i++
+			case c == '(':
+				depth++
+			case c == ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case c == ' ' || c == '\n' || c == '\t':
+				break loop
+			}
+		}
+		url = s[start:i]
+	}
+
+	i = skipSpaces(s, i)
+	if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+		end := strings.IndexByte(s[i+1:], s[i])
+		if end < 0 {
+			return "", 0, false
+		}
+		i = skipSpaces(s, i+end+2)
+	}
+	if i >= len(s) || s[i] != ')' {
+		return "", 0, false
+	}
+	return unescape(url), i + 1, true
+}
+
+// parseEmphasis parses *em*, **strong**, ***both***, the _ forms and
+// ~~strike~~ starting at i. Delimiters must hug the text they wrap, and
+// underscores inside words (snake_case) are left alone.
+func parseEmphasis(s string, i int) (*Node, int, bool) {
+	c := s[i]
+	n := runLen(s, i, c)
+	if c == '~' && n != 2 || n > 3 {
+		return nil, 0, false
+	}
+	start := i + n
+	if start >= len(s) || isSpace(s[start]) {
+		return nil, 0, false
+	}
+	if c == '_' && wordBefore(s, i) {
+		return nil, 0, false
+	}
+
+	for j := start; j < len(s); {
+		switch s[j] {
+		case '\\':
+			j += 2
+			continue
+		case '`':
+			m := runLen(s, j, '`')
+			if end := codeClose(s, j+m, m); end >= 0 {
+				j = end + m
+			} else {
+				j += m
+			}
+			continue
+		case c:
+			m := runLen(s, j, c)
+			if m == n && !isSpace(s[j-1]) && (c != '_' || !wordAfter(s, j+m)) {
+				inner := parseInline(s[start:j])
+				var node *Node
+				switch {
+				case c == '~':
+					node = &Node{Kind: Strike, Children: inner}
+				case n == 1:
+					node = &Node{Kind: Emph, Children: inner}
+				case n == 2:
+					node = &Node{Kind: Strong, Children: inner}
+				default:
+					node = &Node{Kind: Strong, Children: []*Node{{Kind: Emph, Children: inner}}}
+				}
+				return node, j + m, true
+			}
+			j += m
+			continue
+		}
+		j++
+	}
+	return nil, 0, false
+}
+
+// codeClose finds the backtick run of length n that closes a code span.
+func codeClose(s string, from, n int) int {
+	for j := from; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := runLen(s, j, '`')
+		if m == n {
+			return j
+		}
+		j += m
+	}
+	return -1
+}
+
+// codeText normalizes a code span: line breaks become spaces and one
+// padding space on each side is dropped.
+func codeText(code string) string {
+	code = strings.ReplaceAll(code, "\n", " ")
+	if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+		code = code[1 : len(code)-1]
+	}
+	return code
+}
+
+func runLen(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+func wordBefore(s string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return i > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func wordAfter(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return i < len(s) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isPunct(c byte) bool {
+	return c < 0x80 && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
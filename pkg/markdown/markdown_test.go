@@ -0,0 +1,117 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTelegramHTMLInline(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"**bold** and *italic* and ~~gone~~", "<b>bold</b> and <i>italic</i> and <s>gone</s>"},
+		{"***both*** __strong__ _em_", "<b><i>both</i></b> <b>strong</b> <i>em</i>"},
+		{"**bold with *italic* inside**", "<b>bold with <i>italic</i> inside</b>"},
+		{"a < b && c > d", "a &lt; b &amp;&amp; c &gt; d"},
+		{"`x < 1 && **y**`", "<code>x &lt; 1 &amp;&amp; **y**</code>"},
+		{"my_var_name and 5 * 3 * 2", "my_var_name and 5 * 3 * 2"},
+		{`\*not italic\*`, "*not italic*"},
+		{"[docs](https://example.com/a_(b)?q=1&r=2)", `<a href="https://example.com/a_(b)?q=1&amp;r=2">docs</a>`},
+		{"[**bold** link](https://x.io \"title\")", `<a href="https://x.io"><b>bold</b> link</a>`},
+		{"[relative](./file.md)", "relative"},
+		{"<https://go.dev>", `<a href="https://go.dev">https://go.dev</a>`},
+		{"**unclosed and *half", "**unclosed and *half"},
+		{"line one  \nline two", "line one\nline two"},
+	}
+	for _, c := range cases {
+		if got := TelegramHTML(c.in); got != c.want {
+			t.Errorf("TelegramHTML(%q)\n got: %q\nwant: %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestTelegramHTMLBlocks(t *testing.T) {
+	src := "# Plan\n\nIntro with <tags>.\n\n```go\nif a < b {\n}\n```\n\n> quoted **text**\n> more\n\n---\n\nDone"
+	want := "<b>Plan</b>\n\nIntro with &lt;tags&gt;.\n\n<pre><code class=\"language-go\">if a &lt; b {\n}</code></pre>\n\n<blockquote>quoted <b>text</b>\nmore</blockquote>\n\n———\n\nDone"
+	if got := TelegramHTML(src); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNestedLists(t *testing.T) {
+	src := `Steps:
+1. Install
+   - run ` + "`make`" + `
+   - check output
+2. Configure
+  * nested with two spaces
+    more text for it
+
+3. Start`
+	want := `Steps:
+1. Install
+  ◦ run make
+  ◦ check output
+2. Configure
+  ◦ nested with two spaces
+    more text for it
+3. Start`
+	if got := Plain(src); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := Discord("- a\n  - b\n- c"); got != "- a\n  - b\n- c" {
+		t.Errorf("Discord nested list = %q", got)
+	}
+	// A year at the start of a line does not start a list
+	if got := Plain("It was great.\n2024. What a year"); got != "It was great.\n2024. What a year" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestTables(t *testing.T) {
+	src := "Results:\n\n| Name | Score | Note |\n|:-----|------:|:----:|\n| Alice | 9 | **top** |\n| Bob | 10 | a\\|b |\n\nEnd"
+	want := "<pre>Name  | Score | Note\n------+-------+-----\nAlice |     9 | top\nBob   |    10 | a|b</pre>"
+	got := TelegramHTML(src)
+	if !strings.Contains(got, want) {
+		t.Errorf("got:\n%s\nwant table:\n%s", got, want)
+	}
+	if !strings.HasPrefix(got, "Results:\n\n") || !strings.HasSuffix(got, "\n\nEnd") {
+		t.Errorf("expected the surrounding text kept, got %q", got)
+	}
+
+	// Wide characters are counted twice so columns line up
+	if got := Plain("| 名前 | x |\n|---|---|\n| ab | y |"); !strings.Contains(got, "名前 | x\n-----+--\nab   | y") {
+		t.Errorf("got:\n%s", got)
+	}
+	// Without a matching delimiter row it is just text
+	if got := Plain("a | b\nc | d"); got != "a | b\nc | d" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestOtherFormats(t *testing.T) {
+	src := "## Title\n\n**Bold**, *it*, ~~no~~ and [site](https://a.com).\n\n| a | b |\n|---|---|\n| 1 | 2 |"
+	if got := WhatsApp(src); got != "*Title*\n\n*Bold*, _it_, ~no~ and site (https://a.com).\n\n```a | b\n--+--\n1 | 2```" {
+		t.Errorf("WhatsApp:\n%s", got)
+	}
+	if got := Discord(src); got != "## Title\n\n**Bold**, *it*, ~~no~~ and [site](https://a.com).\n\n```\na | b\n--+--\n1 | 2\n```" {
+		t.Errorf("Discord:\n%s", got)
+	}
+	if got := Plain(src); got != "Title\n\nBold, it, no and site (https://a.com).\n\na | b\n--+--\n1 | 2" {
+		t.Errorf("Plain:\n%s", got)
+	}
+	if got := Slack("**Bold** [a & b](https://a.com?x=1) <3"); got != "*Bold* <https://a.com?x=1|a &amp; b> &lt;3" {
+		t.Errorf("Slack: %s", got)
+	}
+	if got := Render(src, FormatMarkdown); got != src {
+		t.Errorf("expected markdown left as written, got %q", got)
+	}
+}
+
+func TestUnclosedFence(t *testing.T) {
+	got := TelegramHTML("Look:\n```\nx := <-ch")
+	if got != "Look:\n<pre>x := &lt;-ch</pre>" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,375 @@
+// Package markdown parses the Markdown that models write into a small AST
+// and renders it for chat platforms: Telegram HTML, Discord and WhatsApp
+// markup, or plain text. It covers the CommonMark/GFM subset that shows
+// up in replies (headings, emphasis, code, links, quotes, nested lists,
+// tables) and never fails: anything it does not recognize is kept as text.
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of a Node.
+type Kind int
+
+const (
+	Document Kind = iota
+	Paragraph
+	Heading
+	CodeBlock
+	Quote
+	List
+	Item
+	Table
+	Row
+	Cell
+	Rule
+	Text
+	Strong
+	Emph
+	Strike
+	Code
+	Link
+	Image
+	Break
+)
+
+// Align is a table column's alignment.
+type Align int
+
+const (
+	AlignNone Align = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// Node is an element of the document tree.
+type Node struct {
+	Kind     Kind
+	Children []*Node
+	Text     string  // Text, Code and CodeBlock content
+	Level    int     // Heading level, 1-6
+	Lang     string  // CodeBlock language
+	URL      string  // Link and Image destination
+	Ordered  bool    // List
+	Start    int     // first number of an ordered List
+	Header   bool    // Row: the table's header row
+	Align    []Align // Table: per column
+	Tight    bool    // follows the previous block without a blank line
+}
+
+var (
+	fenceRe   = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	headingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?[ \t]*$`)
+	quoteRe   = regexp.MustCompile(`^ {0,3}> ?`)
+	itemRe    = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])([ \t]+|$)`)
+	setextRe  = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	delimRe   = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	closingRe = regexp.MustCompile(`[ \t]+#+$`)
+)
+
+// Parse builds the document tree of src.
+func Parse(src string) *Node {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	return &Node{Kind: Document, Children: parseBlocks(lines)}
+}
+
+func parseBlocks(lines []string) []*Node {
+	var blocks []*Node
+	tight := false
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		var node *Node
+		switch {
+		case isBlank(line):
+			i++
+			tight = false
+			continue
+		case fenceRe.MatchString(line):
+			node, i = parseFence(lines, i)
+		case headingRe.MatchString(line):
+			node, i = parseHeading(line), i+1
+		case isRule(line):
+			node, i = &Node{Kind: Rule}, i+1
+		case quoteRe.MatchString(line):
+			node, i = parseQuote(lines, i)
+		case itemRe.MatchString(line):
+			node, i = parseList(lines, i)
+		case isTableStart(lines, i):
+			node, i = parseTable(lines, i)
+		default:
+			node, i = parseParagraph(lines, i)
+		}
+		node.Tight = tight && len(blocks) > 0
+		blocks = append(blocks, node)
+		tight = true
+	}
+	return blocks
+}
+
+func parseFence(lines []string, i int) (*Node, int) {
+	m := fenceRe.FindStringSubmatch(lines[i])
+	indent, fence := len(m[1]), m[2]
+	lang := strings.Fields(m[3])
+	node := &Node{Kind: CodeBlock}
+	if len(lang) > 0 {
+		node.Lang = lang[0]
+	}
+
+	var body []string
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" && indentOf(line) < 4 {
+			i++
+			break
+		}
+		body = append(body, line[min(indent, indentOf(line)):])
+	}
+	node.Text = strings.Join(body, "\n")
+	return node, i
+}
+
+func parseHeading(line string) *Node {
+	m := headingRe.FindStringSubmatch(line)
+	text := closingRe.ReplaceAllString(m[2], "")
+	if strings.Trim(text, "#") == "" {
+		text = ""
+	}
+	return &Node{Kind: Heading, Level: len(m[1]), Children: parseInline(text)}
+}
+
+func parseQuote(lines []string, i int) (*Node, int) {
+	var body []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if loc := quoteRe.FindStringIndex(line); loc != nil {
+			body = append(body, line[loc[1]:])
+			continue
+		}
+		// A paragraph in the quote continues on unmarked lines
+		if isBlank(line) || startsBlock(line) || len(body) == 0 || isBlank(body[len(body)-1]) {
+			break
+		}
+		body = append(body, line)
+	}
+	return &Node{Kind: Quote, Children: parseBlocks(body)}, i
+}
+
+func parseList(lines []string, i int) (*Node, int) {
+	first := itemRe.FindStringSubmatch(lines[i])
+	list := &Node{Kind: List, Ordered: isDigit(first[2][0])}
+	if list.Ordered {
+		list.Start, _ = strconv.Atoi(first[2][:len(first[2])-1])
+	}
+
+	for i < len(lines) {
+		m := itemRe.FindStringSubmatch(lines[i])
+		if m == nil || isDigit(m[2][0]) != list.Ordered || isRule(lines[i]) {
+			break
+		}
+		markerIndent := len(m[1])
+		content := len(m[0])
+		if len(m[3]) > 4 || m[3] == "" {
+			content = len(m[1]) + len(m[2]) + 1 // code-like indent or empty item
+		}
+		body := []string{strings.TrimLeft(lines[i][min(content, len(lines[i])):], " ")}
+
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			indent := indentOf(line)
+			switch {
+			case isBlank(line):
+				// The item goes on if the next text is indented into it
+				next := i + 1
+				for next < len(lines) && isBlank(lines[next]) {
+					next++
+				}
+				if next < len(lines) && indentOf(lines[next]) >= content {
+					body = append(body, "")
+					continue
+				}
+			case indent >= content,
+				itemRe.MatchString(line) && indent >= markerIndent+2: // nested list, loosely indented
+				body = append(body, line[min(indent, content):])
+				continue
+			case !itemRe.MatchString(line) && !startsBlock(line) && !isBlank(body[len(body)-1]):
+				body = append(body, strings.TrimLeft(line, " ")) // lazy continuation
+				continue
+			}
+			break
+		}
+		list.Children = append(list.Children, &Node{Kind: Item, Children: parseBlocks(body)})
+
+		// Blank lines between items keep the list going
+		next := i
+		for next < len(lines) && isBlank(lines[next]) {
+			next++
+		}
+		if next < len(lines) && itemRe.MatchString(lines[next]) && indentOf(lines[next]) <= markerIndent+1 {
+			i = next
+		}
+	}
+	return list, i
+}
+
+func parseTable(lines []string, i int) (*Node, int) {
+	header := splitRow(lines[i])
+	table := &Node{Kind: Table}
+	for _, d := range splitRow(lines[i+1]) {
+		left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":")
+		switch {
+		case left && right:
+			table.Align = append(table.Align, AlignCenter)
+		case right:
+			table.Align = append(table.Align, AlignRight)
+		case left:
+			table.Align = append(table.Align, AlignLeft)
+		default:
+			table.Align = append(table.Align, AlignNone)
+		}
+	}
+
+	row := func(cells []string, header bool) *Node {
+		r := &Node{Kind: Row, Header: header}
+		for c := 0; c < len(table.Align); c++ {
+			text := ""
+			if c < len(cells) {
+				text = cells[c]
+			}
+			r.Children = append(r.Children, &Node{Kind: Cell, Children: parseInline(text)})
+		}
+		return r
+	}
+	table.Children = append(table.Children, row(header, true))
+	for i += 2; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) || !strings.Contains(line, "|") || startsBlock(line) {
+			break
+		}
+		table.Children = append(table.Children, row(splitRow(line), false))
+	}
+	return table, i
+}
+
+func parseParagraph(lines []string, i int) (*Node, int) {
+	var body []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			break
+		}
+		if len(body) > 0 {
+			if m := setextRe.FindStringSubmatch(line); m != nil {
+				level := 2
+				if m[1][0] == '=' {
+					level = 1
+				}
+				return &Node{Kind: Heading, Level: level, Children: parseInline(strings.Join(body, "\n"))}, i + 1
+			}
+			if interrupts(line) || isTableStart(lines, i) {
+				break
+			}
+		}
+		body = append(body, strings.TrimLeft(line, " "))
+	}
+	return &Node{Kind: Paragraph, Children: parseInline(strings.Join(body, "\n"))}, i
+}
+
+// startsBlock reports whether line opens a block other than a paragraph.
+func startsBlock(line string) bool {
+	return fenceRe.MatchString(line) || headingRe.MatchString(line) || isRule(line) ||
+		quoteRe.MatchString(line) || itemRe.MatchString(line)
+}
+
+// interrupts reports whether line ends the paragraph before it. As in
+// CommonMark, an ordered list only does so when it starts at 1, so a line
+// such as "2024. What a year" stays in the paragraph.
+func interrupts(line string) bool {
+	if m := itemRe.FindStringSubmatch(line); m != nil {
+		if strings.TrimSpace(line[len(m[0]):]) == "" {
+			return false
+		}
+		return !isDigit(m[2][0]) || strings.TrimLeft(m[2][:len(m[2])-1], "0") == "1"
+	}
+	return startsBlock(line)
+}
+
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") || !delimRe.MatchString(lines[i+1]) {
+		return false
+	}
+	return len(splitRow(lines[i])) == len(splitRow(lines[i+1]))
+}
+
+// splitRow splits a table row into its cells. Pipes inside code spans or
+// escaped as \| do not separate cells.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+			continue
+		case c == '`':
+			inCode = !inCode
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(c)
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func isRule(line string) bool {
+	if indentOf(line) > 3 {
+		return false
+	}
+	t := strings.ReplaceAll(strings.TrimSpace(line), " ", "")
+	if len(t) < 3 || !strings.ContainsRune("-*_", rune(t[0])) {
+		return false
+	}
+	return strings.Trim(t, t[:1]) == ""
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// expandTabs turns leading tabs into four spaces each.
+func expandTabs(line string) string {
+	n := 0
+	for n < len(line) && (line[n] == ' ' || line[n] == '\t') {
+		n++
+	}
+	if !strings.Contains(line[:n], "\t") {
+		return line
+	}
+	return strings.ReplaceAll(line[:n], "\t", "    ") + line[n:]
+}
@@ -0,0 +1,422 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// style describes a chat platform's markup.
+type style struct {
+	escape  func(string) string
+	strong  [2]string
+	emph    [2]string
+	strike  [2]string
+	code    func(string) string            // inline code; gets raw text
+	pre     func(lang, text string) string // code blocks and tables; gets raw text
+	link    func(text, url string) string  // text is already rendered
+	heading func(level int, text string) string
+	quote   func(text string) string // text is the rendered blocks
+	rule    string
+	bullets []string // by nesting depth, repeating
+}
+
+// Format names a markup a reply can be rendered in.
+type Format string
+
+const (
+	FormatMarkdown     Format = "markdown" // as the model wrote it
+	FormatTelegramHTML Format = "telegram_html"
+	FormatDiscord      Format = "discord"
+	FormatSlack        Format = "slack"
+	FormatWhatsApp     Format = "whatsapp"
+	FormatPlain        Format = "plain"
+)
+
+var styles = map[Format]*style{
+	FormatTelegramHTML: telegramStyle,
+	FormatDiscord:      discordStyle,
+	FormatSlack:        slackStyle,
+	FormatWhatsApp:     whatsappStyle,
+	FormatPlain:        plainStyle,
+}
+
+// Known reports whether f is a format Render supports.
+func Known(f Format) bool {
+	return f == FormatMarkdown || styles[f] != nil
+}
+
+// Render renders src in format f. Markdown and unknown formats return src
+// unchanged.
+func Render(src string, f Format) string {
+	st := styles[f]
+	if st == nil {
+		return src
+	}
+	return render(Parse(src), st)
+}
+
+// TelegramHTML renders src in the HTML subset of Telegram's Bot API
+// (parse_mode HTML). Tables become preformatted text and links with
+// schemes Telegram rejects are shown as plain text.
+func TelegramHTML(src string) string {
+	return render(Parse(src), telegramStyle)
+}
+
+// Discord renders src as Discord-flavored Markdown, which has no tables
+// and only three heading levels.
+func Discord(src string) string {
+	return render(Parse(src), discordStyle)
+}
+
+// Slack renders src as Slack mrkdwn.
+func Slack(src string) string {
+	return render(Parse(src), slackStyle)
+}
+
+// WhatsApp renders src with WhatsApp's *bold*, _italic_, ~strike~ and
+// ``` markup.
+func WhatsApp(src string) string {
+	return render(Parse(src), whatsappStyle)
+}
+
+// Plain renders src as plain text, for platforms that show markup as-is.
+func Plain(src string) string {
+	return render(Parse(src), plainStyle)
+}
+
+var telegramStyle = &style{
+	escape: html.EscapeString,
+	strong: [2]string{"<b>", "</b>"},
+	emph:   [2]string{"<i>", "</i>"},
+	strike: [2]string{"<s>", "</s>"},
+	code:   func(s string) string { return "<code>" + html.EscapeString(s) + "</code>" },
+	pre: func(lang, s string) string {
+		if lang != "" {
+			return `<pre><code class="language-` + html.EscapeString(lang) + `">` + html.EscapeString(s) + "</code></pre>"
+		}
+		return "<pre>" + html.EscapeString(s) + "</pre>"
+	},
+	link: func(text, u string) string {
+		if !linkable(u) {
+			return text
+		}
+		return `<a href="` + html.EscapeString(u) + `">` + text + "</a>"
+	},
+	heading: func(level int, text string) string { return "<b>" + text + "</b>" },
+	quote:   func(text string) string { return "<blockquote>" + text + "</blockquote>" },
+	rule:    "———",
+	bullets: []string{"•", "◦", "▪"},
+}
+
+var discordStyle = &style{
+	escape: func(s string) string { return s },
+	strong: [2]string{"**", "**"},
+	emph:   [2]string{"*", "*"},
+	strike: [2]string{"~~", "~~"},
+	code:   backtickCode,
+	pre:    fencedCode,
+	link: func(text, u string) string {
+		if text == u || !linkable(u) {
+			return u
+		}
+		return "[" + text + "](" + u + ")"
+	},
+	heading: func(level int, text string) string {
+		if level > 3 {
+			return "**" + text + "**"
+		}
+		return strings.Repeat("#", level) + " " + text
+	},
+	quote:   prefixLines("> "),
+	rule:    "———",
+	bullets: []string{"-"},
+}
+
+var slackStyle = &style{
+	escape: slackEscape,
+	strong: [2]string{"*", "*"},
+	emph:   [2]string{"_", "_"},
+	strike: [2]string{"~", "~"},
+	code:   func(s string) string { return "`" + slackEscape(s) + "`" },
+	pre:    func(lang, s string) string { return "```\n" + slackEscape(s) + "\n```" },
+	link: func(text, u string) string {
+		if !linkable(u) {
+			return text
+		}
+		return "<" + u + "|" + text + ">"
+	},
+	heading: func(level int, text string) string { return "*" + text + "*" },
+	quote:   prefixLines("> "),
+	rule:    "———",
+	bullets: []string{"•", "◦", "▪"},
+}
+
+// slackEscape escapes the characters Slack treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+var whatsappStyle = &style{
+	escape: func(s string) string { return s },
+	strong: [2]string{"*", "*"},
+	emph:   [2]string{"_", "_"},
+	strike: [2]string{"~", "~"},
+	code:   backtickCode,
+	pre:    func(lang, s string) string { return "```" + s + "```" },
+	link:   textAndURL,
+	heading: func(level int, text string) string {
+		return "*" + text + "*"
+	},
+	quote:   prefixLines("> "),
+	rule:    "———",
+	bullets: []string{"•", "◦", "▪"},
+}
+
+var plainStyle = &style{
+	escape:  func(s string) string { return s },
+	code:    func(s string) string { return s },
+	pre:     func(lang, s string) string { return s },
+	link:    textAndURL,
+	heading: func(level int, text string) string { return text },
+	quote:   prefixLines("> "),
+	rule:    "———",
+	bullets: []string{"•", "◦", "▪"},
+}
+
+func backtickCode(s string) string {
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+func fencedCode(lang, s string) string {
+	return "```" + lang + "\n" + s + "\n```"
+}
+
+func textAndURL(text, u string) string {
+	if text == u || text == "" || strings.TrimPrefix(u, "mailto:") == text {
+		return u
+	}
+	return text + " (" + u + ")"
+}
+
+func prefixLines(prefix string) func(string) string {
+	return func(text string) string {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(prefix+line, " ")
+		}
+		return strings.Join(lines, "\n")
+	}
+}
+
+// linkable reports whether a link target is an absolute URL worth linking.
+func linkable(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return parsed.Host != ""
+	case "mailto", "tg":
+		return true
+	}
+	return false
+}
+
+type renderer struct {
+	st *style
+}
+
+func render(doc *Node, st *style) string {
+	r := &renderer{st: st}
+	return r.blocks(doc.Children, 0, "\n\n")
+}
+
+// blocks renders nodes separated by sep, or by a single line break where
+// the source had no blank line between them.
+func (r *renderer) blocks(nodes []*Node, depth int, sep string) string {
+	var sb strings.Builder
+	for i, n := range nodes {
+		if i > 0 {
+			if n.Tight {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString(sep)
+			}
+		}
+		sb.WriteString(r.block(n, depth))
+	}
+	return sb.String()
+}
+
+func (r *renderer) block(n *Node, depth int) string {
+	switch n.Kind {
+	case Heading:
+		return r.st.heading(n.Level, r.inlines(n.Children))
+	case CodeBlock:
+		return r.st.pre(n.Lang, strings.TrimRight(n.Text, "\n"))
+	case Quote:
+		return r.st.quote(r.blocks(n.Children, 0, "\n\n"))
+	case List:
+		return r.list(n, depth)
+	case Table:
+		return r.st.pre("", layoutTable(n))
+	case Rule:
+		return r.st.rule
+	default:
+		return r.inlines(n.Children)
+	}
+}
+
+// list renders items one per line, nested lists indented under their item
+// and further blocks of an item aligned with its text.
+func (r *renderer) list(n *Node, depth int) string {
+	indent := strings.Repeat("  ", depth)
+	bullet := r.st.bullets[depth%len(r.st.bullets)]
+	var lines []string
+	for i, item := range n.Children {
+		marker := bullet
+		if n.Ordered {
+			marker = fmt.Sprintf("%d.", n.Start+i)
+		}
+		prefix := indent + marker + " "
+		pad := strings.Repeat(" ", len(indent)+len([]rune(marker))+1)
+
+		first := true
+		for _, child := range item.Children {
+			if child.Kind == List {
+				if first {
+					lines = append(lines, strings.TrimRight(prefix, " "))
+					first = false
+				}
+				lines = append(lines, r.list(child, depth+1))
+				continue
+			}
+			for _, line := range strings.Split(r.block(child, depth+1), "\n") {
+				if first {
+					line, first = prefix+line, false
+				} else if line != "" {
+					line = pad + line
+				}
+				lines = append(lines, line)
+			}
+		}
+		if first {
+			lines = append(lines, strings.TrimRight(prefix, " "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *renderer) inlines(nodes []*Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case Text:
+			sb.WriteString(r.st.escape(n.Text))
+		case Code:
+			sb.WriteString(r.st.code(n.Text))
+		case Strong:
+			sb.WriteString(r.st.strong[0] + r.inlines(n.Children) + r.st.strong[1])
+		case Emph:
+			sb.WriteString(r.st.emph[0] + r.inlines(n.Children) + r.st.emph[1])
+		case Strike:
+			sb.WriteString(r.st.strike[0] + r.inlines(n.Children) + r.st.strike[1])
+		case Link:
+			sb.WriteString(r.st.link(r.inlines(n.Children), n.URL))
+		case Image:
+			text := r.inlines(n.Children)
+			if text == "" {
+				text = r.st.escape("image")
+			}
+			sb.WriteString(r.st.link("🖼 "+text, n.URL))
+		case Break:
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// plainText returns the text of inline nodes without markup.
+func plainText(nodes []*Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case Text, Code:
+			sb.WriteString(n.Text)
+		case Break:
+			sb.WriteString(" ")
+		default:
+			sb.WriteString(plainText(n.Children))
+		}
+	}
+	return sb.String()
+}
+
+// layoutTable lays a table out in columns for a monospace font.
+func layoutTable(t *Node) string {
+	cols := len(t.Align)
+	rows := make([][]string, len(t.Children))
+	widths := make([]int, cols)
+	for i, row := range t.Children {
+		rows[i] = make([]string, cols)
+		for c, cell := range row.Children {
+			text := strings.TrimSpace(plainText(cell.Children))
+			rows[i][c] = text
+			widths[c] = max(widths[c], displayWidth(text))
+		}
+	}
+
+	var lines []string
+	for i, row := range rows {
+		cells := make([]string, cols)
+		for c, text := range row {
+			cells[c] = pad(text, widths[c], t.Align[c])
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, " | "), " "))
+		if i == 0 && t.Children[0].Header {
+			seps := make([]string, cols)
+			for c, w := range widths {
+				seps[c] = strings.Repeat("-", w)
+			}
+			lines = append(lines, strings.Join(seps, "-+-"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func pad(text string, width int, align Align) string {
+	gap := width - displayWidth(text)
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", gap) + text
+	case AlignCenter:
+		return strings.Repeat(" ", gap/2) + text + strings.Repeat(" ", gap-gap/2)
+	default:
+		return text + strings.Repeat(" ", gap)
+	}
+}
+
+// displayWidth estimates how many monospace cells s takes: wide East Asian
+// characters and emoji take two, combining marks none.
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == 0x200D, r >= 0xFE00 && r <= 0xFE0F:
+		case r >= 0x1100 && r <= 0x115F, r >= 0x2E80 && r <= 0xA4CF, r >= 0xAC00 && r <= 0xD7A3,
+			r >= 0xF900 && r <= 0xFAFF, r >= 0xFE30 && r <= 0xFE4F, r >= 0xFF00 && r <= 0xFF60,
+			r >= 0xFFE0 && r <= 0xFFE6, r >= 0x1F300 && r <= 0x1FAFF, r >= 0x20000 && r <= 0x3FFFD:
+			w += 2
+		default:
+			w++
+		}
+	}
+	return w
+}
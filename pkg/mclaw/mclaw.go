@@ -0,0 +1,224 @@
+// Package mclaw embeds the assistant in other Go programs. A Bot bundles
+// the agent loop, its tools and sessions, and the chat channels enabled in
+// the config, without the CLI and gateway wiring of cmd/mclaw:
+//
+//	bot, err := mclaw.New(cfg)
+//	bot.RegisterTool(myTool)
+//	reply, err := bot.HandleMessage(ctx, "app:user-42", "What's on my calendar?")
+//
+// Call Start to also serve the configured channels and any registered with
+// RegisterChannel, and Stop to shut them down.
+package mclaw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ntminh611/mclaw/pkg/agent"
+	"github.com/ntminh611/mclaw/pkg/auth"
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/channels"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/providers"
+	"github.com/ntminh611/mclaw/pkg/session"
+	"github.com/ntminh611/mclaw/pkg/tools"
+)
+
+// Tool is a function the agent can call; see pkg/tools for the optional
+// interfaces (availability, session scope) a tool may implement.
+type Tool = tools.Tool
+
+// Channel is a chat platform connection; see channels.TestChannel for a
+// minimal implementation.
+type Channel = channels.Channel
+
+// Reply is the outcome of one turn: the text, the tool calls made and the
+// tokens used.
+type Reply = agent.TurnResult
+
+// Option customizes New.
+type Option func(*Bot)
+
+// WithProvider makes the bot use p instead of the provider configured in
+// cfg, e.g. a providers.MockProvider in tests.
+func WithProvider(p providers.LLMProvider) Option {
+	return func(b *Bot) { b.provider = p }
+}
+
+// WithBus makes the bot use an existing message bus.
+func WithBus(mb *bus.MessageBus) Option {
+	return func(b *Bot) { b.bus = mb }
+}
+
+// Bot is an embedded assistant.
+type Bot struct {
+	cfg      *config.Config
+	bus      *bus.MessageBus
+	provider providers.LLMProvider
+	agent    *agent.AgentLoop
+	channels *channels.Manager
+
+	mu      sync.Mutex
+	running bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// New builds a bot from cfg; nil uses the defaults. The provider comes
+// from cfg unless WithProvider is given.
+func New(cfg *config.Config, opts ...Option) (*Bot, error) {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	b := &Bot{cfg: cfg}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.bus == nil {
+		b.bus = bus.NewMessageBus()
+	}
+	if b.provider == nil {
+		provider, err := providers.CreateProvider(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("creating provider: %w", err)
+		}
+		b.provider = provider
+	}
+
+	b.agent = agent.NewAgentLoop(cfg, b.bus, b.provider)
+	manager, err := channels.NewManager(cfg, b.bus)
+	if err != nil {
+		return nil, fmt.Errorf("creating channels: %w", err)
+	}
+	b.channels = manager
+	for _, name := range manager.GetEnabledChannels() {
+		if ch, ok := manager.GetChannel(name); ok {
+			b.wire(ch)
+		}
+	}
+	return b, nil
+}
+
+// HandleMessage runs one turn for content in the session sessionKey and
+// returns the reply. Sessions are keyed "<source>:<id>", e.g. "app:user-42";
+// history, pins and topics are kept per key. It does not need Start.
+func (b *Bot) HandleMessage(ctx context.Context, sessionKey, content string) (*Reply, error) {
+	if sessionKey == "" {
+		return nil, errors.New("mclaw: empty session key")
+	}
+	return b.agent.ProcessDirectResult(ctx, content, sessionKey)
+}
+
+// RegisterTool adds t to the agent's tools, replacing a tool of the same
+// name. Tools may be registered at any time; the next turn offers them.
+func (b *Bot) RegisterTool(t Tool) {
+	b.agent.GetToolRegistry().Register(t)
+}
+
+// RegisterChannel adds ch under ch.Name(). If the bot is running, the
+// channel is started right away.
+func (b *Bot) RegisterChannel(ch Channel) error {
+	b.wire(ch)
+	b.channels.RegisterChannel(ch.Name(), ch)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.running {
+		return nil
+	}
+	if err := ch.Start(b.ctx); err != nil {
+		return fmt.Errorf("starting channel %s: %w", ch.Name(), err)
+	}
+	return nil
+}
+
+// Start serves the channels: messages they receive are answered by the
+// agent and replies are sent back. It returns once everything is running.
+func (b *Bot) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	if err := b.channels.StartAll(ctx); err != nil {
+		cancel()
+		return err
+	}
+	b.ctx, b.cancel, b.done = ctx, cancel, make(chan struct{})
+	b.running = true
+	go func() {
+		defer close(b.done)
+		b.agent.Run(ctx)
+	}()
+	return nil
+}
+
+// Stop shuts down the channels and waits for running turns to finish.
+func (b *Bot) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.running {
+		b.mu.Unlock()
+		return nil
+	}
+	b.running = false
+	cancel, done := b.cancel, b.done
+	b.mu.Unlock()
+
+	b.agent.Stop()
+	cancel()
+	err := b.channels.StopAll(ctx)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return err
+}
+
+// Config returns the bot's configuration.
+func (b *Bot) Config() *config.Config {
+	return b.cfg
+}
+
+// Bus returns the message bus between the channels and the agent.
+func (b *Bot) Bus() *bus.MessageBus {
+	return b.bus
+}
+
+// Agent returns the underlying agent loop, for what the Bot API does not cover.
+func (b *Bot) Agent() *agent.AgentLoop {
+	return b.agent
+}
+
+// Channels returns the channel manager.
+func (b *Bot) Channels() *channels.Manager {
+	return b.channels
+}
+
+// Sessions returns the session store.
+func (b *Bot) Sessions() *session.SessionManager {
+	return b.agent.GetSessionManager()
+}
+
+// wire hands the agent's session store and tools to channels that offer
+// commands over them, such as Telegram's /pin and /tools.
+func (b *Bot) wire(ch Channel) {
+	if c, ok := ch.(interface {
+		SetSessionManager(*session.SessionManager)
+	}); ok {
+		c.SetSessionManager(b.agent.GetSessionManager())
+	}
+	if c, ok := ch.(interface{ SetToolRegistry(*tools.ToolRegistry) }); ok {
+		c.SetToolRegistry(b.agent.GetToolRegistry())
+	}
+	if c, ok := ch.(interface{ SetAuthorizer(*auth.Authorizer) }); ok {
+		if a := b.agent.GetAuthorizer(); a != nil {
+			c.SetAuthorizer(a)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package mclaw
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/channels"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+type echoTool struct{ calls int }
+
+func (t *echoTool) Name() string        { return "echo" }
+func (t *echoTool) Description() string { return "Echo the text back" }
+func (t *echoTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": map[string]interface{}{"text": map[string]interface{}{"type": "string"}}}
+}
+func (t *echoTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	t.calls++
+	text, _ := args["text"].(string)
+	return text, nil
+}
+
+func newTestBot(t *testing.T, mock *providers.MockProvider) *Bot {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = filepath.Join(t.TempDir(), "workspace")
+	cfg.Storage.Enabled = false
+	bot, err := New(cfg, WithProvider(mock.SetDefault("ok")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bot
+}
+
+func TestHandleMessageWithCustomTool(t *testing.T) {
+	mock := providers.NewMockProvider().
+		CallTool("echo", map[string]interface{}{"text": "pong"}).
+		Reply("It said pong.")
+	bot := newTestBot(t, mock)
+	tool := &echoTool{}
+	bot.RegisterTool(tool)
+
+	reply, err := bot.HandleMessage(context.Background(), "app:user-1", "ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Content != "It said pong." || tool.calls != 1 || len(reply.ToolCalls) != 1 || reply.ToolCalls[0].Result != "pong" {
+		t.Errorf("unexpected reply %+v (tool calls: %d)", reply, tool.calls)
+	}
+	if offered := mock.Calls()[0].Tools; !contains(offered, "echo") {
+		t.Errorf("expected the custom tool offered to the model, got %v", offered)
+	}
+	if history := bot.Sessions().GetHistory("app:user-1"); len(history) != 2 {
+		t.Errorf("expected the exchange saved in the session, got %d messages", len(history))
+	}
+	if _, err := bot.HandleMessage(context.Background(), "", "hi"); err == nil {
+		t.Error("expected an empty session key to be rejected")
+	}
+}
+
+func TestRegisterChannelWhileRunning(t *testing.T) {
+	bot := newTestBot(t, providers.NewMockProvider().Reply("Hello!"))
+	if err := bot.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer bot.Stop(context.Background())
+
+	ch := channels.NewTestChannel("app", bot.Bus())
+	if err := bot.RegisterChannel(ch); err != nil {
+		t.Fatal(err)
+	}
+	if !ch.IsRunning() {
+		t.Error("expected the channel started on registration")
+	}
+	ch.Receive("u1", "c1", "hi")
+	reply, err := ch.WaitReply("c1", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Content != "Hello!" {
+		t.Errorf("unexpected reply %+v", reply)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
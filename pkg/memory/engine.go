@@ -105,6 +105,13 @@ func NewMemoryEngine(cfg *config.Config, providerGetter func() providers.LLMProv
 	return engine, nil
 }
 
+// SetLimits updates recall and retention limits, e.g. after a config reload.
+func (e *MemoryEngine) SetLimits(topK int, minScore float64, maxMemories int) {
+	e.cfg.TopK = topK
+	e.cfg.MinScore = minScore
+	e.cfg.MaxMemories = maxMemories
+}
+
 // RecallMemories searches for relevant memories based on a query.
 // This is called BEFORE the LLM response to inject context.
 func (e *MemoryEngine) RecallMemories(ctx context.Context, userID, query string, topK int) ([]SearchResult, error) {
@@ -173,6 +180,18 @@ func (e *MemoryEngine) ProcessConversation(ctx context.Context, userID string, m
 	}
 }
 
+// Remember stores a fact learned outside a conversation, such as feedback
+// on an answer, consolidating it with similar memories like extracted facts.
+func (e *MemoryEngine) Remember(userID, content, category string, importance float64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	fact := ExtractedFact{Content: content, Category: category, Importance: importance}
+	if err := e.processFact(ctx, userID, fact); err != nil {
+		logger.WarnC("memory", fmt.Sprintf("Failed to remember '%s': %v", truncate(content, 50), err))
+	}
+}
+
 // processFact handles a single extracted fact through the consolidation pipeline.
 func (e *MemoryEngine) processFact(ctx context.Context, userID string, fact ExtractedFact) error {
 	// Embed the fact
@@ -241,6 +260,11 @@ func (e *MemoryEngine) GetStats(userID string) (*MemoryStats, error) {
 	return e.store.GetStats(userID)
 }
 
+// LearnedSince returns the memories created at or after since, for all users.
+func (e *MemoryEngine) LearnedSince(since time.Time) ([]MemoryItem, error) {
+	return e.store.CreatedSince(since)
+}
+
 // Close shuts down the memory engine.
 func (e *MemoryEngine) Close() error {
 	if e.store != nil {
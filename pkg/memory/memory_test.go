@@ -1,10 +1,16 @@
 package memory
 
 import (
+	"context"
+	"errors"
 	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/providers"
 )
 
 func TestCosineSimilarity(t *testing.T) {
@@ -278,6 +284,27 @@ func TestMemoryStore_GetStats(t *testing.T) {
 	}
 }
 
+func TestMemoryStore_CreatedSince(t *testing.T) {
+	store, err := NewMemoryStore(filepath.Join(t.TempDir(), "test_memory.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	store.Add(&MemoryItem{UserID: "user1", Content: "old fact", Category: CategoryFact, Embedding: []float32{0.1}, CreatedAt: now.Add(-48 * time.Hour)})
+	store.Add(&MemoryItem{UserID: "user1", Content: "new fact", Category: CategoryFact, Embedding: []float32{0.2}, CreatedAt: now.Add(-time.Hour)})
+	store.Add(&MemoryItem{UserID: "user2", Content: "new preference", Category: CategoryPreference, Embedding: []float32{0.3}, CreatedAt: now})
+
+	items, err := store.CreatedSince(now.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("CreatedSince failed: %v", err)
+	}
+	if len(items) != 2 || items[0].Content != "new fact" || items[1].Content != "new preference" {
+		t.Errorf("Expected the two recent memories oldest first, got %+v", items)
+	}
+}
+
 func TestEmbeddingEncoding(t *testing.T) {
 	original := []float32{0.1, 0.2, 0.3, -0.5, 1.0, 0.0}
 
@@ -343,3 +370,30 @@ func TestMemoryStore_Persistence(t *testing.T) {
 		t.Error("Database file should exist")
 	}
 }
+
+func TestExtractorWithMockProvider(t *testing.T) {
+	mock := providers.NewMockProvider().
+		Reply("```json\n[{\"content\":\"User lives in Hanoi\",\"category\":\"\",\"importance\":1.5},{\"content\":\"\"}]\n```").
+		Reply("not json")
+	e := NewExtractor(func() providers.LLMProvider { return mock }, func() string { return "mock-model" })
+	conv := []providers.Message{{Role: "user", Content: "I live in Hanoi"}, {Role: "tool", Content: "ignored"}}
+
+	facts, err := e.Extract(context.Background(), conv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 1 || facts[0].Content != "User lives in Hanoi" || facts[0].Category != CategoryFact || facts[0].Importance != 1 {
+		t.Errorf("unexpected facts %+v", facts)
+	}
+	if prompt := mock.Calls()[0].LastUserMessage(); !strings.Contains(prompt, "user: I live in Hanoi") || strings.Contains(prompt, "ignored") {
+		t.Errorf("unexpected prompt:\n%s", prompt)
+	}
+
+	// An unparseable reply skips extraction rather than failing
+	if facts, err := e.Extract(context.Background(), conv); err != nil || facts != nil {
+		t.Errorf("expected no facts and no error, got %+v, %v", facts, err)
+	}
+	if _, err := e.Extract(context.Background(), conv); !errors.Is(err, providers.ErrScriptExhausted) {
+		t.Errorf("expected the provider error to surface, got %v", err)
+	}
+}
@@ -226,6 +226,36 @@ func (s *MemoryStore) GetByUser(userID string) ([]MemoryItem, error) {
 	return items, nil
 }
 
+// CreatedSince returns active memories of every user created at or after
+// since, oldest first.
+func (s *MemoryStore) CreatedSince(since time.Time) ([]MemoryItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(
+		`SELECT id, user_id, content, category, score, created_at, updated_at, access_cnt
+		 FROM memories WHERE created_at >= ? AND deleted = 0
+		 ORDER BY created_at`,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memories: %w", err)
+	}
+	defer rows.Close()
+
+	var items []MemoryItem
+	for rows.Next() {
+		var item MemoryItem
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Content, &item.Category,
+			&item.Score, &item.CreatedAt, &item.UpdatedAt, &item.AccessCnt); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
 // GetStats returns memory statistics for a user.
 func (s *MemoryStore) GetStats(userID string) (*MemoryStats, error) {
 	s.mu.RLock()
@@ -297,6 +327,48 @@ func (s *MemoryStore) Prune(userID string, maxItems int) (int, error) {
 	return int(deleted), nil
 }
 
+// MatchUserIDs returns stored user IDs belonging to a sender, matching both
+// the bare ID ("123") and channel forms with a username suffix ("123|alice").
+func (s *MemoryStore) MatchUserIDs(id string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(
+		`SELECT DISTINCT user_id FROM memories WHERE user_id = ? OR substr(user_id, 1, ?) = ?`,
+		id, len(id)+1, id+"|",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, userID)
+	}
+	return ids, rows.Err()
+}
+
+// PurgeUser permanently deletes all memories of a user, including
+// soft-deleted ones.
+func (s *MemoryStore) PurgeUser(userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`DELETE FROM memories WHERE user_id = ?`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge memories: %w", err)
+	}
+
+	deleted, _ := result.RowsAffected()
+	log.Printf("[memory] Purged %d memories for user %s", deleted, userID)
+	return int(deleted), nil
+}
+
 // Close closes the database connection.
 func (s *MemoryStore) Close() error {
 	return s.db.Close()
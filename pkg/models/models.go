@@ -0,0 +1,180 @@
+// Package models knows what the common LLMs can do: how large their context
+// window is, whether they call tools and accept images, and what they cost.
+// The built-in table covers model families by name prefix and model_info in
+// the config overrides or extends it.
+package models
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// Capabilities describes one model. ContextWindow is 0 when unknown and
+// Price is nil when the model isn't priced.
+type Capabilities struct {
+	ContextWindow int
+	Tools         bool
+	Vision        bool
+	Price         *config.ModelPrice
+}
+
+type builtin struct {
+	window int
+	tools  bool
+	vision bool
+	input  float64 // USD per 1M prompt tokens, 0 = unpriced
+	output float64 // USD per 1M completion tokens
+}
+
+// builtins is keyed by model family; a model matches the longest family its
+// name starts with, after dropping the provider prefix.
+var builtins = map[string]builtin{
+	"gpt-4o":            {128000, true, true, 2.5, 10},
+	"gpt-4o-mini":       {128000, true, true, 0.15, 0.6},
+	"gpt-4.1":           {1047576, true, true, 2, 8},
+	"gpt-4.1-mini":      {1047576, true, true, 0.4, 1.6},
+	"gpt-4.1-nano":      {1047576, true, true, 0.1, 0.4},
+	"gpt-5":             {400000, true, true, 1.25, 10},
+	"gpt-5-mini":        {400000, true, true, 0.25, 2},
+	"o3":                {200000, true, true, 2, 8},
+	"o4-mini":           {200000, true, true, 1.1, 4.4},
+	"claude-opus-4":     {200000, true, true, 15, 75},
+	"claude-sonnet-4":   {200000, true, true, 3, 15},
+	"claude-haiku-4-5":  {200000, true, true, 1, 5},
+	"gemini-2.5-pro":    {1048576, true, true, 1.25, 10},
+	"gemini-2.5-flash":  {1048576, true, true, 0.3, 2.5},
+	"gemini-2.0-flash":  {1048576, true, true, 0.1, 0.4},
+	"llama-3.3-70b":     {128000, true, false, 0, 0},
+	"deepseek-chat":     {64000, true, false, 0.27, 1.1},
+	"deepseek-reasoner": {64000, false, false, 0.55, 2.19},
+	"glm-4.7":           {200000, true, false, 0, 0},
+}
+
+// Registry answers capability lookups from the built-in table and the
+// config's model_info and usage.pricing. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	overrides config.ModelCatalog
+	pricing   map[string]config.ModelPrice // usage.pricing, wins over everything
+}
+
+// New creates a registry for cfg.
+func New(cfg *config.Config) *Registry {
+	r := &Registry{}
+	r.Apply(cfg)
+	return r
+}
+
+// Apply picks up model_info and usage.pricing after a config reload.
+func (r *Registry) Apply(cfg *config.Config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides = cfg.ModelInfo
+	r.pricing = cfg.Usage.Pricing
+}
+
+// Lookup returns what is known about model. Unknown models are assumed to
+// call tools but not see images.
+func (r *Registry) Lookup(model string) Capabilities {
+	name := stripProvider(model)
+	caps := Capabilities{Tools: true}
+	if b, ok := builtins[longestPrefix(name, builtinFamilies())]; ok {
+		caps = Capabilities{ContextWindow: b.window, Tools: b.tools, Vision: b.vision}
+		if b.input > 0 || b.output > 0 {
+			caps.Price = &config.ModelPrice{Input: b.input, Output: b.output}
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if info, ok := r.override(model, name); ok {
+		if info.ContextWindow > 0 {
+			caps.ContextWindow = info.ContextWindow
+		}
+		if info.Tools != nil {
+			caps.Tools = *info.Tools
+		}
+		if info.Vision != nil {
+			caps.Vision = *info.Vision
+		}
+		if info.Price != nil {
+			price := *info.Price
+			caps.Price = &price
+		}
+	}
+	if price, ok := r.pricing[model]; ok {
+		caps.Price = &price
+	} else if price, ok := r.pricing[name]; ok {
+		caps.Price = &price
+	}
+	return caps
+}
+
+// Pricing returns every known price by model or family name, for the usage
+// footer and the daily budget: built-in prices, then model_info, then
+// usage.pricing.
+func (r *Registry) Pricing() map[string]config.ModelPrice {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pricing := make(map[string]config.ModelPrice, len(builtins)+len(r.overrides)+len(r.pricing))
+	for family, b := range builtins {
+		if b.input > 0 || b.output > 0 {
+			pricing[family] = config.ModelPrice{Input: b.input, Output: b.output}
+		}
+	}
+	for name, info := range r.overrides {
+		if info.Price != nil {
+			pricing[name] = *info.Price
+		}
+	}
+	for name, price := range r.pricing {
+		pricing[name] = price
+	}
+	return pricing
+}
+
+// override finds the model_info entry for a model: the longest key the full
+// name starts with, else the longest key the name without the provider
+// prefix starts with. Callers hold r.mu.
+func (r *Registry) override(model, name string) (config.ModelInfo, bool) {
+	families := make([]string, 0, len(r.overrides))
+	for family := range r.overrides {
+		families = append(families, family)
+	}
+	key := longestPrefix(model, families)
+	if key == "" {
+		key = longestPrefix(name, families)
+	}
+	info, ok := r.overrides[key]
+	return info, ok
+}
+
+// stripProvider drops the provider prefix: "openrouter/anthropic/claude-sonnet-4"
+// becomes "claude-sonnet-4".
+func stripProvider(model string) string {
+	if idx := strings.LastIndex(model, "/"); idx >= 0 {
+		return model[idx+1:]
+	}
+	return model
+}
+
+// longestPrefix returns the longest family name starts with, or "".
+func longestPrefix(name string, families []string) string {
+	best := ""
+	for _, family := range families {
+		if len(family) > len(best) && strings.HasPrefix(name, family) {
+			best = family
+		}
+	}
+	return best
+}
+
+func builtinFamilies() []string {
+	families := make([]string, 0, len(builtins))
+	for family := range builtins {
+		families = append(families, family)
+	}
+	return families
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+func TestLookupBuiltin(t *testing.T) {
+	r := New(config.DefaultConfig())
+
+	caps := r.Lookup("openai/gpt-4o-mini-2024-07-18")
+	if caps.ContextWindow != 128000 || !caps.Tools || !caps.Vision {
+		t.Errorf("gpt-4o-mini = %+v", caps)
+	}
+	if caps.Price == nil || caps.Price.Input != 0.15 {
+		t.Errorf("gpt-4o-mini price = %+v, want the mini price, not gpt-4o's", caps.Price)
+	}
+
+	if caps := r.Lookup("deepseek/deepseek-reasoner"); caps.Tools {
+		t.Error("deepseek-reasoner should not call tools")
+	}
+	if caps := r.Lookup("groq/llama-3.3-70b-versatile"); caps.Vision || !caps.Tools {
+		t.Errorf("llama-3.3-70b = %+v", caps)
+	}
+
+	caps = r.Lookup("ollama/some-local-model")
+	if caps.ContextWindow != 0 || !caps.Tools || caps.Vision || caps.Price != nil {
+		t.Errorf("unknown model = %+v, want tools only", caps)
+	}
+}
+
+func TestLookupOverrides(t *testing.T) {
+	no, yes := false, true
+	cfg := config.DefaultConfig()
+	cfg.ModelInfo = config.ModelCatalog{
+		"gpt-4o":       {ContextWindow: 32000},
+		"ollama/qwen3": {ContextWindow: 40000, Vision: &yes, Tools: &no},
+	}
+	cfg.Usage.Pricing = map[string]config.ModelPrice{"gpt-4o": {Input: 1, Output: 2}}
+	r := New(cfg)
+
+	caps := r.Lookup("openai/gpt-4o")
+	if caps.ContextWindow != 32000 || !caps.Vision {
+		t.Errorf("gpt-4o = %+v, want the window overridden and vision kept", caps)
+	}
+	if caps.Price == nil || caps.Price.Input != 1 {
+		t.Errorf("gpt-4o price = %+v, want usage.pricing", caps.Price)
+	}
+
+	caps = r.Lookup("ollama/qwen3:14b")
+	if caps.ContextWindow != 40000 || caps.Tools || !caps.Vision {
+		t.Errorf("qwen3 = %+v", caps)
+	}
+
+	cfg.ModelInfo = nil
+	r.Apply(cfg)
+	if caps := r.Lookup("openai/gpt-4o"); caps.ContextWindow != 128000 {
+		t.Errorf("after reload window = %d, want the built-in 128000", caps.ContextWindow)
+	}
+}
+
+func TestPricing(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ModelInfo = config.ModelCatalog{"local": {Price: &config.ModelPrice{Input: 0.5}}}
+	cfg.Usage.Pricing = map[string]config.ModelPrice{"gpt-4o": {Input: 1, Output: 2}}
+	pricing := New(cfg).Pricing()
+
+	if pricing["gpt-4o"].Input != 1 {
+		t.Errorf("gpt-4o = %+v, want usage.pricing to win", pricing["gpt-4o"])
+	}
+	if pricing["local"].Input != 0.5 {
+		t.Errorf("local = %+v, want the model_info price", pricing["local"])
+	}
+	if pricing["claude-sonnet-4"].Output != 15 {
+		t.Errorf("claude-sonnet-4 = %+v, want the built-in price", pricing["claude-sonnet-4"])
+	}
+	if _, ok := pricing["llama-3.3-70b"]; ok {
+		t.Error("unpriced built-ins should be left out")
+	}
+}
@@ -0,0 +1,133 @@
+package monitors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+func TestEvaluateTransitions(t *testing.T) {
+	m := &Monitor{Name: "blog", URL: "https://blog.example", State: StateUnknown}
+	now := time.Now()
+	up := Result{OK: true, Status: 200}
+	down := Result{Error: "HTTP 502"}
+
+	if alert := Evaluate(m, up, now, 2, 0); alert != "" || m.State != StateUp {
+		t.Fatalf("expected a silent first check, got %q in state %s", alert, m.State)
+	}
+	if alert := Evaluate(m, down, now.Add(time.Minute), 2, 0); alert != "" || m.State != StateUp {
+		t.Fatalf("expected one failure to be tolerated, got %q in state %s", alert, m.State)
+	}
+	alert := Evaluate(m, down, now.Add(2*time.Minute), 2, 0)
+	if !strings.Contains(alert, "DOWN") || !strings.Contains(alert, "HTTP 502") || m.State != StateDown {
+		t.Fatalf("expected a down alert, got %q in state %s", alert, m.State)
+	}
+	if alert := Evaluate(m, down, now.Add(3*time.Hour), 2, 0); alert != "" {
+		t.Errorf("expected no repeat alert without repeat, got %q", alert)
+	}
+	if alert := Evaluate(m, down, now.Add(3*time.Hour), 2, time.Hour); !strings.Contains(alert, "still down") {
+		t.Errorf("expected a repeat alert after the repeat interval, got %q", alert)
+	}
+	if alert := Evaluate(m, down, now.Add(3*time.Hour+time.Minute), 2, time.Hour); alert != "" {
+		t.Errorf("expected repeats to be spaced out, got %q", alert)
+	}
+	alert = Evaluate(m, up, now.Add(4*time.Hour), 2, time.Hour)
+	if !strings.Contains(alert, "back up") || !strings.Contains(alert, "after 3h58m") || m.State != StateUp || m.Failures != 0 {
+		t.Errorf("expected a recovery alert with the downtime, got %q in state %s", alert, m.State)
+	}
+}
+
+func TestMonitorLifecycle(t *testing.T) {
+	var mu sync.Mutex
+	status, body := http.StatusOK, "all systems operational"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	setBody := func(s int, b string) {
+		mu.Lock()
+		defer mu.Unlock()
+		status, body = s, b
+	}
+
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "memory.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	var alerts []string
+	svc := NewService(store, server.Client(), func(channel, chatID, content string) {
+		alerts = append(alerts, channel+":"+chatID+" "+content)
+	})
+	svc.SetDefaults(10*time.Minute, 1, 0)
+	cs := cron.NewCronService(filepath.Join(dir, "jobs.json"), nil)
+	if err := svc.Attach(cs); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.Add("telegram:42", Monitor{URL: "ftp://example.com"}); err == nil {
+		t.Error("expected a non-HTTP URL to be refused")
+	}
+	m, err := svc.Add("telegram:42", Monitor{URL: server.URL, Keyword: "Operational"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Interval != 10*time.Minute || m.Name == "" || m.JobID == "" {
+		t.Fatalf("expected defaults and a job, got %+v", m)
+	}
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 || jobs[0].Payload.Kind != JobKind || jobs[0].Schedule.Kind != "every" {
+		t.Fatalf("expected a recurring monitor job, got %+v", jobs)
+	}
+
+	fire := func() {
+		t.Helper()
+		job := cs.ListJobs(true)[0]
+		if _, err := svc.fire(&job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fire()
+	setBody(http.StatusOK, "maintenance")
+	fire()
+	setBody(http.StatusOK, "all systems operational")
+	fire()
+	if len(alerts) != 2 || !strings.Contains(alerts[0], "not found in the page") || !strings.Contains(alerts[1], "back up") {
+		t.Fatalf("expected a down and a recovery alert, got %q", alerts)
+	}
+	if !strings.HasPrefix(alerts[0], "telegram:42 ") {
+		t.Errorf("expected alerts to go to the owner's chat, got %q", alerts[0])
+	}
+
+	if _, err := svc.SetPaused("telegram:7", m.ID, true); err == nil {
+		t.Error("expected other chats' monitors to be out of reach")
+	}
+	if m, err = svc.SetPaused("telegram:42", m.ID, true); err != nil || m.JobID != "" || len(cs.ListJobs(true)) != 0 {
+		t.Fatalf("expected pausing to drop the job: %v %+v", err, m)
+	}
+	if m, err = svc.SetPaused("telegram:42", m.ID, false); err != nil || m.State != StateUnknown || len(cs.ListJobs(true)) != 1 {
+		t.Fatalf("expected resuming to reschedule: %v %+v", err, m)
+	}
+
+	setBody(http.StatusServiceUnavailable, "")
+	if _, res, err := svc.CheckNow(context.Background(), "telegram:42", m.ID); err != nil || res.OK || res.Status != 503 {
+		t.Errorf("expected a failed manual check, got %+v %v", res, err)
+	}
+	if _, err := svc.Remove("telegram:42", m.ID); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := svc.List("telegram:42"); len(list) != 0 || len(cs.ListJobs(true)) != 0 {
+		t.Errorf("expected the monitor and its job to be gone, got %d and %d", len(list), len(cs.ListJobs(true)))
+	}
+}
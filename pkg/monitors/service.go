@@ -0,0 +1,416 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+// JobKind is the cron payload kind of monitor checks.
+const JobKind = "monitor"
+
+// MinInterval is the shortest check interval a monitor may use.
+const MinInterval = time.Minute
+
+const (
+	maxBodyBytes = 2 << 20
+	userAgent    = "mclaw-monitor/1.0 (+https://github.com/ntminh611/mclaw)"
+)
+
+// NotifyFunc delivers an alert to a chat.
+type NotifyFunc func(channel, chatID, content string)
+
+// Result is the outcome of one check.
+type Result struct {
+	OK      bool
+	Status  int // HTTP status, 0 if no response
+	Latency time.Duration
+	Error   string // why the check failed
+}
+
+// Service adds, checks and alerts on monitors. Until a cron service is
+// attached, monitors are stored but not checked.
+type Service struct {
+	store    *Store
+	client   *http.Client
+	notify   NotifyFunc
+	mu       sync.Mutex // serializes changes to a monitor and its job
+	cron     *cron.CronService
+	interval time.Duration
+	failures int
+	repeat   time.Duration
+}
+
+// NewService creates a monitor service. client should be guarded against
+// private addresses, since monitor URLs come from chat users.
+func NewService(store *Store, client *http.Client, notify NotifyFunc) *Service {
+	return &Service{store: store, client: client, notify: notify, interval: 5 * time.Minute, failures: 2}
+}
+
+// SetDefaults sets the interval for monitors that don't choose one, how
+// many consecutive failed checks make a monitor down, and how often to
+// repeat the alert while it stays down (0: only alert on changes).
+func (s *Service) SetDefaults(interval time.Duration, failures int, repeat time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if interval > 0 {
+		s.interval = max(interval, MinInterval)
+	}
+	if failures > 0 {
+		s.failures = failures
+	}
+	s.repeat = repeat
+}
+
+// Attach registers the check handler with cs and makes sure every active
+// monitor has a job, dropping jobs of monitors that no longer exist.
+func (s *Service) Attach(cs *cron.CronService) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cron = cs
+	cs.SetKindHandler(JobKind, s.fire)
+
+	jobs := make(map[string]bool)
+	for _, job := range cs.ListJobs(true) {
+		if job.Payload.Kind == JobKind {
+			jobs[job.ID] = true
+		}
+	}
+
+	list, err := s.store.List("")
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]bool)
+	for _, m := range list {
+		if m.Paused {
+			continue
+		}
+		if !jobs[m.JobID] {
+			s.schedule(m)
+			if err := s.store.Save(m); err != nil {
+				log.Printf("[monitors] Failed to save monitor #%d: %v", m.ID, err)
+			}
+		}
+		keep[m.JobID] = true
+	}
+	for id := range jobs {
+		if !keep[id] {
+			cs.RemoveJob(id)
+		}
+	}
+	return nil
+}
+
+// Add validates and stores a monitor for owner's chat and schedules it.
+// Name defaults to the URL's host and Interval to the configured default.
+func (s *Service) Add(owner string, m Monitor) (*Monitor, error) {
+	if _, _, ok := strings.Cut(owner, ":"); !ok {
+		return nil, fmt.Errorf("monitors need a chat to alert")
+	}
+	m.URL = strings.TrimSpace(m.URL)
+	if !strings.Contains(m.URL, "://") {
+		m.URL = "https://" + m.URL
+	}
+	u, err := url.Parse(m.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: use http(s)://host/path", m.URL)
+	}
+	if m.ExpectStatus != 0 && (m.ExpectStatus < 100 || m.ExpectStatus > 599) {
+		return nil, fmt.Errorf("invalid expected status %d", m.ExpectStatus)
+	}
+	if m.Name = strings.TrimSpace(m.Name); m.Name == "" {
+		m.Name = u.Host
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m.Interval <= 0 {
+		m.Interval = s.interval
+	}
+	m.Interval = max(m.Interval, MinInterval)
+	m.Owner = owner
+	m.State = StateUnknown
+	if err := s.store.Add(&m); err != nil {
+		return nil, err
+	}
+	s.schedule(&m)
+	return &m, s.store.Save(&m)
+}
+
+// List returns owner's monitors.
+func (s *Service) List(owner string) ([]*Monitor, error) {
+	return s.store.List(owner)
+}
+
+// Remove deletes one of owner's monitors and its job.
+func (s *Service) Remove(owner string, id int64) (*Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, err := s.store.Get(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	s.unschedule(m)
+	return m, s.store.Delete(owner, id)
+}
+
+// SetPaused pauses or resumes one of owner's monitors. A resumed monitor
+// starts over in the unknown state, so it doesn't alert on stale history.
+func (s *Service) SetPaused(owner string, id int64, paused bool) (*Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, err := s.store.Get(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.Paused == paused {
+		return m, nil
+	}
+	m.Paused = paused
+	if paused {
+		s.unschedule(m)
+	} else {
+		m.State, m.Failures, m.LastChange = StateUnknown, 0, time.Time{}
+		s.schedule(m)
+	}
+	return m, s.store.Save(m)
+}
+
+// CheckNow checks one of owner's monitors right away. The result counts
+// like a scheduled check, alerts included.
+func (s *Service) CheckNow(ctx context.Context, owner string, id int64) (*Monitor, Result, error) {
+	m, err := s.store.Get(owner, id)
+	if err != nil {
+		return nil, Result{}, err
+	}
+	res := Probe(ctx, s.client, m)
+	m, err = s.record(m.ID, res, time.Now())
+	return m, res, err
+}
+
+// fire is the cron handler for monitor jobs.
+func (s *Service) fire(job *cron.CronJob) (string, error) {
+	id, err := strconv.ParseInt(job.Payload.Message, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("bad monitor job: %q", job.Payload.Message)
+	}
+	m, err := s.store.Get("", id)
+	if err != nil {
+		return "", err
+	}
+	if m.Paused || m.JobID != job.ID {
+		return fmt.Sprintf("monitor #%d skipped", id), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), min(m.Interval, time.Minute))
+	defer cancel()
+	res := Probe(ctx, s.client, m)
+	if m, err = s.record(id, res, time.Now()); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("monitor #%d %s", id, m.State), nil
+}
+
+// record applies a check result to the stored monitor and sends the alert
+// it calls for, if any.
+func (s *Service) record(id int64, res Result, now time.Time) (*Monitor, error) {
+	s.mu.Lock()
+	m, err := s.store.Get("", id) // reload: it may have changed during the check
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	alert := Evaluate(m, res, now, s.failures, s.repeat)
+	err = s.store.Save(m)
+	s.mu.Unlock()
+
+	if alert != "" && s.notify != nil {
+		channel, chatID, _ := strings.Cut(m.Owner, ":")
+		s.notify(channel, chatID, alert)
+		log.Printf("[monitors] Monitor #%d %s: alerted %s", m.ID, m.State, m.Owner)
+	}
+	return m, err
+}
+
+// Evaluate updates m's state with a check result and returns the alert to
+// send, or "". A monitor goes down after failures consecutive failed
+// checks and is alerted on once, then again every repeat while it stays
+// down (never if repeat is 0), and once more when it recovers.
+func Evaluate(m *Monitor, res Result, now time.Time, failures int, repeat time.Duration) string {
+	m.LastChecked = now
+	m.LastLatency = res.Latency
+	if res.OK {
+		m.Failures = 0
+		m.LastError = ""
+		previous := m.State
+		if previous == StateUp {
+			return ""
+		}
+		downSince := m.LastChange
+		m.State, m.LastChange = StateUp, now
+		if previous != StateDown {
+			return "" // first successful check
+		}
+		m.LastAlert = now
+		return fmt.Sprintf("✅ **%s is back up** after %s (%s)\n%s", m.Name, formatDuration(now.Sub(downSince)), res.describe(), m.URL)
+	}
+
+	m.Failures++
+	m.LastError = res.Error
+	switch {
+	case m.State != StateDown && m.Failures >= max(failures, 1):
+		m.State, m.LastChange, m.LastAlert = StateDown, now, now
+		return fmt.Sprintf("🔴 **%s is DOWN**: %s\n%s", m.Name, res.Error, m.URL)
+	case m.State == StateDown && repeat > 0 && now.Sub(m.LastAlert) >= repeat:
+		m.LastAlert = now
+		return fmt.Sprintf("🔴 **%s is still down** (for %s): %s\n%s", m.Name, formatDuration(now.Sub(m.LastChange)), res.Error, m.URL)
+	}
+	return ""
+}
+
+// Probe fetches m's URL and checks the status code and keyword.
+func Probe(ctx context.Context, client *http.Client, m *Monitor) Result {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.URL, nil)
+	if err != nil {
+		return Result{Error: err.Error()}
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{Latency: time.Since(start), Error: shortError(err)}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	res := Result{Status: resp.StatusCode, Latency: time.Since(start)}
+
+	switch {
+	case m.ExpectStatus != 0 && resp.StatusCode != m.ExpectStatus:
+		res.Error = fmt.Sprintf("HTTP %d, expected %d", resp.StatusCode, m.ExpectStatus)
+	case m.ExpectStatus == 0 && resp.StatusCode >= 400:
+		res.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	case err != nil:
+		res.Error = "failed to read response: " + shortError(err)
+	case m.Keyword != "" && !strings.Contains(strings.ToLower(string(body)), strings.ToLower(m.Keyword)):
+		res.Error = fmt.Sprintf("HTTP %d, but %q not found in the page", resp.StatusCode, m.Keyword)
+	default:
+		res.OK = true
+	}
+	return res
+}
+
+func (r Result) describe() string {
+	if r.Status == 0 {
+		return r.Error
+	}
+	return fmt.Sprintf("HTTP %d in %s", r.Status, r.Latency.Round(time.Millisecond))
+}
+
+// shortError drops the "Get "url": " prefix net/http puts on errors.
+func shortError(err error) string {
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+	return err.Error()
+}
+
+// schedule (re)creates the recurring check job of an active monitor.
+// Caller must hold s.mu.
+func (s *Service) schedule(m *Monitor) {
+	if s.cron == nil {
+		return // scheduled by Attach
+	}
+	s.unschedule(m)
+	everyMS := m.Interval.Milliseconds()
+	channel, chatID, _ := strings.Cut(m.Owner, ":")
+	job, err := s.cron.AddJobPayload(fmt.Sprintf("monitor #%d %s", m.ID, m.Name), cron.CronSchedule{Kind: "every", EveryMS: &everyMS}, cron.CronPayload{
+		Kind:    JobKind,
+		Message: strconv.FormatInt(m.ID, 10),
+		Channel: channel,
+		To:      chatID,
+	})
+	if err != nil {
+		log.Printf("[monitors] Failed to schedule monitor #%d: %v", m.ID, err)
+		return
+	}
+	m.JobID = job.ID
+}
+
+func (s *Service) unschedule(m *Monitor) {
+	if s.cron != nil && m.JobID != "" {
+		s.cron.RemoveJob(m.JobID)
+	}
+	m.JobID = ""
+}
+
+// Describe renders a monitor for listings, e.g.
+// "🟢 #3 blog — up for 2d · 212ms · every 5m".
+func (m *Monitor) Describe(now time.Time) string {
+	var sb strings.Builder
+	switch {
+	case m.Paused:
+		sb.WriteString("⏸")
+	case m.State == StateUp:
+		sb.WriteString("🟢")
+	case m.State == StateDown:
+		sb.WriteString("🔴")
+	default:
+		sb.WriteString("⚪")
+	}
+	fmt.Fprintf(&sb, " #%d %s — ", m.ID, m.Name)
+	switch {
+	case m.Paused:
+		sb.WriteString("paused")
+	case m.State == StateUnknown:
+		sb.WriteString("not checked yet")
+	default:
+		fmt.Fprintf(&sb, "%s for %s", m.State, formatDuration(now.Sub(m.LastChange)))
+		if m.State == StateUp && m.LastLatency > 0 {
+			fmt.Fprintf(&sb, " · %s", m.LastLatency.Round(time.Millisecond))
+		}
+	}
+	if !m.Paused && m.Failures > 0 && m.LastError != "" {
+		fmt.Fprintf(&sb, " · last check failed: %s", m.LastError)
+	}
+	fmt.Fprintf(&sb, " · every %s\n   %s", formatDuration(m.Interval), m.URL)
+	if m.ExpectStatus != 0 {
+		fmt.Fprintf(&sb, " · expects %d", m.ExpectStatus)
+	}
+	if m.Keyword != "" {
+		fmt.Fprintf(&sb, " · must contain %q", m.Keyword)
+	}
+	return sb.String()
+}
+
+// formatDuration renders a duration coarsely, e.g. "45s", "12m", "3h20m" or "2d".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		h, m := int(d.Hours()), int(d.Minutes())%60
+		if m == 0 {
+			return fmt.Sprintf("%dh", h)
+		}
+		return fmt.Sprintf("%dh%dm", h, m)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
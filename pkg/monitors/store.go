@@ -0,0 +1,234 @@
+// Package monitors keeps uptime monitors: URLs checked on an interval for
+// an expected status code and keyword, with up/down state and alerts sent
+// only when that state changes. Each active monitor is backed by a
+// recurring cron job; the monitor itself, with its state, lives in SQLite.
+package monitors
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Monitor states.
+const (
+	StateUnknown = "unknown" // not checked yet
+	StateUp      = "up"
+	StateDown    = "down"
+)
+
+// Monitor is one uptime monitor. Owner is the session key of the
+// conversation it was added in ("channel:chat_id"), which is also where
+// its alerts go.
+type Monitor struct {
+	ID           int64
+	Owner        string
+	Name         string
+	URL          string
+	ExpectStatus int    // 0 accepts any status below 400
+	Keyword      string // must appear in the response body, if set
+	Interval     time.Duration
+	Paused       bool
+	JobID        string // cron job that checks it while active
+	State        string
+	Failures     int // consecutive failed checks
+	LastChecked  time.Time
+	LastChange   time.Time // when State last changed
+	LastAlert    time.Time
+	LastError    string
+	LastLatency  time.Duration
+	CreatedAt    time.Time
+}
+
+// Store persists monitors in SQLite.
+type Store struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// NewStore creates or opens the monitors table in the database at dbPath.
+func NewStore(dbPath string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create monitors directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open monitors database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate monitors database: %w", err)
+	}
+
+	log.Printf("[monitors] Store initialized at %s", dbPath)
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS monitors (
+		id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner         TEXT NOT NULL,
+		name          TEXT NOT NULL,
+		url           TEXT NOT NULL,
+		expect_status INTEGER NOT NULL DEFAULT 0,
+		keyword       TEXT NOT NULL DEFAULT '',
+		interval_s    INTEGER NOT NULL,
+		paused        INTEGER NOT NULL DEFAULT 0,
+		job_id        TEXT NOT NULL DEFAULT '',
+		state         TEXT NOT NULL DEFAULT 'unknown',
+		failures      INTEGER NOT NULL DEFAULT 0,
+		last_checked  INTEGER NOT NULL DEFAULT 0,
+		last_change   INTEGER NOT NULL DEFAULT 0,
+		last_alert    INTEGER NOT NULL DEFAULT 0,
+		last_error    TEXT NOT NULL DEFAULT '',
+		latency_ms    INTEGER NOT NULL DEFAULT 0,
+		created_at    INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_monitors_owner ON monitors(owner);
+	`)
+	return err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add inserts m and sets its ID.
+func (s *Store) Add(m *Monitor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m.State == "" {
+		m.State = StateUnknown
+	}
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+	res, err := s.db.Exec(`INSERT INTO monitors (owner, name, url, expect_status, keyword, interval_s, state, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.Owner, m.Name, m.URL, m.ExpectStatus, m.Keyword, int64(m.Interval.Seconds()), m.State, m.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to add monitor: %w", err)
+	}
+	m.ID, err = res.LastInsertId()
+	return err
+}
+
+// Save writes back a monitor's settings and state.
+func (s *Store) Save(m *Monitor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`UPDATE monitors SET name = ?, url = ?, expect_status = ?, keyword = ?, interval_s = ?,
+		paused = ?, job_id = ?, state = ?, failures = ?, last_checked = ?, last_change = ?, last_alert = ?,
+		last_error = ?, latency_ms = ? WHERE id = ?`,
+		m.Name, m.URL, m.ExpectStatus, m.Keyword, int64(m.Interval.Seconds()),
+		m.Paused, m.JobID, m.State, m.Failures, unixOrZero(m.LastChecked), unixOrZero(m.LastChange), unixOrZero(m.LastAlert),
+		m.LastError, m.LastLatency.Milliseconds(), m.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save monitor #%d: %w", m.ID, err)
+	}
+	return nil
+}
+
+// Get returns one of owner's monitors, or any monitor if owner is "".
+func (s *Store) Get(owner string, id int64) (*Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var list []*Monitor
+	var err error
+	if owner == "" {
+		list, err = s.query(`WHERE id = ?`, id)
+	} else {
+		list, err = s.query(`WHERE id = ? AND owner = ?`, id, owner)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("monitor #%d not found", id)
+	}
+	return list[0], nil
+}
+
+// List returns the monitors of a chat, or of all chats if owner is "".
+func (s *Store) List(owner string) ([]*Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if owner == "" {
+		return s.query(`ORDER BY id`)
+	}
+	return s.query(`WHERE owner = ? ORDER BY id`, owner)
+}
+
+// Delete removes one of owner's monitors.
+func (s *Store) Delete(owner string, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM monitors WHERE id = ? AND owner = ?`, id, owner)
+	if err != nil {
+		return fmt.Errorf("failed to remove monitor: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("monitor #%d not found", id)
+	}
+	return nil
+}
+
+// query runs a SELECT over monitors. Caller must hold s.mu.
+func (s *Store) query(clause string, args ...interface{}) ([]*Monitor, error) {
+	rows, err := s.db.Query(`SELECT id, owner, name, url, expect_status, keyword, interval_s, paused, job_id, state,
+		failures, last_checked, last_change, last_alert, last_error, latency_ms, created_at FROM monitors `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query monitors: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Monitor
+	for rows.Next() {
+		var m Monitor
+		var interval, checked, changed, alerted, latency, created int64
+		if err := rows.Scan(&m.ID, &m.Owner, &m.Name, &m.URL, &m.ExpectStatus, &m.Keyword, &interval, &m.Paused, &m.JobID,
+			&m.State, &m.Failures, &checked, &changed, &alerted, &m.LastError, &latency, &created); err != nil {
+			return nil, err
+		}
+		m.Interval = time.Duration(interval) * time.Second
+		m.LastChecked = timeOrZero(checked)
+		m.LastChange = timeOrZero(changed)
+		m.LastAlert = timeOrZero(alerted)
+		m.LastLatency = time.Duration(latency) * time.Millisecond
+		m.CreatedAt = time.Unix(created, 0)
+		list = append(list, &m)
+	}
+	return list, rows.Err()
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func timeOrZero(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
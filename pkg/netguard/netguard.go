@@ -0,0 +1,211 @@
+// Package netguard keeps outbound requests made on the model's behalf away
+// from the host's internal network: loopback, private, link-local (including
+// cloud metadata endpoints) and other non-public address ranges are refused
+// unless explicitly allowed.
+package netguard
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Non-public ranges not covered by the net.IP helpers.
+var reservedNets = mustParseCIDRs(
+	"0.0.0.0/8",       // "this" network
+	"100.64.0.0/10",   // carrier-grade NAT, also used by some metadata services
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // documentation
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // documentation
+	"203.0.113.0/24",  // documentation
+	"240.0.0.0/4",     // reserved, incl. broadcast
+	"64:ff9b::/96",    // NAT64, can embed any IPv4 address
+	"2001:db8::/32",   // documentation
+)
+
+// Guard validates destinations against the blocked ranges. Hosts and CIDRs
+// in the allowlist are let through, e.g. a home server on the LAN.
+type Guard struct {
+	allowHosts map[string]bool
+	allowNets  []*net.IPNet
+	resolver   *net.Resolver
+	dialer     *net.Dialer
+}
+
+// New creates a Guard. Each allow entry is a hostname ("nas.local"), an IP
+// address or a CIDR ("192.168.1.0/24").
+func New(allow []string) *Guard {
+	g := &Guard{
+		allowHosts: make(map[string]bool),
+		resolver:   net.DefaultResolver,
+		dialer:     &net.Dialer{Timeout: 15 * time.Second, KeepAlive: 30 * time.Second},
+	}
+	for _, entry := range allow {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			g.allowNets = append(g.allowNets, ipNet)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			g.allowNets = append(g.allowNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		} else {
+			g.allowHosts[strings.TrimSuffix(entry, ".")] = true
+		}
+	}
+	return g
+}
+
+// Blocked reports whether ip is in a loopback, private, link-local or
+// otherwise non-public range.
+func Blocked(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Guard) allowedIP(ip net.IP) bool {
+	for _, n := range g.allowNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return !Blocked(ip)
+}
+
+func (g *Guard) allowedHost(host string) bool {
+	return g.allowHosts[strings.TrimSuffix(strings.ToLower(host), ".")]
+}
+
+// resolve looks up host and returns its addresses, failing if any of them
+// is blocked. Checking every address stops a name with both a public and a
+// private record from slipping through.
+func (g *Guard) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if !g.allowedIP(ip) {
+			return nil, blockedError(host, ip)
+		}
+		return []net.IP{ip}, nil
+	}
+
+	addrs, err := g.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if !g.allowedHost(host) && !g.allowedIP(addr.IP) {
+			return nil, blockedError(host, addr.IP)
+		}
+		ips = append(ips, addr.IP)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	return ips, nil
+}
+
+func blockedError(host string, ip net.IP) error {
+	if host == ip.String() {
+		return fmt.Errorf("access to %s is blocked: private or internal address (allow it in tools.network.allow_private)", host)
+	}
+	return fmt.Errorf("access to %s is blocked: it resolves to private or internal address %s (allow it in tools.network.allow_private)", host, ip)
+}
+
+// CheckURL validates the host of an http(s) or ws(s) URL. Other schemes
+// (data:, about:, blob:) carry no network destination and pass.
+func (g *Guard) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return nil
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("missing domain in URL")
+	}
+	return g.CheckHost(ctx, host)
+}
+
+// CheckHost validates a bare hostname or IP address, for probes such as
+// ping that don't dial through the guard.
+func (g *Guard) CheckHost(ctx context.Context, host string) error {
+	if g.allowedHost(host) {
+		return nil
+	}
+	_, err := g.resolve(ctx, host)
+	return err
+}
+
+// DialContext resolves and checks the destination at connection time, so
+// redirects and DNS rebinding can't reach a blocked address after CheckURL
+// passed.
+func (g *Guard) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := g.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := g.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// Transport returns an http.Transport that dials through the guard.
+// Environment proxies are ignored, since the proxy would make the request
+// on the guard's behalf.
+func (g *Guard) Transport() *http.Transport {
+	return &http.Transport{
+		DialContext:         g.DialContext,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     30 * time.Second,
+		TLSHandshakeTimeout: 15 * time.Second,
+	}
+}
+
+// Client returns an http.Client that dials through the guard.
+func (g *Guard) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: g.Transport()}
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
@@ -0,0 +1,64 @@
+package netguard
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestBlocked(t *testing.T) {
+	blocked := []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1",
+		"169.254.169.254", "100.100.100.200", "0.0.0.0",
+		"::1", "fe80::1", "fd00:ec2::254", "::ffff:127.0.0.1",
+	}
+	for _, s := range blocked {
+		if !Blocked(net.ParseIP(s)) {
+			t.Errorf("expected %s to be blocked", s)
+		}
+	}
+
+	public := []string{"1.1.1.1", "93.184.216.34", "2606:4700:4700::1111"}
+	for _, s := range public {
+		if Blocked(net.ParseIP(s)) {
+			t.Errorf("expected %s to be allowed", s)
+		}
+	}
+}
+
+func TestCheckURLAllowlist(t *testing.T) {
+	ctx := context.Background()
+
+	g := New(nil)
+	for _, u := range []string{"http://127.0.0.1:8080/", "http://169.254.169.254/latest/meta-data/", "http://[::1]/"} {
+		if err := g.CheckURL(ctx, u); err == nil {
+			t.Errorf("expected %s to be blocked", u)
+		}
+	}
+	if err := g.CheckURL(ctx, "data:text/html,hi"); err != nil {
+		t.Errorf("non-network scheme should pass: %v", err)
+	}
+
+	g = New([]string{"192.168.1.0/24", "127.0.0.1", "nas.local"})
+	for _, u := range []string{"http://192.168.1.20/", "http://127.0.0.1:8123/api", "https://nas.local/"} {
+		if err := g.CheckURL(ctx, u); err != nil {
+			t.Errorf("expected %s to be allowed: %v", u, err)
+		}
+	}
+	if err := g.CheckURL(ctx, "http://192.168.2.1/"); err == nil {
+		t.Error("address outside the allowed CIDR should stay blocked")
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	ctx := context.Background()
+	g := New([]string{"10.0.0.5"})
+	if err := g.CheckHost(ctx, "10.0.0.5"); err != nil {
+		t.Errorf("expected an allowed address to pass: %v", err)
+	}
+	for _, host := range []string{"10.0.0.6", "localhost", "::1"} {
+		if err := g.CheckHost(ctx, host); err == nil {
+			t.Errorf("expected %s to be blocked", host)
+		}
+	}
+}
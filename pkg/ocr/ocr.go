@@ -0,0 +1,147 @@
+// Package ocr extracts the text in images, so receipts, screenshots and
+// photographed documents are usable by a text-only model. Tesseract runs
+// locally when it is installed; otherwise a vision model transcribes the
+// image.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// MaxImageSize is the largest image an engine is given.
+const MaxImageSize = 10 << 20 // bytes
+
+const visionPrompt = "Transcribe all text in this image exactly as written, keeping line breaks and the order of " +
+	"columns and rows. Do not describe the image or add commentary. If there is no text, reply with NOTHING."
+
+// Engine extracts text from an image file.
+type Engine interface {
+	Name() string
+	Extract(ctx context.Context, path string) (string, error)
+}
+
+// Tesseract runs the tesseract CLI.
+type Tesseract struct {
+	binary    string
+	languages string // "eng", "eng+vie"
+}
+
+// NewTesseract finds the binary (default: tesseract on PATH). languages
+// defaults to eng.
+func NewTesseract(binary, languages string) (*Tesseract, error) {
+	if binary == "" {
+		binary = "tesseract"
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("tesseract binary %q not found: %w", binary, err)
+	}
+	if languages == "" {
+		languages = "eng"
+	}
+	return &Tesseract{binary: path, languages: languages}, nil
+}
+
+func (t *Tesseract) Name() string { return "tesseract" }
+
+func (t *Tesseract) Extract(ctx context.Context, path string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.binary, path, "stdout", "-l", t.languages)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("tesseract failed: %w: %s", err, firstLine(stderr.String()))
+	}
+	return Clean(stdout.String()), nil
+}
+
+// Vision asks a vision-capable model to transcribe the image.
+type Vision struct {
+	provider providers.LLMProvider
+	model    string
+}
+
+func NewVision(provider providers.LLMProvider, model string) *Vision {
+	return &Vision{provider: provider, model: model}
+}
+
+func (v *Vision) Name() string { return v.model }
+
+func (v *Vision) Extract(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("%s is not an image (detected %s)", path, mimeType)
+	}
+
+	messages := []providers.Message{{
+		Role:    "user",
+		Content: visionPrompt,
+		Parts: []providers.ContentPart{
+			{Type: "text", Text: visionPrompt},
+			{Type: "image_url", ImageURL: &providers.ImageURL{
+				URL: "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data),
+			}},
+		},
+	}}
+	resp, err := v.provider.Chat(ctx, messages, nil, v.model, map[string]interface{}{
+		"max_tokens":  2000,
+		"temperature": 0.0,
+	})
+	if err != nil {
+		return "", fmt.Errorf("vision model %s failed: %w", v.model, err)
+	}
+	text := Clean(resp.Content)
+	if strings.EqualFold(strings.Trim(text, ". "), "nothing") {
+		return "", nil
+	}
+	return text, nil
+}
+
+// Clean trims trailing spaces from each line and collapses runs of blank
+// lines, which OCR output is full of.
+func Clean(text string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(strings.ReplaceAll(line, "\f", ""), " \t")
+		if strings.TrimSpace(line) == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Truncate cuts text to at most max bytes on a line or rune boundary.
+func Truncate(text string, max int) (string, bool) {
+	if max <= 0 || len(text) <= max {
+		return text, false
+	}
+	cut := text[:max]
+	if i := strings.LastIndexByte(cut, '\n'); i > max/2 {
+		cut = cut[:i]
+	}
+	return strings.ToValidUTF8(cut, ""), true
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	return s
+}
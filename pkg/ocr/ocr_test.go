@@ -0,0 +1,52 @@
+package ocr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	got := Clean("\n\nTOTAL   \r\n\n\n\n$12.50\f\n  \n")
+	if want := "TOTAL\n\n$12.50"; got != want {
+		t.Errorf("Clean() = %q, want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got, cut := Truncate("short", 100); got != "short" || cut {
+		t.Errorf("expected short text untouched, got %q %v", got, cut)
+	}
+	if got, cut := Truncate("line one\nline two\nline three", 20); got != "line one\nline two" || !cut {
+		t.Errorf("expected a cut at the last line break, got %q %v", got, cut)
+	}
+	if got, _ := Truncate("ăăăăăăăăăă", 5); got != "ăă" {
+		t.Errorf("expected whole runes only, got %q", got)
+	}
+}
+
+func TestTesseract(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "tesseract")
+	body := "#!/bin/sh\n[ \"$2\" = stdout ] && [ \"$4\" = eng+vie ] || exit 1\nprintf 'Receipt  \\n\\n\\n\\nTotal: 42\\n\\f'\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := NewTesseract(script, "eng+vie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := engine.Extract(context.Background(), filepath.Join(dir, "receipt.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Receipt\n\nTotal: 42" {
+		t.Errorf("unexpected text %q", text)
+	}
+
+	if _, err := NewTesseract(filepath.Join(dir, "missing"), ""); err == nil {
+		t.Error("expected an error for a missing binary")
+	}
+}
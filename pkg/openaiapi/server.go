@@ -0,0 +1,348 @@
+// Package openaiapi serves an OpenAI-compatible chat completions API, so
+// any client that talks to OpenAI (chat apps, SDKs, editor plugins) can
+// use the assistant as a model. Each request becomes an agent turn with
+// tools, memory and sessions; the assistant keeps the conversation
+// history itself, so only the latest user message of a request is used.
+package openaiapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// maxBody is the largest request accepted.
+const maxBody = 4 << 20
+
+// maxInFlight is how many turns may run at once. Further requests are
+// refused with 429 until one finishes.
+const maxInFlight = 4
+
+// turnTimeout bounds one turn.
+const turnTimeout = 10 * time.Minute
+
+// SessionHeader names the session a request belongs to, overriding the
+// request's user field. Clients should send one of the two: without them
+// every request is a turn of its own.
+const SessionHeader = "X-Mclaw-Session"
+
+// maxHistoryChars bounds the earlier messages passed along with a request
+// that has no session.
+const maxHistoryChars = 20000
+
+// Result is the outcome of a turn.
+type Result struct {
+	Content          string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// RunFunc runs an agent turn in the given session. When keep is false the
+// session is a one-off, and the caller discards it after the turn so
+// anonymous requests do not pile up in the session store.
+type RunFunc func(ctx context.Context, prompt, sessionKey string, keep bool) (*Result, error)
+
+// Server handles /v1/chat/completions and /v1/models.
+type Server struct {
+	listen   string
+	keys     []string
+	model    string
+	run      RunFunc
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer returns a server for cfg that runs turns with run.
+func NewServer(cfg config.APIConfig, run RunFunc) *Server {
+	model := cfg.Model
+	if model == "" {
+		model = "mclaw"
+	}
+	return &Server{listen: cfg.Listen, keys: cfg.Keys, model: model, run: run, inFlight: make(chan struct{}, maxInFlight)}
+}
+
+// Run serves until ctx is cancelled, then waits for running turns.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.listen)
+	if err != nil {
+		return fmt.Errorf("api: %w", err)
+	}
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second, BaseContext: func(net.Listener) context.Context { return ctx }}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("[api] Listening on http://%s/v1/chat/completions (model %q)", ln.Addr(), s.model)
+	err = srv.Serve(ln)
+	s.wg.Wait()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid or missing API key")
+		return
+	}
+	switch r.URL.Path {
+	case "/v1/models":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"object": "list",
+			"data":   []interface{}{s.modelInfo()},
+		})
+	case "/v1/models/" + s.model:
+		writeJSON(w, http.StatusOK, s.modelInfo())
+	case "/v1/chat/completions":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, "invalid_request_error", "Use POST")
+			return
+		}
+		s.chatCompletions(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "invalid_request_error", "Unknown endpoint "+r.URL.Path)
+	}
+}
+
+func (s *Server) modelInfo() map[string]interface{} {
+	return map[string]interface{}{"id": s.model, "object": "model", "created": 0, "owned_by": "mclaw"}
+}
+
+// chatRequest is the part of an OpenAI chat completion request we use.
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	User     string        `json:"user"`
+}
+
+type chatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// text returns the message's text: a plain string, or the text parts of
+// a multimodal content array.
+func (m chatMessage) text() string {
+	var s string
+	if json.Unmarshal(m.Content, &s) == nil {
+		return s
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	json.Unmarshal(m.Content, &parts)
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (s *Server) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "Failed to read body")
+		return
+	}
+	if len(body) > maxBody {
+		writeError(w, http.StatusRequestEntityTooLarge, "invalid_request_error", "Request too large")
+		return
+	}
+	var req chatRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "Invalid JSON: "+err.Error())
+		return
+	}
+	prompt := lastUserMessage(req.Messages)
+	if strings.TrimSpace(prompt) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "messages must end with a user message")
+		return
+	}
+
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		writeError(w, http.StatusTooManyRequests, "rate_limit_exceeded", "Too many requests in progress")
+		return
+	}
+	s.wg.Add(1)
+	defer s.wg.Done()
+	defer func() { <-s.inFlight }()
+
+	ctx, cancel := context.WithTimeout(r.Context(), turnTimeout)
+	defer cancel()
+	key, kept := sessionKey(r, req)
+	if !kept {
+		prompt = withHistory(req.Messages, prompt)
+	}
+	result, err := s.run(ctx, prompt, key, kept)
+	if err != nil {
+		log.Printf("[api] Turn failed: %v", err)
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	id := "chatcmpl-" + randomID()
+	created := time.Now().Unix()
+	usage := map[string]int{
+		"prompt_tokens":     result.PromptTokens,
+		"completion_tokens": result.CompletionTokens,
+		"total_tokens":      result.PromptTokens + result.CompletionTokens,
+	}
+	if req.Stream {
+		writeStream(w, id, created, s.model, result.Content, usage)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      id,
+		"object":  "chat.completion",
+		"created": created,
+		"model":   s.model,
+		"choices": []interface{}{map[string]interface{}{
+			"index":         0,
+			"message":       map[string]string{"role": "assistant", "content": result.Content},
+			"finish_reason": "stop",
+		}},
+		"usage": usage,
+	})
+}
+
+// writeStream sends the reply as server-sent events, the way streaming
+// clients expect it. The turn has already finished, so the content comes
+// in one chunk.
+func writeStream(w http.ResponseWriter, id string, created int64, model, content string, usage map[string]int) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	chunk := func(delta map[string]string, finish interface{}, extra map[string]interface{}) {
+		event := map[string]interface{}{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   model,
+			"choices": []interface{}{map[string]interface{}{"index": 0, "delta": delta, "finish_reason": finish}},
+		}
+		for k, v := range extra {
+			event[k] = v
+		}
+		data, _ := json.Marshal(event)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	chunk(map[string]string{"role": "assistant", "content": content}, nil, nil)
+	chunk(map[string]string{}, "stop", map[string]interface{}{"usage": usage})
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func lastUserMessage(messages []chatMessage) string {
+	if len(messages) == 0 || messages[len(messages)-1].Role != "user" {
+		return ""
+	}
+	return messages[len(messages)-1].text()
+}
+
+var unsafeKeyChars = regexp.MustCompile(`[^A-Za-z0-9_.@-]+`)
+
+// sessionKey picks the session for a request: the session header, else
+// the user field. Without either, nothing in the messages reliably tells
+// one client chat from another (many start with the same "hi"), so the
+// request gets a session of its own and kept is false.
+func sessionKey(r *http.Request, req chatRequest) (key string, kept bool) {
+	if id := r.Header.Get(SessionHeader); id != "" {
+		return "api:" + clean(id), true
+	}
+	if req.User != "" {
+		return "api:" + clean(req.User), true
+	}
+	return "api:once-" + randomID()[:12], false
+}
+
+// withHistory prepends the messages before the last one to prompt, for a
+// request without a kept session whose context mclaw has not seen. The
+// oldest messages are dropped beyond maxHistoryChars.
+func withHistory(messages []chatMessage, prompt string) string {
+	if len(messages) < 2 {
+		return prompt
+	}
+	var lines []string
+	size := 0
+	for i := len(messages) - 2; i >= 0; i-- {
+		text := strings.TrimSpace(messages[i].text())
+		if text == "" {
+			continue
+		}
+		line := messages[i].Role + ": " + text
+		if size+len(line) > maxHistoryChars {
+			break
+		}
+		size += len(line)
+		lines = append([]string{line}, lines...)
+	}
+	if len(lines) == 0 {
+		return prompt
+	}
+	return "Earlier in this conversation:\n\n" + strings.Join(lines, "\n\n") + "\n\n---\n\n" + prompt
+}
+
+func clean(id string) string {
+	id = unsafeKeyChars.ReplaceAllString(id, "_")
+	if len(id) > 64 {
+		id = id[:64]
+	}
+	return id
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+	for _, key := range s.keys {
+		if key != "" && subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeError(w http.ResponseWriter, status int, kind, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{"message": message, "type": kind, "code": kind},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
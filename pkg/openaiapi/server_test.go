@@ -0,0 +1,124 @@
+package openaiapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+type turn struct {
+	prompt, session string
+	keep            bool
+}
+
+func newTestServer(turns *[]turn) *Server {
+	return NewServer(config.APIConfig{Keys: []string{"k1"}}, func(ctx context.Context, prompt, sessionKey string, keep bool) (*Result, error) {
+		*turns = append(*turns, turn{prompt, sessionKey, keep})
+		return &Result{Content: "Hi **there**", PromptTokens: 10, CompletionTokens: 3}, nil
+	})
+}
+
+func post(s *Server, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer k1")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestChatCompletion(t *testing.T) {
+	var turns []turn
+	s := newTestServer(&turns)
+	body := `{"model":"gpt-4o","messages":[
+		{"role":"system","content":"be nice"},
+		{"role":"user","content":"first question"},
+		{"role":"assistant","content":"an answer"},
+		{"role":"user","content":[{"type":"text","text":"second"},{"type":"image_url","image_url":{"url":"x"}},{"type":"text","text":"question"}]}]}`
+
+	rec := post(s, "/v1/chat/completions", body, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Object  string `json:"object"`
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Object != "chat.completion" || resp.Model != "mclaw" || len(resp.Choices) != 1 ||
+		resp.Choices[0].Message.Content != "Hi **there**" || resp.Choices[0].FinishReason != "stop" || resp.Usage.TotalTokens != 13 {
+		t.Errorf("unexpected response %s", rec.Body)
+	}
+
+	// Without a session the earlier messages come along as context
+	if len(turns) != 1 || !strings.HasSuffix(turns[0].prompt, "---\n\nsecond\nquestion") ||
+		!strings.Contains(turns[0].prompt, "system: be nice\n\nuser: first question\n\nassistant: an answer") {
+		t.Fatalf("unexpected turns %+v", turns)
+	}
+
+	// Chats that start the same way never share a session
+	post(s, "/v1/chat/completions", `{"messages":[{"role":"user","content":"hi"}]}`, nil)
+	post(s, "/v1/chat/completions", `{"messages":[{"role":"user","content":"hi"}]}`, nil)
+	post(s, "/v1/chat/completions", `{"user":"bob/1","messages":[{"role":"user","content":"hi"}]}`, map[string]string{SessionHeader: ""})
+	post(s, "/v1/chat/completions", `{"user":"bob","messages":[{"role":"user","content":"first"},{"role":"assistant","content":"ok"},{"role":"user","content":"hi"}]}`, map[string]string{SessionHeader: "desk"})
+	if turns[1].session == turns[2].session || turns[1].prompt != "hi" || turns[1].keep ||
+		turns[3].session != "api:bob_1" || turns[4].session != "api:desk" || turns[4].prompt != "hi" || !turns[4].keep {
+		t.Errorf("unexpected sessions %+v", turns)
+	}
+}
+
+func TestChatCompletionStream(t *testing.T) {
+	var turns []turn
+	s := newTestServer(&turns)
+	rec := post(s, "/v1/chat/completions", `{"stream":true,"messages":[{"role":"user","content":"hi"}]}`, nil)
+	out := rec.Body.String()
+	if rec.Header().Get("Content-Type") != "text/event-stream" || !strings.Contains(out, `"delta":{"content":"Hi **there**","role":"assistant"}`) ||
+		!strings.Contains(out, `"finish_reason":"stop"`) || !strings.HasSuffix(out, "data: [DONE]\n\n") {
+		t.Errorf("unexpected stream:\n%s", out)
+	}
+}
+
+func TestAuthAndErrors(t *testing.T) {
+	var turns []turn
+	s := newTestServer(&turns)
+
+	if rec := post(s, "/v1/chat/completions", `{}`, map[string]string{"Authorization": "Bearer wrong"}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a wrong key, got %d", rec.Code)
+	}
+	if rec := post(s, "/v1/chat/completions", `{"messages":[{"role":"user","content":"x"},{"role":"assistant","content":"y"}]}`, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 when the last message is not from the user, got %d", rec.Code)
+	}
+	if rec := post(s, "/v1/chat/completions", `not json`, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for bad JSON, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer k1")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"id":"mclaw"`) {
+		t.Errorf("unexpected models response %d: %s", rec.Code, rec.Body)
+	}
+	if len(turns) != 0 {
+		t.Errorf("expected no turns, got %+v", turns)
+	}
+}
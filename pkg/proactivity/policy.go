@@ -0,0 +1,251 @@
+// Package proactivity decides whether a message the assistant sends on its
+// own (heartbeat, cron, feed, monitor and watcher results) may reach a
+// chat now. Each chat gets at most a set number of such messages a day,
+// and during quiet hours they are held and delivered when the quiet hours
+// end. Urgent messages skip both. Counts and held messages are kept in a
+// JSON file so they survive a restart.
+package proactivity
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// Decision is what happens to a proactive message.
+type Decision int
+
+const (
+	Send Decision = iota // deliver it now
+	Hold                 // deliver it when the quiet hours end
+	Drop                 // discard it: the chat's daily allowance is used up
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Hold:
+		return "hold"
+	case Drop:
+		return "drop"
+	}
+	return "send"
+}
+
+// maxHeld caps the messages held per chat during one quiet period.
+const maxHeld = 50
+
+// Options configure a Policy.
+type Options struct {
+	MaxPerDay      int      // per chat; 0 = unlimited
+	QuietHours     string   // "22:00-07:00"; empty = none
+	DropWhenQuiet  bool     // discard instead of holding during quiet hours
+	UrgentPrefixes []string // content starting with one of these is urgent
+}
+
+type heldMessage struct {
+	Message bus.OutboundMessage `json:"message"`
+	Held    time.Time           `json:"held"`
+}
+
+type stateFile struct {
+	Day    string         `json:"day"`
+	Counts map[string]int `json:"counts"`
+	Held   []heldMessage  `json:"held"`
+}
+
+// Policy throttles proactive messages per chat.
+type Policy struct {
+	path       string
+	opts       Options
+	quiet      bool
+	quietStart int // minutes after midnight
+	quietEnd   int
+
+	mu     sync.Mutex
+	day    string
+	counts map[string]int // proactive messages sent today, by "channel:chat_id"
+	held   []heldMessage
+}
+
+// New creates a policy keeping its state at path.
+func New(path string, opts Options) (*Policy, error) {
+	p := &Policy{path: path, opts: opts, counts: make(map[string]int)}
+	if strings.TrimSpace(opts.QuietHours) != "" {
+		start, end, err := ParseQuietHours(opts.QuietHours)
+		if err != nil {
+			return nil, err
+		}
+		p.quiet, p.quietStart, p.quietEnd = true, start, end
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[proactivity] Failed to read %s: %v", path, err)
+		}
+		return p, nil
+	}
+	var f stateFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		log.Printf("[proactivity] Ignoring corrupt %s: %v", path, err)
+		return p, nil
+	}
+	p.day, p.held = f.Day, f.Held
+	for k, v := range f.Counts {
+		p.counts[k] = v
+	}
+	return p, nil
+}
+
+// Urgent reports whether msg skips the daily cap and quiet hours: it is
+// flagged Urgent or starts with one of the urgent prefixes.
+func (p *Policy) Urgent(msg bus.OutboundMessage) bool {
+	if msg.Urgent {
+		return true
+	}
+	content := strings.TrimSpace(msg.Content)
+	for _, prefix := range p.opts.UrgentPrefixes {
+		if prefix != "" && strings.HasPrefix(strings.ToLower(content), strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Quiet reports whether now falls in the quiet hours.
+func (p *Policy) Quiet(now time.Time) bool {
+	if !p.quiet {
+		return false
+	}
+	m := now.Hour()*60 + now.Minute()
+	if p.quietStart <= p.quietEnd {
+		return m >= p.quietStart && m < p.quietEnd
+	}
+	return m >= p.quietStart || m < p.quietEnd // spans midnight
+}
+
+// Check decides what happens to msg at now, counting it against its chat's
+// daily allowance when it is sent and keeping it when it is held. Messages
+// that are not proactive are always sent.
+func (p *Policy) Check(msg bus.OutboundMessage, now time.Time) Decision {
+	if !msg.Proactive || msg.Interim() || p.Urgent(msg) {
+		return Send
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rollover(now)
+
+	target := msg.Channel + ":" + msg.ChatID
+	if p.Quiet(now) {
+		if p.opts.DropWhenQuiet || p.heldFor(target) >= maxHeld {
+			return Drop
+		}
+		p.held = append(p.held, heldMessage{Message: msg, Held: now})
+		p.save()
+		return Hold
+	}
+	if p.opts.MaxPerDay > 0 && p.counts[target] >= p.opts.MaxPerDay {
+		return Drop
+	}
+	p.counts[target]++
+	p.save()
+	return Send
+}
+
+// Release returns the held messages once the quiet hours are over, in the
+// order they were held, counting them against the new day's allowance.
+// Messages beyond the allowance are dropped.
+func (p *Policy) Release(now time.Time) []bus.OutboundMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.held) == 0 || p.Quiet(now) {
+		return nil
+	}
+	p.rollover(now)
+
+	var out []bus.OutboundMessage
+	dropped := 0
+	for _, h := range p.held {
+		target := h.Message.Channel + ":" + h.Message.ChatID
+		if p.opts.MaxPerDay > 0 && p.counts[target] >= p.opts.MaxPerDay {
+			dropped++
+			continue
+		}
+		p.counts[target]++
+		out = append(out, h.Message)
+	}
+	if dropped > 0 {
+		log.Printf("[proactivity] Dropped %d held message(s) over the daily limit", dropped)
+	}
+	p.held = nil
+	p.save()
+	return out
+}
+
+// Held returns how many messages wait for the quiet hours to end.
+func (p *Policy) Held() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.held)
+}
+
+// rollover resets the daily counts when the day changes. Caller must hold
+// p.mu.
+func (p *Policy) rollover(now time.Time) {
+	if day := now.Format("2006-01-02"); day != p.day {
+		p.day = day
+		p.counts = make(map[string]int)
+	}
+}
+
+func (p *Policy) heldFor(target string) int {
+	n := 0
+	for _, h := range p.held {
+		if h.Message.Channel+":"+h.Message.ChatID == target {
+			n++
+		}
+	}
+	return n
+}
+
+// save writes the state file. Caller must hold p.mu.
+func (p *Policy) save() {
+	data, err := json.MarshalIndent(stateFile{Day: p.day, Counts: p.counts, Held: p.held}, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(p.path), 0755); err == nil {
+			err = os.WriteFile(p.path, data, 0644)
+		}
+	}
+	if err != nil {
+		log.Printf("[proactivity] Failed to save %s: %v", p.path, err)
+	}
+}
+
+// ParseQuietHours parses "HH:MM-HH:MM" into minutes after midnight. The
+// range may span midnight, e.g. "22:00-07:00".
+func ParseQuietHours(s string) (start, end int, err error) {
+	from, to, ok := strings.Cut(strings.ReplaceAll(s, " ", ""), "-")
+	start, okA := parseClock(from)
+	end, okB := parseClock(to)
+	if !ok || !okA || !okB || start == end {
+		return 0, 0, fmt.Errorf("quiet hours %q must look like 22:00-07:00", s)
+	}
+	return start, end, nil
+}
+
+// parseClock parses "7:00" or "07:00" into minutes after midnight.
+func parseClock(s string) (int, bool) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
@@ -0,0 +1,79 @@
+package proactivity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 3, day, hour, minute, 0, 0, time.Local)
+}
+
+func TestPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proactivity.json")
+	p, err := New(path, Options{MaxPerDay: 2, QuietHours: "22:00-7:00", UrgentPrefixes: []string{"[urgent]"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := func(chatID, content string) bus.OutboundMessage {
+		return bus.OutboundMessage{Channel: "telegram", ChatID: chatID, Content: content, Proactive: true}
+	}
+
+	for i, want := range []Decision{Send, Send, Drop} {
+		if got := p.Check(msg("1", "feed item"), at(4, 12, i)); got != want {
+			t.Errorf("message %d: got %s, want %s", i+1, got, want)
+		}
+	}
+	if got := p.Check(msg("2", "feed item"), at(4, 12, 5)); got != Send {
+		t.Errorf("expected other chats to have their own allowance, got %s", got)
+	}
+	if got := p.Check(bus.OutboundMessage{Channel: "telegram", ChatID: "1", Content: "a reply"}, at(4, 12, 6)); got != Send {
+		t.Errorf("expected replies to be sent, got %s", got)
+	}
+	if got := p.Check(msg("1", "[URGENT] disk full"), at(4, 12, 7)); got != Send {
+		t.Errorf("expected an urgent prefix to override the limit, got %s", got)
+	}
+	urgent := msg("1", "site down")
+	urgent.Urgent = true
+	if got := p.Check(urgent, at(4, 23, 0)); got != Send {
+		t.Errorf("expected urgent messages during quiet hours, got %s", got)
+	}
+
+	if got := p.Check(msg("1", "night feed"), at(4, 23, 30)); got != Hold {
+		t.Errorf("expected a hold during quiet hours, got %s", got)
+	}
+	if got := p.Check(msg("1", "early cron"), at(5, 6, 59)); got != Hold {
+		t.Errorf("expected a hold before the quiet hours end, got %s", got)
+	}
+	if released := p.Release(at(5, 6, 59)); len(released) != 0 {
+		t.Errorf("expected nothing released during quiet hours, got %+v", released)
+	}
+
+	// The state survives a restart
+	p, err = New(path, Options{MaxPerDay: 1, QuietHours: "22:00-07:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Held() != 2 {
+		t.Fatalf("expected 2 held messages after reload, got %d", p.Held())
+	}
+	released := p.Release(at(5, 7, 0))
+	if len(released) != 1 || released[0].Content != "night feed" {
+		t.Errorf("expected the first held message within the new day's limit, got %+v", released)
+	}
+	if p.Held() != 0 {
+		t.Errorf("expected the held messages to be cleared, got %d", p.Held())
+	}
+	if got := p.Check(msg("1", "more"), at(5, 9, 0)); got != Drop {
+		t.Errorf("expected released messages to count against the day, got %s", got)
+	}
+
+	for _, bad := range []string{"22:00", "25:00-07:00", "07:00-07:00", "night"} {
+		if _, _, err := ParseQuietHours(bad); err == nil {
+			t.Errorf("ParseQuietHours(%q) should fail", bad)
+		}
+	}
+}
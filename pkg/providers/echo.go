@@ -0,0 +1,35 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// EchoProvider answers every call by repeating the last user message, for
+// dry runs: channels, cron jobs and allow-lists work end to end without
+// calling a model or spending tokens. It never calls tools.
+type EchoProvider struct{}
+
+func (p *EchoProvider) Chat(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}) (*LLMResponse, error) {
+	last := ""
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			last = messages[i].Content
+			break
+		}
+	}
+	last = strings.TrimSpace(last)
+	if len(last) > 300 {
+		last = last[:300] + "..."
+	}
+	return &LLMResponse{
+		Content:      fmt.Sprintf("🧪 Dry run: %s would answer %q (%d messages, %d tools offered)", model, last, len(messages), len(tools)),
+		FinishReason: "stop",
+		Usage:        &UsageInfo{},
+	}, nil
+}
+
+func (p *EchoProvider) GetDefaultModel() string {
+	return ""
+}
@@ -11,21 +11,32 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ntminh611/mclaw/pkg/config"
 	"github.com/ntminh611/mclaw/pkg/logger"
 )
 
+// DefaultStreamIdleTimeout is how long a response stream may go without
+// data before it is treated as stalled.
+const DefaultStreamIdleTimeout = 90 * time.Second
+
+// ErrStreamStalled is returned when a response stream stops sending data
+// before anything usable arrived.
+var ErrStreamStalled = errors.New("response stream stalled")
+
 type HTTPProvider struct {
 	apiKey        string
 	apiBase       string
 	modelOverride string
 	httpClient    *http.Client
+	streamIdle    time.Duration // 0 = wait for the client timeout
 }
 
 func NewHTTPProvider(apiKey, apiBase, modelOverride string) *HTTPProvider {
@@ -36,9 +47,16 @@ func NewHTTPProvider(apiKey, apiBase, modelOverride string) *HTTPProvider {
 		httpClient: &http.Client{
 			Timeout: 600 * time.Second,
 		},
+		streamIdle: DefaultStreamIdleTimeout,
 	}
 }
 
+// SetStreamIdleTimeout sets how long a stream may go without data before
+// the provider gives up on it and returns what it has. 0 disables the check.
+func (p *HTTPProvider) SetStreamIdleTimeout(d time.Duration) {
+	p.streamIdle = d
+}
+
 func (p *HTTPProvider) Chat(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}) (*LLMResponse, error) {
 	if p.apiBase == "" {
 		return nil, fmt.Errorf("API base not configured")
@@ -54,6 +72,9 @@ func (p *HTTPProvider) Chat(ctx context.Context, messages []Message, tools []Too
 		"model":    actualModel,
 		"messages": messages,
 		"stream":   true,
+		"stream_options": map[string]interface{}{
+			"include_usage": true,
+		},
 	}
 
 	if len(tools) > 0 {
@@ -69,6 +90,10 @@ func (p *HTTPProvider) Chat(ctx context.Context, messages []Message, tools []Too
 		requestBody["temperature"] = temperature
 	}
 
+	if topP, ok := options["top_p"].(float64); ok {
+		requestBody["top_p"] = topP
+	}
+
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -76,7 +101,11 @@ func (p *HTTPProvider) Chat(ctx context.Context, messages []Message, tools []Too
 
 	logger.InfoC("llm", fmt.Sprintf("POST %s/chat/completions (model=%s, messages=%d, stream=true)", p.apiBase, actualModel, len(messages)))
 
-	req, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(jsonData))
+	// Cancelled by the idle timer when the stream stalls
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, "POST", p.apiBase+"/chat/completions", bytes.NewReader(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -100,7 +129,7 @@ func (p *HTTPProvider) Chat(ctx context.Context, messages []Message, tools []Too
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Check if response is actually streamed
@@ -115,17 +144,63 @@ func (p *HTTPProvider) Chat(ctx context.Context, messages []Message, tools []Too
 		return p.parseResponse(body)
 	}
 
-	return p.parseStreamResponse(resp.Body)
+	var body io.Reader = resp.Body
+	if p.streamIdle > 0 {
+		idle := newIdleReader(resp.Body, p.streamIdle, cancel)
+		defer idle.stop()
+		body = idle
+	}
+
+	onUsage, _ := options["on_usage"].(UsageCallback)
+	return p.parseStreamResponse(body, EstimatePromptTokens(messages), onUsage)
+}
+
+// idleReader cancels the request when its reader goes idle too long. Reads
+// then fail with ErrStreamStalled.
+type idleReader struct {
+	r       io.Reader
+	idle    time.Duration
+	timer   *time.Timer
+	stalled atomic.Bool
+}
+
+func newIdleReader(r io.Reader, idle time.Duration, cancel context.CancelFunc) *idleReader {
+	ir := &idleReader{r: r, idle: idle}
+	ir.timer = time.AfterFunc(idle, func() {
+		ir.stalled.Store(true)
+		cancel()
+	})
+	return ir
 }
 
-func (p *HTTPProvider) parseStreamResponse(body io.Reader) (*LLMResponse, error) {
+func (ir *idleReader) Read(b []byte) (int, error) {
+	n, err := ir.r.Read(b)
+	if n > 0 {
+		ir.timer.Reset(ir.idle)
+	}
+	if err != nil && err != io.EOF && ir.stalled.Load() {
+		err = fmt.Errorf("%w: no data for %s", ErrStreamStalled, ir.idle)
+	}
+	return n, err
+}
+
+func (ir *idleReader) stop() {
+	ir.timer.Stop()
+}
+
+// parseStreamResponse accumulates an SSE stream into a single LLMResponse.
+// promptTokens is the estimated prompt size, used when the provider does not
+// report usage in the final chunk. onUsage (optional) receives running counts.
+func (p *HTTPProvider) parseStreamResponse(body io.Reader, promptTokens int, onUsage UsageCallback) (*LLMResponse, error) {
 	scanner := bufio.NewScanner(body)
 	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 
 	var contentBuilder strings.Builder
 	var thinkingBuilder strings.Builder
 	var finishReason string
+	var reportedUsage *UsageInfo
 	thinkingDone := false
+	lastUsageReport := 0
 
 	// Tool call accumulation by index
 	type partialToolCall struct {
@@ -168,12 +243,18 @@ func (p *HTTPProvider) parseStreamResponse(body io.Reader) (*LLMResponse, error)
 				} `json:"delta"`
 				FinishReason *string `json:"finish_reason"`
 			} `json:"choices"`
+			Usage *UsageInfo `json:"usage"`
 		}
 
 		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
 			continue
 		}
 
+		// Usage typically arrives in the final chunk (with empty choices)
+		if chunk.Usage != nil && chunk.Usage.TotalTokens > 0 {
+			reportedUsage = chunk.Usage
+		}
+
 		if len(chunk.Choices) == 0 {
 			continue
 		}
@@ -240,10 +321,33 @@ func (p *HTTPProvider) parseStreamResponse(body io.Reader) (*LLMResponse, error)
 		if chunk.Choices[0].FinishReason != nil {
 			finishReason = *chunk.Choices[0].FinishReason
 		}
+
+		// Report running estimates roughly every 100 tokens of output
+		if onUsage != nil {
+			streamed := contentBuilder.Len() + thinkingBuilder.Len()
+			if streamed-lastUsageReport >= 400 {
+				lastUsageReport = streamed
+				completion := streamed / 4
+				onUsage(UsageInfo{
+					PromptTokens:     promptTokens,
+					CompletionTokens: completion,
+					TotalTokens:      promptTokens + completion,
+					Estimated:        true,
+				})
+			}
+		}
 	}
 
+	// A stream that breaks off mid-answer still returns the text received so
+	// far, marked truncated; half-streamed tool calls are unusable and dropped
+	truncated := false
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("stream reading error: %w", err)
+		if contentBuilder.Len() == 0 || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("stream reading error: %w", err)
+		}
+		logger.WarnC("llm", fmt.Sprintf("Stream interrupted after %d chars, returning partial response: %v", contentBuilder.Len(), err))
+		truncated = true
+		toolCallMap = nil
 	}
 
 	// Build tool calls
@@ -271,14 +375,33 @@ func (p *HTTPProvider) parseStreamResponse(body io.Reader) (*LLMResponse, error)
 	content := contentBuilder.String()
 	thinking := thinkingBuilder.String()
 
-	logger.InfoC("llm", fmt.Sprintf("Stream complete: content=%d chars, thinking=%d chars, tools=%d",
-		len(content), len(thinking), len(toolCalls)))
+	usage := reportedUsage
+	if usage == nil {
+		completion := EstimateTokens(content) + EstimateTokens(thinking)
+		for _, ptc := range toolCallMap {
+			completion += EstimateTokens(ptc.ArgsJSON.String())
+		}
+		usage = &UsageInfo{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completion,
+			TotalTokens:      promptTokens + completion,
+			Estimated:        true,
+		}
+	}
+	if onUsage != nil {
+		onUsage(*usage)
+	}
+
+	logger.InfoC("llm", fmt.Sprintf("Stream complete: content=%d chars, thinking=%d chars, tools=%d, tokens=%d (estimated=%t)",
+		len(content), len(thinking), len(toolCalls), usage.TotalTokens, usage.Estimated))
 
 	return &LLMResponse{
 		Content:      content,
 		Thinking:     thinking,
 		ToolCalls:    toolCalls,
 		FinishReason: finishReason,
+		Usage:        usage,
+		Truncated:    truncated,
 	}, nil
 }
 
@@ -363,6 +486,10 @@ func CreateProvider(cfg *config.Config) (LLMProvider, error) {
 }
 
 func CreateProviderForModel(cfg *config.Config, model string) (LLMProvider, error) {
+	if cfg.DryRun {
+		return &EchoProvider{}, nil
+	}
+
 	var apiKey, apiBase string
 
 	lowerModel := strings.ToLower(model)
@@ -488,5 +615,7 @@ func CreateProviderForModel(cfg *config.Config, model string) (LLMProvider, erro
 		return nil, fmt.Errorf("no API base configured for provider (model: %s)", model)
 	}
 
-	return NewHTTPProvider(apiKey, apiBase, modelName), nil
+	p := NewHTTPProvider(apiKey, apiBase, modelName)
+	p.SetStreamIdleTimeout(time.Duration(cfg.Agents.Defaults.StreamIdleSecs) * time.Second)
+	return p, nil
 }
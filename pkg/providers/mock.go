@@ -0,0 +1,197 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrScriptExhausted is returned by MockProvider when a call arrives after
+// every scripted response was used and no default is set.
+var ErrScriptExhausted = errors.New("mock provider: no scripted response left")
+
+// MockCall is one request a MockProvider received.
+type MockCall struct {
+	Messages []Message
+	Tools    []string // names of the tools offered
+	Model    string
+	Options  map[string]interface{}
+}
+
+// LastUserMessage returns the content of the call's last user message.
+func (c MockCall) LastUserMessage() string {
+	for i := len(c.Messages) - 1; i >= 0; i-- {
+		if c.Messages[i].Role == "user" {
+			return c.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+// MockResponder computes a response from the request, for replies that
+// depend on what the agent sent.
+type MockResponder func(call MockCall) (*LLMResponse, error)
+
+// MockProvider is an LLMProvider that plays back a script, for testing the
+// agent loop and anything embedding it without a model. Each Chat call
+// takes the next step of the script: a text reply, a tool call, an error or
+// a responder function. All calls are recorded.
+//
+//	mock := providers.NewMockProvider().
+//		CallTool("write_file", map[string]interface{}{"path": "a.txt", "content": "hi"}).
+//		Reply("Done")
+type MockProvider struct {
+	mu       sync.Mutex
+	model    string
+	script   []MockResponder
+	fallback MockResponder
+	calls    []MockCall
+	seq      int
+}
+
+// NewMockProvider returns a provider that replies with responses in order.
+func NewMockProvider(responses ...*LLMResponse) *MockProvider {
+	m := &MockProvider{model: "mock-model"}
+	for _, r := range responses {
+		m.Respond(staticResponse(r, nil))
+	}
+	return m
+}
+
+// Reply appends a plain text response.
+func (m *MockProvider) Reply(content string) *MockProvider {
+	return m.Respond(staticResponse(&LLMResponse{Content: content, FinishReason: "stop"}, nil))
+}
+
+// CallTool appends a response that calls one tool.
+func (m *MockProvider) CallTool(name string, args map[string]interface{}) *MockProvider {
+	return m.CallTools(MockToolCall{Name: name, Args: args})
+}
+
+// MockToolCall is a tool call in a scripted response.
+type MockToolCall struct {
+	Name string
+	Args map[string]interface{}
+}
+
+// CallTools appends a response that calls several tools at once.
+func (m *MockProvider) CallTools(calls ...MockToolCall) *MockProvider {
+	m.mu.Lock()
+	resp := &LLMResponse{FinishReason: "tool_calls"}
+	for _, c := range calls {
+		m.seq++
+		args, _ := json.Marshal(c.Args)
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_%d", m.seq),
+			Type:      "function",
+			Function:  &FunctionCall{Name: c.Name, Arguments: string(args)},
+			Name:      c.Name,
+			Arguments: c.Args,
+		})
+	}
+	m.mu.Unlock()
+	return m.Respond(staticResponse(resp, nil))
+}
+
+// Fail appends a step that returns err.
+func (m *MockProvider) Fail(err error) *MockProvider {
+	return m.Respond(staticResponse(nil, err))
+}
+
+// Respond appends a step computed by fn.
+func (m *MockProvider) Respond(fn MockResponder) *MockProvider {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.script = append(m.script, fn)
+	return m
+}
+
+// SetDefault answers calls made after the script ran out, e.g. background
+// summarization, with content instead of ErrScriptExhausted.
+func (m *MockProvider) SetDefault(content string) *MockProvider {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback = staticResponse(&LLMResponse{Content: content, FinishReason: "stop"}, nil)
+	return m
+}
+
+// SetModel sets the model reported by GetDefaultModel.
+func (m *MockProvider) SetModel(model string) *MockProvider {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.model = model
+	return m
+}
+
+// Calls returns the requests received so far.
+func (m *MockProvider) Calls() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockCall(nil), m.calls...)
+}
+
+// Remaining returns how many scripted steps have not been used.
+func (m *MockProvider) Remaining() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.script)
+}
+
+func (m *MockProvider) Chat(ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]interface{}) (*LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	call := MockCall{Messages: append([]Message(nil), messages...), Model: model, Options: options}
+	for _, t := range tools {
+		call.Tools = append(call.Tools, t.Function.Name)
+	}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	next := m.fallback
+	if len(m.script) > 0 {
+		next, m.script = m.script[0], m.script[1:]
+	}
+	m.mu.Unlock()
+
+	if next == nil {
+		return nil, ErrScriptExhausted
+	}
+	resp, err := next(call)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report usage like a real provider so usage tracking has numbers
+	if resp.Usage == nil {
+		prompt := EstimatePromptTokens(messages)
+		completion := EstimateTokens(resp.Content)
+		resp.Usage = &UsageInfo{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion, Estimated: true}
+	}
+	if onUsage, ok := options["on_usage"].(UsageCallback); ok {
+		onUsage(*resp.Usage)
+	}
+	return resp, nil
+}
+
+func (m *MockProvider) GetDefaultModel() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.model
+}
+
+// staticResponse returns a responder that hands out a copy of resp, so a
+// response reused by the caller is not shared between calls.
+func staticResponse(resp *LLMResponse, err error) MockResponder {
+	return func(MockCall) (*LLMResponse, error) {
+		if err != nil {
+			return nil, err
+		}
+		cp := *resp
+		cp.ToolCalls = append([]ToolCall(nil), resp.ToolCalls...)
+		return &cp, nil
+	}
+}
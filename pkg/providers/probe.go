@@ -0,0 +1,69 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// ListModels returns the model IDs served by an OpenAI-compatible endpoint
+// (GET /models). It doubles as an API key check for providers that require
+// authentication to list models.
+func ListModels(ctx context.Context, apiKey, apiBase string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(apiBase, "/")+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{Timeout: 20 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %w", err)
+	}
+	models := make([]string, 0, len(list.Data))
+	for _, m := range list.Data {
+		if m.ID != "" {
+			models = append(models, m.ID)
+		}
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// CheckModel sends a one-word prompt to model using the provider routing
+// of cfg, confirming the key, endpoint and model name work together.
+func CheckModel(ctx context.Context, cfg *config.Config, model string) error {
+	provider, err := CreateProviderForModel(cfg, model)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	_, err = provider.Chat(ctx, []Message{{Role: "user", Content: "Reply with OK."}}, nil, model, map[string]interface{}{"max_tokens": 16})
+	return err
+}
@@ -2,8 +2,11 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"strings"
 )
 
 // RateLimitError represents a 429 rate limit error from an API.
@@ -22,6 +25,80 @@ func IsRateLimitError(err error) bool {
 	return errors.As(err, &rle)
 }
 
+// APIError is a failed response other than a 429.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
+}
+
+// Classes of failed calls, each with its own fallback chain.
+const (
+	ErrorRateLimit     = "rate_limit"
+	ErrorTimeout       = "timeout"
+	ErrorContentFilter = "content_filter"
+)
+
+// contentFilterMarkers appear in the error bodies providers send when a
+// request is refused by a safety filter.
+var contentFilterMarkers = []string{"content_filter", "content management policy", "responsible ai", "safety settings", "prompt was blocked"}
+
+// ClassifyError returns the class of a failed call, or "" when another
+// model would not help, e.g. a bad request or a missing API key.
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+	if IsRateLimitError(err) {
+		return ErrorRateLimit
+	}
+	var netErr net.Error
+	if errors.Is(err, ErrStreamStalled) || errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorTimeout
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+	switch apiErr.StatusCode {
+	case 503, 529: // overloaded
+		return ErrorRateLimit
+	case 408, 504, 524:
+		return ErrorTimeout
+	}
+	body := strings.ToLower(apiErr.Body)
+	for _, marker := range contentFilterMarkers {
+		if strings.Contains(body, marker) {
+			return ErrorContentFilter
+		}
+	}
+	return ""
+}
+
+// contextOverflowMarkers appear in the error bodies providers send when the
+// request doesn't fit the model's context window.
+var contextOverflowMarkers = []string{"context_length_exceeded", "maximum context length", "context length", "context window",
+	"prompt is too long", "input is too long", "too many tokens", "exceeds the limit of", "request too large"}
+
+// IsContextOverflow reports whether err says the request was longer than the
+// model's context window.
+func IsContextOverflow(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || (apiErr.StatusCode != 400 && apiErr.StatusCode != 413) {
+		return false
+	}
+	body := strings.ToLower(apiErr.Body)
+	for _, marker := range contextOverflowMarkers {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 type ToolCall struct {
 	ID           string                 `json:"id"`
 	Type         string                 `json:"type,omitempty"`
@@ -42,12 +119,37 @@ type LLMResponse struct {
 	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
 	FinishReason string     `json:"finish_reason"`
 	Usage        *UsageInfo `json:"usage,omitempty"`
+	Truncated    bool       `json:"truncated,omitempty"` // the stream broke off; Content is what arrived
 }
 
 type UsageInfo struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens     int  `json:"prompt_tokens"`
+	CompletionTokens int  `json:"completion_tokens"`
+	TotalTokens      int  `json:"total_tokens"`
+	Estimated        bool `json:"estimated,omitempty"` // true when counts are a chars/4 heuristic
+}
+
+// UsageCallback receives running token counts while a response is streaming.
+// Pass it to Chat via options["on_usage"]. The last call carries the final usage.
+type UsageCallback func(usage UsageInfo)
+
+// EstimateTokens returns a rough token count for text (4 chars per token).
+func EstimateTokens(text string) int {
+	return len(text) / 4
+}
+
+// EstimatePromptTokens returns a rough token count for a message list.
+func EstimatePromptTokens(messages []Message) int {
+	total := 0
+	for _, m := range messages {
+		total += EstimateTokens(m.Content)
+		for _, tc := range m.ToolCalls {
+			if tc.Function != nil {
+				total += EstimateTokens(tc.Function.Arguments)
+			}
+		}
+	}
+	return total
 }
 
 type Message struct {
@@ -55,6 +157,32 @@ type Message struct {
 	Content    string     `json:"content"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
+	// Parts, when set, is sent as multimodal content instead of Content.
+	// It is never persisted.
+	Parts []ContentPart `json:"-"`
+}
+
+// ContentPart is one element of a multimodal message (OpenAI format).
+type ContentPart struct {
+	Type     string    `json:"type"` // "text" or "image_url"
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+type ImageURL struct {
+	URL string `json:"url"` // http(s) URL or data URI
+}
+
+// MarshalJSON sends Parts as the content array when present.
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plain Message
+	if len(m.Parts) == 0 {
+		return json.Marshal(plain(m))
+	}
+	return json.Marshal(struct {
+		plain
+		Content []ContentPart `json:"content"`
+	}{plain(m), m.Parts})
 }
 
 type LLMProvider interface {
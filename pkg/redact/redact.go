@@ -0,0 +1,109 @@
+// Package redact scrubs credentials from text before it is logged, shown to
+// the model or saved in a session: the configured keys and tokens, common
+// key formats (OpenAI, GitHub, AWS, Google, Slack, Telegram, private key
+// blocks), secret-looking assignments as found in .env and YAML files, and
+// any extra patterns from the config.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Mask replaces every redacted value.
+const Mask = "[REDACTED]"
+
+// builtin matches credentials by their shape.
+var builtin = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\bsk-(?:ant-|proj-|or-)?[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`\b\d{8,10}:AA[A-Za-z0-9_-]{33}\b`),
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`),
+}
+
+// assignment matches "NAME=value" and "name: value" lines, optionally
+// quoted or exported; whether NAME looks secret is decided in code.
+var assignment = regexp.MustCompile(`(?m)^(\s*(?:export\s+)?["']?)([A-Za-z_][A-Za-z0-9_.-]*)(["']?\s*[=:]\s*["']?)([^\s"',#]{8,})`)
+
+// secretWords are name segments that mark an assignment as a credential,
+// so API_KEY and db.password match but MAX_TOKENS does not.
+var secretWords = map[string]bool{
+	"SECRET": true, "TOKEN": true, "PASSWORD": true, "PASSWD": true, "PWD": true,
+	"KEY": true, "APIKEY": true, "CREDENTIAL": true, "CREDENTIALS": true, "PASSPHRASE": true,
+}
+
+// Redactor replaces known secret values and secret-looking text with Mask.
+type Redactor struct {
+	values   []string
+	patterns []*regexp.Regexp
+}
+
+// New creates a Redactor for the given secret values and extra regular
+// expressions. Values shorter than 6 characters are ignored, since masking
+// them would mangle ordinary text. A pattern that does not compile is
+// reported and skipped; the returned Redactor is usable either way.
+func New(values, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if len(v) >= 6 && !seen[v] {
+			seen[v] = true
+			r.values = append(r.values, v)
+		}
+	}
+	// Longest first so a secret containing another is fully replaced
+	sort.Slice(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+
+	var bad []string
+	r.patterns = append(r.patterns, builtin...)
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			bad = append(bad, fmt.Sprintf("%q: %v", p, err))
+			continue
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	if len(bad) > 0 {
+		return r, fmt.Errorf("invalid redaction pattern %s", strings.Join(bad, "; "))
+	}
+	return r, nil
+}
+
+// Redact returns s with secrets replaced by Mask. A nil Redactor returns s.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, Mask)
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, Mask)
+	}
+	return assignment.ReplaceAllStringFunc(s, func(line string) string {
+		m := assignment.FindStringSubmatch(line)
+		if !secretName(m[2]) || m[4] == Mask {
+			return line
+		}
+		return m[1] + m[2] + m[3] + Mask
+	})
+}
+
+func secretName(name string) bool {
+	for _, word := range strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return r == '_' || r == '.' || r == '-'
+	}) {
+		if secretWords[word] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,292 @@
+package reminders
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+// JobKind is the cron payload kind of reminder jobs.
+const JobKind = "reminder"
+
+// DefaultSnooze is how long a reminder is snoozed when no time is given.
+const DefaultSnooze = 10 * time.Minute
+
+// Service sets, delivers, snoozes and cancels reminders. Until a cron
+// service is attached, reminders are stored but not scheduled; Attach
+// schedules them and delivers any that came due in the meantime.
+type Service struct {
+	store *Store
+	bus   *bus.MessageBus
+	mu    sync.Mutex // serializes changes to a reminder and its job
+	cron  *cron.CronService
+}
+
+// NewService creates a reminder service that delivers to mb.
+func NewService(store *Store, mb *bus.MessageBus) *Service {
+	return &Service{store: store, bus: mb}
+}
+
+// Attach registers the reminder job handler with cs, delivers reminders
+// that came due while mclaw was not running and schedules the rest.
+func (s *Service) Attach(cs *cron.CronService) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cron = cs
+	cs.SetKindHandler(JobKind, s.fire)
+
+	jobs := make(map[string]bool)
+	for _, job := range cs.ListJobs(true) {
+		if job.Payload.Kind == JobKind && job.State.NextRunAtMS != nil {
+			jobs[job.ID] = true
+		}
+	}
+
+	pending, err := s.store.Pending()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	keep := make(map[string]bool)
+	for _, r := range pending {
+		switch {
+		case !r.Due.After(now):
+			s.deliver(r, now)
+		case !jobs[r.JobID]:
+			s.schedule(r)
+		}
+		if r.Status == StatusPending {
+			keep[r.JobID] = true
+		}
+		if err := s.store.Save(r); err != nil {
+			log.Printf("[reminders] Failed to save reminder #%d: %v", r.ID, err)
+		}
+	}
+
+	// Drop jobs whose reminder was delivered, cancelled or rescheduled
+	for _, job := range cs.ListJobs(true) {
+		if job.Payload.Kind == JobKind && !keep[job.ID] {
+			cs.RemoveJob(job.ID)
+		}
+	}
+	return nil
+}
+
+// Add sets a reminder for owner's chat.
+func (s *Service) Add(owner, text string, due time.Time, repeat string) (*Reminder, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("reminder text is empty")
+	}
+	if _, _, ok := strings.Cut(owner, ":"); !ok {
+		return nil, fmt.Errorf("reminders need a chat to deliver to")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &Reminder{Owner: owner, Text: text, Due: due, Repeat: repeat}
+	if err := s.store.Add(r); err != nil {
+		return nil, err
+	}
+	s.schedule(r)
+	return r, s.store.Save(r)
+}
+
+// List returns owner's pending reminders, and past ones if all is set.
+func (s *Service) List(owner string, all bool) ([]*Reminder, error) {
+	return s.store.List(owner, all)
+}
+
+// Snooze moves a pending or delivered reminder to until. For a recurring
+// reminder only the current occurrence moves; later ones keep their time.
+func (s *Service) Snooze(owner string, id int64, until time.Time) (*Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.store.Get(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status == StatusCancelled {
+		return nil, fmt.Errorf("reminder #%d was cancelled", id)
+	}
+	if !until.After(time.Now()) {
+		return nil, fmt.Errorf("snooze time is in the past")
+	}
+
+	r.Due = until
+	if r.Repeat == "" {
+		r.Anchor = until
+	}
+	r.Status = StatusPending
+	r.Snoozes++
+	s.schedule(r)
+	return r, s.store.Save(r)
+}
+
+// Cancel stops a reminder, including all future occurrences.
+func (s *Service) Cancel(owner string, id int64) (*Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.store.Get(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status == StatusCancelled {
+		return r, nil
+	}
+	s.unschedule(r)
+	r.Status = StatusCancelled
+	return r, s.store.Save(r)
+}
+
+// fire is the cron handler for reminder jobs.
+func (s *Service) fire(job *cron.CronJob) (string, error) {
+	id, err := strconv.ParseInt(job.Payload.Message, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("bad reminder job: %q", job.Payload.Message)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.store.Get(job.Payload.Channel+":"+job.Payload.To, id)
+	if err != nil {
+		return "", err
+	}
+	if r.Status != StatusPending || r.JobID != job.ID {
+		return fmt.Sprintf("reminder #%d skipped (%s)", id, r.Status), nil // cancelled or snoozed since
+	}
+
+	r.JobID = "" // this job removes itself after running
+	s.deliver(r, time.Now())
+	return fmt.Sprintf("reminder #%d delivered", id), s.store.Save(r)
+}
+
+// deliver sends a due reminder and moves it to its next occurrence or
+// marks it fired. Caller must hold s.mu and save r.
+func (s *Service) deliver(r *Reminder, now time.Time) {
+	late := now.Sub(r.Due) > 5*time.Minute
+	r.FiredAt = now
+	if next, ok := Next(r.Anchor, r.Repeat, now); ok {
+		r.Anchor, r.Due = next, next
+		s.schedule(r)
+	} else {
+		r.Status = StatusFired
+	}
+
+	channel, chatID, _ := strings.Cut(r.Owner, ":")
+	s.bus.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: deliveryText(r, now, late)})
+	log.Printf("[reminders] Delivered reminder #%d to %s", r.ID, r.Owner)
+}
+
+func deliveryText(r *Reminder, now time.Time, late bool) string {
+	var sb strings.Builder
+	sb.WriteString("⏰ **Reminder:** " + r.Text)
+	if late {
+		sb.WriteString("\n_Delivered late; mclaw was not running when it was due._")
+	}
+	if r.Status == StatusPending {
+		fmt.Fprintf(&sb, "\n🔁 Next: %s", FormatTime(r.Due, now))
+	}
+	if strings.HasPrefix(r.Owner, "telegram:") {
+		fmt.Fprintf(&sb, "\n_Snooze: /reminders snooze %d 10m_", r.ID)
+	} else {
+		fmt.Fprintf(&sb, "\n_Reply \"snooze #%d 10m\" to snooze._", r.ID)
+	}
+	return sb.String()
+}
+
+// schedule (re)creates the cron job of a pending reminder. Caller must
+// hold s.mu.
+func (s *Service) schedule(r *Reminder) {
+	if s.cron == nil {
+		return // scheduled by Attach
+	}
+	s.unschedule(r)
+	channel, chatID, _ := strings.Cut(r.Owner, ":")
+	job, err := s.cron.AddOneShot(fmt.Sprintf("reminder #%d", r.ID), r.Due, cron.CronPayload{
+		Kind:    JobKind,
+		Message: strconv.FormatInt(r.ID, 10),
+		Channel: channel,
+		To:      chatID,
+	})
+	if err != nil {
+		log.Printf("[reminders] Failed to schedule reminder #%d: %v", r.ID, err)
+		return
+	}
+	r.JobID = job.ID
+}
+
+func (s *Service) unschedule(r *Reminder) {
+	if s.cron != nil && r.JobID != "" {
+		s.cron.RemoveJob(r.JobID)
+	}
+	r.JobID = ""
+}
+
+// Describe renders a reminder for listings, e.g.
+// "#3 Call mom — tomorrow 09:00 (in 15h) · daily".
+func (r *Reminder) Describe(now time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "#%d %s — ", r.ID, r.Text)
+	switch r.Status {
+	case StatusPending:
+		fmt.Fprintf(&sb, "%s (in %s)", FormatTime(r.Due, now), formatIn(r.Due.Sub(now)))
+	case StatusFired:
+		sb.WriteString("delivered " + FormatTime(r.FiredAt, now))
+	default:
+		sb.WriteString(r.Status)
+	}
+	if r.Repeat != "" {
+		sb.WriteString(" · " + r.Repeat)
+	}
+	if r.Snoozes > 0 {
+		fmt.Fprintf(&sb, " · snoozed %d×", r.Snoozes)
+	}
+	return sb.String()
+}
+
+// FormatTime renders t relative to now's day: "today 15:04",
+// "tomorrow 09:00", "Mon 09:00", "Mar 1 09:00" or "Mar 1 2027 09:00".
+func FormatTime(t, now time.Time) string {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()) }
+	days := int(day(t).Sub(day(now)).Round(24*time.Hour) / (24 * time.Hour))
+	switch {
+	case days == 0:
+		return "today " + t.Format("15:04")
+	case days == 1:
+		return "tomorrow " + t.Format("15:04")
+	case days == -1:
+		return "yesterday " + t.Format("15:04")
+	case days > 1 && days < 7:
+		return t.Format("Mon 15:04")
+	case t.Year() == now.Year():
+		return t.Format("Jan 2 15:04")
+	default:
+		return t.Format("Jan 2 2006 15:04")
+	}
+}
+
+// formatIn renders a wait coarsely, e.g. "15h" or "3d".
+func formatIn(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return "<1m"
+	}
+}
@@ -0,0 +1,143 @@
+package reminders
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+func newTestService(t *testing.T) (*Service, *cron.CronService, *bus.MessageBus) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "memory.db"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	mb := bus.NewMessageBus()
+	return NewService(store, mb), cron.NewCronService(filepath.Join(dir, "jobs.json"), nil), mb
+}
+
+func nextOutbound(t *testing.T, mb *bus.MessageBus) bus.OutboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, ok := mb.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("expected a reminder to be delivered")
+	}
+	return msg
+}
+
+func jobOf(cs *cron.CronService, id string) *cron.CronJob {
+	for _, job := range cs.ListJobs(true) {
+		if job.ID == id {
+			return &job
+		}
+	}
+	return nil
+}
+
+func TestReminderLifecycle(t *testing.T) {
+	svc, cs, mb := newTestService(t)
+	if err := svc.Attach(cs); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := svc.Add("telegram:42", "Call mom", time.Now().Add(time.Hour), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := jobOf(cs, r.JobID)
+	if job == nil || job.Payload.Kind != JobKind || !job.DeleteAfterRun {
+		t.Fatalf("expected a one-shot reminder job, got %+v", job)
+	}
+
+	// Snoozing replaces the job
+	r, err = svc.Snooze("telegram:42", r.ID, time.Now().Add(2*time.Hour))
+	if err != nil || r.Snoozes != 1 || jobOf(cs, job.ID) != nil || jobOf(cs, r.JobID) == nil {
+		t.Fatalf("Snooze = %+v, %v; expected a new job", r, err)
+	}
+	if _, err := svc.Snooze("telegram:7", r.ID, time.Now().Add(time.Hour)); err == nil {
+		t.Error("expected another chat's reminder to be off limits")
+	}
+
+	// Firing delivers and retires a one-shot reminder
+	fired := *jobOf(cs, r.JobID)
+	if _, err := svc.fire(&fired); err != nil {
+		t.Fatal(err)
+	}
+	msg := nextOutbound(t, mb)
+	if msg.Channel != "telegram" || msg.ChatID != "42" || !strings.Contains(msg.Content, "Call mom") {
+		t.Errorf("unexpected delivery: %+v", msg)
+	}
+	list, _ := svc.List("telegram:42", false)
+	if len(list) != 0 {
+		t.Errorf("expected no pending reminders after delivery, got %d", len(list))
+	}
+
+	// A stale job for a reminder that has moved on does nothing
+	if out, _ := svc.fire(&fired); !strings.Contains(out, "skipped") {
+		t.Errorf("expected the stale job to be skipped, got %q", out)
+	}
+
+	if _, err := svc.Cancel("telegram:42", r.ID); err != nil {
+		t.Fatal(err)
+	}
+	all, _ := svc.List("telegram:42", true)
+	if len(all) != 1 || all[0].Status != StatusCancelled {
+		t.Errorf("expected the reminder to be cancelled, got %+v", all)
+	}
+}
+
+func TestRecurringReminder(t *testing.T) {
+	svc, cs, mb := newTestService(t)
+	svc.Attach(cs)
+
+	due := time.Now().Add(time.Minute)
+	r, err := svc.Add("discord:9", "Stand up", due, RepeatDaily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Snoozing moves only this occurrence
+	r, _ = svc.Snooze("discord:9", r.ID, due.Add(10*time.Minute))
+
+	svc.fire(jobOf(cs, r.JobID))
+	if msg := nextOutbound(t, mb); !strings.Contains(msg.Content, "Next:") {
+		t.Errorf("expected the next occurrence in the delivery, got %q", msg.Content)
+	}
+	list, _ := svc.List("discord:9", false)
+	if len(list) != 1 || !list[0].Due.Equal(due.AddDate(0, 0, 1).Truncate(time.Second)) || jobOf(cs, list[0].JobID) == nil {
+		t.Errorf("expected the reminder rescheduled for tomorrow at the original time, got %+v", list)
+	}
+}
+
+func TestAttachCatchesUp(t *testing.T) {
+	svc, cs, mb := newTestService(t)
+
+	// Reminders added before a cron service is attached are kept
+	overdue, _ := svc.Add("telegram:1", "Missed while down", time.Now().Add(-time.Hour), "")
+	later, _ := svc.Add("telegram:1", "Later", time.Now().Add(time.Hour), "")
+	if overdue.JobID != "" || later.JobID != "" {
+		t.Fatal("expected no jobs before Attach")
+	}
+
+	if err := svc.Attach(cs); err != nil {
+		t.Fatal(err)
+	}
+	if msg := nextOutbound(t, mb); !strings.Contains(msg.Content, "Missed while down") || !strings.Contains(msg.Content, "late") {
+		t.Errorf("expected the overdue reminder delivered late, got %q", msg.Content)
+	}
+	list, _ := svc.List("telegram:1", false)
+	if len(list) != 1 || list[0].Text != "Later" || jobOf(cs, list[0].JobID) == nil {
+		t.Errorf("expected the future reminder scheduled, got %+v", list)
+	}
+	if n := len(cs.ListJobs(true)); n != 1 {
+		t.Errorf("expected 1 reminder job, got %d", n)
+	}
+}
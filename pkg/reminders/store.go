@@ -0,0 +1,203 @@
+// Package reminders keeps one-shot and recurring reminders per conversation.
+// Each pending reminder is backed by a one-shot cron job; the reminder
+// itself, with its repeat rule and snoozes, lives in SQLite so it can be
+// listed, snoozed and cancelled on its own terms.
+package reminders
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Reminder states.
+const (
+	StatusPending   = "pending"   // waiting to be delivered
+	StatusFired     = "fired"     // one-shot reminder that was delivered
+	StatusCancelled = "cancelled" // cancelled by the user
+)
+
+// Reminder is one reminder. Owner is the session key of the conversation
+// it was set in ("channel:chat_id"), which is also where it is delivered.
+type Reminder struct {
+	ID        int64
+	Owner     string
+	Text      string
+	Due       time.Time // next delivery, including any snooze
+	Anchor    time.Time // the scheduled occurrence Due was snoozed from
+	Repeat    string    // "" for one-shot reminders, see ParseRepeat
+	Status    string
+	Snoozes   int
+	JobID     string // cron job that delivers it while pending
+	CreatedAt time.Time
+	FiredAt   time.Time // last delivery
+}
+
+// Store persists reminders in SQLite.
+type Store struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// NewStore creates or opens the reminders table in the database at dbPath.
+func NewStore(dbPath string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create reminders directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reminders database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate reminders database: %w", err)
+	}
+
+	log.Printf("[reminders] Store initialized at %s", dbPath)
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS reminders (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner      TEXT NOT NULL,
+		text       TEXT NOT NULL,
+		due_at     INTEGER NOT NULL,
+		anchor_at  INTEGER NOT NULL,
+		repeat     TEXT NOT NULL DEFAULT '',
+		status     TEXT NOT NULL DEFAULT 'pending',
+		snoozes    INTEGER NOT NULL DEFAULT 0,
+		job_id     TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		fired_at   INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_reminders_owner ON reminders(owner, status);
+	CREATE INDEX IF NOT EXISTS idx_reminders_due ON reminders(status, due_at);
+	`)
+	return err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add inserts a pending reminder and sets its ID.
+func (s *Store) Add(r *Reminder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Status == "" {
+		r.Status = StatusPending
+	}
+	if r.Anchor.IsZero() {
+		r.Anchor = r.Due
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+
+	res, err := s.db.Exec(`INSERT INTO reminders (owner, text, due_at, anchor_at, repeat, status, job_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Owner, r.Text, r.Due.Unix(), r.Anchor.Unix(), r.Repeat, r.Status, r.JobID, r.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to add reminder: %w", err)
+	}
+	r.ID, err = res.LastInsertId()
+	return err
+}
+
+// Get returns one of owner's reminders.
+func (s *Store) Get(owner string, id int64) (*Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, err := s.query(`WHERE owner = ? AND id = ?`, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("reminder #%d not found", id)
+	}
+	return list[0], nil
+}
+
+// List returns owner's pending reminders by due time, followed by
+// delivered and cancelled ones (newest first) if all is set.
+func (s *Store) List(owner string, all bool) ([]*Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !all {
+		return s.query(`WHERE owner = ? AND status = 'pending' ORDER BY due_at, id`, owner)
+	}
+	return s.query(`WHERE owner = ? ORDER BY status != 'pending', CASE WHEN status = 'pending' THEN due_at ELSE -due_at END, id`, owner)
+}
+
+// Pending returns the pending reminders of all owners.
+func (s *Store) Pending() ([]*Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.query(`WHERE status = 'pending' ORDER BY due_at`)
+}
+
+// Save writes a reminder's schedule and state.
+func (s *Store) Save(r *Reminder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`UPDATE reminders SET text = ?, due_at = ?, anchor_at = ?, repeat = ?, status = ?, snoozes = ?, job_id = ?, fired_at = ? WHERE owner = ? AND id = ?`,
+		r.Text, r.Due.Unix(), r.Anchor.Unix(), r.Repeat, r.Status, r.Snoozes, r.JobID, unix(r.FiredAt), r.Owner, r.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save reminder: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("reminder #%d not found", r.ID)
+	}
+	return nil
+}
+
+// query runs a SELECT over reminders with the given clause. Caller must hold s.mu.
+func (s *Store) query(clause string, args ...interface{}) ([]*Reminder, error) {
+	rows, err := s.db.Query(`SELECT id, owner, text, due_at, anchor_at, repeat, status, snoozes, job_id, created_at, fired_at FROM reminders `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Reminder
+	for rows.Next() {
+		var r Reminder
+		var due, anchor, created, fired int64
+		if err := rows.Scan(&r.ID, &r.Owner, &r.Text, &due, &anchor, &r.Repeat, &r.Status, &r.Snoozes, &r.JobID, &created, &fired); err != nil {
+			return nil, err
+		}
+		r.Due = time.Unix(due, 0)
+		r.Anchor = time.Unix(anchor, 0)
+		r.CreatedAt = time.Unix(created, 0)
+		if fired != 0 {
+			r.FiredAt = time.Unix(fired, 0)
+		}
+		list = append(list, &r)
+	}
+	return list, rows.Err()
+}
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,396 @@
+package reminders
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Repeat rules. Interval rules are written "every <span>", e.g. "every 2h".
+const (
+	RepeatDaily    = "daily"
+	RepeatWeekdays = "weekdays"
+	RepeatWeekly   = "weekly"
+	RepeatMonthly  = "monthly"
+)
+
+// minInterval is the shortest "every <span>" repeat allowed.
+const minInterval = 5 * time.Minute
+
+// defaultClock is the time of day used when only a day is given.
+var defaultClock = [2]int{9, 0}
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// ParseSchedule parses when a reminder is due, e.g. "in 20 minutes",
+// "tomorrow 9am", "friday at 18:30", "2026-03-01 08:00", or a recurring
+// rule such as "every day at 8", "every weekday 9:30", "every monday" or
+// "every 2 hours". It returns the first delivery time and the repeat rule
+// ("" for one-shot reminders).
+func ParseSchedule(s string, now time.Time) (time.Time, string, error) {
+	s = normalize(s)
+	rest, recurring := cutAny(s, "every ", "each ")
+	if !recurring {
+		due, err := ParseWhen(s, now)
+		return due, "", err
+	}
+
+	// The longest leading phrase that is a repeat rule; the rest is the time
+	words := strings.Fields(rest)
+	for n := len(words); n > 0; n-- {
+		repeat, err := ParseRepeat(strings.Join(words[:n], " "))
+		if err != nil {
+			continue
+		}
+		clock := strings.Join(words[n:], " ")
+		due, err := firstRun(repeat, words[0], clock, now)
+		return due, repeat, err
+	}
+	return time.Time{}, "", fmt.Errorf("don't understand the repeat in %q (try \"every day at 9am\" or \"every 2 hours\")", s)
+}
+
+// firstRun returns the first occurrence of a repeat rule. head is the
+// rule's first word, which names the weekday of weekly rules.
+func firstRun(repeat, head, clock string, now time.Time) (time.Time, error) {
+	if span, ok := strings.CutPrefix(repeat, "every "); ok {
+		if clock != "" {
+			return time.Time{}, fmt.Errorf("can't combine %q with a time of day", repeat)
+		}
+		d, _ := parseSpan(span)
+		return now.Add(d), nil
+	}
+
+	switch repeat {
+	case RepeatWeekly:
+		if _, ok := weekdayNames[head]; ok {
+			return parseDayTime(strings.TrimSpace(head+" "+clock), now)
+		}
+		if clock == "" {
+			return now.AddDate(0, 0, 7), nil
+		}
+	case RepeatMonthly:
+		if clock == "" {
+			return now.AddDate(0, 1, 0), nil
+		}
+	}
+
+	due, err := parseDayTime(clock, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if repeat == RepeatWeekdays {
+		for isWeekend(due) {
+			due = due.AddDate(0, 0, 1)
+		}
+	}
+	return due, nil
+}
+
+// ParseRepeat normalizes a repeat rule: "daily", "every weekday",
+// "weekly", "every friday" (weekly), "monthly", "hourly" or an interval
+// such as "every 30 minutes".
+func ParseRepeat(s string) (string, error) {
+	s = normalize(s)
+	s, _ = cutAny(s, "every ", "each ")
+	switch s {
+	case "day", "daily":
+		return RepeatDaily, nil
+	case "weekday", "weekdays", "workday", "workdays":
+		return RepeatWeekdays, nil
+	case "week", "weekly":
+		return RepeatWeekly, nil
+	case "month", "monthly":
+		return RepeatMonthly, nil
+	case "hour", "hourly":
+		return "every 1h", nil
+	}
+	if _, ok := weekdayNames[s]; ok {
+		return RepeatWeekly, nil
+	}
+
+	d, err := parseSpan(s)
+	if err != nil {
+		return "", fmt.Errorf("unknown repeat %q (use daily, weekdays, weekly, monthly or an interval like \"every 2 hours\")", s)
+	}
+	if d < minInterval {
+		return "", fmt.Errorf("repeat interval must be at least %s", formatSpan(minInterval))
+	}
+	return "every " + formatSpan(d), nil
+}
+
+// Next returns the first occurrence of repeat after now, stepping from the
+// occurrence at. It returns false for one-shot reminders.
+func Next(at time.Time, repeat string, now time.Time) (time.Time, bool) {
+	var step func(t time.Time) time.Time
+	switch repeat {
+	case "":
+		return time.Time{}, false
+	case RepeatDaily:
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case RepeatWeekdays:
+		step = func(t time.Time) time.Time {
+			t = t.AddDate(0, 0, 1)
+			for isWeekend(t) {
+				t = t.AddDate(0, 0, 1)
+			}
+			return t
+		}
+	case RepeatWeekly:
+		step = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case RepeatMonthly:
+		step = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		d, err := parseSpan(strings.TrimPrefix(repeat, "every "))
+		if err != nil {
+			return time.Time{}, false
+		}
+		if days := int(d / (24 * time.Hour)); d%(24*time.Hour) == 0 {
+			// Whole days keep the time of day across DST changes
+			step = func(t time.Time) time.Time { return t.AddDate(0, 0, days) }
+		} else {
+			step = func(t time.Time) time.Time { return t.Add(d) }
+		}
+	}
+
+	next := step(at)
+	for !next.After(now) {
+		next = step(next)
+	}
+	return next, true
+}
+
+// ParseWhen parses a single point in time: a span ("in 2 hours", "90m"),
+// a day with an optional time ("tomorrow", "tonight", "monday 8am", "next
+// fri at 17:00"), a time of day ("15:30", "3pm"; tomorrow if already past
+// today), or a date ("2026-03-01", "2026-03-01 08:00", RFC 3339). Days
+// without a time default to 09:00.
+func ParseWhen(s string, now time.Time) (time.Time, error) {
+	s = normalize(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("missing time")
+	}
+
+	if span, ok := strings.CutPrefix(s, "in "); ok {
+		d, err := parseSpan(span)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("don't understand %q (try \"in 20 minutes\")", s)
+		}
+		return now.Add(d), nil
+	}
+	if d, err := parseSpan(s); err == nil {
+		return now.Add(d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return checkFuture(t, now)
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, now.Location()); err == nil {
+		return checkFuture(t, now)
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return checkFuture(t.Add(time.Duration(defaultClock[0])*time.Hour), now)
+	}
+
+	t, err := parseDayTime(s, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return checkFuture(t, now)
+}
+
+// parseDayTime parses "[day] [at] [time]". Without a day, a time that has
+// already passed today means tomorrow.
+func parseDayTime(s string, now time.Time) (time.Time, error) {
+	words := strings.Fields(s)
+	next := false
+	if len(words) > 0 && (words[0] == "next" || words[0] == "this" || words[0] == "on") {
+		next = words[0] == "next"
+		words = words[1:]
+	}
+
+	day := now
+	dayWord := ""
+	if len(words) > 0 {
+		switch w := words[0]; w {
+		case "today", "tonight", "tomorrow":
+			dayWord = w
+			if w == "tomorrow" {
+				day = now.AddDate(0, 0, 1)
+			}
+			words = words[1:]
+		default:
+			if wd, ok := weekdayNames[w]; ok {
+				dayWord = w
+				ahead := (int(wd) - int(now.Weekday()) + 7) % 7
+				if next && ahead == 0 {
+					ahead = 7
+				}
+				day = now.AddDate(0, 0, ahead)
+				words = words[1:]
+			}
+		}
+	}
+	if len(words) > 0 && words[0] == "at" {
+		words = words[1:]
+	}
+
+	clock := defaultClock
+	switch rest := strings.Join(words, " "); {
+	case rest != "":
+		hour, minute, ok := parseClock(rest)
+		if !ok {
+			return time.Time{}, fmt.Errorf("don't understand %q (try \"in 20 minutes\", \"tomorrow 9am\" or \"2026-03-01 08:00\")", s)
+		}
+		clock = [2]int{hour, minute}
+	case dayWord == "tonight":
+		clock = [2]int{20, 0}
+	case dayWord == "today":
+		return time.Time{}, fmt.Errorf("say what time today")
+	}
+
+	t := time.Date(day.Year(), day.Month(), day.Day(), clock[0], clock[1], 0, 0, now.Location())
+	if !t.After(now) {
+		if _, isWeekday := weekdayNames[dayWord]; isWeekday {
+			t = t.AddDate(0, 0, 7)
+		} else if dayWord == "" {
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+	return t, nil
+}
+
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?:[:.h](\d{2}))?\s*(am|pm)?$`)
+
+// parseClock parses "15:30", "15.30", "7", "7am", "7:30 pm", "noon",
+// "midnight", "morning", "afternoon" and "evening".
+func parseClock(s string) (hour, minute int, ok bool) {
+	switch s {
+	case "noon":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	case "morning":
+		return 9, 0, true
+	case "afternoon":
+		return 15, 0, true
+	case "evening":
+		return 19, 0, true
+	}
+	m := clockPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" && (hour < 1 || hour > 12) {
+		return 0, 0, false
+	}
+	switch m[3] {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+var spanPart = regexp.MustCompile(`^(\d+|an?|one|half an?)\s*(seconds|second|secs|sec|s|minutes|minute|mins|min|m|hours|hour|hrs|hr|h|days|day|d|weeks|week|w)`)
+
+var spanUnits = map[byte]time.Duration{
+	's': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour,
+}
+
+// ParseSpan parses a length of time such as "25 minutes", "1h30m" or
+// "half an hour".
+func ParseSpan(s string) (time.Duration, error) {
+	return parseSpan(normalize(s))
+}
+
+// parseSpan parses a duration such as "20 minutes", "1h30m", "an hour",
+// "half an hour" or "2 days and 3 hours".
+func parseSpan(s string) (time.Duration, error) {
+	rest := strings.TrimSpace(s)
+	var total time.Duration
+	for rest != "" {
+		m := spanPart.FindStringSubmatch(rest)
+		if m == nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		rest = rest[len(m[0]):]
+		if rest != "" && rest[0] >= 'a' && rest[0] <= 'z' {
+			return 0, fmt.Errorf("invalid duration %q", s) // e.g. "monday" read as "mon" + "day"
+		}
+
+		unit := spanUnits[m[2][0]]
+		switch {
+		case strings.HasPrefix(m[1], "half"):
+			total += unit / 2
+		case m[1] == "a" || m[1] == "an" || m[1] == "one":
+			total += unit
+		default:
+			n, _ := strconv.Atoi(m[1])
+			total += time.Duration(n) * unit
+		}
+		rest = strings.TrimSpace(rest)
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "and "), ", ")
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return total, nil
+}
+
+// formatSpan renders an interval compactly: "3d", "2h", "90m".
+func formatSpan(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
+
+func checkFuture(t, now time.Time) (time.Time, error) {
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("%s is in the past", t.Format("2006-01-02 15:04"))
+	}
+	return t, nil
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func cutAny(s string, prefixes ...string) (string, bool) {
+	for _, p := range prefixes {
+		if rest, ok := strings.CutPrefix(s, p); ok {
+			return rest, true
+		}
+	}
+	return s, false
+}
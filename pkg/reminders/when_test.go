@@ -0,0 +1,122 @@
+package reminders
+
+import (
+	"testing"
+	"time"
+)
+
+// now is Wednesday 2026-03-04 14:00 local time.
+var now = time.Date(2026, 3, 4, 14, 0, 0, 0, time.Local)
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 3, day, hour, minute, 0, 0, time.Local)
+}
+
+func TestParseWhen(t *testing.T) {
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"in 20 minutes", now.Add(20 * time.Minute)},
+		{"in an hour and 30 mins", now.Add(90 * time.Minute)},
+		{"in half an hour", now.Add(30 * time.Minute)},
+		{"2h", now.Add(2 * time.Hour)},
+		{"1h30m", now.Add(90 * time.Minute)},
+		{"tomorrow", at(5, 9, 0)},
+		{"tomorrow at 7:30pm", at(5, 19, 30)},
+		{"tonight", at(4, 20, 0)},
+		{"15:30", at(4, 15, 30)},
+		{"9am", at(5, 9, 0)}, // already past today
+		{"friday 18:00", at(6, 18, 0)},
+		{"wednesday", at(11, 9, 0)}, // today's 09:00 has passed
+		{"next wed 16:00", at(11, 16, 0)},
+		{"mon morning", at(9, 9, 0)},
+		{"2026-03-10", at(10, 9, 0)},
+		{"2026-03-10 08:15", at(10, 8, 15)},
+	}
+	for _, c := range cases {
+		got, err := ParseWhen(c.in, now)
+		if err != nil {
+			t.Errorf("ParseWhen(%q): %v", c.in, err)
+			continue
+		}
+		if !got.Equal(c.want) {
+			t.Errorf("ParseWhen(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"", "someday", "today", "2026-03-01", "25:00", "13pm", "in a while"} {
+		if _, err := ParseWhen(bad, now); err == nil {
+			t.Errorf("ParseWhen(%q) should fail", bad)
+		}
+	}
+
+	if d, err := ParseSpan("25 Minutes"); err != nil || d != 25*time.Minute {
+		t.Errorf("ParseSpan = %v, %v", d, err)
+	}
+	if _, err := ParseSpan("tomorrow"); err == nil {
+		t.Error("ParseSpan should only accept spans")
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	cases := []struct {
+		in     string
+		due    time.Time
+		repeat string
+	}{
+		{"in 10m", now.Add(10 * time.Minute), ""},
+		{"every day at 8", at(5, 8, 0), RepeatDaily},
+		{"every weekday 9:30", at(5, 9, 30), RepeatWeekdays},
+		{"every monday", at(9, 9, 0), RepeatWeekly},
+		{"every friday at 17:00", at(6, 17, 0), RepeatWeekly},
+		{"every month at 10am", at(5, 10, 0), RepeatMonthly},
+		{"every 2 hours", now.Add(2 * time.Hour), "every 2h"},
+		{"every 3 days", now.Add(72 * time.Hour), "every 3d"},
+	}
+	for _, c := range cases {
+		due, repeat, err := ParseSchedule(c.in, now)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", c.in, err)
+			continue
+		}
+		if !due.Equal(c.due) || repeat != c.repeat {
+			t.Errorf("ParseSchedule(%q) = %v, %q; want %v, %q", c.in, due, repeat, c.due, c.repeat)
+		}
+	}
+
+	if _, _, err := ParseSchedule("every 1 minute", now); err == nil {
+		t.Error("expected intervals under 5 minutes to be rejected")
+	}
+	if _, _, err := ParseSchedule("every 2 hours at 9", now); err == nil {
+		t.Error("expected an interval with a time of day to be rejected")
+	}
+}
+
+func TestNext(t *testing.T) {
+	friday := at(6, 9, 0)
+	cases := []struct {
+		repeat string
+		want   time.Time
+	}{
+		{RepeatDaily, at(7, 9, 0)},
+		{RepeatWeekdays, at(9, 9, 0)}, // skips the weekend
+		{RepeatWeekly, at(13, 9, 0)},
+		{RepeatMonthly, time.Date(2026, 4, 6, 9, 0, 0, 0, time.Local)},
+		{"every 2h", at(6, 11, 0)},
+	}
+	for _, c := range cases {
+		got, ok := Next(friday, c.repeat, friday)
+		if !ok || !got.Equal(c.want) {
+			t.Errorf("Next(%s) = %v, %t; want %v", c.repeat, got, ok, c.want)
+		}
+	}
+
+	// Missed occurrences are skipped, not replayed
+	if got, _ := Next(at(1, 9, 0), RepeatDaily, now); !got.Equal(at(5, 9, 0)) {
+		t.Errorf("expected the next daily run after now, got %v", got)
+	}
+	if _, ok := Next(friday, "", now); ok {
+		t.Error("expected no next run for a one-shot reminder")
+	}
+}
@@ -0,0 +1,198 @@
+package session
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SetArchiveDir sets where idle sessions are archived. Archived sessions are
+// restored transparently when their conversation resumes.
+func (sm *SessionManager) SetArchiveDir(dir string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.archiveDir = dir
+}
+
+func (sm *SessionManager) archivePath(key string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(key)
+	return filepath.Join(sm.archiveDir, name+".json.gz")
+}
+
+// ArchiveIdle moves sessions not updated within maxIdle to compressed
+// archives and removes them from the live store.
+func (sm *SessionManager) ArchiveIdle(maxIdle time.Duration) (int, error) {
+	if sm.archiveDir == "" {
+		return 0, fmt.Errorf("archive directory not configured")
+	}
+	if err := os.MkdirAll(sm.archiveDir, 0755); err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-maxIdle)
+
+	var keys []string
+	if sm.store != nil {
+		var err error
+		if keys, err = sm.store.IdleKeys(cutoff); err != nil {
+			return 0, err
+		}
+	} else {
+		sm.mu.RLock()
+		for key, session := range sm.sessions {
+			if session.Updated.Before(cutoff) {
+				keys = append(keys, key)
+			}
+		}
+		sm.mu.RUnlock()
+	}
+
+	archived := 0
+	for _, key := range keys {
+		if err := sm.archive(key, cutoff); err != nil {
+			return archived, fmt.Errorf("failed to archive %s: %w", key, err)
+		}
+		archived++
+	}
+
+	if archived > 0 {
+		log.Printf("[session] Archived %d idle sessions", archived)
+	}
+	return archived, nil
+}
+
+func (sm *SessionManager) archive(key string, cutoff time.Time) error {
+	sm.ensureLoaded(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[key]
+	if !ok || !session.Updated.Before(cutoff) {
+		return nil // gone or resumed meanwhile
+	}
+
+	if err := writeArchive(sm.archivePath(key), session); err != nil {
+		return err
+	}
+
+	if sm.store != nil {
+		if err := sm.store.Delete(key); err != nil {
+			return err
+		}
+	} else if sm.storage != "" {
+		os.Remove(filepath.Join(sm.storage, key+".json"))
+	}
+
+	delete(sm.sessions, key)
+	return nil
+}
+
+// ArchivedKeys lists the keys of archived sessions.
+func (sm *SessionManager) ArchivedKeys() []string {
+	if sm.archiveDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(sm.archiveDir)
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasSuffix(name, ".json.gz") {
+			keys = append(keys, strings.TrimSuffix(name, ".json.gz"))
+		}
+	}
+	return keys
+}
+
+// LoadArchived reads an archived session without restoring it.
+func (sm *SessionManager) LoadArchived(key string) (*Session, bool) {
+	if sm.archiveDir == "" {
+		return nil, false
+	}
+	session, err := readArchive(sm.archivePath(key))
+	if err != nil {
+		return nil, false
+	}
+	return session, true
+}
+
+// restoreArchived brings an archived session back into the live store.
+// Caller must hold sm.mu.
+func (sm *SessionManager) restoreArchived(key string) *Session {
+	if sm.archiveDir == "" {
+		return nil
+	}
+
+	path := sm.archivePath(key)
+	session, err := readArchive(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[session] Failed to read archive for %s: %v", key, err)
+		}
+		return nil
+	}
+
+	session.persisted = 0
+	if err := sm.persist(session); err != nil {
+		log.Printf("[session] Failed to restore %s: %v", key, err)
+		return nil
+	}
+	os.Remove(path)
+
+	sm.sessions[key] = session
+	log.Printf("[session] Restored archived session %s", key)
+	return session
+}
+
+func writeArchive(path string, session *Session) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(f)
+	if err := json.NewEncoder(gz).Encode(session); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func readArchive(path string) (*Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var session Session
+	if err := json.NewDecoder(gz).Decode(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,145 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// Only the most recent checkpoints are kept per session.
+const maxCheckpoints = 10
+
+// Checkpoint is a snapshot of a session's conversational state that can be
+// restored to rewind the conversation.
+type Checkpoint struct {
+	ID         int                 `json:"id"`
+	Label      string              `json:"label,omitempty"`
+	Messages   []providers.Message `json:"messages"`
+	Summary    string              `json:"summary,omitempty"`
+	Scratchpad string              `json:"scratchpad,omitempty"`
+	Created    time.Time           `json:"created"`
+}
+
+// SaveCheckpoint snapshots the session's history, summary and scratchpad and
+// returns the checkpoint ID. The session is created if needed.
+func (sm *SessionManager) SaveCheckpoint(key, label string) int {
+	session := sm.GetOrCreate(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	id := 1
+	if n := len(session.Checkpoints); n > 0 {
+		id = session.Checkpoints[n-1].ID + 1
+	}
+
+	session.Checkpoints = append(session.Checkpoints, Checkpoint{
+		ID:         id,
+		Label:      label,
+		Messages:   append([]providers.Message(nil), session.Messages...),
+		Summary:    session.Summary,
+		Scratchpad: session.Scratchpad,
+		Created:    time.Now(),
+	})
+	if len(session.Checkpoints) > maxCheckpoints {
+		session.Checkpoints = session.Checkpoints[len(session.Checkpoints)-maxCheckpoints:]
+	}
+	return id
+}
+
+// RestoreCheckpoint rewinds the session to the given checkpoint. The
+// checkpoint and any newer ones are discarded. The transcript is kept as an
+// audit trail.
+func (sm *SessionManager) RestoreCheckpoint(key string, id int) error {
+	sm.ensureLoaded(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return fmt.Errorf("session %s not found", key)
+	}
+
+	for i, cp := range session.Checkpoints {
+		if cp.ID != id {
+			continue
+		}
+		session.Messages = cp.Messages
+		session.Summary = cp.Summary
+		session.Scratchpad = cp.Scratchpad
+		session.Checkpoints = session.Checkpoints[:i]
+		session.Updated = time.Now()
+		return sm.persist(session)
+	}
+	return fmt.Errorf("checkpoint %d not found", id)
+}
+
+// Undo restores the most recent checkpoint, which the agent saves before
+// each turn, rewinding the last exchange. Returns the restored checkpoint's
+// label, or false if there is nothing to undo.
+func (sm *SessionManager) Undo(key string) (string, bool) {
+	sm.ensureLoaded(key)
+
+	sm.mu.RLock()
+	session, ok := sm.sessions[key]
+	if !ok || len(session.Checkpoints) == 0 {
+		sm.mu.RUnlock()
+		return "", false
+	}
+	last := session.Checkpoints[len(session.Checkpoints)-1]
+	sm.mu.RUnlock()
+
+	if err := sm.RestoreCheckpoint(key, last.ID); err != nil {
+		return "", false
+	}
+	return last.Label, true
+}
+
+// Rewind undoes the last exchange like Undo and returns the user message
+// that started it, so the turn can be run again. Returns false if there is
+// nothing to rewind.
+func (sm *SessionManager) Rewind(key string) (string, bool) {
+	sm.ensureLoaded(key)
+
+	sm.mu.RLock()
+	session, ok := sm.sessions[key]
+	if !ok || len(session.Checkpoints) == 0 {
+		sm.mu.RUnlock()
+		return "", false
+	}
+	last := session.Checkpoints[len(session.Checkpoints)-1]
+	// The turn's user message follows the checkpointed history, unless the
+	// history was summarized since; then it is the last user message
+	prompt := ""
+	if len(session.Messages) > len(last.Messages) {
+		prompt = firstUserMessage(session.Messages[len(last.Messages):])
+	}
+	if prompt == "" {
+		for i := len(session.Messages) - 1; i >= 0; i-- {
+			if session.Messages[i].Role == "user" {
+				prompt = session.Messages[i].Content
+				break
+			}
+		}
+	}
+	sm.mu.RUnlock()
+
+	if prompt == "" {
+		return "", false
+	}
+	if err := sm.RestoreCheckpoint(key, last.ID); err != nil {
+		return "", false
+	}
+	return prompt, true
+}
+
+func firstUserMessage(messages []providers.Message) string {
+	for _, m := range messages {
+		if m.Role == "user" {
+			return m.Content
+		}
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// Export formats supported by Export.
+const (
+	FormatMarkdown = "md"
+	FormatJSON     = "json"
+)
+
+// exportEntries returns the transcript if one was recorded, otherwise falls
+// back to the (possibly summarized) message history.
+func exportEntries(s *Session) []TranscriptEntry {
+	if len(s.Transcript) > 0 {
+		return s.Transcript
+	}
+	entries := make([]TranscriptEntry, 0, len(s.Messages))
+	for _, m := range s.Messages {
+		entries = append(entries, TranscriptEntry{Message: m})
+	}
+	return entries
+}
+
+// Export renders a session transcript in the given format ("md" or "json").
+func Export(s *Session, format string) ([]byte, error) {
+	switch format {
+	case FormatMarkdown, "markdown", "":
+		return []byte(ExportMarkdown(s)), nil
+	case FormatJSON:
+		return ExportJSON(s)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s (use md or json)", format)
+	}
+}
+
+// ExportJSON renders the session transcript as indented JSON.
+func ExportJSON(s *Session) ([]byte, error) {
+	export := struct {
+		Key        string            `json:"key"`
+		Summary    string            `json:"summary,omitempty"`
+		Created    time.Time         `json:"created"`
+		Updated    time.Time         `json:"updated"`
+		Transcript []TranscriptEntry `json:"transcript"`
+	}{
+		Key:        s.Key,
+		Summary:    s.Summary,
+		Created:    s.Created,
+		Updated:    s.Updated,
+		Transcript: exportEntries(s),
+	}
+	return json.MarshalIndent(export, "", "  ")
+}
+
+// ExportMarkdown renders the session transcript as a readable Markdown document.
+func ExportMarkdown(s *Session) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Conversation: %s\n\n", s.Key))
+	sb.WriteString(fmt.Sprintf("- Created: %s\n", s.Created.Format("2006-01-02 15:04")))
+	sb.WriteString(fmt.Sprintf("- Updated: %s\n\n", s.Updated.Format("2006-01-02 15:04")))
+
+	if s.Summary != "" {
+		sb.WriteString("## Summary of earlier conversation\n\n")
+		sb.WriteString(s.Summary + "\n\n")
+	}
+
+	sb.WriteString("## Transcript\n\n")
+	for _, e := range exportEntries(s) {
+		header := roleTitle(e.Role)
+		if !e.Time.IsZero() {
+			header += " · " + e.Time.Format("2006-01-02 15:04")
+		}
+		sb.WriteString("### " + header + "\n\n")
+
+		if e.Content != "" {
+			if e.Role == "tool" {
+				sb.WriteString("```\n" + e.Content + "\n```\n\n")
+			} else {
+				sb.WriteString(e.Content + "\n\n")
+			}
+		}
+
+		for _, tc := range e.ToolCalls {
+			sb.WriteString(formatToolCall(tc))
+		}
+	}
+
+	return sb.String()
+}
+
+func formatToolCall(tc providers.ToolCall) string {
+	name, args := tc.Name, ""
+	if tc.Function != nil {
+		name = tc.Function.Name
+		args = tc.Function.Arguments
+	} else if len(tc.Arguments) > 0 {
+		data, _ := json.Marshal(tc.Arguments)
+		args = string(data)
+	}
+	return fmt.Sprintf("🔧 `%s` (id: %s)\n\n```json\n%s\n```\n\n", name, tc.ID, args)
+}
+
+func roleTitle(role string) string {
+	switch role {
+	case "user":
+		return "👤 User"
+	case "assistant":
+		return "🤖 Assistant"
+	case "tool":
+		return "🔧 Tool result"
+	case "system":
+		return "⚙️ System"
+	default:
+		return role
+	}
+}
@@ -2,6 +2,7 @@ package session
 
 import (
 	"encoding/json"
+	"log"
 	"os"
 	"path/filepath"
 	"sync"
@@ -11,19 +12,51 @@ import (
 )
 
 type Session struct {
-	Key      string              `json:"key"`
-	Messages []providers.Message `json:"messages"`
-	Summary  string              `json:"summary,omitempty"`
-	Created  time.Time           `json:"created"`
-	Updated  time.Time           `json:"updated"`
+	Key         string              `json:"key"`
+	Messages    []providers.Message `json:"messages"`
+	Summary     string              `json:"summary,omitempty"`
+	Scratchpad  string              `json:"scratchpad,omitempty"`
+	Project     string              `json:"project,omitempty"`
+	Model       string              `json:"model,omitempty"`   // model or alias chosen with /model, "" = the default
+	Options     map[string]float64  `json:"options,omitempty"` // generation settings changed with /set
+	Pinned      []Pin               `json:"pinned,omitempty"`
+	Checkpoints []Checkpoint        `json:"checkpoints,omitempty"`
+	Transcript  []TranscriptEntry   `json:"transcript,omitempty"`
+	ToolsOff    []string            `json:"tools_off,omitempty"` // tools the user disabled with /tools
+	Topic       string              `json:"topic,omitempty"`     // active topic of the chat, see ActiveKey
+	Created     time.Time           `json:"created"`
+	Updated     time.Time           `json:"updated"`
+
+	persisted int // transcript entries already written to the SQLite store
+}
+
+// TranscriptEntry is a full-fidelity record of one message in a session,
+// including tool calls and tool results. Unlike Messages, the transcript is
+// never summarized or truncated and is not sent back to the LLM.
+type TranscriptEntry struct {
+	providers.Message
+	Time time.Time `json:"time"`
+}
+
+// Pin is a sticky user instruction that is always included in context and
+// survives summarization, truncation and /reset.
+type Pin struct {
+	ID      int       `json:"id"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created"`
 }
 
 type SessionManager struct {
 	sessions map[string]*Session
 	mu       sync.RWMutex
 	storage  string
+	store    *Store // when set, sessions live in SQLite and are loaded on demand
+
+	archiveDir string
+	redact     func(string) string // masks secrets before they are stored
 }
 
+// NewSessionManager keeps sessions as one JSON file per session in storage.
 func NewSessionManager(storage string) *SessionManager {
 	sm := &SessionManager{
 		sessions: make(map[string]*Session),
@@ -38,7 +71,63 @@ func NewSessionManager(storage string) *SessionManager {
 	return sm
 }
 
+// NewSQLiteSessionManager keeps sessions in the SQLite database at dbPath.
+// Legacy JSON sessions found in legacyDir are imported on first start.
+func NewSQLiteSessionManager(dbPath, legacyDir string) (*SessionManager, error) {
+	store, err := OpenStore(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if legacyDir != "" {
+		if _, err := store.ImportJSONDir(legacyDir); err != nil {
+			log.Printf("[session] JSON migration incomplete: %v", err)
+		}
+	}
+
+	return &SessionManager{
+		sessions: make(map[string]*Session),
+		store:    store,
+	}, nil
+}
+
+// ensureLoaded pulls a session from the SQLite store, or restores it from the
+// archive, into memory on first use.
+func (sm *SessionManager) ensureLoaded(key string) {
+	sm.mu.RLock()
+	_, ok := sm.sessions[key]
+	archiveDir := sm.archiveDir
+	sm.mu.RUnlock()
+	if ok || (sm.store == nil && archiveDir == "") {
+		return
+	}
+
+	if sm.store != nil {
+		session, err := sm.store.Load(key)
+		if err != nil {
+			log.Printf("[session] Failed to load %s: %v", key, err)
+			return
+		}
+		if session != nil {
+			sm.mu.Lock()
+			if _, ok := sm.sessions[key]; !ok {
+				sm.sessions[key] = session
+			}
+			sm.mu.Unlock()
+			return
+		}
+	}
+
+	sm.mu.Lock()
+	if _, ok := sm.sessions[key]; !ok {
+		sm.restoreArchived(key)
+	}
+	sm.mu.Unlock()
+}
+
 func (sm *SessionManager) GetOrCreate(key string) *Session {
+	sm.ensureLoaded(key)
+
 	sm.mu.RLock()
 	session, ok := sm.sessions[key]
 	sm.mu.RUnlock()
@@ -59,6 +148,8 @@ func (sm *SessionManager) GetOrCreate(key string) *Session {
 }
 
 func (sm *SessionManager) AddMessage(sessionKey, role, content string) {
+	sm.ensureLoaded(sessionKey)
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -74,12 +165,109 @@ func (sm *SessionManager) AddMessage(sessionKey, role, content string) {
 
 	session.Messages = append(session.Messages, providers.Message{
 		Role:    role,
-		Content: content,
+		Content: sm.scrub(content),
 	})
 	session.Updated = time.Now()
 }
 
+// AppendTranscript records messages in the session's full transcript.
+func (sm *SessionManager) AppendTranscript(sessionKey string, messages ...providers.Message) {
+	sm.ensureLoaded(sessionKey)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[sessionKey]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	for _, m := range messages {
+		m.Content = sm.scrub(m.Content)
+		if len(m.ToolCalls) > 0 {
+			calls := make([]providers.ToolCall, len(m.ToolCalls))
+			for i, tc := range m.ToolCalls {
+				if tc.Function != nil {
+					fn := *tc.Function
+					fn.Arguments = sm.scrub(fn.Arguments)
+					tc.Function = &fn
+				}
+				calls[i] = tc
+			}
+			m.ToolCalls = calls
+		}
+		session.Transcript = append(session.Transcript, TranscriptEntry{Message: m, Time: now})
+	}
+}
+
+// SetRedactor installs a filter applied to message content and tool call
+// arguments before they are stored, so credentials the user pasted or a tool
+// printed are not kept in history or written to disk.
+func (sm *SessionManager) SetRedactor(fn func(string) string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.redact = fn
+}
+
+// scrub applies the redactor, if any. Callers hold sm.mu.
+func (sm *SessionManager) scrub(s string) string {
+	if sm.redact == nil {
+		return s
+	}
+	return sm.redact(s)
+}
+
+// Get returns a copy of the session for key, or false if it doesn't exist.
+func (sm *SessionManager) Get(key string) (*Session, bool) {
+	sm.ensureLoaded(key)
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return nil, false
+	}
+
+	cp := *session
+	cp.persisted = 0
+	cp.Messages = append([]providers.Message(nil), session.Messages...)
+	cp.Transcript = append([]TranscriptEntry(nil), session.Transcript...)
+	cp.Pinned = append([]Pin(nil), session.Pinned...)
+	cp.Checkpoints = append([]Checkpoint(nil), session.Checkpoints...)
+	return &cp, true
+}
+
+// ListKeys returns the keys of all known sessions.
+func (sm *SessionManager) ListKeys() []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	seen := make(map[string]bool, len(sm.sessions))
+	keys := make([]string, 0, len(sm.sessions))
+	for key := range sm.sessions {
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	if sm.store != nil {
+		stored, err := sm.store.Keys()
+		if err != nil {
+			log.Printf("[session] Failed to list sessions: %v", err)
+		}
+		for _, key := range stored {
+			if !seen[key] {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
 func (sm *SessionManager) GetHistory(key string) []providers.Message {
+	sm.ensureLoaded(key)
+
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
@@ -94,6 +282,8 @@ func (sm *SessionManager) GetHistory(key string) []providers.Message {
 }
 
 func (sm *SessionManager) GetSummary(key string) string {
+	sm.ensureLoaded(key)
+
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
@@ -105,6 +295,8 @@ func (sm *SessionManager) GetSummary(key string) string {
 }
 
 func (sm *SessionManager) SetSummary(key string, summary string) {
+	sm.ensureLoaded(key)
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -115,7 +307,182 @@ func (sm *SessionManager) SetSummary(key string, summary string) {
 	}
 }
 
+// GetScratchpad returns the session's working notes buffer.
+func (sm *SessionManager) GetScratchpad(key string) string {
+	sm.ensureLoaded(key)
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return ""
+	}
+	return session.Scratchpad
+}
+
+// SetScratchpad replaces the session's working notes buffer, creating the
+// session if needed. It is persisted with the next Save.
+func (sm *SessionManager) SetScratchpad(key, content string) {
+	session := sm.GetOrCreate(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session.Scratchpad = content
+	session.Updated = time.Now()
+}
+
+// GetProject returns the name of the session's active project, if any.
+func (sm *SessionManager) GetProject(key string) string {
+	sm.ensureLoaded(key)
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return ""
+	}
+	return session.Project
+}
+
+// SetProject switches the session's active project ("" = none), creating the
+// session if needed.
+func (sm *SessionManager) SetProject(key, project string) {
+	session := sm.GetOrCreate(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session.Project = project
+	session.Updated = time.Now()
+	sm.persist(session)
+}
+
+// GetModel returns the model or alias chosen for the session, or "" when it
+// uses the default model.
+func (sm *SessionManager) GetModel(key string) string {
+	sm.ensureLoaded(key)
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return ""
+	}
+	return session.Model
+}
+
+// SetModel sets the session's model ("" = the default), creating the session
+// if needed.
+func (sm *SessionManager) SetModel(key, model string) {
+	session := sm.GetOrCreate(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session.Model = model
+	session.Updated = time.Now()
+	sm.persist(session)
+}
+
+// GetDisabledTools returns the tools the user turned off in this session.
+func (sm *SessionManager) GetDisabledTools(key string) []string {
+	sm.ensureLoaded(key)
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), session.ToolsOff...)
+}
+
+// SetToolDisabled turns a tool off or back on for the session, creating the
+// session if needed.
+func (sm *SessionManager) SetToolDisabled(key, tool string, disabled bool) {
+	session := sm.GetOrCreate(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	off := session.ToolsOff[:0:0]
+	for _, name := range session.ToolsOff {
+		if name != tool {
+			off = append(off, name)
+		}
+	}
+	if disabled {
+		off = append(off, tool)
+	}
+	session.ToolsOff = off
+	session.Updated = time.Now()
+	sm.persist(session)
+}
+
+// AddPin pins an instruction to the session, creating the session if needed.
+func (sm *SessionManager) AddPin(key, content string) Pin {
+	session := sm.GetOrCreate(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	id := 1
+	for _, p := range session.Pinned {
+		if p.ID >= id {
+			id = p.ID + 1
+		}
+	}
+	pin := Pin{ID: id, Content: content, Created: time.Now()}
+	session.Pinned = append(session.Pinned, pin)
+	session.Updated = time.Now()
+	sm.persist(session)
+	return pin
+}
+
+// RemovePin unpins an instruction by ID.
+func (sm *SessionManager) RemovePin(key string, id int) bool {
+	sm.ensureLoaded(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return false
+	}
+	for i, p := range session.Pinned {
+		if p.ID == id {
+			session.Pinned = append(session.Pinned[:i], session.Pinned[i+1:]...)
+			session.Updated = time.Now()
+			sm.persist(session)
+			return true
+		}
+	}
+	return false
+}
+
+// GetPins returns the session's pinned instructions.
+func (sm *SessionManager) GetPins(key string) []Pin {
+	sm.ensureLoaded(key)
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return nil
+	}
+	return append([]Pin(nil), session.Pinned...)
+}
+
 func (sm *SessionManager) TruncateHistory(key string, keepLast int) {
+	sm.ensureLoaded(key)
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -133,6 +500,8 @@ func (sm *SessionManager) TruncateHistory(key string, keepLast int) {
 }
 
 func (sm *SessionManager) ClearHistory(key string) {
+	sm.ensureLoaded(key)
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -143,23 +512,70 @@ func (sm *SessionManager) ClearHistory(key string) {
 
 	session.Messages = []providers.Message{}
 	session.Summary = ""
+	session.Scratchpad = ""
 	session.Updated = time.Now()
 
-	sm.saveToFile(session)
+	sm.persist(session)
 }
 
 func (sm *SessionManager) Save(session *Session) error {
-	if sm.storage == "" {
+	if sm.storage == "" && sm.store == nil {
 		return nil
 	}
 
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	return sm.saveToFile(session)
+	return sm.persist(session)
 }
 
-func (sm *SessionManager) saveToFile(session *Session) error {
+// Delete permanently removes a session from memory, storage and the archive.
+func (sm *SessionManager) Delete(key string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	delete(sm.sessions, key)
+
+	if sm.store != nil {
+		if err := sm.store.Delete(key); err != nil {
+			return err
+		}
+	} else if sm.storage != "" {
+		if err := os.Remove(filepath.Join(sm.storage, key+".json")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if sm.archiveDir != "" {
+		if err := os.Remove(sm.archivePath(key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Vacuum reclaims space in the SQLite database so deleted rows are not
+// recoverable from free pages.
+func (sm *SessionManager) Vacuum() error {
+	if sm.store == nil {
+		return nil
+	}
+	_, err := sm.store.db.Exec("VACUUM")
+	return err
+}
+
+// Close releases the SQLite store, if any.
+func (sm *SessionManager) Close() error {
+	if sm.store == nil {
+		return nil
+	}
+	return sm.store.Close()
+}
+
+func (sm *SessionManager) persist(session *Session) error {
+	if sm.store != nil {
+		return sm.store.Save(session)
+	}
 	if sm.storage == "" {
 		return nil
 	}
@@ -0,0 +1,125 @@
+package session
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Option is a generation setting a session can override with /set.
+type Option struct {
+	Name    string
+	Min     float64
+	Max     float64
+	Integer bool
+	Help    string
+}
+
+// Options are the settings /set accepts.
+var Options = []Option{
+	{Name: "temperature", Min: 0, Max: 2, Help: "randomness, 0 = focused, 2 = creative"},
+	{Name: "max_tokens", Min: 1, Max: 200000, Integer: true, Help: "longest reply, in tokens"},
+	{Name: "top_p", Min: 0, Max: 1, Help: "nucleus sampling cutoff"},
+}
+
+// LookupOption returns the option with the given name.
+func LookupOption(name string) (Option, bool) {
+	for _, o := range Options {
+		if o.Name == name {
+			return o, true
+		}
+	}
+	return Option{}, false
+}
+
+// ParseOption checks raw against the option's type and range.
+func ParseOption(name, raw string) (float64, error) {
+	o, ok := LookupOption(name)
+	if !ok {
+		return 0, fmt.Errorf("unknown option %s", name)
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+	if o.Integer && v != math.Trunc(v) {
+		return 0, fmt.Errorf("%s must be a whole number", name)
+	}
+	if v < o.Min || v > o.Max {
+		return 0, fmt.Errorf("%s must be between %g and %g", name, o.Min, o.Max)
+	}
+	return v, nil
+}
+
+// FormatOption prints a value the way /set takes it.
+func FormatOption(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// GetOptions returns a copy of the session's option overrides.
+func (sm *SessionManager) GetOptions(key string) map[string]float64 {
+	sm.ensureLoaded(key)
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok || len(session.Options) == 0 {
+		return nil
+	}
+	options := make(map[string]float64, len(session.Options))
+	for name, v := range session.Options {
+		options[name] = v
+	}
+	return options
+}
+
+// SetOption overrides an option for the session, creating the session if
+// needed. Callers validate the value with ParseOption.
+func (sm *SessionManager) SetOption(key, name string, value float64) {
+	session := sm.GetOrCreate(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if session.Options == nil {
+		session.Options = make(map[string]float64)
+	}
+	session.Options[name] = value
+	session.Updated = time.Now()
+	sm.persist(session)
+}
+
+// ResetOptions drops the given overrides, or all of them when none are
+// named, and returns the names that were dropped.
+func (sm *SessionManager) ResetOptions(key string, names ...string) []string {
+	sm.ensureLoaded(key)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return nil
+	}
+	if len(names) == 0 {
+		for name := range session.Options {
+			names = append(names, name)
+		}
+	}
+	var dropped []string
+	for _, name := range names {
+		if _, ok := session.Options[name]; ok {
+			delete(session.Options, name)
+			dropped = append(dropped, name)
+		}
+	}
+	if len(dropped) > 0 {
+		session.Updated = time.Now()
+		sm.persist(session)
+	}
+	sort.Strings(dropped)
+	return dropped
+}
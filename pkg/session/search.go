@@ -0,0 +1,73 @@
+package session
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultSearchLimit = 20
+
+// SearchQuery filters past conversation messages. Zero fields are ignored.
+type SearchQuery struct {
+	Text       string    // case-insensitive substring
+	Channel    string    // e.g. "telegram"
+	SessionKey string    // restrict to one session
+	Since      time.Time // inclusive
+	Until      time.Time // exclusive
+	Limit      int
+}
+
+// SearchHit is a single matching message.
+type SearchHit struct {
+	SessionKey string    `json:"session_key"`
+	Role       string    `json:"role"`
+	Content    string    `json:"content"`
+	Time       time.Time `json:"time"`
+}
+
+func (q SearchQuery) limit() int {
+	if q.Limit <= 0 {
+		return defaultSearchLimit
+	}
+	return q.Limit
+}
+
+// Search finds user and assistant messages across past conversations.
+func (sm *SessionManager) Search(q SearchQuery) ([]SearchHit, error) {
+	if sm.store != nil {
+		return sm.store.Search(q)
+	}
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	text := strings.ToLower(q.Text)
+	var hits []SearchHit
+	for key, session := range sm.sessions {
+		if q.SessionKey != "" && key != q.SessionKey {
+			continue
+		}
+		if q.Channel != "" && channelOf(key) != q.Channel {
+			continue
+		}
+		for _, e := range session.Transcript {
+			if e.Role != "user" && e.Role != "assistant" {
+				continue
+			}
+			if text != "" && !strings.Contains(strings.ToLower(e.Content), text) {
+				continue
+			}
+			if (!q.Since.IsZero() && e.Time.Before(q.Since)) || (!q.Until.IsZero() && !e.Time.Before(q.Until)) {
+				continue
+			}
+			hits = append(hits, SearchHit{SessionKey: key, Role: e.Role, Content: e.Content, Time: e.Time})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Time.After(hits[j].Time) })
+	if len(hits) > q.limit() {
+		hits = hits[:q.limit()]
+	}
+	return hits, nil
+}
@@ -0,0 +1,429 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/providers"
+	_ "modernc.org/sqlite"
+)
+
+// Store persists sessions in SQLite. Session state (history and summary) is
+// kept in one row per session; the transcript is stored one row per message
+// so it can be searched.
+type Store struct {
+	db *sql.DB
+}
+
+// OpenStore opens (or creates) the session tables in the SQLite database at dbPath.
+func OpenStore(dbPath string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	store := &Store{db: db}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate session database: %w", err)
+	}
+	return store, nil
+}
+
+func (s *Store) migrate() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS sessions (
+		key         TEXT PRIMARY KEY,
+		channel     TEXT NOT NULL DEFAULT '',
+		summary     TEXT NOT NULL DEFAULT '',
+		messages    TEXT NOT NULL DEFAULT '[]',
+		created_at  INTEGER NOT NULL,
+		updated_at  INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_sessions_channel ON sessions(channel, updated_at);
+	CREATE INDEX IF NOT EXISTS idx_sessions_updated ON sessions(updated_at);
+
+	CREATE TABLE IF NOT EXISTS session_messages (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_key TEXT NOT NULL,
+		channel     TEXT NOT NULL DEFAULT '',
+		role        TEXT NOT NULL,
+		content     TEXT NOT NULL DEFAULT '',
+		data        TEXT NOT NULL,
+		created_at  INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_session_messages_key ON session_messages(session_key, id);
+	CREATE INDEX IF NOT EXISTS idx_session_messages_time ON session_messages(created_at);
+	CREATE INDEX IF NOT EXISTS idx_session_messages_channel ON session_messages(channel, created_at);
+	`
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+	if err := s.addColumn("sessions", "scratchpad", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sessions", "pinned", "TEXT NOT NULL DEFAULT '[]'"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sessions", "checkpoints", "TEXT NOT NULL DEFAULT '[]'"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sessions", "project", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sessions", "tools_off", "TEXT NOT NULL DEFAULT '[]'"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sessions", "topic", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sessions", "model", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return s.addColumn("sessions", "options", "TEXT NOT NULL DEFAULT '{}'")
+}
+
+// addColumn adds a column to an existing table unless it is already present.
+func (s *Store) addColumn(table, column, definition string) error {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// channelOf derives the channel from a session key such as "telegram:12345".
+func channelOf(key string) string {
+	if idx := strings.Index(key, ":"); idx > 0 {
+		return key[:idx]
+	}
+	return ""
+}
+
+// Load returns the stored session, or nil if it doesn't exist.
+func (s *Store) Load(key string) (*Session, error) {
+	var summary, scratchpad, project, pinned, checkpoints, toolsOff, topic, model, options, messages string
+	var created, updated int64
+	err := s.db.QueryRow(`SELECT summary, scratchpad, project, pinned, checkpoints, tools_off, topic, model, options, messages, created_at, updated_at FROM sessions WHERE key = ?`, key).
+		Scan(&summary, &scratchpad, &project, &pinned, &checkpoints, &toolsOff, &topic, &model, &options, &messages, &created, &updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{
+		Key:        key,
+		Summary:    summary,
+		Scratchpad: scratchpad,
+		Project:    project,
+		Topic:      topic,
+		Model:      model,
+		Created:    time.UnixMilli(created),
+		Updated:    time.UnixMilli(updated),
+	}
+	if err := json.Unmarshal([]byte(messages), &session.Messages); err != nil {
+		return nil, fmt.Errorf("corrupt messages for session %s: %w", key, err)
+	}
+	if err := json.Unmarshal([]byte(pinned), &session.Pinned); err != nil {
+		return nil, fmt.Errorf("corrupt pins for session %s: %w", key, err)
+	}
+	if err := json.Unmarshal([]byte(checkpoints), &session.Checkpoints); err != nil {
+		return nil, fmt.Errorf("corrupt checkpoints for session %s: %w", key, err)
+	}
+	if err := json.Unmarshal([]byte(toolsOff), &session.ToolsOff); err != nil {
+		return nil, fmt.Errorf("corrupt tool settings for session %s: %w", key, err)
+	}
+	if err := json.Unmarshal([]byte(options), &session.Options); err != nil {
+		return nil, fmt.Errorf("corrupt options for session %s: %w", key, err)
+	}
+
+	rows, err := s.db.Query(`SELECT data, created_at FROM session_messages WHERE session_key = ? ORDER BY id`, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		var at int64
+		if err := rows.Scan(&data, &at); err != nil {
+			return nil, err
+		}
+		var msg providers.Message
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			continue
+		}
+		session.Transcript = append(session.Transcript, TranscriptEntry{Message: msg, Time: time.UnixMilli(at)})
+	}
+	session.persisted = len(session.Transcript)
+
+	return session, rows.Err()
+}
+
+// Save upserts the session row and appends transcript entries not yet stored.
+func (s *Store) Save(session *Session) error {
+	messages, err := json.Marshal(session.Messages)
+	if err != nil {
+		return err
+	}
+	pinned, err := json.Marshal(session.Pinned)
+	if err != nil {
+		return err
+	}
+	if session.Pinned == nil {
+		pinned = []byte("[]")
+	}
+	checkpoints, err := json.Marshal(session.Checkpoints)
+	if err != nil {
+		return err
+	}
+	if session.Checkpoints == nil {
+		checkpoints = []byte("[]")
+	}
+	toolsOff, err := json.Marshal(session.ToolsOff)
+	if err != nil {
+		return err
+	}
+	if session.ToolsOff == nil {
+		toolsOff = []byte("[]")
+	}
+	options, err := json.Marshal(session.Options)
+	if err != nil {
+		return err
+	}
+	if session.Options == nil {
+		options = []byte("{}")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	channel := channelOf(session.Key)
+	_, err = tx.Exec(`
+		INSERT INTO sessions (key, channel, summary, scratchpad, project, pinned, checkpoints, tools_off, topic, model, options, messages, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET summary = excluded.summary, scratchpad = excluded.scratchpad,
+			project = excluded.project, pinned = excluded.pinned, checkpoints = excluded.checkpoints,
+			tools_off = excluded.tools_off, topic = excluded.topic, model = excluded.model, options = excluded.options, messages = excluded.messages, updated_at = excluded.updated_at`,
+		session.Key, channel, session.Summary, session.Scratchpad, session.Project, string(pinned), string(checkpoints),
+		string(toolsOff), session.Topic, session.Model, string(options), string(messages), session.Created.UnixMilli(), session.Updated.UnixMilli())
+	if err != nil {
+		return err
+	}
+
+	if session.persisted > len(session.Transcript) {
+		session.persisted = len(session.Transcript)
+	}
+	for _, entry := range session.Transcript[session.persisted:] {
+		data, err := json.Marshal(entry.Message)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO session_messages (session_key, channel, role, content, data, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			session.Key, channel, entry.Role, entry.Content, string(data), entry.Time.UnixMilli())
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	session.persisted = len(session.Transcript)
+	return nil
+}
+
+// Keys returns all stored session keys, most recently updated first.
+func (s *Store) Keys() ([]string, error) {
+	rows, err := s.db.Query(`SELECT key FROM sessions ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// IdleKeys returns keys of sessions not updated since cutoff.
+func (s *Store) IdleKeys(cutoff time.Time) ([]string, error) {
+	rows, err := s.db.Query(`SELECT key FROM sessions WHERE updated_at < ?`, cutoff.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Delete removes a session and its transcript.
+func (s *Store) Delete(key string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM session_messages WHERE session_key = ?`, key); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM sessions WHERE key = ?`, key); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Search finds transcript messages matching the query, newest first.
+func (s *Store) Search(q SearchQuery) ([]SearchHit, error) {
+	query := `SELECT session_key, role, content, created_at FROM session_messages WHERE role IN ('user', 'assistant')`
+	var args []interface{}
+
+	if q.Text != "" {
+		query += ` AND content LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(q.Text)+"%")
+	}
+	if q.Channel != "" {
+		query += ` AND channel = ?`
+		args = append(args, q.Channel)
+	}
+	if q.SessionKey != "" {
+		query += ` AND session_key = ?`
+		args = append(args, q.SessionKey)
+	}
+	if !q.Since.IsZero() {
+		query += ` AND created_at >= ?`
+		args = append(args, q.Since.UnixMilli())
+	}
+	if !q.Until.IsZero() {
+		query += ` AND created_at < ?`
+		args = append(args, q.Until.UnixMilli())
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, q.limit())
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []SearchHit
+	for rows.Next() {
+		var hit SearchHit
+		var at int64
+		if err := rows.Scan(&hit.SessionKey, &hit.Role, &hit.Content, &at); err != nil {
+			return nil, err
+		}
+		hit.Time = time.UnixMilli(at)
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+// ImportJSONDir migrates legacy per-file JSON sessions into the store.
+// Imported files are renamed to *.json.migrated so they are not imported twice.
+func (s *Store) ImportJSONDir(dir string) (int, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	imported := 0
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var session Session
+		if err := json.Unmarshal(data, &session); err != nil || session.Key == "" {
+			log.Printf("[session] Skipping unreadable session file %s", file.Name())
+			continue
+		}
+
+		existing, err := s.Load(session.Key)
+		if err != nil {
+			return imported, err
+		}
+		if existing == nil {
+			if err := s.Save(&session); err != nil {
+				return imported, fmt.Errorf("failed to import %s: %w", file.Name(), err)
+			}
+			imported++
+		}
+		os.Rename(path, path+".migrated")
+	}
+
+	if imported > 0 {
+		log.Printf("[session] Migrated %d JSON sessions into SQLite", imported)
+	}
+	return imported, nil
+}
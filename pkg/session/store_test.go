@@ -0,0 +1,398 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+func TestSQLiteSessionRoundTripAndSearch(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+
+	sm, err := NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionManager failed: %v", err)
+	}
+	sm.AddMessage("telegram:1", "user", "remind me about the dentist")
+	sm.AppendTranscript("telegram:1",
+		providers.Message{Role: "user", Content: "remind me about the dentist"},
+		providers.Message{Role: "assistant", Content: "Sure, dentist on Friday."},
+	)
+	if err := sm.Save(sm.GetOrCreate("telegram:1")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// Saving again must not duplicate transcript rows
+	sm.Save(sm.GetOrCreate("telegram:1"))
+	sm.Close()
+
+	sm, err = NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer sm.Close()
+
+	if history := sm.GetHistory("telegram:1"); len(history) != 1 {
+		t.Fatalf("expected 1 history message after reload, got %d", len(history))
+	}
+
+	hits, err := sm.Search(SearchQuery{Text: "DENTIST", Channel: "telegram"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("expected 2 hits, got %d", len(hits))
+	}
+	if hits[0].Role != "assistant" {
+		t.Errorf("expected newest hit first, got %s", hits[0].Role)
+	}
+
+	hits, _ = sm.Search(SearchQuery{Text: "dentist", Channel: "discord"})
+	if len(hits) != 0 {
+		t.Errorf("expected no hits for other channel, got %d", len(hits))
+	}
+	hits, _ = sm.Search(SearchQuery{Text: "dentist", Since: time.Now().Add(time.Hour)})
+	if len(hits) != 0 {
+		t.Errorf("expected no hits in the future, got %d", len(hits))
+	}
+}
+
+func TestImportJSONSessions(t *testing.T) {
+	dir := t.TempDir()
+	legacyDir := filepath.Join(dir, "sessions")
+	os.MkdirAll(legacyDir, 0755)
+
+	legacy := Session{
+		Key:      "cli:direct",
+		Messages: []providers.Message{{Role: "user", Content: "hello"}},
+		Created:  time.Now(),
+		Updated:  time.Now(),
+	}
+	data, _ := json.Marshal(legacy)
+	os.WriteFile(filepath.Join(legacyDir, "cli:direct.json"), data, 0644)
+
+	sm, err := NewSQLiteSessionManager(filepath.Join(dir, "memory.db"), legacyDir)
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionManager failed: %v", err)
+	}
+	defer sm.Close()
+
+	keys := sm.ListKeys()
+	if len(keys) != 1 || keys[0] != "cli:direct" {
+		t.Fatalf("expected migrated session, got %v", keys)
+	}
+	if _, err := os.Stat(filepath.Join(legacyDir, "cli:direct.json.migrated")); err != nil {
+		t.Errorf("expected legacy file to be renamed: %v", err)
+	}
+}
+
+func TestArchiveAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	sm, err := NewSQLiteSessionManager(filepath.Join(dir, "memory.db"), "")
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionManager failed: %v", err)
+	}
+	defer sm.Close()
+	sm.SetArchiveDir(filepath.Join(dir, "archive"))
+
+	sm.AddMessage("telegram:old", "user", "old chat")
+	old := sm.GetOrCreate("telegram:old")
+	old.Updated = time.Now().Add(-40 * 24 * time.Hour)
+	sm.Save(old)
+
+	sm.AddMessage("telegram:new", "user", "new chat")
+	sm.Save(sm.GetOrCreate("telegram:new"))
+
+	n, err := sm.ArchiveIdle(30 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("ArchiveIdle failed: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 archived session, got %d", n)
+	}
+	if keys := sm.ListKeys(); len(keys) != 1 || keys[0] != "telegram:new" {
+		t.Fatalf("expected only the active session to remain, got %v", keys)
+	}
+
+	// Resuming the chat restores it from the archive
+	history := sm.GetHistory("telegram:old")
+	if len(history) != 1 || history[0].Content != "old chat" {
+		t.Fatalf("expected restored history, got %v", history)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "archive", "telegram:old.json.gz")); !os.IsNotExist(err) {
+		t.Error("archive file should be removed after restore")
+	}
+}
+
+func TestDeleteRemovesLiveAndArchived(t *testing.T) {
+	dir := t.TempDir()
+	sm, err := NewSQLiteSessionManager(filepath.Join(dir, "memory.db"), "")
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionManager failed: %v", err)
+	}
+	defer sm.Close()
+	sm.SetArchiveDir(filepath.Join(dir, "archive"))
+
+	sm.AddMessage("telegram:1", "user", "archived chat")
+	old := sm.GetOrCreate("telegram:1")
+	old.Updated = time.Now().Add(-40 * 24 * time.Hour)
+	sm.Save(old)
+	if _, err := sm.ArchiveIdle(30 * 24 * time.Hour); err != nil {
+		t.Fatalf("ArchiveIdle failed: %v", err)
+	}
+
+	sm.AddMessage("telegram:2", "user", "live chat")
+	sm.Save(sm.GetOrCreate("telegram:2"))
+
+	if keys := sm.ArchivedKeys(); len(keys) != 1 || keys[0] != "telegram:1" {
+		t.Fatalf("expected telegram:1 archived, got %v", keys)
+	}
+	if s, ok := sm.LoadArchived("telegram:1"); !ok || len(s.Messages) != 1 {
+		t.Fatal("expected to read archived session")
+	}
+
+	for _, key := range []string{"telegram:1", "telegram:2"} {
+		if err := sm.Delete(key); err != nil {
+			t.Fatalf("Delete(%s) failed: %v", key, err)
+		}
+	}
+	if err := sm.Vacuum(); err != nil {
+		t.Fatalf("Vacuum failed: %v", err)
+	}
+
+	if keys := sm.ListKeys(); len(keys) != 0 {
+		t.Errorf("expected no sessions, got %v", keys)
+	}
+	if keys := sm.ArchivedKeys(); len(keys) != 0 {
+		t.Errorf("expected no archives, got %v", keys)
+	}
+	if history := sm.GetHistory("telegram:1"); len(history) != 0 {
+		t.Errorf("deleted session should not be restored, got %v", history)
+	}
+}
+
+func TestProjectPersists(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	sm, err := NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionManager failed: %v", err)
+	}
+	sm.SetProject("telegram:1", "website")
+	sm.SetModel("telegram:1", "pro")
+	sm.Close()
+
+	sm, err = NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer sm.Close()
+	if got := sm.GetProject("telegram:1"); got != "website" {
+		t.Errorf("expected project website after reopen, got %q", got)
+	}
+	if got := sm.GetModel("telegram:1"); got != "pro" {
+		t.Errorf("expected model pro after reopen, got %q", got)
+	}
+}
+
+func TestDisabledToolsPersist(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	sm, err := NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionManager failed: %v", err)
+	}
+	sm.SetToolDisabled("telegram:1", "exec", true)
+	sm.SetToolDisabled("telegram:1", "browser", true)
+	sm.SetToolDisabled("telegram:1", "exec", false)
+	sm.Close()
+
+	sm, err = NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer sm.Close()
+	if got := sm.GetDisabledTools("telegram:1"); len(got) != 1 || got[0] != "browser" {
+		t.Errorf("expected only browser disabled after reopen, got %v", got)
+	}
+}
+
+func TestPinsSurviveReset(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	sm, err := NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionManager failed: %v", err)
+	}
+
+	sm.AddPin("telegram:1", "always answer in Vietnamese")
+	second := sm.AddPin("telegram:1", "be brief")
+	sm.AddMessage("telegram:1", "user", "hi")
+	sm.ClearHistory("telegram:1")
+	sm.Close()
+
+	sm, err = NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer sm.Close()
+
+	pins := sm.GetPins("telegram:1")
+	if len(pins) != 2 || pins[0].Content != "always answer in Vietnamese" {
+		t.Fatalf("expected pins to survive reset, got %v", pins)
+	}
+	if !sm.RemovePin("telegram:1", second.ID) {
+		t.Fatal("RemovePin failed")
+	}
+	if pins := sm.GetPins("telegram:1"); len(pins) != 1 {
+		t.Errorf("expected 1 pin after unpin, got %d", len(pins))
+	}
+}
+
+func TestCheckpointUndo(t *testing.T) {
+	sm := NewSessionManager("")
+
+	sm.SaveCheckpoint("cli:direct", "first")
+	sm.AddMessage("cli:direct", "user", "first")
+	sm.AddMessage("cli:direct", "assistant", "reply 1")
+
+	sm.SaveCheckpoint("cli:direct", "second")
+	sm.AddMessage("cli:direct", "user", "second")
+	sm.AddMessage("cli:direct", "assistant", "reply 2")
+	sm.SetScratchpad("cli:direct", "step 2 in progress")
+
+	label, ok := sm.Undo("cli:direct")
+	if !ok || label != "second" {
+		t.Fatalf("expected to undo 'second', got %q (%t)", label, ok)
+	}
+	if history := sm.GetHistory("cli:direct"); len(history) != 2 || history[1].Content != "reply 1" {
+		t.Fatalf("expected history rewound to first exchange, got %v", history)
+	}
+	if sp := sm.GetScratchpad("cli:direct"); sp != "" {
+		t.Errorf("expected scratchpad reverted, got %q", sp)
+	}
+
+	sm.Undo("cli:direct")
+	if _, ok := sm.Undo("cli:direct"); ok {
+		t.Error("expected nothing left to undo")
+	}
+}
+
+func TestCheckpointRewind(t *testing.T) {
+	sm := NewSessionManager("")
+	if _, ok := sm.Rewind("cli:direct"); ok {
+		t.Error("expected nothing to rewind in a new session")
+	}
+
+	sm.SaveCheckpoint("cli:direct", "first")
+	sm.AddMessage("cli:direct", "user", "first question")
+	sm.AddMessage("cli:direct", "assistant", "reply 1")
+	sm.SaveCheckpoint("cli:direct", "second")
+	sm.AddMessage("cli:direct", "user", "second question, in full")
+	sm.AddMessage("cli:direct", "assistant", "reply 2")
+
+	prompt, ok := sm.Rewind("cli:direct")
+	if !ok || prompt != "second question, in full" {
+		t.Fatalf("expected to rewind the second question, got %q (%t)", prompt, ok)
+	}
+	if history := sm.GetHistory("cli:direct"); len(history) != 2 {
+		t.Errorf("expected the first exchange to remain, got %v", history)
+	}
+}
+
+func TestRedactorMasksStoredMessages(t *testing.T) {
+	sm := NewSessionManager("")
+	sm.SetRedactor(func(s string) string { return strings.ReplaceAll(s, "hunter2", "[REDACTED]") })
+	sm.AddMessage("telegram:1", "user", "my password is hunter2")
+	sm.AppendTranscript("telegram:1", providers.Message{
+		Role:      "assistant",
+		ToolCalls: []providers.ToolCall{{ID: "1", Function: &providers.FunctionCall{Name: "exec", Arguments: `{"command":"login hunter2"}`}}},
+	})
+
+	s, _ := sm.Get("telegram:1")
+	if got := s.Messages[0].Content; got != "my password is [REDACTED]" {
+		t.Errorf("expected masked history, got %q", got)
+	}
+	if got := s.Transcript[0].ToolCalls[0].Function.Arguments; strings.Contains(got, "hunter2") {
+		t.Errorf("expected masked tool arguments, got %q", got)
+	}
+}
+
+func TestTopics(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	sm, err := NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionManager failed: %v", err)
+	}
+	sm.AddMessage("telegram:1", "user", "main chat")
+
+	name, err := NormalizeTopic(" Project-X ")
+	if err != nil || name != "project-x" {
+		t.Fatalf("NormalizeTopic = %q, %v", name, err)
+	}
+	if _, err := NormalizeTopic("a/b"); err == nil {
+		t.Error("expected a name with a slash to be rejected")
+	}
+	if !sm.SwitchTopic("telegram:1", name) {
+		t.Error("expected the topic to be created")
+	}
+	if got := sm.ActiveKey("telegram:1"); got != "telegram:1#project-x" {
+		t.Errorf("ActiveKey = %q", got)
+	}
+	sm.AddMessage(sm.ActiveKey("telegram:1"), "user", "about x")
+	if history := sm.GetHistory("telegram:1"); len(history) != 1 || history[0].Content != "main chat" {
+		t.Errorf("expected the main history untouched, got %+v", history)
+	}
+	sm.Save(sm.GetOrCreate("telegram:1#project-x"))
+	sm.Close()
+
+	sm, err = NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer sm.Close()
+	topics := sm.Topics("telegram:1")
+	if len(topics) != 1 || topics[0].Name != "project-x" || !topics[0].Active || topics[0].Messages != 1 {
+		t.Errorf("unexpected topics after reopen: %+v", topics)
+	}
+	if sm.SwitchTopic("telegram:1", ""); sm.ActiveKey("telegram:1") != "telegram:1" {
+		t.Error("expected main to be active again")
+	}
+	if chat, topic := SplitTopic("telegram:1#project-x"); chat != "telegram:1" || topic != "project-x" {
+		t.Errorf("SplitTopic = %q, %q", chat, topic)
+	}
+}
+
+func TestOptionsPersist(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "memory.db")
+	sm, err := NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("NewSQLiteSessionManager failed: %v", err)
+	}
+	if _, err := ParseOption("temperature", "3"); err == nil {
+		t.Error("expected temperature 3 to be out of range")
+	}
+	if _, err := ParseOption("max_tokens", "1.5"); err == nil {
+		t.Error("expected max_tokens to take whole numbers only")
+	}
+	v, err := ParseOption("temperature", "0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm.SetOption("telegram:1", "temperature", v)
+	sm.SetOption("telegram:1", "max_tokens", 2048)
+	if dropped := sm.ResetOptions("telegram:1", "max_tokens"); len(dropped) != 1 {
+		t.Errorf("expected max_tokens to be reset, got %v", dropped)
+	}
+	sm.Close()
+
+	sm, err = NewSQLiteSessionManager(dbPath, "")
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer sm.Close()
+	if got := sm.GetOptions("telegram:1"); len(got) != 1 || got["temperature"] != 0.2 {
+		t.Errorf("expected temperature 0.2 after reopen, got %v", got)
+	}
+}
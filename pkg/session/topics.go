@@ -0,0 +1,118 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// topicSep joins a chat's session key and a topic name: "telegram:123#project-x".
+const topicSep = "#"
+
+var topicNameRe = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}_.-]{0,39}$`)
+
+// Topic is a named conversation thread within one chat.
+type Topic struct {
+	Name     string    `json:"name"`
+	Messages int       `json:"messages"`
+	Updated  time.Time `json:"updated"`
+	Active   bool      `json:"active"`
+}
+
+// TopicKey returns the session key of a topic in the chat; "" is the
+// chat's main conversation.
+func TopicKey(chatKey, topic string) string {
+	if topic == "" {
+		return chatKey
+	}
+	return chatKey + topicSep + topic
+}
+
+// SplitTopic splits a session key into the chat's key and the topic, if any.
+func SplitTopic(key string) (chatKey, topic string) {
+	chatKey, topic, _ = strings.Cut(key, topicSep)
+	return chatKey, topic
+}
+
+// NormalizeTopic validates a topic name and lowercases it. "main" is the
+// chat's main conversation and normalizes to "".
+func NormalizeTopic(name string) (string, error) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), topicSep))
+	if name == "main" || name == "" {
+		return "", nil
+	}
+	if !topicNameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid topic name %q: use up to 40 letters, digits, '-', '_' or '.'", name)
+	}
+	return name, nil
+}
+
+// ActiveKey returns the session key the next message in the chat goes to:
+// the chat's active topic, or key itself when no topic is active. Keys that
+// already name a topic are returned as they are.
+func (sm *SessionManager) ActiveKey(key string) string {
+	if strings.Contains(key, topicSep) {
+		return key
+	}
+	return TopicKey(key, sm.ActiveTopic(key))
+}
+
+// ActiveTopic returns the chat's active topic, or "" for the main conversation.
+func (sm *SessionManager) ActiveTopic(chatKey string) string {
+	sm.ensureLoaded(chatKey)
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[chatKey]
+	if !ok {
+		return ""
+	}
+	return session.Topic
+}
+
+// SwitchTopic makes topic the chat's active topic ("" = main conversation),
+// creating its session if needed. Returns whether the topic is new.
+func (sm *SessionManager) SwitchTopic(chatKey, topic string) bool {
+	created := false
+	if topic != "" {
+		key := TopicKey(chatKey, topic)
+		if _, ok := sm.Get(key); !ok {
+			sm.Save(sm.GetOrCreate(key))
+			created = true
+		}
+	}
+
+	session := sm.GetOrCreate(chatKey)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session.Topic = topic
+	session.Updated = time.Now()
+	sm.persist(session)
+	return created
+}
+
+// Topics lists the chat's topics, most recently used first.
+func (sm *SessionManager) Topics(chatKey string) []Topic {
+	active := sm.ActiveTopic(chatKey)
+	prefix := chatKey + topicSep
+
+	var topics []Topic
+	for _, key := range sm.ListKeys() {
+		name, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		session, ok := sm.Get(key)
+		if !ok {
+			continue
+		}
+		topics = append(topics, Topic{Name: name, Messages: len(session.Messages), Updated: session.Updated, Active: name == active})
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Updated.After(topics[j].Updated) })
+	return topics
+}
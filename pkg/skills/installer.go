@@ -89,6 +89,14 @@ func (si *SkillInstaller) Uninstall(skillName string) error {
 		return fmt.Errorf("failed to remove skill: %w", err)
 	}
 
+	lock, err := si.readLock()
+	if err != nil {
+		return err
+	}
+	if _, ok := lock[skillName]; ok {
+		delete(lock, skillName)
+		return si.writeLock(lock)
+	}
 	return nil
 }
 
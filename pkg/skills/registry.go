@@ -0,0 +1,446 @@
+package skills
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRegistry is the GitHub repository holding the community index.
+	DefaultRegistry = "sipeed/mclaw-skills"
+
+	lockFileName     = "skills-lock.json"
+	maxSkillFileSize = 1 << 20
+)
+
+// skillNamePattern keeps a skill name usable as a single directory name.
+var skillNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// RegistryIndex is the index.json published at the root of a skill registry
+// repository. Every version lists its files with a SHA-256 checksum so an
+// install can be verified before anything is written to the workspace.
+//
+// The checksums come from the same index as the download URLs, so they catch
+// truncated or corrupted downloads but not a compromised registry: whoever
+// can change a file can change its checksum too. Only install from
+// registries you trust.
+type RegistryIndex struct {
+	Skills []RegistrySkill `json:"skills"`
+}
+
+type RegistrySkill struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Author      string            `json:"author"`
+	Tags        []string          `json:"tags"`
+	Versions    []RegistryVersion `json:"versions"`
+}
+
+type RegistryVersion struct {
+	Version string         `json:"version"`
+	Files   []RegistryFile `json:"files"`
+}
+
+type RegistryFile struct {
+	Path   string `json:"path"` // relative to the skill directory, e.g. SKILL.md
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// LockEntry records a skill installed from a registry.
+type LockEntry struct {
+	Version     string            `json:"version"`
+	Registry    string            `json:"registry"`
+	Pinned      bool              `json:"pinned"`
+	InstalledAt time.Time         `json:"installed_at"`
+	Files       map[string]string `json:"files"` // path -> sha256
+}
+
+// UpgradeResult describes what Upgrade did for one skill.
+type UpgradeResult struct {
+	Name    string
+	From    string
+	To      string // empty when nothing was installed
+	Skipped string // reason the skill was left alone
+	Err     error
+}
+
+// Find returns the named skill or nil.
+func (idx *RegistryIndex) Find(name string) *RegistrySkill {
+	for i := range idx.Skills {
+		if strings.EqualFold(idx.Skills[i].Name, name) {
+			return &idx.Skills[i]
+		}
+	}
+	return nil
+}
+
+// Search returns skills whose name, description or tags contain keyword.
+func (idx *RegistryIndex) Search(keyword string) []RegistrySkill {
+	keyword = strings.ToLower(keyword)
+	var out []RegistrySkill
+	for _, s := range idx.Skills {
+		text := strings.ToLower(s.Name + " " + s.Description + " " + strings.Join(s.Tags, " "))
+		if strings.Contains(text, keyword) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Latest returns the highest version, or nil if none are published.
+func (s *RegistrySkill) Latest() *RegistryVersion {
+	var latest *RegistryVersion
+	for i := range s.Versions {
+		if latest == nil || CompareVersions(s.Versions[i].Version, latest.Version) > 0 {
+			latest = &s.Versions[i]
+		}
+	}
+	return latest
+}
+
+// Version returns the given version, or nil.
+func (s *RegistrySkill) Version(v string) *RegistryVersion {
+	v = strings.TrimPrefix(v, "v")
+	for i := range s.Versions {
+		if strings.TrimPrefix(s.Versions[i].Version, "v") == v {
+			return &s.Versions[i]
+		}
+	}
+	return nil
+}
+
+// CompareVersions compares dotted versions numerically ("1.10.0" > "1.9.2").
+// A leading "v" is ignored and missing parts count as zero.
+func CompareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		if x == "" {
+			nx, errX = 0, nil
+		}
+		if y == "" {
+			ny, errY = 0, nil
+		}
+		switch {
+		case errX == nil && errY == nil && nx != ny:
+			if nx < ny {
+				return -1
+			}
+			return 1
+		case (errX != nil || errY != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+// registryIndexURL accepts a GitHub "owner/repo" or a full index URL.
+func registryIndexURL(registry string) string {
+	if registry == "" {
+		registry = DefaultRegistry
+	}
+	if strings.HasPrefix(registry, "http://") || strings.HasPrefix(registry, "https://") {
+		return registry
+	}
+	return fmt.Sprintf("https://raw.githubusercontent.com/%s/main/index.json", registry)
+}
+
+// FetchIndex downloads and parses a registry index.
+func (si *SkillInstaller) FetchIndex(ctx context.Context, registry string) (*RegistryIndex, error) {
+	body, err := fetch(ctx, registryIndexURL(registry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch skill index: %w", err)
+	}
+	var idx RegistryIndex
+	if err := json.Unmarshal(body, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse skill index: %w", err)
+	}
+	return &idx, nil
+}
+
+// InstallFromRegistry installs a skill from the registry index. An empty
+// version installs the latest; an explicit version is pinned so Upgrade
+// leaves it alone. Reinstalling a registry skill replaces it in place.
+func (si *SkillInstaller) InstallFromRegistry(ctx context.Context, registry, name, version string) (*LockEntry, error) {
+	idx, err := si.FetchIndex(ctx, registry)
+	if err != nil {
+		return nil, err
+	}
+	skill := idx.Find(name)
+	if skill == nil {
+		return nil, fmt.Errorf("skill '%s' not found in registry", name)
+	}
+
+	lock, err := si.readLock()
+	if err != nil {
+		return nil, err
+	}
+	if _, managed := lock[skill.Name]; !managed {
+		if _, err := os.Stat(filepath.Join(si.workspace, "skills", skill.Name)); err == nil {
+			return nil, fmt.Errorf("skill '%s' already exists and was not installed from a registry", skill.Name)
+		}
+	}
+
+	var v *RegistryVersion
+	if version == "" {
+		v = skill.Latest()
+	} else {
+		v = skill.Version(version)
+	}
+	if v == nil {
+		return nil, fmt.Errorf("version '%s' of skill '%s' not found", version, skill.Name)
+	}
+
+	entry, err := si.installVersion(ctx, skill.Name, v)
+	if err != nil {
+		return nil, err
+	}
+	entry.Registry = registry
+	entry.Pinned = version != ""
+	lock[skill.Name] = *entry
+	if err := si.writeLock(lock); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Upgrade moves registry-installed skills to their latest version. With no
+// names it considers every skill in the lock file. Pinned skills are skipped.
+func (si *SkillInstaller) Upgrade(ctx context.Context, registry string, names []string) ([]UpgradeResult, error) {
+	lock, err := si.readLock()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		for name := range lock {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	indexes := make(map[string]*RegistryIndex)
+	var results []UpgradeResult
+	for _, name := range names {
+		entry, ok := lock[name]
+		if !ok {
+			results = append(results, UpgradeResult{Name: name, Skipped: "not installed from a registry"})
+			continue
+		}
+		res := UpgradeResult{Name: name, From: entry.Version}
+		if entry.Pinned {
+			res.Skipped = "pinned"
+			results = append(results, res)
+			continue
+		}
+
+		source := entry.Registry
+		if source == "" {
+			source = registry
+		}
+		idx, ok := indexes[source]
+		if !ok {
+			if idx, err = si.FetchIndex(ctx, source); err != nil {
+				res.Err = err
+				results = append(results, res)
+				continue
+			}
+			indexes[source] = idx
+		}
+
+		skill := idx.Find(name)
+		var latest *RegistryVersion
+		if skill != nil {
+			latest = skill.Latest()
+		}
+		switch {
+		case latest == nil:
+			res.Skipped = "no longer in the registry"
+		case CompareVersions(latest.Version, entry.Version) <= 0:
+			res.Skipped = "up to date"
+		default:
+			updated, err := si.installVersion(ctx, name, latest)
+			if err != nil {
+				res.Err = err
+				break
+			}
+			updated.Registry = entry.Registry
+			lock[name] = *updated
+			res.To = updated.Version
+		}
+		results = append(results, res)
+	}
+
+	if err := si.writeLock(lock); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// SetPinned pins or unpins an installed registry skill at its current version.
+func (si *SkillInstaller) SetPinned(name string, pinned bool) error {
+	lock, err := si.readLock()
+	if err != nil {
+		return err
+	}
+	entry, ok := lock[name]
+	if !ok {
+		return fmt.Errorf("skill '%s' was not installed from a registry", name)
+	}
+	entry.Pinned = pinned
+	lock[name] = entry
+	return si.writeLock(lock)
+}
+
+// Installed returns the lock entries of registry-installed skills.
+func (si *SkillInstaller) Installed() (map[string]LockEntry, error) {
+	return si.readLock()
+}
+
+// installVersion downloads and verifies every file of a version into a
+// staging directory, then swaps it in for the current skill directory.
+func (si *SkillInstaller) installVersion(ctx context.Context, name string, v *RegistryVersion) (*LockEntry, error) {
+	if !skillNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid skill name in index: %q", name)
+	}
+	if len(v.Files) == 0 {
+		return nil, fmt.Errorf("version %s of skill '%s' lists no files", v.Version, name)
+	}
+	skillsDir := filepath.Join(si.workspace, "skills")
+	if err := os.MkdirAll(skillsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(skillsDir, "."+name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	entry := &LockEntry{Version: v.Version, InstalledAt: time.Now(), Files: make(map[string]string)}
+	hasSkillFile := false
+	for _, f := range v.Files {
+		rel := path.Clean(f.Path)
+		if rel == "." || path.IsAbs(rel) || strings.HasPrefix(rel, "../") || rel == ".." || strings.ContainsAny(rel, `\:`) {
+			return nil, fmt.Errorf("invalid file path in index: %q", f.Path)
+		}
+		if f.SHA256 == "" {
+			return nil, fmt.Errorf("%s has no checksum in the index", rel)
+		}
+		data, err := fetch(ctx, f.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", rel, err)
+		}
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, f.SHA256) {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", rel, f.SHA256, got)
+		}
+
+		dest := filepath.Join(staging, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		entry.Files[rel] = strings.ToLower(f.SHA256)
+		hasSkillFile = hasSkillFile || rel == "SKILL.md"
+	}
+	if !hasSkillFile {
+		return nil, fmt.Errorf("version %s of skill '%s' has no SKILL.md", v.Version, name)
+	}
+
+	skillDir := filepath.Join(skillsDir, name)
+	backup := skillDir + ".old"
+	os.RemoveAll(backup)
+	if _, err := os.Stat(skillDir); err == nil {
+		if err := os.Rename(skillDir, backup); err != nil {
+			return nil, fmt.Errorf("failed to replace skill: %w", err)
+		}
+	}
+	if err := os.Rename(staging, skillDir); err != nil {
+		os.Rename(backup, skillDir)
+		return nil, fmt.Errorf("failed to install skill: %w", err)
+	}
+	os.RemoveAll(backup)
+	return entry, nil
+}
+
+func (si *SkillInstaller) lockPath() string {
+	return filepath.Join(si.workspace, "skills", lockFileName)
+}
+
+func (si *SkillInstaller) readLock() (map[string]LockEntry, error) {
+	lock := make(map[string]LockEntry)
+	data, err := os.ReadFile(si.lockPath())
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", lockFileName, err)
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", lockFileName, err)
+	}
+	return lock, nil
+}
+
+func (si *SkillInstaller) writeLock(lock map[string]LockEntry) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(si.lockPath()), 0755); err != nil {
+		return err
+	}
+	tmp := si.lockPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockFileName, err)
+	}
+	return os.Rename(tmp, si.lockPath())
+}
+
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSkillFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSkillFileSize {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxSkillFileSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,168 @@
+package skills
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRegistry serves a skill index and the files it lists.
+type testRegistry struct {
+	srv   *httptest.Server
+	files map[string]string // URL path -> body
+	index RegistryIndex
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+	r := &testRegistry{files: make(map[string]string)}
+	r.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/index.json" {
+			json.NewEncoder(w).Encode(r.index)
+			return
+		}
+		body, ok := r.files[req.URL.Path]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(r.srv.Close)
+	return r
+}
+
+func (r *testRegistry) url() string {
+	return r.srv.URL + "/index.json"
+}
+
+// add publishes a skill version whose files map index paths to contents.
+func (r *testRegistry) add(name, version string, files map[string]string) {
+	v := RegistryVersion{Version: version}
+	for p, body := range files {
+		urlPath := "/" + name + "/" + version + "/" + strings.ReplaceAll(p, "/", "_")
+		r.files[urlPath] = body
+		sum := sha256.Sum256([]byte(body))
+		v.Files = append(v.Files, RegistryFile{Path: p, URL: r.srv.URL + urlPath, SHA256: hex.EncodeToString(sum[:])})
+	}
+	if s := r.index.Find(name); s != nil {
+		s.Versions = append(s.Versions, v)
+		return
+	}
+	r.index.Skills = append(r.index.Skills, RegistrySkill{Name: name, Versions: []RegistryVersion{v}})
+}
+
+func TestInstallFromRegistry(t *testing.T) {
+	reg := newTestRegistry(t)
+	reg.add("weather", "1.0.0", map[string]string{"SKILL.md": "# Weather", "scripts/fetch.sh": "echo sunny"})
+	workspace := t.TempDir()
+	si := NewSkillInstaller(workspace)
+
+	entry, err := si.InstallFromRegistry(context.Background(), reg.url(), "weather", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Version != "1.0.0" || entry.Pinned || len(entry.Files) != 2 {
+		t.Errorf("unexpected lock entry %+v", entry)
+	}
+	data, err := os.ReadFile(filepath.Join(workspace, "skills", "weather", "scripts", "fetch.sh"))
+	if err != nil || string(data) != "echo sunny" {
+		t.Errorf("expected the script installed, got %q, %v", data, err)
+	}
+	lock, _ := si.Installed()
+	if lock["weather"].Registry != reg.url() {
+		t.Errorf("expected the registry recorded in the lock file, got %+v", lock)
+	}
+}
+
+func TestInstallRejectsChecksumMismatch(t *testing.T) {
+	reg := newTestRegistry(t)
+	reg.add("weather", "1.0.0", map[string]string{"SKILL.md": "# Weather v1"})
+	reg.add("weather", "2.0.0", map[string]string{"SKILL.md": "# Weather v2"})
+	workspace := t.TempDir()
+	si := NewSkillInstaller(workspace)
+	if _, err := si.InstallFromRegistry(context.Background(), reg.url(), "weather", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	si.SetPinned("weather", false)
+
+	// The download no longer matches what the index promised
+	for p := range reg.files {
+		if strings.HasPrefix(p, "/weather/2.0.0/") {
+			reg.files[p] = "# Weather v2, truncated"
+		}
+	}
+
+	results, err := si.Upgrade(context.Background(), reg.url(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "checksum mismatch for SKILL.md") {
+		t.Fatalf("expected a checksum mismatch, got %+v", results)
+	}
+
+	// The installed version is left as it was, with no staging left behind
+	data, _ := os.ReadFile(filepath.Join(workspace, "skills", "weather", "SKILL.md"))
+	if string(data) != "# Weather v1" {
+		t.Errorf("expected the old version kept, got %q", data)
+	}
+	entries, _ := os.ReadDir(filepath.Join(workspace, "skills"))
+	for _, e := range entries {
+		if e.Name() != "weather" && e.Name() != lockFileName {
+			t.Errorf("expected no leftovers, found %s", e.Name())
+		}
+	}
+	if lock, _ := si.Installed(); lock["weather"].Version != "1.0.0" {
+		t.Errorf("expected the lock file unchanged, got %+v", lock["weather"])
+	}
+}
+
+func TestInstallRejectsInvalidSkillNames(t *testing.T) {
+	for _, name := range []string{"../escape", "a/b", `a\b`, ".hidden", "..", "-flag"} {
+		t.Run(name, func(t *testing.T) {
+			reg := newTestRegistry(t)
+			reg.add(name, "1.0.0", map[string]string{"SKILL.md": "# Bad"})
+			root := t.TempDir()
+			workspace := filepath.Join(root, "workspace")
+
+			_, err := NewSkillInstaller(workspace).InstallFromRegistry(context.Background(), reg.url(), name, "")
+			if err == nil || !strings.Contains(err.Error(), "invalid skill name") {
+				t.Fatalf("expected an invalid name error, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(root, "escape")); !os.IsNotExist(err) {
+				t.Errorf("expected nothing written outside the skills directory")
+			}
+		})
+	}
+}
+
+func TestInstallRejectsEscapingPaths(t *testing.T) {
+	for _, p := range []string{"../escape.md", "../../escape.md", "docs/../../escape.md", "/tmp/escape.md", `..\escape.md`, "C:escape.md", ".", ""} {
+		t.Run(p, func(t *testing.T) {
+			reg := newTestRegistry(t)
+			reg.add("weather", "1.0.0", map[string]string{"SKILL.md": "# Weather", p: "pwned"})
+			root := t.TempDir()
+			workspace := filepath.Join(root, "workspace")
+
+			_, err := NewSkillInstaller(workspace).InstallFromRegistry(context.Background(), reg.url(), "weather", "")
+			if err == nil || !strings.Contains(err.Error(), "invalid file path") {
+				t.Fatalf("expected an invalid path error, got %v", err)
+			}
+			for _, dir := range []string{root, workspace, filepath.Join(workspace, "skills")} {
+				if _, err := os.Stat(filepath.Join(dir, "escape.md")); !os.IsNotExist(err) {
+					t.Errorf("expected nothing written to %s", dir)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(workspace, "skills", "weather")); !os.IsNotExist(err) {
+				t.Errorf("expected the skill not installed")
+			}
+		})
+	}
+}
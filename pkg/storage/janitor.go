@@ -0,0 +1,259 @@
+// Package storage keeps the directories mclaw writes to as it runs
+// (downloaded media, the workspace inbox, logs) within size and age
+// limits, so a long-running bot does not slowly fill the disk.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// grace protects files written moments ago, e.g. a photo the agent is
+// about to read, from the size limit.
+const grace = 10 * time.Minute
+
+// Target is a directory and the limits it is held to.
+type Target struct {
+	Name     string
+	Dir      string
+	MaxBytes int64         // 0 = no size limit
+	MaxAge   time.Duration // 0 = no age limit
+	KeepTail bool          // shorten oversized files to their end instead of deleting them (logs still being written)
+}
+
+// Result reports what cleaning one target did.
+type Result struct {
+	Target    string
+	Removed   int
+	Truncated int
+	Freed     int64
+	Used      int64 // bytes left
+}
+
+// MediaDir is where channels download attachments before handing them on.
+func MediaDir() string {
+	return filepath.Join(os.TempDir(), "mclaw_media")
+}
+
+// Targets returns the directories covered by cfg.Storage.Limits.
+func Targets(cfg *config.Config) []Target {
+	limits := cfg.Storage.Limits
+	base := filepath.Dir(cfg.WorkspacePath())
+	return []Target{
+		target("media", MediaDir(), limits.Media, false),
+		target("inbox", filepath.Join(cfg.WorkspacePath(), "inbox"), limits.Inbox, false),
+		target("logs", filepath.Join(base, "logs"), limits.Logs, true),
+	}
+}
+
+func target(name, dir string, limit config.StorageLimit, keepTail bool) Target {
+	return Target{
+		Name:     name,
+		Dir:      dir,
+		MaxBytes: int64(limit.MaxMB) << 20,
+		MaxAge:   time.Duration(limit.MaxAgeDays) * 24 * time.Hour,
+		KeepTail: keepTail,
+	}
+}
+
+// Janitor enforces the limits of its targets.
+type Janitor struct {
+	targets []Target
+}
+
+// NewJanitor returns a janitor for targets. Targets without limits are
+// left alone.
+func NewJanitor(targets []Target) *Janitor {
+	j := &Janitor{}
+	for _, t := range targets {
+		if t.Dir != "" && (t.MaxBytes > 0 || t.MaxAge > 0) {
+			j.targets = append(j.targets, t)
+		}
+	}
+	return j
+}
+
+// Run cleans every interval until ctx is cancelled.
+func (j *Janitor) Run(ctx context.Context, interval time.Duration) {
+	if len(j.targets) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, r := range j.Clean(time.Now()) {
+			if r.Removed > 0 || r.Truncated > 0 {
+				log.Printf("[storage] %s: removed %d file(s), shortened %d, freed %s, %s in use",
+					r.Target, r.Removed, r.Truncated, FormatBytes(r.Freed), FormatBytes(r.Used))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Clean applies the limits once.
+func (j *Janitor) Clean(now time.Time) []Result {
+	results := make([]Result, 0, len(j.targets))
+	for _, t := range j.targets {
+		r, err := clean(t, now)
+		if err != nil {
+			log.Printf("[storage] Cleaning %s failed: %v", t.Name, err)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+type file struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// clean deletes files past the age limit, then the oldest files until the
+// target fits its size limit. Hidden files and directories are skipped, so
+// the inbox's metadata survives.
+func clean(t Target, now time.Time) (Result, error) {
+	r := Result{Target: t.Name}
+	files, err := scan(t.Dir)
+	if err != nil {
+		return r, err
+	}
+
+	var kept []file
+	for _, f := range files {
+		if t.MaxAge > 0 && now.Sub(f.modTime) > t.MaxAge && r.remove(f) {
+			continue
+		}
+		kept = append(kept, f)
+		r.Used += f.size
+	}
+
+	if t.MaxBytes <= 0 || r.Used <= t.MaxBytes {
+		return r, nil
+	}
+	if t.KeepTail {
+		for i, f := range kept {
+			if f.size > t.MaxBytes/2 {
+				if size, err := keepTail(f.path, t.MaxBytes/4); err == nil {
+					r.Truncated++
+					r.Freed += f.size - size
+					r.Used -= f.size - size
+					kept[i].size = size
+				}
+			}
+		}
+	}
+	sort.Slice(kept, func(a, b int) bool { return kept[a].modTime.Before(kept[b].modTime) })
+	for _, f := range kept {
+		if r.Used <= t.MaxBytes {
+			break
+		}
+		if now.Sub(f.modTime) < grace {
+			continue
+		}
+		if r.remove(f) {
+			r.Used -= f.size
+		}
+	}
+	return r, nil
+}
+
+func (r *Result) remove(f file) bool {
+	if err := os.Remove(f.path); err != nil {
+		log.Printf("[storage] Failed to remove %s: %v", f.path, err)
+		return false
+	}
+	r.Removed++
+	r.Freed += f.size
+	return true
+}
+
+func scan(dir string) ([]file, error) {
+	var files []file
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return nil // unreadable entries are left alone
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	return files, err
+}
+
+// keepTail shortens the file at path to about its last n bytes, starting at
+// a line boundary, and returns the new size. Writers that append keep
+// working.
+func keepTail(path string, n int64) (int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() <= n {
+		return info.Size(), nil
+	}
+	tail := make([]byte, n)
+	if _, err := f.ReadAt(tail, info.Size()-n); err != nil && err != io.EOF {
+		return 0, err
+	}
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	if err := f.Truncate(0); err != nil {
+		return 0, err
+	}
+	if _, err := f.WriteAt(tail, 0); err != nil {
+		return 0, err
+	}
+	return int64(len(tail)), nil
+}
+
+// FormatBytes renders a size as "512 B", "3.4 MB"...
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mod := time.Now().Add(-age)
+	os.Chtimes(path, mod, mod)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestCleanAgeAndSize(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "ancient.jpg"), 10, 10*24*time.Hour)
+	writeFile(t, filepath.Join(dir, "old.ogg"), 600, 2*time.Hour)
+	writeFile(t, filepath.Join(dir, "sub", "older.pdf"), 600, 3*time.Hour)
+	writeFile(t, filepath.Join(dir, "fresh.jpg"), 600, time.Minute)
+	writeFile(t, filepath.Join(dir, ".meta", "fresh.jpg.json"), 5000, 10*24*time.Hour)
+
+	j := NewJanitor([]Target{{Name: "media", Dir: dir, MaxBytes: 1000, MaxAge: 7 * 24 * time.Hour}})
+	results := j.Clean(time.Now())
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	r := results[0]
+
+	// The ancient file ages out; the oldest others go until under 1000 bytes,
+	// except the one written moments ago
+	if exists(filepath.Join(dir, "ancient.jpg")) || exists(filepath.Join(dir, "sub", "older.pdf")) || exists(filepath.Join(dir, "old.ogg")) {
+		t.Error("expected old files to be removed")
+	}
+	if !exists(filepath.Join(dir, "fresh.jpg")) {
+		t.Error("expected the fresh file to be kept")
+	}
+	if !exists(filepath.Join(dir, ".meta", "fresh.jpg.json")) {
+		t.Error("expected hidden files to be left alone")
+	}
+	if r.Removed != 3 || r.Freed != 1210 || r.Used != 600 {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
+
+func TestCleanKeepsLogTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mclaw.log")
+	var sb strings.Builder
+	for i := 0; i < 200; i++ {
+		sb.WriteString("line of log output\n")
+	}
+	sb.WriteString("last line\n")
+	os.WriteFile(path, []byte(sb.String()), 0644)
+
+	j := NewJanitor([]Target{{Name: "logs", Dir: dir, MaxBytes: 1000, KeepTail: true}})
+	r := j.Clean(time.Now())[0]
+	data, _ := os.ReadFile(path)
+	if r.Truncated != 1 || len(data) > 250 || !strings.HasSuffix(string(data), "last line\n") || !strings.HasPrefix(string(data), "line") {
+		t.Errorf("expected the log cut to its latest whole lines, got %d bytes (%+v)", len(data), r)
+	}
+}
+
+func TestNoLimitsNoTargets(t *testing.T) {
+	if j := NewJanitor([]Target{{Name: "inbox", Dir: t.TempDir()}}); len(j.targets) != 0 {
+		t.Error("expected a target without limits to be skipped")
+	}
+	missing := NewJanitor([]Target{{Name: "media", Dir: filepath.Join(t.TempDir(), "nope"), MaxAge: time.Hour}})
+	if r := missing.Clean(time.Now()); r[0].Removed != 0 {
+		t.Errorf("unexpected result for a missing dir: %+v", r)
+	}
+}
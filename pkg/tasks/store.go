@@ -0,0 +1,314 @@
+// Package tasks stores to-do items per conversation in SQLite.
+package tasks
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Priorities in ascending order of urgency.
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+// Task is one to-do item. Owner is the session key of the conversation the
+// task was added in, which is also where reminders are delivered.
+type Task struct {
+	ID          int64
+	Owner       string
+	Title       string
+	Notes       string
+	Priority    string
+	Due         time.Time // zero = no due date
+	Done        bool
+	CreatedAt   time.Time
+	CompletedAt time.Time
+}
+
+// HasDue reports whether the task has a due date.
+func (t *Task) HasDue() bool { return !t.Due.IsZero() }
+
+// Overdue reports whether the task is open and past its due date.
+func (t *Task) Overdue(now time.Time) bool {
+	return !t.Done && t.HasDue() && t.Due.Before(now)
+}
+
+// Store persists tasks in SQLite.
+type Store struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// NewStore creates or opens the tasks table in the database at dbPath.
+func NewStore(dbPath string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tasks database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate tasks database: %w", err)
+	}
+
+	log.Printf("[tasks] Store initialized at %s", dbPath)
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+	CREATE TABLE IF NOT EXISTS tasks (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner        TEXT NOT NULL,
+		title        TEXT NOT NULL,
+		notes        TEXT NOT NULL DEFAULT '',
+		priority     TEXT NOT NULL DEFAULT 'normal',
+		due_at       INTEGER NOT NULL DEFAULT 0,
+		done         INTEGER NOT NULL DEFAULT 0,
+		created_at   INTEGER NOT NULL,
+		completed_at INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_tasks_owner ON tasks(owner, done);
+	CREATE INDEX IF NOT EXISTS idx_tasks_due ON tasks(done, due_at);
+	`)
+	return err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// NormalizePriority maps user input to a known priority.
+func NormalizePriority(p string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(p)) {
+	case "", PriorityNormal, "medium":
+		return PriorityNormal, nil
+	case PriorityLow:
+		return PriorityLow, nil
+	case PriorityHigh, "urgent":
+		return PriorityHigh, nil
+	default:
+		return "", fmt.Errorf("unknown priority %q (use low, normal or high)", p)
+	}
+}
+
+// Add inserts a task and sets its ID.
+func (s *Store) Add(task *Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task.Priority == "" {
+		task.Priority = PriorityNormal
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+
+	res, err := s.db.Exec(`INSERT INTO tasks (owner, title, notes, priority, due_at, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		task.Owner, task.Title, task.Notes, task.Priority, unix(task.Due), task.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to add task: %w", err)
+	}
+	task.ID, err = res.LastInsertId()
+	return err
+}
+
+// Get returns one of owner's tasks.
+func (s *Store) Get(owner string, id int64) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks, err := s.query(`WHERE owner = ? AND id = ?`, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task #%d not found", id)
+	}
+	return tasks[0], nil
+}
+
+// List returns owner's tasks: open ones first, by due date then priority.
+func (s *Store) List(owner string, includeDone bool) ([]*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	where := `WHERE owner = ?`
+	if !includeDone {
+		where += ` AND done = 0`
+	}
+	return s.query(where+` ORDER BY done, due_at = 0, due_at, `+priorityOrder+`, id`, owner)
+}
+
+// Due returns open tasks of all owners that are due before the given time.
+func (s *Store) Due(before time.Time) ([]*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.query(`WHERE done = 0 AND due_at > 0 AND due_at < ? ORDER BY due_at, `+priorityOrder, before.Unix())
+}
+
+// Update saves the title, notes, priority and due date of a task.
+func (s *Store) Update(task *Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`UPDATE tasks SET title = ?, notes = ?, priority = ?, due_at = ? WHERE owner = ? AND id = ?`,
+		task.Title, task.Notes, task.Priority, unix(task.Due), task.Owner, task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	return requireRow(res, task.ID)
+}
+
+// Complete marks a task as done.
+func (s *Store) Complete(owner string, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`UPDATE tasks SET done = 1, completed_at = ? WHERE owner = ? AND id = ?`, time.Now().Unix(), owner, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete task: %w", err)
+	}
+	return requireRow(res, id)
+}
+
+// Delete removes a task.
+func (s *Store) Delete(owner string, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM tasks WHERE owner = ? AND id = ?`, owner, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	return requireRow(res, id)
+}
+
+const priorityOrder = `CASE priority WHEN 'high' THEN 0 WHEN 'normal' THEN 1 ELSE 2 END`
+
+// query runs a SELECT over tasks with the given clause. Caller must hold s.mu.
+func (s *Store) query(clause string, args ...interface{}) ([]*Task, error) {
+	rows, err := s.db.Query(`SELECT id, owner, title, notes, priority, due_at, done, created_at, completed_at FROM tasks `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		var t Task
+		var due, created, completed int64
+		if err := rows.Scan(&t.ID, &t.Owner, &t.Title, &t.Notes, &t.Priority, &due, &t.Done, &created, &completed); err != nil {
+			return nil, err
+		}
+		t.Due = fromUnix(due)
+		t.CreatedAt = fromUnix(created)
+		t.CompletedAt = fromUnix(completed)
+		tasks = append(tasks, &t)
+	}
+	return tasks, rows.Err()
+}
+
+func requireRow(res sql.Result, id int64) error {
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("task #%d not found", id)
+	}
+	return nil
+}
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func fromUnix(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// HeartbeatSection lists overdue tasks and tasks due within the next day
+// for the heartbeat prompt, or "" when nothing is due.
+func (s *Store) HeartbeatSection(now time.Time) string {
+	due, err := s.Due(now.Add(24 * time.Hour))
+	if err != nil {
+		log.Printf("[tasks] Failed to list due tasks: %v", err)
+		return ""
+	}
+	if len(due) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Due Tasks\n\n")
+	for _, t := range due {
+		state := "due " + t.Due.Format("2006-01-02 15:04")
+		if t.Overdue(now) {
+			state = "OVERDUE by " + FormatAge(now.Sub(t.Due))
+		}
+		fmt.Fprintf(&sb, "- #%d [%s] %s — %s (chat: %s)\n", t.ID, t.Priority, t.Title, state, t.Owner)
+	}
+	sb.WriteString("\nRemind the owner of each overdue task with the message tool (channel and chat_id come from the chat key \"channel:chat_id\"). Mention tasks due soon only if they are high priority.\n")
+	return sb.String()
+}
+
+// FormatAge renders a duration coarsely, e.g. "3h" or "2d".
+func FormatAge(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
+// ParseDue parses a due date: "today", "tomorrow", "2006-01-02",
+// "2006-01-02 15:04" or RFC 3339, in local time. A date without a time
+// is due at the end of that day.
+func ParseDue(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	endOfDay := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 0, 0, time.Local)
+	}
+
+	switch strings.ToLower(s) {
+	case "today":
+		return endOfDay(now), nil
+	case "tomorrow":
+		return endOfDay(now.AddDate(0, 0, 1)), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return endOfDay(t), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid due date %q (use YYYY-MM-DD, \"YYYY-MM-DD HH:MM\", today or tomorrow)", s)
+}
@@ -0,0 +1,70 @@
+package tasks
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreLifecycle(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "memory.db"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	overdue := &Task{Owner: "telegram:1", Title: "Pay rent", Priority: PriorityHigh, Due: now.Add(-2 * time.Hour)}
+	later := &Task{Owner: "telegram:1", Title: "Renew passport", Due: now.Add(30 * 24 * time.Hour)}
+	other := &Task{Owner: "telegram:2", Title: "Someone else's task"}
+	for _, task := range []*Task{overdue, later, other} {
+		if err := store.Add(task); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	list, err := store.List("telegram:1", false)
+	if err != nil || len(list) != 2 || list[0].ID != overdue.ID {
+		t.Fatalf("List = %v, %v; want 2 tasks, overdue first", list, err)
+	}
+
+	section := store.HeartbeatSection(now)
+	if !strings.Contains(section, "Pay rent") || !strings.Contains(section, "OVERDUE") {
+		t.Errorf("heartbeat section missing overdue task:\n%s", section)
+	}
+	if strings.Contains(section, "Renew passport") {
+		t.Errorf("heartbeat section lists a task due next month:\n%s", section)
+	}
+
+	if err := store.Complete("telegram:2", overdue.ID); err == nil {
+		t.Error("completing another chat's task should fail")
+	}
+	if err := store.Complete("telegram:1", overdue.ID); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if section := store.HeartbeatSection(now); section != "" {
+		t.Errorf("expected empty heartbeat section after completing, got:\n%s", section)
+	}
+
+	list, _ = store.List("telegram:1", true)
+	if len(list) != 2 || !list[1].Done {
+		t.Errorf("include_done list = %v", list)
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, time.Local)
+
+	due, err := ParseDue("tomorrow", now)
+	if err != nil || due.Day() != 11 || due.Hour() != 23 {
+		t.Errorf("tomorrow = %v, %v", due, err)
+	}
+	due, err = ParseDue("2026-04-01 09:30", now)
+	if err != nil || due.Month() != 4 || due.Hour() != 9 || due.Minute() != 30 {
+		t.Errorf("datetime = %v, %v", due, err)
+	}
+	if _, err := ParseDue("next blue moon", now); err == nil {
+		t.Error("expected error for unparseable date")
+	}
+}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+// Answers longer than this are cut before they are returned or merged.
+const maxModelAnswerChars = 4000
+
+// ModelClient is one model ask_models can consult.
+type ModelClient struct {
+	Name     string // what the user configured, e.g. an alias
+	Model    string
+	Provider providers.LLMProvider
+}
+
+// AskModelsTool sends the same prompt to several models at once and
+// returns their answers side by side, optionally merged into one by the
+// agent's own model. Useful for important decisions and fact checking.
+type AskModelsTool struct {
+	models  []ModelClient
+	merge   func(ctx context.Context, prompt string) (string, error)
+	timeout time.Duration
+}
+
+// NewAskModelsTool creates the tool. merge runs a prompt on the agent's
+// model; timeout bounds each model's answer.
+func NewAskModelsTool(models []ModelClient, merge func(ctx context.Context, prompt string) (string, error), timeout time.Duration) *AskModelsTool {
+	return &AskModelsTool{models: models, merge: merge, timeout: timeout}
+}
+
+func (t *AskModelsTool) Available() (bool, string) {
+	if len(t.models) < 2 {
+		return false, "fewer than two models configured in tools.ask_models.models"
+	}
+	return true, ""
+}
+
+func (t *AskModelsTool) Name() string { return "ask_models" }
+
+func (t *AskModelsTool) Description() string {
+	names := make([]string, len(t.models))
+	for i, m := range t.models {
+		names[i] = m.Name
+	}
+	return "Ask several other AI models the same question at once and compare their answers (" + strings.Join(names, ", ") + "). " +
+		"Use it for important decisions and fact checking, not for routine questions: it costs one call per model. " +
+		"Set merge to get a combined answer that points out where the models disagree."
+}
+
+func (t *AskModelsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"prompt": map[string]interface{}{
+				"type":        "string",
+				"description": "The question, with all the context the models need; they don't see this conversation",
+			},
+			"models": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Which of the configured models to ask (default: all)",
+			},
+			"merge": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also merge the answers into one, noting agreements and disagreements (default false)",
+			},
+		},
+		"required": []string{"prompt"},
+	}
+}
+
+func (t *AskModelsTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	prompt, _ := args["prompt"].(string)
+	if strings.TrimSpace(prompt) == "" {
+		return "", fmt.Errorf("prompt is required")
+	}
+	models, err := t.pick(args["models"])
+	if err != nil {
+		return "Error: " + err.Error(), nil
+	}
+
+	answers := make([]string, len(models))
+	errs := make([]error, len(models))
+	var wg sync.WaitGroup
+	for i, m := range models {
+		wg.Add(1)
+		go func(i int, m ModelClient) {
+			defer wg.Done()
+			answers[i], errs[i] = t.ask(ctx, m, prompt)
+		}(i, m)
+	}
+	wg.Wait()
+
+	var sb strings.Builder
+	answered := 0
+	for i, m := range models {
+		fmt.Fprintf(&sb, "## %s (%s)\n", m.Name, m.Model)
+		if errs[i] != nil {
+			fmt.Fprintf(&sb, "(no answer: %v)\n\n", errs[i])
+			continue
+		}
+		answered++
+		sb.WriteString(answers[i] + "\n\n")
+	}
+	result := strings.TrimSpace(sb.String())
+
+	if merge, _ := args["merge"].(bool); !merge || answered < 2 || t.merge == nil {
+		return result, nil
+	}
+	merged, err := t.merge(ctx, "Several AI models answered the same question. Merge their answers into one: "+
+		"state what they agree on, point out where they disagree and which answer is more likely right, and say how confident the combined answer is.\n\n"+
+		"QUESTION:\n"+prompt+"\n\nANSWERS:\n"+result)
+	if err != nil {
+		return result + "\n\n(Merging failed: " + err.Error() + ")", nil
+	}
+	return "## Merged answer\n" + strings.TrimSpace(merged) + "\n\n" + result, nil
+}
+
+// pick returns the configured models named in raw, or all of them.
+func (t *AskModelsTool) pick(raw interface{}) ([]ModelClient, error) {
+	list, _ := raw.([]interface{})
+	if len(list) == 0 {
+		return t.models, nil
+	}
+	var picked []ModelClient
+	for _, item := range list {
+		name, _ := item.(string)
+		found := false
+		for _, m := range t.models {
+			if m.Name == name || m.Model == name {
+				picked = append(picked, m)
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, len(t.models))
+			for i, m := range t.models {
+				names[i] = m.Name
+			}
+			return nil, fmt.Errorf("%q is not one of the configured models (%s)", name, strings.Join(names, ", "))
+		}
+	}
+	return picked, nil
+}
+
+func (t *AskModelsTool) ask(ctx context.Context, m ModelClient, prompt string) (string, error) {
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	resp, err := m.Provider.Chat(ctx, []providers.Message{{Role: "user", Content: prompt}}, nil, m.Model, map[string]interface{}{
+		"max_tokens":  2048,
+		"temperature": 0.3,
+	})
+	if err != nil {
+		return "", err
+	}
+	answer := strings.TrimSpace(resp.Content)
+	if answer == "" {
+		return "", fmt.Errorf("empty answer")
+	}
+	if len(answer) > maxModelAnswerChars {
+		answer = answer[:maxModelAnswerChars] + "\n... (cut)"
+	}
+	return answer, nil
+}
@@ -9,6 +9,13 @@ type Tool interface {
 	Execute(ctx context.Context, args map[string]interface{}) (string, error)
 }
 
+// AvailabilityChecker is implemented by tools whose backing dependency
+// (binary, API key, external service) may be missing. Unavailable tools are
+// hidden from the model; reason should name an alternative where possible.
+type AvailabilityChecker interface {
+	Available() (ok bool, reason string)
+}
+
 func ToolToSchema(tool Tool) map[string]interface{} {
 	return map[string]interface{}{
 		"type": "function",
@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/bookmarks"
+)
+
+// BookmarksTool keeps the read-later list of the current conversation.
+type BookmarksTool struct {
+	service *bookmarks.Service
+}
+
+func NewBookmarksTool(service *bookmarks.Service) *BookmarksTool {
+	return &BookmarksTool{service: service}
+}
+
+func (t *BookmarksTool) Name() string {
+	return "bookmarks"
+}
+
+func (t *BookmarksTool) Description() string {
+	return `Read-later list of links. When the user shares a link to keep ("save this", "read later"), save it with "add". Actions:
+- "add": Save a link. Requires: url. Optional: tags; title and summary if you already read the page (otherwise the page is fetched for them).
+- "list": Saved links, unread only unless all is true. Optional: tag, all, limit.
+- "search": Find links by words in the URL, title or summary. Requires: query. Optional: tag.
+- "read": Mark a link read (unread: true to undo). Requires: bookmark_id.
+- "tag": Change tags. Requires: bookmark_id. Optional: tags to add, remove_tags.
+- "remove": Delete a link. Requires: bookmark_id.`
+}
+
+func (t *BookmarksTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: add, list, search, read, tag, remove",
+				"enum":        []string{"add", "list", "search", "read", "tag", "remove"},
+			},
+			"url": map[string]interface{}{
+				"type":        "string",
+				"description": "Link to save",
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Page title, if already known",
+			},
+			"summary": map[string]interface{}{
+				"type":        "string",
+				"description": "One or two sentences on the page, if already known",
+			},
+			"tags": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Tags to add, e.g. [\"go\", \"recipes\"]",
+			},
+			"remove_tags": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Tags to remove (tag action)",
+			},
+			"tag": map[string]interface{}{
+				"type":        "string",
+				"description": "Only links with this tag (list, search)",
+			},
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Words to search for",
+			},
+			"all": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Include links already read (list)",
+			},
+			"unread": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Mark the link unread again (read action)",
+			},
+			"limit": map[string]interface{}{
+				"type":        "number",
+				"description": "Maximum links to list (default 20)",
+			},
+			"bookmark_id": map[string]interface{}{
+				"type":        "number",
+				"description": "Bookmark ID (required for read, tag and remove)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *BookmarksTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	owner := ScopeFrom(ctx).ChatKey()
+	if t.service == nil {
+		return "Error: bookmarks are disabled", nil
+	}
+	if owner == "" {
+		return "Error: bookmarks are only available in a conversation", nil
+	}
+
+	tag, _ := args["tag"].(string)
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		rawURL, _ := args["url"].(string)
+		if strings.TrimSpace(rawURL) == "" {
+			return "Error: 'url' is required for add", nil
+		}
+		title, _ := args["title"].(string)
+		summary, _ := args["summary"].(string)
+		b, note, err := t.service.Add(ctx, owner, rawURL, title, summary, bookmarkTags(args["tags"]))
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		result := "✓ Saved " + b.Describe()
+		if b.Summary != "" {
+			result += "\n" + b.Summary
+		}
+		if note != "" {
+			result += "\n(" + note + ")"
+		}
+		return result, nil
+
+	case "list", "search":
+		f := bookmarks.Filter{Tag: tag, Limit: 20}
+		if limit, ok := args["limit"].(float64); ok && limit > 0 {
+			f.Limit = int(limit)
+		}
+		if action == "search" {
+			f.Query, _ = args["query"].(string)
+			if strings.TrimSpace(f.Query) == "" {
+				return "Error: 'query' is required for search", nil
+			}
+		} else if all, _ := args["all"].(bool); !all {
+			f.Unread = true
+		}
+		list, err := t.service.List(owner, f)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if len(list) == 0 {
+			if f.Unread {
+				return "No unread links.", nil
+			}
+			return "No links found.", nil
+		}
+		var sb strings.Builder
+		heading := "Saved links"
+		if f.Unread {
+			heading = "Unread links"
+		}
+		fmt.Fprintf(&sb, "%s (%d):\n", heading, len(list))
+		for _, b := range list {
+			sb.WriteString(b.Describe() + "\n")
+			if b.Summary != "" {
+				sb.WriteString("  " + b.Summary + "\n")
+			}
+		}
+		return sb.String(), nil
+
+	case "read", "tag", "remove":
+		id, ok := args["bookmark_id"].(float64)
+		if !ok || id <= 0 {
+			return fmt.Sprintf("Error: 'bookmark_id' is required for %s", action), nil
+		}
+		var b *bookmarks.Bookmark
+		var err error
+		switch action {
+		case "read":
+			unread, _ := args["unread"].(bool)
+			b, err = t.service.MarkRead(owner, int64(id), !unread)
+		case "tag":
+			b, err = t.service.Tag(owner, int64(id), bookmarkTags(args["tags"]), bookmarkTags(args["remove_tags"]))
+		default:
+			b, err = t.service.Remove(owner, int64(id))
+		}
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if action == "remove" {
+			return "✓ Removed " + b.Describe(), nil
+		}
+		return "✓ " + b.Describe(), nil
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: add, list, search, read, tag, remove", action), nil
+	}
+}
+
+// bookmarkTags accepts tags as a list or as one comma-separated string.
+func bookmarkTags(raw interface{}) []string {
+	if s, ok := raw.(string); ok {
+		return strings.Split(s, ",")
+	}
+	return stringList(raw)
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// BroadcastTool sends one message to every chat of a recipient group
+// (config channels.recipients), e.g. an announcement to the family chats.
+type BroadcastTool struct {
+	bus    *bus.MessageBus
+	groups func() map[string][]string
+}
+
+// NewBroadcastTool creates the tool; groups returns the current recipient
+// groups, so config reloads apply.
+func NewBroadcastTool(mb *bus.MessageBus, groups func() map[string][]string) *BroadcastTool {
+	return &BroadcastTool{bus: mb, groups: groups}
+}
+
+func (t *BroadcastTool) Name() string {
+	return "broadcast"
+}
+
+func (t *BroadcastTool) Description() string {
+	return `Send a message to all chats of a named recipient group at once. Without a group, lists the groups. To schedule a broadcast, use send_later or cron with channel "group" and the group name as the chat.`
+}
+
+func (t *BroadcastTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"group": map[string]interface{}{
+				"type":        "string",
+				"description": "Recipient group name (omit to list groups)",
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "The message, sent as written",
+			},
+		},
+	}
+}
+
+func (t *BroadcastTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	groups := t.groups()
+	group, _ := args["group"].(string)
+	group = strings.TrimSpace(group)
+	if group == "" {
+		return t.list(groups), nil
+	}
+	members, ok := groups[group]
+	if !ok {
+		return fmt.Sprintf("Error: no recipient group %q. %s", group, t.list(groups)), nil
+	}
+	content, _ := args["content"].(string)
+	if strings.TrimSpace(content) == "" {
+		return "Error: 'content' is required", nil
+	}
+
+	t.bus.PublishOutbound(bus.OutboundMessage{Channel: bus.GroupChannel, ChatID: group, Content: content})
+	return fmt.Sprintf("✓ Sent to %d chats in %s", len(members), group), nil
+}
+
+func (t *BroadcastTool) list(groups map[string][]string) string {
+	if len(groups) == 0 {
+		return "No recipient groups configured (channels.recipients in config.json)."
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString("Recipient groups:\n")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "- %s: %s\n", name, strings.Join(groups[name], ", "))
+	}
+	return sb.String()
+}
@@ -2,19 +2,29 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
+	"github.com/ntminh611/mclaw/pkg/netguard"
 )
 
 // BrowserTool uses headless Chrome (chromedp) to fetch JS-rendered pages.
 type BrowserTool struct {
 	timeout         time.Duration
 	chromeAvailable bool
+	profiles        ExtractionProfiles
+	guard           *netguard.Guard
+	profileDir      string
+	profileMu       sync.Mutex // Chrome locks a profile to one process
 }
 
 func NewBrowserTool(timeout time.Duration) *BrowserTool {
@@ -44,7 +54,75 @@ func NewBrowserTool(timeout time.Duration) *BrowserTool {
 		log.Printf("[tools] Browser tool: Chrome/Chromium not found — browser tool disabled")
 	}
 
-	return &BrowserTool{timeout: timeout, chromeAvailable: available}
+	return &BrowserTool{timeout: timeout, chromeAvailable: available, guard: netguard.New(nil)}
+}
+
+// guardRequests pauses every request Chrome makes — redirects, subresources,
+// script fetches — and fails those headed for a blocked address. Requires
+// fetch.Enable() to run before navigating.
+func (t *BrowserTool) guardRequests(ctx context.Context) {
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		e, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		go func() {
+			c := chromedp.FromContext(ctx)
+			exec := cdp.WithExecutor(ctx, c.Target)
+			if err := t.guard.CheckURL(ctx, e.Request.URL); err != nil {
+				log.Printf("[tools] Browser blocked request: %v", err)
+				fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient).Do(exec)
+				return
+			}
+			fetch.ContinueRequest(e.RequestID).Do(exec)
+		}()
+	})
+}
+
+func mustJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// SetNetworkGuard replaces the guard that blocks requests to internal addresses.
+func (t *BrowserTool) SetNetworkGuard(g *netguard.Guard) {
+	t.guard = g
+}
+
+// SetProfileDir keeps cookies and logins in dir between invocations.
+// An empty dir uses a throwaway profile each time.
+func (t *BrowserTool) SetProfileDir(dir string) {
+	t.profileDir = dir
+}
+
+// BrowserOptions returns the Chrome allocator options shared by the browser
+// tool and `mclaw browser login`.
+func BrowserOptions(headless bool, profileDir string) []chromedp.ExecAllocatorOption {
+	opts := []chromedp.ExecAllocatorOption{
+		chromedp.NoFirstRun,
+		chromedp.NoDefaultBrowserCheck,
+		chromedp.Flag("headless", headless),
+		chromedp.Flag("disable-gpu", true),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.UserAgent("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"),
+	}
+	if profileDir != "" {
+		opts = append(opts, chromedp.UserDataDir(profileDir))
+	}
+	return opts
+}
+
+// SetProfiles sets per-domain extraction profiles.
+func (t *BrowserTool) SetProfiles(profiles ExtractionProfiles) {
+	t.profiles = profiles
+}
+
+func (t *BrowserTool) Available() (bool, string) {
+	if !t.chromeAvailable {
+		return false, "Chrome/Chromium is not installed; use web_fetch instead"
+	}
+	return true, ""
 }
 
 func (t *BrowserTool) Name() string {
@@ -95,8 +173,16 @@ func (t *BrowserTool) Execute(ctx context.Context, args map[string]interface{})
 	if !strings.HasPrefix(urlStr, "http://") && !strings.HasPrefix(urlStr, "https://") {
 		return "", fmt.Errorf("only http/https URLs are allowed")
 	}
+	if err := t.guard.CheckURL(ctx, urlStr); err != nil {
+		return "", err
+	}
+
+	profile, _ := t.profiles.match(urlStr)
 
 	waitSeconds := 2
+	if profile.WaitSeconds > 0 {
+		waitSeconds = profile.WaitSeconds
+	}
 	if ws, ok := args["wait_seconds"].(float64); ok {
 		waitSeconds = int(ws)
 		if waitSeconds > 10 {
@@ -107,14 +193,13 @@ func (t *BrowserTool) Execute(ctx context.Context, args map[string]interface{})
 		}
 	}
 
+	if t.profileDir != "" {
+		t.profileMu.Lock()
+		defer t.profileMu.Unlock()
+	}
+
 	// Create headless Chrome context with timeout
-	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx,
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.UserAgent("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"),
-	)
+	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx, BrowserOptions(true, t.profileDir)...)
 	defer allocCancel()
 
 	chromeCtx, chromeCancel := chromedp.NewContext(allocCtx)
@@ -123,15 +208,38 @@ func (t *BrowserTool) Execute(ctx context.Context, args map[string]interface{})
 	timeoutCtx, timeoutCancel := context.WithTimeout(chromeCtx, t.timeout)
 	defer timeoutCancel()
 
+	t.guardRequests(timeoutCtx)
+
 	var pageText string
 	var pageTitle string
 
-	err := chromedp.Run(timeoutCtx,
+	actions := []chromedp.Action{
+		fetch.Enable(),
 		chromedp.Navigate(urlStr),
-		chromedp.Sleep(time.Duration(waitSeconds)*time.Second),
+		chromedp.Sleep(time.Duration(waitSeconds) * time.Second),
 		chromedp.Title(&pageTitle),
-		chromedp.Text("body", &pageText, chromedp.ByQuery),
-	)
+	}
+	for _, sel := range profile.Remove {
+		actions = append(actions, chromedp.Evaluate(
+			fmt.Sprintf("document.querySelectorAll(%s).forEach(e => e.remove())", mustJSON(sel)), nil))
+	}
+	// A profile selector narrows extraction; fall back to body if it doesn't match
+	selector := "body"
+	if profile.Selector != "" {
+		var found bool
+		actions = append(actions, chromedp.Evaluate(
+			fmt.Sprintf("document.querySelector(%s) !== null", mustJSON(profile.Selector)), &found))
+		if err := chromedp.Run(timeoutCtx, actions...); err != nil {
+			return "", fmt.Errorf("browser failed: %w", err)
+		}
+		actions = nil
+		if found {
+			selector = profile.Selector
+		}
+	}
+	actions = append(actions, chromedp.Text(selector, &pageText, chromedp.ByQuery))
+
+	err := chromedp.Run(timeoutCtx, actions...)
 	if err != nil {
 		return "", fmt.Errorf("browser failed: %w", err)
 	}
@@ -0,0 +1,483 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+const (
+	codeRunMaxOutput    = 10000
+	codeRunMaxInputSize = 50 << 20
+	codeRunInstallTime  = 3 * time.Minute
+)
+
+var packageSpec = regexp.MustCompile(`^[A-Za-z0-9@][A-Za-z0-9._/@\[\],=<>~^-]*$`)
+
+// codeLanguage describes how to run one language.
+type codeLanguage struct {
+	file   string
+	binary []string // local interpreters, first found wins
+	image  func(cfg config.CodeRunConfig) string
+}
+
+var codeLanguages = map[string]codeLanguage{
+	"python": {
+		file:   "main.py",
+		binary: []string{"python3", "python"},
+		image:  func(cfg config.CodeRunConfig) string { return cfg.PythonImage },
+	},
+	"javascript": {
+		file:   "main.js",
+		binary: []string{"node"},
+		image:  func(cfg config.CodeRunConfig) string { return cfg.NodeImage },
+	},
+}
+
+// CodeRunTool runs Python or JavaScript snippets in a scratch directory,
+// inside a container (or directly on the host when configured "local"),
+// and returns their output together with any files they created.
+type CodeRunTool struct {
+	cfg     config.CodeRunConfig
+	runsDir string
+}
+
+func NewCodeRunTool(cfg config.CodeRunConfig, workspace string) *CodeRunTool {
+	if cfg.TimeoutSeconds <= 0 {
+		cfg.TimeoutSeconds = 60
+	}
+	if cfg.MemoryMB <= 0 {
+		cfg.MemoryMB = 512
+	}
+	if cfg.PythonImage == "" {
+		cfg.PythonImage = "python:3.12-slim"
+	}
+	if cfg.NodeImage == "" {
+		cfg.NodeImage = "node:22-slim"
+	}
+	// Docker bind mounts need an absolute path
+	runsDir, err := filepath.Abs(filepath.Join(workspace, "code_runs"))
+	if err != nil {
+		runsDir = filepath.Join(workspace, "code_runs")
+	}
+	if strings.EqualFold(cfg.Sandbox, "local") {
+		logger.WarnC("tools", "code_run sandbox is \"local\": model-written code runs on the host as the mclaw user, with its files and network")
+	}
+	return &CodeRunTool{cfg: cfg, runsDir: runsDir}
+}
+
+func (t *CodeRunTool) Available() (bool, string) {
+	if sandbox, reason := t.sandbox(); sandbox == "" {
+		return false, reason
+	}
+	return true, ""
+}
+
+// sandbox returns "docker" or "local", or "" and the reason nothing can run
+// code. "auto" only ever picks docker: falling back to the host would run
+// model-written code with mclaw's own files and network.
+func (t *CodeRunTool) sandbox() (string, string) {
+	_, dockerErr := exec.LookPath("docker")
+	switch strings.ToLower(t.cfg.Sandbox) {
+	case "local":
+		for _, lang := range codeLanguages {
+			if findBinary(lang.binary) != "" {
+				return "local", ""
+			}
+		}
+		return "", "neither python3 nor node is installed; use exec instead"
+	case "docker":
+		if dockerErr != nil {
+			return "", "docker is not installed (tools.code_run.sandbox is \"docker\")"
+		}
+	default:
+		if dockerErr != nil {
+			return "", "docker is not installed; install Docker, or set tools.code_run.sandbox to \"local\" to run code on the host without isolation"
+		}
+	}
+	return "docker", ""
+}
+
+func (t *CodeRunTool) Name() string {
+	return "code_run"
+}
+
+func (t *CodeRunTool) Description() string {
+	desc := `Run a Python or JavaScript program in a fresh scratch directory and return stdout, stderr and any files it writes (charts, CSVs, reports). Prefer this over exec for data analysis and calculations.
+Pass input files from the workspace with "files"; they are copied into the current directory under their base names. Save outputs to the current directory.`
+	// Only claim the isolation the configured mode actually gives
+	if sandbox, _ := t.sandbox(); sandbox == "local" {
+		desc += ` The program runs directly on the host, not in a sandbox: do not touch files outside the current directory.`
+	} else {
+		desc += ` The program runs in a container with no network access.`
+	}
+	if t.cfg.AllowPackages {
+		desc += ` Extra pip/npm packages can be listed in "packages" and are installed before the run.`
+	}
+	return desc
+}
+
+func (t *CodeRunTool) Parameters() map[string]interface{} {
+	props := map[string]interface{}{
+		"language": map[string]interface{}{
+			"type":        "string",
+			"description": "Programming language",
+			"enum":        []string{"python", "javascript"},
+		},
+		"code": map[string]interface{}{
+			"type":        "string",
+			"description": "Complete program source",
+		},
+		"files": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "Workspace files to copy into the sandbox as inputs",
+		},
+		"timeout_seconds": map[string]interface{}{
+			"type":        "number",
+			"description": fmt.Sprintf("Run time limit (default and max %d)", t.cfg.TimeoutSeconds),
+		},
+	}
+	if t.cfg.AllowPackages {
+		props["packages"] = map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "pip or npm packages to install first, e.g. [\"pandas\", \"matplotlib\"]",
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+		"required":   []string{"language", "code"},
+	}
+}
+
+func (t *CodeRunTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	language := normalizeLanguage(stringArg(args, "language"))
+	lang, ok := codeLanguages[language]
+	if !ok {
+		return "Error: 'language' must be python or javascript", nil
+	}
+	code := stringArg(args, "code")
+	if strings.TrimSpace(code) == "" {
+		return "Error: 'code' is required", nil
+	}
+	sandbox, reason := t.sandbox()
+	if sandbox == "" {
+		return "Error: " + reason, nil
+	}
+
+	packages := stringList(args["packages"])
+	if len(packages) > 0 && !t.cfg.AllowPackages {
+		return "Error: installing packages is disabled (tools.code_run.allow_packages)", nil
+	}
+	for _, p := range packages {
+		if !packageSpec.MatchString(p) {
+			return fmt.Sprintf("Error: invalid package name %q", p), nil
+		}
+	}
+
+	timeout := time.Duration(t.cfg.TimeoutSeconds) * time.Second
+	if s, ok := args["timeout_seconds"].(float64); ok && s > 0 && time.Duration(s)*time.Second < timeout {
+		timeout = time.Duration(s) * time.Second
+	}
+
+	runDir := filepath.Join(t.runsDir, time.Now().Format("20060102-150405.000"))
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			os.RemoveAll(runDir)
+		}
+	}()
+
+	if err := os.WriteFile(filepath.Join(runDir, lang.file), []byte(code), 0644); err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	inputs := map[string]bool{lang.file: true}
+	for _, f := range stringList(args["files"]) {
+		name, err := copyInput(resolvePath(ScopeFrom(ctx).WorkDir, f), runDir)
+		if err != nil {
+			return fmt.Sprintf("Error: input %s: %v", f, err), nil
+		}
+		inputs[name] = true
+	}
+
+	var run codeRun
+	if sandbox == "docker" {
+		run = t.dockerRun(ctx, language, lang, runDir, packages, timeout)
+	} else {
+		run = t.localRun(ctx, language, lang, runDir, packages, timeout)
+	}
+	if run.setupErr != "" {
+		return "Error: " + run.setupErr, nil
+	}
+
+	files := collectOutputs(runDir, inputs)
+	keep = len(files) > 0
+	return formatCodeRun(run, files, runDir, timeout), nil
+}
+
+// codeRun is the outcome of one execution.
+type codeRun struct {
+	stdout, stderr string
+	exitCode       int
+	timedOut       bool
+	setupErr       string
+}
+
+func (t *CodeRunTool) dockerRun(ctx context.Context, language string, lang codeLanguage, runDir string, packages []string, timeout time.Duration) codeRun {
+	image := lang.image(t.cfg)
+	mount := runDir + ":/work"
+	base := []string{"run", "--rm", "-v", mount, "-w", "/work", "-e", "HOME=/tmp", "-e", "MPLBACKEND=Agg"}
+	if runtime.GOOS == "linux" {
+		// Write outputs as the mclaw user instead of root
+		base = append(base, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	}
+
+	env := []string{}
+	if len(packages) > 0 {
+		installCmd := append([]string{"pip", "install", "--quiet", "--disable-pip-version-check", "--target", "/work/.packages"}, packages...)
+		if language == "javascript" {
+			installCmd = append([]string{"npm", "install", "--silent", "--no-audit", "--no-fund", "--prefix", "/work"}, packages...)
+		}
+		args := append(append(append([]string{}, base...), "--name", containerName("install")), image)
+		if out, err := runCommand(ctx, codeRunInstallTime, "", nil, "docker", append(args, installCmd...)...); err != nil {
+			return codeRun{setupErr: fmt.Sprintf("package install failed: %s", truncateOutput(out.stderr+out.stdout))}
+		}
+		env = append(env, "-e", "PYTHONPATH=/work/.packages")
+	}
+
+	name := containerName("run")
+	args := append(append([]string{}, base...), env...)
+	args = append(args, "--name", name, "--network", "none", "--memory", fmt.Sprintf("%dm", t.cfg.MemoryMB),
+		"--cpus", "1", "--pids-limit", "256", "--tmpfs", "/tmp", image)
+	if language == "python" {
+		args = append(args, "python", lang.file)
+	} else {
+		args = append(args, "node", lang.file)
+	}
+
+	run, _ := runCommand(ctx, timeout, "", nil, "docker", args...)
+	if run.timedOut {
+		// Killing the docker client leaves the container running
+		exec.Command("docker", "rm", "-f", name).Run()
+	}
+	return run
+}
+
+func (t *CodeRunTool) localRun(ctx context.Context, language string, lang codeLanguage, runDir string, packages []string, timeout time.Duration) codeRun {
+	binary := findBinary(lang.binary)
+	if binary == "" {
+		return codeRun{setupErr: fmt.Sprintf("%s is not installed", lang.binary[0])}
+	}
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + runDir,
+		"LANG=C.UTF-8",
+		"MPLBACKEND=Agg",
+	}
+
+	if len(packages) > 0 {
+		if language == "python" {
+			venv := filepath.Join(runDir, ".venv")
+			if out, err := runCommand(ctx, codeRunInstallTime, runDir, env, binary, "-m", "venv", venv); err != nil {
+				return codeRun{setupErr: "failed to create venv: " + truncateOutput(out.stderr+out.stdout)}
+			}
+			binary = filepath.Join(venv, "bin", "python")
+			if runtime.GOOS == "windows" {
+				binary = filepath.Join(venv, "Scripts", "python.exe")
+			}
+			installArgs := append([]string{"-m", "pip", "install", "--quiet", "--disable-pip-version-check"}, packages...)
+			if out, err := runCommand(ctx, codeRunInstallTime, runDir, env, binary, installArgs...); err != nil {
+				return codeRun{setupErr: "package install failed: " + truncateOutput(out.stderr+out.stdout)}
+			}
+		} else {
+			installArgs := append([]string{"install", "--silent", "--no-audit", "--no-fund", "--prefix", runDir}, packages...)
+			if out, err := runCommand(ctx, codeRunInstallTime, runDir, env, "npm", installArgs...); err != nil {
+				return codeRun{setupErr: "package install failed: " + truncateOutput(out.stderr+out.stdout)}
+			}
+		}
+	}
+
+	run, _ := runCommand(ctx, timeout, runDir, env, binary, lang.file)
+	return run
+}
+
+// runCommand runs a program with a time limit and captures its output.
+// The error is non-nil when the program failed or timed out.
+func runCommand(ctx context.Context, timeout time.Duration, dir string, env []string, name string, args ...string) (codeRun, error) {
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, name, args...)
+	cmd.Dir = dir
+	if env != nil {
+		cmd.Env = env
+	}
+	cmd.WaitDelay = 2 * time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	run := codeRun{stdout: stdout.String(), stderr: stderr.String()}
+	if runCtx.Err() == context.DeadlineExceeded {
+		run.timedOut = true
+		return run, runCtx.Err()
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		run.exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		run.setupErr = err.Error()
+	}
+	return run, err
+}
+
+func formatCodeRun(run codeRun, files []fileEntry, dir string, timeout time.Duration) string {
+	var sb strings.Builder
+	switch {
+	case run.timedOut:
+		fmt.Fprintf(&sb, "Timed out after %s.\n", timeout)
+	case run.exitCode != 0:
+		fmt.Fprintf(&sb, "Exit code: %d\n", run.exitCode)
+	}
+	if run.stdout != "" {
+		sb.WriteString("\nSTDOUT:\n" + truncateOutput(strings.TrimRight(run.stdout, "\n")) + "\n")
+	}
+	if run.stderr != "" {
+		sb.WriteString("\nSTDERR:\n" + truncateOutput(strings.TrimRight(run.stderr, "\n")) + "\n")
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(&sb, "\nFiles written (in %s):\n", dir)
+		for _, f := range files {
+			fmt.Fprintf(&sb, "- %s (%s)\n", f.name, formatSize(f.size))
+		}
+	}
+	if sb.Len() == 0 {
+		return "(no output)"
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+type fileEntry struct {
+	name string
+	size int64
+}
+
+// collectOutputs lists files the program created, skipping inputs and
+// installed packages.
+func collectOutputs(runDir string, inputs map[string]bool) []fileEntry {
+	var files []fileEntry
+	filepath.WalkDir(runDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(runDir, path)
+		if d.IsDir() {
+			switch d.Name() {
+			case ".venv", ".packages", "node_modules", "__pycache__", ".cache", ".npm":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if inputs[rel] || rel == "package.json" || rel == "package-lock.json" {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files = append(files, fileEntry{name: filepath.ToSlash(rel), size: info.Size()})
+		}
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files
+}
+
+func copyInput(src, dir string) (string, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("is a directory")
+	}
+	if info.Size() > codeRunMaxInputSize {
+		return "", fmt.Errorf("larger than %s", formatSize(codeRunMaxInputSize))
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	name := filepath.Base(src)
+	out, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return name, err
+}
+
+func normalizeLanguage(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "python", "python3", "py":
+		return "python"
+	case "javascript", "js", "node", "nodejs":
+		return "javascript"
+	default:
+		return s
+	}
+}
+
+func findBinary(names []string) string {
+	for _, n := range names {
+		if path, err := exec.LookPath(n); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func containerName(kind string) string {
+	return fmt.Sprintf("mclaw-code-%s-%d", kind, time.Now().UnixNano())
+}
+
+func truncateOutput(s string) string {
+	if len(s) <= codeRunMaxOutput {
+		return s
+	}
+	return s[:codeRunMaxOutput] + fmt.Sprintf("\n... (truncated, %d more chars)", len(s)-codeRunMaxOutput)
+}
+
+func stringArg(args map[string]interface{}, key string) string {
+	s, _ := args[key].(string)
+	return s
+}
+
+func stringList(v interface{}) []string {
+	raw, _ := v.([]interface{})
+	var out []string
+	for _, item := range raw {
+		if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	return out
+}
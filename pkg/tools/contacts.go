@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/contacts"
+)
+
+// ContactsTool manages the contact book of the current conversation.
+type ContactsTool struct {
+	service *contacts.Service
+}
+
+func NewContactsTool(service *contacts.Service) *ContactsTool {
+	return &ContactsTool{service: service}
+}
+
+func (t *ContactsTool) Name() string {
+	return "contacts"
+}
+
+func (t *ContactsTool) Description() string {
+	return `The user's contact book: people they mention, with relation, birthday, notes and how to reach them. Contacts mentioned in a message are shown to you automatically, and birthdays are announced in this chat on the day. Actions:
+- "add": Save a person. Requires: name. Optional: aliases (nicknames like "mom" or a first name, so mentions are recognized), relation, birthday (YYYY-MM-DD, or MM-DD without the year), notes, channel (preferred way to reach them, e.g. "zalo", "email:lan@example.com").
+- "update": Change a contact. Requires: contact_id. Give only the fields to change; notes replace the old notes unless append_notes is true.
+- "list": All contacts.
+- "search": Find contacts by any field. Requires: query.
+- "birthdays": Birthdays in the next days (default 30).
+- "remove": Delete a contact. Requires: contact_id.
+When the user tells you something lasting about a person (a birthday, a preference, a new job), save it here.`
+}
+
+func (t *ContactsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: add, update, list, search, birthdays, remove",
+				"enum":        []string{"add", "update", "list", "search", "birthdays", "remove"},
+			},
+			"contact_id": map[string]interface{}{
+				"type":        "number",
+				"description": "Contact ID (required for update, remove)",
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Full name",
+			},
+			"aliases": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Other names the user calls them by",
+			},
+			"relation": map[string]interface{}{
+				"type":        "string",
+				"description": "Relation to the user, e.g. sister, colleague, landlord",
+			},
+			"birthday": map[string]interface{}{
+				"type":        "string",
+				"description": "Birthday as YYYY-MM-DD or MM-DD; empty string clears it on update",
+			},
+			"notes": map[string]interface{}{
+				"type":        "string",
+				"description": "Free-form notes: preferences, gift ideas, context",
+			},
+			"append_notes": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Add notes to the existing ones instead of replacing them (update)",
+			},
+			"channel": map[string]interface{}{
+				"type":        "string",
+				"description": "Preferred channel to reach them",
+			},
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Text to search for (search)",
+			},
+			"days": map[string]interface{}{
+				"type":        "number",
+				"description": "How many days ahead to look (birthdays, default 30)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ContactsTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	owner := ScopeFrom(ctx).ChatKey()
+	if t.service == nil {
+		return "Error: contacts are disabled", nil
+	}
+	if owner == "" {
+		return "Error: contacts are only available in a conversation", nil
+	}
+
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		c := contacts.Contact{}
+		c.Name, _ = args["name"].(string)
+		c.Aliases = contactAliases(args["aliases"])
+		c.Relation, _ = args["relation"].(string)
+		c.Birthday, _ = args["birthday"].(string)
+		c.Notes, _ = args["notes"].(string)
+		c.Channel, _ = args["channel"].(string)
+		added, err := t.service.Add(owner, c)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return "✓ Saved " + added.Describe(), nil
+
+	case "list", "search":
+		var list []*contacts.Contact
+		var err error
+		if action == "search" {
+			query, _ := args["query"].(string)
+			if strings.TrimSpace(query) == "" {
+				return "Error: 'query' is required for search", nil
+			}
+			list, err = t.service.Search(owner, strings.TrimSpace(query))
+		} else {
+			list, err = t.service.List(owner)
+		}
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if len(list) == 0 {
+			return "No contacts found.", nil
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Contacts (%d):\n", len(list))
+		for _, c := range list {
+			sb.WriteString(c.Describe() + "\n")
+		}
+		return sb.String(), nil
+
+	case "birthdays":
+		days := 30
+		if n, ok := args["days"].(float64); ok && n > 0 {
+			days = int(n)
+		}
+		now := time.Now()
+		list, err := t.service.Upcoming(owner, now, days)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if len(list) == 0 {
+			return fmt.Sprintf("No birthdays in the next %d days.", days), nil
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Birthdays in the next %d days:\n", days)
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		for _, c := range list {
+			next, _ := contacts.NextBirthday(c.Birthday, today.Add(-time.Second), 0)
+			fmt.Fprintf(&sb, "- %s: %s", next.Format("Mon Jan 2"), c.Name)
+			if age := contacts.Age(c.Birthday, next.Year()); age > 0 {
+				fmt.Fprintf(&sb, " turns %d", age)
+			}
+			if c.Relation != "" {
+				fmt.Fprintf(&sb, " (%s)", c.Relation)
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+
+	case "update", "remove":
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: add, update, list, search, birthdays, remove", action), nil
+	}
+
+	id, ok := args["contact_id"].(float64)
+	if !ok || id <= 0 {
+		return fmt.Sprintf("Error: 'contact_id' is required for %s", action), nil
+	}
+	if action == "remove" {
+		c, err := t.service.Remove(owner, int64(id))
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Removed contact #%d %s", c.ID, c.Name), nil
+	}
+
+	c, err := t.service.Update(owner, int64(id), func(c *contacts.Contact) {
+		if v, ok := args["name"].(string); ok && strings.TrimSpace(v) != "" {
+			c.Name = v
+		}
+		if _, ok := args["aliases"]; ok {
+			c.Aliases = contactAliases(args["aliases"])
+		}
+		if v, ok := args["relation"].(string); ok {
+			c.Relation = v
+		}
+		if v, ok := args["birthday"].(string); ok {
+			c.Birthday = v
+		}
+		if v, ok := args["notes"].(string); ok {
+			if appendNotes, _ := args["append_notes"].(bool); appendNotes && c.Notes != "" {
+				v = c.Notes + "; " + v
+			}
+			c.Notes = v
+		}
+		if v, ok := args["channel"].(string); ok {
+			c.Channel = v
+		}
+	})
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	return "✓ Updated " + c.Describe(), nil
+}
+
+// contactAliases accepts a list of names or a comma-separated string.
+func contactAliases(raw interface{}) []string {
+	if s, ok := raw.(string); ok {
+		return contacts.SplitAliases(s)
+	}
+	return stringList(raw)
+}
@@ -0,0 +1,366 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fxRatesAPI = "https://open.er-api.com/v6/latest/USD"
+	fxMaxAge   = 24 * time.Hour
+)
+
+// unit is a unit of some dimension, with its size in the dimension's base
+// unit (metre, kilogram, litre, ...).
+type unit struct {
+	dim    string
+	factor float64
+}
+
+// units maps every accepted spelling to its unit. Temperature is handled
+// apart because it isn't a plain factor.
+var units = map[string]unit{}
+
+func init() {
+	add := func(dim string, factor float64, names ...string) {
+		for _, n := range names {
+			units[n] = unit{dim: dim, factor: factor}
+		}
+	}
+	add("length", 1e-9, "nm", "nanometer", "nanometre")
+	add("length", 1e-6, "um", "µm", "micrometer", "micrometre", "micron")
+	add("length", 1e-3, "mm", "millimeter", "millimetre")
+	add("length", 1e-2, "cm", "centimeter", "centimetre")
+	add("length", 1, "m", "meter", "metre")
+	add("length", 1e3, "km", "kilometer", "kilometre")
+	add("length", 0.0254, "in", "inch", "inches", "\"")
+	add("length", 0.3048, "ft", "foot", "feet", "'")
+	add("length", 0.9144, "yd", "yard")
+	add("length", 1609.344, "mi", "mile")
+	add("length", 1852, "nmi", "nautical mile")
+
+	add("mass", 1e-6, "mg", "milligram")
+	add("mass", 1e-3, "g", "gram")
+	add("mass", 1, "kg", "kilogram", "kilo")
+	add("mass", 1e3, "t", "tonne", "metric ton")
+	add("mass", 0.028349523125, "oz", "ounce")
+	add("mass", 0.45359237, "lb", "lbs", "pound")
+	add("mass", 6.35029318, "st", "stone")
+	add("mass", 0.0311034768, "ozt", "troy ounce")
+	add("mass", 0.0375, "tael", "lượng", "luong", "cây", "cay") // Vietnamese gold tael
+	add("mass", 0.00375, "chỉ", "chi")
+
+	add("volume", 1e-3, "ml", "milliliter", "millilitre")
+	add("volume", 1e-2, "cl", "centiliter", "centilitre")
+	add("volume", 1, "l", "liter", "litre")
+	add("volume", 1e3, "m3", "m³", "cubic meter", "cubic metre")
+	add("volume", 0.00492892159375, "tsp", "teaspoon")
+	add("volume", 0.01478676478125, "tbsp", "tablespoon")
+	add("volume", 0.0295735295625, "floz", "fl oz", "fluid ounce")
+	add("volume", 0.2365882365, "cup")
+	add("volume", 0.473176473, "pt", "pint")
+	add("volume", 0.946352946, "qt", "quart")
+	add("volume", 3.785411784, "gal", "gallon")
+
+	add("area", 1e-4, "cm2", "cm²")
+	add("area", 1, "m2", "m²", "sqm", "square meter", "square metre")
+	add("area", 1e4, "ha", "hectare")
+	add("area", 1e6, "km2", "km²")
+	add("area", 0.09290304, "ft2", "ft²", "sqft", "square foot", "square feet")
+	add("area", 4046.8564224, "acre")
+	add("area", 2589988.110336, "mi2", "mi²", "square mile")
+
+	add("speed", 1, "m/s", "mps")
+	add("speed", 1/3.6, "km/h", "kmh", "kph")
+	add("speed", 0.44704, "mph")
+	add("speed", 1852.0/3600, "kn", "knot")
+
+	add("time", 1e-3, "ms", "millisecond")
+	add("time", 1, "s", "sec", "second")
+	add("time", 60, "min", "minute")
+	add("time", 3600, "h", "hr", "hour")
+	add("time", 86400, "d", "day")
+	add("time", 604800, "wk", "week")
+	add("time", 31557600, "yr", "year")
+
+	add("data", 1, "b", "byte")
+	add("data", 0.125, "bit")
+	add("data", 1e3, "kb", "kilobyte")
+	add("data", 1e6, "mb", "megabyte")
+	add("data", 1e9, "gb", "gigabyte")
+	add("data", 1e12, "tb", "terabyte")
+	add("data", 1<<10, "kib", "kibibyte")
+	add("data", 1<<20, "mib", "mebibyte")
+	add("data", 1<<30, "gib", "gibibyte")
+	add("data", 1<<40, "tib", "tebibyte")
+
+	add("energy", 1, "j", "joule")
+	add("energy", 1e3, "kj", "kilojoule")
+	add("energy", 4.184, "cal", "calorie")
+	add("energy", 4184, "kcal", "kilocalorie")
+	add("energy", 3.6e6, "kwh", "kilowatt hour")
+
+	add("pressure", 1, "pa", "pascal")
+	add("pressure", 1e3, "kpa")
+	add("pressure", 1e5, "bar")
+	add("pressure", 101325, "atm")
+	add("pressure", 6894.757293168, "psi")
+	add("pressure", 133.322387415, "mmhg")
+}
+
+// ConvertTool converts units offline and currencies with exchange rates
+// fetched at most once a day and cached in the data directory.
+type ConvertTool struct {
+	cachePath string
+	client    *http.Client
+	mu        sync.Mutex
+	rates     *fxRates
+}
+
+// fxRates is the cached rate table: units of each currency per US dollar.
+type fxRates struct {
+	Updated time.Time          `json:"updated"`
+	Rates   map[string]float64 `json:"rates"`
+}
+
+func NewConvertTool(cachePath string) *ConvertTool {
+	return &ConvertTool{cachePath: cachePath, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (t *ConvertTool) Name() string {
+	return "convert"
+}
+
+func (t *ConvertTool) Description() string {
+	return "Convert an amount between units or currencies without searching the web. Units: length (m, km, ft, in, mi), mass (kg, lb, oz, tael/lượng, chỉ), volume (l, ml, gal, cup), area (m2, ha, acre, sqft), speed (km/h, mph, knot), temperature (C, F, K), time, data (MB, GiB), energy (kcal, kWh) and pressure (bar, psi). Currencies use ISO codes (USD, EUR, VND) with daily exchange rates."
+}
+
+func (t *ConvertTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"amount": map[string]interface{}{
+				"type":        "number",
+				"description": "Amount to convert",
+			},
+			"from": map[string]interface{}{
+				"type":        "string",
+				"description": "Source unit or currency code, e.g. \"mi\", \"F\", \"USD\"",
+			},
+			"to": map[string]interface{}{
+				"type":        "string",
+				"description": "Target unit or currency code, e.g. \"km\", \"C\", \"VND\"",
+			},
+		},
+		"required": []string{"amount", "from", "to"},
+	}
+}
+
+func (t *ConvertTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	amount, ok := args["amount"].(float64)
+	if !ok {
+		return "Error: 'amount' is required", nil
+	}
+	from, _ := args["from"].(string)
+	to, _ := args["to"].(string)
+	if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+		return "Error: 'from' and 'to' are required", nil
+	}
+
+	if result, ok := convertTemperature(amount, from, to); ok {
+		return fmt.Sprintf("%s %s = %s %s", formatAmount(amount), tempName(from), formatAmount(result), tempName(to)), nil
+	}
+	fu, fok := lookupUnit(from)
+	tu, tok := lookupUnit(to)
+	if fok && tok {
+		if fu.dim != tu.dim {
+			return fmt.Sprintf("Error: cannot convert %s (%s) to %s (%s)", from, fu.dim, to, tu.dim), nil
+		}
+		return fmt.Sprintf("%s %s = %s %s", formatAmount(amount), from, formatAmount(amount*fu.factor/tu.factor), to), nil
+	}
+	return t.convertCurrency(ctx, amount, strings.ToUpper(strings.TrimSpace(from)), strings.ToUpper(strings.TrimSpace(to)))
+}
+
+func lookupUnit(name string) (unit, bool) {
+	name = strings.TrimSpace(name)
+	if u, ok := units[name]; ok {
+		return u, true
+	}
+	lower := strings.ToLower(name)
+	if u, ok := units[lower]; ok {
+		return u, true
+	}
+	u, ok := units[strings.TrimSuffix(lower, "s")] // plurals: miles, grams
+	return u, ok
+}
+
+// tempScale returns C, F or K for a temperature unit name.
+func tempScale(name string) string {
+	switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "°"))) {
+	case "c", "celsius":
+		return "C"
+	case "f", "fahrenheit":
+		return "F"
+	case "k", "kelvin":
+		return "K"
+	}
+	return ""
+}
+
+func tempName(name string) string {
+	if s := tempScale(name); s != "K" {
+		return "°" + s
+	}
+	return "K"
+}
+
+func convertTemperature(amount float64, from, to string) (float64, bool) {
+	f, t := tempScale(from), tempScale(to)
+	if f == "" || t == "" {
+		return 0, false
+	}
+	celsius := amount
+	switch f {
+	case "F":
+		celsius = (amount - 32) * 5 / 9
+	case "K":
+		celsius = amount - 273.15
+	}
+	switch t {
+	case "F":
+		return celsius*9/5 + 32, true
+	case "K":
+		return celsius + 273.15, true
+	}
+	return celsius, true
+}
+
+func (t *ConvertTool) convertCurrency(ctx context.Context, amount float64, from, to string) (string, error) {
+	if len(from) != 3 || len(to) != 3 {
+		return fmt.Sprintf("Error: unknown unit or currency %q or %q", from, to), nil
+	}
+	rates, stale, err := t.loadRates(ctx)
+	if err != nil {
+		return fmt.Sprintf("Error: exchange rates unavailable: %v", err), nil
+	}
+	fr, fok := rates.Rates[from]
+	tr, tok := rates.Rates[to]
+	if !fok || !tok {
+		missing := from
+		if fok {
+			missing = to
+		}
+		return fmt.Sprintf("Error: unknown unit or currency %q", missing), nil
+	}
+
+	result := amount / fr * tr
+	out := fmt.Sprintf("%s %s = %s %s (1 %s = %s %s, rates of %s)", formatAmount(amount), from, formatAmount(result), to,
+		from, formatAmount(tr/fr), to, rates.Updated.Local().Format("2006-01-02"))
+	if stale {
+		out += "\nNote: the rates could not be refreshed and may be out of date."
+	}
+	return out, nil
+}
+
+// loadRates returns today's rates, fetching them when the cache is older
+// than a day. When the fetch fails, an older cache is returned as stale.
+func (t *ConvertTool) loadRates(ctx context.Context) (*fxRates, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.rates == nil {
+		if data, err := os.ReadFile(t.cachePath); err == nil {
+			var cached fxRates
+			if json.Unmarshal(data, &cached) == nil && len(cached.Rates) > 0 {
+				t.rates = &cached
+			}
+		}
+	}
+	if t.rates != nil && time.Since(t.rates.Updated) < fxMaxAge {
+		return t.rates, false, nil
+	}
+
+	fresh, err := t.fetchRates(ctx)
+	if err != nil {
+		if t.rates != nil {
+			return t.rates, true, nil
+		}
+		return nil, false, err
+	}
+	t.rates = fresh
+	if data, err := json.Marshal(fresh); err == nil {
+		os.MkdirAll(filepath.Dir(t.cachePath), 0755)
+		os.WriteFile(t.cachePath, data, 0644)
+	}
+	return fresh, false, nil
+}
+
+func (t *ConvertTool) fetchRates(ctx context.Context) (*fxRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fxRatesAPI, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	var result struct {
+		Result     string             `json:"result"`
+		UpdateUnix int64              `json:"time_last_update_unix"`
+		Rates      map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Result != "success" || len(result.Rates) == 0 {
+		return nil, fmt.Errorf("unexpected response: %s", result.Result)
+	}
+	updated := time.Now()
+	if result.UpdateUnix > 0 {
+		updated = time.Unix(result.UpdateUnix, 0)
+	}
+	return &fxRates{Updated: updated, Rates: result.Rates}, nil
+}
+
+// formatAmount prints up to six significant decimals without trailing
+// zeros, and groups the thousands of large numbers.
+func formatAmount(v float64) string {
+	if v != 0 && (v < 1e-4 && v > -1e-4 || v >= 1e15 || v <= -1e15) {
+		return strconv.FormatFloat(v, 'g', 6, 64)
+	}
+	s := strconv.FormatFloat(v, 'f', 6, 64)
+	if v >= 1000 || v <= -1000 {
+		s = strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	whole, frac, _ := strings.Cut(s, ".")
+	neg := strings.HasPrefix(whole, "-")
+	whole = strings.TrimPrefix(whole, "-")
+	var sb strings.Builder
+	if neg {
+		sb.WriteByte('-')
+	}
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(r)
+	}
+	if frac != "" {
+		sb.WriteString("." + frac)
+	}
+	return sb.String()
+}
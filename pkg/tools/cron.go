@@ -4,16 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"time"
 
+	"github.com/ntminh611/mclaw/pkg/config"
 	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/workflow"
 )
 
 // CronTool allows the AI agent to create, list, remove, and manage scheduled jobs
 type CronTool struct {
-	cronService    *cron.CronService
-	defaultChannel string
-	defaultChatID  string
+	cronService *cron.CronService
+	workflows   *workflow.Engine
+	policy      func() map[string]config.ToolPolicyConfig
 }
 
 func NewCronTool() *CronTool {
@@ -22,12 +25,54 @@ func NewCronTool() *CronTool {
 
 func (t *CronTool) SetCronService(cs *cron.CronService) {
 	t.cronService = cs
+	t.registerWorkflowHandler()
 }
 
-// SetContext sets the default channel and chatID for delivery
-func (t *CronTool) SetContext(channel, chatID string) {
-	t.defaultChannel = channel
-	t.defaultChatID = chatID
+// SetWorkflowEngine enables scheduling YAML workflows as cron jobs.
+func (t *CronTool) SetWorkflowEngine(engine *workflow.Engine) {
+	t.workflows = engine
+	t.registerWorkflowHandler()
+}
+
+// SetToolPolicy gives the tools.policy that scheduled workflows obey, read
+// at each run so config reloads apply.
+func (t *CronTool) SetToolPolicy(policy func() map[string]config.ToolPolicyConfig) {
+	t.policy = policy
+}
+
+func (t *CronTool) registerWorkflowHandler() {
+	if t.cronService == nil || t.workflows == nil {
+		return
+	}
+	t.cronService.SetKindHandler("workflow", t.runWorkflowJob)
+}
+
+// runWorkflowJob executes a "workflow" job deterministically, without an agent turn.
+func (t *CronTool) runWorkflowJob(job *cron.CronJob) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	var target workflow.Target
+	if job.Payload.Deliver {
+		target = workflow.Target{Channel: job.Payload.Channel, ChatID: job.Payload.To}
+	}
+
+	// Steps run as the job's owner, not in whatever chat last used the tools:
+	// the chat the job belongs to, the workspace, and that chat's tool policy.
+	scope := Scope{Channel: job.Payload.Channel, ChatID: job.Payload.To, Group: job.Payload.Group, WorkDir: filepath.Dir(t.workflows.Dir())}
+	if scope.Channel != "" && scope.ChatID != "" {
+		scope.SessionKey = scope.Channel + ":" + scope.ChatID
+	}
+	if t.policy != nil {
+		scope.Denied = PolicyDenied(t.policy(), scope.Channel, scope.Group)
+	}
+	ctx = WithScope(ctx, scope)
+
+	result, err := t.workflows.RunByName(ctx, job.Payload.Message, target)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("workflow %s: %d steps, %d notifications", result.Workflow, len(result.Steps), len(result.Notifications)), nil
 }
 
 func (t *CronTool) Name() string {
@@ -36,12 +81,15 @@ func (t *CronTool) Name() string {
 
 func (t *CronTool) Description() string {
 	return `Manage scheduled/recurring tasks (cron jobs). Actions:
-- "add": Create a new scheduled job. Requires: name, message, schedule_type ("every" or "at"), interval_seconds (for "every") or run_at_iso (for "at"). Optional: deliver (bool), channel, to (chat_id).
+- "add": Create a new scheduled job. Requires: name, message (or workflow), schedule_type ("every" or "at"), interval_seconds (for "every") or run_at_iso (for "at"). Optional: deliver (bool), channel, to (chat_id).
+  Set "workflow" instead of "message" to run a saved YAML workflow (see the workflow tool) without an agent turn.
 - "list": List all active scheduled jobs.
 - "remove": Remove a job by ID. Requires: job_id.
 - "enable": Enable a disabled job. Requires: job_id.
 - "disable": Disable a job. Requires: job_id.
-When deliver=true, the job result will be sent to the specified channel/chat.`
+When deliver=true, the job result will be sent to the specified channel/chat.
+Optional "template" formats the delivered result consistently, e.g. "📊 Daily report {date}\n\n{result}". Variables: {date}, {time}, {job_name}, {result}. Channel-specific parts go in blocks like {#telegram}...{/telegram}.
+To simply remind the user of something, use the remind tool instead.`
 }
 
 func (t *CronTool) Parameters() map[string]interface{} {
@@ -61,6 +109,10 @@ func (t *CronTool) Parameters() map[string]interface{} {
 				"type":        "string",
 				"description": "The prompt/message the agent will process when the job runs (required for add)",
 			},
+			"workflow": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of a workflow to run instead of an agent prompt (for add)",
+			},
 			"schedule_type": map[string]interface{}{
 				"type":        "string",
 				"description": "Schedule type: 'every' for recurring, 'at' for one-time",
@@ -80,12 +132,16 @@ func (t *CronTool) Parameters() map[string]interface{} {
 			},
 			"channel": map[string]interface{}{
 				"type":        "string",
-				"description": "Target channel for delivery (e.g. 'telegram')",
+				"description": "Target channel for delivery (e.g. 'telegram', or 'group' with a recipient group name as 'to')",
 			},
 			"to": map[string]interface{}{
 				"type":        "string",
 				"description": "Target chat/user ID for delivery",
 			},
+			"template": map[string]interface{}{
+				"type":        "string",
+				"description": "Delivery template with {date}, {time}, {job_name}, {result} and optional {#channel}...{/channel} blocks (for add)",
+			},
 			"job_id": map[string]interface{}{
 				"type":        "string",
 				"description": "Job ID (required for remove/enable/disable)",
@@ -104,7 +160,7 @@ func (t *CronTool) Execute(ctx context.Context, args map[string]interface{}) (st
 
 	switch action {
 	case "add":
-		return t.addJob(args)
+		return t.addJob(ScopeFrom(ctx), args)
 	case "list":
 		return t.listJobs()
 	case "remove":
@@ -118,15 +174,23 @@ func (t *CronTool) Execute(ctx context.Context, args map[string]interface{}) (st
 	}
 }
 
-func (t *CronTool) addJob(args map[string]interface{}) (string, error) {
+func (t *CronTool) addJob(scope Scope, args map[string]interface{}) (string, error) {
 	name, _ := args["name"].(string)
 	message, _ := args["message"].(string)
+	workflowName, _ := args["workflow"].(string)
 	scheduleType, _ := args["schedule_type"].(string)
 
 	if name == "" {
 		return "Error: 'name' is required for add", nil
 	}
-	if message == "" {
+	if workflowName != "" {
+		if t.workflows == nil {
+			return "Error: workflows are not available", nil
+		}
+		if _, err := t.workflows.Get(workflowName); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+	} else if message == "" {
 		return "Error: 'message' is required for add", nil
 	}
 
@@ -136,13 +200,14 @@ func (t *CronTool) addJob(args map[string]interface{}) (string, error) {
 	}
 	channel, _ := args["channel"].(string)
 	to, _ := args["to"].(string)
+	template, _ := args["template"].(string)
 
 	// Auto-fill from current chat context if not specified
 	if channel == "" {
-		channel = t.defaultChannel
+		channel = scope.Channel
 	}
 	if to == "" {
-		to = t.defaultChatID
+		to = scope.ChatID
 	}
 
 	var schedule cron.CronSchedule
@@ -178,7 +243,22 @@ func (t *CronTool) addJob(args map[string]interface{}) (string, error) {
 		return "Error: 'schedule_type' must be 'every' or 'at'", nil
 	}
 
-	job, err := t.cronService.AddJob(name, schedule, message, deliver, channel, to)
+	payload := cron.CronPayload{
+		Kind:     "agent_turn",
+		Message:  message,
+		Deliver:  deliver,
+		Channel:  channel,
+		To:       to,
+		Template: template,
+		// A chat other than the current one may be a group
+		Group: scope.Group || channel != scope.Channel || to != scope.ChatID,
+	}
+	if workflowName != "" {
+		payload.Kind = "workflow"
+		payload.Message = workflowName
+	}
+
+	job, err := t.cronService.AddJobPayload(name, schedule, payload)
 	if err != nil {
 		return fmt.Sprintf("Error adding job: %v", err), nil
 	}
@@ -205,8 +285,10 @@ func (t *CronTool) listJobs() (string, error) {
 		Enabled  bool   `json:"enabled"`
 		Schedule string `json:"schedule"`
 		NextRun  string `json:"next_run"`
+		Kind     string `json:"kind"`
 		Message  string `json:"message"`
 		Deliver  bool   `json:"deliver"`
+		Template string `json:"template,omitempty"`
 	}
 
 	var result []jobInfo
@@ -229,8 +311,10 @@ func (t *CronTool) listJobs() (string, error) {
 			Enabled:  job.Enabled,
 			Schedule: schedule,
 			NextRun:  nextRun,
+			Kind:     job.Payload.Kind,
 			Message:  job.Payload.Message,
 			Deliver:  job.Payload.Deliver,
+			Template: job.Payload.Template,
 		})
 	}
 
@@ -0,0 +1,246 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CustomToolSpec is a tool defined in workspace/tools/<name>.yaml (or .json)
+// with either a shell command or an HTTP request as its backend.
+//
+// Example:
+//
+//	name: find_notes
+//	description: Find note files mentioning a phrase
+//	parameters:
+//	  type: object
+//	  properties:
+//	    pattern: {type: string, description: Text to look for}
+//	  required: [pattern]
+//	command: grep -ril {{pattern}} ~/Documents/notes
+//
+// In command, {{param}} expands to the shell-quoted argument; parameters are
+// also exported as MCLAW_ARG_<NAME>. In http.url, values are URL-encoded;
+// headers and body expand them verbatim and may use {{secret:NAME}}.
+type CustomToolSpec struct {
+	Name        string                 `yaml:"name" json:"name"`
+	Description string                 `yaml:"description" json:"description"`
+	Parameters  map[string]interface{} `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Command     string                 `yaml:"command,omitempty" json:"command,omitempty"`
+	HTTP        *CustomHTTPSpec        `yaml:"http,omitempty" json:"http,omitempty"`
+	Timeout     int                    `yaml:"timeout,omitempty" json:"timeout,omitempty"` // seconds, default 30
+	Path        string                 `yaml:"-" json:"-"`
+}
+
+type CustomHTTPSpec struct {
+	Method  string            `yaml:"method,omitempty" json:"method,omitempty"`
+	URL     string            `yaml:"url" json:"url"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty" json:"body,omitempty"`
+}
+
+var (
+	customToolName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+	customArgRef   = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+	envArgName     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// CustomTool runs a CustomToolSpec through the exec or http_request tool,
+// so it inherits their command guard, network guard and secret handling.
+type CustomTool struct {
+	spec     CustomToolSpec
+	execTool *ExecTool
+	httpTool *HTTPRequestTool
+}
+
+// NewCustomTool validates a spec and binds it to its backend.
+func NewCustomTool(spec CustomToolSpec, workspace string, httpTool *HTTPRequestTool) (*CustomTool, error) {
+	if !customToolName.MatchString(spec.Name) {
+		return nil, fmt.Errorf("invalid tool name %q (use lowercase letters, digits and _)", spec.Name)
+	}
+	if spec.Description == "" {
+		return nil, fmt.Errorf("tool %q has no description", spec.Name)
+	}
+	if (spec.Command == "") == (spec.HTTP == nil) {
+		return nil, fmt.Errorf("tool %q must set exactly one of command or http", spec.Name)
+	}
+	if spec.HTTP != nil && spec.HTTP.URL == "" {
+		return nil, fmt.Errorf("tool %q: http.url is required", spec.Name)
+	}
+	if spec.Parameters == nil {
+		spec.Parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	if spec.Timeout <= 0 {
+		spec.Timeout = 30
+	}
+
+	t := &CustomTool{spec: spec}
+	if spec.Command != "" {
+		t.execTool = NewExecTool(workspace)
+		t.execTool.SetTimeout(time.Duration(spec.Timeout) * time.Second)
+	} else {
+		if httpTool == nil {
+			return nil, fmt.Errorf("tool %q: http backend unavailable", spec.Name)
+		}
+		t.httpTool = httpTool
+	}
+	return t, nil
+}
+
+func (t *CustomTool) Name() string {
+	return t.spec.Name
+}
+
+func (t *CustomTool) Description() string {
+	return t.spec.Description
+}
+
+func (t *CustomTool) Parameters() map[string]interface{} {
+	return t.spec.Parameters
+}
+
+func (t *CustomTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if t.execTool != nil {
+		return t.runCommand(ctx, args)
+	}
+	return t.runHTTP(ctx, args)
+}
+
+func (t *CustomTool) runCommand(ctx context.Context, args map[string]interface{}) (string, error) {
+	command := expandArgs(t.spec.Command, args, shellQuote)
+
+	// Export arguments for scripts that prefer the environment
+	var env []string
+	for name, v := range args {
+		if !envArgName.MatchString(name) {
+			continue
+		}
+		env = append(env, "MCLAW_ARG_"+strings.ToUpper(name)+"="+argString(v))
+	}
+	if len(env) > 0 {
+		sort.Strings(env)
+		command = "export " + strings.Join(quoteAssignments(env), " ") + "; " + command
+	}
+	return t.execTool.Execute(ctx, map[string]interface{}{"command": command})
+}
+
+func (t *CustomTool) runHTTP(ctx context.Context, args map[string]interface{}) (string, error) {
+	spec := t.spec.HTTP
+	req := map[string]interface{}{
+		"method": spec.Method,
+		"url":    expandArgs(spec.URL, args, url.QueryEscape),
+	}
+	if len(spec.Headers) > 0 {
+		headers := make(map[string]interface{}, len(spec.Headers))
+		for k, v := range spec.Headers {
+			headers[k] = expandArgs(v, args, nil)
+		}
+		req["headers"] = headers
+	}
+	if spec.Body != "" {
+		req["body"] = expandArgs(spec.Body, args, nil)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(t.spec.Timeout)*time.Second)
+	defer cancel()
+	return t.httpTool.Execute(ctx, req)
+}
+
+// expandArgs replaces {{name}} with the argument value, passed through
+// escape when set. {{secret:NAME}} does not match and is left for the
+// http_request tool. Missing optional arguments expand to "".
+func expandArgs(tmpl string, args map[string]interface{}, escape func(string) string) string {
+	return customArgRef.ReplaceAllStringFunc(tmpl, func(ref string) string {
+		name := customArgRef.FindStringSubmatch(ref)[1]
+		s := argString(args[name])
+		if escape != nil {
+			return escape(s)
+		}
+		return s
+	})
+}
+
+func argString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		data, _ := json.Marshal(x)
+		return string(data)
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteAssignments(env []string) []string {
+	out := make([]string, len(env))
+	for i, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		out[i] = k + "=" + shellQuote(v)
+	}
+	return out
+}
+
+// LoadCustomTools loads every *.yaml, *.yml and *.json tool definition in
+// dir, sorted by file name. Invalid files are skipped and reported in the
+// returned error list.
+func LoadCustomTools(dir, workspace string, httpTool *HTTPRequestTool) ([]*CustomTool, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+
+	var loaded []*CustomTool
+	var errs []error
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// YAML is a superset of JSON, so one decoder handles both
+		var spec CustomToolSpec
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid tool definition: %w", entry.Name(), err))
+			continue
+		}
+		if spec.Name == "" {
+			spec.Name = strings.TrimSuffix(entry.Name(), ext)
+		}
+		spec.Path = path
+		tool, err := NewCustomTool(spec, workspace, httpTool)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		loaded = append(loaded, tool)
+	}
+	return loaded, errs
+}
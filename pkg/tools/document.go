@@ -0,0 +1,610 @@
+package tools
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxDocumentSize = 50 << 20 // bytes
+	maxSheetRows    = 2000
+)
+
+// ReadDocumentTool extracts text from PDF, DOCX and XLSX files, such as
+// documents sent to the bot (saved under the temp mclaw_media directory).
+type ReadDocumentTool struct {
+	maxChars int
+}
+
+func NewReadDocumentTool() *ReadDocumentTool {
+	return &ReadDocumentTool{maxChars: 50000}
+}
+
+func (t *ReadDocumentTool) Name() string { return "read_document" }
+
+func (t *ReadDocumentTool) Description() string {
+	return "Extract text from a PDF, Word (.docx) or Excel (.xlsx) file. Use pages to select PDF pages or spreadsheet sheets, e.g. \"1-3,7\". Use read_file for plain text files."
+}
+
+func (t *ReadDocumentTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the document",
+			},
+			"pages": map[string]interface{}{
+				"type":        "string",
+				"description": "PDF pages or XLSX sheets to read, e.g. \"1-5\" or \"2,4\" (default: all)",
+			},
+			"max_chars": map[string]interface{}{
+				"type":        "integer",
+				"description": "Maximum characters to return (default: 50000)",
+				"minimum":     100.0,
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *ReadDocumentTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	p, ok := args["path"].(string)
+	if !ok || p == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	p = resolvePath(ScopeFrom(ctx).WorkDir, p)
+
+	info, err := os.Stat(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to read document: %w", err)
+	}
+	if info.Size() > maxDocumentSize {
+		return fmt.Sprintf("Error: document is %s; the limit is %s", formatSize(info.Size()), formatSize(maxDocumentSize)), nil
+	}
+
+	var pages pageRange
+	if s, _ := args["pages"].(string); s != "" {
+		if pages, err = parsePageRange(s); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+	}
+
+	maxChars := t.maxChars
+	if mc, ok := args["max_chars"].(float64); ok && int(mc) >= 100 {
+		maxChars = int(mc)
+	}
+
+	var text string
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".pdf":
+		text, err = extractPDF(ctx, p, pages)
+	case ".docx":
+		text, err = extractDOCX(p)
+	case ".xlsx":
+		text, err = extractXLSX(p, pages)
+	default:
+		return fmt.Sprintf("Error: unsupported document type %q (supported: .pdf, .docx, .xlsx)", filepath.Ext(p)), nil
+	}
+	if err != nil {
+		return fmt.Sprintf("Error: failed to extract text: %v", err), nil
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "No text found in the document (it may be scanned images).", nil
+	}
+	if len(text) > maxChars {
+		text = text[:maxChars] + fmt.Sprintf("\n... (truncated, %d more characters; use pages to read further)", len(text)-maxChars)
+	}
+	return text, nil
+}
+
+// pageRange is a set of 1-based page numbers; nil means all pages.
+type pageRange map[int]bool
+
+// parsePageRange parses "1-3,7" into a pageRange.
+func parsePageRange(s string) (pageRange, error) {
+	pages := make(pageRange)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid page range %q", s)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || end < start {
+				return nil, fmt.Errorf("invalid page range %q", s)
+			}
+		}
+		if end-start > 10000 {
+			return nil, fmt.Errorf("page range %q is too large", s)
+		}
+		for i := start; i <= end; i++ {
+			pages[i] = true
+		}
+	}
+	return pages, nil
+}
+
+func (r pageRange) includes(page int) bool {
+	return r == nil || r[page]
+}
+
+// bounds returns the first and last selected page.
+func (r pageRange) bounds() (int, int) {
+	first, last := 0, 0
+	for p := range r {
+		if first == 0 || p < first {
+			first = p
+		}
+		if p > last {
+			last = p
+		}
+	}
+	return first, last
+}
+
+// ── PDF ─────────────────────────────────────────────────────
+
+// extractPDF uses pdftotext (poppler) when installed, which handles fonts
+// and layout properly, and falls back to a basic built-in extractor.
+func extractPDF(ctx context.Context, p string, pages pageRange) (string, error) {
+	if _, err := exec.LookPath("pdftotext"); err == nil {
+		args := []string{"-layout", "-enc", "UTF-8"}
+		if pages != nil {
+			first, last := pages.bounds()
+			args = append(args, "-f", strconv.Itoa(first), "-l", strconv.Itoa(last))
+		}
+		out, err := exec.CommandContext(ctx, "pdftotext", append(args, p, "-")...).Output()
+		if err != nil {
+			return "", fmt.Errorf("pdftotext: %w", err)
+		}
+
+		// pdftotext separates pages with form feeds
+		first := 1
+		if pages != nil {
+			first, _ = pages.bounds()
+		}
+		var sb strings.Builder
+		for i, page := range strings.Split(string(out), "\f") {
+			n := first + i
+			if !pages.includes(n) || strings.TrimSpace(page) == "" {
+				continue
+			}
+			fmt.Fprintf(&sb, "--- Page %d ---\n%s\n", n, strings.TrimRight(page, "\n "))
+		}
+		return sb.String(), nil
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	text := extractPDFStreams(data)
+	if pages != nil && text != "" {
+		text = "(install poppler-utils for page selection; showing all pages)\n" + text
+	}
+	return text, nil
+}
+
+var (
+	pdfStream   = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	pdfTextShow = regexp.MustCompile(`(?s)\((?:\\.|[^\\)])*\)\s*(?:Tj|'|")|\[(?:\\.|[^\]])*\]\s*TJ|T\*|Td|TD`)
+	pdfString   = regexp.MustCompile(`\((?:\\.|[^\\)])*\)`)
+)
+
+// extractPDFStreams pulls literal strings out of text-showing operators in
+// the document's content streams. It only copes with simple PDFs using
+// standard font encodings.
+func extractPDFStreams(data []byte) string {
+	var sb strings.Builder
+	for _, loc := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		dict := string(data[loc[2]:loc[3]])
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			continue
+		}
+		stream := data[start : start+end]
+
+		if strings.Contains(dict, "/FlateDecode") {
+			r, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			stream, err = io.ReadAll(io.LimitReader(r, 10<<20))
+			r.Close()
+			if err != nil && len(stream) == 0 {
+				continue
+			}
+		} else if strings.Contains(dict, "/Filter") {
+			continue // images and other encodings
+		}
+		if !bytes.Contains(stream, []byte("BT")) {
+			continue
+		}
+
+		for _, op := range pdfTextShow.FindAll(stream, -1) {
+			switch {
+			case bytes.Equal(op, []byte("T*")), bytes.Equal(op, []byte("Td")), bytes.Equal(op, []byte("TD")):
+				sb.WriteString("\n")
+			default:
+				for _, s := range pdfString.FindAll(op, -1) {
+					sb.WriteString(unescapePDFString(s[1 : len(s)-1]))
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return collapseBlankLines(sb.String())
+}
+
+func unescapePDFString(s []byte) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r', 't':
+			sb.WriteByte(' ')
+		case '(', ')', '\\':
+			sb.WriteByte(s[i])
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			n, _ := strconv.ParseUint(string(s[i:j]), 8, 8)
+			sb.WriteByte(byte(n))
+			i = j - 1
+		}
+	}
+	return sb.String()
+}
+
+func collapseBlankLines(s string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank && len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ── DOCX ────────────────────────────────────────────────────
+
+// extractDOCX reads word/document.xml, keeping paragraphs, line breaks and
+// table cells.
+func extractDOCX(p string) (string, error) {
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+
+	f := zipFile(&zr.Reader, "word/document.xml")
+	if f == nil {
+		return "", fmt.Errorf("not a Word document")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var sb bytes.Buffer
+	dec := xml.NewDecoder(rc)
+	inText := false
+	cellDepth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch el := tok.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			case "tc":
+				cellDepth++
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				// Paragraphs inside a table cell stay on the row's line
+				if cellDepth > 0 {
+					sb.WriteString(" ")
+				} else {
+					sb.WriteString("\n")
+				}
+			case "tc":
+				cellDepth--
+				sb.Truncate(len(bytes.TrimRight(sb.Bytes(), " ")))
+				sb.WriteString(" | ")
+			case "tr":
+				sb.Truncate(len(bytes.TrimSuffix(sb.Bytes(), []byte(" | "))))
+				sb.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(el)
+			}
+		}
+	}
+	return collapseBlankLines(sb.String()), nil
+}
+
+// ── XLSX ────────────────────────────────────────────────────
+
+// extractXLSX renders each selected sheet as rows of " | "-separated cells.
+func extractXLSX(p string, sheets pageRange) (string, error) {
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+
+	shared, err := xlsxSharedStrings(&zr.Reader)
+	if err != nil {
+		return "", err
+	}
+	list, err := xlsxSheets(&zr.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, sheet := range list {
+		if !sheets.includes(i + 1) {
+			continue
+		}
+		f := zipFile(&zr.Reader, sheet.path)
+		if f == nil {
+			continue
+		}
+		rows, truncated, err := xlsxRows(f, shared)
+		if err != nil {
+			return "", fmt.Errorf("sheet %q: %w", sheet.name, err)
+		}
+		fmt.Fprintf(&sb, "## Sheet %d: %s\n", i+1, sheet.name)
+		for _, row := range rows {
+			sb.WriteString(strings.Join(row, " | "))
+			sb.WriteString("\n")
+		}
+		if truncated {
+			fmt.Fprintf(&sb, "... (only the first %d rows shown)\n", maxSheetRows)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+type xlsxSheet struct {
+	name string
+	path string
+}
+
+// xlsxSheets lists sheets in workbook order with their part paths.
+func xlsxSheets(zr *zip.Reader) ([]xlsxSheet, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(zr, "xl/workbook.xml", &workbook); err != nil {
+		return nil, fmt.Errorf("not an Excel workbook: %w", err)
+	}
+
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	decodeZipXML(zr, "xl/_rels/workbook.xml.rels", &rels)
+	targets := make(map[string]string)
+	for _, r := range rels.Rels {
+		target := strings.TrimPrefix(r.Target, "/")
+		if !strings.HasPrefix(target, "xl/") {
+			target = path.Join("xl", target)
+		}
+		targets[r.ID] = target
+	}
+
+	sheets := make([]xlsxSheet, 0, len(workbook.Sheets))
+	for i, s := range workbook.Sheets {
+		p, ok := targets[s.RID]
+		if !ok {
+			p = fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)
+		}
+		sheets = append(sheets, xlsxSheet{name: s.Name, path: p})
+	}
+	return sheets, nil
+}
+
+func xlsxSharedStrings(zr *zip.Reader) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			T    string `xml:"t"`
+			Runs []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if zipFile(zr, "xl/sharedStrings.xml") == nil {
+		return nil, nil
+	}
+	if err := decodeZipXML(zr, "xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		s := item.T
+		for _, r := range item.Runs {
+			s += r.T
+		}
+		strs[i] = s
+	}
+	return strs, nil
+}
+
+// xlsxRows decodes a worksheet into rows of cell text, placing each cell in
+// its column so gaps are preserved.
+func xlsxRows(f *zip.File, shared []string) ([][]string, bool, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, false, err
+	}
+	defer rc.Close()
+
+	type cell struct {
+		Ref    string `xml:"r,attr"`
+		Type   string `xml:"t,attr"`
+		Value  string `xml:"v"`
+		Inline string `xml:"is>t"`
+	}
+	type row struct {
+		Cells []cell `xml:"c"`
+	}
+
+	var rows [][]string
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		if len(rows) >= maxSheetRows {
+			return rows, true, nil
+		}
+
+		var r row
+		if err := dec.DecodeElement(&r, &start); err != nil {
+			return nil, false, err
+		}
+
+		var values []string
+		for i, c := range r.Cells {
+			col := xlsxColumn(c.Ref)
+			if col < 0 {
+				col = i
+			}
+			for len(values) < col {
+				values = append(values, "")
+			}
+
+			v := c.Value
+			switch c.Type {
+			case "s":
+				if idx, err := strconv.Atoi(v); err == nil && idx >= 0 && idx < len(shared) {
+					v = shared[idx]
+				}
+			case "inlineStr":
+				v = c.Inline
+			case "b":
+				v = map[string]string{"1": "TRUE", "0": "FALSE"}[v]
+			}
+			if col < len(values) {
+				values[col] = v
+			} else {
+				values = append(values, v)
+			}
+		}
+
+		if strings.TrimSpace(strings.Join(values, "")) != "" {
+			rows = append(rows, values)
+		}
+	}
+	return rows, false, nil
+}
+
+// xlsxColumn converts a cell reference like "C7" to a 0-based column index.
+func xlsxColumn(ref string) int {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
+
+func zipFile(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func decodeZipXML(zr *zip.Reader, name string, v interface{}) error {
+	f := zipFile(zr, name)
+	if f == nil {
+		return fmt.Errorf("%s not found", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(io.LimitReader(rc, 50<<20)).Decode(v)
+}
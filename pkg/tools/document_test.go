@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	docxNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	xlsxNS = `xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+)
+
+// writeZip writes an OOXML-style fixture holding the given parts.
+func writeZip(t *testing.T, path string, parts map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// pdfObject is one content stream of a minimal PDF fixture.
+func pdfObject(n int, dict string, stream []byte) string {
+	return fmt.Sprintf("%d 0 obj\n<< %s /Length %d >>\nstream\n%s\nendstream\nendobj\n", n, dict, len(stream), stream)
+}
+
+func writePDF(t *testing.T, path string) {
+	t.Helper()
+	var flate bytes.Buffer
+	zw := zlib.NewWriter(&flate)
+	zw.Write([]byte("BT /F1 12 Tf 72 600 Td (Page two) Tj ET"))
+	zw.Close()
+
+	pdf := "%PDF-1.4\n" +
+		pdfObject(4, "", []byte(`BT /F1 12 Tf 72 712 Td (Hello \(PDF\)) Tj T* [(Wor) -20 (ld)] TJ ET`)) +
+		pdfObject(5, "/Filter /DCTDecode", []byte("BT (not text) Tj ET")) +
+		pdfObject(6, "/Filter /FlateDecode", flate.Bytes()) +
+		"%%EOF\n"
+	if err := os.WriteFile(path, []byte(pdf), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeDOCX(t *testing.T, path, body string) {
+	t.Helper()
+	writeZip(t, path, map[string]string{
+		"[Content_Types].xml": `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"/>`,
+		"word/document.xml":   `<w:document ` + docxNS + `><w:body>` + body + `</w:body></w:document>`,
+	})
+}
+
+func writeXLSX(t *testing.T, path string, sheets ...string) {
+	t.Helper()
+	parts := map[string]string{
+		"xl/sharedStrings.xml": `<sst ` + xlsxNS + `><si><t>Item</t></si><si><t>Price</t></si><si><r><t>Rich </t></r><r><t>text</t></r></si></sst>`,
+	}
+	var list, rels string
+	for i, rows := range sheets {
+		n := i + 1
+		list += fmt.Sprintf(`<sheet name="Sheet%d" sheetId="%d" r:id="rId%d"/>`, n, n, n)
+		rels += fmt.Sprintf(`<Relationship Id="rId%d" Target="worksheets/sheet%d.xml"/>`, n, n)
+		parts[fmt.Sprintf("xl/worksheets/sheet%d.xml", n)] = `<worksheet ` + xlsxNS + `><sheetData>` + rows + `</sheetData></worksheet>`
+	}
+	parts["xl/workbook.xml"] = `<workbook ` + xlsxNS + `><sheets>` + list + `</sheets></workbook>`
+	parts["xl/_rels/workbook.xml.rels"] = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels + `</Relationships>`
+	writeZip(t, path, parts)
+}
+
+// runReadDocument calls the tool without pdftotext on PATH, so PDFs go
+// through the built-in extractor.
+func runReadDocument(t *testing.T, args map[string]interface{}) string {
+	t.Helper()
+	t.Setenv("PATH", t.TempDir())
+	out, err := NewReadDocumentTool().Execute(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestReadDocumentFormats(t *testing.T) {
+	dir := t.TempDir()
+	pdf := filepath.Join(dir, "report.pdf")
+	writePDF(t, pdf)
+	docx := filepath.Join(dir, "report.docx")
+	writeDOCX(t, docx, `<w:p><w:r><w:t>Quarterly report</w:t></w:r></w:p>`+
+		`<w:p><w:r><w:t xml:space="preserve">Revenue </w:t><w:tab/><w:t>up</w:t><w:br/><w:t>next line</w:t></w:r></w:p>`+
+		`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Region</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Sales</w:t></w:r></w:p></w:tc></w:tr>`+
+		`<w:tr><w:tc><w:p><w:r><w:t>EU</w:t></w:r></w:p><w:p><w:r><w:t>(west)</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>12</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`)
+	xlsx := filepath.Join(dir, "prices.xlsx")
+	writeXLSX(t, xlsx,
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>`+
+			`<row r="2"><c r="A2" t="inlineStr"><is><t>Tea</t></is></c><c r="C2"><v>3.5</v></c></row>`+
+			`<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3" t="b"><v>1</v></c></row>`,
+		`<row r="1"><c r="B1"><v>42</v></c></row>`)
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{
+			name: "pdf",
+			args: map[string]interface{}{"path": pdf},
+			want: "Hello (PDF)\nWorld\n\nPage two",
+		},
+		{
+			name: "pdf pages without pdftotext",
+			args: map[string]interface{}{"path": pdf, "pages": "2"},
+			want: "(install poppler-utils for page selection; showing all pages)\nHello (PDF)\nWorld\n\nPage two",
+		},
+		{
+			name: "docx",
+			args: map[string]interface{}{"path": docx},
+			want: "Quarterly report\nRevenue \tup\nnext line\nRegion | Sales\nEU (west) | 12",
+		},
+		{
+			name: "xlsx",
+			args: map[string]interface{}{"path": xlsx},
+			want: "## Sheet 1: Sheet1\nItem | Price\nTea |  | 3.5\nRich text | TRUE\n\n## Sheet 2: Sheet2\n | 42",
+		},
+		{
+			name: "xlsx sheet selection",
+			args: map[string]interface{}{"path": xlsx, "pages": "2"},
+			want: "## Sheet 2: Sheet2\n | 42",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runReadDocument(t, tt.args); got != tt.want {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReadDocumentLimits(t *testing.T) {
+	dir := t.TempDir()
+
+	// Long text is cut off at max_chars
+	docx := filepath.Join(dir, "long.docx")
+	writeDOCX(t, docx, `<w:p><w:r><w:t>`+strings.Repeat("a", 1000)+`</w:t></w:r></w:p>`)
+	out := runReadDocument(t, map[string]interface{}{"path": docx, "max_chars": float64(100)})
+	if !strings.HasPrefix(out, strings.Repeat("a", 100)+"\n... (truncated, 900 more characters") {
+		t.Errorf("expected the text cut at 100 characters, got %q", out)
+	}
+
+	// Large sheets stop at maxSheetRows
+	var rows strings.Builder
+	for i := 1; i <= maxSheetRows+5; i++ {
+		fmt.Fprintf(&rows, `<row r="%d"><c r="A%d"><v>%d</v></c></row>`, i, i, i)
+	}
+	xlsx := filepath.Join(dir, "big.xlsx")
+	writeXLSX(t, xlsx, rows.String())
+	out = runReadDocument(t, map[string]interface{}{"path": xlsx, "max_chars": float64(1 << 20)})
+	if !strings.Contains(out, fmt.Sprintf("\n%d\n... (only the first %d rows shown)", maxSheetRows, maxSheetRows)) || strings.Contains(out, fmt.Sprintf("\n%d\n", maxSheetRows+1)) {
+		t.Errorf("expected the sheet cut at %d rows, got ...%s", maxSheetRows, out[len(out)-200:])
+	}
+
+	// Files over maxDocumentSize are refused before being read
+	big := filepath.Join(dir, "huge.pdf")
+	if err := os.WriteFile(big, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(big, maxDocumentSize+1); err != nil {
+		t.Fatal(err)
+	}
+	out = runReadDocument(t, map[string]interface{}{"path": big})
+	if !strings.HasPrefix(out, "Error: document is 50.0 MB; the limit is 50.0 MB") {
+		t.Errorf("expected an oversized document to be refused, got %q", out)
+	}
+}
+
+func TestReadDocumentErrors(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	os.WriteFile(notes, []byte("plain"), 0644)
+	notWord := filepath.Join(dir, "fake.docx")
+	writeZip(t, notWord, map[string]string{"hello.txt": "hi"})
+	empty := filepath.Join(dir, "empty.docx")
+	writeDOCX(t, empty, `<w:p/>`)
+
+	tests := []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"path": notes}, `Error: unsupported document type ".txt"`},
+		{map[string]interface{}{"path": notWord}, "Error: failed to extract text: not a Word document"},
+		{map[string]interface{}{"path": notWord, "pages": "3-1"}, `Error: invalid page range "3-1"`},
+		{map[string]interface{}{"path": empty}, "No text found in the document"},
+	}
+	for _, tt := range tests {
+		if got := runReadDocument(t, tt.args); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%v: expected %q, got %q", tt.args, tt.want, got)
+		}
+	}
+}
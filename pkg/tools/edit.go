@@ -0,0 +1,353 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EditFileTool applies targeted edits to a file (search/replace or a unified
+// diff) instead of rewriting it, with dry-run preview and automatic backups.
+type EditFileTool struct {
+	backupDir string
+}
+
+func NewEditFileTool(backupDir string) *EditFileTool {
+	return &EditFileTool{backupDir: backupDir}
+}
+
+func (t *EditFileTool) Name() string { return "edit_file" }
+
+func (t *EditFileTool) Description() string {
+	return `Edit part of an existing file without rewriting it. Prefer this over write_file for changes to existing files. Either:
+- Search/replace: old_string (must match exactly, including whitespace, and be unique unless replace_all) and new_string.
+- Unified diff: diff with one or more "@@ -l,n +l,n @@" hunks; context lines must match the file and each header's line counts must match its hunk.
+Set dry_run=true to preview the change without writing. A backup of the original is kept.`
+}
+
+func (t *EditFileTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the file to edit",
+			},
+			"old_string": map[string]interface{}{
+				"type":        "string",
+				"description": "Exact text to replace (search/replace mode)",
+			},
+			"new_string": map[string]interface{}{
+				"type":        "string",
+				"description": "Replacement text (search/replace mode)",
+			},
+			"replace_all": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Replace every occurrence of old_string (default: false)",
+			},
+			"diff": map[string]interface{}{
+				"type":        "string",
+				"description": "Unified diff to apply (diff mode)",
+			},
+			"dry_run": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Preview the change without writing (default: false)",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *EditFileTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	path, ok := args["path"].(string)
+	if !ok || path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	path = resolvePath(ScopeFrom(ctx).WorkDir, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	original := string(data)
+
+	var updated, summary string
+	if diff, _ := args["diff"].(string); diff != "" {
+		var hunks int
+		updated, hunks, err = applyUnifiedDiff(original, diff)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		summary = fmt.Sprintf("applied %d hunk(s)", hunks)
+	} else {
+		oldString, ok := args["old_string"].(string)
+		if !ok || oldString == "" {
+			return "Error: provide either 'diff' or 'old_string' and 'new_string'", nil
+		}
+		newString, _ := args["new_string"].(string)
+		replaceAll, _ := args["replace_all"].(bool)
+
+		count := strings.Count(original, oldString)
+		switch {
+		case count == 0:
+			return "Error: old_string not found in file. Read the file and copy the text exactly, including whitespace.", nil
+		case count > 1 && !replaceAll:
+			return fmt.Sprintf("Error: old_string matches %d places. Include more surrounding context to make it unique, or set replace_all.", count), nil
+		}
+		if replaceAll {
+			updated = strings.ReplaceAll(original, oldString, newString)
+		} else {
+			updated = strings.Replace(original, oldString, newString, 1)
+		}
+		summary = fmt.Sprintf("replaced %d occurrence(s)", count)
+	}
+
+	if updated == original {
+		return "No changes: the edit leaves the file unchanged.", nil
+	}
+
+	preview := changePreview(original, updated, 2)
+
+	if dryRun, _ := args["dry_run"].(bool); dryRun {
+		return fmt.Sprintf("Dry run for %s (%s), nothing written:\n\n%s", path, summary, preview), nil
+	}
+
+	backup, err := t.backup(path, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to back up file: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(updated), info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return fmt.Sprintf("Edited %s (%s). Backup: %s\n\n%s", path, summary, backup, preview), nil
+}
+
+// backup keeps a timestamped copy of the original file.
+func (t *EditFileTool) backup(path string, data []byte) (string, error) {
+	dir := t.backupDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "mclaw_backups")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s.%s", filepath.Base(path), time.Now().Format("20060102-150405.000"))
+	backup := filepath.Join(dir, name)
+	return backup, os.WriteFile(backup, data, 0644)
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+\d+(?:,(\d+))? @@`)
+
+type diffHunk struct {
+	oldStart int // 1-based line number from the header
+	oldCount int // lines the header says the hunk removes or keeps
+	newCount int // lines the header says the hunk adds or keeps
+	oldLines []string
+	newLines []string
+}
+
+func (h *diffHunk) complete() bool {
+	return len(h.oldLines) == h.oldCount && len(h.newLines) == h.newCount
+}
+
+// applyUnifiedDiff applies the hunks of a unified diff to content. Hunks are
+// located by their context, so small line-number drift is tolerated.
+func applyUnifiedDiff(content, diff string) (string, int, error) {
+	hunks, err := parseUnifiedDiff(diff)
+	if err != nil {
+		return "", 0, err
+	}
+
+	trailingNewline := strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	offset := 0 // line shift from previously applied hunks
+	cursor := 0 // hunks apply in order, never before the previous one
+	for i, h := range hunks {
+		at := findLines(lines, h.oldLines, h.oldStart-1+offset, cursor)
+		if at < 0 {
+			return "", 0, fmt.Errorf("hunk %d (line %d) does not match the file; re-read the file and regenerate the diff", i+1, h.oldStart)
+		}
+
+		replaced := make([]string, 0, len(lines)-len(h.oldLines)+len(h.newLines))
+		replaced = append(replaced, lines[:at]...)
+		replaced = append(replaced, h.newLines...)
+		replaced = append(replaced, lines[at+len(h.oldLines):]...)
+		lines = replaced
+
+		offset += len(h.newLines) - len(h.oldLines)
+		cursor = at + len(h.newLines)
+	}
+
+	out := strings.Join(lines, "\n")
+	if trailingNewline || content == "" {
+		out += "\n"
+	}
+	return out, len(hunks), nil
+}
+
+// parseUnifiedDiff reads the hunks of a unified diff. Each hunk consumes
+// exactly the lines its "@@ -l,n +l,n @@" header counts, so content lines
+// starting with "--" or "++" are not mistaken for file headers, which are
+// only recognised between hunks.
+func parseUnifiedDiff(diff string) ([]diffHunk, error) {
+	var hunks []diffHunk
+	var cur *diffHunk // the hunk still being read, nil between hunks
+
+	diff = strings.TrimSuffix(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	for _, line := range strings.Split(diff, "\n") {
+		if cur == nil {
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				h := diffHunk{oldCount: 1, newCount: 1}
+				h.oldStart, _ = strconv.Atoi(m[1])
+				if m[2] != "" {
+					h.oldCount, _ = strconv.Atoi(m[2])
+				}
+				if m[3] != "" {
+					h.newCount, _ = strconv.Atoi(m[3])
+				}
+				hunks = append(hunks, h)
+				if cur = &hunks[len(hunks)-1]; cur.complete() {
+					cur = nil
+				}
+				continue
+			}
+			switch {
+			case line == "", strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, `\`):
+				// file headers, "\ No newline at end of file"
+			case len(hunks) > 0 && strings.ContainsAny(line[:1], " +-"):
+				return nil, fmt.Errorf("hunk %d has more lines than its header (-%d,+%d) counts; fix the @@ line",
+					len(hunks), hunks[len(hunks)-1].oldCount, hunks[len(hunks)-1].newCount)
+			}
+			continue // "diff --git", "index" and other preamble
+		}
+		if strings.HasPrefix(line, `\`) {
+			continue
+		}
+
+		wantOld, wantNew := len(cur.oldLines) < cur.oldCount, len(cur.newLines) < cur.newCount
+		switch {
+		case line == "" && wantOld && wantNew:
+			// Blank context lines often lose their leading space
+			cur.oldLines = append(cur.oldLines, "")
+			cur.newLines = append(cur.newLines, "")
+		case line != "" && line[0] == ' ' && wantOld && wantNew:
+			cur.oldLines = append(cur.oldLines, line[1:])
+			cur.newLines = append(cur.newLines, line[1:])
+		case line != "" && line[0] == '-' && wantOld:
+			cur.oldLines = append(cur.oldLines, line[1:])
+		case line != "" && line[0] == '+' && wantNew:
+			cur.newLines = append(cur.newLines, line[1:])
+		case line == "" || strings.ContainsAny(line[:1], " +-"):
+			return nil, fmt.Errorf("hunk %d has more lines than its header (-%d,+%d) counts; fix the @@ line",
+				len(hunks), cur.oldCount, cur.newCount)
+		case hunkHeader.MatchString(line):
+			return nil, fmt.Errorf("hunk %d has fewer lines than its header (-%d,+%d) counts; fix the @@ line",
+				len(hunks), cur.oldCount, cur.newCount)
+		default:
+			return nil, fmt.Errorf("invalid diff line: %q", line)
+		}
+		if cur.complete() {
+			cur = nil
+		}
+	}
+
+	if cur != nil {
+		return nil, fmt.Errorf("hunk %d has fewer lines than its header (-%d,+%d) counts; fix the @@ line",
+			len(hunks), cur.oldCount, cur.newCount)
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no hunks found; a diff needs \"@@ -l,n +l,n @@\" headers")
+	}
+	return hunks, nil
+}
+
+// findLines returns the index of want in lines at or after min, preferring
+// the position closest to hint. Returns -1 if not found.
+func findLines(lines, want []string, hint, min int) int {
+	matches := func(at int) bool {
+		if at < min || at+len(want) > len(lines) {
+			return false
+		}
+		for i, l := range want {
+			if lines[at+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+
+	if hint < min {
+		hint = min
+	}
+	for d := 0; hint-d >= min || hint+d <= len(lines); d++ {
+		if matches(hint + d) {
+			return hint + d
+		}
+		if d > 0 && matches(hint-d) {
+			return hint - d
+		}
+	}
+	return -1
+}
+
+// changePreview renders the changed region between two versions of a file as
+// "-"/"+" lines with a few lines of context.
+func changePreview(before, after string, context int) string {
+	a := strings.Split(before, "\n")
+	b := strings.Split(after, "\n")
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	start := prefix - context
+	if start < 0 {
+		start = 0
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("@@ line %d @@\n", start+1))
+	for _, l := range a[start:prefix] {
+		sb.WriteString(" " + l + "\n")
+	}
+	for _, l := range a[prefix : len(a)-suffix] {
+		sb.WriteString("-" + l + "\n")
+	}
+	for _, l := range b[prefix : len(b)-suffix] {
+		sb.WriteString("+" + l + "\n")
+	}
+	end := len(a) - suffix + context
+	if end > len(a) {
+		end = len(a)
+	}
+	for _, l := range a[len(a)-suffix : end] {
+		sb.WriteString(" " + l + "\n")
+	}
+
+	preview := sb.String()
+	const maxLen = 4000
+	if len(preview) > maxLen {
+		preview = preview[:maxLen] + fmt.Sprintf("\n... (truncated, %d more chars)", len(preview)-maxLen)
+	}
+	return preview
+}
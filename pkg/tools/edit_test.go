@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyUnifiedDiff(t *testing.T) {
+	sql := "SELECT 1;\n-- old comment\nSELECT 2;\nSELECT 3;\n"
+
+	tests := []struct {
+		name    string
+		content string
+		diff    string
+		want    string
+		hunks   int
+		err     string
+	}{
+		{
+			name:    "single hunk",
+			content: "a\nb\nc\n",
+			diff:    "--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			want:    "a\nB\nc\n",
+			hunks:   1,
+		},
+		{
+			name:    "multiple hunks with drifted line numbers",
+			content: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			diff:    "@@ -1,2 +1,3 @@\n 1\n+1.5\n 2\n@@ -9,2 +10,2 @@\n 7\n-8\n+eight\n",
+			want:    "1\n1.5\n2\n3\n4\n5\n6\n7\neight\n9\n",
+			hunks:   2,
+		},
+		{
+			name:    "removed line starting with --",
+			content: sql,
+			diff:    "--- a/q.sql\n+++ b/q.sql\n@@ -1,3 +1,2 @@\n SELECT 1;\n--- old comment\n SELECT 2;\n",
+			want:    "SELECT 1;\nSELECT 2;\nSELECT 3;\n",
+			hunks:   1,
+		},
+		{
+			name:    "added line starting with ++ and a following hunk",
+			content: "x\ny\nz\n",
+			diff:    "@@ -1,1 +1,2 @@\n x\n+++counter\n@@ -3 +4 @@\n-z\n+Z\n",
+			want:    "x\n++counter\ny\nZ\n",
+			hunks:   2,
+		},
+		{
+			name:    "blank context line without its space",
+			content: "a\n\nb\n",
+			diff:    "@@ -1,3 +1,3 @@\n a\n\n-b\n+c\n",
+			want:    "a\n\nc\n",
+			hunks:   1,
+		},
+		{
+			name:    "context mismatch",
+			content: "a\nb\nc\n",
+			diff:    "@@ -1,2 +1,2 @@\n a\n-x\n+y\n",
+			err:     "does not match the file",
+		},
+		{
+			name:    "hunk shorter than its header",
+			content: "a\nb\nc\n",
+			diff:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n",
+			err:     "fewer lines",
+		},
+		{
+			name:    "hunk shorter than its header before the next hunk",
+			content: "a\nb\nc\n",
+			diff:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n@@ -3 +3 @@\n-c\n+C\n",
+			err:     "fewer lines",
+		},
+		{
+			name:    "hunk longer than its header",
+			content: "a\nb\nc\n",
+			diff:    "@@ -1,2 +1,2 @@\n a\n-b\n+B\n c\n",
+			err:     "more lines",
+		},
+		{
+			name:    "no hunks",
+			content: "a\n",
+			diff:    "--- a/f\n+++ b/f\n",
+			err:     "no hunks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hunks, err := applyUnifiedDiff(tt.content, tt.diff)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected an error containing %q, got %v (result %q)", tt.err, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || hunks != tt.hunks {
+				t.Errorf("got %q with %d hunk(s), want %q with %d", got, hunks, tt.want, tt.hunks)
+			}
+		})
+	}
+}
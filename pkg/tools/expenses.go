@@ -0,0 +1,262 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/expenses"
+)
+
+// ExpensesTool records and reports the spending of the current conversation.
+type ExpensesTool struct {
+	service *expenses.Service
+}
+
+func NewExpensesTool(service *expenses.Service) *ExpensesTool {
+	return &ExpensesTool{service: service}
+}
+
+func (t *ExpensesTool) Name() string {
+	return "expenses"
+}
+
+func (t *ExpensesTool) Description() string {
+	return `Track the user's spending. When they mention money they spent ("I spent 50k on lunch", "paid 1.2tr for electricity"), record it with "add" right away. Actions:
+- "add": Record an expense. Requires: amount (e.g. 50000, "50k", "1.5tr"). Optional: category (food, transport, shopping, bills, health, entertainment, ... default other), note, date (YYYY-MM-DD or "yesterday", default today), currency (default ` + t.service.Currency() + `).
+- "list": Expenses of a month. Optional: month (YYYY-MM, default this month), category.
+- "report": Totals by category against budgets, compared with the month before. Optional: month.
+- "remove": Delete a wrong entry. Requires: expense_id.
+- "budget": Set a monthly budget. Requires: category ("total" for all spending), amount (0 removes it).
+- "budgets": Show the budgets and how much of each is used this month.
+Budget warnings come back from "add"; pass them on to the user.`
+}
+
+func (t *ExpensesTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: add, list, report, remove, budget, budgets",
+				"enum":        []string{"add", "list", "report", "remove", "budget", "budgets"},
+			},
+			"amount": map[string]interface{}{
+				"type":        "string",
+				"description": "Amount as a number or shorthand like \"50k\", \"1.5m\", \"2tr\"",
+			},
+			"category": map[string]interface{}{
+				"type":        "string",
+				"description": "Spending category, e.g. food, transport, bills",
+			},
+			"note": map[string]interface{}{
+				"type":        "string",
+				"description": "What it was for, e.g. \"lunch with Lan\"",
+			},
+			"date": map[string]interface{}{
+				"type":        "string",
+				"description": "Day of the expense: YYYY-MM-DD, today or yesterday",
+			},
+			"currency": map[string]interface{}{
+				"type":        "string",
+				"description": "ISO currency code, if not the default",
+			},
+			"month": map[string]interface{}{
+				"type":        "string",
+				"description": "Month as YYYY-MM (default: this month)",
+			},
+			"expense_id": map[string]interface{}{
+				"type":        "number",
+				"description": "Expense ID (required for remove)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ExpensesTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	owner := ScopeFrom(ctx).ChatKey()
+	if t.service == nil {
+		return "Error: expenses are disabled", nil
+	}
+	if owner == "" {
+		return "Error: expenses are only available in a conversation", nil
+	}
+
+	now := time.Now()
+	category, _ := args["category"].(string)
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		amount, err := expenseAmount(args["amount"])
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		date, err := expenseDate(args["date"], now)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		e := expenses.Expense{Amount: amount, Category: category, Date: date}
+		e.Note, _ = args["note"].(string)
+		e.Currency, _ = args["currency"].(string)
+		added, alerts, err := t.service.Add(owner, e)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		result := "✓ Recorded " + added.Describe()
+		for _, a := range alerts {
+			result += "\n" + a
+		}
+		return result, nil
+
+	case "list", "report":
+		month, err := expenseMonth(args["month"], now)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if action == "report" {
+			report, err := t.service.Report(owner, month)
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err), nil
+			}
+			if report == "" {
+				return fmt.Sprintf("No expenses in %s.", month.Format("January 2006")), nil
+			}
+			return report, nil
+		}
+		list, err := t.service.List(owner, month, month.AddDate(0, 1, -1), category)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if len(list) == 0 {
+			return fmt.Sprintf("No expenses in %s.", month.Format("January 2006")), nil
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Expenses in %s (%d):\n", month.Format("January 2006"), len(list))
+		for _, e := range list {
+			sb.WriteString(e.Describe() + "\n")
+		}
+		return sb.String(), nil
+
+	case "remove":
+		id, ok := args["expense_id"].(float64)
+		if !ok || id <= 0 {
+			return "Error: 'expense_id' is required for remove", nil
+		}
+		e, err := t.service.Remove(owner, int64(id))
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return "✓ Removed " + e.Describe(), nil
+
+	case "budget":
+		if strings.TrimSpace(category) == "" {
+			return "Error: 'category' is required for budget (use \"total\" for all spending)", nil
+		}
+		amount := 0.0
+		if raw, ok := args["amount"]; ok && raw != 0.0 && raw != "0" {
+			var err error
+			if amount, err = expenseAmount(raw); err != nil {
+				return fmt.Sprintf("Error: %v", err), nil
+			}
+		}
+		if err := t.service.SetBudget(owner, category, amount); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if amount == 0 {
+			return fmt.Sprintf("✓ Removed the %s budget", expenses.NormalizeCategory(category)), nil
+		}
+		return fmt.Sprintf("✓ Monthly %s budget: %s %s", expenses.NormalizeCategory(category),
+			expenses.FormatAmount(amount), t.service.Currency()), nil
+
+	case "budgets":
+		return t.budgets(owner, now)
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: add, list, report, remove, budget, budgets", action), nil
+	}
+}
+
+func (t *ExpensesTool) budgets(owner string, now time.Time) (string, error) {
+	budgets, err := t.service.Budgets(owner)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	if len(budgets) == 0 {
+		return "No budgets set. Set one with action \"budget\".", nil
+	}
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	list, err := t.service.List(owner, month, now, "")
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	spent := make(map[string]float64)
+	for _, e := range list {
+		if e.Currency == t.service.Currency() {
+			spent[e.Category] += e.Amount
+			spent[expenses.TotalCategory] += e.Amount
+		}
+	}
+
+	categories := make([]string, 0, len(budgets))
+	for c := range budgets {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Budgets for %s (%s):\n", now.Format("January"), t.service.Currency())
+	for _, c := range categories {
+		fmt.Fprintf(&sb, "- %s: %s of %s (%.0f%%)\n", c, expenses.FormatAmount(spent[c]), expenses.FormatAmount(budgets[c]),
+			spent[c]/budgets[c]*100)
+	}
+	return sb.String(), nil
+}
+
+func expenseAmount(raw interface{}) (float64, error) {
+	switch v := raw.(type) {
+	case float64:
+		if v <= 0 {
+			return 0, fmt.Errorf("amount must be positive")
+		}
+		return v, nil
+	case string:
+		return expenses.ParseAmount(v)
+	}
+	return 0, fmt.Errorf("'amount' is required")
+}
+
+func expenseDate(raw interface{}, now time.Time) (time.Time, error) {
+	s, _ := raw.(string)
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", "today":
+		return now, nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+	d, err := time.ParseInLocation("2006-01-02", s, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD", s)
+	}
+	if d.After(now) {
+		return time.Time{}, fmt.Errorf("date %s is in the future", s)
+	}
+	return d, nil
+}
+
+func expenseMonth(raw interface{}, now time.Time) (time.Time, error) {
+	s, _ := raw.(string)
+	if s = strings.TrimSpace(s); s == "" {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), nil
+	}
+	if m, err := strconv.Atoi(s); err == nil && m >= 1 && m <= 12 {
+		return time.Date(now.Year(), time.Month(m), 1, 0, 0, 0, 0, now.Location()), nil
+	}
+	m, err := time.ParseInLocation("2006-01", s, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q: use YYYY-MM", s)
+	}
+	return m, nil
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// ExtractionProfiles maps domains to site-specific extraction settings.
+type ExtractionProfiles map[string]config.ExtractionProfile
+
+// match returns the profile for a URL's host, preferring the most specific
+// domain ("docs.example.com" over "example.com").
+func (p ExtractionProfiles) match(rawURL string) (config.ExtractionProfile, bool) {
+	if len(p) == 0 {
+		return config.ExtractionProfile{}, false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return config.ExtractionProfile{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	best, bestLen := config.ExtractionProfile{}, -1
+	for domain, profile := range p {
+		domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+		if (host == domain || strings.HasSuffix(host, "."+domain)) && len(domain) > bestLen {
+			best, bestLen = profile, len(domain)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// tableMarkdown renders an HTML table as Markdown rows.
+func tableMarkdown(table *goquery.Selection) string {
+	var rows []string
+	table.Find("tr").Each(func(i int, tr *goquery.Selection) {
+		var cells []string
+		tr.Find("th, td").Each(func(j int, cell *goquery.Selection) {
+			cells = append(cells, strings.ReplaceAll(strings.Join(strings.Fields(cell.Text()), " "), "|", "\\|"))
+		})
+		if len(cells) == 0 {
+			return
+		}
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+		if len(rows) == 1 {
+			rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+		}
+	})
+	return strings.Join(rows, "\n")
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/feeds"
+)
+
+// FeedsTool manages the RSS/Atom subscriptions of the current conversation.
+type FeedsTool struct {
+	service *feeds.Service
+}
+
+func NewFeedsTool(service *feeds.Service) *FeedsTool {
+	return &FeedsTool{service: service}
+}
+
+func (t *FeedsTool) Name() string {
+	return "feeds"
+}
+
+func (t *FeedsTool) Description() string {
+	return `Subscribe this chat to RSS/Atom feeds. New items are pushed automatically. Actions:
+- "subscribe": Follow a feed. Requires: url. Optional: interval_minutes.
+- "unsubscribe": Stop following. Requires: id or url.
+- "list": Show this chat's subscriptions.
+- "preview": Show the latest items of a feed without subscribing. Requires: url.`
+}
+
+func (t *FeedsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: subscribe, unsubscribe, list, preview",
+				"enum":        []string{"subscribe", "unsubscribe", "list", "preview"},
+			},
+			"url": map[string]interface{}{
+				"type":        "string",
+				"description": "Feed URL (RSS or Atom)",
+			},
+			"id": map[string]interface{}{
+				"type":        "number",
+				"description": "Subscription ID (for unsubscribe)",
+			},
+			"interval_minutes": map[string]interface{}{
+				"type":        "number",
+				"description": "How often to check the feed (default from config, minimum 5)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *FeedsTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	owner := ScopeFrom(ctx).ChatKey()
+	if t.service == nil {
+		return "Error: feeds are disabled", nil
+	}
+	if owner == "" {
+		return "Error: feeds are only available in a conversation", nil
+	}
+
+	action, _ := args["action"].(string)
+	url, _ := args["url"].(string)
+	url = strings.TrimSpace(url)
+
+	switch action {
+	case "subscribe":
+		if url == "" {
+			return "Error: 'url' is required for subscribe", nil
+		}
+		var interval time.Duration
+		if m, ok := args["interval_minutes"].(float64); ok && m > 0 {
+			interval = time.Duration(max(m, 5)) * time.Minute
+		}
+		sub, feed, err := t.service.Subscribe(ctx, url, owner, interval)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Subscribed #%d to %s (%d items now, checking every %s). New items will be posted here.",
+			sub.ID, feedTitle(sub), len(feed.Items), sub.Interval), nil
+
+	case "unsubscribe":
+		id, ok := args["id"].(float64)
+		if !ok && url == "" {
+			return "Error: 'id' or 'url' is required for unsubscribe", nil
+		}
+		subID := int64(id)
+		if !ok {
+			subs, err := t.service.Store().List(owner)
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err), nil
+			}
+			for _, s := range subs {
+				if s.URL == url {
+					subID = s.ID
+				}
+			}
+			if subID == 0 {
+				return fmt.Sprintf("Error: not subscribed to %s", url), nil
+			}
+		}
+		if err := t.service.Store().Unsubscribe(owner, subID); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Unsubscribed #%d", subID), nil
+
+	case "list":
+		subs, err := t.service.Store().List(owner)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if len(subs) == 0 {
+			return "No feed subscriptions in this chat.", nil
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Feeds (%d):\n", len(subs))
+		for _, s := range subs {
+			fmt.Fprintf(&sb, "- #%d %s — %s, every %s", s.ID, feedTitle(s), s.URL, s.Interval)
+			if s.LastError != "" {
+				fmt.Fprintf(&sb, " (last check failed: %s)", s.LastError)
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+
+	case "preview":
+		if url == "" {
+			return "Error: 'url' is required for preview", nil
+		}
+		feed, err := t.service.Fetch(ctx, url)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%s (%d items)\n", feed.Title, len(feed.Items))
+		for i, it := range feed.Items {
+			if i == 10 {
+				break
+			}
+			fmt.Fprintf(&sb, "\n- %s\n  %s", it.Title, it.Link)
+			if !it.Published.IsZero() {
+				fmt.Fprintf(&sb, " (%s)", it.Published.Format("2006-01-02"))
+			}
+		}
+		return sb.String(), nil
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: subscribe, unsubscribe, list, preview", action), nil
+	}
+}
+
+func feedTitle(s *feeds.Subscription) string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.URL
+}
@@ -37,6 +37,8 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]interface{})
 		return "", fmt.Errorf("path is required")
 	}
 
+	path = resolvePath(ScopeFrom(ctx).WorkDir, path)
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file: %w", err)
@@ -90,6 +92,7 @@ func (t *WriteFileTool) Execute(ctx context.Context, args map[string]interface{}
 	if !ok {
 		return "", fmt.Errorf("content is required")
 	}
+	path = resolvePath(ScopeFrom(ctx).WorkDir, path)
 
 	// Create parent directories if needed
 	dir := filepath.Dir(path)
@@ -134,7 +137,7 @@ func (t *ListDirTool) Execute(ctx context.Context, args map[string]interface{})
 	}
 
 	// Resolve to absolute path
-	absPath, err := filepath.Abs(path)
+	absPath, err := filepath.Abs(resolvePath(ScopeFrom(ctx).WorkDir, path))
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve path: %w", err)
 	}
@@ -169,6 +172,15 @@ func (t *ListDirTool) Execute(ctx context.Context, args map[string]interface{})
 	return sb.String(), nil
 }
 
+// resolvePath interprets relative paths against the tool's working directory
+// (the active project or the workspace).
+func resolvePath(workingDir, path string) string {
+	if workingDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(workingDir, path)
+}
+
 func formatSize(bytes int64) string {
 	switch {
 	case bytes >= 1<<20:
@@ -0,0 +1,292 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GeoTool looks up places and coordinates and reports the weather at a
+// place or at a location the user shared. It uses Open-Meteo and
+// OpenStreetMap Nominatim, which need no API key.
+type GeoTool struct {
+	client      *http.Client
+	geocodeURL  string
+	reverseURL  string
+	forecastURL string
+}
+
+func NewGeoTool() *GeoTool {
+	return &GeoTool{
+		client:      &http.Client{Timeout: 20 * time.Second},
+		geocodeURL:  "https://geocoding-api.open-meteo.com/v1/search",
+		reverseURL:  "https://nominatim.openstreetmap.org/reverse",
+		forecastURL: "https://api.open-meteo.com/v1/forecast",
+	}
+}
+
+func (t *GeoTool) Name() string {
+	return "geo"
+}
+
+func (t *GeoTool) Description() string {
+	return `Places, coordinates and weather. Actions:
+- "geocode": Find places by name. Requires: place.
+- "reverse": Name the place at coordinates. Requires: latitude, longitude.
+- "weather": Current weather and a 3-day forecast. Requires: place, or latitude and longitude.
+When the user shared a location ("[location: lat, lon]" or "[venue: ...]" in the conversation) and asks about "here", use its coordinates.`
+}
+
+func (t *GeoTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: geocode, reverse, weather",
+				"enum":        []string{"geocode", "reverse", "weather"},
+			},
+			"place": map[string]interface{}{
+				"type":        "string",
+				"description": "Place name, e.g. \"Hanoi\" or \"Lyon, France\"",
+			},
+			"latitude": map[string]interface{}{
+				"type":        "number",
+				"description": "Latitude in decimal degrees",
+			},
+			"longitude": map[string]interface{}{
+				"type":        "number",
+				"description": "Longitude in decimal degrees",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *GeoTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	action, _ := args["action"].(string)
+	place, _ := args["place"].(string)
+	place = strings.TrimSpace(place)
+	lat, hasLat := args["latitude"].(float64)
+	lon, hasLon := args["longitude"].(float64)
+	hasCoords := hasLat && hasLon
+	if hasCoords && (lat < -90 || lat > 90 || lon < -180 || lon > 180) {
+		return fmt.Sprintf("Error: coordinates out of range: %g, %g", lat, lon), nil
+	}
+
+	switch action {
+	case "geocode":
+		if place == "" {
+			return "Error: 'place' is required for geocode", nil
+		}
+		places, err := t.geocode(ctx, place, 5)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if len(places) == 0 {
+			return fmt.Sprintf("No places found for %q.", place), nil
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Places matching %q:\n", place)
+		for _, p := range places {
+			fmt.Fprintf(&sb, "- %s: %.5f, %.5f", p.label(), p.Latitude, p.Longitude)
+			if p.Timezone != "" {
+				fmt.Fprintf(&sb, " (%s)", p.Timezone)
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+
+	case "reverse":
+		if !hasCoords {
+			return "Error: 'latitude' and 'longitude' are required for reverse", nil
+		}
+		name, err := t.reverse(ctx, lat, lon)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("%.5f, %.5f is %s", lat, lon, name), nil
+
+	case "weather":
+		label := ""
+		switch {
+		case hasCoords:
+			label = fmt.Sprintf("%.4f, %.4f", lat, lon)
+			if name, err := t.reverse(ctx, lat, lon); err == nil {
+				label = name
+			}
+		case place != "":
+			places, err := t.geocode(ctx, place, 1)
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err), nil
+			}
+			if len(places) == 0 {
+				return fmt.Sprintf("Error: no place found for %q", place), nil
+			}
+			lat, lon, label = places[0].Latitude, places[0].Longitude, places[0].label()
+		default:
+			return "Error: 'place' or 'latitude' and 'longitude' are required for weather", nil
+		}
+		return t.weather(ctx, lat, lon, label)
+
+	default:
+		return fmt.Sprintf("Error: unknown action %q", action), nil
+	}
+}
+
+type geoPlace struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Admin1    string  `json:"admin1"`
+	Country   string  `json:"country"`
+	Timezone  string  `json:"timezone"`
+}
+
+func (p geoPlace) label() string {
+	parts := []string{p.Name}
+	if p.Admin1 != "" && p.Admin1 != p.Name {
+		parts = append(parts, p.Admin1)
+	}
+	if p.Country != "" {
+		parts = append(parts, p.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (t *GeoTool) geocode(ctx context.Context, place string, count int) ([]geoPlace, error) {
+	q := url.Values{"name": {place}, "count": {fmt.Sprint(count)}, "format": {"json"}}
+	var resp struct {
+		Results []geoPlace `json:"results"`
+	}
+	if err := t.getJSON(ctx, t.geocodeURL+"?"+q.Encode(), &resp); err != nil {
+		return nil, fmt.Errorf("geocoding failed: %w", err)
+	}
+	return resp.Results, nil
+}
+
+func (t *GeoTool) reverse(ctx context.Context, lat, lon float64) (string, error) {
+	q := url.Values{"format": {"jsonv2"}, "lat": {fmt.Sprint(lat)}, "lon": {fmt.Sprint(lon)}, "zoom": {"14"}}
+	var resp struct {
+		DisplayName string `json:"display_name"`
+		Error       string `json:"error"`
+	}
+	if err := t.getJSON(ctx, t.reverseURL+"?"+q.Encode(), &resp); err != nil {
+		return "", fmt.Errorf("reverse geocoding failed: %w", err)
+	}
+	if resp.DisplayName == "" {
+		if resp.Error != "" {
+			return "", fmt.Errorf("reverse geocoding failed: %s", resp.Error)
+		}
+		return "", fmt.Errorf("no place found at %g, %g", lat, lon)
+	}
+	return resp.DisplayName, nil
+}
+
+func (t *GeoTool) weather(ctx context.Context, lat, lon float64, label string) (string, error) {
+	q := url.Values{
+		"latitude":      {fmt.Sprint(lat)},
+		"longitude":     {fmt.Sprint(lon)},
+		"current":       {"temperature_2m,apparent_temperature,relative_humidity_2m,wind_speed_10m,weather_code"},
+		"daily":         {"weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max"},
+		"timezone":      {"auto"},
+		"forecast_days": {"3"},
+	}
+	var resp struct {
+		Timezone string `json:"timezone"`
+		Current  struct {
+			Time        string  `json:"time"`
+			Temperature float64 `json:"temperature_2m"`
+			FeelsLike   float64 `json:"apparent_temperature"`
+			Humidity    float64 `json:"relative_humidity_2m"`
+			Wind        float64 `json:"wind_speed_10m"`
+			Code        int     `json:"weather_code"`
+		} `json:"current"`
+		Daily struct {
+			Time   []string  `json:"time"`
+			Code   []int     `json:"weather_code"`
+			Max    []float64 `json:"temperature_2m_max"`
+			Min    []float64 `json:"temperature_2m_min"`
+			Precip []float64 `json:"precipitation_probability_max"`
+		} `json:"daily"`
+	}
+	if err := t.getJSON(ctx, t.forecastURL+"?"+q.Encode(), &resp); err != nil {
+		return fmt.Sprintf("Error: weather lookup failed: %v", err), nil
+	}
+
+	c := resp.Current
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Weather at %s (%.4f, %.4f)\n", label, lat, lon)
+	fmt.Fprintf(&sb, "Now (%s, %s): %s, %.1f°C (feels like %.1f°C), humidity %.0f%%, wind %.0f km/h\n",
+		c.Time, resp.Timezone, weatherCode(c.Code), c.Temperature, c.FeelsLike, c.Humidity, c.Wind)
+	d := resp.Daily
+	for i := range d.Time {
+		if i >= len(d.Code) || i >= len(d.Max) || i >= len(d.Min) {
+			break
+		}
+		fmt.Fprintf(&sb, "- %s: %s, %.0f–%.0f°C", d.Time[i], weatherCode(d.Code[i]), d.Min[i], d.Max[i])
+		if i < len(d.Precip) {
+			fmt.Fprintf(&sb, ", %.0f%% chance of precipitation", d.Precip[i])
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Source: Open-Meteo")
+	return sb.String(), nil
+}
+
+func (t *GeoTool) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	// Nominatim's usage policy asks for an identifying user agent
+	req.Header.Set("User-Agent", "mclaw (https://github.com/ntminh611/mclaw)")
+	req.Header.Set("Accept", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncateChars(string(body), 200))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// weatherCode describes a WMO weather interpretation code.
+func weatherCode(code int) string {
+	switch {
+	case code == 0:
+		return "clear sky"
+	case code == 1:
+		return "mainly clear"
+	case code == 2:
+		return "partly cloudy"
+	case code == 3:
+		return "overcast"
+	case code == 45 || code == 48:
+		return "fog"
+	case code >= 51 && code <= 57:
+		return "drizzle"
+	case code >= 61 && code <= 67:
+		return "rain"
+	case code >= 71 && code <= 77:
+		return "snow"
+	case code >= 80 && code <= 82:
+		return "rain showers"
+	case code == 85 || code == 86:
+		return "snow showers"
+	case code >= 95:
+		return "thunderstorm"
+	}
+	return fmt.Sprintf("weather code %d", code)
+}
@@ -11,7 +11,8 @@ import (
 
 // HeartbeatTool allows the AI agent to manage heartbeat notes
 type HeartbeatTool struct {
-	service *heartbeat.HeartbeatService
+	service  *heartbeat.HeartbeatService
+	sections []func() string
 }
 
 func NewHeartbeatTool() *HeartbeatTool {
@@ -20,6 +21,18 @@ func NewHeartbeatTool() *HeartbeatTool {
 
 func (t *HeartbeatTool) SetHeartbeatService(hs *heartbeat.HeartbeatService) {
 	t.service = hs
+	for _, section := range t.sections {
+		hs.AddPromptSection(section)
+	}
+}
+
+// AddPromptSection adds a section to heartbeat prompts once the service is
+// attached, letting other tools (tasks) surface state to the heartbeat.
+func (t *HeartbeatTool) AddPromptSection(section func() string) {
+	t.sections = append(t.sections, section)
+	if t.service != nil {
+		t.service.AddPromptSection(section)
+	}
 }
 
 func (t *HeartbeatTool) Name() string {
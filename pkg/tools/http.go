@@ -0,0 +1,239 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/netguard"
+)
+
+// secretRef matches {{secret:NAME}} placeholders.
+var secretRef = regexp.MustCompile(`\{\{secret:([A-Za-z0-9_.-]+)\}\}`)
+
+// HTTPRequestTool calls HTTP APIs with any method, custom headers and a body.
+// Credentials are referenced as {{secret:NAME}} and substituted from
+// tools.http.secrets, so the model never sees their values. Only names
+// listed there resolve: the credentials mclaw itself runs on (provider keys,
+// bot tokens) must never be sendable to an arbitrary URL.
+type HTTPRequestTool struct {
+	secrets  map[string]string
+	timeout  time.Duration
+	maxChars int
+	guard    *netguard.Guard
+}
+
+func NewHTTPRequestTool(secrets map[string]string) *HTTPRequestTool {
+	return &HTTPRequestTool{
+		secrets:  secrets,
+		timeout:  30 * time.Second,
+		maxChars: 20000,
+		guard:    netguard.New(nil),
+	}
+}
+
+// SetNetworkGuard replaces the guard that blocks requests to internal addresses.
+func (t *HTTPRequestTool) SetNetworkGuard(g *netguard.Guard) {
+	t.guard = g
+}
+
+func (t *HTTPRequestTool) Name() string {
+	return "http_request"
+}
+
+func (t *HTTPRequestTool) Description() string {
+	desc := `Send an HTTP request (GET, POST, PUT, PATCH, DELETE) to an API or webhook and return status, headers and body. Use web_fetch for reading web pages.
+Reference credentials as {{secret:NAME}} in the url, headers or body; they are filled in server-side and never shown.`
+	if names := t.secretNames(); len(names) > 0 {
+		desc += "\nAvailable secrets: " + strings.Join(names, ", ")
+	}
+	return desc
+}
+
+func (t *HTTPRequestTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"method": map[string]interface{}{
+				"type":        "string",
+				"description": "HTTP method (default: GET)",
+				"enum":        []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
+			},
+			"url": map[string]interface{}{
+				"type":        "string",
+				"description": "Request URL",
+			},
+			"headers": map[string]interface{}{
+				"type":        "object",
+				"description": "Request headers, e.g. {\"Authorization\": \"Bearer {{secret:HA_TOKEN}}\"}",
+			},
+			"body": map[string]interface{}{
+				"type":        "string",
+				"description": "Raw request body",
+			},
+			"json": map[string]interface{}{
+				"type":        "object",
+				"description": "JSON body (sets Content-Type: application/json); used instead of body",
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+
+func (t *HTTPRequestTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	rawURL, _ := args["url"].(string)
+	if rawURL == "" {
+		return "", fmt.Errorf("url is required")
+	}
+
+	method, _ := args["method"].(string)
+	method = strings.ToUpper(method)
+	if method == "" {
+		method = "GET"
+	}
+
+	var used []string // secret values to redact from the output
+
+	rawURL, err := t.substitute(rawURL, &used)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "Error: url must be an absolute http(s) URL", nil
+	}
+
+	var body io.Reader
+	contentType := ""
+	if j, ok := args["json"]; ok && j != nil {
+		data, err := json.Marshal(j)
+		if err != nil {
+			return fmt.Sprintf("Error: invalid json body: %v", err), nil
+		}
+		s, err := t.substitute(string(data), &used)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		body = strings.NewReader(s)
+		contentType = "application/json"
+	} else if b, ok := args["body"].(string); ok && b != "" {
+		s, err := t.substitute(b, &used)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		body = strings.NewReader(s)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if headers, ok := args["headers"].(map[string]interface{}); ok {
+		for k, v := range headers {
+			s, err := t.substitute(fmt.Sprint(v), &used)
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err), nil
+			}
+			req.Header.Set(k, s)
+		}
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", "mclaw")
+	}
+
+	client := t.guard.Client(t.timeout)
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %s", redact(err.Error(), used))
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxChars)+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	text := string(data)
+	truncated := len(text) > t.maxChars
+	if truncated {
+		text = text[:t.maxChars]
+	}
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		var v interface{}
+		if json.Unmarshal(data, &v) == nil {
+			pretty, _ := json.MarshalIndent(v, "", "  ")
+			text = string(pretty)
+		}
+	}
+
+	headers := make(map[string]string)
+	for _, k := range []string{"Content-Type", "Location", "Retry-After", "X-Request-Id"} {
+		if v := resp.Header.Get(k); v != "" {
+			headers[k] = v
+		}
+	}
+
+	result := map[string]interface{}{
+		"status":      resp.StatusCode,
+		"headers":     headers,
+		"duration_ms": time.Since(start).Milliseconds(),
+		"truncated":   truncated,
+		"body":        text,
+	}
+	out, _ := json.MarshalIndent(result, "", "  ")
+	return redact(string(out), used), nil
+}
+
+// substitute replaces {{secret:NAME}} references, recording used values.
+func (t *HTTPRequestTool) substitute(s string, used *[]string) (string, error) {
+	var missing string
+	out := secretRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := secretRef.FindStringSubmatch(ref)[1]
+		value, ok := t.secret(name)
+		if !ok {
+			missing = name
+			return ref
+		}
+		*used = append(*used, value)
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("unknown secret %q", missing)
+	}
+	return out, nil
+}
+
+func (t *HTTPRequestTool) secret(name string) (string, bool) {
+	v, ok := t.secrets[name]
+	return v, ok && v != ""
+}
+
+func (t *HTTPRequestTool) secretNames() []string {
+	names := make([]string, 0, len(t.secrets))
+	for name := range t.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// redact hides secret values echoed back by the server or in errors.
+func redact(s string, secrets []string) string {
+	for _, v := range secrets {
+		if len(v) >= 4 {
+			s = strings.ReplaceAll(s, v, "[REDACTED]")
+		}
+	}
+	return s
+}
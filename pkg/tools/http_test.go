@@ -0,0 +1,23 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTTPSecretsOnlyFromToolConfig(t *testing.T) {
+	t.Setenv("MCLAW_SECRET_PROVIDERS_OPENAI_API_KEY", "sk-live")
+	tool := NewHTTPRequestTool(map[string]string{"HA_TOKEN": "ha-123"})
+
+	var used []string
+	got, err := tool.substitute("Bearer {{secret:HA_TOKEN}}", &used)
+	if err != nil || got != "Bearer ha-123" {
+		t.Fatalf("expected the configured secret, got %q, %v", got, err)
+	}
+
+	// mclaw's own credentials are not reachable through the tool
+	got, err = tool.substitute("{{secret:providers_openai_api_key}}", &used)
+	if err == nil || strings.Contains(got, "sk-live") {
+		t.Errorf("expected an unknown secret error, got %q, %v", got, err)
+	}
+}
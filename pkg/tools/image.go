@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/providers"
+)
+
+const maxImageSize = 10 << 20 // bytes
+
+const defaultImagePrompt = "Describe this image in detail. If it contains text (a document, screenshot, sign, receipt), transcribe the text exactly."
+
+// DescribeImageTool sends a local image to a vision-capable model and
+// returns its description, so photos can be handled even when the agent's
+// own model is text-only.
+type DescribeImageTool struct {
+	provider providers.LLMProvider
+	model    string
+}
+
+// NewDescribeImageTool creates the tool for the given vision model. A nil
+// provider leaves the tool unavailable.
+func NewDescribeImageTool(provider providers.LLMProvider, model string) *DescribeImageTool {
+	return &DescribeImageTool{provider: provider, model: model}
+}
+
+func (t *DescribeImageTool) Available() (bool, string) {
+	if t.provider == nil {
+		return false, "no vision model configured (agents.defaults.vision_model)"
+	}
+	return true, ""
+}
+
+func (t *DescribeImageTool) Name() string { return "describe_image" }
+
+func (t *DescribeImageTool) Description() string {
+	return "Look at a local image (e.g. a photo the user sent) with a vision model and return a description and any text in it. Pass a question as prompt to ask about something specific."
+}
+
+func (t *DescribeImageTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the image file",
+			},
+			"prompt": map[string]interface{}{
+				"type":        "string",
+				"description": "What to look for or ask about the image (default: describe it and transcribe any text)",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+func (t *DescribeImageTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if t.provider == nil {
+		return "Error: no vision model configured (set agents.defaults.vision_model)", nil
+	}
+
+	path, ok := args["path"].(string)
+	if !ok || path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	path = resolvePath(ScopeFrom(ctx).WorkDir, path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if info.Size() > maxImageSize {
+		return fmt.Sprintf("Error: image is %s; the limit is %s", formatSize(info.Size()), formatSize(maxImageSize)), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return fmt.Sprintf("Error: %s is not an image (detected %s)", path, mimeType), nil
+	}
+
+	prompt, _ := args["prompt"].(string)
+	if strings.TrimSpace(prompt) == "" {
+		prompt = defaultImagePrompt
+	}
+
+	messages := []providers.Message{{
+		Role:    "user",
+		Content: prompt,
+		Parts: []providers.ContentPart{
+			{Type: "text", Text: prompt},
+			{Type: "image_url", ImageURL: &providers.ImageURL{
+				URL: "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data),
+			}},
+		},
+	}}
+
+	resp, err := t.provider.Chat(ctx, messages, nil, t.model, map[string]interface{}{
+		"max_tokens": 1500,
+	})
+	if err != nil {
+		return fmt.Sprintf("Error: vision model %s failed: %v", t.model, err), nil
+	}
+
+	description := strings.TrimSpace(resp.Content)
+	if description == "" {
+		return "The vision model returned no description.", nil
+	}
+	return description, nil
+}
@@ -0,0 +1,403 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ntminh611/mclaw/pkg/cron"
+)
+
+const (
+	journalReadMax    = 20000 // characters returned by read and week
+	journalSearchMax  = 20    // matching entries returned by search
+	journalReflectJob = "journal reflection"
+)
+
+// journalReflectPrompt is the agent turn the weekly reflection job runs.
+const journalReflectPrompt = `Weekly journal reflection: read this week's entries with the journal tool (action "week"). Write a short, warm reflection: recurring themes, wins, struggles, mood over the week and one gentle suggestion for next week. Save it with journal action "save_reflection", then reply with it. If there are no entries this week, say so briefly instead.`
+
+// JournalTool keeps a journal as one Markdown file per day in
+// workspace/journal, with timestamped entries, and can schedule a weekly
+// reflection written by the agent.
+type JournalTool struct {
+	dir         string
+	cronService *cron.CronService
+	mu          sync.Mutex
+}
+
+func NewJournalTool(workspace string) *JournalTool {
+	return &JournalTool{dir: filepath.Join(workspace, "journal")}
+}
+
+// SetCronService enables scheduling the weekly reflection.
+func (t *JournalTool) SetCronService(cs *cron.CronService) {
+	t.cronService = cs
+}
+
+func (t *JournalTool) Name() string {
+	return "journal"
+}
+
+func (t *JournalTool) Description() string {
+	return `The user's personal journal: timestamped entries in one file per day (journal/YYYY-MM-DD.md). Actions:
+- "add": Write an entry, in the user's words. Requires: content. Optional: tags.
+- "read": Show a day. Optional: date (YYYY-MM-DD, today or yesterday; default today).
+- "search": Find past entries containing text. Requires: query. Optional: days (how far back).
+- "week": All entries of the last 7 days, e.g. for a reflection.
+- "save_reflection": Save a weekly reflection. Requires: content.
+- "schedule_reflection": Have a reflection on the week written and sent here every week. Optional: weekday (default sunday), time (HH:MM, default 20:00).
+- "unschedule_reflection": Stop the weekly reflection.
+Use it when the user journals, vents or wants to remember how a day went; use notes for working documents.`
+}
+
+func (t *JournalTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: add, read, search, week, save_reflection, schedule_reflection, unschedule_reflection",
+				"enum":        []string{"add", "read", "search", "week", "save_reflection", "schedule_reflection", "unschedule_reflection"},
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "Entry or reflection text (Markdown)",
+			},
+			"tags": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Tags for the entry, e.g. [\"work\", \"health\"]",
+			},
+			"date": map[string]interface{}{
+				"type":        "string",
+				"description": "Day to read: YYYY-MM-DD, today or yesterday",
+			},
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Text to search for (case-insensitive; also matches #tags)",
+			},
+			"days": map[string]interface{}{
+				"type":        "number",
+				"description": "Only search the last this many days",
+			},
+			"weekday": map[string]interface{}{
+				"type":        "string",
+				"description": "Day of the weekly reflection, e.g. sunday",
+			},
+			"time": map[string]interface{}{
+				"type":        "string",
+				"description": "Local time of the weekly reflection, HH:MM",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *JournalTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	owner := ScopeFrom(ctx).ChatKey()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	content, _ := args["content"].(string)
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		if strings.TrimSpace(content) == "" {
+			return "Error: 'content' is required for add", nil
+		}
+		return t.add(strings.TrimSpace(content), stringList(args["tags"]), now)
+
+	case "read":
+		s, _ := args["date"].(string)
+		day, err := journalDay(s, now)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		data, err := os.ReadFile(t.dayPath(day))
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("No journal entries on %s.", day.Format("Monday, January 2, 2006")), nil
+		}
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return truncateJournal(string(data)), nil
+
+	case "search":
+		query, _ := args["query"].(string)
+		if strings.TrimSpace(query) == "" {
+			return "Error: 'query' is required for search", nil
+		}
+		var since time.Time
+		if n, ok := args["days"].(float64); ok && n > 0 {
+			since = now.AddDate(0, 0, -int(n))
+		}
+		return t.search(strings.TrimSpace(query), since)
+
+	case "week":
+		return t.week(now)
+
+	case "save_reflection":
+		if strings.TrimSpace(content) == "" {
+			return "Error: 'content' is required for save_reflection", nil
+		}
+		year, week := now.ISOWeek()
+		path := filepath.Join(t.dir, "reflections", fmt.Sprintf("%d-W%02d.md", year, week))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		text := fmt.Sprintf("# Reflection, week %d of %d\n\n%s\n", week, year, strings.TrimSpace(content))
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Saved the reflection to journal/reflections/%s", filepath.Base(path)), nil
+
+	case "schedule_reflection", "unschedule_reflection":
+		if t.cronService == nil {
+			return "Error: scheduling is not available", nil
+		}
+		removed := t.removeReflectionJobs(owner)
+		if action == "unschedule_reflection" {
+			if removed == 0 {
+				return "No weekly reflection was scheduled.", nil
+			}
+			return "✓ Stopped the weekly reflection", nil
+		}
+		weekday, _ := args["weekday"].(string)
+		clock, _ := args["time"].(string)
+		return t.scheduleReflection(owner, weekday, clock, now)
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: add, read, search, week, save_reflection, schedule_reflection, unschedule_reflection", action), nil
+	}
+}
+
+func (t *JournalTool) dayPath(day time.Time) string {
+	return filepath.Join(t.dir, day.Format("2006-01-02")+".md")
+}
+
+func (t *JournalTool) add(content string, tags []string, now time.Time) (string, error) {
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	path := t.dayPath(now)
+	var sb strings.Builder
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Fprintf(&sb, "# %s\n", now.Format("Monday, January 2, 2006"))
+	}
+	fmt.Fprintf(&sb, "\n## %s\n\n%s\n", now.Format("15:04"), content)
+	if len(tags) > 0 {
+		for i, tag := range tags {
+			tags[i] = "#" + strings.TrimPrefix(strings.ReplaceAll(tag, " ", "-"), "#")
+		}
+		sb.WriteString("\n" + strings.Join(tags, " ") + "\n")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	defer f.Close()
+	if _, err := f.WriteString(sb.String()); err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	return fmt.Sprintf("✓ Added a %s entry to journal/%s", now.Format("15:04"), filepath.Base(path)), nil
+}
+
+// journalEntry is one "## HH:MM" section of a day file.
+type journalEntry struct {
+	day  time.Time
+	text string
+}
+
+// entries returns the entries of the day files from since on, newest day
+// first.
+func (t *JournalTool) entries(since time.Time) ([]journalEntry, error) {
+	files, err := filepath.Glob(filepath.Join(t.dir, "????-??-??.md"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	from := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.Local)
+
+	var entries []journalEntry
+	for _, f := range files {
+		day, err := time.ParseInLocation("2006-01-02", strings.TrimSuffix(filepath.Base(f), ".md"), time.Local)
+		if err != nil || (!since.IsZero() && day.Before(from)) {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		for _, section := range strings.Split(string(data), "\n## ")[1:] {
+			entries = append(entries, journalEntry{day: day, text: strings.TrimSpace(section)})
+		}
+	}
+	return entries, nil
+}
+
+func (t *JournalTool) search(query string, since time.Time) (string, error) {
+	entries, err := t.entries(since)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	q := strings.ToLower(query)
+	var sb strings.Builder
+	matches := 0
+	for _, e := range entries {
+		if !strings.Contains(strings.ToLower(e.text), q) {
+			continue
+		}
+		if matches++; matches > journalSearchMax {
+			break
+		}
+		clock, body, _ := strings.Cut(e.text, "\n")
+		fmt.Fprintf(&sb, "- %s %s: %s\n", e.day.Format("2006-01-02"), clock, excerptAround(strings.TrimSpace(body), q, 300))
+	}
+	if matches == 0 {
+		return fmt.Sprintf("No journal entries mention %q.", query), nil
+	}
+	header := fmt.Sprintf("Journal entries mentioning %q:\n", query)
+	if matches > journalSearchMax {
+		header = fmt.Sprintf("Journal entries mentioning %q (first %d, newest first):\n", query, journalSearchMax)
+	}
+	return header + sb.String(), nil
+}
+
+func (t *JournalTool) week(now time.Time) (string, error) {
+	entries, err := t.entries(now.AddDate(0, 0, -6))
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	if len(entries) == 0 {
+		return "No journal entries in the last 7 days.", nil
+	}
+	// Oldest first reads like a story
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].day.Before(entries[j].day) })
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Journal entries of the last 7 days (%d):\n", len(entries))
+	var current time.Time
+	for _, e := range entries {
+		if !e.day.Equal(current) {
+			current = e.day
+			fmt.Fprintf(&sb, "\n# %s\n", e.day.Format("Monday, January 2"))
+		}
+		sb.WriteString("\n## " + e.text + "\n")
+	}
+	return truncateJournal(sb.String()), nil
+}
+
+func (t *JournalTool) scheduleReflection(owner, weekday, clock string, now time.Time) (string, error) {
+	channel, chatID, ok := strings.Cut(owner, ":")
+	if !ok {
+		return "Error: the weekly reflection needs a chat to be sent to", nil
+	}
+	day := time.Sunday
+	if weekday != "" {
+		d, ok := parseJournalWeekday(weekday)
+		if !ok {
+			return fmt.Sprintf("Error: invalid weekday %q", weekday), nil
+		}
+		day = d
+	}
+	if clock == "" {
+		clock = "20:00"
+	}
+	at, err := time.ParseInLocation("15:04", clock, now.Location())
+	if err != nil {
+		return fmt.Sprintf("Error: invalid time %q: use HH:MM", clock), nil
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	next = next.AddDate(0, 0, (int(day)-int(next.Weekday())+7)%7)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	anchor, every := next.UnixMilli(), int64(7*24*time.Hour/time.Millisecond)
+	job, err := t.cronService.AddJobPayload(journalReflectJob, cron.CronSchedule{Kind: "every", AtMS: &anchor, EveryMS: &every},
+		cron.CronPayload{Kind: "agent_turn", Message: journalReflectPrompt, Deliver: true, Channel: channel, To: chatID})
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	return fmt.Sprintf("✓ A reflection on the week will be sent here every %s at %s, first on %s (cron job %s)",
+		day, next.Format("15:04"), next.Format("Jan 2"), job.ID), nil
+}
+
+// removeReflectionJobs drops the reflection jobs of the current chat and
+// returns how many there were.
+func (t *JournalTool) removeReflectionJobs(owner string) int {
+	channel, chatID, _ := strings.Cut(owner, ":")
+	removed := 0
+	for _, job := range t.cronService.ListJobs(true) {
+		if job.Name == journalReflectJob && job.Payload.Channel == channel && job.Payload.To == chatID {
+			t.cronService.RemoveJob(job.ID)
+			removed++
+		}
+	}
+	return removed
+}
+
+func journalDay(s string, now time.Time) (time.Time, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", "today":
+		return now, nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+	d, err := time.ParseInLocation("2006-01-02", s, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD", s)
+	}
+	return d, nil
+}
+
+func parseJournalWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || (len(s) >= 3 && strings.HasPrefix(name, s)) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// excerptAround returns up to limit bytes of text around the first match
+// of q, which must be lowercase.
+func excerptAround(text, q string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= limit {
+		return text
+	}
+	start := max(0, strings.Index(strings.ToLower(text), q)-limit/3)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	end := min(len(text), start+limit)
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	out := text[start:end]
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(text) {
+		out += "…"
+	}
+	return out
+}
+
+func truncateJournal(text string) string {
+	if runes := []rune(text); len(runes) > journalReadMax {
+		return string(runes[:journalReadMax]) + fmt.Sprintf("\n... (truncated, %d more characters)", len(runes)-journalReadMax)
+	}
+	return text
+}
@@ -0,0 +1,296 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+const (
+	kubectlTimeout     = 30 * time.Second
+	kubeDefaultTail    = 100
+	kubeMaxTail        = 1000
+	kubeDefaultEvents  = 30
+	kubeMaxOutputChars = 12000
+)
+
+// kubeName matches Kubernetes object names (DNS subdomains), which also
+// keeps them from being read as kubectl flags.
+var kubeName = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+
+// kubeDescribeKinds are the kinds describe accepts, by singular, plural and
+// short name. All are namespaced, so the namespace allow-list holds; nodes
+// and other cluster-scoped kinds, and secrets, are left out on purpose.
+var kubeDescribeKinds = map[string]string{}
+
+func init() {
+	for kind, aliases := range map[string][]string{
+		"pod":                     {"pods", "po"},
+		"deployment":              {"deployments", "deploy"},
+		"replicaset":              {"replicasets", "rs"},
+		"statefulset":             {"statefulsets", "sts"},
+		"daemonset":               {"daemonsets", "ds"},
+		"job":                     {"jobs"},
+		"cronjob":                 {"cronjobs", "cj"},
+		"service":                 {"services", "svc"},
+		"endpoints":               {"ep"},
+		"ingress":                 {"ingresses", "ing"},
+		"configmap":               {"configmaps", "cm"},
+		"persistentvolumeclaim":   {"persistentvolumeclaims", "pvc"},
+		"serviceaccount":          {"serviceaccounts", "sa"},
+		"horizontalpodautoscaler": {"horizontalpodautoscalers", "hpa"},
+		"poddisruptionbudget":     {"poddisruptionbudgets", "pdb"},
+		"networkpolicy":           {"networkpolicies", "netpol"},
+	} {
+		kubeDescribeKinds[kind] = kind
+		for _, alias := range aliases {
+			kubeDescribeKinds[alias] = kind
+		}
+	}
+}
+
+// kubeDescribeKind maps a kind as the model wrote it ("Deployment",
+// "deployments.v1.apps", "po") to one describe accepts. The API group and
+// version are dropped, so "secrets.v1." cannot slip past as another name.
+func kubeDescribeKind(kind string) (string, bool) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	kind, _, _ = strings.Cut(kind, ".")
+	canonical, ok := kubeDescribeKinds[kind]
+	return canonical, ok
+}
+
+// KubernetesTool answers questions about a cluster during incidents: pods,
+// deployments, logs and events. It runs kubectl with the user's kubeconfig,
+// only uses read verbs, and stays inside the configured namespaces.
+type KubernetesTool struct {
+	cfg config.KubernetesToolConfig
+}
+
+func NewKubernetesTool(cfg config.KubernetesToolConfig) *KubernetesTool {
+	if strings.HasPrefix(cfg.Kubeconfig, "~/") {
+		home, _ := os.UserHomeDir()
+		cfg.Kubeconfig = filepath.Join(home, cfg.Kubeconfig[2:])
+	}
+	return &KubernetesTool{cfg: cfg}
+}
+
+func (t *KubernetesTool) Available() (bool, string) {
+	if len(t.cfg.Namespaces) == 0 {
+		return false, "no namespaces configured (tools.kubernetes.namespaces)"
+	}
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return false, "kubectl not found in PATH"
+	}
+	return true, ""
+}
+
+func (t *KubernetesTool) Name() string {
+	return "kubernetes"
+}
+
+func (t *KubernetesTool) Description() string {
+	namespaces := "none configured"
+	if len(t.cfg.Namespaces) > 0 {
+		namespaces = strings.Join(t.cfg.Namespaces, ", ") + " (default " + t.cfg.Namespaces[0] + ")"
+	}
+	return `Read-only view of the user's Kubernetes cluster, for checking on workloads and incidents. Namespaces: ` + namespaces + `. Actions:
+- "pods": Pods with status, restarts, age and node. Optional: namespace, selector (label selector, e.g. "app=api").
+- "deployments": Deployments with ready/up-to-date/available replicas. Optional: namespace, selector.
+- "logs": A pod's log. Requires: name. Optional: namespace, container, tail (lines, default 100), since (e.g. "15m"), previous (the crashed container's log).
+- "events": Recent events, newest last; warnings are the usual clue. Optional: namespace, name (only events about this object), warnings_only.
+- "describe": Full detail of one object. Requires: kind (` + kubeDescribeKindList() + `), name. Optional: namespace.`
+}
+
+func (t *KubernetesTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform",
+				"enum":        []string{"pods", "deployments", "logs", "events", "describe"},
+			},
+			"namespace": map[string]interface{}{
+				"type":        "string",
+				"description": "Namespace, one of: " + strings.Join(t.cfg.Namespaces, ", "),
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Object name (pod for logs)",
+			},
+			"kind": map[string]interface{}{
+				"type":        "string",
+				"description": "Object kind for describe: " + kubeDescribeKindList(),
+			},
+			"selector": map[string]interface{}{
+				"type":        "string",
+				"description": "Label selector, e.g. \"app=api,tier!=cache\"",
+			},
+			"container": map[string]interface{}{
+				"type":        "string",
+				"description": "Container, for pods with several",
+			},
+			"tail": map[string]interface{}{
+				"type":        "number",
+				"description": "Log lines to return (default 100, max 1000)",
+			},
+			"since": map[string]interface{}{
+				"type":        "string",
+				"description": "Only logs newer than this, e.g. \"10m\" or \"2h\"",
+			},
+			"previous": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Log of the previous, crashed container instance",
+			},
+			"warnings_only": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Only Warning events",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *KubernetesTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	action, _ := args["action"].(string)
+	namespace, err := t.namespace(args)
+	if err != nil {
+		return "Error: " + err.Error(), nil
+	}
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	if name != "" && !kubeName.MatchString(name) {
+		return fmt.Sprintf("Error: %q is not a valid object name", name), nil
+	}
+
+	var kubectlArgs []string
+	switch action {
+	case "pods", "deployments":
+		kubectlArgs = []string{"get", action, "-o", "wide"}
+		if selector, _ := args["selector"].(string); selector != "" {
+			if strings.HasPrefix(selector, "-") {
+				return "Error: invalid selector", nil
+			}
+			kubectlArgs = append(kubectlArgs, "-l", selector)
+		}
+	case "logs":
+		if name == "" {
+			return "Error: 'name' (the pod) is required for logs", nil
+		}
+		kubectlArgs = []string{"logs", name, "--tail", fmt.Sprint(kubeDefaultTail)}
+		if n, ok := args["tail"].(float64); ok && n > 0 {
+			kubectlArgs[3] = fmt.Sprint(min(int(n), kubeMaxTail))
+		}
+		if container, _ := args["container"].(string); container != "" {
+			if !kubeName.MatchString(container) {
+				return "Error: invalid container name", nil
+			}
+			kubectlArgs = append(kubectlArgs, "-c", container)
+		}
+		if since, _ := args["since"].(string); since != "" {
+			if _, err := time.ParseDuration(since); err != nil {
+				return fmt.Sprintf("Error: invalid since %q, use e.g. 15m or 2h", since), nil
+			}
+			kubectlArgs = append(kubectlArgs, "--since", since)
+		}
+		if previous, _ := args["previous"].(bool); previous {
+			kubectlArgs = append(kubectlArgs, "--previous")
+		}
+	case "events":
+		kubectlArgs = []string{"get", "events", "--sort-by", ".lastTimestamp"}
+		var fields []string
+		if name != "" {
+			fields = append(fields, "involvedObject.name="+name)
+		}
+		if warnings, _ := args["warnings_only"].(bool); warnings {
+			fields = append(fields, "type=Warning")
+		}
+		if len(fields) > 0 {
+			kubectlArgs = append(kubectlArgs, "--field-selector", strings.Join(fields, ","))
+		}
+	case "describe":
+		raw, _ := args["kind"].(string)
+		if name == "" || strings.TrimSpace(raw) == "" {
+			return "Error: 'kind' and 'name' are required for describe", nil
+		}
+		kind, ok := kubeDescribeKind(raw)
+		if !ok {
+			return fmt.Sprintf("Error: describe does not support %q; use one of: %s", raw, kubeDescribeKindList()), nil
+		}
+		kubectlArgs = []string{"describe", kind, name}
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: pods, deployments, logs, events, describe", action), nil
+	}
+
+	out, err := t.kubectl(ctx, namespace, kubectlArgs...)
+	if err != nil {
+		return fmt.Sprintf("Error: kubectl %s failed: %v\n%s", kubectlArgs[0], err, strings.TrimSpace(out)), nil
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return fmt.Sprintf("No %s found in namespace %s.", action, namespace), nil
+	}
+	if action == "events" {
+		if lines := strings.Split(out, "\n"); len(lines) > kubeDefaultEvents+1 {
+			out = lines[0] + "\n" + strings.Join(lines[len(lines)-kubeDefaultEvents:], "\n")
+		}
+	}
+	if len(out) > kubeMaxOutputChars {
+		// Keep the end: the newest log lines matter most.
+		out = "... (earlier output cut)\n" + out[len(out)-kubeMaxOutputChars:]
+	}
+	return fmt.Sprintf("[namespace %s]\n%s", namespace, out), nil
+}
+
+// kubeDescribeKindList names the kinds describe accepts, for messages.
+func kubeDescribeKindList() string {
+	var kinds []string
+	for alias, kind := range kubeDescribeKinds {
+		if alias == kind {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	return strings.Join(kinds, ", ")
+}
+
+// namespace returns the requested namespace if it is configured, or the
+// default one.
+func (t *KubernetesTool) namespace(args map[string]interface{}) (string, error) {
+	ns, _ := args["namespace"].(string)
+	ns = strings.TrimSpace(ns)
+	if len(t.cfg.Namespaces) == 0 {
+		return "", fmt.Errorf("no namespaces configured (tools.kubernetes.namespaces)")
+	}
+	if ns == "" {
+		return t.cfg.Namespaces[0], nil
+	}
+	for _, allowed := range t.cfg.Namespaces {
+		if ns == allowed {
+			return ns, nil
+		}
+	}
+	return "", fmt.Errorf("namespace %q is not configured (tools.kubernetes.namespaces: %s)", ns, strings.Join(t.cfg.Namespaces, ", "))
+}
+
+func (t *KubernetesTool) kubectl(ctx context.Context, namespace string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, kubectlTimeout)
+	defer cancel()
+	full := []string{"--namespace", namespace, "--request-timeout", "20s"}
+	if t.cfg.Kubeconfig != "" {
+		full = append(full, "--kubeconfig", t.cfg.Kubeconfig)
+	}
+	if t.cfg.Context != "" {
+		full = append(full, "--context", t.cfg.Context)
+	}
+	out, err := exec.CommandContext(ctx, "kubectl", append(full, args...)...).CombinedOutput()
+	return string(out), err
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+func TestKubeDescribeKind(t *testing.T) {
+	tests := []struct {
+		kind string
+		want string
+		ok   bool
+	}{
+		{"pod", "pod", true},
+		{"Pods", "pod", true},
+		{" deploy ", "deployment", true},
+		{"deployments.v1.apps", "deployment", true},
+		{"deployment.apps", "deployment", true},
+		{"svc", "service", true},
+		{"secret", "", false},
+		{"secrets", "", false},
+		{"secret.v1", "", false},
+		{"secrets.v1.", "", false},
+		{"node", "", false},
+		{"nodes.v1", "", false},
+		{"namespace", "", false},
+		{"clusterrole.rbac.authorization.k8s.io", "", false},
+		{"persistentvolume", "", false},
+		{"-o=yaml", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := kubeDescribeKind(tt.kind)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("kubeDescribeKind(%q): expected %q, %v, got %q, %v", tt.kind, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestKubernetesDescribeRefusesKinds(t *testing.T) {
+	tool := NewKubernetesTool(config.KubernetesToolConfig{Namespaces: []string{"prod"}})
+	for _, kind := range []string{"secrets.v1.", "node"} {
+		out, err := tool.Execute(context.Background(), map[string]interface{}{"action": "describe", "kind": kind, "name": "db"})
+		if err != nil || !strings.HasPrefix(out, "Error: describe does not support") {
+			t.Errorf("describe %s: expected a refusal, got %q, %v", kind, out, err)
+		}
+	}
+}
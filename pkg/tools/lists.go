@@ -0,0 +1,440 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	listMaxLists = 50
+	listMaxItems = 200
+)
+
+// ListItem is one entry of a named list.
+type ListItem struct {
+	Text    string    `json:"text"`
+	Done    bool      `json:"done,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// NamedList is a checklist such as "shopping" or "packing".
+type NamedList struct {
+	Name      string     `json:"name"`
+	Items     []ListItem `json:"items"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ListsTool keeps named checklists per conversation in one JSON file,
+// keyed by chat and then by list name.
+type ListsTool struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewListsTool(path string) *ListsTool {
+	return &ListsTool{path: path}
+}
+
+func (t *ListsTool) Name() string {
+	return "lists"
+}
+
+func (t *ListsTool) Description() string {
+	return `Named checklists such as shopping, groceries, packing or todo, kept for this chat. Actions:
+- "add": Add items. Requires: list, items. Creates the list if needed; items already on it are not duplicated.
+- "check": Tick items off (uncheck: true to undo). Requires: list, items (text or item numbers).
+- "remove": Delete items. Requires: list, items.
+- "clear_checked": Delete every ticked item. Requires: list.
+- "show": Show a list with item numbers. Requires: list.
+- "lists": All lists with how many items are left.
+- "delete": Delete a whole list. Requires: list.
+- "share": The list formatted as a message to forward to someone (send it with the message tool if asked). Requires: list. Optional: unchecked_only.`
+}
+
+func (t *ListsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: add, check, remove, clear_checked, show, lists, delete, share",
+				"enum":        []string{"add", "check", "remove", "clear_checked", "show", "lists", "delete", "share"},
+			},
+			"list": map[string]interface{}{
+				"type":        "string",
+				"description": "List name, e.g. shopping, packing",
+			},
+			"items": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Item texts, or item numbers as shown by show",
+			},
+			"uncheck": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Untick the items instead (check action)",
+			},
+			"unchecked_only": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Leave ticked items out (share action)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ListsTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	owner := ScopeFrom(ctx).ChatKey()
+	if owner == "" {
+		return "Error: lists are only available in a conversation", nil
+	}
+
+	action, _ := args["action"].(string)
+	rawName, _ := args["list"].(string)
+	name := listName(rawName)
+	items := listItems(args["items"])
+
+	if action == "lists" {
+		return t.overview(owner)
+	}
+	switch action {
+	case "add", "check", "remove", "clear_checked", "show", "delete", "share":
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: add, check, remove, clear_checked, show, lists, delete, share", action), nil
+	}
+	if name == "" {
+		return fmt.Sprintf("Error: 'list' is required for %s", action), nil
+	}
+	if (action == "add" || action == "check" || action == "remove") && len(items) == 0 {
+		return fmt.Sprintf("Error: 'items' is required for %s", action), nil
+	}
+
+	if action == "show" || action == "share" {
+		lists, err := t.load()
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		l := lists[owner][name]
+		if l == nil {
+			return fmt.Sprintf("There is no %q list.", name), nil
+		}
+		if action == "share" {
+			uncheckedOnly, _ := args["unchecked_only"].(bool)
+			return shareList(l, uncheckedOnly), nil
+		}
+		return showList(l), nil
+	}
+
+	var result string
+	err := t.update(func(lists map[string]map[string]*NamedList) error {
+		chat := lists[owner]
+		if chat == nil {
+			chat = make(map[string]*NamedList)
+			lists[owner] = chat
+		}
+		l := chat[name]
+		if l == nil && action != "add" {
+			return fmt.Errorf("there is no %q list", name)
+		}
+
+		switch action {
+		case "add":
+			if l == nil {
+				if len(chat) >= listMaxLists {
+					return fmt.Errorf("a chat can keep at most %d lists", listMaxLists)
+				}
+				l = &NamedList{Name: name}
+				chat[name] = l
+			}
+			var added, reopened, present []string
+			for _, text := range items {
+				if i := findListItem(l.Items, text, false); i >= 0 {
+					if l.Items[i].Done {
+						l.Items[i].Done = false
+						reopened = append(reopened, l.Items[i].Text)
+					} else {
+						present = append(present, l.Items[i].Text)
+					}
+					continue
+				}
+				if len(l.Items) >= listMaxItems {
+					return fmt.Errorf("a list can hold at most %d items", listMaxItems)
+				}
+				l.Items = append(l.Items, ListItem{Text: text, AddedAt: time.Now()})
+				added = append(added, text)
+			}
+			result = listSummary("Added", added) + listSummary("Back on the list", reopened) + listSummary("Already on it", present)
+
+		case "check", "remove":
+			var done, missing []string
+			indexes := make(map[int]bool)
+			for _, ref := range items {
+				i := findListItem(l.Items, ref, true)
+				if i < 0 {
+					missing = append(missing, ref)
+					continue
+				}
+				indexes[i] = true
+				done = append(done, l.Items[i].Text)
+			}
+			if len(indexes) == 0 {
+				return fmt.Errorf("not on the %s list: %s", name, strings.Join(missing, ", "))
+			}
+			verb := "Removed"
+			if action == "check" {
+				uncheck, _ := args["uncheck"].(bool)
+				for i := range indexes {
+					l.Items[i].Done = !uncheck
+				}
+				verb = "Checked off"
+				if uncheck {
+					verb = "Unchecked"
+				}
+			} else {
+				kept := l.Items[:0]
+				for i, it := range l.Items {
+					if !indexes[i] {
+						kept = append(kept, it)
+					}
+				}
+				l.Items = kept
+			}
+			result = listSummary(verb, done) + listSummary("Not found", missing)
+
+		case "clear_checked":
+			kept := l.Items[:0]
+			for _, it := range l.Items {
+				if !it.Done {
+					kept = append(kept, it)
+				}
+			}
+			result = fmt.Sprintf("✓ Cleared %d checked items\n", len(l.Items)-len(kept))
+			l.Items = kept
+
+		case "delete":
+			delete(chat, name)
+			result = fmt.Sprintf("✓ Deleted the %s list (%d items)", name, len(l.Items))
+			return nil
+		}
+
+		l.UpdatedAt = time.Now()
+		left := 0
+		for _, it := range l.Items {
+			if !it.Done {
+				left++
+			}
+		}
+		result += fmt.Sprintf("%s: %d of %d items left", l.Name, left, len(l.Items))
+		return nil
+	})
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	return result, nil
+}
+
+func (t *ListsTool) overview(owner string) (string, error) {
+	lists, err := t.load()
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	chat := lists[owner]
+	if len(chat) == 0 {
+		return "No lists yet. Start one with action \"add\".", nil
+	}
+	names := make([]string, 0, len(chat))
+	for name := range chat {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString("Lists:\n")
+	for _, name := range names {
+		l := chat[name]
+		left := 0
+		for _, it := range l.Items {
+			if !it.Done {
+				left++
+			}
+		}
+		fmt.Fprintf(&sb, "- %s %s: %d of %d left\n", listEmoji(name), name, left, len(l.Items))
+	}
+	return sb.String(), nil
+}
+
+func (t *ListsTool) load() (map[string]map[string]*NamedList, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.read()
+}
+
+func (t *ListsTool) read() (map[string]map[string]*NamedList, error) {
+	lists := make(map[string]map[string]*NamedList)
+	data, err := os.ReadFile(t.path)
+	if os.IsNotExist(err) {
+		return lists, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lists: %w", err)
+	}
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return nil, fmt.Errorf("failed to parse lists: %w", err)
+	}
+	return lists, nil
+}
+
+// update applies fn to the stored lists and saves them when it succeeds.
+func (t *ListsTool) update(fn func(map[string]map[string]*NamedList) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lists, err := t.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(lists); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(lists, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(t.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save lists: %w", err)
+	}
+	return nil
+}
+
+// findListItem returns the index of the item ref names, or -1. With
+// byNumber, ref may be a 1-based item number; otherwise text is matched
+// case-insensitively, first exactly and then as a unique substring.
+func findListItem(items []ListItem, ref string, byNumber bool) int {
+	if n, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); byNumber && err == nil {
+		if n >= 1 && n <= len(items) {
+			return n - 1
+		}
+		return -1
+	}
+	ref = strings.ToLower(ref)
+	for i, it := range items {
+		if strings.ToLower(it.Text) == ref {
+			return i
+		}
+	}
+	if !byNumber {
+		return -1
+	}
+	found := -1
+	for i, it := range items {
+		if strings.Contains(strings.ToLower(it.Text), ref) {
+			if found >= 0 {
+				return -1 // ambiguous
+			}
+			found = i
+		}
+	}
+	return found
+}
+
+func showList(l *NamedList) string {
+	if len(l.Items) == 0 {
+		return fmt.Sprintf("The %s list is empty.", l.Name)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s:\n", listEmoji(l.Name), l.Name)
+	for i, it := range l.Items {
+		mark := "☐"
+		if it.Done {
+			mark = "☑"
+		}
+		fmt.Fprintf(&sb, "%d. %s %s\n", i+1, mark, it.Text)
+	}
+	return sb.String()
+}
+
+// shareList renders l as a message for someone else: a title, the items
+// left to get and then the ticked ones.
+func shareList(l *NamedList, uncheckedOnly bool) string {
+	var todo, done []string
+	for _, it := range l.Items {
+		if it.Done {
+			done = append(done, "✅ ~"+it.Text+"~")
+		} else {
+			todo = append(todo, "⬜ "+it.Text)
+		}
+	}
+	if len(todo) == 0 && (uncheckedOnly || len(done) == 0) {
+		return fmt.Sprintf("Nothing left on the %s list.", l.Name)
+	}
+	var sb strings.Builder
+	title := []rune(l.Name)
+	title[0] = unicode.ToUpper(title[0])
+	fmt.Fprintf(&sb, "%s **%s list**", listEmoji(l.Name), string(title))
+	if len(todo) > 0 {
+		fmt.Fprintf(&sb, " (%d to go)", len(todo))
+	}
+	sb.WriteString("\n" + strings.Join(todo, "\n"))
+	if !uncheckedOnly && len(done) > 0 {
+		if len(todo) > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n" + strings.Join(done, "\n"))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func listSummary(verb string, items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("✓ %s: %s\n", verb, strings.Join(items, ", "))
+}
+
+// listName lowercases a list name and drops a trailing " list", so
+// "Shopping list" and "shopping" are the same list.
+func listName(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	if trimmed := strings.TrimSuffix(s, " list"); trimmed != "" {
+		s = trimmed
+	}
+	return s
+}
+
+// listItems accepts items as a list or as one comma-separated string.
+func listItems(raw interface{}) []string {
+	if s, ok := raw.(string); ok {
+		var out []string
+		for _, part := range strings.Split(s, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+		return out
+	}
+	return stringList(raw)
+}
+
+func listEmoji(name string) string {
+	switch {
+	case strings.Contains(name, "shop"), strings.Contains(name, "grocer"), strings.Contains(name, "chợ"):
+		return "🛒"
+	case strings.Contains(name, "pack"), strings.Contains(name, "trip"), strings.Contains(name, "travel"):
+		return "🧳"
+	case strings.Contains(name, "todo"), strings.Contains(name, "to-do"), strings.Contains(name, "task"):
+		return "📝"
+	case strings.Contains(name, "gift"):
+		return "🎁"
+	}
+	return "📋"
+}
@@ -0,0 +1,560 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+const (
+	coingeckoAPI   = "https://api.coingecko.com/api/v3"
+	yahooChartAPI  = "https://query1.finance.yahoo.com/v8/finance/chart/"
+	tcbsBarsAPI    = "https://apipubaws.tcbs.com.vn/stock-insight/v2/stock/bars-long-term"
+	marketMaxBytes = 1 << 20
+	marketMaxItems = 30
+)
+
+// Common tickers mapped to CoinGecko IDs, so "btc" doesn't cost a search.
+var coinIDs = map[string]string{
+	"btc": "bitcoin", "eth": "ethereum", "usdt": "tether", "usdc": "usd-coin", "bnb": "binancecoin",
+	"sol": "solana", "xrp": "ripple", "doge": "dogecoin", "ada": "cardano", "trx": "tron",
+	"ton": "the-open-network", "avax": "avalanche-2", "dot": "polkadot", "link": "chainlink",
+	"ltc": "litecoin", "matic": "matic-network", "pol": "polygon-ecosystem-token", "shib": "shiba-inu",
+	"bch": "bitcoin-cash", "xlm": "stellar", "atom": "cosmos", "near": "near", "apt": "aptos",
+	"arb": "arbitrum", "op": "optimism", "sui": "sui",
+}
+
+// Vietnamese indices, which TCBS serves as type=index.
+var vnIndices = map[string]bool{"VNINDEX": true, "VN30": true, "HNXINDEX": true, "HNX30": true, "UPCOMINDEX": true}
+
+// WatchItem is one entry of the market watchlist.
+type WatchItem struct {
+	Kind   string `json:"kind"` // crypto or stock
+	Symbol string `json:"symbol"`
+}
+
+// quote is a normalized price from any source.
+type quote struct {
+	Symbol    string
+	Name      string
+	Price     float64
+	Currency  string
+	ChangePct float64
+	HasChange bool
+	Volume    float64
+	Time      time.Time
+	Source    string
+	Err       error
+}
+
+// MarketTool looks up crypto prices on CoinGecko and stock prices on the
+// configured provider, and keeps a watchlist the heartbeat or cron can
+// check in one call.
+type MarketTool struct {
+	cfg           config.MarketToolConfig
+	client        *http.Client
+	watchlistPath string
+	mu            sync.Mutex
+}
+
+func NewMarketTool(cfg config.MarketToolConfig, watchlistPath string) *MarketTool {
+	if cfg.StocksProvider == "" {
+		cfg.StocksProvider = "yahoo"
+	}
+	if cfg.Currency == "" {
+		cfg.Currency = "usd"
+	}
+	cfg.Currency = strings.ToLower(cfg.Currency)
+	return &MarketTool{cfg: cfg, client: &http.Client{Timeout: 20 * time.Second}, watchlistPath: watchlistPath}
+}
+
+func (t *MarketTool) Name() string {
+	return "market"
+}
+
+func (t *MarketTool) Description() string {
+	stocks := "Yahoo Finance: use Yahoo symbols, e.g. AAPL, VOD.L, 7203.T"
+	if t.cfg.StocksProvider == "tcbs" {
+		stocks = "TCBS, Vietnamese stocks on HOSE, HNX and UPCOM: use tickers like FPT, VCB, HPG, or VNINDEX and VN30"
+	}
+	return `Get current crypto and stock prices as structured data, and keep a watchlist. Prefer this over web search or scraping for prices. Actions:
+- "quote": Prices of some symbols. Requires: kind (crypto or stock), symbols.
+- "watchlist": Prices of everything on the watchlist, e.g. for a heartbeat or cron check.
+- "add" / "remove": Change the watchlist. Requires: kind, symbols.
+Crypto comes from CoinGecko (tickers like BTC, ETH or CoinGecko IDs like "bitcoin"), quoted in ` + strings.ToUpper(t.cfg.Currency) + `. Stocks come from ` + stocks + `.`
+}
+
+func (t *MarketTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: quote, watchlist, add, remove",
+				"enum":        []string{"quote", "watchlist", "add", "remove"},
+			},
+			"kind": map[string]interface{}{
+				"type":        "string",
+				"description": "Asset kind",
+				"enum":        []string{"crypto", "stock"},
+			},
+			"symbols": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Tickers, e.g. [\"BTC\", \"ETH\"] or [\"FPT\", \"VCB\"]",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *MarketTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	action, _ := args["action"].(string)
+	kind, _ := args["kind"].(string)
+	symbols := marketSymbols(args["symbols"])
+
+	switch action {
+	case "quote":
+		if kind != "crypto" && kind != "stock" {
+			return "Error: 'kind' must be crypto or stock", nil
+		}
+		if len(symbols) == 0 {
+			return "Error: 'symbols' is required for quote", nil
+		}
+		items := make([]WatchItem, len(symbols))
+		for i, s := range symbols {
+			items[i] = WatchItem{Kind: kind, Symbol: s}
+		}
+		return formatQuotes(t.quotes(ctx, items)), nil
+
+	case "watchlist":
+		items, err := t.loadWatchlist()
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if len(items) == 0 {
+			return "The watchlist is empty. Add symbols with action \"add\".", nil
+		}
+		return "Watchlist:\n" + formatQuotes(t.quotes(ctx, items)), nil
+
+	case "add", "remove":
+		if kind != "crypto" && kind != "stock" {
+			return "Error: 'kind' must be crypto or stock", nil
+		}
+		if len(symbols) == 0 {
+			return fmt.Sprintf("Error: 'symbols' is required for %s", action), nil
+		}
+		items, err := t.updateWatchlist(kind, symbols, action == "add")
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		names := make([]string, len(items))
+		for i, it := range items {
+			names[i] = it.Symbol + " (" + it.Kind + ")"
+		}
+		if len(names) == 0 {
+			return "✓ The watchlist is now empty.", nil
+		}
+		return fmt.Sprintf("✓ Watchlist (%d): %s", len(items), strings.Join(names, ", ")), nil
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: quote, watchlist, add, remove", action), nil
+	}
+}
+
+// marketSymbols accepts a list or a comma-separated string and normalizes
+// the tickers to upper case.
+func marketSymbols(raw interface{}) []string {
+	var parts []string
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+	case string:
+		parts = strings.Split(v, ",")
+	}
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, p := range parts {
+		p = strings.ToUpper(strings.TrimSpace(p))
+		if p != "" && !seen[p] && len(symbols) < marketMaxItems {
+			seen[p] = true
+			symbols = append(symbols, p)
+		}
+	}
+	return symbols
+}
+
+// quotes fetches the items in order: crypto in one CoinGecko call, stocks
+// one request each.
+func (t *MarketTool) quotes(ctx context.Context, items []WatchItem) []quote {
+	out := make([]quote, len(items))
+	var coins []int
+	var wg sync.WaitGroup
+	for i, it := range items {
+		if it.Kind == "crypto" {
+			coins = append(coins, i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, symbol string) {
+			defer wg.Done()
+			if t.cfg.StocksProvider == "tcbs" {
+				out[i] = t.tcbsQuote(ctx, symbol)
+			} else {
+				out[i] = t.yahooQuote(ctx, symbol)
+			}
+		}(i, it.Symbol)
+	}
+	if len(coins) > 0 {
+		symbols := make([]string, len(coins))
+		for j, i := range coins {
+			symbols[j] = items[i].Symbol
+		}
+		for j, q := range t.cryptoQuotes(ctx, symbols) {
+			out[coins[j]] = q
+		}
+	}
+	wg.Wait()
+	return out
+}
+
+func (t *MarketTool) cryptoQuotes(ctx context.Context, symbols []string) []quote {
+	out := make([]quote, len(symbols))
+	ids := make([]string, len(symbols))
+	for i, s := range symbols {
+		out[i] = quote{Symbol: s, Currency: strings.ToUpper(t.cfg.Currency), Source: "CoinGecko"}
+		id, err := t.coinID(ctx, s)
+		if err != nil {
+			out[i].Err = err
+			continue
+		}
+		ids[i] = id
+		out[i].Name = id
+	}
+
+	var wanted []string
+	for _, id := range ids {
+		if id != "" {
+			wanted = append(wanted, id)
+		}
+	}
+	if len(wanted) == 0 {
+		return out
+	}
+	params := url.Values{
+		"ids":                     {strings.Join(wanted, ",")},
+		"vs_currencies":           {t.cfg.Currency},
+		"include_24hr_change":     {"true"},
+		"include_24hr_vol":        {"true"},
+		"include_last_updated_at": {"true"},
+	}
+	var prices map[string]map[string]float64
+	err := t.getJSON(ctx, coingeckoAPI+"/simple/price?"+params.Encode(), &prices)
+	for i, id := range ids {
+		if id == "" {
+			continue
+		}
+		p, ok := prices[id]
+		switch {
+		case err != nil:
+			out[i].Err = err
+		case !ok:
+			out[i].Err = fmt.Errorf("no price for %s", id)
+		default:
+			out[i].Price = p[t.cfg.Currency]
+			out[i].ChangePct, out[i].HasChange = p[t.cfg.Currency+"_24h_change"]
+			out[i].Volume = p[t.cfg.Currency+"_24h_vol"]
+			if ts := p["last_updated_at"]; ts > 0 {
+				out[i].Time = time.Unix(int64(ts), 0)
+			}
+		}
+	}
+	return out
+}
+
+// coinID maps a ticker to a CoinGecko ID: the built-in table first, then
+// CoinGecko's search, preferring the best-ranked coin with that symbol.
+func (t *MarketTool) coinID(ctx context.Context, symbol string) (string, error) {
+	lower := strings.ToLower(symbol)
+	if id, ok := coinIDs[lower]; ok {
+		return id, nil
+	}
+	var result struct {
+		Coins []struct {
+			ID            string `json:"id"`
+			Symbol        string `json:"symbol"`
+			MarketCapRank int    `json:"market_cap_rank"`
+		} `json:"coins"`
+	}
+	if err := t.getJSON(ctx, coingeckoAPI+"/search?query="+url.QueryEscape(lower), &result); err != nil {
+		return "", err
+	}
+	for _, c := range result.Coins {
+		if c.ID == lower || strings.EqualFold(c.Symbol, symbol) {
+			return c.ID, nil // results come sorted by market cap
+		}
+	}
+	return "", fmt.Errorf("unknown coin %q", symbol)
+}
+
+func (t *MarketTool) yahooQuote(ctx context.Context, symbol string) quote {
+	q := quote{Symbol: symbol, Source: "Yahoo Finance"}
+	var result struct {
+		Chart struct {
+			Result []struct {
+				Meta struct {
+					Currency            string  `json:"currency"`
+					Symbol              string  `json:"symbol"`
+					ExchangeName        string  `json:"fullExchangeName"`
+					LongName            string  `json:"longName"`
+					ShortName           string  `json:"shortName"`
+					Price               float64 `json:"regularMarketPrice"`
+					PreviousClose       float64 `json:"chartPreviousClose"`
+					RegularMarketTime   int64   `json:"regularMarketTime"`
+					RegularMarketVolume float64 `json:"regularMarketVolume"`
+				} `json:"meta"`
+			} `json:"result"`
+			Error *struct {
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"chart"`
+	}
+	err := t.getJSON(ctx, yahooChartAPI+url.PathEscape(symbol)+"?range=1d&interval=1d", &result)
+	switch {
+	case result.Chart.Error != nil:
+		q.Err = fmt.Errorf("%s", result.Chart.Error.Description)
+	case err != nil:
+		q.Err = err
+	case len(result.Chart.Result) == 0:
+		q.Err = fmt.Errorf("unknown symbol %s", symbol)
+	default:
+		m := result.Chart.Result[0].Meta
+		q.Name = m.LongName
+		if q.Name == "" {
+			q.Name = m.ShortName
+		}
+		q.Price, q.Currency, q.Volume = m.Price, m.Currency, m.RegularMarketVolume
+		if m.PreviousClose > 0 {
+			q.ChangePct, q.HasChange = (m.Price-m.PreviousClose)/m.PreviousClose*100, true
+		}
+		if m.RegularMarketTime > 0 {
+			q.Time = time.Unix(m.RegularMarketTime, 0)
+		}
+	}
+	return q
+}
+
+func (t *MarketTool) tcbsQuote(ctx context.Context, symbol string) quote {
+	q := quote{Symbol: symbol, Currency: "VND", Source: "TCBS"}
+	kind := "stock"
+	if vnIndices[symbol] {
+		kind, q.Currency = "index", "points"
+	}
+	params := url.Values{"ticker": {symbol}, "type": {kind}, "resolution": {"D"}, "countBack": {"2"},
+		"to": {strconv.FormatInt(time.Now().Unix(), 10)}}
+	var result struct {
+		Data []struct {
+			Close       float64 `json:"close"`
+			Volume      float64 `json:"volume"`
+			TradingDate string  `json:"tradingDate"`
+		} `json:"data"`
+	}
+	if err := t.getJSON(ctx, tcbsBarsAPI+"?"+params.Encode(), &result); err != nil {
+		q.Err = err
+		return q
+	}
+	n := len(result.Data)
+	if n == 0 {
+		q.Err = fmt.Errorf("unknown symbol %s", symbol)
+		return q
+	}
+	last := result.Data[n-1]
+	q.Price, q.Volume = last.Close, last.Volume
+	if n > 1 && result.Data[n-2].Close > 0 {
+		prev := result.Data[n-2].Close
+		q.ChangePct, q.HasChange = (last.Close-prev)/prev*100, true
+	}
+	if ts, err := time.Parse(time.RFC3339, last.TradingDate); err == nil {
+		q.Time = ts
+	}
+	return q
+}
+
+func (t *MarketTool) getJSON(ctx context.Context, rawURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; mclaw/1.0)")
+	req.Header.Set("Accept", "application/json")
+	if t.cfg.CoinGeckoAPIKey != "" && strings.HasPrefix(rawURL, coingeckoAPI) {
+		req.Header.Set("x-cg-demo-api-key", t.cfg.CoinGeckoAPIKey)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, marketMaxBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("rate limited by %s, try again in a minute", req.URL.Host)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("HTTP %d from %s", resp.StatusCode, req.URL.Host)
+		}
+		return fmt.Errorf("unexpected response from %s: %w", req.URL.Host, err)
+	}
+	return nil
+}
+
+func formatQuotes(quotes []quote) string {
+	var sb strings.Builder
+	for _, q := range quotes {
+		if q.Err != nil {
+			fmt.Fprintf(&sb, "- %s: unavailable (%v)\n", q.Symbol, q.Err)
+			continue
+		}
+		fmt.Fprintf(&sb, "- %s", q.Symbol)
+		if q.Name != "" && !strings.EqualFold(q.Name, q.Symbol) {
+			fmt.Fprintf(&sb, " (%s)", q.Name)
+		}
+		fmt.Fprintf(&sb, ": %s %s", formatPrice(q.Price), q.Currency)
+		if q.HasChange {
+			fmt.Fprintf(&sb, ", %+.2f%%", q.ChangePct)
+			if q.Source == "CoinGecko" {
+				sb.WriteString(" 24h")
+			}
+		}
+		if q.Volume > 0 {
+			fmt.Fprintf(&sb, ", volume %s", formatVolume(q.Volume))
+		}
+		if !q.Time.IsZero() {
+			fmt.Fprintf(&sb, ", as of %s", q.Time.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Fprintf(&sb, " [%s]\n", q.Source)
+	}
+	return sb.String()
+}
+
+// formatPrice keeps significant digits for small prices and groups
+// thousands for large ones, e.g. 0.00001234, 12.35, 134,500.
+func formatPrice(p float64) string {
+	switch {
+	case p == 0:
+		return "0"
+	case p < 1:
+		return strconv.FormatFloat(p, 'g', 4, 64)
+	case p >= 1000:
+		whole := strconv.FormatInt(int64(p+0.5), 10)
+		var sb strings.Builder
+		for i, r := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String()
+	default:
+		return strconv.FormatFloat(p, 'f', 2, 64)
+	}
+}
+
+func formatVolume(v float64) string {
+	switch {
+	case v >= 1e9:
+		return fmt.Sprintf("%.1fB", v/1e9)
+	case v >= 1e6:
+		return fmt.Sprintf("%.1fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("%.1fK", v/1e3)
+	default:
+		return fmt.Sprintf("%.0f", v)
+	}
+}
+
+func (t *MarketTool) loadWatchlist() ([]WatchItem, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.readWatchlist()
+}
+
+func (t *MarketTool) readWatchlist() ([]WatchItem, error) {
+	data, err := os.ReadFile(t.watchlistPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watchlist: %w", err)
+	}
+	var items []WatchItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse watchlist: %w", err)
+	}
+	return items, nil
+}
+
+// updateWatchlist adds or removes symbols of one kind and returns the new
+// watchlist, crypto first.
+func (t *MarketTool) updateWatchlist(kind string, symbols []string, add bool) ([]WatchItem, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	items, err := t.readWatchlist()
+	if err != nil {
+		return nil, err
+	}
+	listed := make(map[WatchItem]bool)
+	for _, it := range items {
+		listed[it] = true
+	}
+	for _, s := range symbols {
+		listed[WatchItem{Kind: kind, Symbol: s}] = add
+	}
+	items = items[:0]
+	for it, keep := range listed {
+		if keep {
+			items = append(items, it)
+		}
+	}
+	if len(items) > marketMaxItems {
+		return nil, fmt.Errorf("the watchlist is limited to %d symbols", marketMaxItems)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].Symbol < items[j].Symbol
+	})
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.watchlistPath), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(t.watchlistPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save watchlist: %w", err)
+	}
+	return items, nil
+}
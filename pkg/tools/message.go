@@ -8,9 +8,7 @@ import (
 type SendCallback func(channel, chatID, content string) error
 
 type MessageTool struct {
-	sendCallback   SendCallback
-	defaultChannel string
-	defaultChatID  string
+	sendCallback SendCallback
 }
 
 func NewMessageTool() *MessageTool {
@@ -46,11 +44,6 @@ func (t *MessageTool) Parameters() map[string]interface{} {
 	}
 }
 
-func (t *MessageTool) SetContext(channel, chatID string) {
-	t.defaultChannel = channel
-	t.defaultChatID = chatID
-}
-
 func (t *MessageTool) SetSendCallback(callback SendCallback) {
 	t.sendCallback = callback
 }
@@ -65,10 +58,10 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]interface{})
 	chatID, _ := args["chat_id"].(string)
 
 	if channel == "" {
-		channel = t.defaultChannel
+		channel = ScopeFrom(ctx).Channel
 	}
 	if chatID == "" {
-		chatID = t.defaultChatID
+		chatID = ScopeFrom(ctx).ChatID
 	}
 
 	if channel == "" || chatID == "" {
@@ -0,0 +1,377 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/audit"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// ToolCall is one invocation passing through the middleware chain.
+type ToolCall struct {
+	Name string
+	Args map[string]interface{}
+	Tool Tool
+}
+
+// Handler executes a tool call.
+type Handler func(ctx context.Context, call *ToolCall) (string, error)
+
+// Middleware wraps tool execution with cross-cutting behavior such as
+// logging, validation or result rewriting. It may modify call.Args before
+// calling next, short-circuit with an error, or post-process the result.
+type Middleware func(next Handler) Handler
+
+// Use appends middleware to the registry's chain. The first middleware
+// added is the outermost.
+func (r *ToolRegistry) Use(mw ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, mw...)
+}
+
+// handler builds the chain around the tool's own Execute. Caller must hold r.mu.
+func (r *ToolRegistry) handler() Handler {
+	h := Handler(func(ctx context.Context, call *ToolCall) (string, error) {
+		return call.Tool.Execute(ctx, call.Args)
+	})
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+	return h
+}
+
+// LoggingMiddleware logs each call's arguments, duration and result size
+// at debug level.
+func LoggingMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (string, error) {
+			args, _ := json.Marshal(call.Args)
+			if len(args) > 300 {
+				args = append(args[:300], "..."...)
+			}
+			start := time.Now()
+			result, err := next(ctx, call)
+			fields := map[string]interface{}{
+				"tool":     call.Name,
+				"args":     string(args),
+				"duration": time.Since(start).String(),
+				"chars":    len(result),
+			}
+			if err != nil {
+				fields["error"] = err.Error()
+			}
+			logger.DebugCF("tools", "Tool call", fields)
+			return result, err
+		}
+	}
+}
+
+// Caller identifies who a tool call is made for.
+type Caller struct {
+	Channel    string
+	SenderID   string
+	SessionKey string
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context that attributes tool calls to caller.
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom returns the context's caller, or a zero Caller outside an
+// agent turn (workflows run from the CLI, tests).
+func CallerFrom(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+// AuditMiddleware records every call in log: the caller, tool, a hash of
+// the arguments, result size, duration and outcome. Failures to write are
+// logged but never fail the call.
+func AuditMiddleware(log *audit.Log) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (string, error) {
+			hash := audit.HashArgs(call.Args) // before other middleware can rewrite them
+			start := time.Now()
+			result, err := next(ctx, call)
+			caller := CallerFrom(ctx)
+			entry := audit.Entry{
+				Time:        start,
+				Channel:     caller.Channel,
+				SenderID:    caller.SenderID,
+				SessionKey:  caller.SessionKey,
+				Tool:        call.Name,
+				ArgsHash:    hash,
+				ResultChars: len(result),
+				DurationMs:  time.Since(start).Milliseconds(),
+				Outcome:     audit.OutcomeOK,
+			}
+			if err != nil {
+				entry.Outcome = audit.OutcomeError
+				entry.Error = err.Error()
+			}
+			if werr := log.Append(entry); werr != nil {
+				logger.WarnCF("tools", "Audit log write failed", map[string]interface{}{"error": werr.Error()})
+			}
+			return result, err
+		}
+	}
+}
+
+// ErrInvalidArgs is wrapped by errors from ValidateArgs. Such errors are
+// the caller's mistake, not a tool failure.
+var ErrInvalidArgs = errors.New("invalid arguments")
+
+// ValidateArgs checks arguments against the tool's JSON schema (required
+// properties, primitive types and enums) before it runs, so every tool
+// gets the same clear error for malformed calls.
+func ValidateArgs() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (string, error) {
+			if call.Args == nil {
+				call.Args = map[string]interface{}{}
+			}
+			if problems := validateSchema(call.Tool.Parameters(), call.Args); len(problems) > 0 {
+				return "", fmt.Errorf("%w: %s", ErrInvalidArgs, strings.Join(problems, "; "))
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+func validateSchema(schema map[string]interface{}, args map[string]interface{}) []string {
+	var problems []string
+	props, _ := schema["properties"].(map[string]interface{})
+
+	for _, name := range requiredNames(schema["required"]) {
+		if v, ok := args[name]; !ok || v == nil {
+			problems = append(problems, fmt.Sprintf("'%s' is required", name))
+		}
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := props[name].(map[string]interface{})
+		if !ok || args[name] == nil {
+			continue // unknown properties are ignored, as most providers do
+		}
+		if p := checkValue(name, prop, args[name]); p != "" {
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
+
+func requiredNames(v interface{}) []string {
+	switch req := v.(type) {
+	case []string:
+		return req
+	case []interface{}:
+		var names []string
+		for _, n := range req {
+			if s, ok := n.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func checkValue(name string, prop map[string]interface{}, value interface{}) string {
+	typ, _ := prop["type"].(string)
+	ok := true
+	switch typ {
+	case "string":
+		_, ok = value.(string)
+	case "number":
+		_, ok = toFloat(value)
+	case "integer":
+		f, isNum := toFloat(value)
+		ok = isNum && f == float64(int64(f))
+	case "boolean":
+		_, ok = value.(bool)
+	case "array":
+		_, ok = value.([]interface{})
+	case "object":
+		_, ok = value.(map[string]interface{})
+	}
+	if !ok {
+		return fmt.Sprintf("'%s' must be a %s, got %s", name, typ, jsonType(value))
+	}
+
+	if enum := enumValues(prop["enum"]); len(enum) > 0 {
+		s := fmt.Sprint(value)
+		for _, e := range enum {
+			if e == s {
+				return ""
+			}
+		}
+		return fmt.Sprintf("'%s' must be one of %s", name, strings.Join(enum, ", "))
+	}
+	return ""
+}
+
+// toFloat accepts JSON numbers and the integer types YAML workflows produce.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func enumValues(v interface{}) []string {
+	switch enum := v.(type) {
+	case []string:
+		return enum
+	case []interface{}:
+		out := make([]string, len(enum))
+		for i, e := range enum {
+			out[i] = fmt.Sprint(e)
+		}
+		return out
+	}
+	return nil
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case float64, float32, int, int64, json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// RedactSecrets masks credentials in tool results and errors, e.g. an API
+// key printed by exec or a .env file read with read_file. redact is
+// normally a redact.Redactor's Redact method.
+func RedactSecrets(redact func(string) string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (string, error) {
+			result, err := next(ctx, call)
+			result = redact(result)
+			if err != nil {
+				if msg := redact(err.Error()); msg != err.Error() {
+					err = fmt.Errorf("%s", msg)
+				}
+			}
+			return result, err
+		}
+	}
+}
+
+// ToolStats are counters for one tool.
+type ToolStats struct {
+	Calls    int
+	Errors   int
+	Duration time.Duration // total
+}
+
+// ToolMetrics counts calls, errors and time spent per tool.
+type ToolMetrics struct {
+	stats map[string]*ToolStats
+	mu    sync.Mutex
+}
+
+func NewToolMetrics() *ToolMetrics {
+	return &ToolMetrics{stats: make(map[string]*ToolStats)}
+}
+
+// Middleware records every call in m.
+func (m *ToolMetrics) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (string, error) {
+			start := time.Now()
+			result, err := next(ctx, call)
+
+			m.mu.Lock()
+			s, ok := m.stats[call.Name]
+			if !ok {
+				s = &ToolStats{}
+				m.stats[call.Name] = s
+			}
+			s.Calls++
+			s.Duration += time.Since(start)
+			if err != nil || strings.HasPrefix(result, "Error:") {
+				s.Errors++
+			}
+			m.mu.Unlock()
+			return result, err
+		}
+	}
+}
+
+// Snapshot returns a copy of the counters keyed by tool name.
+func (m *ToolMetrics) Snapshot() map[string]ToolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]ToolStats, len(m.stats))
+	for name, s := range m.stats {
+		out[name] = *s
+	}
+	return out
+}
+
+// Summary renders the counters, busiest tool first, one line per tool.
+func (m *ToolMetrics) Summary() string {
+	snap := m.Snapshot()
+	names := make([]string, 0, len(snap))
+	for name := range snap {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return snap[names[i]].Calls > snap[names[j]].Calls })
+
+	var lines []string
+	for _, name := range names {
+		s := snap[name]
+		avg := s.Duration / time.Duration(s.Calls)
+		lines = append(lines, fmt.Sprintf("%s: %d calls, %d errors, avg %s", name, s.Calls, s.Errors, avg.Round(time.Millisecond)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DryRun stops every tool from running and returns what would have been
+// called instead, so a dry run touches no files and reaches no services.
+// Arguments are still validated by the middleware before it.
+func DryRun() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *ToolCall) (string, error) {
+			args, _ := json.Marshal(call.Args)
+			logger.InfoCF("tools", "Dry run: skipped tool call", map[string]interface{}{"tool": call.Name})
+			return fmt.Sprintf("Dry run: %s was not run. Arguments: %s", call.Name, args), nil
+		}
+	}
+}
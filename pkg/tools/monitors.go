@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/monitors"
+)
+
+// MonitorsTool manages the uptime monitors of the current conversation.
+type MonitorsTool struct {
+	service *monitors.Service
+}
+
+func NewMonitorsTool(service *monitors.Service) *MonitorsTool {
+	return &MonitorsTool{service: service}
+}
+
+func (t *MonitorsTool) Name() string {
+	return "monitors"
+}
+
+func (t *MonitorsTool) Description() string {
+	return `Watch websites and HTTP endpoints for downtime. Each monitor checks its URL on an interval and alerts this chat once when it goes down and once when it is back up. Actions:
+- "add": Start monitoring. Requires: url. Optional: name, interval_minutes, expect_status (default: any status below 400), keyword (text the page must contain).
+- "list": Show this chat's monitors with their state.
+- "check": Check a monitor right now. Requires: monitor_id.
+- "pause" / "resume": Stop or restart checks. Requires: monitor_id.
+- "remove": Delete a monitor. Requires: monitor_id.
+For a one-off "is it down?" question, use netcheck instead.`
+}
+
+func (t *MonitorsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: add, list, check, pause, resume, remove",
+				"enum":        []string{"add", "list", "check", "pause", "resume", "remove"},
+			},
+			"url": map[string]interface{}{
+				"type":        "string",
+				"description": "URL to check (required for add)",
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Short name used in alerts (default: the host)",
+			},
+			"interval_minutes": map[string]interface{}{
+				"type":        "number",
+				"description": "Minutes between checks (default from config, minimum 1)",
+			},
+			"expect_status": map[string]interface{}{
+				"type":        "number",
+				"description": "Exact HTTP status the URL must return, e.g. 200",
+			},
+			"keyword": map[string]interface{}{
+				"type":        "string",
+				"description": "Text the response must contain (case-insensitive)",
+			},
+			"monitor_id": map[string]interface{}{
+				"type":        "number",
+				"description": "Monitor ID (required for check, pause, resume, remove)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *MonitorsTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	owner := ScopeFrom(ctx).ChatKey()
+	if t.service == nil {
+		return "Error: monitors are disabled", nil
+	}
+	if owner == "" {
+		return "Error: monitors are only available in a conversation", nil
+	}
+
+	now := time.Now()
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		return t.add(owner, args, now)
+	case "list":
+		return t.list(owner, now)
+	case "check", "pause", "resume", "remove":
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: add, list, check, pause, resume, remove", action), nil
+	}
+
+	id, ok := args["monitor_id"].(float64)
+	if !ok || id <= 0 {
+		return fmt.Sprintf("Error: 'monitor_id' is required for %s", action), nil
+	}
+	switch action {
+	case "check":
+		m, res, err := t.service.CheckNow(ctx, owner, int64(id))
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if !res.OK {
+			return fmt.Sprintf("✗ %s check failed: %s (after %s)\n%s", m.Name, res.Error, res.Latency.Round(time.Millisecond), m.Describe(now)), nil
+		}
+		return fmt.Sprintf("✓ %s is up: HTTP %d in %s\n%s", m.Name, res.Status, res.Latency.Round(time.Millisecond), m.Describe(now)), nil
+	case "remove":
+		m, err := t.service.Remove(owner, int64(id))
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Removed monitor #%d %s", m.ID, m.Name), nil
+	default:
+		m, err := t.service.SetPaused(owner, int64(id), action == "pause")
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return "✓ " + m.Describe(now), nil
+	}
+}
+
+func (t *MonitorsTool) add(owner string, args map[string]interface{}, now time.Time) (string, error) {
+	url, _ := args["url"].(string)
+	if strings.TrimSpace(url) == "" {
+		return "Error: 'url' is required for add", nil
+	}
+	m := monitors.Monitor{URL: url}
+	m.Name, _ = args["name"].(string)
+	m.Keyword, _ = args["keyword"].(string)
+	if n, ok := args["interval_minutes"].(float64); ok && n > 0 {
+		m.Interval = time.Duration(n * float64(time.Minute))
+	}
+	if n, ok := args["expect_status"].(float64); ok {
+		m.ExpectStatus = int(n)
+	}
+
+	added, err := t.service.Add(owner, m)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	return fmt.Sprintf("✓ Monitoring #%d %s every %s. Alerts will be posted here when it goes down and when it recovers.\n%s",
+		added.ID, added.Name, added.Interval, added.Describe(now)), nil
+}
+
+func (t *MonitorsTool) list(owner string, now time.Time) (string, error) {
+	list, err := t.service.List(owner)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	if len(list) == 0 {
+		return "No monitors in this chat.", nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Monitors (%d):\n", len(list))
+	for _, m := range list {
+		sb.WriteString(m.Describe(now) + "\n")
+	}
+	return sb.String(), nil
+}
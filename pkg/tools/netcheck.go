@@ -0,0 +1,353 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/netguard"
+)
+
+const (
+	netcheckTimeout      = 10 * time.Second
+	netcheckTraceTimeout = 60 * time.Second
+	netcheckMaxPings     = 10
+)
+
+// probeHost matches hostnames and IPv4 addresses; IPv6 addresses are
+// checked with net.ParseIP. Neither can start with "-" and pass as a flag.
+var probeHost = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_.-]*[A-Za-z0-9])?$`)
+
+// NetCheckTool probes hosts and websites from the machine mclaw runs on:
+// ping, DNS lookups, TCP port checks, HTTP status and traceroute. Probes go
+// through the network guard like web_fetch, so the LAN stays off-limits
+// unless allowed in tools.network.allow_private.
+type NetCheckTool struct {
+	guard *netguard.Guard
+}
+
+func NewNetCheckTool() *NetCheckTool {
+	return &NetCheckTool{guard: netguard.New(nil)}
+}
+
+// SetNetworkGuard replaces the guard that blocks probes of internal addresses.
+func (t *NetCheckTool) SetNetworkGuard(g *netguard.Guard) {
+	t.guard = g
+}
+
+func (t *NetCheckTool) Name() string {
+	return "netcheck"
+}
+
+func (t *NetCheckTool) Description() string {
+	return `Run network diagnostics to find out whether a host or website is actually reachable, instead of guessing. Actions:
+- "http": Fetch a URL and report status code, response time, redirects and TLS certificate expiry. Requires: target (URL or host).
+- "port": Check whether a TCP port accepts connections. Requires: target (host), port.
+- "ping": ICMP ping, falling back to a TCP connect when ping isn't available. Requires: target. Optional: count (default 4).
+- "dns": Look up DNS records. Requires: target (domain). Optional: record (A, AAAA, CNAME, MX, TXT, NS; default A and AAAA).
+- "traceroute": The network path to a host, to see where traffic stops. Requires: target.`
+}
+
+func (t *NetCheckTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Probe to run",
+				"enum":        []string{"http", "port", "ping", "dns", "traceroute"},
+			},
+			"target": map[string]interface{}{
+				"type":        "string",
+				"description": "Host name, IP address or URL",
+			},
+			"port": map[string]interface{}{
+				"type":        "number",
+				"description": "TCP port for the port check",
+			},
+			"count": map[string]interface{}{
+				"type":        "number",
+				"description": "Pings to send (default 4, max 10)",
+			},
+			"record": map[string]interface{}{
+				"type":        "string",
+				"description": "DNS record type",
+				"enum":        []string{"A", "AAAA", "CNAME", "MX", "TXT", "NS"},
+			},
+		},
+		"required": []string{"action", "target"},
+	}
+}
+
+func (t *NetCheckTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	action, _ := args["action"].(string)
+	target, _ := args["target"].(string)
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "Error: 'target' is required", nil
+	}
+
+	if action == "http" {
+		return t.http(ctx, target), nil
+	}
+	host, port := splitTarget(target)
+	if !validProbeHost(host) {
+		return fmt.Sprintf("Error: %q is not a valid host name or address", target), nil
+	}
+	if action == "dns" {
+		record, _ := args["record"].(string)
+		return lookupDNS(ctx, host, strings.ToUpper(record)), nil
+	}
+	if err := t.guard.CheckHost(ctx, host); err != nil {
+		return "Error: " + err.Error(), nil
+	}
+
+	switch action {
+	case "port":
+		if p, ok := args["port"].(float64); ok {
+			port = strconv.Itoa(int(p))
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return "Error: a 'port' between 1 and 65535 is required", nil
+		}
+		return t.port(ctx, host, port), nil
+	case "ping":
+		count := 4
+		if n, ok := args["count"].(float64); ok && n > 0 {
+			count = min(int(n), netcheckMaxPings)
+		}
+		return t.ping(ctx, host, count), nil
+	case "traceroute":
+		return traceroute(ctx, host), nil
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: http, port, ping, dns, traceroute", action), nil
+	}
+}
+
+// splitTarget accepts "host", "host:port" and URLs, returning the host and
+// the port if one was given.
+func splitTarget(target string) (host, port string) {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return u.Hostname(), u.Port()
+	}
+	if h, p, err := net.SplitHostPort(target); err == nil {
+		return h, p
+	}
+	return strings.Trim(target, "[]"), ""
+}
+
+func validProbeHost(host string) bool {
+	return net.ParseIP(host) != nil || probeHost.MatchString(host)
+}
+
+func (t *NetCheckTool) http(ctx context.Context, target string) string {
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+	if err := t.guard.CheckURL(ctx, target); err != nil {
+		return "Error: " + err.Error()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	req.Header.Set("User-Agent", "mclaw-netcheck/1.0")
+
+	var redirects []string
+	client := t.guard.Client(netcheckTimeout)
+	client.CheckRedirect = func(r *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		redirects = append(redirects, r.URL.String())
+		return nil
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		return fmt.Sprintf("✗ %s is DOWN: %v (after %s)", target, err, elapsed.Round(time.Millisecond))
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	var sb strings.Builder
+	mark := "✓"
+	if resp.StatusCode >= 400 {
+		mark = "✗"
+	}
+	fmt.Fprintf(&sb, "%s %s → %s in %s\n", mark, target, resp.Status, elapsed.Round(time.Millisecond))
+	for _, r := range redirects {
+		fmt.Fprintf(&sb, "Redirected to: %s\n", r)
+	}
+	if server := resp.Header.Get("Server"); server != "" {
+		fmt.Fprintf(&sb, "Server: %s\n", server)
+	}
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		cert := resp.TLS.PeerCertificates[0]
+		days := int(time.Until(cert.NotAfter).Hours() / 24)
+		fmt.Fprintf(&sb, "TLS: %s, certificate expires %s (%d days)\n", tlsVersion(resp.TLS.Version), cert.NotAfter.Format("2006-01-02"), days)
+		if days < 14 {
+			sb.WriteString("⚠️ The certificate expires soon.\n")
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func tlsVersion(v uint16) string {
+	switch v {
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	default:
+		return fmt.Sprintf("TLS 0x%04x", v)
+	}
+}
+
+func (t *NetCheckTool) port(ctx context.Context, host, port string) string {
+	ctx, cancel := context.WithTimeout(ctx, netcheckTimeout)
+	defer cancel()
+	start := time.Now()
+	conn, err := t.guard.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		return fmt.Sprintf("✗ %s port %s is closed or filtered: %v (after %s)", host, port, err, elapsed)
+	}
+	conn.Close()
+	return fmt.Sprintf("✓ %s port %s is open (connected in %s)", host, port, elapsed)
+}
+
+func (t *NetCheckTool) ping(ctx context.Context, host string, count int) string {
+	args := []string{"-c", strconv.Itoa(count), "-W", "2", host}
+	if runtime.GOOS == "darwin" {
+		args = []string{"-c", strconv.Itoa(count), "-W", "2000", host}
+	}
+	if _, err := exec.LookPath("ping"); err == nil {
+		ctx, cancel := context.WithTimeout(ctx, time.Duration(count)*3*time.Second+netcheckTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "ping", args...).CombinedOutput()
+		// ping exits 1 when no reply came back and 2 on other errors, e.g.
+		// missing privileges; the TCP fallback covers the latter.
+		if exitErr, ok := err.(*exec.ExitError); err == nil || ok && exitErr.ExitCode() == 1 {
+			return pingSummary(host, string(out), err == nil)
+		}
+	}
+
+	// TCP connect to a common port, for hosts where ICMP isn't possible.
+	for _, port := range []string{"443", "80", "22"} {
+		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		start := time.Now()
+		conn, err := t.guard.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+		cancel()
+		if err == nil {
+			conn.Close()
+			return fmt.Sprintf("✓ %s is reachable (ICMP ping unavailable; TCP connect to port %s took %s)", host, port, time.Since(start).Round(time.Millisecond))
+		}
+	}
+	return fmt.Sprintf("✗ %s did not answer (ICMP ping unavailable; no TCP answer on ports 443, 80 or 22)", host)
+}
+
+// pingSummary keeps ping's statistics lines, which is all the model needs.
+func pingSummary(host, out string, ok bool) string {
+	var stats []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "packets transmitted") || strings.Contains(line, "min/avg/max") {
+			stats = append(stats, strings.TrimSpace(line))
+		}
+	}
+	if len(stats) == 0 {
+		stats = []string{strings.TrimSpace(out)}
+	}
+	if !ok {
+		return fmt.Sprintf("✗ %s did not answer pings\n%s", host, strings.Join(stats, "\n"))
+	}
+	return fmt.Sprintf("✓ %s answers pings\n%s", host, strings.Join(stats, "\n"))
+}
+
+func lookupDNS(ctx context.Context, host, record string) string {
+	ctx, cancel := context.WithTimeout(ctx, netcheckTimeout)
+	defer cancel()
+	r := net.DefaultResolver
+	var lines []string
+	var err error
+
+	switch record {
+	case "", "A", "AAAA":
+		var addrs []net.IPAddr
+		addrs, err = r.LookupIPAddr(ctx, host)
+		for _, a := range addrs {
+			isV4 := a.IP.To4() != nil
+			if record == "" || record == "A" && isV4 || record == "AAAA" && !isV4 {
+				lines = append(lines, a.IP.String())
+			}
+		}
+	case "CNAME":
+		var cname string
+		cname, err = r.LookupCNAME(ctx, host)
+		if cname != "" {
+			lines = append(lines, cname)
+		}
+	case "MX":
+		var mxs []*net.MX
+		mxs, err = r.LookupMX(ctx, host)
+		for _, mx := range mxs {
+			lines = append(lines, fmt.Sprintf("%d %s", mx.Pref, mx.Host))
+		}
+	case "TXT":
+		lines, err = r.LookupTXT(ctx, host)
+	case "NS":
+		var nss []*net.NS
+		nss, err = r.LookupNS(ctx, host)
+		for _, ns := range nss {
+			lines = append(lines, ns.Host)
+		}
+	default:
+		return fmt.Sprintf("Error: unsupported record type %q", record)
+	}
+
+	if record == "" {
+		record = "A/AAAA"
+	}
+	if err != nil {
+		return fmt.Sprintf("✗ %s lookup for %s failed: %v", record, host, err)
+	}
+	if len(lines) == 0 {
+		return fmt.Sprintf("No %s records for %s.", record, host)
+	}
+	return fmt.Sprintf("%s records for %s:\n%s", record, host, strings.Join(lines, "\n"))
+}
+
+func traceroute(ctx context.Context, host string) string {
+	ctx, cancel := context.WithTimeout(ctx, netcheckTraceTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	switch {
+	case hasBinary("traceroute"):
+		cmd = exec.CommandContext(ctx, "traceroute", "-n", "-w", "2", "-q", "1", "-m", "20", host)
+	case hasBinary("tracepath"):
+		cmd = exec.CommandContext(ctx, "tracepath", "-n", "-m", "20", host)
+	default:
+		return "Error: neither traceroute nor tracepath is installed"
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil && len(out) == 0 {
+		return fmt.Sprintf("Error: traceroute failed: %v", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func hasBinary(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	notesMaxBytes = 100 * 1024 // per note
+	notesReadMax  = 20000      // characters returned by read
+)
+
+var noteNameInvalid = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// NotesTool keeps named notes as Markdown files in workspace/memory/notes.
+// Unlike the scratchpad they survive the conversation and are not injected
+// into every prompt, so they suit longer working documents.
+type NotesTool struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewNotesTool(workspace string) *NotesTool {
+	return &NotesTool{dir: filepath.Join(workspace, "memory", "notes")}
+}
+
+func (t *NotesTool) Name() string {
+	return "notes"
+}
+
+func (t *NotesTool) Description() string {
+	return `Durable named notes saved in the workspace, shared by all conversations. Actions:
+- "list": Show all notes with size and last update.
+- "read": Show a note. Requires: name.
+- "append": Add text to the end of a note, creating it if needed. Requires: name, content.
+- "write": Replace a note's content. Requires: name, content.
+- "clear": Delete a note. Requires: name.
+Use notes for research findings, drafts and intermediate results you need again later; use the scratchpad for short state of the current conversation.`
+}
+
+func (t *NotesTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: list, read, append, write, clear",
+				"enum":        []string{"list", "read", "append", "write", "clear"},
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Note name, e.g. \"trip-plan\" (letters, digits, - and _)",
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "Text to append or write",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *NotesTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	action, _ := args["action"].(string)
+	if action == "list" {
+		return t.list()
+	}
+
+	rawName, _ := args["name"].(string)
+	name := noteName(rawName)
+	if name == "" {
+		return fmt.Sprintf("Error: 'name' is required for %s", action), nil
+	}
+	path := filepath.Join(t.dir, name+".md")
+	content, _ := args["content"].(string)
+
+	switch action {
+	case "read":
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("Note %q does not exist. Use action \"list\" to see notes.", name), nil
+		}
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		text := string(data)
+		if text == "" {
+			return fmt.Sprintf("Note %q is empty.", name), nil
+		}
+		if runes := []rune(text); len(runes) > notesReadMax {
+			text = string(runes[len(runes)-notesReadMax:])
+			return fmt.Sprintf("(showing the last %d characters)\n...%s", notesReadMax, text), nil
+		}
+		return text, nil
+
+	case "append", "write":
+		if content == "" {
+			return fmt.Sprintf("Error: 'content' is required for %s", action), nil
+		}
+		if err := os.MkdirAll(t.dir, 0755); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		updated := content
+		if action == "append" {
+			if existing, err := os.ReadFile(path); err == nil && len(existing) > 0 {
+				updated = strings.TrimRight(string(existing), "\n") + "\n" + content
+			}
+		}
+		if !strings.HasSuffix(updated, "\n") {
+			updated += "\n"
+		}
+		if len(updated) > notesMaxBytes {
+			return fmt.Sprintf("Error: note would be %d bytes (limit %d). Condense it with \"write\" or start a new note.", len(updated), notesMaxBytes), nil
+		}
+		if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Saved note %q (%d bytes)", name, len(updated)), nil
+
+	case "clear":
+		if err := os.Remove(path); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Sprintf("Note %q does not exist.", name), nil
+			}
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Deleted note %q", name), nil
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: list, read, append, write, clear", action), nil
+	}
+}
+
+func (t *NotesTool) list() (string, error) {
+	entries, err := os.ReadDir(t.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+
+	type note struct {
+		name    string
+		size    int64
+		updated time.Time
+	}
+	var notes []note
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		notes = append(notes, note{strings.TrimSuffix(e.Name(), ".md"), info.Size(), info.ModTime()})
+	}
+	if len(notes) == 0 {
+		return "No notes yet.", nil
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].updated.After(notes[j].updated) })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Notes (%d):\n", len(notes))
+	for _, n := range notes {
+		fmt.Fprintf(&sb, "- %s (%d bytes, updated %s)\n", n.name, n.size, n.updated.Format("2006-01-02 15:04"))
+	}
+	return sb.String(), nil
+}
+
+// noteName turns user input into a safe file name.
+func noteName(s string) string {
+	s = strings.ToLower(strings.TrimSpace(strings.TrimSuffix(s, ".md")))
+	s = noteNameInvalid.ReplaceAllString(strings.ReplaceAll(s, " ", "-"), "")
+	if len(s) > 64 {
+		s = s[:64]
+	}
+	return strings.Trim(s, "-_")
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/session"
+)
+
+// PinTool manages sticky instructions for the current conversation
+type PinTool struct {
+	sessions *session.SessionManager
+}
+
+func NewPinTool(sm *session.SessionManager) *PinTool {
+	return &PinTool{sessions: sm}
+}
+
+func (t *PinTool) Name() string {
+	return "pin"
+}
+
+func (t *PinTool) Description() string {
+	return `Manage pinned instructions for this conversation. Pinned instructions are always in your context and survive summarization and /reset. Actions:
+- "pin": Pin an instruction. Requires: content.
+- "unpin": Remove a pinned instruction. Requires: pin_id.
+- "list": List pinned instructions.
+Pin only when the user asks for a lasting preference (e.g. "always answer in Vietnamese").`
+}
+
+func (t *PinTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: pin, unpin, list",
+				"enum":        []string{"pin", "unpin", "list"},
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "Instruction to pin (required for pin)",
+			},
+			"pin_id": map[string]interface{}{
+				"type":        "number",
+				"description": "Pin ID (required for unpin)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *PinTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	sessionKey := ScopeFrom(ctx).SessionKey
+	if sessionKey == "" {
+		return "Error: no active conversation", nil
+	}
+
+	action, _ := args["action"].(string)
+
+	switch action {
+	case "pin":
+		content, _ := args["content"].(string)
+		content = strings.TrimSpace(content)
+		if content == "" {
+			return "Error: 'content' is required for pin", nil
+		}
+		pin := t.sessions.AddPin(sessionKey, content)
+		return fmt.Sprintf("✓ Pinned #%d: %s", pin.ID, pin.Content), nil
+
+	case "unpin":
+		id, ok := args["pin_id"].(float64)
+		if !ok {
+			return "Error: 'pin_id' is required for unpin", nil
+		}
+		if t.sessions.RemovePin(sessionKey, int(id)) {
+			return fmt.Sprintf("✓ Unpinned #%d", int(id)), nil
+		}
+		return fmt.Sprintf("Pin #%d not found", int(id)), nil
+
+	case "list":
+		return formatPins(t.sessions.GetPins(sessionKey)), nil
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: pin, unpin, list", action), nil
+	}
+}
+
+// formatPins renders pinned instructions as a numbered list.
+func formatPins(pins []session.Pin) string {
+	if len(pins) == 0 {
+		return "No pinned instructions."
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Pinned instructions (%d):\n", len(pins)))
+	for _, p := range pins {
+		sb.WriteString(fmt.Sprintf("#%d %s\n", p.ID, p.Content))
+	}
+	return sb.String()
+}
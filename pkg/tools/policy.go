@@ -0,0 +1,32 @@
+package tools
+
+import "github.com/ntminh611/mclaw/pkg/config"
+
+// PolicyDenied returns the tools blocked by config for a chat, mapped to the
+// reason shown to the user. Policies for "*" apply to every channel.
+func PolicyDenied(policies map[string]config.ToolPolicyConfig, channel string, isGroup bool) map[string]string {
+	denied := make(map[string]string)
+	for _, key := range []string{"*", channel} {
+		policy, ok := policies[key]
+		if !ok {
+			continue
+		}
+		for _, name := range policy.Deny {
+			denied[name] = "disabled for " + channel + " by config"
+		}
+		if isGroup {
+			for _, name := range policy.GroupDeny {
+				denied[name] = "disabled in group chats by config"
+			}
+		}
+	}
+	return denied
+}
+
+// IsGroupChat reports whether inbound message metadata describes a group
+// conversation, using the keys each channel sets.
+func IsGroupChat(metadata map[string]string) bool {
+	return metadata["is_group"] == "true" ||
+		metadata["is_dm"] == "false" ||
+		metadata["chat_type"] == "group"
+}
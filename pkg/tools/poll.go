@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+)
+
+// Telegram's limits on polls.
+const (
+	maxPollOptions  = 10
+	maxPollQuestion = 300
+	maxPollOption   = 100
+)
+
+// PollTool asks the current chat a question with fixed answers. Telegram
+// shows a native poll and reports each vote back as a message; other
+// channels get the options as a numbered list.
+type PollTool struct {
+	bus *bus.MessageBus
+}
+
+func NewPollTool(mb *bus.MessageBus) *PollTool {
+	return &PollTool{bus: mb}
+}
+
+func (t *PollTool) Name() string {
+	return "poll"
+}
+
+func (t *PollTool) Description() string {
+	return "Ask the user (or the group) a question with 2-10 fixed answers, shown as a poll. Votes arrive later as messages, so end your turn after creating the poll."
+}
+
+func (t *PollTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"question": map[string]interface{}{
+				"type":        "string",
+				"description": "The question (up to 300 characters)",
+			},
+			"options": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "2 to 10 answers (up to 100 characters each)",
+			},
+			"multiple": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Allow choosing several answers (default false)",
+			},
+		},
+		"required": []string{"question", "options"},
+	}
+}
+
+func (t *PollTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	channel, chatID, ok := strings.Cut(ScopeFrom(ctx).ChatKey(), ":")
+	if !ok || chatID == "" {
+		return "Error: polls are only available in a chat", nil
+	}
+
+	question, _ := args["question"].(string)
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return "Error: 'question' is required", nil
+	}
+	if len([]rune(question)) > maxPollQuestion {
+		return fmt.Sprintf("Error: the question is longer than %d characters", maxPollQuestion), nil
+	}
+
+	raw, _ := args["options"].([]interface{})
+	var options []string
+	for _, o := range raw {
+		s, _ := o.(string)
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if len([]rune(s)) > maxPollOption {
+			return fmt.Sprintf("Error: option %q is longer than %d characters", s, maxPollOption), nil
+		}
+		options = append(options, s)
+	}
+	if len(options) < 2 || len(options) > maxPollOptions {
+		return fmt.Sprintf("Error: a poll needs 2 to %d options, got %d", maxPollOptions, len(options)), nil
+	}
+	multiple, _ := args["multiple"].(bool)
+
+	// The text version, for channels without native polls
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📊 %s\n", question)
+	for i, o := range options {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, o)
+	}
+	if multiple {
+		sb.WriteString("Reply with the numbers of your choices.")
+	} else {
+		sb.WriteString("Reply with the number of your choice.")
+	}
+
+	t.bus.PublishOutbound(bus.OutboundMessage{
+		Channel: channel,
+		ChatID:  chatID,
+		Content: sb.String(),
+		Poll:    &bus.Poll{Question: question, Options: options, Multiple: multiple},
+	})
+	return fmt.Sprintf("Poll sent with %d options. Votes will arrive as messages; don't ask the question again.", len(options)), nil
+}
@@ -3,18 +3,84 @@ package tools
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 type ToolRegistry struct {
-	tools map[string]Tool
-	mu    sync.RWMutex
+	tools       map[string]Tool
+	unavailable map[string]unavailableMark
+	middleware  []Middleware
+	mu          sync.RWMutex
+}
+
+// unavailableMark records a runtime outage reported via MarkUnavailable.
+type unavailableMark struct {
+	reason string
+	until  time.Time
 }
 
 func NewToolRegistry() *ToolRegistry {
 	return &ToolRegistry{
-		tools: make(map[string]Tool),
+		tools:       make(map[string]Tool),
+		unavailable: make(map[string]unavailableMark),
+	}
+}
+
+// MarkUnavailable hides a tool from the model for the given duration,
+// e.g. after repeated failures from its backing service.
+func (r *ToolRegistry) MarkUnavailable(name, reason string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unavailable[name] = unavailableMark{reason: reason, until: time.Now().Add(d)}
+}
+
+// MarkAvailable clears a runtime outage mark.
+func (r *ToolRegistry) MarkAvailable(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.unavailable, name)
+}
+
+// availability reports whether a tool can be offered to the model. Caller must hold r.mu.
+func (r *ToolRegistry) availability(name string, tool Tool) (bool, string) {
+	if mark, ok := r.unavailable[name]; ok && time.Now().Before(mark.until) {
+		return false, mark.reason
+	}
+	if checker, ok := tool.(AvailabilityChecker); ok {
+		return checker.Available()
 	}
+	return true, ""
+}
+
+// IsAvailable reports whether the named tool is registered and currently usable.
+func (r *ToolRegistry) IsAvailable(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[name]
+	if !ok {
+		return false
+	}
+	available, _ := r.availability(name, tool)
+	return available
+}
+
+// UnavailableSummary describes tools that are hidden from the model and why,
+// one "- name: reason" line per tool. Empty when everything is available.
+func (r *ToolRegistry) UnavailableSummary() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var lines []string
+	for name, tool := range r.tools {
+		if ok, reason := r.availability(name, tool); !ok {
+			lines = append(lines, fmt.Sprintf("- %s: %s", name, reason))
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
 }
 
 func (r *ToolRegistry) Register(tool Tool) {
@@ -30,20 +96,53 @@ func (r *ToolRegistry) Get(name string) (Tool, bool) {
 	return tool, ok
 }
 
+// Names returns the names of all registered tools, sorted.
+func (r *ToolRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.tools))
+	for name := range r.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Execute runs the named tool in the scope of ctx (see WithScope): tools the
+// scope does not allow are refused.
 func (r *ToolRegistry) Execute(ctx context.Context, name string, args map[string]interface{}) (string, error) {
 	tool, ok := r.Get(name)
 	if !ok {
 		return "", fmt.Errorf("tool '%s' not found", name)
 	}
-	return tool.Execute(ctx, args)
+	if blocked := ScopeFrom(ctx).blocked(name); blocked != "" {
+		return "", fmt.Errorf("tool '%s' is %s", name, blocked)
+	}
+	r.mu.RLock()
+	available, reason := r.availability(name, tool)
+	handler := r.handler()
+	r.mu.RUnlock()
+	if !available {
+		return "", fmt.Errorf("tool '%s' is unavailable: %s", name, reason)
+	}
+	return handler(ctx, &ToolCall{Name: name, Args: args, Tool: tool})
 }
 
-func (r *ToolRegistry) GetDefinitions() []map[string]interface{} {
+// GetDefinitions returns the schemas of the tools available in the scope
+// of ctx.
+func (r *ToolRegistry) GetDefinitions(ctx context.Context) []map[string]interface{} {
+	scope := ScopeFrom(ctx)
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	definitions := make([]map[string]interface{}, 0, len(r.tools))
-	for _, tool := range r.tools {
+	for name, tool := range r.tools {
+		if scope.blocked(name) != "" {
+			continue
+		}
+		if ok, _ := r.availability(name, tool); !ok {
+			continue
+		}
 		definitions = append(definitions, ToolToSchema(tool))
 	}
 	return definitions
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/reminders"
+)
+
+// RemindTool sets, lists, snoozes and cancels reminders for the current
+// conversation.
+type RemindTool struct {
+	service *reminders.Service
+}
+
+func NewRemindTool(service *reminders.Service) *RemindTool {
+	return &RemindTool{service: service}
+}
+
+func (t *RemindTool) Name() string {
+	return "remind"
+}
+
+func (t *RemindTool) Description() string {
+	return `Remind the user of something at a given time, once or repeatedly. The reminder is sent to this chat. Actions:
+- "add": Set a reminder. Requires: text, when. Optional: repeat.
+- "list": List pending reminders. Optional: include_past.
+- "snooze": Push a reminder back. Requires: reminder_id. Optional: until (default 10 minutes).
+- "cancel": Cancel a reminder and all its repeats. Requires: reminder_id.
+"when" understands "in 20 minutes", "tomorrow 9am", "friday at 18:30", "2026-03-01 08:00" and recurring phrases like "every weekday at 8:30" or "every 2 hours".`
+}
+
+func (t *RemindTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: add, list, snooze, cancel",
+				"enum":        []string{"add", "list", "snooze", "cancel"},
+			},
+			"text": map[string]interface{}{
+				"type":        "string",
+				"description": "What to remind the user of, written as the reminder itself, e.g. \"Take the laundry out\" (required for add)",
+			},
+			"when": map[string]interface{}{
+				"type":        "string",
+				"description": "When the reminder is due, in the user's words (required for add)",
+			},
+			"repeat": map[string]interface{}{
+				"type":        "string",
+				"description": "Repeat rule if not given in when: daily, weekdays, weekly, monthly or an interval like \"every 3 hours\"",
+			},
+			"reminder_id": map[string]interface{}{
+				"type":        "number",
+				"description": "Reminder ID (required for snooze, cancel)",
+			},
+			"until": map[string]interface{}{
+				"type":        "string",
+				"description": "Snooze length or time, e.g. \"30m\", \"in 2 hours\", \"tomorrow 9am\"",
+			},
+			"include_past": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also list delivered and cancelled reminders",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *RemindTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	owner := ScopeFrom(ctx).ChatKey()
+	if t.service == nil {
+		return "Error: reminders not available", nil
+	}
+	if owner == "" {
+		return "Error: reminders are only available in a conversation", nil
+	}
+
+	now := time.Now()
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		return t.add(owner, args, now)
+	case "list":
+		includePast, _ := args["include_past"].(bool)
+		return t.list(owner, includePast, now)
+	case "snooze":
+		id, ok := reminderID(args)
+		if !ok {
+			return "Error: 'reminder_id' is required for snooze", nil
+		}
+		until := now.Add(reminders.DefaultSnooze)
+		if s, _ := args["until"].(string); strings.TrimSpace(s) != "" {
+			parsed, err := reminders.ParseWhen(s, now)
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err), nil
+			}
+			until = parsed
+		}
+		r, err := t.service.Snooze(owner, id, until)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return "✓ Snoozed " + r.Describe(now), nil
+	case "cancel":
+		id, ok := reminderID(args)
+		if !ok {
+			return "Error: 'reminder_id' is required for cancel", nil
+		}
+		r, err := t.service.Cancel(owner, id)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Cancelled reminder #%d: %s", r.ID, r.Text), nil
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: add, list, snooze, cancel", action), nil
+	}
+}
+
+func (t *RemindTool) add(owner string, args map[string]interface{}, now time.Time) (string, error) {
+	text, _ := args["text"].(string)
+	when, _ := args["when"].(string)
+	if strings.TrimSpace(text) == "" || strings.TrimSpace(when) == "" {
+		return "Error: 'text' and 'when' are required for add", nil
+	}
+
+	due, repeat, err := reminders.ParseSchedule(when, now)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	if s, _ := args["repeat"].(string); strings.TrimSpace(s) != "" && repeat == "" {
+		if repeat, err = reminders.ParseRepeat(s); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+	}
+
+	r, err := t.service.Add(owner, text, due, repeat)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	return "✓ Reminder set: " + r.Describe(now), nil
+}
+
+func (t *RemindTool) list(owner string, includePast bool, now time.Time) (string, error) {
+	list, err := t.service.List(owner, includePast)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	if len(list) == 0 {
+		return "No pending reminders.", nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Reminders (%d):\n", len(list))
+	for _, r := range list {
+		sb.WriteString("- " + r.Describe(now) + "\n")
+	}
+	return sb.String(), nil
+}
+
+func reminderID(args map[string]interface{}) (int64, bool) {
+	id, ok := args["reminder_id"].(float64)
+	if !ok || id <= 0 {
+		return 0, false
+	}
+	return int64(id), true
+}
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"hash/fnv"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	shingleSize = 5 // words per shingle
+	// A text whose shingles were mostly seen before is a near-duplicate
+	duplicateRatio = 0.8
+	// Paragraphs shorter than this are never dropped (headings, list items)
+	minDedupChars = 80
+)
+
+// ResearchCache remembers URLs and text returned by web tools during one
+// agent turn, so repeated searches and fetches don't flood the context with
+// near-duplicate snippets. Attach it to the turn's context with
+// WithResearchCache.
+type ResearchCache struct {
+	urls     map[string]bool
+	shingles map[uint64]bool
+	mu       sync.Mutex
+}
+
+type researchCacheKey struct{}
+
+func NewResearchCache() *ResearchCache {
+	return &ResearchCache{
+		urls:     make(map[string]bool),
+		shingles: make(map[uint64]bool),
+	}
+}
+
+// WithResearchCache returns a context carrying a fresh research cache.
+func WithResearchCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, researchCacheKey{}, NewResearchCache())
+}
+
+// researchCacheFrom returns the context's cache, or nil outside an agent turn.
+func researchCacheFrom(ctx context.Context) *ResearchCache {
+	cache, _ := ctx.Value(researchCacheKey{}).(*ResearchCache)
+	return cache
+}
+
+// SeenURL records a URL and reports whether it was already seen.
+func (c *ResearchCache) SeenURL(rawURL string) bool {
+	key := normalizeURL(rawURL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.urls[key] {
+		return true
+	}
+	c.urls[key] = true
+	return false
+}
+
+// IsDuplicate reports whether text is a near-duplicate of text seen before,
+// and records its shingles either way.
+func (c *ResearchCache) IsDuplicate(text string) bool {
+	hashes := shingleHashes(text)
+	if len(hashes) == 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := 0
+	for _, h := range hashes {
+		if c.shingles[h] {
+			seen++
+		}
+		c.shingles[h] = true
+	}
+	return float64(seen)/float64(len(hashes)) >= duplicateRatio
+}
+
+// DedupParagraphs drops paragraphs of a fetched document that were already
+// seen in this turn. Returns the remaining text and the number dropped.
+func (c *ResearchCache) DedupParagraphs(text string) (string, int) {
+	paragraphs := strings.Split(text, "\n")
+	kept := paragraphs[:0]
+	dropped := 0
+	for _, p := range paragraphs {
+		if len(strings.TrimSpace(p)) >= minDedupChars && c.IsDuplicate(p) {
+			dropped++
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return strings.Join(kept, "\n"), dropped
+}
+
+// normalizeURL strips fragments, tracking parameters and trailing slashes so
+// trivially different links to the same page compare equal.
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(strings.TrimPrefix(u.Host, "www."))
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+
+	q := u.Query()
+	for key := range q {
+		if strings.HasPrefix(key, "utm_") || key == "fbclid" || key == "gclid" || key == "ref" {
+			q.Del(key)
+		}
+	}
+	u.RawQuery = q.Encode()
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
+
+// shingleHashes hashes each run of shingleSize consecutive normalized words.
+func shingleHashes(text string) []uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < shingleSize {
+		words = append(words, make([]string, shingleSize-len(words))...)
+	}
+
+	hashes := make([]uint64, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+shingleSize], " ")))
+		hashes = append(hashes, h.Sum64())
+	}
+	return hashes
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/ntminh611/mclaw/pkg/session"
+)
+
+// Scope is what one tool call works on: the chat replies and cron jobs go
+// to, the conversation whose state session tools use, the directory file
+// tools resolve paths against, and the tools the chat may use. It travels
+// in the call's context, so turns and workflow runs going on at the same
+// time never see each other's scope.
+type Scope struct {
+	Channel    string
+	ChatID     string
+	Group      bool              // the chat is a group chat, for tools.policy group_deny
+	SessionKey string            // "channel:chat_id", possibly with a topic
+	WorkDir    string            // active project directory; "" = the tool's default
+	Allowed    []string          // tools offered, e.g. a project's; empty = all
+	Denied     map[string]string // tools blocked in this chat, name -> reason
+}
+
+type scopeKey struct{}
+
+// WithScope returns a context whose tool calls run in scope.
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFrom returns the context's scope, or a zero Scope (no conversation,
+// no restrictions) when none was set.
+func ScopeFrom(ctx context.Context) Scope {
+	scope, _ := ctx.Value(scopeKey{}).(Scope)
+	return scope
+}
+
+// ChatKey returns the session key without its topic, so topics of a chat
+// share its reminders, lists and other per-chat state.
+func (s Scope) ChatKey() string {
+	key, _ := session.SplitTopic(s.SessionKey)
+	return key
+}
+
+// blocked returns why the tool may not run in this scope, or "".
+func (s Scope) blocked(name string) string {
+	if len(s.Allowed) > 0 {
+		allowed := false
+		for _, a := range s.Allowed {
+			if a == name {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "not enabled for this project"
+		}
+	}
+	if reason, denied := s.Denied[name]; denied {
+		return reason
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/workflow"
+)
+
+// scopeRecorder remembers the scope of each call.
+type scopeRecorder struct {
+	mu     sync.Mutex
+	scopes []Scope
+}
+
+func (r *scopeRecorder) Name() string        { return "probe" }
+func (r *scopeRecorder) Description() string { return "records its scope" }
+func (r *scopeRecorder) Parameters() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+
+func (r *scopeRecorder) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scopes = append(r.scopes, ScopeFrom(ctx))
+	return "ok", nil
+}
+
+func TestChatDenialDoesNotLeakIntoWorkflow(t *testing.T) {
+	workspace := t.TempDir()
+	os.MkdirAll(filepath.Join(workspace, "workflows"), 0755)
+	wf := "name: probe-run\nsteps:\n  - id: probe\n    tool: probe\n"
+	if err := os.WriteFile(filepath.Join(workspace, "workflows", "probe.yaml"), []byte(wf), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	probe := &scopeRecorder{}
+	registry := NewToolRegistry()
+	registry.Register(probe)
+	cronTool := NewCronTool()
+	cronTool.SetWorkflowEngine(workflow.NewEngine(workspace, registry, nil))
+	cronTool.SetToolPolicy(func() map[string]config.ToolPolicyConfig {
+		return map[string]config.ToolPolicyConfig{"discord": {Deny: []string{"exec"}, GroupDeny: []string{"probe"}}}
+	})
+
+	// A chat that turned the tool off neither sees nor runs it
+	chat := WithScope(context.Background(), Scope{
+		Channel: "telegram", ChatID: "1", SessionKey: "telegram:1", WorkDir: "/projects/site",
+		Denied: map[string]string{"probe": "turned off in this chat"},
+	})
+	if _, err := registry.Execute(chat, "probe", nil); err == nil || !strings.Contains(err.Error(), "turned off") {
+		t.Fatalf("expected the chat's denial, got %v", err)
+	}
+	if defs := registry.GetDefinitions(chat); len(defs) != 0 {
+		t.Errorf("expected no definitions for the chat, got %d", len(defs))
+	}
+	project := WithScope(context.Background(), Scope{Allowed: []string{"read_file"}})
+	if _, err := registry.Execute(project, "probe", nil); err == nil || !strings.Contains(err.Error(), "not enabled") {
+		t.Errorf("expected the project's allow-list to apply, got %v", err)
+	}
+
+	// A scheduled workflow runs as its own chat, whatever chat ran last,
+	// under that chat's tool policy
+	job := &cron.CronJob{Payload: cron.CronPayload{Kind: "workflow", Message: "probe-run", Deliver: true, Channel: "discord", To: "2"}}
+	if _, err := cronTool.runWorkflowJob(job); err != nil {
+		t.Fatalf("workflow run failed: %v", err)
+	}
+	if len(probe.scopes) != 1 {
+		t.Fatalf("expected one probe call, got %d", len(probe.scopes))
+	}
+	got := probe.scopes[0]
+	if got.SessionKey != "discord:2" || got.WorkDir != workspace || len(got.Denied) != 1 || got.Denied["exec"] == "" {
+		t.Errorf("expected the job's own scope, got %+v", got)
+	}
+
+	group := &cron.CronJob{Payload: cron.CronPayload{Kind: "workflow", Message: "probe-run", Channel: "discord", To: "3", Group: true}}
+	cronTool.runWorkflowJob(group)
+	if len(probe.scopes) != 1 {
+		t.Errorf("expected group_deny to block the tool in a group's workflow, got %d calls", len(probe.scopes))
+	}
+}
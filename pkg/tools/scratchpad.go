@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/session"
+)
+
+// Scratchpad contents are sent with every request, so keep them small.
+const scratchpadMaxChars = 4000
+
+// ScratchpadTool gives the model a bounded per-conversation notes buffer
+// that is always included in its context.
+type ScratchpadTool struct {
+	sessions *session.SessionManager
+}
+
+func NewScratchpadTool(sm *session.SessionManager) *ScratchpadTool {
+	return &ScratchpadTool{sessions: sm}
+}
+
+func (t *ScratchpadTool) Name() string {
+	return "scratchpad"
+}
+
+func (t *ScratchpadTool) Description() string {
+	return fmt.Sprintf(`Working notes for the current conversation, always shown to you in the system prompt under "Scratchpad". Actions:
+- "read": Show the current notes.
+- "write": Replace the notes. Requires: content.
+- "append": Add a line to the notes. Requires: content.
+- "clear": Erase the notes.
+Use it to track multi-turn task state (plans, progress, intermediate results). It is limited to %d characters and reset with the conversation; use memory for long-term facts.`, scratchpadMaxChars)
+}
+
+func (t *ScratchpadTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: read, write, append, clear",
+				"enum":        []string{"read", "write", "append", "clear"},
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "Notes content (required for write/append)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ScratchpadTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	sessionKey := ScopeFrom(ctx).SessionKey
+	if sessionKey == "" {
+		return "Error: no active conversation", nil
+	}
+
+	action, _ := args["action"].(string)
+	content, _ := args["content"].(string)
+	current := t.sessions.GetScratchpad(sessionKey)
+
+	switch action {
+	case "read":
+		if current == "" {
+			return "Scratchpad is empty.", nil
+		}
+		return current, nil
+
+	case "write", "append":
+		if content == "" {
+			return fmt.Sprintf("Error: 'content' is required for %s", action), nil
+		}
+		updated := content
+		if action == "append" && current != "" {
+			updated = strings.TrimRight(current, "\n") + "\n" + content
+		}
+		if len(updated) > scratchpadMaxChars {
+			return fmt.Sprintf("Error: scratchpad would be %d characters (limit %d). Condense it with \"write\" first.", len(updated), scratchpadMaxChars), nil
+		}
+		t.sessions.SetScratchpad(sessionKey, updated)
+		return fmt.Sprintf("✓ Scratchpad updated (%d/%d characters)", len(updated), scratchpadMaxChars), nil
+
+	case "clear":
+		t.sessions.SetScratchpad(sessionKey, "")
+		return "✓ Scratchpad cleared", nil
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: read, write, append, clear", action), nil
+	}
+}
@@ -0,0 +1,280 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// SearchResult is one hit returned by a search backend.
+type SearchResult struct {
+	Title   string
+	URL     string
+	Snippet string
+}
+
+// SearchBackend runs a web search query for WebSearchTool.
+type SearchBackend interface {
+	Name() string
+	Search(ctx context.Context, query string, count int) ([]SearchResult, error)
+}
+
+// NewSearchBackend picks the backend named by cfg.Provider. With no provider
+// set, Brave is used when an API key is configured and DuckDuckGo otherwise,
+// so search works without a paid key.
+func NewSearchBackend(cfg config.WebSearchConfig) (SearchBackend, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "":
+		if cfg.APIKey != "" {
+			return &braveSearch{apiKey: cfg.APIKey}, nil
+		}
+		return &duckDuckGoSearch{}, nil
+	case "brave":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("brave search needs tools.web.search.api_key")
+		}
+		return &braveSearch{apiKey: cfg.APIKey}, nil
+	case "tavily":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("tavily search needs tools.web.search.api_key")
+		}
+		return &tavilySearch{apiKey: cfg.APIKey}, nil
+	case "searxng":
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("searxng search needs tools.web.search.base_url")
+		}
+		return &searxngSearch{baseURL: strings.TrimRight(cfg.BaseURL, "/")}, nil
+	case "duckduckgo", "ddg":
+		return &duckDuckGoSearch{}, nil
+	default:
+		return nil, fmt.Errorf("unknown search provider %q (use brave, tavily, searxng or duckduckgo)", cfg.Provider)
+	}
+}
+
+var searchClient = &http.Client{Timeout: 15 * time.Second}
+
+// doSearchRequest sends req and returns the body, failing on non-2xx status.
+func doSearchRequest(req *http.Request) ([]byte, error) {
+	resp, err := searchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("search returned status %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+// ── Brave ───────────────────────────────────────────────────
+
+type braveSearch struct {
+	apiKey string
+}
+
+func (b *braveSearch) Name() string { return "brave" }
+
+func (b *braveSearch) Search(ctx context.Context, query string, count int) ([]SearchResult, error) {
+	searchURL := fmt.Sprintf("https://api.search.brave.com/res/v1/web/search?q=%s&count=%d",
+		url.QueryEscape(query), count)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", b.apiKey)
+
+	body, err := doSearchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(resp.Web.Results))
+	for _, r := range resp.Web.Results {
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Description})
+	}
+	return results, nil
+}
+
+// ── Tavily ──────────────────────────────────────────────────
+
+type tavilySearch struct {
+	apiKey string
+}
+
+func (t *tavilySearch) Name() string { return "tavily" }
+
+func (t *tavilySearch) Search(ctx context.Context, query string, count int) ([]SearchResult, error) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"query":       query,
+		"max_results": count,
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.tavily.com/search", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+
+	body, err := doSearchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Content})
+	}
+	return results, nil
+}
+
+// ── SearxNG ─────────────────────────────────────────────────
+
+// searxngSearch queries a self-hosted SearxNG instance. The instance must
+// have the json format enabled (search.formats in settings.yml).
+type searxngSearch struct {
+	baseURL string
+}
+
+func (s *searxngSearch) Name() string { return "searxng" }
+
+func (s *searxngSearch) Search(ctx context.Context, query string, count int) ([]SearchResult, error) {
+	searchURL := fmt.Sprintf("%s/search?q=%s&format=json", s.baseURL, url.QueryEscape(query))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	body, err := doSearchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response (is the json format enabled?): %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if len(results) >= count {
+			break
+		}
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Content})
+	}
+	return results, nil
+}
+
+// ── DuckDuckGo ──────────────────────────────────────────────
+
+// duckDuckGoSearch scrapes the DuckDuckGo HTML endpoint. No key needed.
+type duckDuckGoSearch struct{}
+
+func (d *duckDuckGoSearch) Name() string { return "duckduckgo" }
+
+func (d *duckDuckGoSearch) Search(ctx context.Context, query string, count int) ([]SearchResult, error) {
+	form := url.Values{"q": {query}}
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://html.duckduckgo.com/html/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36")
+
+	body, err := doSearchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var results []SearchResult
+	doc.Find(".result").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if s.HasClass("result--ad") {
+			return true
+		}
+		link := s.Find("a.result__a").First()
+		href, _ := link.Attr("href")
+		target := duckDuckGoTarget(href)
+		if target == "" {
+			return true
+		}
+		results = append(results, SearchResult{
+			Title:   strings.TrimSpace(link.Text()),
+			URL:     target,
+			Snippet: strings.TrimSpace(s.Find(".result__snippet").Text()),
+		})
+		return len(results) < count
+	})
+	return results, nil
+}
+
+// duckDuckGoTarget unwraps DuckDuckGo's redirect links
+// ("//duckduckgo.com/l/?uddg=<url>") to the destination URL.
+func duckDuckGoTarget(href string) string {
+	if strings.HasPrefix(href, "//") {
+		href = "https:" + href
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if target := u.Query().Get("uddg"); target != "" {
+		return target
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		return href
+	}
+	return ""
+}
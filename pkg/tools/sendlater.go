@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/cron"
+	"github.com/ntminh611/mclaw/pkg/reminders"
+)
+
+// SendJobKind is the cron payload kind of scheduled messages.
+const SendJobKind = "send"
+
+// SendLaterTool schedules a message to be sent verbatim at a later time.
+// Unlike cron jobs, no agent turn runs when it is due: the text goes out
+// as written, to the current chat or any other.
+type SendLaterTool struct {
+	bus  *bus.MessageBus
+	cron *cron.CronService
+}
+
+func NewSendLaterTool(mb *bus.MessageBus) *SendLaterTool {
+	return &SendLaterTool{bus: mb}
+}
+
+// SetCronService schedules messages on cs and sends those that came due
+// while mclaw was not running.
+func (t *SendLaterTool) SetCronService(cs *cron.CronService) {
+	t.cron = cs
+	cs.SetKindHandler(SendJobKind, t.fire)
+
+	now := time.Now().UnixMilli()
+	for _, job := range cs.ListJobs(true) {
+		if job.Payload.Kind == SendJobKind && job.Schedule.AtMS != nil && *job.Schedule.AtMS <= now {
+			log.Printf("[tools] Sending scheduled message %s late", job.ID)
+			t.fire(&job)
+			cs.RemoveJob(job.ID)
+		}
+	}
+}
+
+func (t *SendLaterTool) Name() string {
+	return "send_later"
+}
+
+func (t *SendLaterTool) Description() string {
+	return `Send a message at a later time, exactly as written, without running the assistant then (for tasks that need thinking at that time, use cron). Actions:
+- "schedule": Requires: content, when. Optional: channel and chat_id to send to another chat (default: this chat).
+- "list": Show scheduled messages.
+- "cancel": Requires: id.
+"when" understands "in 20 minutes", "tomorrow 9am", "friday at 18:30" or "2026-03-01 08:00".`
+}
+
+func (t *SendLaterTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: schedule, list, cancel",
+				"enum":        []string{"schedule", "list", "cancel"},
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "The message, sent verbatim (for schedule)",
+			},
+			"when": map[string]interface{}{
+				"type":        "string",
+				"description": "When to send it, in the user's words (for schedule)",
+			},
+			"channel": map[string]interface{}{
+				"type":        "string",
+				"description": "Target channel, e.g. telegram or discord, or \"group\" with a recipient group name as chat_id (default: this chat's)",
+			},
+			"chat_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Target chat ID (default: this chat)",
+			},
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": "Scheduled message ID (for cancel)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *SendLaterTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if t.cron == nil {
+		return "Error: scheduling is not available (the gateway is not running)", nil
+	}
+
+	now := time.Now()
+	action, _ := args["action"].(string)
+	switch action {
+	case "schedule":
+		content, _ := args["content"].(string)
+		when, _ := args["when"].(string)
+		if strings.TrimSpace(content) == "" || strings.TrimSpace(when) == "" {
+			return "Error: 'content' and 'when' are required for schedule", nil
+		}
+		due, err := reminders.ParseWhen(when, now)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+
+		defChannel, defChat, _ := strings.Cut(ScopeFrom(ctx).ChatKey(), ":")
+		channel, _ := args["channel"].(string)
+		chatID, _ := args["chat_id"].(string)
+		if channel == "" {
+			channel = defChannel
+		}
+		if chatID == "" {
+			if channel != defChannel {
+				return "Error: 'chat_id' is required when sending to another channel", nil
+			}
+			chatID = defChat
+		}
+		if channel == "" || chatID == "" {
+			return "Error: no chat to send to; give channel and chat_id", nil
+		}
+
+		job, err := t.cron.AddOneShot("send later: "+sendPreview(content, 40), due, cron.CronPayload{
+			Kind:    SendJobKind,
+			Message: content,
+			Channel: channel,
+			To:      chatID,
+		})
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Message %s scheduled for %s to %s:%s", job.ID, reminders.FormatTime(due, now), channel, chatID), nil
+
+	case "list":
+		var sb strings.Builder
+		count := 0
+		for _, job := range t.cron.ListJobs(true) {
+			if job.Payload.Kind != SendJobKind || job.Schedule.AtMS == nil {
+				continue
+			}
+			count++
+			fmt.Fprintf(&sb, "- %s — %s to %s:%s: %s\n", job.ID, reminders.FormatTime(time.UnixMilli(*job.Schedule.AtMS), now),
+				job.Payload.Channel, job.Payload.To, sendPreview(job.Payload.Message, 80))
+		}
+		if count == 0 {
+			return "No scheduled messages.", nil
+		}
+		return fmt.Sprintf("Scheduled messages (%d):\n%s", count, sb.String()), nil
+
+	case "cancel":
+		id, _ := args["id"].(string)
+		if id == "" {
+			return "Error: 'id' is required for cancel", nil
+		}
+		for _, job := range t.cron.ListJobs(true) {
+			if job.ID == id && job.Payload.Kind == SendJobKind {
+				t.cron.RemoveJob(id)
+				return fmt.Sprintf("✓ Cancelled scheduled message %s", id), nil
+			}
+		}
+		return fmt.Sprintf("Error: no scheduled message %s", id), nil
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: schedule, list, cancel", action), nil
+	}
+}
+
+// fire is the cron handler for scheduled messages.
+func (t *SendLaterTool) fire(job *cron.CronJob) (string, error) {
+	t.bus.PublishOutbound(bus.OutboundMessage{Channel: job.Payload.Channel, ChatID: job.Payload.To, Content: job.Payload.Message})
+	return fmt.Sprintf("sent to %s:%s", job.Payload.Channel, job.Payload.To), nil
+}
+
+// sendPreview shortens a message to one line of at most n characters.
+func sendPreview(s string, n int) string {
+	r := []rune(strings.Join(strings.Fields(s), " "))
+	if len(r) <= n {
+		return string(r)
+	}
+	return string(r[:n]) + "…"
+}
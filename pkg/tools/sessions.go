@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/session"
+)
+
+// SessionSearchTool searches past conversations across all sessions
+type SessionSearchTool struct {
+	sessions *session.SessionManager
+}
+
+func NewSessionSearchTool(sm *session.SessionManager) *SessionSearchTool {
+	return &SessionSearchTool{sessions: sm}
+}
+
+func (t *SessionSearchTool) Name() string {
+	return "session_search"
+}
+
+func (t *SessionSearchTool) Description() string {
+	return `Search past conversations (user and assistant messages) across all chats. Use this when the user refers to something discussed earlier that is not in the current context, e.g. "what did we say about X last week?". Filter by text, channel, and date range.`
+}
+
+func (t *SessionSearchTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Text to search for (case-insensitive substring)",
+			},
+			"channel": map[string]interface{}{
+				"type":        "string",
+				"description": "Only search this channel (e.g. 'telegram', 'discord', 'cli')",
+			},
+			"since": map[string]interface{}{
+				"type":        "string",
+				"description": "Start date, YYYY-MM-DD or ISO 8601 (inclusive)",
+			},
+			"until": map[string]interface{}{
+				"type":        "string",
+				"description": "End date, YYYY-MM-DD or ISO 8601 (exclusive)",
+			},
+			"limit": map[string]interface{}{
+				"type":        "number",
+				"description": "Maximum number of results (default: 20)",
+			},
+		},
+	}
+}
+
+func (t *SessionSearchTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	q := session.SearchQuery{}
+	q.Text, _ = args["query"].(string)
+	q.Channel, _ = args["channel"].(string)
+	if limit, ok := args["limit"].(float64); ok {
+		q.Limit = int(limit)
+	}
+
+	var err error
+	if since, _ := args["since"].(string); since != "" {
+		if q.Since, err = parseDate(since); err != nil {
+			return fmt.Sprintf("Error: invalid since: %v", err), nil
+		}
+	}
+	if until, _ := args["until"].(string); until != "" {
+		if q.Until, err = parseDate(until); err != nil {
+			return fmt.Sprintf("Error: invalid until: %v", err), nil
+		}
+	}
+
+	if q.Text == "" && q.Channel == "" && q.Since.IsZero() && q.Until.IsZero() {
+		return "Error: provide at least one of query, channel, since, until", nil
+	}
+
+	hits, err := t.sessions.Search(q)
+	if err != nil {
+		return fmt.Sprintf("Error: search failed: %v", err), nil
+	}
+	if len(hits) == 0 {
+		return "No matching messages found.", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Found %d messages (newest first):\n\n", len(hits)))
+	for _, hit := range hits {
+		content := hit.Content
+		if len(content) > 500 {
+			content = content[:500] + "..."
+		}
+		sb.WriteString(fmt.Sprintf("[%s] %s (%s):\n%s\n\n", hit.Time.Format("2006-01-02 15:04"), hit.Role, hit.SessionKey, content))
+	}
+	return sb.String(), nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
@@ -74,6 +74,9 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]interface{}) (st
 	}
 
 	cwd := t.workingDir
+	if dir := ScopeFrom(ctx).WorkDir; dir != "" {
+		cwd = dir // the active project's directory
+	}
 	if wd, ok := args["working_dir"].(string); ok && wd != "" {
 		cwd = wd
 	}
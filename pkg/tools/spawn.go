@@ -6,17 +6,11 @@ import (
 )
 
 type SpawnTool struct {
-	manager       *SubagentManager
-	originChannel string
-	originChatID  string
+	manager *SubagentManager
 }
 
 func NewSpawnTool(manager *SubagentManager) *SpawnTool {
-	return &SpawnTool{
-		manager:       manager,
-		originChannel: "cli",
-		originChatID:  "direct",
-	}
+	return &SpawnTool{manager: manager}
 }
 
 func (t *SpawnTool) Name() string {
@@ -44,11 +38,6 @@ func (t *SpawnTool) Parameters() map[string]interface{} {
 	}
 }
 
-func (t *SpawnTool) SetContext(channel, chatID string) {
-	t.originChannel = channel
-	t.originChatID = chatID
-}
-
 func (t *SpawnTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
 	task, ok := args["task"].(string)
 	if !ok {
@@ -61,7 +50,12 @@ func (t *SpawnTool) Execute(ctx context.Context, args map[string]interface{}) (s
 		return "Error: Subagent manager not configured", nil
 	}
 
-	result, err := t.manager.Spawn(ctx, task, label, t.originChannel, t.originChatID)
+	// Results go back to the chat the call came from
+	channel, chatID := "cli", "direct"
+	if scope := ScopeFrom(ctx); scope.Channel != "" && scope.ChatID != "" {
+		channel, chatID = scope.Channel, scope.ChatID
+	}
+	result, err := t.manager.Spawn(ctx, task, label, channel, chatID)
 	if err != nil {
 		return "", fmt.Errorf("failed to spawn subagent: %w", err)
 	}
@@ -0,0 +1,380 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+const (
+	systemCommandTimeout = 10 * time.Second
+	cpuSampleInterval    = 250 * time.Millisecond
+	systemDefaultLimit   = 15
+	systemMaxLimit       = 100
+)
+
+// serviceName keeps unit names from being read as options.
+var serviceName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9@._:-]*$`)
+
+// SystemTool reports on the machine mclaw runs on: load, memory, disks,
+// processes and systemd services. Unless read-only, it can also start, stop
+// and restart the services listed in tools.system.services.
+type SystemTool struct {
+	cfg config.SystemToolConfig
+}
+
+func NewSystemTool(cfg config.SystemToolConfig) *SystemTool {
+	return &SystemTool{cfg: cfg}
+}
+
+func (t *SystemTool) Name() string {
+	return "system"
+}
+
+func (t *SystemTool) Description() string {
+	desc := `Check the machine the assistant runs on. Actions:
+- "overview": CPU load and usage, memory, swap, disks and the configured services.
+- "processes": Top processes by CPU or memory. Optional: sort (cpu, memory), filter (name substring), limit.
+- "service": Whether a systemd service is running, since when, and its latest log lines. Requires: service.`
+	if !t.cfg.ReadOnly && len(t.cfg.Services) > 0 {
+		desc += `
+- "start" / "stop" / "restart": Control a service. Requires: service, one of: ` + strings.Join(t.cfg.Services, ", ") + `.`
+	}
+	return desc
+}
+
+func (t *SystemTool) actions() []string {
+	actions := []string{"overview", "processes", "service"}
+	if !t.cfg.ReadOnly && len(t.cfg.Services) > 0 {
+		actions = append(actions, "start", "stop", "restart")
+	}
+	return actions
+}
+
+func (t *SystemTool) Parameters() map[string]interface{} {
+	actions := t.actions()
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: " + strings.Join(actions, ", "),
+				"enum":        actions,
+			},
+			"service": map[string]interface{}{
+				"type":        "string",
+				"description": "systemd unit name, e.g. \"restic-backup\" or \"nginx.service\"",
+			},
+			"sort": map[string]interface{}{
+				"type":        "string",
+				"description": "Order processes by cpu (default) or memory",
+				"enum":        []string{"cpu", "memory"},
+			},
+			"filter": map[string]interface{}{
+				"type":        "string",
+				"description": "Only processes whose command contains this text",
+			},
+			"limit": map[string]interface{}{
+				"type":        "number",
+				"description": "Maximum processes to list (default 15, max 100)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *SystemTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	action, _ := args["action"].(string)
+	service, _ := args["service"].(string)
+	service = strings.TrimSpace(service)
+
+	switch action {
+	case "overview":
+		return t.overview(ctx), nil
+	case "processes":
+		return processes(ctx, args)
+	case "service":
+		if !serviceName.MatchString(service) {
+			return "Error: a valid 'service' name is required", nil
+		}
+		return serviceStatus(ctx, service, true), nil
+	case "start", "stop", "restart":
+		if t.cfg.ReadOnly {
+			return "Error: the system tool is read-only (tools.system.read_only)", nil
+		}
+		if !t.controls(service) {
+			return fmt.Sprintf("Error: %q is not in tools.system.services", service), nil
+		}
+		out, err := runSystem(ctx, "systemctl", action, service)
+		if err != nil {
+			return fmt.Sprintf("Error: systemctl %s %s failed: %v %s", action, service, err, out), nil
+		}
+		return fmt.Sprintf("✓ systemctl %s %s\n%s", action, service, serviceStatus(ctx, service, false)), nil
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: %s", action, strings.Join(t.actions(), ", ")), nil
+	}
+}
+
+func (t *SystemTool) controls(service string) bool {
+	for _, s := range t.cfg.Services {
+		if s == service || strings.TrimSuffix(s, ".service") == strings.TrimSuffix(service, ".service") {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *SystemTool) overview(ctx context.Context) string {
+	var sb strings.Builder
+	host, _ := os.Hostname()
+	fmt.Fprintf(&sb, "Host: %s (%s/%s, %d CPUs)\n", host, runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+		if f := strings.Fields(string(data)); len(f) >= 3 {
+			fmt.Fprintf(&sb, "Load: %s %s %s (1, 5, 15 min)\n", f[0], f[1], f[2])
+		}
+	}
+	if busy, ok := cpuUsage(ctx); ok {
+		fmt.Fprintf(&sb, "CPU: %.0f%% busy\n", busy)
+	}
+	if data, err := os.ReadFile("/proc/uptime"); err == nil {
+		if f := strings.Fields(string(data)); len(f) > 0 {
+			if secs, err := strconv.ParseFloat(f[0], 64); err == nil {
+				fmt.Fprintf(&sb, "Uptime: %s\n", (time.Duration(secs) * time.Second).Round(time.Minute))
+			}
+		}
+	}
+	if mem := meminfo(); mem != nil {
+		total, avail := mem["MemTotal"], mem["MemAvailable"]
+		if total > 0 {
+			fmt.Fprintf(&sb, "Memory: %s used of %s (%.0f%%), %s available\n",
+				formatSize(total-avail), formatSize(total), float64(total-avail)*100/float64(total), formatSize(avail))
+		}
+		if swap := mem["SwapTotal"]; swap > 0 {
+			fmt.Fprintf(&sb, "Swap: %s used of %s\n", formatSize(swap-mem["SwapFree"]), formatSize(swap))
+		}
+	}
+	if out, err := runSystem(ctx, "df", "-kP"); err == nil {
+		sb.WriteString("Disks:\n")
+		for _, line := range strings.Split(out, "\n")[1:] {
+			f := strings.Fields(line)
+			if len(f) < 6 || !strings.HasPrefix(f[0], "/") {
+				continue // tmpfs, overlay and other virtual filesystems
+			}
+			size, _ := strconv.ParseInt(f[1], 10, 64)
+			avail, _ := strconv.ParseInt(f[3], 10, 64)
+			fmt.Fprintf(&sb, "- %s: %s used, %s free of %s\n", f[5], f[4], formatSize(avail<<10), formatSize(size<<10))
+		}
+	}
+	if len(t.cfg.Services) > 0 {
+		sb.WriteString("Services:\n")
+		for _, s := range t.cfg.Services {
+			state, err := serviceState(ctx, s)
+			if err != nil {
+				state = "unknown (" + err.Error() + ")"
+			}
+			fmt.Fprintf(&sb, "- %s: %s\n", s, state)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// cpuUsage samples /proc/stat twice, cpuSampleInterval apart, and returns
+// the share of time the CPUs were busy in between.
+func cpuUsage(ctx context.Context) (float64, bool) {
+	idle1, total1, ok := cpuTimes()
+	if !ok {
+		return 0, false
+	}
+	select {
+	case <-ctx.Done():
+		return 0, false
+	case <-time.After(cpuSampleInterval):
+	}
+	idle2, total2, ok := cpuTimes()
+	if !ok || total2 <= total1 {
+		return 0, false
+	}
+	return 100 * (1 - float64(idle2-idle1)/float64(total2-total1)), true
+}
+
+// cpuTimes reads the aggregate idle and total jiffies from /proc/stat.
+func cpuTimes() (idle, total uint64, ok bool) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, false
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, false
+	}
+	for i, f := range fields[1:] {
+		v, _ := strconv.ParseUint(f, 10, 64)
+		total += v
+		if i == 3 || i == 4 { // idle, iowait
+			idle += v
+		}
+	}
+	return idle, total, true
+}
+
+// meminfo reads /proc/meminfo in bytes, or returns nil where it doesn't exist.
+func meminfo() map[string]int64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	mem := make(map[string]int64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		if kb, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+			mem[name] = kb << 10
+		}
+	}
+	return mem
+}
+
+type processInfo struct {
+	pid     string
+	cpu     float64
+	mem     float64
+	elapsed string
+	command string
+}
+
+func processes(ctx context.Context, args map[string]interface{}) (string, error) {
+	out, err := runSystem(ctx, "ps", "-eo", "pid=,pcpu=,pmem=,etime=,args=")
+	if err != nil {
+		return fmt.Sprintf("Error: ps failed: %v", err), nil
+	}
+	filter, _ := args["filter"].(string)
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	var procs []processInfo
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.Fields(line)
+		if len(f) < 5 {
+			continue
+		}
+		command := strings.Join(f[4:], " ")
+		if filter != "" && !strings.Contains(strings.ToLower(command), filter) {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(f[1], 64)
+		mem, _ := strconv.ParseFloat(f[2], 64)
+		if len(command) > 120 {
+			command = command[:120] + "..."
+		}
+		procs = append(procs, processInfo{pid: f[0], cpu: cpu, mem: mem, elapsed: f[3], command: command})
+	}
+	if len(procs) == 0 {
+		return "No matching processes.", nil
+	}
+
+	byMemory := args["sort"] == "memory"
+	sort.SliceStable(procs, func(i, j int) bool {
+		if byMemory {
+			return procs[i].mem > procs[j].mem
+		}
+		return procs[i].cpu > procs[j].cpu
+	})
+	limit := systemDefaultLimit
+	if n, ok := args["limit"].(float64); ok && n > 0 {
+		limit = min(int(n), systemMaxLimit)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d processes", len(procs))
+	if len(procs) > limit {
+		fmt.Fprintf(&sb, ", top %d", limit)
+		procs = procs[:limit]
+	}
+	sb.WriteString(":\nPID     CPU%  MEM%  ELAPSED      COMMAND\n")
+	for _, p := range procs {
+		fmt.Fprintf(&sb, "%-7s %5.1f %5.1f  %-12s %s\n", p.pid, p.cpu, p.mem, p.elapsed, p.command)
+	}
+	return sb.String(), nil
+}
+
+// serviceStatus describes a systemd unit, with its last log lines when
+// withLogs is set.
+func serviceStatus(ctx context.Context, service string, withLogs bool) string {
+	state, err := serviceState(ctx, service)
+	if err != nil {
+		return fmt.Sprintf("Error: can't query %s: %v", service, err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s", service, state)
+	props, _ := runSystem(ctx, "systemctl", "show", service, "--no-pager",
+		"-p", "SubState,ActiveEnterTimestamp,MainPID,Result,Description")
+	values := make(map[string]string)
+	for _, line := range strings.Split(props, "\n") {
+		if k, v, ok := strings.Cut(line, "="); ok {
+			values[k] = v
+		}
+	}
+	if v := values["SubState"]; v != "" {
+		fmt.Fprintf(&sb, " (%s)", v)
+	}
+	sb.WriteString("\n")
+	if v := values["Description"]; v != "" {
+		fmt.Fprintf(&sb, "Description: %s\n", v)
+	}
+	if v := values["ActiveEnterTimestamp"]; v != "" {
+		fmt.Fprintf(&sb, "Since: %s\n", v)
+	}
+	if v := values["MainPID"]; v != "" && v != "0" {
+		fmt.Fprintf(&sb, "Main PID: %s\n", v)
+	}
+	if v := values["Result"]; v != "" && v != "success" {
+		fmt.Fprintf(&sb, "Last result: %s\n", v)
+	}
+	if withLogs {
+		if logs, err := runSystem(ctx, "journalctl", "-u", service, "-n", "10", "--no-pager", "-o", "short-iso"); err == nil && strings.TrimSpace(logs) != "" {
+			sb.WriteString("Recent log:\n" + logs)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// serviceState returns what systemctl is-active says about a unit, e.g.
+// "active", "inactive" or "failed".
+func serviceState(ctx context.Context, service string) (string, error) {
+	out, err := runSystem(ctx, "systemctl", "is-active", service)
+	state := strings.TrimSpace(out)
+	if state == "" || strings.ContainsAny(state, " \n") {
+		if err == nil {
+			err = fmt.Errorf("unexpected output %q", state)
+		}
+		return "", fmt.Errorf("systemd not available: %v", err)
+	}
+	return state, nil
+}
+
+// runSystem runs a read-only system command and returns its output, which
+// is also returned on failure (systemctl is-active exits 3 for inactive units).
+func runSystem(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, systemCommandTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return string(out), err
+}
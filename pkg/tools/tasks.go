@@ -0,0 +1,229 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/tasks"
+)
+
+// TasksTool manages the to-do list of the current conversation.
+type TasksTool struct {
+	store *tasks.Store
+}
+
+func NewTasksTool(store *tasks.Store) *TasksTool {
+	return &TasksTool{store: store}
+}
+
+func (t *TasksTool) Name() string {
+	return "tasks"
+}
+
+func (t *TasksTool) Description() string {
+	return `Manage the user's to-do list. Overdue tasks are reviewed on every heartbeat and the user is reminded. Actions:
+- "add": Add a task. Requires: title. Optional: due, priority, notes.
+- "list": List open tasks. Optional: include_done.
+- "complete": Mark a task done. Requires: task_id.
+- "update": Change a task. Requires: task_id. Optional: title, due, priority, notes.
+- "remove": Delete a task. Requires: task_id.
+Use this for things the user needs to do; use remind for a nudge at an exact time.`
+}
+
+func (t *TasksTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: add, list, complete, update, remove",
+				"enum":        []string{"add", "list", "complete", "update", "remove"},
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Task title (required for add)",
+			},
+			"due": map[string]interface{}{
+				"type":        "string",
+				"description": "Due date: YYYY-MM-DD, \"YYYY-MM-DD HH:MM\", today or tomorrow. Use \"none\" to clear.",
+			},
+			"priority": map[string]interface{}{
+				"type":        "string",
+				"description": "Priority (default: normal)",
+				"enum":        []string{"low", "normal", "high"},
+			},
+			"notes": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional details",
+			},
+			"task_id": map[string]interface{}{
+				"type":        "number",
+				"description": "Task ID (required for complete, update, remove)",
+			},
+			"include_done": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also list completed tasks",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *TasksTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	sessionKey := ScopeFrom(ctx).SessionKey
+	if t.store == nil {
+		return "Error: task store not available", nil
+	}
+	if sessionKey == "" {
+		return "Error: tasks are only available in a conversation", nil
+	}
+
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		return t.add(sessionKey, args)
+	case "list":
+		includeDone, _ := args["include_done"].(bool)
+		return t.list(sessionKey, includeDone)
+	case "complete":
+		id, ok := taskID(args)
+		if !ok {
+			return "Error: 'task_id' is required for complete", nil
+		}
+		if err := t.store.Complete(sessionKey, id); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Completed task #%d", id), nil
+	case "update":
+		return t.update(sessionKey, args)
+	case "remove":
+		id, ok := taskID(args)
+		if !ok {
+			return "Error: 'task_id' is required for remove", nil
+		}
+		if err := t.store.Delete(sessionKey, id); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Removed task #%d", id), nil
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: add, list, complete, update, remove", action), nil
+	}
+}
+
+func (t *TasksTool) add(sessionKey string, args map[string]interface{}) (string, error) {
+	title, _ := args["title"].(string)
+	if strings.TrimSpace(title) == "" {
+		return "Error: 'title' is required for add", nil
+	}
+
+	task := &tasks.Task{Owner: sessionKey, Title: strings.TrimSpace(title)}
+	task.Notes, _ = args["notes"].(string)
+	if msg := applyTaskFields(task, args); msg != "" {
+		return msg, nil
+	}
+
+	if err := t.store.Add(task); err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	return "✓ Added " + formatTask(task, time.Now()), nil
+}
+
+func (t *TasksTool) update(sessionKey string, args map[string]interface{}) (string, error) {
+	id, ok := taskID(args)
+	if !ok {
+		return "Error: 'task_id' is required for update", nil
+	}
+	task, err := t.store.Get(sessionKey, id)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+
+	if title, _ := args["title"].(string); strings.TrimSpace(title) != "" {
+		task.Title = strings.TrimSpace(title)
+	}
+	if notes, ok := args["notes"].(string); ok {
+		task.Notes = notes
+	}
+	if msg := applyTaskFields(task, args); msg != "" {
+		return msg, nil
+	}
+
+	if err := t.store.Update(task); err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	return "✓ Updated " + formatTask(task, time.Now()), nil
+}
+
+// applyTaskFields sets due date and priority from args, returning an error
+// message for invalid input.
+func applyTaskFields(task *tasks.Task, args map[string]interface{}) string {
+	if due, _ := args["due"].(string); due != "" {
+		if strings.EqualFold(due, "none") {
+			task.Due = time.Time{}
+		} else {
+			parsed, err := tasks.ParseDue(due, time.Now())
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err)
+			}
+			task.Due = parsed
+		}
+	}
+	if p, ok := args["priority"].(string); ok {
+		priority, err := tasks.NormalizePriority(p)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		task.Priority = priority
+	}
+	return ""
+}
+
+func (t *TasksTool) list(sessionKey string, includeDone bool) (string, error) {
+	list, err := t.store.List(sessionKey, includeDone)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	if len(list) == 0 {
+		return "No open tasks.", nil
+	}
+
+	now := time.Now()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Tasks (%d):\n", len(list))
+	for _, task := range list {
+		sb.WriteString("- " + formatTask(task, now) + "\n")
+	}
+	return sb.String(), nil
+}
+
+func formatTask(task *tasks.Task, now time.Time) string {
+	var sb strings.Builder
+	mark := "☐"
+	if task.Done {
+		mark = "☑"
+	}
+	fmt.Fprintf(&sb, "%s #%d %s", mark, task.ID, task.Title)
+	if task.Priority != tasks.PriorityNormal {
+		fmt.Fprintf(&sb, " [%s]", task.Priority)
+	}
+	if task.HasDue() {
+		fmt.Fprintf(&sb, " — due %s", task.Due.Format("2006-01-02 15:04"))
+		if task.Overdue(now) {
+			fmt.Fprintf(&sb, " (overdue by %s)", tasks.FormatAge(now.Sub(task.Due)))
+		}
+	}
+	if task.Notes != "" {
+		fmt.Fprintf(&sb, "\n    %s", task.Notes)
+	}
+	return sb.String()
+}
+
+func taskID(args map[string]interface{}) (int64, bool) {
+	id, ok := args["task_id"].(float64)
+	if !ok || id <= 0 {
+		return 0, false
+	}
+	return int64(id), true
+}
@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/bus"
+	"github.com/ntminh611/mclaw/pkg/reminders"
+)
+
+const (
+	timerMaxDuration = 24 * time.Hour
+	timerMaxPerChat  = 20
+)
+
+// runningTimer is a countdown timer, or a stopwatch when due is zero.
+type runningTimer struct {
+	id      int
+	owner   string // chat key, "channel:chat_id"
+	label   string
+	started time.Time
+	due     time.Time
+	laps    []time.Duration
+	timer   *time.Timer
+}
+
+// TimerTool runs short countdown timers and stopwatches in memory. Unlike
+// reminders and cron jobs they are not persisted, so they are cheap to
+// start and cancel but are lost when mclaw restarts.
+type TimerTool struct {
+	bus    *bus.MessageBus
+	mu     sync.Mutex
+	timers map[int]*runningTimer
+	nextID int
+}
+
+func NewTimerTool(mb *bus.MessageBus) *TimerTool {
+	return &TimerTool{bus: mb, timers: make(map[int]*runningTimer)}
+}
+
+func (t *TimerTool) Name() string {
+	return "timer"
+}
+
+func (t *TimerTool) Description() string {
+	return `Short timers and stopwatches for this chat ("timer 25 minutes for pomodoro", "start a stopwatch"). A timer sends a message here when it runs out. They live in memory only and are lost on restart, so use remind for anything longer than a few hours. Actions:
+- "start": Start a countdown. Requires: duration (e.g. "25 minutes", "1h30m", up to 24h). Optional: label.
+- "stopwatch": Start a stopwatch. Optional: label.
+- "lap": Record a lap on a stopwatch. Requires: timer_id.
+- "list": Running timers and stopwatches with the time left or elapsed.
+- "stop": Cancel a timer, or stop a stopwatch and report its time. Requires: timer_id.`
+}
+
+func (t *TimerTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: start, stopwatch, lap, list, stop",
+				"enum":        []string{"start", "stopwatch", "lap", "list", "stop"},
+			},
+			"duration": map[string]interface{}{
+				"type":        "string",
+				"description": "Countdown length, e.g. \"25 minutes\", \"90s\", \"1h30m\" (for start)",
+			},
+			"label": map[string]interface{}{
+				"type":        "string",
+				"description": "What the timer is for, e.g. \"pomodoro\", \"pasta\"",
+			},
+			"timer_id": map[string]interface{}{
+				"type":        "number",
+				"description": "Timer or stopwatch ID (for lap and stop)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *TimerTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	owner := ScopeFrom(ctx).ChatKey()
+	if owner == "" {
+		return "Error: timers are only available in a conversation", nil
+	}
+
+	now := time.Now()
+	label, _ := args["label"].(string)
+	label = strings.TrimSpace(label)
+	action, _ := args["action"].(string)
+	switch action {
+	case "start", "stopwatch":
+		var d time.Duration
+		if action == "start" {
+			raw, _ := args["duration"].(string)
+			var err error
+			if d, err = reminders.ParseSpan(raw); err != nil {
+				return fmt.Sprintf("Error: %v (try \"25 minutes\" or \"1h30m\")", err), nil
+			}
+			if d > timerMaxDuration {
+				return "Error: timers last at most 24 hours; use remind for longer", nil
+			}
+		}
+		rt, err := t.start(owner, label, d, now)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if action == "stopwatch" {
+			return fmt.Sprintf("✓ Stopwatch #%d started%s", rt.id, timerLabel(rt.label)), nil
+		}
+		return fmt.Sprintf("✓ Timer #%d%s: %s, rings at %s", rt.id, timerLabel(rt.label), formatTimerSpan(d),
+			rt.due.Format("15:04:05")), nil
+
+	case "list":
+		return t.list(owner, now), nil
+
+	case "lap", "stop":
+		id, ok := args["timer_id"].(float64)
+		if !ok || id <= 0 {
+			return fmt.Sprintf("Error: 'timer_id' is required for %s", action), nil
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		rt := t.timers[int(id)]
+		if rt == nil || rt.owner != owner {
+			return fmt.Sprintf("Error: no running timer #%d", int(id)), nil
+		}
+		elapsed := now.Sub(rt.started)
+		if action == "lap" {
+			if !rt.due.IsZero() {
+				return fmt.Sprintf("Error: #%d is a timer, not a stopwatch", rt.id), nil
+			}
+			var previous time.Duration
+			if n := len(rt.laps); n > 0 {
+				previous = rt.laps[n-1]
+			}
+			rt.laps = append(rt.laps, elapsed)
+			return fmt.Sprintf("⏱️ Lap %d: %s (total %s)", len(rt.laps), formatTimerSpan(elapsed-previous), formatTimerSpan(elapsed)), nil
+		}
+
+		delete(t.timers, rt.id)
+		if rt.timer != nil {
+			rt.timer.Stop()
+			return fmt.Sprintf("✓ Cancelled timer #%d%s with %s left", rt.id, timerLabel(rt.label), formatTimerSpan(rt.due.Sub(now))), nil
+		}
+		result := fmt.Sprintf("⏱️ Stopwatch #%d%s stopped at %s", rt.id, timerLabel(rt.label), formatTimerSpan(elapsed))
+		var previous time.Duration
+		for i, lap := range rt.laps {
+			result += fmt.Sprintf("\n- lap %d: %s", i+1, formatTimerSpan(lap-previous))
+			previous = lap
+		}
+		return result, nil
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: start, stopwatch, lap, list, stop", action), nil
+	}
+}
+
+// start adds a timer that rings after d, or a stopwatch when d is 0.
+func (t *TimerTool) start(owner, label string, d time.Duration, now time.Time) (*runningTimer, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count := 0
+	for _, rt := range t.timers {
+		if rt.owner == owner {
+			count++
+		}
+	}
+	if count >= timerMaxPerChat {
+		return nil, fmt.Errorf("a chat can run at most %d timers at once", timerMaxPerChat)
+	}
+
+	t.nextID++
+	rt := &runningTimer{id: t.nextID, owner: owner, label: label, started: now}
+	if d > 0 {
+		rt.due = now.Add(d)
+		rt.timer = time.AfterFunc(d, func() { t.ring(rt.id) })
+	}
+	t.timers[rt.id] = rt
+	return rt, nil
+}
+
+// ring sends the message of a timer that ran out to its chat.
+func (t *TimerTool) ring(id int) {
+	t.mu.Lock()
+	rt := t.timers[id]
+	delete(t.timers, id)
+	t.mu.Unlock()
+	if rt == nil {
+		return // stopped just before it rang
+	}
+
+	content := fmt.Sprintf("⏰ Time's up! Timer #%d (%s) is done.", rt.id, formatTimerSpan(rt.due.Sub(rt.started)))
+	if rt.label != "" {
+		content = fmt.Sprintf("⏰ Time's up: %s (%s)", rt.label, formatTimerSpan(rt.due.Sub(rt.started)))
+	}
+	channel, chatID, _ := strings.Cut(rt.owner, ":")
+	t.bus.PublishOutbound(bus.OutboundMessage{Channel: channel, ChatID: chatID, Content: content})
+}
+
+func (t *TimerTool) list(owner string, now time.Time) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var running []*runningTimer
+	for _, rt := range t.timers {
+		if rt.owner == owner {
+			running = append(running, rt)
+		}
+	}
+	if len(running) == 0 {
+		return "No timers running."
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].id < running[j].id })
+	var sb strings.Builder
+	sb.WriteString("Running:\n")
+	for _, rt := range running {
+		if rt.timer == nil {
+			fmt.Fprintf(&sb, "- ⏱️ #%d stopwatch%s: %s elapsed", rt.id, timerLabel(rt.label), formatTimerSpan(now.Sub(rt.started)))
+			switch len(rt.laps) {
+			case 0:
+			case 1:
+				sb.WriteString(", 1 lap")
+			default:
+				fmt.Fprintf(&sb, ", %d laps", len(rt.laps))
+			}
+			sb.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&sb, "- ⏰ #%d timer%s: %s left of %s (rings at %s)\n", rt.id, timerLabel(rt.label),
+			formatTimerSpan(rt.due.Sub(now)), formatTimerSpan(rt.due.Sub(rt.started)), rt.due.Format("15:04:05"))
+	}
+	return sb.String()
+}
+
+func timerLabel(label string) string {
+	if label == "" {
+		return ""
+	}
+	return " (" + label + ")"
+}
+
+// formatTimerSpan renders a duration to the second: "25m", "1h 5m 30s", "42s".
+func formatTimerSpan(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Second {
+		return "0s"
+	}
+	h, m, s := int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second)
+	var parts []string
+	if h > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", h))
+	}
+	if m > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", m))
+	}
+	if s > 0 {
+		parts = append(parts, fmt.Sprintf("%ds", s))
+	}
+	return strings.Join(parts, " ")
+}
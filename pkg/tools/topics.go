@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/session"
+)
+
+// TopicTool creates, switches and lists the named conversation threads of
+// the current chat. Each topic has its own history, summary and pins.
+type TopicTool struct {
+	sessions *session.SessionManager
+}
+
+func NewTopicTool(sm *session.SessionManager) *TopicTool {
+	return &TopicTool{sessions: sm}
+}
+
+func (t *TopicTool) Name() string {
+	return "topic"
+}
+
+func (t *TopicTool) Description() string {
+	return `Manage named conversation threads (topics) in this chat. Each topic keeps its own history, so the user can work on several subjects without mixing them. Actions:
+- "list": List the chat's topics and show which one is active.
+- "switch": Switch to a topic, creating it if it does not exist. Requires: name ("main" is the default conversation).
+- "current": Show the active topic.
+The switch takes effect from the user's next message; this reply still belongs to the current topic. Suggest a topic when the user starts an unrelated, long-running subject.`
+}
+
+func (t *TopicTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: list, switch, current",
+				"enum":        []string{"list", "switch", "current"},
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Topic name, e.g. 'project-x' (required for switch)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *TopicTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	sessionKey := ScopeFrom(ctx).SessionKey
+	if sessionKey == "" {
+		return "Error: no active conversation", nil
+	}
+	chatKey, _ := session.SplitTopic(sessionKey)
+
+	action, _ := args["action"].(string)
+	switch action {
+	case "list":
+		topics := t.sessions.Topics(chatKey)
+		if len(topics) == 0 {
+			return "No topics yet; everything is in the main conversation.", nil
+		}
+		var sb strings.Builder
+		marker := func(active bool) string {
+			if active {
+				return "▶"
+			}
+			return "-"
+		}
+		sb.WriteString(fmt.Sprintf("%s main\n", marker(t.sessions.ActiveTopic(chatKey) == "")))
+		for _, topic := range topics {
+			sb.WriteString(fmt.Sprintf("%s %s (%d messages, last used %s)\n",
+				marker(topic.Active), topic.Name, topic.Messages, topic.Updated.Format("2006-01-02 15:04")))
+		}
+		return sb.String(), nil
+
+	case "switch":
+		raw, _ := args["name"].(string)
+		if strings.TrimSpace(raw) == "" {
+			return "Error: 'name' is required for switch", nil
+		}
+		name, err := session.NormalizeTopic(raw)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if t.sessions.SwitchTopic(chatKey, name) {
+			return fmt.Sprintf("✓ Created topic %s; the next message starts it.", name), nil
+		}
+		if name == "" {
+			return "✓ Switched back to the main conversation.", nil
+		}
+		return fmt.Sprintf("✓ Switched to topic %s.", name), nil
+
+	case "current":
+		if name := t.sessions.ActiveTopic(chatKey); name != "" {
+			return "Active topic: " + name, nil
+		}
+		return "Active topic: main", nil
+	}
+	return fmt.Sprintf("Error: unknown action %q", action), nil
+}
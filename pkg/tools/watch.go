@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/watch"
+)
+
+// WatchPathTool lets the agent watch files and directories for the
+// current chat. Changes come back as a prompt to the agent, whose reply is
+// posted in the chat.
+type WatchPathTool struct {
+	service *watch.Service
+}
+
+func NewWatchPathTool(service *watch.Service) *WatchPathTool {
+	return &WatchPathTool{service: service}
+}
+
+func (t *WatchPathTool) Available() (bool, string) {
+	if t.service == nil {
+		return false, "file watching is disabled (watches.enabled)"
+	}
+	return true, ""
+}
+
+func (t *WatchPathTool) Name() string {
+	return "watch_path"
+}
+
+func (t *WatchPathTool) Description() string {
+	return `Watch a file or directory and react when files appear, change or disappear. Each batch of changes is sent to you as a message with the prompt below, and your reply is posted in this chat. Actions:
+- "add": Start watching. Requires: path. Optional: pattern (e.g. "*.pdf"), events (created, modified, removed; default created), recursive, prompt (what to do, e.g. "Summarize each new invoice"), name.
+- "remove": Stop watching. Requires: id.
+- "list": Show this chat's watches.`
+}
+
+func (t *WatchPathTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: add, remove, list",
+				"enum":        []string{"add", "remove", "list"},
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "File or directory to watch (~ is your home directory)",
+			},
+			"pattern": map[string]interface{}{
+				"type":        "string",
+				"description": "Only files whose name matches this glob, e.g. \"*.pdf\"",
+			},
+			"events": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string", "enum": []string{watch.EventCreated, watch.EventModified, watch.EventRemoved}},
+				"description": "Changes to react to (default: created)",
+			},
+			"recursive": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Include subdirectories",
+			},
+			"prompt": map[string]interface{}{
+				"type":        "string",
+				"description": "Instructions for when files change; the list of changed files is added automatically",
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Short name for the watch",
+			},
+			"id": map[string]interface{}{
+				"type":        "number",
+				"description": "Watch ID (for remove)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *WatchPathTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if t.service == nil {
+		return "Error: file watching is disabled (set watches.enabled)", nil
+	}
+	channel, chatID, ok := strings.Cut(ScopeFrom(ctx).ChatKey(), ":")
+	if !ok {
+		return "Error: watches are only available in a conversation", nil
+	}
+
+	action, _ := args["action"].(string)
+	switch action {
+	case "add":
+		path, _ := args["path"].(string)
+		if strings.TrimSpace(path) == "" {
+			return "Error: 'path' is required for add", nil
+		}
+		if !strings.HasPrefix(path, "~") {
+			path = resolvePath(ScopeFrom(ctx).WorkDir, path)
+		}
+		w := watch.Watch{Path: path, Channel: channel, ChatID: chatID}
+		w.Name, _ = args["name"].(string)
+		w.Pattern, _ = args["pattern"].(string)
+		w.Recursive, _ = args["recursive"].(bool)
+		if raw, _ := args["events"].([]interface{}); len(raw) > 0 {
+			for _, e := range raw {
+				if s, ok := e.(string); ok {
+					w.Events = append(w.Events, s)
+				}
+			}
+		}
+		if prompt, _ := args["prompt"].(string); strings.TrimSpace(prompt) != "" {
+			w.Prompt = strings.TrimSpace(prompt) + "\n\nChanged files:\n{{range .changes}}- {{.Event}}: {{.File}}\n{{end}}"
+		}
+		added, err := t.service.Add(w)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Watch #%d (%s) on %s. Changes will be handled here.", added.ID, added.Name, added.Path), nil
+
+	case "remove":
+		id, ok := args["id"].(float64)
+		if !ok {
+			return "Error: 'id' is required for remove", nil
+		}
+		if err := t.service.Remove(int(id), channel, chatID); err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		return fmt.Sprintf("✓ Stopped watch #%d", int(id)), nil
+
+	case "list":
+		watches := t.service.List(channel, chatID)
+		if len(watches) == 0 {
+			return "No watches in this chat.", nil
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Watches (%d):\n", len(watches))
+		for _, w := range watches {
+			events := "created"
+			if len(w.Events) > 0 {
+				events = strings.Join(w.Events, ", ")
+			}
+			fmt.Fprintf(&sb, "- #%d %s — %s", w.ID, w.Name, w.Path)
+			if w.Pattern != "" {
+				fmt.Fprintf(&sb, " (%s)", w.Pattern)
+			}
+			fmt.Fprintf(&sb, ", on %s", events)
+			if w.Configured {
+				sb.WriteString(", from config")
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: add, remove, list", action), nil
+	}
+}
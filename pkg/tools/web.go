@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -12,21 +13,36 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/netguard"
 )
 
 type WebSearchTool struct {
-	apiKey     string
+	backend    SearchBackend
+	fallback   SearchBackend
 	maxResults int
 }
 
-func NewWebSearchTool(apiKey string, maxResults int) *WebSearchTool {
+// NewWebSearchTool creates the search tool using the configured provider.
+// A misconfigured provider falls back to DuckDuckGo, which is also retried
+// whenever the primary backend fails.
+func NewWebSearchTool(cfg config.WebSearchConfig) *WebSearchTool {
+	maxResults := cfg.MaxResults
 	if maxResults <= 0 || maxResults > 10 {
 		maxResults = 5
 	}
-	return &WebSearchTool{
-		apiKey:     apiKey,
-		maxResults: maxResults,
+
+	backend, err := NewSearchBackend(cfg)
+	if err != nil {
+		log.Printf("[tools] Web search: %v — using DuckDuckGo", err)
+		backend = &duckDuckGoSearch{}
+	}
+
+	t := &WebSearchTool{backend: backend, maxResults: maxResults}
+	if backend.Name() != "duckduckgo" {
+		t.fallback = &duckDuckGoSearch{}
 	}
+	return t
 }
 
 func (t *WebSearchTool) Name() string {
@@ -57,10 +73,6 @@ func (t *WebSearchTool) Parameters() map[string]interface{} {
 }
 
 func (t *WebSearchTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
-	if t.apiKey == "" {
-		return "Error: BRAVE_API_KEY not configured", nil
-	}
-
 	query, ok := args["query"].(string)
 	if !ok {
 		return "", fmt.Errorf("query is required")
@@ -73,65 +85,52 @@ func (t *WebSearchTool) Execute(ctx context.Context, args map[string]interface{}
 		}
 	}
 
-	searchURL := fmt.Sprintf("https://api.search.brave.com/res/v1/web/search?q=%s&count=%d",
-		url.QueryEscape(query), count)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-Subscription-Token", t.apiKey)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+	results, err := t.backend.Search(ctx, query, count)
+	if err != nil && t.fallback != nil {
+		log.Printf("[tools] Web search via %s failed: %v — trying %s", t.backend.Name(), err, t.fallback.Name())
+		results, err = t.fallback.Search(ctx, query, count)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var searchResp struct {
-		Web struct {
-			Results []struct {
-				Title       string `json:"title"`
-				URL         string `json:"url"`
-				Description string `json:"description"`
-			} `json:"results"`
-		} `json:"web"`
-	}
-
-	if err := json.Unmarshal(body, &searchResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return "", fmt.Errorf("search failed: %w", err)
 	}
 
-	results := searchResp.Web.Results
 	if len(results) == 0 {
 		return fmt.Sprintf("No results for: %s", query), nil
 	}
 
+	// Skip results already returned earlier in this turn
+	cache := researchCacheFrom(ctx)
+	duplicates := 0
+
 	var lines []string
 	lines = append(lines, fmt.Sprintf("Results for: %s", query))
+	n := 0
 	for i, item := range results {
 		if i >= count {
 			break
 		}
-		lines = append(lines, fmt.Sprintf("%d. %s\n   %s", i+1, item.Title, item.URL))
-		if item.Description != "" {
-			lines = append(lines, fmt.Sprintf("   %s", item.Description))
+		if cache != nil && (cache.SeenURL(item.URL) || cache.IsDuplicate(item.Title+" "+item.Snippet)) {
+			duplicates++
+			continue
+		}
+		n++
+		lines = append(lines, fmt.Sprintf("%d. %s\n   %s", n, item.Title, item.URL))
+		if item.Snippet != "" {
+			lines = append(lines, fmt.Sprintf("   %s", item.Snippet))
 		}
 	}
 
+	if duplicates > 0 {
+		lines = append(lines, fmt.Sprintf("(%d results omitted: already seen earlier in this task)", duplicates))
+	}
+
 	return strings.Join(lines, "\n"), nil
 }
 
 type WebFetchTool struct {
 	maxChars int
+	profiles ExtractionProfiles
+	guard    *netguard.Guard
 }
 
 func NewWebFetchTool(maxChars int) *WebFetchTool {
@@ -140,9 +139,21 @@ func NewWebFetchTool(maxChars int) *WebFetchTool {
 	}
 	return &WebFetchTool{
 		maxChars: maxChars,
+		guard:    netguard.New(nil),
 	}
 }
 
+// SetNetworkGuard replaces the guard that blocks requests to internal addresses.
+func (t *WebFetchTool) SetNetworkGuard(g *netguard.Guard) {
+	t.guard = g
+}
+
+// SetProfiles sets per-domain extraction profiles.
+
+func (t *WebFetchTool) SetProfiles(profiles ExtractionProfiles) {
+	t.profiles = profiles
+}
+
 func (t *WebFetchTool) Name() string {
 	return "web_fetch"
 }
@@ -188,6 +199,11 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]interface{})
 		return "", fmt.Errorf("missing domain in URL")
 	}
 
+	cache := researchCacheFrom(ctx)
+	if cache != nil && cache.SeenURL(urlStr) {
+		return fmt.Sprintf("Already fetched %s earlier in this task; use the earlier result instead of fetching it again.", urlStr), nil
+	}
+
 	maxChars := t.maxChars
 	if mc, ok := args["maxChars"].(float64); ok {
 		if int(mc) > 100 {
@@ -205,13 +221,8 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]interface{})
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9,vi;q=0.8")
 
 	client := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:        10,
-			IdleConnTimeout:     30 * time.Second,
-			DisableCompression:  false,
-			TLSHandshakeTimeout: 15 * time.Second,
-		},
+		Timeout:   30 * time.Second,
+		Transport: t.guard.Transport(),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if len(via) >= 5 {
 				return fmt.Errorf("stopped after 5 redirects")
@@ -247,7 +258,8 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]interface{})
 		}
 	} else if strings.Contains(contentType, "text/html") || len(body) > 0 &&
 		(strings.HasPrefix(string(body), "<!DOCTYPE") || strings.HasPrefix(strings.ToLower(string(body)), "<html")) {
-		text = t.extractTextGoquery(string(body))
+		profile, _ := t.profiles.match(urlStr)
+		text = t.extractTextGoquery(string(body), profile)
 		extractor = "goquery"
 	} else {
 		text = string(body)
@@ -259,6 +271,12 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]interface{})
 		text = text[:maxChars]
 	}
 
+	// Drop passages already seen in other pages this turn (mirrors, syndicated articles)
+	duplicates := 0
+	if cache != nil && extractor != "json" {
+		text, duplicates = cache.DedupParagraphs(text)
+	}
+
 	result := map[string]interface{}{
 		"url":       urlStr,
 		"status":    resp.StatusCode,
@@ -267,6 +285,9 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]interface{})
 		"length":    len(text),
 		"text":      text,
 	}
+	if duplicates > 0 {
+		result["duplicate_paragraphs_omitted"] = duplicates
+	}
 
 	resultJSON, _ := json.MarshalIndent(result, "", "  ")
 	return string(resultJSON), nil
@@ -274,7 +295,8 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]interface{})
 
 // extractTextGoquery uses goquery to parse HTML and extract readable text
 // preserving document structure (headings, paragraphs, lists, links, tables).
-func (t *WebFetchTool) extractTextGoquery(htmlContent string) string {
+// The profile overrides content selection and formatting for known sites.
+func (t *WebFetchTool) extractTextGoquery(htmlContent string, profile config.ExtractionProfile) string {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
 	if err != nil {
 		return t.extractTextFallback(htmlContent)
@@ -282,11 +304,19 @@ func (t *WebFetchTool) extractTextGoquery(htmlContent string) string {
 
 	// Remove non-content elements
 	doc.Find("script, style, nav, footer, header, iframe, noscript, svg, form, button, input, select, textarea, [role='navigation'], [role='banner'], [role='complementary'], .sidebar, .nav, .menu, .footer, .header, .ad, .advertisement, .cookie-banner").Remove()
+	for _, sel := range profile.Remove {
+		doc.Find(sel).Remove()
+	}
 
 	var parts []string
 
 	// Try to find main content area first
 	mainContent := doc.Find("main, article, [role='main'], .content, .post-content, .article-content, .entry-content, #content, #main")
+	if profile.Selector != "" {
+		if custom := doc.Find(profile.Selector); custom.Length() > 0 {
+			mainContent = custom
+		}
+	}
 	var contentNode *goquery.Selection
 	if mainContent.Length() > 0 {
 		contentNode = mainContent.First()
@@ -301,7 +331,17 @@ func (t *WebFetchTool) extractTextGoquery(htmlContent string) string {
 	contentNode.Find("*").Each(func(i int, s *goquery.Selection) {
 		tag := goquery.NodeName(s)
 
+		if profile.KeepTables && tag != "table" && s.ParentsFiltered("table").Length() > 0 {
+			return // rendered with its table
+		}
+
 		switch tag {
+		case "table":
+			if profile.KeepTables && s.ParentsFiltered("table").Length() == 0 {
+				if md := tableMarkdown(s); md != "" {
+					parts = append(parts, "\n"+md+"\n")
+				}
+			}
 		case "h1", "h2", "h3", "h4", "h5", "h6":
 			text := strings.TrimSpace(s.Text())
 			if text != "" {
@@ -322,7 +362,7 @@ func (t *WebFetchTool) extractTextGoquery(htmlContent string) string {
 		case "a":
 			href, exists := s.Attr("href")
 			text := strings.TrimSpace(s.Text())
-			if exists && text != "" && strings.HasPrefix(href, "http") {
+			if exists && text != "" && strings.HasPrefix(href, "http") && !profile.StripLinks {
 				parts = append(parts, fmt.Sprintf("[%s](%s)", text, href))
 			}
 		case "td", "th":
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ntminh611/mclaw/pkg/workflow"
+)
+
+// WorkflowTool lets the agent list, inspect and run YAML workflows
+type WorkflowTool struct {
+	engine *workflow.Engine
+}
+
+func NewWorkflowTool(engine *workflow.Engine) *WorkflowTool {
+	return &WorkflowTool{engine: engine}
+}
+
+func (t *WorkflowTool) Name() string {
+	return "workflow"
+}
+
+func (t *WorkflowTool) Description() string {
+	return fmt.Sprintf(`Run deterministic multi-step workflows defined as YAML files in %s. Actions:
+- "list": List available workflows.
+- "show": Show a workflow definition. Requires: name.
+- "run": Run a workflow now and return each step's output. Requires: name.
+Workflow format: name, description, steps[]. Each step has an id and exactly one of tool (+args), prompt, or notify.
+Steps may set extract (regex, first group becomes output), when {step, contains, not_contains, matches, gt, lt} and continue_on_error.
+Use {{step_id}}, {{date}} and {{time}} in args, prompts and notifications. Create workflows with write_file; schedule them with the cron tool's "workflow" parameter.`, t.engine.Dir())
+}
+
+func (t *WorkflowTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Action to perform: list, show, run",
+				"enum":        []string{"list", "show", "run"},
+			},
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Workflow name (required for show/run)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *WorkflowTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	action, _ := args["action"].(string)
+	name, _ := args["name"].(string)
+
+	switch action {
+	case "list":
+		return t.list(), nil
+	case "show", "run":
+		if name == "" {
+			return fmt.Sprintf("Error: 'name' is required for %s", action), nil
+		}
+		wf, err := t.engine.Get(name)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err), nil
+		}
+		if action == "show" {
+			data, err := os.ReadFile(wf.Path)
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err), nil
+			}
+			return string(data), nil
+		}
+		return t.run(ctx, wf)
+	default:
+		return fmt.Sprintf("Unknown action: %s. Use: list, show, run", action), nil
+	}
+}
+
+func (t *WorkflowTool) list() string {
+	workflows, errs := t.engine.List()
+
+	var sb strings.Builder
+	if len(workflows) == 0 {
+		sb.WriteString(fmt.Sprintf("No workflows in %s.\n", t.engine.Dir()))
+	} else {
+		sb.WriteString(fmt.Sprintf("Workflows (%d):\n", len(workflows)))
+		for _, wf := range workflows {
+			sb.WriteString(fmt.Sprintf("- %s (%d steps)", wf.Name, len(wf.Steps)))
+			if wf.Description != "" {
+				sb.WriteString(": " + wf.Description)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	for _, err := range errs {
+		sb.WriteString(fmt.Sprintf("⚠️ Invalid: %v\n", err))
+	}
+	return sb.String()
+}
+
+func (t *WorkflowTool) run(ctx context.Context, wf *workflow.Workflow) (string, error) {
+	result, err := t.engine.Run(ctx, wf, workflow.Target{})
+	data, _ := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("Error: workflow %s failed: %v\n%s", wf.Name, err, string(data)), nil
+	}
+	return fmt.Sprintf("✓ Workflow %s completed\n%s", wf.Name, string(data)), nil
+}
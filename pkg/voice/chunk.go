@@ -0,0 +1,100 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// ChunkLength is the length of the pieces long recordings are cut into.
+// Ten minutes of 32 kbps Opus stays far below the 25 MB upload limit of
+// hosted Whisper APIs.
+const ChunkLength = 10 * time.Minute
+
+// chunkTimeout bounds the transcription of one piece.
+const chunkTimeout = 90 * time.Second
+
+// TranscribeLong transcribes a recording of any length, or the audio of a
+// video. Recordings longer than ChunkLength, and videos, are cut into
+// Opus pieces with ffmpeg and transcribed in turn; the texts are joined.
+// Without ffmpeg the file is sent as it is, which works for short clips.
+func TranscribeLong(ctx context.Context, t Transcriber, path string, duration time.Duration) (*TranscriptionResponse, error) {
+	if duration <= ChunkLength && !isVideo(path) {
+		return transcribeChunk(ctx, t, path)
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return transcribeChunk(ctx, t, path)
+	}
+
+	dir, err := os.MkdirTemp("", "mclaw_chunks")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	pieces, err := splitAudio(ctx, path, dir)
+	if err != nil {
+		return nil, err
+	}
+	logger.InfoCF("voice", "Transcribing in pieces", map[string]interface{}{"audio_file": path, "pieces": len(pieces)})
+
+	var texts []string
+	result := &TranscriptionResponse{}
+	for i, piece := range pieces {
+		r, err := transcribeChunk(ctx, t, piece)
+		if err != nil {
+			return nil, fmt.Errorf("piece %d of %d: %w", i+1, len(pieces), err)
+		}
+		if text := strings.TrimSpace(r.Text); text != "" {
+			texts = append(texts, text)
+		}
+		if result.Language == "" {
+			result.Language = r.Language
+		}
+		result.Duration += r.Duration
+	}
+	result.Text = strings.Join(texts, " ")
+	return result, nil
+}
+
+func transcribeChunk(ctx context.Context, t Transcriber, path string) (*TranscriptionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, chunkTimeout)
+	defer cancel()
+	return t.Transcribe(ctx, path)
+}
+
+// splitAudio writes the audio track of path to dir as mono Opus pieces of
+// ChunkLength and returns them in order.
+func splitAudio(ctx context.Context, path, dir string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-loglevel", "error", "-i", path,
+		"-vn", "-ac", "1", "-c:a", "libopus", "-b:a", "32k",
+		"-f", "segment", "-segment_time", fmt.Sprintf("%d", int(ChunkLength.Seconds())),
+		filepath.Join(dir, "piece_%03d.ogg"))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, truncateText(string(output), 200))
+	}
+	pieces, err := filepath.Glob(filepath.Join(dir, "piece_*.ogg"))
+	if err != nil {
+		return nil, err
+	}
+	if len(pieces) == 0 {
+		return nil, fmt.Errorf("no audio track in %s", filepath.Base(path))
+	}
+	sort.Strings(pieces)
+	return pieces, nil
+}
+
+func isVideo(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp4", ".mov", ".webm", ".mkv":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// NewTranscriber creates the transcriber selected by cfg.Provider, or nil
+// when none is configured. groqKey is used by the default groq provider.
+func NewTranscriber(cfg config.STTConfig, groqKey string) (Transcriber, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", "groq":
+		if groqKey == "" {
+			return nil, nil
+		}
+		t := NewGroqTranscriber(groqKey)
+		t.language = cfg.Language
+		return t, nil
+	case "server":
+		if cfg.APIBase == "" {
+			return nil, fmt.Errorf("server transcription needs stt.api_base")
+		}
+		return NewServerTranscriber(cfg.APIBase, cfg.APIKey, cfg.Model, cfg.Language), nil
+	case "whisper_cpp", "whisper.cpp":
+		return NewWhisperCppTranscriber(cfg.Binary, cfg.Model, cfg.Language)
+	default:
+		return nil, fmt.Errorf("unknown transcription provider %q (use groq, server or whisper_cpp)", cfg.Provider)
+	}
+}
+
+// NewServerTranscriber uses a self-hosted OpenAI-compatible transcription
+// server, e.g. faster-whisper-server or whisper.cpp's server started with
+// --inference-path /v1/audio/transcriptions. apiBase includes the /v1 part.
+func NewServerTranscriber(apiBase, apiKey, model, language string) *GroqTranscriber {
+	if model == "" {
+		model = "whisper-1"
+	}
+	return &GroqTranscriber{
+		name:       "local server",
+		apiKey:     apiKey,
+		apiBase:    strings.TrimRight(apiBase, "/"),
+		model:      model,
+		language:   language,
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// WhisperCppTranscriber runs the whisper.cpp CLI on this machine, so audio
+// never leaves the host. ffmpeg converts voice notes to the 16 kHz WAV
+// whisper.cpp expects.
+type WhisperCppTranscriber struct {
+	binary   string
+	model    string
+	language string
+}
+
+// NewWhisperCppTranscriber checks that the binary, model file and ffmpeg
+// are present. binary defaults to whisper-cli (older builds name it main).
+func NewWhisperCppTranscriber(binary, model, language string) (*WhisperCppTranscriber, error) {
+	if binary == "" {
+		binary = "whisper-cli"
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("whisper.cpp binary %q not found: %w", binary, err)
+	}
+	if model == "" {
+		return nil, fmt.Errorf("whisper.cpp needs stt.model (path to a ggml model file)")
+	}
+	model = expandHome(model)
+	if _, err := os.Stat(model); err != nil {
+		return nil, fmt.Errorf("whisper.cpp model: %w", err)
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, fmt.Errorf("whisper.cpp transcription needs ffmpeg to convert audio")
+	}
+	if language == "" {
+		language = "auto"
+	}
+	return &WhisperCppTranscriber{binary: path, model: model, language: language}, nil
+}
+
+func (t *WhisperCppTranscriber) Name() string { return "whisper.cpp" }
+
+func (t *WhisperCppTranscriber) IsAvailable() bool { return true }
+
+func (t *WhisperCppTranscriber) Transcribe(ctx context.Context, audioFilePath string) (*TranscriptionResponse, error) {
+	logger.InfoCF("voice", "Starting local transcription", map[string]interface{}{"audio_file": audioFilePath})
+
+	tmpDir, err := os.MkdirTemp("", "mclaw_whisper")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	wav := filepath.Join(tmpDir, "audio.wav")
+	convert := exec.CommandContext(ctx, "ffmpeg", "-y", "-loglevel", "error", "-i", audioFilePath, "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", wav)
+	if output, err := convert.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, truncateText(string(output), 200))
+	}
+
+	outBase := filepath.Join(tmpDir, "transcript")
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, t.binary, "-m", t.model, "-f", wav, "-l", t.language, "-nt", "-otxt", "-of", outBase)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("whisper.cpp failed: %w: %s", err, truncateText(string(output), 200))
+	}
+
+	data, err := os.ReadFile(outBase + ".txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	text := strings.Join(strings.Fields(string(data)), " ")
+
+	logger.InfoCF("voice", "Local transcription completed", map[string]interface{}{
+		"text_length":           len(text),
+		"elapsed":               time.Since(start).String(),
+		"transcription_preview": truncateText(text, 50),
+	})
+	return &TranscriptionResponse{Text: text}, nil
+}
+
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,255 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+	"github.com/ntminh611/mclaw/pkg/logger"
+)
+
+// Synthesizer turns reply text into an audio file. Files are written as
+// OGG/Opus when possible so channels can send them as voice notes; the
+// caller removes the file after sending.
+type Synthesizer interface {
+	Name() string
+	Synthesize(ctx context.Context, text string) (string, error)
+}
+
+// NewSynthesizer creates the synthesizer selected by cfg.Provider, or nil
+// when text-to-speech is not configured. openaiKey is used when the openai
+// provider has no key of its own.
+func NewSynthesizer(cfg config.TTSConfig, openaiKey string) (Synthesizer, error) {
+	client := &http.Client{Timeout: 60 * time.Second}
+
+	switch strings.ToLower(cfg.Provider) {
+	case "":
+		return nil, nil
+	case "openai":
+		apiKey := cfg.APIKey
+		if apiKey == "" {
+			apiKey = openaiKey
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("openai tts needs tts.api_key or providers.openai.api_key")
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = "https://api.openai.com/v1"
+		}
+		return &openAISynthesizer{
+			apiKey:  apiKey,
+			apiBase: strings.TrimRight(apiBase, "/"),
+			model:   valueOr(cfg.Model, "tts-1"),
+			voice:   valueOr(cfg.Voice, "alloy"),
+			client:  client,
+		}, nil
+	case "elevenlabs":
+		if cfg.APIKey == "" || cfg.Voice == "" {
+			return nil, fmt.Errorf("elevenlabs tts needs tts.api_key and tts.voice (voice ID)")
+		}
+		return &elevenLabsSynthesizer{
+			apiKey: cfg.APIKey,
+			model:  valueOr(cfg.Model, "eleven_multilingual_v2"),
+			voice:  cfg.Voice,
+			client: client,
+		}, nil
+	case "piper":
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("piper tts needs tts.model (path to a .onnx voice)")
+		}
+		if _, err := exec.LookPath("piper"); err != nil {
+			return nil, fmt.Errorf("piper tts: piper binary not found in PATH")
+		}
+		return &piperSynthesizer{model: cfg.Model}, nil
+	default:
+		return nil, fmt.Errorf("unknown tts provider %q (use openai, elevenlabs or piper)", cfg.Provider)
+	}
+}
+
+func valueOr(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// outputPath returns a fresh file path for synthesized audio.
+func outputPath(ext string) (string, error) {
+	dir := filepath.Join(os.TempDir(), "mclaw_media")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("tts_%d%s", time.Now().UnixNano(), ext)), nil
+}
+
+// toOggOpus converts audio to OGG/Opus with ffmpeg, which Telegram requires
+// for voice notes. Without ffmpeg the original file is returned.
+func toOggOpus(ctx context.Context, path string) string {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return path
+	}
+	out := strings.TrimSuffix(path, filepath.Ext(path)) + ".ogg"
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-loglevel", "error", "-i", path, "-c:a", "libopus", "-b:a", "48k", out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.WarnCF("voice", "ffmpeg conversion failed", map[string]interface{}{"error": err, "output": string(output)})
+		os.Remove(out)
+		return path
+	}
+	os.Remove(path)
+	return out
+}
+
+// postAudio sends a TTS request and writes the audio response to path.
+func postAudio(client *http.Client, req *http.Request, path string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2000))
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write audio: %w", err)
+	}
+	return f.Close()
+}
+
+// ── OpenAI ──────────────────────────────────────────────────
+
+type openAISynthesizer struct {
+	apiKey  string
+	apiBase string
+	model   string
+	voice   string
+	client  *http.Client
+}
+
+func (s *openAISynthesizer) Name() string { return "openai" }
+
+func (s *openAISynthesizer) Synthesize(ctx context.Context, text string) (string, error) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"model":           s.model,
+		"voice":           s.voice,
+		"input":           text,
+		"response_format": "opus", // OGG/Opus, sent as-is
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiBase+"/audio/speech", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	path, err := outputPath(".ogg")
+	if err != nil {
+		return "", err
+	}
+	if err := postAudio(s.client, req, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ── ElevenLabs ──────────────────────────────────────────────
+
+type elevenLabsSynthesizer struct {
+	apiKey string
+	model  string
+	voice  string
+	client *http.Client
+}
+
+func (s *elevenLabsSynthesizer) Name() string { return "elevenlabs" }
+
+func (s *elevenLabsSynthesizer) Synthesize(ctx context.Context, text string) (string, error) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"text":     text,
+		"model_id": s.model,
+	})
+
+	url := fmt.Sprintf("https://api.elevenlabs.io/v1/text-to-speech/%s?output_format=mp3_44100_128", s.voice)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("xi-api-key", s.apiKey)
+
+	path, err := outputPath(".mp3")
+	if err != nil {
+		return "", err
+	}
+	if err := postAudio(s.client, req, path); err != nil {
+		return "", err
+	}
+	return toOggOpus(ctx, path), nil
+}
+
+// ── Piper (local) ───────────────────────────────────────────
+
+type piperSynthesizer struct {
+	model string
+}
+
+func (s *piperSynthesizer) Name() string { return "piper" }
+
+func (s *piperSynthesizer) Synthesize(ctx context.Context, text string) (string, error) {
+	path, err := outputPath(".wav")
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(ctx, "piper", "--model", s.model, "--output_file", path)
+	cmd.Stdin = strings.NewReader(text)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("piper failed: %w: %s", err, truncateText(string(output), 200))
+	}
+	return toOggOpus(ctx, path), nil
+}
+
+var (
+	mdCodeBlock = regexp.MustCompile("(?s)```.*?```")
+	mdLink      = regexp.MustCompile(`\[([^\]]+)\]\([^)]+\)`)
+	mdURL       = regexp.MustCompile(`https?://\S+`)
+	mdMarkers   = regexp.MustCompile("[*_`#>|~]+")
+)
+
+// SpeakableText strips Markdown formatting, code blocks and URLs from a
+// reply so it reads naturally when spoken.
+func SpeakableText(text string) string {
+	text = mdCodeBlock.ReplaceAllString(text, " (code omitted) ")
+	text = mdLink.ReplaceAllString(text, "$1")
+	text = mdURL.ReplaceAllString(text, "")
+	text = mdMarkers.ReplaceAllString(text, "")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" && strings.Trim(line, "-=") != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
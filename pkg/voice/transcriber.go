@@ -15,9 +15,21 @@ import (
 	"github.com/ntminh611/mclaw/pkg/logger"
 )
 
+// Transcriber converts a voice message to text.
+type Transcriber interface {
+	Name() string
+	IsAvailable() bool
+	Transcribe(ctx context.Context, audioFilePath string) (*TranscriptionResponse, error)
+}
+
+// GroqTranscriber calls an OpenAI-compatible /audio/transcriptions endpoint:
+// Groq by default, or a self-hosted server (see NewServerTranscriber).
 type GroqTranscriber struct {
+	name       string
 	apiKey     string
 	apiBase    string
+	model      string
+	language   string
 	httpClient *http.Client
 }
 
@@ -32,8 +44,10 @@ func NewGroqTranscriber(apiKey string) *GroqTranscriber {
 
 	apiBase := "https://api.groq.com/openai/v1"
 	return &GroqTranscriber{
+		name:    "Groq",
 		apiKey:  apiKey,
 		apiBase: apiBase,
+		model:   "whisper-large-v3",
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
@@ -78,11 +92,17 @@ func (t *GroqTranscriber) Transcribe(ctx context.Context, audioFilePath string)
 
 	logger.DebugCF("voice", "File copied to request", map[string]interface{}{"bytes_copied": copied})
 
-	if err := writer.WriteField("model", "whisper-large-v3"); err != nil {
+	if err := writer.WriteField("model", t.model); err != nil {
 		logger.ErrorCF("voice", "Failed to write model field", map[string]interface{}{"error": err})
 		return nil, fmt.Errorf("failed to write model field: %w", err)
 	}
 
+	if t.language != "" {
+		if err := writer.WriteField("language", t.language); err != nil {
+			return nil, fmt.Errorf("failed to write language field: %w", err)
+		}
+	}
+
 	if err := writer.WriteField("response_format", "json"); err != nil {
 		logger.ErrorCF("voice", "Failed to write response_format field", map[string]interface{}{"error": err})
 		return nil, fmt.Errorf("failed to write response_format field: %w", err)
@@ -101,9 +121,12 @@ func (t *GroqTranscriber) Transcribe(ctx context.Context, audioFilePath string)
 	}
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
 
-	logger.DebugCF("voice", "Sending transcription request to Groq API", map[string]interface{}{
+	logger.DebugCF("voice", "Sending transcription request", map[string]interface{}{
+		"backend":            t.name,
 		"url":                url,
 		"request_size_bytes": requestBody.Len(),
 		"file_size_bytes":    fileInfo.Size(),
@@ -130,7 +153,7 @@ func (t *GroqTranscriber) Transcribe(ctx context.Context, audioFilePath string)
 		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	logger.DebugCF("voice", "Received response from Groq API", map[string]interface{}{
+	logger.DebugCF("voice", "Received transcription response", map[string]interface{}{
 		"status_code":         resp.StatusCode,
 		"response_size_bytes": len(body),
 	})
@@ -151,8 +174,11 @@ func (t *GroqTranscriber) Transcribe(ctx context.Context, audioFilePath string)
 	return &result, nil
 }
 
+func (t *GroqTranscriber) Name() string { return t.name }
+
 func (t *GroqTranscriber) IsAvailable() bool {
-	available := t.apiKey != ""
+	// Self-hosted servers usually need no key
+	available := t.apiKey != "" || t.name != "Groq"
 	logger.DebugCF("voice", "Checking transcriber availability", map[string]interface{}{"available": available})
 	return available
 }
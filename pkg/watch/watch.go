@@ -0,0 +1,428 @@
+// Package watch polls files and directories for changes and hands each
+// batch of changes to the agent as a prompt, like a webhook fired by the
+// file system. Watches come from the config or are added at runtime with
+// the watch_path tool, which keeps them in a JSON file.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Kinds of change.
+const (
+	EventCreated  = "created"
+	EventModified = "modified"
+	EventRemoved  = "removed"
+)
+
+// maxEntries caps how many files one watch tracks.
+const maxEntries = 5000
+
+// turnTimeout bounds one watch-triggered turn.
+const turnTimeout = 10 * time.Minute
+
+// defaultPrompt is used by watches without a prompt template.
+const defaultPrompt = `Files changed in {{.path}} (watch "{{.name}}"):
+{{range .changes}}- {{.Event}}: {{.File}}{{if .Size}} ({{.Size}} bytes){{end}}
+{{end}}
+Look at the new or changed files if that helps, then report briefly.`
+
+// Watch is one watched file or directory.
+type Watch struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	Path       string   `json:"path"`
+	Pattern    string   `json:"pattern,omitempty"`   // file name glob, e.g. "*.pdf"
+	Recursive  bool     `json:"recursive,omitempty"` // include subdirectories
+	Events     []string `json:"events,omitempty"`    // default: created
+	Prompt     string   `json:"prompt,omitempty"`    // Go template over .name, .path and .changes
+	Channel    string   `json:"channel"`
+	ChatID     string   `json:"chat_id"`
+	Configured bool     `json:"-"` // from the config file, not removable at runtime
+}
+
+// Change is one file that was created, modified or removed.
+type Change struct {
+	Event string
+	File  string // path of the file
+	Size  int64
+}
+
+// RunFunc runs an agent turn in the given session and returns the reply.
+type RunFunc func(ctx context.Context, prompt, sessionKey string) (string, error)
+
+// NotifyFunc sends a message to a chat.
+type NotifyFunc func(channel, chatID, content string)
+
+// Service scans every watch on an interval.
+type Service struct {
+	path     string // runtime watches
+	interval time.Duration
+	run      RunFunc
+	notify   NotifyFunc
+	mu       sync.Mutex
+	watches  []*watcher
+	nextID   int
+	wg       sync.WaitGroup
+}
+
+type watcher struct {
+	Watch
+	prompt  *template.Template
+	seen    map[string]fileState // nil until the first scan
+	pending map[string]string    // path → event, reported once the file settles
+	busy    sync.Mutex           // one turn per watch at a time
+}
+
+type fileState struct {
+	size int64
+	mod  time.Time
+}
+
+// NewService loads the runtime watches kept at path and adds the
+// configured ones. Invalid watches are skipped and reported in the
+// returned error; the service is usable either way.
+func NewService(path string, interval time.Duration, configured []Watch, run RunFunc, notify NotifyFunc) (*Service, error) {
+	s := &Service{path: path, interval: interval, run: run, notify: notify}
+	var errs []error
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		errs = append(errs, err)
+	}
+	if len(data) > 0 {
+		var saved []Watch
+		if err := json.Unmarshal(data, &saved); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+		for _, w := range saved {
+			if _, err := s.add(w); err != nil {
+				errs = append(errs, fmt.Errorf("watch %q: %w", w.Name, err))
+			}
+		}
+	}
+
+	// Configured watches are numbered after the saved ones
+	for _, w := range configured {
+		w.ID = 0
+		w.Configured = true
+		if _, err := s.add(w); err != nil {
+			errs = append(errs, fmt.Errorf("watch %q: %w", w.Name, err))
+		}
+	}
+	return s, errors.Join(errs...)
+}
+
+// Add starts watching w and saves it. The ID is assigned and, when empty,
+// the name is derived from it.
+func (s *Service) Add(w Watch) (Watch, error) {
+	w.ID = 0
+	w.Configured = false
+	added, err := s.add(w)
+	if err != nil {
+		return Watch{}, err
+	}
+	if err := s.save(); err != nil {
+		log.Printf("[watch] Failed to save watches: %v", err)
+	}
+	return added, nil
+}
+
+// Remove stops a runtime watch that reports to the given chat.
+func (s *Service) Remove(id int, channel, chatID string) error {
+	s.mu.Lock()
+	found := false
+	for i, w := range s.watches {
+		if w.ID != id || w.Channel != channel || w.ChatID != chatID {
+			continue
+		}
+		if w.Configured {
+			s.mu.Unlock()
+			return fmt.Errorf("watch #%d is set in the config file; remove it there", id)
+		}
+		s.watches = append(s.watches[:i], s.watches[i+1:]...)
+		found = true
+		break
+	}
+	s.mu.Unlock()
+	if !found {
+		return fmt.Errorf("no watch #%d in this chat", id)
+	}
+	return s.save()
+}
+
+// List returns the watches that report to the given chat.
+func (s *Service) List(channel, chatID string) []Watch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []Watch
+	for _, w := range s.watches {
+		if w.Channel == channel && w.ChatID == chatID {
+			list = append(list, w.Watch)
+		}
+	}
+	return list
+}
+
+// Run scans the watches until ctx is cancelled, then waits for running
+// turns.
+func (s *Service) Run(ctx context.Context) {
+	s.mu.Lock()
+	log.Printf("[watch] Watching %d path(s) every %s", len(s.watches), s.interval)
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	s.Scan(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return
+		case <-ticker.C:
+			s.Scan(ctx)
+		}
+	}
+}
+
+// Scan checks every watch once and starts a turn for each one with
+// changes. The first scan of a watch only records what is there.
+func (s *Service) Scan(ctx context.Context) {
+	s.mu.Lock()
+	watches := append([]*watcher(nil), s.watches...)
+	s.mu.Unlock()
+
+	for _, w := range watches {
+		changes := w.scan()
+		if len(changes) == 0 {
+			continue
+		}
+		s.wg.Add(1)
+		go s.fire(ctx, w, changes)
+	}
+}
+
+// Wait blocks until the turns started by Scan are done.
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Service) fire(ctx context.Context, w *watcher, changes []Change) {
+	defer s.wg.Done()
+	w.busy.Lock()
+	defer w.busy.Unlock()
+
+	var sb strings.Builder
+	err := w.prompt.Execute(&sb, map[string]interface{}{
+		"name":    w.Name,
+		"path":    w.Path,
+		"changes": changes,
+	})
+	if err != nil {
+		log.Printf("[watch] Watch %q: template error: %v", w.Name, err)
+		return
+	}
+	log.Printf("[watch] %d change(s) in %s", len(changes), w.Path)
+
+	ctx, cancel := context.WithTimeout(ctx, turnTimeout)
+	defer cancel()
+	reply, err := s.run(ctx, sb.String(), "watch:"+w.Name)
+	if err != nil {
+		log.Printf("[watch] Watch %q failed: %v", w.Name, err)
+		reply = fmt.Sprintf("⚠️ Watch %s failed: %v", w.Name, err)
+	}
+	if w.Channel != "" && w.ChatID != "" && strings.TrimSpace(reply) != "" {
+		s.notify(w.Channel, w.ChatID, reply)
+	}
+}
+
+func (s *Service) add(w Watch) (Watch, error) {
+	w.Path = expandHome(w.Path)
+	if w.Path == "" {
+		return Watch{}, fmt.Errorf("path is required")
+	}
+	if _, err := os.Stat(w.Path); err != nil {
+		return Watch{}, err
+	}
+	if w.Pattern != "" {
+		if _, err := filepath.Match(w.Pattern, ""); err != nil {
+			return Watch{}, fmt.Errorf("bad pattern %q: %w", w.Pattern, err)
+		}
+	}
+	for _, e := range w.Events {
+		if e != EventCreated && e != EventModified && e != EventRemoved {
+			return Watch{}, fmt.Errorf("unknown event %q (use created, modified or removed)", e)
+		}
+	}
+	text := w.Prompt
+	if strings.TrimSpace(text) == "" {
+		text = defaultPrompt
+	}
+	tmpl, err := template.New(w.Name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return Watch{}, fmt.Errorf("prompt: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w.ID == 0 {
+		w.ID = s.nextID + 1
+	}
+	if w.ID > s.nextID {
+		s.nextID = w.ID
+	}
+	if w.Name == "" {
+		w.Name = fmt.Sprintf("watch-%d", w.ID)
+	}
+	for _, other := range s.watches {
+		if other.Name == w.Name || other.ID == w.ID {
+			return Watch{}, fmt.Errorf("a watch named %q or numbered #%d already exists", w.Name, w.ID)
+		}
+	}
+	s.watches = append(s.watches, &watcher{Watch: w, prompt: tmpl, pending: make(map[string]string)})
+	return w, nil
+}
+
+func (s *Service) save() error {
+	s.mu.Lock()
+	var runtime []Watch
+	for _, w := range s.watches {
+		if !w.Configured {
+			runtime = append(runtime, w.Watch)
+		}
+	}
+	s.mu.Unlock()
+
+	data, err := json.MarshalIndent(runtime, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// scan compares the files against the last scan. New and modified files
+// are reported once they stayed the same for a whole interval, so a file
+// still being written or downloaded is reported once, when complete.
+func (w *watcher) scan() []Change {
+	current := snapshot(w.Path, w.Pattern, w.Recursive)
+	if w.seen == nil {
+		w.seen = current
+		return nil
+	}
+
+	var changes []Change
+	for path, st := range current {
+		old, existed := w.seen[path]
+		switch {
+		case !existed:
+			if _, ok := w.pending[path]; !ok {
+				w.pending[path] = EventCreated
+			}
+		case st != old:
+			if _, ok := w.pending[path]; !ok {
+				w.pending[path] = EventModified
+			}
+		default:
+			if event, ok := w.pending[path]; ok {
+				delete(w.pending, path)
+				changes = append(changes, Change{Event: event, File: path, Size: st.size})
+			}
+		}
+	}
+	for path := range w.seen {
+		if _, ok := current[path]; ok {
+			continue
+		}
+		event, pending := w.pending[path]
+		delete(w.pending, path)
+		if pending && event == EventCreated {
+			continue // came and went between scans
+		}
+		changes = append(changes, Change{Event: EventRemoved, File: path})
+	}
+	w.seen = current
+
+	wanted := w.Events
+	if len(wanted) == 0 {
+		wanted = []string{EventCreated}
+	}
+	filtered := changes[:0]
+	for _, c := range changes {
+		for _, e := range wanted {
+			if c.Event == e {
+				filtered = append(filtered, c)
+				break
+			}
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].File < filtered[j].File })
+	return filtered
+}
+
+// snapshot lists the watched files with their size and modification time.
+// Hidden files and directories are skipped.
+func snapshot(root, pattern string, recursive bool) map[string]fileState {
+	files := make(map[string]fileState)
+	add := func(path string, info fs.FileInfo) {
+		if pattern != "" {
+			if ok, _ := filepath.Match(pattern, info.Name()); !ok {
+				return
+			}
+		}
+		files[path] = fileState{size: info.Size(), mod: info.ModTime()}
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return files
+	}
+	if !info.IsDir() {
+		add(root, info)
+		return files
+	}
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || (d.IsDir() && !recursive) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if len(files) >= maxEntries {
+			return filepath.SkipAll
+		}
+		if info, err := d.Info(); err == nil {
+			add(path, info)
+		}
+		return nil
+	})
+	return files
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || rest[0] == '/') {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + rest
+		}
+	}
+	return path
+}
@@ -0,0 +1,120 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu       sync.Mutex
+	prompts  []string
+	sessions []string
+	sent     []string
+}
+
+func (r *recorder) run(ctx context.Context, prompt, sessionKey string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompts = append(r.prompts, prompt)
+	r.sessions = append(r.sessions, sessionKey)
+	return "noted", nil
+}
+
+func (r *recorder) notify(channel, chatID, content string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, channel+":"+chatID+" "+content)
+}
+
+func TestScanReportsSettledFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "old.pdf"), []byte("old"), 0644)
+	r := &recorder{}
+	s, err := NewService(filepath.Join(t.TempDir(), "watches.json"), time.Minute, []Watch{{
+		Name: "invoices", Path: dir, Pattern: "*.pdf", Events: []string{EventCreated, EventRemoved},
+		Channel: "telegram", ChatID: "42",
+	}}, r.run, r.notify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	s.Scan(ctx) // baseline: existing files are not news
+	os.WriteFile(filepath.Join(dir, "new.pdf"), []byte("partial"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+	s.Scan(ctx) // seen, but may still be written
+	s.Wait()
+	if len(r.prompts) != 0 {
+		t.Fatalf("expected a new file to settle first, got %q", r.prompts)
+	}
+
+	os.Remove(filepath.Join(dir, "old.pdf"))
+	s.Scan(ctx)
+	s.Wait()
+	if len(r.prompts) != 1 {
+		t.Fatalf("expected one turn, got %d", len(r.prompts))
+	}
+	prompt := r.prompts[0]
+	if !strings.Contains(prompt, "created: "+filepath.Join(dir, "new.pdf")) || !strings.Contains(prompt, "removed: "+filepath.Join(dir, "old.pdf")) {
+		t.Errorf("unexpected prompt %q", prompt)
+	}
+	if strings.Contains(prompt, "notes.txt") {
+		t.Error("expected files not matching the pattern to be ignored")
+	}
+	if r.sessions[0] != "watch:invoices" || len(r.sent) != 1 || r.sent[0] != "telegram:42 noted" {
+		t.Errorf("unexpected delivery: %v %v", r.sessions, r.sent)
+	}
+
+	s.Scan(ctx)
+	s.Wait()
+	if len(r.prompts) != 1 {
+		t.Error("expected each change to be reported once")
+	}
+}
+
+func TestAddRemovePersist(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "watches.json")
+	r := &recorder{}
+	s, _ := NewService(path, time.Minute, []Watch{{Name: "cfg", Path: dir, Channel: "telegram", ChatID: "1"}}, r.run, r.notify)
+
+	w, err := s.Add(Watch{Path: dir, Prompt: "New: {{range .changes}}{{.File}}{{end}}", Channel: "telegram", ChatID: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.ID != 2 || w.Name != "watch-2" {
+		t.Errorf("expected a derived name, got %q", w.Name)
+	}
+	if _, err := s.Add(Watch{Path: filepath.Join(dir, "missing"), Channel: "telegram", ChatID: "1"}); err == nil {
+		t.Error("expected a missing path to be refused")
+	}
+	if _, err := s.Add(Watch{Path: dir, Events: []string{"renamed"}}); err == nil {
+		t.Error("expected an unknown event to be refused")
+	}
+
+	reloaded, err := NewService(path, time.Minute, []Watch{{Name: "cfg", Path: dir, Channel: "telegram", ChatID: "1"}}, r.run, r.notify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := reloaded.List("telegram", "1")
+	if len(list) != 2 || list[0].ID != w.ID || !list[1].Configured {
+		t.Fatalf("expected the saved and configured watches, got %+v", list)
+	}
+	if err := reloaded.Remove(list[1].ID, "telegram", "1"); err == nil {
+		t.Error("expected configured watches to be kept")
+	}
+	if err := reloaded.Remove(w.ID, "telegram", "2"); err == nil {
+		t.Error("expected other chats' watches to be out of reach")
+	}
+	if err := reloaded.Remove(w.ID, "telegram", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if list := reloaded.List("telegram", "1"); len(list) != 1 || !list[0].Configured {
+		t.Errorf("expected only the configured watch left, got %+v", list)
+	}
+}
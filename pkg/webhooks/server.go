@@ -0,0 +1,248 @@
+// Package webhooks serves /hooks/<name> endpoints that let other services
+// (GitHub, Grafana, IFTTT, a shell script) trigger the agent. The request
+// body is rendered into the hook's prompt template, the agent handles it
+// as background work, and the reply is sent to the hook's chat.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+// maxBody is the largest payload accepted.
+const maxBody = 1 << 20
+
+// maxInFlight is how many turns one hook may have queued or running.
+// Further requests are refused with 429 until one finishes.
+const maxInFlight = 4
+
+// turnTimeout bounds one webhook-triggered turn.
+const turnTimeout = 10 * time.Minute
+
+// RunFunc runs an agent turn in the given session and returns the reply.
+type RunFunc func(ctx context.Context, prompt, sessionKey string) (string, error)
+
+// NotifyFunc sends a message to a chat.
+type NotifyFunc func(channel, chatID, content string)
+
+// Server routes webhook requests to the agent.
+type Server struct {
+	listen string
+	hooks  map[string]*hook
+	run    RunFunc
+	notify NotifyFunc
+	wg     sync.WaitGroup
+	ctx    context.Context // set by Run; turns are cancelled with it
+}
+
+type hook struct {
+	config.HookConfig
+	prompt   *template.Template
+	inFlight chan struct{}
+}
+
+// NewServer prepares the hooks in cfg. A hook whose prompt template does
+// not parse is skipped and reported in the returned error; the server is
+// usable either way.
+func NewServer(cfg config.WebhooksConfig, run RunFunc, notify NotifyFunc) (*Server, error) {
+	s := &Server{listen: cfg.Listen, hooks: make(map[string]*hook), run: run, notify: notify, ctx: context.Background()}
+	var errs []error
+	for _, hc := range cfg.Hooks {
+		tmpl, err := parsePrompt(hc.Name, hc.Prompt)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hook %q: %w", hc.Name, err))
+			continue
+		}
+		s.hooks[hc.Name] = &hook{HookConfig: hc, prompt: tmpl, inFlight: make(chan struct{}, maxInFlight)}
+	}
+	return s, errors.Join(errs...)
+}
+
+// defaultPrompt is used by hooks without a prompt template.
+const defaultPrompt = "The webhook \"{{.name}}\" was called with this payload:\n\n{{json .payload}}\n\nReport what happened briefly."
+
+func parsePrompt(name, text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = defaultPrompt
+	}
+	return template.New(name).Funcs(template.FuncMap{
+		"json": func(v interface{}) string {
+			data, _ := json.MarshalIndent(v, "", "  ")
+			return string(data)
+		},
+		"truncate": func(n int, s string) string {
+			if len(s) <= n {
+				return s
+			}
+			return s[:n] + "..."
+		},
+	}).Option("missingkey=zero").Parse(text)
+}
+
+// Run serves until ctx is cancelled, then waits for running turns.
+func (s *Server) Run(ctx context.Context) error {
+	s.ctx = ctx
+	ln, err := net.Listen("tcp", s.listen)
+	if err != nil {
+		return fmt.Errorf("webhooks: %w", err)
+	}
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("[webhooks] Listening on http://%s/hooks/ (%d hooks)", ln.Addr(), len(s.hooks))
+	err = srv.Serve(ln)
+	s.wg.Wait()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// ServeHTTP handles POST /hooks/<name>.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutPrefix(r.URL.Path, "/hooks/")
+	h := s.hooks[name]
+	if !ok || h == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxBody {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !authorized(h.Secret, r, body) {
+		log.Printf("[webhooks] Rejected unauthenticated call to %q from %s", name, r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Header.Get("X-GitHub-Event") == "ping" {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "pong"})
+		return
+	}
+
+	prompt, err := h.render(r, body)
+	if err != nil {
+		http.Error(w, "template error: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	select {
+	case h.inFlight <- struct{}{}:
+	default:
+		http.Error(w, "too many pending calls", http.StatusTooManyRequests)
+		return
+	}
+	s.wg.Add(1)
+	go s.handle(h, prompt)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+// handle runs the turn for one call and delivers the reply.
+func (s *Server) handle(h *hook, prompt string) {
+	defer s.wg.Done()
+	defer func() { <-h.inFlight }()
+
+	ctx, cancel := context.WithTimeout(s.ctx, turnTimeout)
+	defer cancel()
+	reply, err := s.run(ctx, prompt, "webhook:"+h.Name)
+	if err != nil {
+		log.Printf("[webhooks] Hook %q failed: %v", h.Name, err)
+		reply = fmt.Sprintf("⚠️ Webhook %s failed: %v", h.Name, err)
+	}
+	if h.Channel != "" && h.ChatID != "" && strings.TrimSpace(reply) != "" {
+		s.notify(h.Channel, h.ChatID, reply)
+	}
+}
+
+// render fills in the hook's prompt. A body that is not JSON is passed as
+// a string.
+func (h *hook) render(r *http.Request, body []byte) (string, error) {
+	var payload interface{}
+	if json.Unmarshal(body, &payload) != nil {
+		payload = string(body)
+	}
+	headers := make(map[string]string)
+	for k, v := range r.Header {
+		if !strings.EqualFold(k, "Authorization") && !strings.EqualFold(k, "X-Webhook-Token") {
+			headers[strings.ToLower(k)] = strings.Join(v, ", ")
+		}
+	}
+	query := make(map[string]string)
+	for k, v := range r.URL.Query() {
+		if k != "token" {
+			query[k] = strings.Join(v, ", ")
+		}
+	}
+
+	var sb strings.Builder
+	err := h.prompt.Execute(&sb, map[string]interface{}{
+		"name":    h.Name,
+		"payload": payload,
+		"headers": headers,
+		"query":   query,
+	})
+	// Fields missing from the payload render as nothing
+	return strings.ReplaceAll(sb.String(), "<no value>", ""), err
+}
+
+// authorized checks the caller knows the hook's secret: as the key of a
+// GitHub-style X-Hub-Signature-256 HMAC of the body, or as a token in the
+// Authorization (Bearer), X-Webhook-Token or ?token= fields.
+func authorized(secret string, r *http.Request, body []byte) bool {
+	if secret == "" {
+		return false
+	}
+	if sig, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256="); ok {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		want := hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(sig), []byte(want))
+	}
+
+	token := r.Header.Get("X-Webhook-Token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
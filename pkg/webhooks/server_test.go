@@ -0,0 +1,117 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntminh611/mclaw/pkg/config"
+)
+
+type delivery struct{ channel, chatID, content string }
+
+func newTestServer(t *testing.T, prompts chan string) (*Server, chan delivery) {
+	t.Helper()
+	delivered := make(chan delivery, 4)
+	s, err := NewServer(config.WebhooksConfig{Hooks: []config.HookConfig{
+		{Name: "github", Secret: "s3cret", Channel: "telegram", ChatID: "42",
+			Prompt: `{{index .headers "x-github-event"}} on {{.payload.repository.full_name}} by {{.payload.sender.login}}{{.payload.missing}}`},
+		{Name: "plain", Secret: "tok"},
+		{Name: "broken", Secret: "x", Prompt: "{{.payload"},
+	}}, func(ctx context.Context, prompt, sessionKey string) (string, error) {
+		prompts <- sessionKey + "|" + prompt
+		return "done", nil
+	}, func(channel, chatID, content string) {
+		delivered <- delivery{channel, chatID, content}
+	})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the broken template to be reported, got %v", err)
+	}
+	return s, delivered
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func call(s *Server, path, body string, header map[string]string) int {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestGitHubHook(t *testing.T) {
+	prompts := make(chan string, 4)
+	s, delivered := newTestServer(t, prompts)
+	body := `{"repository":{"full_name":"acme/app"},"sender":{"login":"octocat"}}`
+
+	if code := call(s, "/hooks/github", body, map[string]string{"X-Hub-Signature-256": sign("wrong", body)}); code != http.StatusUnauthorized {
+		t.Errorf("expected a bad signature to be rejected, got %d", code)
+	}
+	if code := call(s, "/hooks/github", body, map[string]string{"X-Hub-Signature-256": sign("s3cret", body), "X-GitHub-Event": "ping"}); code != http.StatusOK {
+		t.Errorf("expected ping to be answered directly, got %d", code)
+	}
+
+	code := call(s, "/hooks/github", body, map[string]string{"X-Hub-Signature-256": sign("s3cret", body), "X-GitHub-Event": "push"})
+	if code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	select {
+	case p := <-prompts:
+		if p != "webhook:github|push on acme/app by octocat" {
+			t.Errorf("unexpected prompt %q", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the agent to run")
+	}
+	select {
+	case d := <-delivered:
+		if d != (delivery{"telegram", "42", "done"}) {
+			t.Errorf("unexpected delivery %+v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the reply to be delivered")
+	}
+	s.wg.Wait()
+}
+
+func TestTokenHook(t *testing.T) {
+	prompts := make(chan string, 4)
+	s, delivered := newTestServer(t, prompts)
+
+	if code := call(s, "/hooks/plain", "hello", nil); code != http.StatusUnauthorized {
+		t.Errorf("expected a call without token to be rejected, got %d", code)
+	}
+	if code := call(s, "/hooks/nope?token=tok", "{}", nil); code != http.StatusNotFound {
+		t.Errorf("expected an unknown hook to be 404, got %d", code)
+	}
+	if code := call(s, "/hooks/broken?token=x", "{}", nil); code != http.StatusNotFound {
+		t.Errorf("expected a hook with a bad template to be unavailable, got %d", code)
+	}
+	if code := call(s, "/hooks/plain?token=tok", "disk almost full", nil); code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	if p := <-prompts; !strings.Contains(p, `"disk almost full"`) {
+		t.Errorf("expected the raw body in the default prompt, got %q", p)
+	}
+	if code := call(s, "/hooks/plain", `{"a":1}`, map[string]string{"Authorization": "Bearer tok"}); code != http.StatusAccepted {
+		t.Errorf("expected a bearer token to be accepted, got %d", code)
+	}
+	<-prompts
+	s.wg.Wait()
+	if len(delivered) != 0 {
+		t.Error("expected no delivery for a hook without a chat")
+	}
+}
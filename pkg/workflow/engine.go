@@ -0,0 +1,282 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ToolExecutor runs a named tool. Satisfied by *tools.ToolRegistry.
+type ToolExecutor interface {
+	Execute(ctx context.Context, name string, args map[string]interface{}) (string, error)
+}
+
+// PromptFunc sends a one-shot prompt to the LLM (no tools) and returns its reply.
+type PromptFunc func(ctx context.Context, prompt string) (string, error)
+
+// NotifyFunc delivers a notification to a chat.
+type NotifyFunc func(channel, chatID, content string)
+
+// Target is where notify steps are delivered. An empty target only records
+// notifications in the run result.
+type Target struct {
+	Channel string
+	ChatID  string
+}
+
+// StepResult records the outcome of one step.
+type StepResult struct {
+	ID      string `json:"id"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Output  string `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RunResult is the outcome of a workflow run.
+type RunResult struct {
+	Workflow      string       `json:"workflow"`
+	Steps         []StepResult `json:"steps"`
+	Notifications []string     `json:"notifications,omitempty"`
+}
+
+type Engine struct {
+	dir    string
+	tools  ToolExecutor
+	prompt PromptFunc
+	notify NotifyFunc
+}
+
+// NewEngine creates an engine that loads workflows from workspace/workflows.
+func NewEngine(workspace string, tools ToolExecutor, prompt PromptFunc) *Engine {
+	return &Engine{
+		dir:    filepath.Join(workspace, "workflows"),
+		tools:  tools,
+		prompt: prompt,
+	}
+}
+
+func (e *Engine) SetNotifier(notify NotifyFunc) {
+	e.notify = notify
+}
+
+// Dir returns the directory workflows are loaded from.
+func (e *Engine) Dir() string {
+	return e.dir
+}
+
+func (e *Engine) List() ([]*Workflow, []error) {
+	return LoadDir(e.dir)
+}
+
+// Get looks up a workflow by name.
+func (e *Engine) Get(name string) (*Workflow, error) {
+	workflows, errs := LoadDir(e.dir)
+	for _, wf := range workflows {
+		if wf.Name == name {
+			return wf, nil
+		}
+	}
+	// Surface parse errors for a file with the requested name
+	for _, err := range errs {
+		if strings.HasPrefix(err.Error(), name+".") {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("workflow %q not found in %s", name, e.dir)
+}
+
+// RunByName loads and runs a workflow.
+func (e *Engine) RunByName(ctx context.Context, name string, target Target) (*RunResult, error) {
+	wf, err := e.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.Run(ctx, wf, target)
+}
+
+// Run executes the steps in order. A failing step aborts the run unless it
+// sets continue_on_error; the partial result is returned alongside the error.
+func (e *Engine) Run(ctx context.Context, wf *Workflow, target Target) (*RunResult, error) {
+	result := &RunResult{Workflow: wf.Name}
+	outputs := make(map[string]string)
+
+	log.Printf("[workflow] Running '%s' (%d steps)", wf.Name, len(wf.Steps))
+
+	for _, step := range wf.Steps {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if step.When != nil {
+			ok, err := step.When.Eval(outputs)
+			if err != nil {
+				result.Steps = append(result.Steps, StepResult{ID: step.ID, Error: err.Error()})
+				return result, fmt.Errorf("step %s: %w", step.ID, err)
+			}
+			if !ok {
+				result.Steps = append(result.Steps, StepResult{ID: step.ID, Skipped: true})
+				continue
+			}
+		}
+
+		output, err := e.runStep(ctx, step, outputs, target, result)
+		if err == nil && step.Extract != "" {
+			output, err = extract(step.Extract, output)
+		}
+		if err != nil {
+			result.Steps = append(result.Steps, StepResult{ID: step.ID, Error: err.Error()})
+			if step.ContinueOnError {
+				outputs[step.ID] = ""
+				continue
+			}
+			log.Printf("[workflow] '%s' failed at step %s: %v", wf.Name, step.ID, err)
+			return result, fmt.Errorf("step %s: %w", step.ID, err)
+		}
+
+		outputs[step.ID] = output
+		result.Steps = append(result.Steps, StepResult{ID: step.ID, Output: output})
+	}
+
+	log.Printf("[workflow] '%s' completed", wf.Name)
+	return result, nil
+}
+
+func (e *Engine) runStep(ctx context.Context, step Step, outputs map[string]string, target Target, result *RunResult) (string, error) {
+	switch {
+	case step.Tool != "":
+		if e.tools == nil {
+			return "", fmt.Errorf("no tool executor configured")
+		}
+		args, _ := expandValue(step.Args, outputs).(map[string]interface{})
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+		output, err := e.tools.Execute(ctx, step.Tool, args)
+		if err != nil {
+			return "", err
+		}
+		// Tools report soft failures as "Error: ..." strings
+		if strings.HasPrefix(output, "Error:") {
+			return "", fmt.Errorf("%s", strings.TrimSpace(strings.TrimPrefix(output, "Error:")))
+		}
+		return output, nil
+
+	case step.Prompt != "":
+		if e.prompt == nil {
+			return "", fmt.Errorf("no LLM configured for prompt steps")
+		}
+		return e.prompt(ctx, Expand(step.Prompt, outputs))
+
+	default:
+		content := Expand(step.Notify, outputs)
+		result.Notifications = append(result.Notifications, content)
+		if e.notify != nil && target.Channel != "" && target.ChatID != "" {
+			e.notify(target.Channel, target.ChatID, content)
+		}
+		return content, nil
+	}
+}
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_\-]+)\s*\}\}`)
+
+// Expand substitutes {{step_id}} with that step's output, plus the built-ins
+// {{date}} (2006-01-02) and {{time}} (15:04). Unknown placeholders are left as-is.
+func Expand(s string, outputs map[string]string) string {
+	return placeholderRe.ReplaceAllStringFunc(s, func(m string) string {
+		key := placeholderRe.FindStringSubmatch(m)[1]
+		if v, ok := outputs[key]; ok {
+			return v
+		}
+		switch key {
+		case "date":
+			return time.Now().Format("2006-01-02")
+		case "time":
+			return time.Now().Format("15:04")
+		}
+		return m
+	})
+}
+
+func expandValue(v interface{}, outputs map[string]string) interface{} {
+	switch val := v.(type) {
+	case string:
+		return Expand(val, outputs)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = expandValue(item, outputs)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = expandValue(item, outputs)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func extract(pattern, output string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid extract pattern: %w", err)
+	}
+	m := re.FindStringSubmatch(output)
+	if m == nil {
+		return "", fmt.Errorf("extract pattern %q did not match", pattern)
+	}
+	if len(m) > 1 {
+		return m[1], nil
+	}
+	return m[0], nil
+}
+
+var numberRe = regexp.MustCompile(`-?\d+(?:\.\d+)?`)
+
+// Eval reports whether the condition holds for the referenced step's output.
+func (c *Condition) Eval(outputs map[string]string) (bool, error) {
+	value := outputs[c.Step]
+
+	if c.Contains != "" && !strings.Contains(value, c.Contains) {
+		return false, nil
+	}
+	if c.NotContains != "" && strings.Contains(value, c.NotContains) {
+		return false, nil
+	}
+	if c.Matches != "" {
+		re, err := regexp.Compile(c.Matches)
+		if err != nil {
+			return false, fmt.Errorf("invalid matches pattern: %w", err)
+		}
+		if !re.MatchString(value) {
+			return false, nil
+		}
+	}
+
+	if c.GT != nil || c.LT != nil {
+		// Use the first number in the output, ignoring thousands separators
+		num := numberRe.FindString(strings.ReplaceAll(value, ",", ""))
+		if num == "" {
+			return false, nil
+		}
+		n, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return false, nil
+		}
+		if c.GT != nil && !(n > *c.GT) {
+			return false, nil
+		}
+		if c.LT != nil && !(n < *c.LT) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,148 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow is a deterministic, multi-step pipeline loaded from
+// workspace/workflows/<name>.yaml.
+//
+// Example:
+//
+//	name: btc-alert
+//	description: Notify me when BTC is above 100k
+//	steps:
+//	  - id: price
+//	    tool: web_fetch
+//	    args: {url: "https://api.coinbase.com/v2/prices/BTC-USD/spot"}
+//	    extract: '"amount":"([0-9.]+)"'
+//	  - id: alert
+//	    when: {step: price, gt: 100000}
+//	    notify: "BTC is at ${{price}}"
+type Workflow struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Steps       []Step `yaml:"steps" json:"steps"`
+	Path        string `yaml:"-" json:"-"`
+}
+
+// Step is a single workflow action. Exactly one of Tool, Prompt or Notify must be set.
+type Step struct {
+	ID              string                 `yaml:"id" json:"id"`
+	Tool            string                 `yaml:"tool,omitempty" json:"tool,omitempty"`
+	Args            map[string]interface{} `yaml:"args,omitempty" json:"args,omitempty"`
+	Prompt          string                 `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+	Notify          string                 `yaml:"notify,omitempty" json:"notify,omitempty"`
+	Extract         string                 `yaml:"extract,omitempty" json:"extract,omitempty"` // regex; first capture group (or whole match) becomes the output
+	When            *Condition             `yaml:"when,omitempty" json:"when,omitempty"`
+	ContinueOnError bool                   `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty"`
+}
+
+// Condition gates a step on the output of an earlier step. All set fields must match.
+type Condition struct {
+	Step        string   `yaml:"step" json:"step"`
+	Contains    string   `yaml:"contains,omitempty" json:"contains,omitempty"`
+	NotContains string   `yaml:"not_contains,omitempty" json:"not_contains,omitempty"`
+	Matches     string   `yaml:"matches,omitempty" json:"matches,omitempty"`
+	GT          *float64 `yaml:"gt,omitempty" json:"gt,omitempty"`
+	LT          *float64 `yaml:"lt,omitempty" json:"lt,omitempty"`
+}
+
+// Parse decodes and validates a workflow definition.
+func Parse(data []byte) (*Workflow, error) {
+	var wf Workflow
+	if err := yaml.Unmarshal(data, &wf); err != nil {
+		return nil, fmt.Errorf("invalid workflow YAML: %w", err)
+	}
+	if err := wf.Validate(); err != nil {
+		return nil, err
+	}
+	return &wf, nil
+}
+
+// Validate checks that step IDs are unique and every step has exactly one action.
+func (wf *Workflow) Validate() error {
+	if len(wf.Steps) == 0 {
+		return fmt.Errorf("workflow %q has no steps", wf.Name)
+	}
+
+	seen := make(map[string]bool)
+	for i := range wf.Steps {
+		step := &wf.Steps[i]
+		if step.ID == "" {
+			step.ID = fmt.Sprintf("step%d", i+1)
+		}
+		if seen[step.ID] {
+			return fmt.Errorf("duplicate step id %q", step.ID)
+		}
+
+		actions := 0
+		for _, set := range []bool{step.Tool != "", step.Prompt != "", step.Notify != ""} {
+			if set {
+				actions++
+			}
+		}
+		if actions != 1 {
+			return fmt.Errorf("step %q must set exactly one of tool, prompt or notify", step.ID)
+		}
+
+		if step.When != nil && step.When.Step != "" && !seen[step.When.Step] {
+			return fmt.Errorf("step %q: condition references unknown or later step %q", step.ID, step.When.Step)
+		}
+		seen[step.ID] = true
+	}
+	return nil
+}
+
+// LoadFile reads a workflow from disk. The name defaults to the file name.
+func LoadFile(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if wf.Name == "" {
+		wf.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	wf.Path = path
+	return wf, nil
+}
+
+// LoadDir loads all *.yaml / *.yml workflows in dir, sorted by name.
+// Invalid files are skipped and reported in the returned error list.
+func LoadDir(dir string) ([]*Workflow, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+
+	var workflows []*Workflow
+	var errs []error
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		wf, err := LoadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		workflows = append(workflows, wf)
+	}
+
+	sort.Slice(workflows, func(i, j int) bool { return workflows[i].Name < workflows[j].Name })
+	return workflows, errs
+}
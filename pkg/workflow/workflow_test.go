@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeTools struct {
+	calls []map[string]interface{}
+}
+
+func (f *fakeTools) Execute(ctx context.Context, name string, args map[string]interface{}) (string, error) {
+	f.calls = append(f.calls, args)
+	return `{"data":{"amount":"101,250.5","currency":"USD"}}`, nil
+}
+
+const btcWorkflow = `
+name: btc-alert
+steps:
+  - id: price
+    tool: web_fetch
+    args: {url: "https://example.com/btc"}
+    extract: '"amount":"([0-9.,]+)"'
+  - id: high
+    when: {step: price, gt: 100000}
+    notify: "BTC is at {{price}}"
+  - id: low
+    when: {step: price, lt: 50000}
+    notify: "BTC dropped to {{price}}"
+`
+
+func TestRunConditionalNotify(t *testing.T) {
+	wf, err := Parse([]byte(btcWorkflow))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	ft := &fakeTools{}
+	engine := NewEngine(t.TempDir(), ft, nil)
+
+	var delivered []string
+	engine.SetNotifier(func(channel, chatID, content string) {
+		delivered = append(delivered, channel+":"+chatID+":"+content)
+	})
+
+	result, err := engine.Run(context.Background(), wf, Target{Channel: "telegram", ChatID: "42"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(ft.calls) != 1 || ft.calls[0]["url"] != "https://example.com/btc" {
+		t.Fatalf("unexpected tool calls: %v", ft.calls)
+	}
+	if len(delivered) != 1 || delivered[0] != "telegram:42:BTC is at 101,250.5" {
+		t.Errorf("unexpected deliveries: %v", delivered)
+	}
+	if !result.Steps[2].Skipped {
+		t.Error("low-price step should be skipped")
+	}
+}
+
+func TestValidateAndLoadDir(t *testing.T) {
+	if _, err := Parse([]byte("steps:\n  - id: a\n    tool: x\n    notify: y\n")); err == nil {
+		t.Error("expected error for step with two actions")
+	}
+	if _, err := Parse([]byte("steps:\n  - id: a\n    when: {step: b}\n    notify: y\n")); err == nil {
+		t.Error("expected error for condition on unknown step")
+	}
+
+	dir := filepath.Join(t.TempDir(), "workflows")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "daily.yaml"), []byte("steps:\n  - notify: hello {{date}}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.yml"), []byte("steps: []\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+
+	workflows, errs := LoadDir(dir)
+	if len(workflows) != 1 || workflows[0].Name != "daily" {
+		t.Fatalf("expected workflow 'daily', got %v", workflows)
+	}
+	if workflows[0].Steps[0].ID != "step1" {
+		t.Errorf("expected default step id, got %q", workflows[0].Steps[0].ID)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected 1 load error, got %v", errs)
+	}
+}